@@ -24,19 +24,23 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cgrates/cgrates/config"
 	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/sessionmanager"
 	"github.com/cgrates/cgrates/utils"
 	"github.com/cgrates/rpcclient"
 	"github.com/fiorix/go-diameter/diam"
+	"github.com/fiorix/go-diameter/diam/avp"
 	"github.com/fiorix/go-diameter/diam/datatype"
 	"github.com/fiorix/go-diameter/diam/sm"
 )
 
 func NewDiameterAgent(cgrCfg *config.CGRConfig, smg rpcclient.RpcClientConnection,
 	pubsubs rpcclient.RpcClientConnection) (*DiameterAgent, error) {
-	da := &DiameterAgent{cgrCfg: cgrCfg, smg: smg, pubsubs: pubsubs, connMux: new(sync.Mutex)}
+	da := &DiameterAgent{cgrCfg: cgrCfg, smg: smg, pubsubs: pubsubs, connMux: new(sync.Mutex),
+		sessions: make(map[string]*dmtSession), peerConns: make(map[diam.Conn]bool), sessionsMux: new(sync.RWMutex)}
 	if reflect.ValueOf(da.pubsubs).IsNil() {
 		da.pubsubs = nil // Empty it so we can check it later
 	}
@@ -50,10 +54,19 @@ func NewDiameterAgent(cgrCfg *config.CGRConfig, smg rpcclient.RpcClientConnectio
 }
 
 type DiameterAgent struct {
-	cgrCfg  *config.CGRConfig
-	smg     rpcclient.RpcClientConnection // Connection towards CGR-SMG component
-	pubsubs rpcclient.RpcClientConnection // Connection towards CGR-PubSub component
-	connMux *sync.Mutex                   // Protect connection for read/write
+	cgrCfg      *config.CGRConfig
+	smg         rpcclient.RpcClientConnection // Connection towards CGR-SMG component
+	pubsubs     rpcclient.RpcClientConnection // Connection towards CGR-PubSub component
+	connMux     *sync.Mutex                   // Protect connection for read/write
+	sessions    map[string]*dmtSession        // active sessions indexed on OriginID, so we can reach back their peers
+	peerConns   map[diam.Conn]bool            // peer connections watched for closing, so we drop their sessions
+	sessionsMux *sync.RWMutex                 // Protect sessions and peerConns
+}
+
+// dmtSession keeps the peer connection a session was initiated over
+type dmtSession struct {
+	conn diam.Conn
+	ccr  *CCR // initial CCR, source of the identities in server initiated requests
 }
 
 // Creates the message handlers
@@ -67,6 +80,8 @@ func (self *DiameterAgent) handlers() diam.Handler {
 	}
 	dSM := sm.New(settings)
 	dSM.HandleFunc("CCR", self.handleCCR)
+	dSM.HandleFunc("RAA", self.handleServerAnswer)
+	dSM.HandleFunc("ASA", self.handleServerAnswer)
 	dSM.HandleFunc("ALL", self.handleALL)
 	go func() {
 		for err := range dSM.ErrorReports() {
@@ -77,6 +92,9 @@ func (self *DiameterAgent) handlers() diam.Handler {
 }
 
 func (self DiameterAgent) processCCR(ccr *CCR, reqProcessor *config.DARequestProcessor, processorVars map[string]string, cca *CCA) (bool, error) {
+	if !processorMatchesCommand(reqProcessor, ccr.diamMessage) {
+		return false, nil
+	}
 	passesAllFilters := true
 	for _, fldFilter := range reqProcessor.RequestFilter {
 		if passes, _ := passesFieldFilter(ccr.diamMessage, fldFilter, nil); !passes {
@@ -107,6 +125,7 @@ func (self DiameterAgent) processCCR(ccr *CCR, reqProcessor *config.DARequestPro
 	if len(reqProcessor.Flags) != 0 {
 		smgEv[utils.CGRFlags] = reqProcessor.Flags.String() // Populate CGRFlags automatically
 	}
+	smgEv[utils.CGRConnectorID] = self.cgrCfg.DiameterAgentCfg().OriginHost // scopes the sessions sync to ours
 	if reqProcessor.PublishEvent && self.pubsubs != nil {
 		evt, err := smgEv.AsMapStringString()
 		if err != nil {
@@ -136,16 +155,27 @@ func (self DiameterAgent) processCCR(ccr *CCR, reqProcessor *config.DARequestPro
 		utils.Logger.Info(fmt.Sprintf("<DiameterAgent> SMGenericEvent: %+v", smgEv))
 		processorVars[CGRResultCode] = strconv.Itoa(diam.LimitedSuccess)
 	} else { // Find out maxUsage over APIs
-		switch ccr.CCRequestType {
-		case 1:
+		processorVars[CGROriginID] = smgEv.GetOriginID(utils.META_DEFAULT)
+		smgAction := reqProcessor.SMGAction
+		if smgAction == "" {
+			smgAction = smgActionFromCCRequestType(ccr.CCRequestType)
+		}
+		processorVars[CGRSMGAction] = smgAction
+		switch smgAction {
+		case MetaSMGAuthorize:
+			err = self.smg.Call("SMGenericV1.GetMaxUsage", smgEv, &maxUsage)
+		case MetaSMGInitiate:
 			err = self.smg.Call("SMGenericV1.InitiateSession", smgEv, &maxUsage)
-		case 2:
+		case MetaSMGUpdate:
 			err = self.smg.Call("SMGenericV1.UpdateSession", smgEv, &maxUsage)
-		case 3, 4: // Handle them together since we generate CDR for them
+		case MetaSMGCDR:
+			var rpl string
+			err = self.smg.Call("SMGenericV1.ProcessCDR", smgEv, &rpl)
+		case MetaSMGTerminate, MetaSMGEvent: // Handle them together since we generate CDR for them
 			var rpl string
-			if ccr.CCRequestType == 3 {
+			if smgAction == MetaSMGTerminate {
 				err = self.smg.Call("SMGenericV1.TerminateSession", smgEv, &rpl)
-			} else if ccr.CCRequestType == 4 {
+			} else {
 				err = self.smg.Call("SMGenericV1.ChargeEvent", smgEv.Clone(), &maxUsage)
 				if maxUsage == 0 {
 					smgEv[utils.USAGE] = 0 // For CDR not to debit
@@ -232,6 +262,7 @@ func (self *DiameterAgent) handlerCCR(c diam.Conn, m *diam.Message) {
 		utils.Logger.Err(fmt.Sprintf("<DiameterAgent> No request processor enabled for CCR: %s, ignoring request", ccr.diamMessage))
 		return
 	}
+	self.trackSession(c, ccr, processorVars)
 	self.connMux.Lock()
 	defer self.connMux.Unlock()
 	if _, err := cca.AsDiameterMessage().WriteTo(c); err != nil {
@@ -240,16 +271,202 @@ func (self *DiameterAgent) handlerCCR(c diam.Conn, m *diam.Message) {
 	}
 }
 
+// trackSession keeps the sessions authorized by SMG so we can reach their peers with RAR/ASR
+func (self *DiameterAgent) trackSession(c diam.Conn, ccr *CCR, processorVars map[string]string) {
+	originID, has := processorVars[CGROriginID]
+	if !has {
+		return
+	}
+	authorized := processorVars[CGRResultCode] == strconv.Itoa(diam.Success) &&
+		processorVars[CGRError] == "" // errors like INSUFFICIENT_CREDIT keep the Result-Code, leaving the answer to the templates
+	switch processorVars[CGRSMGAction] {
+	case MetaSMGInitiate:
+		if authorized {
+			self.setSession(originID, &dmtSession{conn: c, ccr: ccr})
+		}
+	case MetaSMGUpdate:
+		if _, has := self.getSession(originID); !has && authorized {
+			self.setSession(originID, &dmtSession{conn: c, ccr: ccr}) // session initiated before our restart
+		}
+	case MetaSMGTerminate:
+		self.removeSession(originID)
+	}
+}
+
+func (self *DiameterAgent) setSession(originID string, s *dmtSession) {
+	self.sessionsMux.Lock()
+	self.sessions[originID] = s
+	watched := self.peerConns[s.conn]
+	self.peerConns[s.conn] = true
+	self.sessionsMux.Unlock()
+	if !watched {
+		go self.removeSessionsOnClose(s.conn)
+	}
+}
+
+// removeSessionsOnClose waits for the peer connection to go away, removing the sessions initiated over it
+func (self *DiameterAgent) removeSessionsOnClose(c diam.Conn) {
+	cn, canNotify := c.(diam.CloseNotifier)
+	if !canNotify {
+		return
+	}
+	<-cn.CloseNotify()
+	self.sessionsMux.Lock()
+	for originID, s := range self.sessions {
+		if s.conn == c {
+			delete(self.sessions, originID)
+		}
+	}
+	delete(self.peerConns, c)
+	self.sessionsMux.Unlock()
+}
+
+func (self *DiameterAgent) removeSession(originID string) {
+	self.sessionsMux.Lock()
+	delete(self.sessions, originID)
+	self.sessionsMux.Unlock()
+}
+
+func (self *DiameterAgent) getSession(originID string) (s *dmtSession, has bool) {
+	self.sessionsMux.RLock()
+	s, has = self.sessions[originID]
+	self.sessionsMux.RUnlock()
+	return
+}
+
+// sendServerRequest writes a request initiated by us towards the peer of the session
+func (self *DiameterAgent) sendServerRequest(originID string, m *diam.Message) error {
+	s, has := self.getSession(originID)
+	if !has {
+		return utils.ErrNoActiveSession
+	}
+	self.connMux.Lock()
+	defer self.connMux.Unlock()
+	if _, err := m.WriteTo(s.conn); err != nil {
+		self.removeSession(originID) // peer connection is gone, the session cannot be reached anymore
+		return err
+	}
+	return nil
+}
+
+// V1DisconnectSession is called by SMG to terminate a session, sends ASR towards the peer.
+// SMG ends the session on its side (ie: on TTL), so we stop tracking it.
+func (self *DiameterAgent) V1DisconnectSession(args utils.AttrDisconnectSession, reply *string) error {
+	originID := sessionmanager.SMGenericEvent(args.EventStart).GetOriginID(utils.META_DEFAULT)
+	s, has := self.getSession(originID)
+	if !has {
+		return utils.ErrNoActiveSession
+	}
+	defer self.removeSession(originID)
+	if err := self.sendServerRequest(originID,
+		NewASRFromCCR(s.ccr, self.cgrCfg.DiameterAgentCfg().OriginHost, self.cgrCfg.DiameterAgentCfg().OriginRealm)); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<DiameterAgent> Error: %s when sending ASR for session: %s, reason: %s", err.Error(), originID, args.Reason))
+		return err
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// V1ReAuthorizeSession is called by SMG to force the peer to ask for new quota, sends RAR towards the peer
+func (self *DiameterAgent) V1ReAuthorizeSession(args utils.AttrReAuthorizeSession, reply *string) error {
+	originID := sessionmanager.SMGenericEvent(args.EventStart).GetOriginID(utils.META_DEFAULT)
+	s, has := self.getSession(originID)
+	if !has {
+		return utils.ErrNoActiveSession
+	}
+	if err := self.sendServerRequest(originID,
+		NewRARFromCCR(s.ccr, self.cgrCfg.DiameterAgentCfg().OriginHost, self.cgrCfg.DiameterAgentCfg().OriginRealm)); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<DiameterAgent> Error: %s when sending RAR for session: %s", err.Error(), originID))
+		return err
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// rpcclient.RpcClientConnection interface, so SMG can reach us back over BiRPC
+func (self *DiameterAgent) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	parts := strings.Split(serviceMethod, ".")
+	if len(parts) != 2 {
+		return rpcclient.ErrUnsupporteServiceMethod
+	}
+	// get method
+	method := reflect.ValueOf(self).MethodByName(parts[0][len(parts[0])-2:] + parts[1]) // Inherit the version in the method
+	if !method.IsValid() {
+		return rpcclient.ErrUnsupporteServiceMethod
+	}
+	// construct the params
+	params := []reflect.Value{reflect.ValueOf(args), reflect.ValueOf(reply)}
+	ret := method.Call(params)
+	if len(ret) != 1 {
+		return utils.ErrServerError
+	}
+	if ret[0].Interface() == nil {
+		return nil
+	}
+	err, ok := ret[0].Interface().(error)
+	if !ok {
+		return utils.ErrServerError
+	}
+	return err
+}
+
 // Simply dispatch the handling in goroutines
 // Could be futher improved with rate control
 func (self *DiameterAgent) handleCCR(c diam.Conn, m *diam.Message) {
 	go self.handlerCCR(c, m)
 }
 
+// handleServerAnswer logs the failures reported to our RAR/ASR
+func (self *DiameterAgent) handleServerAnswer(c diam.Conn, m *diam.Message) {
+	rc, err := m.FindAVP(avp.ResultCode, 0)
+	if err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<DiameterAgent> Received answer without Result-Code from %s:\n%s", c.RemoteAddr(), m))
+		return
+	}
+	if code, _ := rc.Data.(datatype.Unsigned32); code != diam.Success {
+		utils.Logger.Warning(fmt.Sprintf("<DiameterAgent> Server initiated request failed on %s:\n%s", c.RemoteAddr(), m))
+	}
+}
+
+// handleALL dispatches the requests other than CCR towards the request processors configured for them
 func (self *DiameterAgent) handleALL(c diam.Conn, m *diam.Message) {
+	if m.Header.CommandFlags&diam.RequestFlag != 0 {
+		for _, reqProcessor := range self.cgrCfg.DiameterAgentCfg().RequestProcessors {
+			if processorMatchesCommand(reqProcessor, m) {
+				go self.handlerCCR(c, m)
+				return
+			}
+		}
+	}
 	utils.Logger.Warning(fmt.Sprintf("<DiameterAgent> Received unexpected message from %s:\n%s", c.RemoteAddr(), m))
 }
 
+// syncSessions reports the sessions we know of to SMG so it can end the ones recovered after restart which are gone.
+// Limited to the sessions carrying our OriginHost so the ones of other agents sharing the SMG are left alone.
+// The sync runs once after start, sessions recovered by a SMG restarting on its own are confirmed by their
+// next update or ended on session TTL.
+func (self *DiameterAgent) syncSessions() error {
+	self.sessionsMux.RLock()
+	originIDs := make([]string, 0, len(self.sessions))
+	for originID := range self.sessions {
+		originIDs = append(originIDs, originID)
+	}
+	self.sessionsMux.RUnlock()
+	var reply string
+	return self.smg.Call("SMGenericV1.SyncSessions", sessionmanager.ArgsSyncSessions{
+		Filter: map[string]string{utils.EVENT_NAME: DIAMETER_CCR,
+			utils.CGRConnectorID: self.cgrCfg.DiameterAgentCfg().OriginHost}, OriginIDs: originIDs}, &reply)
+}
+
 func (self *DiameterAgent) ListenAndServe() error {
+	// active sessions send CCR updates within debit_interval, by then we know all of them
+	if self.smg != nil && !reflect.ValueOf(self.smg).IsNil() &&
+		self.cgrCfg.DiameterAgentCfg().DebitInterval != 0 {
+		time.AfterFunc(self.cgrCfg.DiameterAgentCfg().DebitInterval, func() {
+			if err := self.syncSessions(); err != nil {
+				utils.Logger.Warning(fmt.Sprintf("<DiameterAgent> Could not sync sessions with SMG, error: %s", err.Error()))
+			}
+		})
+	}
 	return diam.ListenAndServe(self.cgrCfg.DiameterAgentCfg().Listen, self.handlers(), nil)
 }
@@ -57,6 +57,14 @@ const (
 	CGRError             = "CGRError"
 	CGRMaxUsage          = "CGRMaxUsage"
 	CGRResultCode        = "CGRResultCode"
+	CGROriginID          = "CGROriginID"
+	CGRSMGAction         = "CGRSMGAction"
+	MetaSMGAuthorize     = "*authorize"
+	MetaSMGInitiate      = "*initiate"
+	MetaSMGUpdate        = "*update"
+	MetaSMGTerminate     = "*terminate"
+	MetaSMGEvent         = "*event"
+	MetaSMGCDR           = "*cdr"
 )
 
 var (
@@ -490,7 +498,20 @@ func messageSetAVPsWithPath(m *diam.Message, path []interface{},
 // debitInterval is the configured debitInterval, in sync with the diameter client one
 func NewCCRFromDiameterMessage(m *diam.Message, debitInterval time.Duration) (*CCR, error) {
 	var ccr CCR
-	if err := m.Unmarshal(&ccr); err != nil {
+	if m.Header.CommandCode != diam.CreditControl { // CCR specific AVPs are not defined for other applications, populate only the common ones
+		if a, err := m.FindAVP(avp.SessionID, 0); err == nil {
+			ccr.SessionId = avpValAsString(a)
+		}
+		if a, err := m.FindAVP(avp.OriginHost, 0); err == nil {
+			ccr.OriginHost = avpValAsString(a)
+		}
+		if a, err := m.FindAVP(avp.OriginRealm, 0); err == nil {
+			ccr.OriginRealm = avpValAsString(a)
+		}
+		if a, err := m.FindAVP(avp.AuthApplicationID, 0); err == nil {
+			ccr.AuthApplicationId, _ = strconv.Atoi(avpValAsString(a))
+		}
+	} else if err := m.Unmarshal(&ccr); err != nil {
 		return nil, err
 	}
 	ccr.diamMessage = m
@@ -619,7 +640,7 @@ func (self *CCR) AsDiameterMessage() (*diam.Message, error) {
 // Extracts data out of CCR into a SMGenericEvent based on the configured template
 func (self *CCR) AsSMGenericEvent(cfgFlds []*config.CfgCdrField) (sessionmanager.SMGenericEvent, error) {
 	outMap := make(map[string]string) // work with it so we can append values to keys
-	outMap[utils.EVENT_NAME] = DIAMETER_CCR
+	outMap[utils.EVENT_NAME] = diameterEventName(self.diamMessage)
 	for _, cfgFld := range cfgFlds {
 		fmtOut, err := fieldOutVal(self.diamMessage, cfgFld, self.debitInterval, nil)
 		if err != nil {
@@ -641,6 +662,70 @@ func (self *CCR) AsSMGenericEvent(cfgFlds []*config.CfgCdrField) (sessionmanager
 	return sessionmanager.SMGenericEvent(utils.ConvertMapValStrIf(outMap)), nil
 }
 
+// diameterEventName returns the EventName of the SMGenericEvent built out of request, ie: DIAMETER_ACR
+func diameterEventName(m *diam.Message) string {
+	if m == nil || m.Header.CommandCode == diam.CreditControl {
+		return DIAMETER_CCR
+	}
+	cmd, err := m.Dictionary().FindCommand(m.Header.ApplicationID, m.Header.CommandCode)
+	if err != nil {
+		return "DIAMETER_" + strconv.Itoa(int(m.Header.CommandCode))
+	}
+	return "DIAMETER_" + cmd.Short + "R"
+}
+
+// smgActionFromCCRequestType returns the SMG action used by default for the CC-Request-Type of a CCR
+func smgActionFromCCRequestType(reqType int) string {
+	switch reqType {
+	case 1:
+		return MetaSMGInitiate
+	case 2:
+		return MetaSMGUpdate
+	case 3:
+		return MetaSMGTerminate
+	case 4:
+		return MetaSMGEvent
+	}
+	return utils.META_NONE
+}
+
+// processorMatchesCommand checks whether the request processor is configured for the command and application of the request
+// processors without command code configured handle CCRs, the ones without application ID match any application
+func processorMatchesCommand(reqProcessor *config.DARequestProcessor, m *diam.Message) bool {
+	cmdCode := uint32(reqProcessor.CommandCode)
+	if cmdCode == 0 {
+		cmdCode = diam.CreditControl
+	}
+	if m.Header.CommandCode != cmdCode {
+		return false
+	}
+	return reqProcessor.ApplicationID == 0 || m.Header.ApplicationID == uint32(reqProcessor.ApplicationID)
+}
+
+// serverRequestFromCCR builds the common part of requests sent by us towards the peer which originated the CCR
+func serverRequestFromCCR(cmdCode uint32, ccr *CCR, originHost, originRealm string) *diam.Message {
+	m := diam.NewRequest(cmdCode, uint32(ccr.AuthApplicationId), nil)
+	m.NewAVP(avp.SessionID, avp.Mbit, 0, datatype.UTF8String(ccr.SessionId))
+	m.NewAVP(avp.OriginHost, avp.Mbit, 0, datatype.DiameterIdentity(originHost))
+	m.NewAVP(avp.OriginRealm, avp.Mbit, 0, datatype.DiameterIdentity(originRealm))
+	m.NewAVP(avp.DestinationRealm, avp.Mbit, 0, datatype.DiameterIdentity(ccr.OriginRealm))
+	m.NewAVP(avp.DestinationHost, avp.Mbit, 0, datatype.DiameterIdentity(ccr.OriginHost))
+	m.NewAVP(avp.AuthApplicationID, avp.Mbit, 0, datatype.Unsigned32(ccr.AuthApplicationId))
+	return m
+}
+
+// NewRARFromCCR builds the Re-Auth-Request forcing the peer to re-authorize the quota of the session started with ccr
+func NewRARFromCCR(ccr *CCR, originHost, originRealm string) *diam.Message {
+	m := serverRequestFromCCR(diam.ReAuth, ccr, originHost, originRealm)
+	m.NewAVP(avp.ReAuthRequestType, avp.Mbit, 0, datatype.Enumerated(0)) // AUTHORIZE_ONLY
+	return m
+}
+
+// NewASRFromCCR builds the Abort-Session-Request terminating the session started with ccr
+func NewASRFromCCR(ccr *CCR, originHost, originRealm string) *diam.Message {
+	return serverRequestFromCCR(diam.AbortSession, ccr, originHost, originRealm)
+}
+
 func NewBareCCAFromCCR(ccr *CCR, originHost, originRealm string) *CCA {
 	cca := &CCA{SessionId: ccr.SessionId, AuthApplicationId: ccr.AuthApplicationId, CCRequestType: ccr.CCRequestType, CCRequestNumber: ccr.CCRequestNumber,
 		OriginHost: originHost, OriginRealm: originRealm,
@@ -652,6 +737,7 @@ func NewBareCCAFromCCR(ccr *CCR, originHost, originRealm string) *CCA {
 }
 
 // Call Control Answer, bare structure so we can dynamically manage adding it's fields
+// Also used to answer requests other than CCR, case when only the AVPs common to all answers are populated
 type CCA struct {
 	SessionId          string `avp:"Session-Id"`
 	OriginHost         string `avp:"Origin-Host"`
@@ -676,6 +762,18 @@ func (self *CCA) AsBareDiameterMessage() *diam.Message {
 	m.NewAVP(avp.SessionID, avp.Mbit, 0, datatype.UTF8String(self.SessionId))
 	m.NewAVP(avp.OriginHost, avp.Mbit, 0, datatype.DiameterIdentity(self.OriginHost))
 	m.NewAVP(avp.OriginRealm, avp.Mbit, 0, datatype.DiameterIdentity(self.OriginRealm))
+	if m.Header != nil && m.Header.CommandCode != diam.CreditControl {
+		if self.ccrMessage != nil {
+			for _, avpCode := range []uint32{avp.AuthApplicationID, avp.AcctApplicationID,
+				avp.AccountingRecordType, avp.AccountingRecordNumber} { // echoed by the answers of base and Rf/Rx commands
+				if a, err := self.ccrMessage.FindAVP(avpCode, 0); err == nil {
+					m.AddAVP(a)
+				}
+			}
+		}
+		m.NewAVP(avp.ResultCode, avp.Mbit, 0, datatype.Unsigned32(self.ResultCode))
+		return &m
+	}
 	m.NewAVP(avp.AuthApplicationID, avp.Mbit, 0, datatype.Unsigned32(self.AuthApplicationId))
 	m.NewAVP(avp.CCRequestType, avp.Mbit, 0, datatype.Enumerated(self.CCRequestType))
 	m.NewAVP(avp.CCRequestNumber, avp.Mbit, 0, datatype.Enumerated(self.CCRequestNumber))
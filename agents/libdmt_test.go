@@ -23,6 +23,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -502,3 +504,216 @@ func TestPassesFieldFilter(t *testing.T) {
 		t.Error("Does not pass")
 	}
 }
+
+func TestNewRARFromCCR(t *testing.T) {
+	ccr := &CCR{SessionId: "routinga;1442095190;1476802709", OriginHost: "pcef.example.org", OriginRealm: "example.org",
+		AuthApplicationId: 4, CCRequestType: 1}
+	m := NewRARFromCCR(ccr, "CGR-DA", "cgrates.org")
+	if m.Header.CommandCode != diam.ReAuth || m.Header.ApplicationID != 4 ||
+		m.Header.CommandFlags&diam.RequestFlag == 0 {
+		t.Errorf("Unexpected header: %+v", m.Header)
+	}
+	for code, eVal := range map[uint32]datatype.Type{
+		avp.SessionID:         datatype.UTF8String(ccr.SessionId),
+		avp.OriginHost:        datatype.DiameterIdentity("CGR-DA"),
+		avp.OriginRealm:       datatype.DiameterIdentity("cgrates.org"),
+		avp.DestinationHost:   datatype.DiameterIdentity(ccr.OriginHost),
+		avp.DestinationRealm:  datatype.DiameterIdentity(ccr.OriginRealm),
+		avp.AuthApplicationID: datatype.Unsigned32(4),
+		avp.ReAuthRequestType: datatype.Enumerated(0),
+	} {
+		if a, err := m.FindAVP(code, 0); err != nil {
+			t.Errorf("AVP %d, error: %v", code, err)
+		} else if !reflect.DeepEqual(eVal, a.Data) {
+			t.Errorf("AVP %d, expecting: %+v, received: %+v", code, eVal, a.Data)
+		}
+	}
+}
+
+func TestNewASRFromCCR(t *testing.T) {
+	ccr := &CCR{SessionId: "routinga;1442095190;1476802709", OriginHost: "pcef.example.org", OriginRealm: "example.org",
+		AuthApplicationId: 4, CCRequestType: 1}
+	m := NewASRFromCCR(ccr, "CGR-DA", "cgrates.org")
+	if m.Header.CommandCode != diam.AbortSession || m.Header.ApplicationID != 4 {
+		t.Errorf("Unexpected header: %+v", m.Header)
+	}
+	if a, err := m.FindAVP(avp.DestinationHost, 0); err != nil {
+		t.Error(err)
+	} else if a.Data != datatype.DiameterIdentity(ccr.OriginHost) {
+		t.Errorf("Unexpected Destination-Host: %+v", a.Data)
+	}
+	if _, err := m.FindAVP(avp.ReAuthRequestType, 0); err == nil {
+		t.Error("Re-Auth-Request-Type should not be part of ASR")
+	}
+}
+
+func TestDiameterAgentServerRequestsNoSession(t *testing.T) {
+	da := &DiameterAgent{cgrCfg: config.CgrConfig(), connMux: new(sync.Mutex),
+		sessions: make(map[string]*dmtSession), sessionsMux: new(sync.RWMutex), peerConns: make(map[diam.Conn]bool)}
+	var reply string
+	args := utils.AttrDisconnectSession{EventStart: map[string]interface{}{utils.ACCID: "unknown"}}
+	if err := da.Call("SMGClientV1.DisconnectSession", args, &reply); err != utils.ErrNoActiveSession {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNoActiveSession, err)
+	}
+	if err := da.Call("SMGClientV1.ReAuthorizeSession",
+		utils.AttrReAuthorizeSession{EventStart: args.EventStart}, &reply); err != utils.ErrNoActiveSession {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNoActiveSession, err)
+	}
+	da.setSession("unknown", &dmtSession{ccr: &CCR{SessionId: "unknown"}})
+	if _, has := da.getSession("unknown"); !has {
+		t.Error("Session not recorded")
+	}
+	da.removeSession("unknown")
+	if _, has := da.getSession("unknown"); has {
+		t.Error("Session not removed")
+	}
+}
+
+func TestProcessorMatchesCommand(t *testing.T) {
+	ccr := diam.NewRequest(diam.CreditControl, 4, nil)
+	acr := diam.NewRequest(diam.Accounting, 3, nil)
+	if !processorMatchesCommand(&config.DARequestProcessor{}, ccr) {
+		t.Error("Processor without command code should match CCR")
+	}
+	if processorMatchesCommand(&config.DARequestProcessor{}, acr) {
+		t.Error("Processor without command code should not match ACR")
+	}
+	if !processorMatchesCommand(&config.DARequestProcessor{CommandCode: diam.Accounting}, acr) {
+		t.Error("Processor should match ACR")
+	}
+	if !processorMatchesCommand(&config.DARequestProcessor{CommandCode: diam.Accounting, ApplicationID: 3}, acr) {
+		t.Error("Processor should match ACR on application 3")
+	}
+	if processorMatchesCommand(&config.DARequestProcessor{CommandCode: diam.Accounting, ApplicationID: 16777236}, acr) {
+		t.Error("Processor should not match ACR on application 3")
+	}
+}
+
+func TestSMGActionFromCCRequestType(t *testing.T) {
+	for reqType, eAction := range map[int]string{
+		1: MetaSMGInitiate, 2: MetaSMGUpdate, 3: MetaSMGTerminate, 4: MetaSMGEvent, 0: utils.META_NONE} {
+		if action := smgActionFromCCRequestType(reqType); action != eAction {
+			t.Errorf("CC-Request-Type: %d, expecting: %s, received: %s", reqType, eAction, action)
+		}
+	}
+}
+
+func TestNewBareCCAFromACR(t *testing.T) {
+	m := diam.NewRequest(diam.Accounting, 3, nil)
+	m.NewAVP(avp.SessionID, avp.Mbit, 0, datatype.UTF8String("scscf.example.org;1442095190;1476802709"))
+	m.NewAVP(avp.OriginHost, avp.Mbit, 0, datatype.DiameterIdentity("scscf.example.org"))
+	m.NewAVP(avp.OriginRealm, avp.Mbit, 0, datatype.DiameterIdentity("example.org"))
+	m.NewAVP(avp.AcctApplicationID, avp.Mbit, 0, datatype.Unsigned32(3))
+	m.NewAVP(avp.AccountingRecordType, avp.Mbit, 0, datatype.Enumerated(2))
+	m.NewAVP(avp.AccountingRecordNumber, avp.Mbit, 0, datatype.Unsigned32(0))
+	acr, err := NewCCRFromDiameterMessage(m, time.Duration(5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acr.SessionId != "scscf.example.org;1442095190;1476802709" || acr.OriginHost != "scscf.example.org" {
+		t.Errorf("Unexpected request: %+v", acr)
+	}
+	if evName := diameterEventName(m); evName != "DIAMETER_ACR" {
+		t.Errorf("Unexpected EventName: %s", evName)
+	}
+	aca := NewBareCCAFromCCR(acr, "CGR-DA", "cgrates.org").AsDiameterMessage()
+	if aca.Header.CommandCode != diam.Accounting || aca.Header.CommandFlags&diam.RequestFlag != 0 {
+		t.Errorf("Unexpected header: %+v", aca.Header)
+	}
+	for code, eVal := range map[uint32]datatype.Type{
+		avp.SessionID:              datatype.UTF8String(acr.SessionId),
+		avp.OriginHost:             datatype.DiameterIdentity("CGR-DA"),
+		avp.AcctApplicationID:      datatype.Unsigned32(3),
+		avp.AccountingRecordType:   datatype.Enumerated(2),
+		avp.AccountingRecordNumber: datatype.Unsigned32(0),
+	} {
+		if a, err := aca.FindAVP(code, 0); err != nil {
+			t.Errorf("AVP %d, error: %v", code, err)
+		} else if !reflect.DeepEqual(eVal, a.Data) {
+			t.Errorf("AVP %d, expecting: %+v, received: %+v", code, eVal, a.Data)
+		}
+	}
+	if _, err := aca.FindAVP(avp.CCRequestType, 0); err == nil {
+		t.Error("CC-Request-Type should not be part of ACA")
+	}
+}
+
+type testDiamConn struct {
+	diam.Conn
+	closed chan struct{}
+}
+
+func (c *testDiamConn) CloseNotify() <-chan struct{} {
+	return c.closed
+}
+
+func (c *testDiamConn) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func TestDiameterAgentSessionsCleanup(t *testing.T) {
+	da := &DiameterAgent{cgrCfg: config.CgrConfig(), connMux: new(sync.Mutex),
+		sessions: make(map[string]*dmtSession), sessionsMux: new(sync.RWMutex), peerConns: make(map[diam.Conn]bool)}
+	ccr := &CCR{SessionId: "routinga;1442095190;1476802709", OriginHost: "pcef.example.org", OriginRealm: "example.org",
+		AuthApplicationId: 4, CCRequestType: 1}
+	conn1 := &testDiamConn{closed: make(chan struct{})}
+	conn2 := &testDiamConn{closed: make(chan struct{})}
+	da.setSession("sess1", &dmtSession{conn: conn1, ccr: ccr})
+	da.setSession("sess2", &dmtSession{conn: conn1, ccr: ccr})
+	da.setSession("sess3", &dmtSession{conn: conn2, ccr: ccr})
+	close(conn1.closed)
+	for i := 0; i < 100; i++ {
+		_, has1 := da.getSession("sess1")
+		_, has2 := da.getSession("sess2")
+		if !has1 && !has2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, originID := range []string{"sess1", "sess2"} {
+		if _, has := da.getSession(originID); has {
+			t.Errorf("Session %s not removed on peer close", originID)
+		}
+	}
+	if _, has := da.getSession("sess3"); !has {
+		t.Error("Session of the active peer removed")
+	}
+	var reply string
+	if err := da.Call("SMGClientV1.DisconnectSession",
+		utils.AttrDisconnectSession{EventStart: map[string]interface{}{utils.ACCID: "sess3"}}, &reply); err != nil {
+		t.Error(err)
+	} else if reply != utils.OK {
+		t.Errorf("Unexpected reply: %s", reply)
+	}
+	if _, has := da.getSession("sess3"); has {
+		t.Error("Session not removed on disconnect")
+	}
+}
+
+func TestDiameterAgentTrackSession(t *testing.T) {
+	da := &DiameterAgent{cgrCfg: config.CgrConfig(), connMux: new(sync.Mutex),
+		sessions: make(map[string]*dmtSession), sessionsMux: new(sync.RWMutex), peerConns: make(map[diam.Conn]bool)}
+	ccr := &CCR{SessionId: "routinga;1442095190;1476802709", OriginHost: "pcef.example.org", OriginRealm: "example.org",
+		AuthApplicationId: 4, CCRequestType: 1}
+	conn := &testDiamConn{closed: make(chan struct{})}
+	da.trackSession(conn, ccr, map[string]string{CGROriginID: "sess1", CGRSMGAction: MetaSMGInitiate,
+		CGRResultCode: strconv.Itoa(diam.Success), CGRError: utils.ErrInsufficientCredit.Error()})
+	if _, has := da.getSession("sess1"); has {
+		t.Error("Tracking session not authorized")
+	}
+	da.trackSession(conn, ccr, map[string]string{CGROriginID: "sess1", CGRSMGAction: MetaSMGUpdate,
+		CGRResultCode: strconv.Itoa(diam.Success), CGRError: utils.ErrInsufficientCredit.Error()})
+	if _, has := da.getSession("sess1"); has {
+		t.Error("Tracking session not authorized on update")
+	}
+	da.trackSession(conn, ccr, map[string]string{CGROriginID: "sess1", CGRSMGAction: MetaSMGInitiate,
+		CGRResultCode: strconv.Itoa(diam.Success), CGRError: ""})
+	if _, has := da.getSession("sess1"); !has {
+		t.Error("Authorized session not tracked")
+	}
+	da.trackSession(conn, ccr, map[string]string{CGROriginID: "sess1", CGRSMGAction: MetaSMGTerminate,
+		CGRResultCode: strconv.Itoa(diam.Success), CGRError: ""})
+	if _, has := da.getSession("sess1"); has {
+		t.Error("Session tracked after terminate")
+	}
+}
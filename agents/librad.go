@@ -201,3 +201,36 @@ func radReplyAppendAttributes(reply *radigo.Packet, procVars map[string]string,
 	}
 	return
 }
+
+// radRequestAppendAttributes appends attributes to a RADIUS request initiated by us (ie: CoA-Request),
+// composing their values out of a previously received packet
+func radRequestAppendAttributes(req, srcPkt *radigo.Packet, procVars map[string]string,
+	cfgFlds []*config.CfgCdrField) (err error) {
+	for _, cfgFld := range cfgFlds {
+		passedAllFilters := true
+		for _, fldFilter := range cfgFld.FieldFilter {
+			if !radPassesFieldFilter(srcPkt, procVars, fldFilter) {
+				passedAllFilters = false
+				break
+			}
+		}
+		if !passedAllFilters {
+			continue
+		}
+		fmtOut, err := radFieldOutVal(srcPkt, procVars, cfgFld)
+		if err != nil {
+			return err
+		}
+		if fmtOut == "" { // attribute missing in the source packet, NASes reject empty ones
+			continue
+		}
+		attrName, vendorName := attrVendorFromPath(cfgFld.FieldId)
+		if err = req.AddAVPWithName(attrName, fmtOut, vendorName); err != nil {
+			return err
+		}
+		if cfgFld.BreakOnSuccess {
+			break
+		}
+	}
+	return
+}
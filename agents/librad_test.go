@@ -278,3 +278,42 @@ func TestRadReplyAppendAttributes(t *testing.T) {
 		t.Errorf("Expecting: 30, received: %s", avps[0].GetStringValue())
 	}
 }
+
+func TestRadRequestAppendAttributes(t *testing.T) {
+	acntReq := radigo.NewPacket(radigo.AccountingRequest, 3, dictRad, coder, "CGRateS.org")
+	if err := acntReq.AddAVPWithName("Acct-Session-Id", "e4921177ab0e3586c37f6a185864b71a@0:0:0:0:0:0:0:0", ""); err != nil {
+		t.Error(err)
+	}
+	if err := acntReq.AddAVPWithName("User-Name", "1001", ""); err != nil {
+		t.Error(err)
+	}
+	req := radigo.NewPacket(RadDisconnectRequest, 4, dictRad, coder, "CGRateS.org")
+	reqFlds := []*config.CfgCdrField{
+		&config.CfgCdrField{Tag: "AcctSessionId", FieldId: "Acct-Session-Id", Type: utils.META_COMPOSED,
+			Value: utils.ParseRSRFieldsMustCompile("Acct-Session-Id", utils.INFIELD_SEP)},
+		&config.CfgCdrField{Tag: "UserName", FieldId: "User-Name", Type: utils.META_COMPOSED,
+			Value: utils.ParseRSRFieldsMustCompile("User-Name", utils.INFIELD_SEP)},
+		&config.CfgCdrField{Tag: "CiscoNASPort", FieldId: "Cisco/Cisco-NAS-Port", Type: utils.META_COMPOSED, // missing in source
+			Value: utils.ParseRSRFieldsMustCompile("Cisco/Cisco-NAS-Port", utils.INFIELD_SEP)},
+		&config.CfgCdrField{Tag: "ReplyMessage", FieldId: "Reply-Message", Type: utils.META_COMPOSED,
+			FieldFilter: utils.ParseRSRFieldsMustCompile("*radReqType(*radDMReq)", utils.INFIELD_SEP),
+			Value:       utils.ParseRSRFieldsMustCompile("*cgrReason", utils.INFIELD_SEP)},
+	}
+	if err := radRequestAppendAttributes(req, acntReq,
+		map[string]string{MetaRadReqType: MetaRadDMReq, MetaCGRReason: "INSUFFICIENT_FUNDS"}, reqFlds); err != nil {
+		t.Error(err)
+	}
+	if len(req.AVPs) != 3 {
+		t.Errorf("Unexpected AVPs: %+v", req.AVPs)
+	}
+	if avps := req.AttributesWithName("Acct-Session-Id", ""); len(avps) == 0 {
+		t.Error("Cannot find Acct-Session-Id in request")
+	} else if avps[0].GetStringValue() != "e4921177ab0e3586c37f6a185864b71a@0:0:0:0:0:0:0:0" {
+		t.Errorf("Received: %s", avps[0].GetStringValue())
+	}
+	if avps := req.AttributesWithName("Reply-Message", ""); len(avps) == 0 {
+		t.Error("Cannot find Reply-Message in request")
+	} else if avps[0].GetStringValue() != "INSUFFICIENT_FUNDS" {
+		t.Errorf("Received: %s", avps[0].GetStringValue())
+	}
+}
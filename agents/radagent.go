@@ -20,10 +20,16 @@ package agents
 
 import (
 	"fmt"
+	"net"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/sessionmanager"
 	"github.com/cgrates/cgrates/utils"
 	"github.com/cgrates/radigo"
 	"github.com/cgrates/rpcclient"
@@ -36,6 +42,9 @@ const (
 	MetaRadAcctUpdate   = "*radAcctUpdate"
 	MetaRadAcctStop     = "*radAcctStop"
 	MetaRadAcctEvent    = "*radAcctEvent"
+	MetaRadCoAReq       = "*radCoAReq"
+	MetaRadDMReq        = "*radDMReq"
+	MetaCGRReason       = "*cgrReason"
 	MetaCGRReply        = "*cgrReply"
 	MetaCGRMaxUsage     = "*cgrMaxUsage"
 	MetaCGRError        = "*cgrError"
@@ -44,6 +53,16 @@ const (
 	MetaUsageDifference = "*usage_difference"
 )
 
+// Dynamic Authorization packet codes, RFC 5176
+const (
+	RadDisconnectRequest radigo.PacketCode = 40
+	RadDisconnectACK     radigo.PacketCode = 41
+	RadDisconnectNAK     radigo.PacketCode = 42
+	RadCoARequest        radigo.PacketCode = 43
+	RadCoAACK            radigo.PacketCode = 44
+	RadCoANAK            radigo.PacketCode = 45
+)
+
 func NewRadiusAgent(cgrCfg *config.CGRConfig, smg rpcclient.RpcClientConnection) (ra *RadiusAgent, err error) {
 	dts := make(map[string]*radigo.Dictionary, len(cgrCfg.RadiusAgentCfg().ClientDictionaries))
 	for clntID, dictPath := range cgrCfg.RadiusAgentCfg().ClientDictionaries {
@@ -52,7 +71,9 @@ func NewRadiusAgent(cgrCfg *config.CGRConfig, smg rpcclient.RpcClientConnection)
 		}
 	}
 	dicts := radigo.NewDictionaries(dts)
-	ra = &RadiusAgent{cgrCfg: cgrCfg, smg: smg}
+	ra = &RadiusAgent{cgrCfg: cgrCfg, smg: smg, dicts: dts,
+		sessions: make(map[string]*radSession), sessionsMux: new(sync.RWMutex),
+		dynAuthClnts: make(map[string]*radigo.Client), dynAuthClntsMux: new(sync.Mutex)}
 	secrets := radigo.NewSecrets(cgrCfg.RadiusAgentCfg().ClientSecrets)
 	ra.rsAuth = radigo.NewServer(cgrCfg.RadiusAgentCfg().ListenNet,
 		cgrCfg.RadiusAgentCfg().ListenAuth, secrets, dicts,
@@ -71,6 +92,19 @@ type RadiusAgent struct {
 	smg    rpcclient.RpcClientConnection // Connection towards CGR-SMG component
 	rsAuth *radigo.Server
 	rsAcct *radigo.Server
+	dicts  map[string]*radigo.Dictionary // per client dictionaries, needed when sending requests towards NAS
+
+	sessions        map[string]*radSession    // active sessions indexed on OriginID, so we can reach back their NAS
+	sessionsMux     *sync.RWMutex             // protect sessions
+	dynAuthClnts    map[string]*radigo.Client // clients sending CoA/Disconnect requests, indexed on NAS address
+	dynAuthClntsMux *sync.Mutex               // protect dynAuthClnts
+	pktID           uint32                    // identifier of the last request sent towards NAS
+}
+
+// radSession keeps the NAS and the Accounting-Request which started the session
+type radSession struct {
+	nasAddr string
+	req     *radigo.Packet
 }
 
 // handleAuth handles RADIUS Authorization request
@@ -166,6 +200,7 @@ func (ra *RadiusAgent) processRequest(reqProcessor *config.RARequestProcessor,
 	if err != nil {
 		return false, err
 	}
+	smgEv[utils.CGRConnectorID] = ra.cgrCfg.InstanceID // scopes the sessions sync to ours
 	if reqProcessor.DryRun {
 		utils.Logger.Info(fmt.Sprintf("<RadiusAgent> DRY_RUN, SMGEvent: %+v", smgEv))
 	} else { // process with RPC
@@ -208,6 +243,22 @@ func (ra *RadiusAgent) processRequest(reqProcessor *config.RARequestProcessor,
 			processorVars[MetaCGRError] = err.Error()
 			return false, err
 		}
+		switch processorVars[MetaRadReqType] {
+		case MetaRadAcctStart:
+			if nasAddr := radComposedFieldValue(req, processorVars,
+				ra.cgrCfg.RadiusAgentCfg().NasAddress); nasAddr != "" {
+				ra.setSession(smgEv.GetOriginID(utils.META_DEFAULT), &radSession{nasAddr: nasAddr, req: req})
+			}
+		case MetaRadAcctUpdate:
+			if _, has := ra.getSession(smgEv.GetOriginID(utils.META_DEFAULT)); !has { // session started before our restart
+				if nasAddr := radComposedFieldValue(req, processorVars,
+					ra.cgrCfg.RadiusAgentCfg().NasAddress); nasAddr != "" {
+					ra.setSession(smgEv.GetOriginID(utils.META_DEFAULT), &radSession{nasAddr: nasAddr, req: req})
+				}
+			}
+		case MetaRadAcctStop:
+			ra.removeSession(smgEv.GetOriginID(utils.META_DEFAULT))
+		}
 		processorVars[MetaCGRReply] = utils.ToJSON(cgrReply)
 		processorVars[MetaCGRMaxUsage] = strconv.Itoa(int(maxUsage))
 	}
@@ -220,7 +271,170 @@ func (ra *RadiusAgent) processRequest(reqProcessor *config.RARequestProcessor,
 	return true, nil
 }
 
+func (ra *RadiusAgent) setSession(originID string, s *radSession) {
+	ra.sessionsMux.Lock()
+	ra.sessions[originID] = s
+	ra.sessionsMux.Unlock()
+}
+
+func (ra *RadiusAgent) removeSession(originID string) {
+	ra.sessionsMux.Lock()
+	delete(ra.sessions, originID)
+	ra.sessionsMux.Unlock()
+}
+
+func (ra *RadiusAgent) getSession(originID string) (s *radSession, has bool) {
+	ra.sessionsMux.RLock()
+	s, has = ra.sessions[originID]
+	ra.sessionsMux.RUnlock()
+	return
+}
+
+// dynAuthClient returns the client sending CoA and Disconnect requests towards the NAS, creating it on first use
+func (ra *RadiusAgent) dynAuthClient(nasAddr string) (clnt *radigo.Client, err error) {
+	ra.dynAuthClntsMux.Lock()
+	defer ra.dynAuthClntsMux.Unlock()
+	if clnt, has := ra.dynAuthClnts[nasAddr]; has {
+		return clnt, nil
+	}
+	secret, has := ra.cgrCfg.RadiusAgentCfg().ClientSecrets[nasAddr]
+	if !has {
+		secret = ra.cgrCfg.RadiusAgentCfg().ClientSecrets[utils.META_DEFAULT]
+	}
+	dict, has := ra.dicts[nasAddr]
+	if !has {
+		if dict, has = ra.dicts[utils.META_DEFAULT]; !has {
+			dict = radigo.RFC2865Dictionary()
+		}
+	}
+	if clnt, err = radigo.NewClient("udp",
+		net.JoinHostPort(nasAddr, strconv.Itoa(ra.cgrCfg.RadiusAgentCfg().DynAuthPort)),
+		secret, dict, ra.cgrCfg.ConnectAttempts, nil); err != nil {
+		return
+	}
+	ra.dynAuthClnts[nasAddr] = clnt
+	return
+}
+
+// sendServerRequest builds a request out of template and sends it towards the NAS of the session
+func (ra *RadiusAgent) sendServerRequest(originID string, code, ackCode radigo.PacketCode,
+	procVars map[string]string, tpl []*config.CfgCdrField) error {
+	s, has := ra.getSession(originID)
+	if !has {
+		return utils.ErrNoActiveSession
+	}
+	clnt, err := ra.dynAuthClient(s.nasAddr)
+	if err != nil {
+		return err
+	}
+	req := clnt.NewRequest(code, uint8(atomic.AddUint32(&ra.pktID, 1)))
+	if err := radRequestAppendAttributes(req, s.req, procVars, tpl); err != nil {
+		return err
+	}
+	rpl, err := clnt.SendRequest(req)
+	if err != nil {
+		return err
+	}
+	if rpl.Code != ackCode {
+		rpl.SetAVPValues()
+		var errCause string
+		if avps := rpl.AttributesWithName("Error-Cause", ""); len(avps) != 0 {
+			errCause = avps[0].GetStringValue()
+		}
+		return fmt.Errorf("NAS replied with code: %d, error cause: <%s>", rpl.Code, errCause)
+	}
+	return nil
+}
+
+// V1DisconnectSession is called by SMG to terminate a session, sends Disconnect-Request towards the NAS
+func (ra *RadiusAgent) V1DisconnectSession(args utils.AttrDisconnectSession, reply *string) error {
+	originID := sessionmanager.SMGenericEvent(args.EventStart).GetOriginID(utils.META_DEFAULT)
+	procVars := map[string]string{
+		MetaRadReqType: MetaRadDMReq,
+		MetaCGRReason:  args.Reason,
+	}
+	if err := ra.sendServerRequest(originID, RadDisconnectRequest, RadDisconnectACK,
+		procVars, ra.cgrCfg.RadiusAgentCfg().DMTemplate); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<RadiusAgent> Error: %s when sending Disconnect-Request for session: %s, reason: %s",
+			err.Error(), originID, args.Reason))
+		return err
+	}
+	ra.removeSession(originID) // disconnected, the NAS is not to be reached for it anymore
+	*reply = utils.OK
+	return nil
+}
+
+// V1ReAuthorizeSession is called by SMG to change the authorization of a session, sends CoA-Request towards the NAS
+func (ra *RadiusAgent) V1ReAuthorizeSession(args utils.AttrReAuthorizeSession, reply *string) error {
+	originID := sessionmanager.SMGenericEvent(args.EventStart).GetOriginID(utils.META_DEFAULT)
+	procVars := map[string]string{
+		MetaRadReqType: MetaRadCoAReq,
+	}
+	if err := ra.sendServerRequest(originID, RadCoARequest, RadCoAACK,
+		procVars, ra.cgrCfg.RadiusAgentCfg().CoATemplate); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<RadiusAgent> Error: %s when sending CoA-Request for session: %s",
+			err.Error(), originID))
+		return err
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// rpcclient.RpcClientConnection interface, so SMG can reach us back over BiRPC
+func (ra *RadiusAgent) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	parts := strings.Split(serviceMethod, ".")
+	if len(parts) != 2 {
+		return rpcclient.ErrUnsupporteServiceMethod
+	}
+	// get method
+	method := reflect.ValueOf(ra).MethodByName(parts[0][len(parts[0])-2:] + parts[1]) // Inherit the version in the method
+	if !method.IsValid() {
+		return rpcclient.ErrUnsupporteServiceMethod
+	}
+	// construct the params
+	params := []reflect.Value{reflect.ValueOf(args), reflect.ValueOf(reply)}
+	ret := method.Call(params)
+	if len(ret) != 1 {
+		return utils.ErrServerError
+	}
+	if ret[0].Interface() == nil {
+		return nil
+	}
+	err, ok := ret[0].Interface().(error)
+	if !ok {
+		return utils.ErrServerError
+	}
+	return err
+}
+
+// syncSessions reports the sessions we know of to SMG so it can end the ones recovered after restart which are gone.
+// Limited to the sessions carrying our instance_id so the ones of other agents sharing the SMG are left alone,
+// the instance_id needs to be configured for the sessions to be synced after the agent restarts.
+// The sync runs once after start, sessions recovered by a SMG restarting on its own are confirmed by their
+// next update or ended on session TTL.
+func (ra *RadiusAgent) syncSessions() error {
+	ra.sessionsMux.RLock()
+	originIDs := make([]string, 0, len(ra.sessions))
+	for originID := range ra.sessions {
+		originIDs = append(originIDs, originID)
+	}
+	ra.sessionsMux.RUnlock()
+	var reply string
+	return ra.smg.Call("SMGenericV1.SyncSessions", sessionmanager.ArgsSyncSessions{
+		Filter: map[string]string{utils.EVENT_NAME: EvRadiusReq,
+			utils.CGRConnectorID: ra.cgrCfg.InstanceID}, OriginIDs: originIDs}, &reply)
+}
+
 func (ra *RadiusAgent) ListenAndServe() (err error) {
+	// active sessions send Interim-Update within interim_interval, by then we know all of them
+	if ra.smg != nil && !reflect.ValueOf(ra.smg).IsNil() &&
+		ra.cgrCfg.RadiusAgentCfg().InterimInterval != 0 {
+		time.AfterFunc(ra.cgrCfg.RadiusAgentCfg().InterimInterval, func() {
+			if err := ra.syncSessions(); err != nil {
+				utils.Logger.Warning(fmt.Sprintf("<RadiusAgent> Could not sync sessions with SMG, error: %s", err.Error()))
+			}
+		})
+	}
 	var errListen chan error
 	go func() {
 		utils.Logger.Info(fmt.Sprintf("<RadiusAgent> Start listening for auth requests on <%s>", ra.cgrCfg.RadiusAgentCfg().ListenAuth))
@@ -0,0 +1,206 @@
+// +build integration
+
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package agents
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/sessionmanager"
+	"github.com/cgrates/cgrates/utils"
+	"github.com/cgrates/radigo"
+)
+
+var coaRA *RadiusAgent
+var nasReqs chan *radigo.Packet // requests received by the NAS stand-in
+
+// Start a radigo server acting as NAS, listening for CoA and Disconnect requests
+func TestRAcoaitStartNAS(t *testing.T) {
+	nasReqs = make(chan *radigo.Packet, 2)
+	nas := radigo.NewServer("udp", "127.0.0.1:13799",
+		radigo.NewSecrets(map[string]string{utils.META_DEFAULT: "CGRateS.org"}),
+		radigo.NewDictionaries(map[string]*radigo.Dictionary{utils.META_DEFAULT: dictRad}),
+		map[radigo.PacketCode]func(*radigo.Packet) (*radigo.Packet, error){
+			RadDisconnectRequest: func(req *radigo.Packet) (*radigo.Packet, error) {
+				nasReqs <- req
+				rpl := req.Reply()
+				rpl.Code = RadDisconnectACK
+				return rpl, nil
+			},
+			RadCoARequest: func(req *radigo.Packet) (*radigo.Packet, error) {
+				nasReqs <- req
+				rpl := req.Reply()
+				rpl.Code = RadCoANAK // the NAS stand-in does not support changing authorization
+				return rpl, nil
+			},
+		}, nil)
+	go nas.ListenAndServe()
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestRAcoaitInitAgent(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.RadiusAgentCfg().DynAuthPort = 13799
+	cfg.RadiusAgentCfg().DMTemplate = append(cfg.RadiusAgentCfg().DMTemplate,
+		&config.CfgCdrField{Tag: "ReplyMessage", FieldId: "Reply-Message", Type: utils.META_COMPOSED,
+			Value: utils.ParseRSRFieldsMustCompile("*cgrReason", utils.INFIELD_SEP)})
+	coaRA = &RadiusAgent{cgrCfg: cfg, dicts: map[string]*radigo.Dictionary{utils.META_DEFAULT: dictRad},
+		sessions: make(map[string]*radSession), sessionsMux: new(sync.RWMutex),
+		dynAuthClnts: make(map[string]*radigo.Client), dynAuthClntsMux: new(sync.Mutex)}
+	// session as recorded out of Accounting-Request Start
+	acntReq := radigo.NewPacket(radigo.AccountingRequest, 1, dictRad, coder, "CGRateS.org")
+	if err := acntReq.AddAVPWithName("Acct-Session-Id", "e4921177ab0e3586c37f6a185864b71a@0:0:0:0:0:0:0:0", ""); err != nil {
+		t.Error(err)
+	}
+	if err := acntReq.AddAVPWithName("User-Name", "1001", ""); err != nil {
+		t.Error(err)
+	}
+	acntReq.SetAVPValues()
+	coaRA.setSession("e4921177ab0e3586c37f6a185864b71a@0:0:0:0:0:0:0:0-51585361-75c2f57b",
+		&radSession{nasAddr: "127.0.0.1", req: acntReq})
+}
+
+func TestRAcoaitDisconnectSession(t *testing.T) {
+	originID := "e4921177ab0e3586c37f6a185864b71a@0:0:0:0:0:0:0:0-51585361-75c2f57b"
+	s, _ := coaRA.getSession(originID)
+	var reply string
+	if err := coaRA.Call("SMGClientV1.DisconnectSession",
+		utils.AttrDisconnectSession{EventStart: map[string]interface{}{
+			utils.ACCID: "e4921177ab0e3586c37f6a185864b71a@0:0:0:0:0:0:0:0-51585361-75c2f57b"},
+			Reason: utils.ErrInsufficientCredit.Error()}, &reply); err != nil {
+		t.Fatal(err)
+	} else if reply != utils.OK {
+		t.Errorf("Received reply: %s", reply)
+	}
+	select {
+	case req := <-nasReqs:
+		if req.Code != RadDisconnectRequest {
+			t.Errorf("Unexpected code: %d", req.Code)
+		}
+		req.SetAVPValues()
+		if avps := req.AttributesWithName("Acct-Session-Id", ""); len(avps) == 0 ||
+			avps[0].GetStringValue() != "e4921177ab0e3586c37f6a185864b71a@0:0:0:0:0:0:0:0" {
+			t.Errorf("Unexpected Acct-Session-Id in: %+v", req.AVPs)
+		}
+		if avps := req.AttributesWithName("User-Name", ""); len(avps) == 0 || avps[0].GetStringValue() != "1001" {
+			t.Errorf("Unexpected User-Name in: %+v", req.AVPs)
+		}
+		if avps := req.AttributesWithName("Reply-Message", ""); len(avps) == 0 ||
+			avps[0].GetStringValue() != utils.ErrInsufficientCredit.Error() {
+			t.Errorf("Unexpected Reply-Message in: %+v", req.AVPs)
+		}
+	case <-time.After(time.Second):
+		t.Error("Disconnect-Request not received by NAS")
+	}
+	if _, has := coaRA.getSession(originID); has {
+		t.Error("Session not removed after Disconnect-ACK")
+	}
+	coaRA.setSession(originID, s) // for the CoA test
+}
+
+func TestRAcoaitReAuthorizeSession(t *testing.T) {
+	var reply string
+	if err := coaRA.V1ReAuthorizeSession(utils.AttrReAuthorizeSession{EventStart: map[string]interface{}{
+		utils.ACCID: "e4921177ab0e3586c37f6a185864b71a@0:0:0:0:0:0:0:0-51585361-75c2f57b"}}, &reply); err == nil {
+		t.Error("Expecting error on CoA-NAK")
+	}
+	select {
+	case req := <-nasReqs:
+		if req.Code != RadCoARequest {
+			t.Errorf("Unexpected code: %d", req.Code)
+		}
+	case <-time.After(time.Second):
+		t.Error("CoA-Request not received by NAS")
+	}
+	if err := coaRA.V1DisconnectSession(utils.AttrDisconnectSession{
+		EventStart: sessionmanager.SMGenericEvent{utils.ACCID: "unknown"}}, &reply); err != utils.ErrNoActiveSession {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNoActiveSession, err)
+	}
+}
+
+// coaRALsCDRs answers the RALs and CDRs requests of SMG so sessions can be handled without a rating engine
+type coaRALsCDRs struct{}
+
+func (*coaRALsCDRs) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	switch serviceMethod {
+	case "Responder.GetSessionRuns":
+		*reply.(*[]*engine.SessionRun) = []*engine.SessionRun{&engine.SessionRun{
+			DerivedCharger: &utils.DerivedCharger{RunID: utils.META_DEFAULT},
+			CallDescriptor: &engine.CallDescriptor{TimeStart: time.Now()}}}
+	case "Responder.MaxDebit":
+		cd := args.(*engine.CallDescriptor)
+		*reply.(*engine.CallCost) = engine.CallCost{Timespans: engine.TimeSpans{
+			&engine.TimeSpan{TimeStart: cd.TimeStart, TimeEnd: cd.TimeEnd}}}
+	default:
+		*reply.(*string) = utils.OK
+	}
+	return nil
+}
+
+// Session not updated within its TTL, SMG disconnects it over the agent
+func TestRAcoaitSMGTTLDisconnect(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.SmGenericConfig.SessionTTL = 50 * time.Millisecond
+	cfg.SmGenericConfig.SessionTTLDisconnect = true
+	smg := sessionmanager.NewSMGeneric(cfg, new(coaRALsCDRs), new(coaRALsCDRs), nil, nil, "UTC")
+	smgConn := utils.NewBiRPCInternalClient(smg)
+	smgConn.SetClientConn(coaRA)
+	acntReq := radigo.NewPacket(radigo.AccountingRequest, 2, dictRad, coder, "CGRateS.org")
+	if err := acntReq.AddAVPWithName("Acct-Session-Id", "ttl-session", ""); err != nil {
+		t.Error(err)
+	}
+	if err := acntReq.AddAVPWithName("User-Name", "1001", ""); err != nil {
+		t.Error(err)
+	}
+	acntReq.SetAVPValues()
+	coaRA.setSession("ttl-session", &radSession{nasAddr: "127.0.0.1", req: acntReq})
+	var maxUsage time.Duration
+	if err := smgConn.Call("SMGenericV2.InitiateSession", sessionmanager.SMGenericEvent{
+		utils.EVENT_NAME: EvRadiusReq,
+		utils.TOR:        utils.VOICE,
+		utils.ACCID:      "ttl-session",
+		utils.TENANT:     "cgrates.org",
+		utils.ACCOUNT:    "1001",
+		utils.USAGE:      "30s",
+	}, &maxUsage); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case req := <-nasReqs:
+		if req.Code != RadDisconnectRequest {
+			t.Errorf("Unexpected code: %d", req.Code)
+		}
+		req.SetAVPValues()
+		if avps := req.AttributesWithName("Acct-Session-Id", ""); len(avps) == 0 ||
+			avps[0].GetStringValue() != "ttl-session" {
+			t.Errorf("Unexpected Acct-Session-Id in: %+v", req.AVPs)
+		}
+		if avps := req.AttributesWithName("Reply-Message", ""); len(avps) == 0 ||
+			avps[0].GetStringValue() != sessionmanager.SessionTTLExpired {
+			t.Errorf("Unexpected Reply-Message in: %+v", req.AVPs)
+		}
+	case <-time.After(time.Second):
+		t.Error("Disconnect-Request not received by NAS")
+	}
+}
@@ -411,6 +411,7 @@ type AttrAddBalance struct {
 	Value          float64
 	ExpiryTime     *string
 	RatingSubject  *string
+	Currency       *string // monetary balances only
 	Categories     *string
 	DestinationIds *string
 	TimingIds      *string
@@ -419,6 +420,7 @@ type AttrAddBalance struct {
 	Overwrite      bool // When true it will reset if the balance is already there
 	Blocker        *bool
 	Disabled       *bool
+	IdempotencyKey string // retries with the same key are applied only once
 }
 
 func (self *ApierV1) AddBalance(attr *AttrAddBalance, reply *string) error {
@@ -467,6 +469,7 @@ func (self *ApierV1) modifyBalance(aType string, attr *AttrAddBalance, reply *st
 			Value:          &utils.ValueFormula{Static: attr.Value},
 			ExpirationDate: expTime,
 			RatingSubject:  attr.RatingSubject,
+			Currency:       attr.Currency,
 			Weight:         attr.Weight,
 			Blocker:        attr.Blocker,
 			Disabled:       attr.Disabled,
@@ -488,11 +491,13 @@ func (self *ApierV1) modifyBalance(aType string, attr *AttrAddBalance, reply *st
 		a.Balance.TimingIDs = utils.StringMapPointer(utils.ParseStringMap(*attr.TimingIds))
 	}
 	at.SetActions(engine.Actions{a})
-	if err := at.Execute(nil, nil); err != nil {
-		return err
-	}
-	*reply = OK
-	return nil
+	return self.DataManager.ProcessIdempotent(attr.Tenant, attr.Account, "ApierV1."+aType, attr.IdempotencyKey, attr, reply, func() error {
+		if err := at.Execute(nil, nil); err != nil {
+			return err
+		}
+		*reply = OK
+		return nil
+	})
 }
 
 func (self *ApierV1) SetBalance(attr *utils.AttrSetBalance, reply *string) error {
@@ -534,6 +539,7 @@ func (self *ApierV1) SetBalance(attr *utils.AttrSetBalance, reply *string) error
 			Type:           utils.StringPointer(attr.BalanceType),
 			ExpirationDate: expTime,
 			RatingSubject:  attr.RatingSubject,
+			Currency:       attr.Currency,
 			Weight:         attr.Weight,
 			Blocker:        attr.Blocker,
 			Disabled:       attr.Disabled,
@@ -594,6 +600,7 @@ func (self *ApierV1) RemoveBalances(attr *utils.AttrSetBalance, reply *string) e
 			Type:           utils.StringPointer(attr.BalanceType),
 			ExpirationDate: expTime,
 			RatingSubject:  attr.RatingSubject,
+			Currency:       attr.Currency,
 			Weight:         attr.Weight,
 			Blocker:        attr.Blocker,
 			Disabled:       attr.Disabled,
@@ -0,0 +1,161 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package v1
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+
+	"github.com/cenk/rpc2"
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewAuthV1 initializes AuthV1
+func NewAuthV1(apiAuth *engine.APIAuthorizer) *AuthV1 {
+	return &AuthV1{apiAuth: apiAuth}
+}
+
+// AuthV1 authenticates the connections with API keys
+type AuthV1 struct {
+	apiAuth *engine.APIAuthorizer
+}
+
+// Authenticate presents the API key used by the following requests over the same connection
+func (authV1 *AuthV1) Authenticate(apiKey string, reply *string) error {
+	if err := authV1.apiAuth.Authenticate(apiKey, ""); err != nil {
+		return err
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// BiRPCv1Authenticate is Authenticate over the bidirectional connections
+func (authV1 *AuthV1) BiRPCv1Authenticate(clnt *rpc2.Client, apiKey string, reply *string) error {
+	return authV1.Authenticate(apiKey, reply)
+}
+
+type AttrSetAPIKey struct {
+	Key         string // generated if empty
+	Description string
+	Tenants     []string // *any for all
+	RoleIDs     []string
+	Disabled    bool
+}
+
+// SetAPIKey alters/creates an APIKey, replying with the key since only its hash is stored
+func (apierV1 *ApierV1) SetAPIKey(attr *AttrSetAPIKey, reply *string) (err error) {
+	if missing := utils.MissingStructFields(attr, []string{"Tenants", "RoleIDs"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	apiKey := attr.Key
+	if apiKey == "" {
+		b := make([]byte, 32)
+		if _, err = rand.Read(b); err != nil {
+			return utils.NewErrServerError(err)
+		}
+		apiKey = hex.EncodeToString(b)
+	}
+	if err = apierV1.DataManager.SetAPIKey(&engine.APIKey{ID: engine.APIKeyID(apiKey),
+		Description: attr.Description, Tenants: attr.Tenants,
+		RoleIDs: attr.RoleIDs, Disabled: attr.Disabled}); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = apiKey
+	return
+}
+
+// GetAPIKey returns an APIKey based on its ID
+func (apierV1 *ApierV1) GetAPIKey(id string, reply *engine.APIKey) (err error) {
+	apiKey, err := apierV1.DataManager.GetAPIKey(id, true)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *apiKey
+	return
+}
+
+// GetAPIKeyIDs returns the IDs of the APIKeys
+func (apierV1 *ApierV1) GetAPIKeyIDs(ign string, reply *[]string) (err error) {
+	return apierV1.getIDsForPrefix(utils.APIKeyPrefix, reply)
+}
+
+// RemAPIKey removes an APIKey based on its ID, revoking the access of the clients using it
+func (apierV1 *ApierV1) RemAPIKey(id string, reply *string) (err error) {
+	if err = apierV1.DataManager.RemoveAPIKey(id); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return
+}
+
+// SetAPIRole alters/creates an APIRole
+func (apierV1 *ApierV1) SetAPIRole(apiRole *engine.APIRole, reply *string) (err error) {
+	if missing := utils.MissingStructFields(apiRole, []string{"ID", "Methods"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err = apierV1.DataManager.SetAPIRole(apiRole); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return
+}
+
+// GetAPIRole returns an APIRole
+func (apierV1 *ApierV1) GetAPIRole(id string, reply *engine.APIRole) (err error) {
+	apiRole, err := apierV1.DataManager.GetAPIRole(id, true)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *apiRole
+	return
+}
+
+// GetAPIRoleIDs returns the IDs of the APIRoles
+func (apierV1 *ApierV1) GetAPIRoleIDs(ign string, reply *[]string) (err error) {
+	return apierV1.getIDsForPrefix(utils.APIRolePrefix, reply)
+}
+
+// RemAPIRole removes an APIRole
+func (apierV1 *ApierV1) RemAPIRole(id string, reply *string) (err error) {
+	if err = apierV1.DataManager.RemoveAPIRole(id); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return
+}
+
+// getIDsForPrefix lists the sorted IDs of the items stored with the prefix
+func (apierV1 *ApierV1) getIDsForPrefix(prfx string, reply *[]string) (err error) {
+	keys, err := apierV1.DataManager.DataDB().GetKeysForPrefix(prfx)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	if len(keys) == 0 {
+		return utils.ErrNotFound
+	}
+	retIDs := make([]string, len(keys))
+	for i, key := range keys {
+		retIDs[i] = key[len(prfx):]
+	}
+	sort.Strings(retIDs)
+	*reply = retIDs
+	return
+}
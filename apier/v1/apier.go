@@ -1727,6 +1727,8 @@ func (self *ApierV1) LoadTariffPlanFromFolder(attrs utils.AttrLoadTpFromFolder,
 		path.Join(attrs.FolderPath, utils.StatsCsv),
 		path.Join(attrs.FolderPath, utils.ThresholdsCsv),
 		path.Join(attrs.FolderPath, utils.FiltersCsv),
+		path.Join(attrs.FolderPath, utils.SuppliersCsv),
+		path.Join(attrs.FolderPath, utils.ExchangeRatesCsv),
 	), "", self.Config.DefaultTimezone)
 	if err := loader.LoadAll(); err != nil {
 		return utils.NewErrServerError(err)
@@ -2049,6 +2051,8 @@ func (v1 *ApierV1) ReplayFailedPosts(args ArgsReplyFailedPosts, reply *string) (
 					chn.Close()
 				}
 			}
+		case utils.MetaFileJSONL, utils.MetaSQLTable:
+			err = engine.ReplayCDRExport(ffn.Transport, ffn.Address, fileContent)
 		default:
 			err = fmt.Errorf("unsupported replication transport: %s", ffn.Transport)
 		}
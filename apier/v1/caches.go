@@ -0,0 +1,44 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package v1
+
+import (
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewCacheSv1 initializes CacheSv1
+func NewCacheSv1() *CacheSv1 {
+	return new(CacheSv1)
+}
+
+// Exports RPC for the cache shared with other engines
+type CacheSv1 struct{}
+
+// Call implements rpcclient.RpcClientConnection interface for internal RPC
+func (chSv1 *CacheSv1) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	return utils.APIerRPCCall(chSv1, serviceMethod, args, reply)
+}
+
+// InvalidateItems removes out of local cache the items changed by a peer engine
+func (chSv1 *CacheSv1) InvalidateItems(args *utils.ArgsCacheInvalidation, reply *string) error {
+	cache.InvalidateItems(args.Keys, args.Prefixes)
+	*reply = utils.OK
+	return nil
+}
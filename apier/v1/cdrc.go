@@ -36,7 +36,7 @@ func (apier *ApierV1) ReloadCdrcConfig(attrs AttrReloadConfig, reply *string) er
 	if err != nil {
 		return utils.NewErrServerError(err)
 	}
-	apier.Config.CdrcProfiles = newCfg.CdrcProfiles // ToDo: Check if there can be any concurency involved here so we need to lock maybe
+	apier.Config.SetCdrcProfiles(newCfg.CdrcProfiles)
 	apier.Config.ConfigReloads[utils.CDRC] <- struct{}{}
 	*reply = OK
 	return nil
@@ -93,10 +93,10 @@ func (self *ApierV1) ExportCdrsToZipString(attr utils.AttrExpFileCdrs, reply *st
 
 // Deprecated by AttrExportCDRsToFile
 func (self *ApierV1) ExportCdrsToFile(attr utils.AttrExpFileCdrs, reply *utils.ExportedFileCdrs) (err error) {
-	exportTemplate := self.Config.CdreProfiles[utils.META_DEFAULT]
+	exportTemplate := self.Config.CdreProfilesCfg()[utils.META_DEFAULT]
 	if attr.ExportTemplate != nil && len(*attr.ExportTemplate) != 0 { // Export template prefered, use it
 		var hasIt bool
-		if exportTemplate, hasIt = self.Config.CdreProfiles[*attr.ExportTemplate]; !hasIt {
+		if exportTemplate, hasIt = self.Config.CdreProfilesCfg()[*attr.ExportTemplate]; !hasIt {
 			return fmt.Errorf("%s:ExportTemplate", utils.ErrNotFound.Error())
 		}
 	}
@@ -130,8 +130,11 @@ func (self *ApierV1) ExportCdrsToFile(attr utils.AttrExpFileCdrs, reply *utils.E
 		fileName = *attr.ExportFileName
 	}
 	filePath := path.Join(exportPath, fileName)
-	if exportFormat == utils.DRYRUN {
+	switch exportFormat {
+	case utils.DRYRUN:
 		filePath = utils.DRYRUN
+	case utils.MetaSQLTable: // export path is the table location, not a folder
+		filePath = exportPath
 	}
 	usageMultiplyFactor := exportTemplate.UsageMultiplyFactor
 	if attr.DataUsageMultiplyFactor != nil && *attr.DataUsageMultiplyFactor != 0.0 {
@@ -192,7 +195,7 @@ func (apier *ApierV1) ReloadCdreConfig(attrs AttrReloadConfig, reply *string) er
 		return utils.NewErrServerError(err)
 	}
 	cdreReloadStruct := <-apier.Config.ConfigReloads[utils.CDRE] // Get the CDRE reload channel                     // Read the content of the channel, locking it
-	apier.Config.CdreProfiles = newCfg.CdreProfiles
+	apier.Config.SetCdreProfiles(newCfg.CdreProfiles)
 	apier.Config.ConfigReloads[utils.CDRE] <- cdreReloadStruct // Unlock reloads
 	utils.Logger.Info("<CDRE> Configuration reloaded")
 	*reply = OK
@@ -230,10 +233,10 @@ type RplExportedCDRs struct {
 func (self *ApierV1) ExportCDRs(arg ArgExportCDRs, reply *RplExportedCDRs) (err error) {
 	cdreReloadStruct := <-self.Config.ConfigReloads[utils.CDRE]                  // Read the content of the channel, locking it
 	defer func() { self.Config.ConfigReloads[utils.CDRE] <- cdreReloadStruct }() // Unlock reloads at exit
-	exportTemplate := self.Config.CdreProfiles[utils.META_DEFAULT]
+	exportTemplate := self.Config.CdreProfilesCfg()[utils.META_DEFAULT]
 	if arg.ExportTemplate != nil && len(*arg.ExportTemplate) != 0 { // Export template prefered, use it
 		var hasIt bool
-		if exportTemplate, hasIt = self.Config.CdreProfiles[*arg.ExportTemplate]; !hasIt {
+		if exportTemplate, hasIt = self.Config.CdreProfilesCfg()[*arg.ExportTemplate]; !hasIt {
 			return fmt.Errorf("%s:ExportTemplate", utils.ErrNotFound)
 		}
 	}
@@ -273,10 +276,12 @@ func (self *ApierV1) ExportCDRs(arg ArgExportCDRs, reply *RplExportedCDRs) (err
 	}
 	var filePath string
 	switch exportFormat {
-	case utils.MetaFileFWV, utils.MetaFileCSV:
+	case utils.MetaFileFWV, utils.MetaFileCSV, utils.MetaFileJSONL:
 		filePath = path.Join(eDir, fileName)
 	case utils.DRYRUN:
 		filePath = utils.DRYRUN
+	case utils.MetaSQLTable: // export path is the table location, not a folder
+		filePath = eDir
 	default:
 		u, _ := url.Parse(eDir)
 		u.Path = path.Join(u.Path, fileName)
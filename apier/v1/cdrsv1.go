@@ -76,3 +76,8 @@ func (self *CdrsV1) RateCDRs(attrs utils.AttrRateCdrs, reply *string) error {
 func (self *CdrsV1) StoreSMCost(attr engine.AttrCDRSStoreSMCost, reply *string) error {
 	return self.CdrSrv.V1StoreSMCost(attr, reply)
 }
+
+// GetCDRExportQueues returns the status of the retry queues for online CDR exports
+func (self *CdrsV1) GetCDRExportQueues(exportIDs []string, reply *[]*engine.CDRExportQueueStatus) error {
+	return self.CdrSrv.V1GetCDRExportQueues(exportIDs, reply)
+}
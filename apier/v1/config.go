@@ -0,0 +1,58 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewConfigSv1 initializes ConfigSv1, cfgDir being the folder the running configuration was loaded from
+func NewConfigSv1(cfg *config.CGRConfig, cfgDir string) *ConfigSv1 {
+	return &ConfigSv1{cfg: cfg, cfgDir: cfgDir}
+}
+
+// Exports RPC for the configuration of the engine
+type ConfigSv1 struct {
+	cfg    *config.CGRConfig
+	cfgDir string
+}
+
+// Call implements rpcclient.RpcClientConnection interface for internal RPC
+func (cSv1 *ConfigSv1) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	return utils.APIerRPCCall(cSv1, serviceMethod, args, reply)
+}
+
+// ReloadConfig re-reads the configuration folder, applying the changes possible without restart.
+// The sections needing a restart for their changes are reported back.
+func (cSv1 *ConfigSv1) ReloadConfig(attrs AttrReloadConfig, reply *config.ConfigReloadReport) error {
+	if attrs.ConfigDir == "" {
+		attrs.ConfigDir = cSv1.cfgDir
+	}
+	newCfg, err := config.NewCGRConfigFromFolder(attrs.ConfigDir)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = *cSv1.cfg.Reload(newCfg)
+	utils.Logger.Info(fmt.Sprintf("<ConfigS> configuration reloaded out of <%s>, reloaded sections: %v, requiring restart: %v",
+		attrs.ConfigDir, reply.Reloaded, reply.RestartRequired))
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+type AttrExchangeRate struct {
+	FromCurrency string
+	ToCurrency   string
+}
+
+// GetExchangeRate returns the rate converting FromCurrency into ToCurrency
+func (apierV1 *ApierV1) GetExchangeRate(arg *AttrExchangeRate, reply *engine.ExchangeRate) error {
+	if missing := utils.MissingStructFields(arg, []string{"FromCurrency", "ToCurrency"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	xr, err := apierV1.DataManager.GetExchangeRate(arg.FromCurrency, arg.ToCurrency, false, utils.NonTransactional)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *xr
+	return nil
+}
+
+// SetExchangeRate alters/creates an ExchangeRate, used by the debits following it
+func (apierV1 *ApierV1) SetExchangeRate(xr *engine.ExchangeRate, reply *string) error {
+	if missing := utils.MissingStructFields(xr, []string{"FromCurrency", "ToCurrency", "Rate"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if xr.Rate <= 0 { // MissingStructFields does not check the floats
+		return fmt.Errorf("%s:Rate", utils.ErrMandatoryIeMissing.Error())
+	}
+	if err := apierV1.DataManager.SetExchangeRate(xr, utils.NonTransactional); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// RemExchangeRate removes a specific ExchangeRate
+func (apierV1 *ApierV1) RemExchangeRate(arg *AttrExchangeRate, reply *string) error {
+	if missing := utils.MissingStructFields(arg, []string{"FromCurrency", "ToCurrency"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := apierV1.DataManager.RemoveExchangeRate(arg.FromCurrency, arg.ToCurrency, utils.NonTransactional); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
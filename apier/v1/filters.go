@@ -28,6 +28,9 @@ func (self *ApierV1) SetFilter(attrs *engine.Filter, reply *string) error {
 	if missing := utils.MissingStructFields(attrs, []string{"Tenant", "ID"}); len(missing) != 0 {
 		return utils.NewErrMandatoryIeMissing(missing...)
 	}
+	if err := attrs.Compile(); err != nil { // validate values, ie: *regex
+		return utils.NewErrServerError(err)
+	}
 	if err := self.DataManager.SetFilter(attrs); err != nil {
 		return utils.APIErrorHandler(err)
 	}
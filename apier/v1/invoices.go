@@ -0,0 +1,59 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package v1
+
+import (
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewInvoiceSv1 initializes InvoiceSv1
+func NewInvoiceSv1(iS *engine.InvoiceService) *InvoiceSv1 {
+	return &InvoiceSv1{iS: iS}
+}
+
+// Exports RPC from InvoiceS
+type InvoiceSv1 struct {
+	iS *engine.InvoiceService
+}
+
+// Call implements rpcclient.RpcClientConnection interface for internal RPC
+func (iSv1 *InvoiceSv1) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	return utils.APIerRPCCall(iSv1, serviceMethod, args, reply)
+}
+
+// GenerateInvoice builds and stores the invoice of an account for a billing period
+func (iSv1 *InvoiceSv1) GenerateInvoice(args *engine.ArgsGenerateInvoice, reply *engine.Invoice) error {
+	return iSv1.iS.V1GenerateInvoice(args, reply)
+}
+
+// GetInvoice returns a stored invoice
+func (iSv1 *InvoiceSv1) GetInvoice(tntID *utils.TenantID, reply *engine.Invoice) error {
+	return iSv1.iS.V1GetInvoice(tntID, reply)
+}
+
+// GetInvoiceIDs returns the IDs of the invoices stored for a tenant
+func (iSv1 *InvoiceSv1) GetInvoiceIDs(tenant string, invIDs *[]string) error {
+	return iSv1.iS.V1GetInvoiceIDs(tenant, invIDs)
+}
+
+// RenderInvoice formats a stored invoice with one of the configured templates
+func (iSv1 *InvoiceSv1) RenderInvoice(args *engine.ArgsRenderInvoice, reply *string) error {
+	return iSv1.iS.V1RenderInvoice(args, reply)
+}
@@ -58,6 +58,16 @@ func (rsv1 *ResourceSV1) ReleaseResource(args utils.ArgRSv1ResourceUsage, reply
 	return rsv1.rls.V1ReleaseResource(args, reply)
 }
 
+// GetResourceAvailability returns the units left on a resource
+func (rsv1 *ResourceSV1) GetResourceAvailability(args *utils.TenantID, reply *float64) error {
+	return rsv1.rls.V1GetResourceAvailability(args, reply)
+}
+
+// GetResourceUsage returns the units used on a resource
+func (rsv1 *ResourceSV1) GetResourceUsage(args *utils.TenantID, reply *float64) error {
+	return rsv1.rls.V1GetResourceUsage(args, reply)
+}
+
 // GetResourceProfile returns a resource configuration
 func (apierV1 *ApierV1) GetResourceProfile(arg utils.TenantID, reply *engine.ResourceProfile) error {
 	if missing := utils.MissingStructFields(&arg, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
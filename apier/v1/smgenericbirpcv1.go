@@ -46,6 +46,9 @@ func (self *SMGenericBiRpcV1) Handlers() map[string]interface{} {
 		"SMGenericV1.GetṔassiveSessions":      self.GetṔassiveSessions,
 		"SMGenericV1.GetPassiveSessionsCount": self.GetPassiveSessionsCount,
 		"SMGenericV1.ReplicateActiveSessions": self.ReplicateActiveSessions,
+		"SMGenericV1.ReAuthorizeSessions":     self.ReAuthorizeSessions,
+		"SMGenericV1.DisconnectSessions":      self.DisconnectSessions,
+		"SMGenericV1.SyncSessions":            self.SyncSessions,
 	}
 }
 
@@ -107,3 +110,16 @@ func (self *SMGenericBiRpcV1) ReplicateActiveSessions(clnt *rpc2.Client, args se
 func (self *SMGenericBiRpcV1) ReplicatePassiveSessions(clnt *rpc2.Client, args sessionmanager.ArgsReplicateSessions, reply *string) error {
 	return self.sm.BiRPCV1ReplicateActiveSessions(clnt, args, reply)
 }
+
+func (self *SMGenericBiRpcV1) ReAuthorizeSessions(clnt *rpc2.Client, attrs map[string]string, reply *string) error {
+	return self.sm.BiRPCV1ReAuthorizeSessions(clnt, attrs, reply)
+}
+
+func (self *SMGenericBiRpcV1) DisconnectSessions(clnt *rpc2.Client, args sessionmanager.ArgsDisconnectSessions, reply *string) error {
+	return self.sm.BiRPCV1DisconnectSessions(clnt, args, reply)
+}
+
+// Reports the sessions active on the client side so the stale ones can be ended
+func (self *SMGenericBiRpcV1) SyncSessions(clnt *rpc2.Client, args sessionmanager.ArgsSyncSessions, reply *string) error {
+	return self.sm.BiRPCV1SyncSessions(clnt, args, reply)
+}
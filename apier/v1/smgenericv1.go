@@ -99,6 +99,18 @@ func (self *SMGenericV1) ReplicatePassiveSessions(args sessionmanager.ArgsReplic
 	return self.SMG.BiRPCV1ReplicatePassiveSessions(nil, args, reply)
 }
 
+func (self *SMGenericV1) ReAuthorizeSessions(attrs map[string]string, reply *string) error {
+	return self.SMG.BiRPCV1ReAuthorizeSessions(nil, attrs, reply)
+}
+
+func (self *SMGenericV1) DisconnectSessions(args sessionmanager.ArgsDisconnectSessions, reply *string) error {
+	return self.SMG.BiRPCV1DisconnectSessions(nil, args, reply)
+}
+
+func (self *SMGenericV1) SyncSessions(args sessionmanager.ArgsSyncSessions, reply *string) error {
+	return self.SMG.BiRPCV1SyncSessions(nil, args, reply)
+}
+
 // rpcclient.RpcClientConnection interface
 func (self *SMGenericV1) Call(serviceMethod string, args interface{}, reply interface{}) error {
 	methodSplit := strings.Split(serviceMethod, ".")
@@ -19,6 +19,8 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package v1
 
 import (
+	"fmt"
+
 	"github.com/cgrates/cgrates/cache"
 	"github.com/cgrates/cgrates/engine"
 	"github.com/cgrates/cgrates/utils"
@@ -43,6 +45,10 @@ func (apierV1 *ApierV1) SetStatQueueProfile(sqp *engine.StatQueueProfile, reply
 	if missing := utils.MissingStructFields(sqp, []string{"Tenant", "ID"}); len(missing) != 0 {
 		return utils.NewErrMandatoryIeMissing(missing...)
 	}
+	if sqp.Bucket != "" &&
+		!utils.IsSliceMember([]string{utils.MetaHourly, utils.MetaDaily, utils.MetaMonthly}, sqp.Bucket) {
+		return utils.NewErrServerError(fmt.Errorf("unsupported bucket: <%s>", sqp.Bucket))
+	}
 	if err := apierV1.DataManager.SetStatQueueProfile(sqp); err != nil {
 		return utils.APIErrorHandler(err)
 	}
@@ -103,3 +109,13 @@ func (stsv1 *StatSV1) GetQueueStringMetrics(args *utils.TenantID, reply *map[str
 func (stsv1 *StatSV1) GetQueueFloatMetrics(args *utils.TenantID, reply *map[string]float64) (err error) {
 	return stsv1.sS.V1GetQueueFloatMetrics(args, reply)
 }
+
+// ResetStatQueue clears the events and the metrics of a Queue
+func (stsv1 *StatSV1) ResetStatQueue(args *utils.TenantID, reply *string) (err error) {
+	return stsv1.sS.V1ResetStatQueue(args, reply)
+}
+
+// GetQueueHistory returns the closed calendar buckets of a Queue
+func (stsv1 *StatSV1) GetQueueHistory(args *utils.TenantID, reply *[]*engine.StatQueueBucket) (err error) {
+	return stsv1.sS.V1GetQueueHistory(args, reply)
+}
@@ -0,0 +1,88 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package v1
+
+import (
+	"github.com/cgrates/cgrates/cache"
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// NewSupplierSv1 initializes SupplierSv1
+func NewSupplierSv1(splS *engine.SupplierService) *SupplierSv1 {
+	return &SupplierSv1{splS: splS}
+}
+
+// Exports RPC from SupplierS
+type SupplierSv1 struct {
+	splS *engine.SupplierService
+}
+
+// Call implements rpcclient.RpcClientConnection interface for internal RPC
+func (splSv1 *SupplierSv1) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	return utils.APIerRPCCall(splSv1, serviceMethod, args, reply)
+}
+
+// GetSuppliers returns the ordered list of suppliers for an event
+func (splSv1 *SupplierSv1) GetSuppliers(ev *engine.SupplierEvent, reply *engine.SortedSuppliers) error {
+	return splSv1.splS.V1GetSuppliers(ev, reply)
+}
+
+// GetSupplierProfilesForEvent returns the list of supplier profiles matching an event
+func (splSv1 *SupplierSv1) GetSupplierProfilesForEvent(ev *engine.SupplierEvent, reply *engine.SupplierProfiles) error {
+	return splSv1.splS.V1GetSupplierProfilesForEvent(ev, reply)
+}
+
+// GetSupplierProfile returns a Supplier Profile
+func (apierV1 *ApierV1) GetSupplierProfile(arg *utils.TenantID, reply *engine.SupplierProfile) (err error) {
+	if missing := utils.MissingStructFields(arg, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if spp, err := apierV1.DataManager.GetSupplierProfile(arg.Tenant, arg.ID, false, utils.NonTransactional); err != nil {
+		return utils.APIErrorHandler(err)
+	} else {
+		*reply = *spp
+	}
+	return
+}
+
+// SetSupplierProfile alters/creates a SupplierProfile
+func (apierV1 *ApierV1) SetSupplierProfile(spp *engine.SupplierProfile, reply *string) error {
+	if missing := utils.MissingStructFields(spp, []string{"Tenant", "ID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := apierV1.DataManager.SetSupplierProfile(spp); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	cache.RemKey(utils.SupplierProfilePrefix+utils.ConcatenatedKey(spp.Tenant, spp.ID), true, "") // ToDo: Remove here with autoreload
+	*reply = utils.OK
+	return nil
+}
+
+// RemSupplierProfile removes a specific Supplier Profile
+func (apierV1 *ApierV1) RemSupplierProfile(args *utils.TenantID, reply *string) error {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := apierV1.DataManager.RemoveSupplierProfile(args.Tenant, args.ID, utils.NonTransactional); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package v1
+
+import (
+	"sort"
+
+	"github.com/cgrates/cgrates/engine"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// GetTaxProfile returns a TaxProfile
+func (apierV1 *ApierV1) GetTaxProfile(arg *utils.TenantID, reply *engine.TaxProfile) (err error) {
+	if missing := utils.MissingStructFields(arg, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	txPrfl, err := apierV1.DataManager.GetTaxProfile(arg.Tenant, arg.ID)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = *txPrfl
+	return
+}
+
+// GetTaxProfileIDs returns the IDs of the TaxProfiles defined for a tenant
+func (apierV1 *ApierV1) GetTaxProfileIDs(tenant string, txPrflIDs *[]string) (err error) {
+	prfx := utils.TaxProfilePrefix + tenant + ":"
+	keys, err := apierV1.DataManager.DataDB().GetKeysForPrefix(prfx)
+	if err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	if len(keys) == 0 {
+		return utils.ErrNotFound
+	}
+	retIDs := make([]string, len(keys))
+	for i, key := range keys {
+		retIDs[i] = key[len(prfx):]
+	}
+	sort.Strings(retIDs)
+	*txPrflIDs = retIDs
+	return
+}
+
+// SetTaxProfile alters/creates a TaxProfile
+func (apierV1 *ApierV1) SetTaxProfile(txPrfl *engine.TaxProfile, reply *string) error {
+	if missing := utils.MissingStructFields(txPrfl, []string{"Tenant", "ID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := apierV1.DataManager.SetTaxProfile(txPrfl); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// RemTaxProfile removes a TaxProfile
+func (apierV1 *ApierV1) RemTaxProfile(args *utils.TenantID, reply *string) error {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	if err := apierV1.DataManager.RemoveTaxProfile(args.Tenant, args.ID); err != nil {
+		return utils.APIErrorHandler(err)
+	}
+	*reply = utils.OK
+	return nil
+}
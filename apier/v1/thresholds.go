@@ -54,6 +54,11 @@ func (tSv1 *ThresholdSV1) GetThreshold(tntID *utils.TenantID, t *engine.Threshol
 	return tSv1.tS.V1GetThreshold(tntID, t)
 }
 
+// ResetThreshold clears the hits of a Threshold
+func (tSv1 *ThresholdSV1) ResetThreshold(tntID *utils.TenantID, reply *string) error {
+	return tSv1.tS.V1ResetThreshold(tntID, reply)
+}
+
 // ProcessEvent will process an Event
 func (tSv1 *ThresholdSV1) ProcessEvent(ev *engine.ThresholdEvent, hits *int) error {
 	return tSv1.tS.V1ProcessEvent(ev, hits)
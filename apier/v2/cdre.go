@@ -60,10 +60,10 @@ type ExportedFileCdrs struct {
 func (self *ApierV2) ExportCdrsToFile(attr AttrExportCdrsToFile, reply *ExportedFileCdrs) (err error) {
 	cdreReloadStruct := <-self.Config.ConfigReloads[utils.CDRE]                  // Read the content of the channel, locking it
 	defer func() { self.Config.ConfigReloads[utils.CDRE] <- cdreReloadStruct }() // Unlock reloads at exit
-	exportTemplate := self.Config.CdreProfiles[utils.META_DEFAULT]
+	exportTemplate := self.Config.CdreProfilesCfg()[utils.META_DEFAULT]
 	if attr.ExportTemplate != nil && len(*attr.ExportTemplate) != 0 { // Export template prefered, use it
 		var hasIt bool
-		if exportTemplate, hasIt = self.Config.CdreProfiles[*attr.ExportTemplate]; !hasIt {
+		if exportTemplate, hasIt = self.Config.CdreProfilesCfg()[*attr.ExportTemplate]; !hasIt {
 			return fmt.Errorf("%s:ExportTemplate", utils.ErrNotFound)
 		}
 	}
@@ -94,8 +94,11 @@ func (self *ApierV2) ExportCdrsToFile(attr AttrExportCdrsToFile, reply *Exported
 		fileName = *attr.ExportFileName
 	}
 	filePath := path.Join(eDir, fileName)
-	if exportFormat == utils.DRYRUN {
+	switch exportFormat {
+	case utils.DRYRUN:
 		filePath = utils.DRYRUN
+	case utils.MetaSQLTable: // export path is the table location, not a folder
+		filePath = eDir
 	}
 	usageMultiplyFactor := exportTemplate.UsageMultiplyFactor
 	if attr.DataUsageMultiplyFactor != nil && *attr.DataUsageMultiplyFactor != 0.0 {
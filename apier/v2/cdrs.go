@@ -19,6 +19,8 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package v2
 
 import (
+	"time"
+
 	"github.com/cgrates/cgrates/apier/v1"
 
 	"github.com/cgrates/cgrates/engine"
@@ -63,6 +65,68 @@ func (apier *ApierV2) CountCdrs(attrs utils.RPCCDRsFilter, reply *int64) error {
 	return nil
 }
 
+type AttrRefundCDR struct {
+	CGRID          string
+	RunID          string  // *default if empty
+	Usage          string  // RefundCDRUsage only, refund the last usage of the event
+	Amount         float64 // RefundCDRAmount only, money to be given back
+	IdempotencyKey string  // retries with the same key are refunded only once
+}
+
+// RefundCDR gives back to the accounts all the charges of a CDR
+func (apier *ApierV2) RefundCDR(attr AttrRefundCDR, reply *engine.CDRRefund) error {
+	return apier.refundCDR(attr, nil, nil, reply)
+}
+
+// RefundCDRUsage gives back the charges for the last Usage of a CDR
+func (apier *ApierV2) RefundCDRUsage(attr AttrRefundCDR, reply *engine.CDRRefund) error {
+	if attr.Usage == "" {
+		return utils.NewErrMandatoryIeMissing("Usage")
+	}
+	usage, err := utils.ParseDurationWithSecs(attr.Usage)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	return apier.refundCDR(attr, &usage, nil, reply)
+}
+
+// RefundCDRAmount gives back Amount out of the money charged for a CDR
+func (apier *ApierV2) RefundCDRAmount(attr AttrRefundCDR, reply *engine.CDRRefund) error {
+	if attr.Amount <= 0 {
+		return utils.NewErrMandatoryIeMissing("Amount")
+	}
+	return apier.refundCDR(attr, nil, &attr.Amount, reply)
+}
+
+func (apier *ApierV2) refundCDR(attr AttrRefundCDR, usage *time.Duration, amount *float64, reply *engine.CDRRefund) error {
+	if missing := utils.MissingStructFields(&attr, []string{"CGRID"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	runID := attr.RunID
+	if runID == "" {
+		runID = utils.META_DEFAULT
+	}
+	cdrs, _, err := apier.CdrDb.GetCDRs(&utils.CDRsFilter{CGRIDs: []string{attr.CGRID}, RunIDs: []string{runID}}, false)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	} else if len(cdrs) == 0 {
+		return utils.ErrNotFound
+	}
+	return apier.DataManager.ProcessIdempotent(cdrs[0].Tenant, cdrs[0].Account, "ApierV2.RefundCDR",
+		attr.IdempotencyKey, attr, reply, func() error {
+			rf, err := engine.RefundCDR(apier.CdrDb, attr.CGRID, attr.RunID, usage, amount)
+			if err != nil {
+				if err != utils.ErrNotFound && err != utils.ErrNotRefundable &&
+					err != utils.ErrRefundPending {
+					err = utils.NewErrServerError(err)
+				}
+				return err
+			}
+			*reply = *rf
+			return nil
+		})
+}
+
 // Receive CDRs via RPC methods, not included with APIer because it has way less dependencies and can be standalone
 type CdrsV2 struct {
 	v1.CdrsV1
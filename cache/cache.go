@@ -21,12 +21,14 @@ package cache
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/cgrates/cgrates/config"
 	"github.com/cgrates/cgrates/guardian"
 	"github.com/cgrates/cgrates/utils"
+	"github.com/cgrates/rpcclient"
 )
 
 const (
@@ -45,6 +47,11 @@ var (
 	transactionBuffer map[string][]*transactionItem // Queue tasks based on transactionID
 	transBufMux       sync.Mutex                    // Protects the transactionBuffer
 	transactionMux    sync.Mutex                    // Queue transactions on commit
+
+	// replication stuff
+	replicator         rpcclient.RpcClientConnection // broadcasts the invalidations towards peer engines, nil to disable
+	replicatedPrefixes utils.StringMap               // prefixes of the partitions invalidated on peers
+	replMux            sync.RWMutex                  // Protects the replication settings
 )
 
 type transactionItem struct {
@@ -63,6 +70,89 @@ func NewCache(cacheCfg config.CacheConfig) {
 	cfg = cacheCfg
 	cache = newLRUTTL(cacheCfg)
 	transactionBuffer = make(map[string][]*transactionItem) // map[transactionID][]*transactionItem
+	setReplicatedPrefixes(cacheCfg)
+}
+
+// Reconfigure applies the new cache settings on the running cache.
+// Only the partitions with changed settings are rebuilt, losing their cached items.
+func Reconfigure(cacheCfg config.CacheConfig) {
+	cacheMux.Lock()
+	cache.reconfigure(cfg, cacheCfg)
+	cfg = cacheCfg
+	cacheMux.Unlock()
+	setReplicatedPrefixes(cacheCfg)
+}
+
+// setReplicatedPrefixes builds the prefixes of the partitions replicated to peer engines
+func setReplicatedPrefixes(cacheCfg config.CacheConfig) {
+	replMux.Lock()
+	replicatedPrefixes = make(utils.StringMap)
+	for cfgKey, cacheParam := range cacheCfg {
+		if cacheParam.Replicate {
+			replicatedPrefixes[cacheInstanceID(cfgKey)] = true
+		}
+	}
+	replMux.Unlock()
+}
+
+// SetReplicator sets the connection towards the peer engines sharing our DataDB, nil disables the replication
+func SetReplicator(conn rpcclient.RpcClientConnection) {
+	replMux.Lock()
+	replicator = conn
+	replMux.Unlock()
+}
+
+// replicating returns true if the changes of key need to reach the peer engines
+func replicating(key string) bool {
+	if len(key) < PREFIX_LEN {
+		return false
+	}
+	replMux.RLock()
+	defer replMux.RUnlock()
+	return replicator != nil && replicatedPrefixes[key[:PREFIX_LEN]]
+}
+
+// replicate publishes towards the peer engines the invalidation of the items within replicated partitions
+func replicate(keys, prefixes []string) {
+	args := new(utils.ArgsCacheInvalidation)
+	for _, key := range keys {
+		if replicating(key) {
+			args.Keys = append(args.Keys, key)
+		}
+	}
+	for _, prfx := range prefixes {
+		if replicating(prfx) {
+			args.Prefixes = append(args.Prefixes, prfx)
+		}
+	}
+	if len(args.Keys) == 0 && len(args.Prefixes) == 0 {
+		return
+	}
+	replMux.RLock()
+	conn := replicator
+	replMux.RUnlock()
+	go func() {
+		var reply string
+		if err := conn.Call(utils.CacheSv1InvalidateItems, args, &reply); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<Cache> replicating invalidation of keys: %+v, prefixes: %+v, error: %s",
+				args.Keys, args.Prefixes, err.Error()))
+		}
+	}()
+}
+
+// InvalidateItems removes the items changed on a peer engine, without replicating them further
+func InvalidateItems(keys, prefixes []string) {
+	cacheMux.Lock()
+	defer cacheMux.Unlock()
+	for _, key := range keys {
+		if len(key) < PREFIX_LEN {
+			continue
+		}
+		cache.Delete(key)
+	}
+	for _, prfx := range prefixes {
+		cache.DeletePrefix(prfx)
+	}
 }
 
 func BeginTransaction() string {
@@ -83,29 +173,44 @@ func CommitTransaction(transID string) {
 	transactionMux.Lock()
 	transBufMux.Lock()
 	// apply all transactioned items in one shot
+	var replKeys, replPrefixes []string
 	cacheMux.Lock()
 	for _, item := range transactionBuffer[transID] {
 		switch item.verb {
 		case REM:
 			RemKey(item.key, true, transID)
+			replKeys = append(replKeys, item.key)
 		case REM_PREFIX:
 			RemPrefixKey(item.key, true, transID)
+			replPrefixes = append(replPrefixes, item.key)
 		case ADD:
+			if replicating(item.key) {
+				if _, has := cache.Get(item.key); has { // only changed items, not the fresh ones
+					replKeys = append(replKeys, item.key)
+				}
+			}
 			Set(item.key, item.value, true, transID)
 		}
 	}
 	cacheMux.Unlock()
+	replicate(replKeys, replPrefixes)
 	delete(transactionBuffer, transID)
 	transBufMux.Unlock()
 	transactionMux.Unlock()
 }
 
 // The function to be used to cache a key/value pair when expiration is not needed
+// Overwriting an item invalidates it on peers, fresh items are considered read from DataDB
 func Set(key string, value interface{}, commit bool, transID string) {
 	if commit {
 		if transID == "" { // Lock locally
 			cacheMux.Lock()
 			defer cacheMux.Unlock()
+			if replicating(key) {
+				if _, has := cache.Get(key); has {
+					replicate([]string{key}, nil)
+				}
+			}
 		}
 		cache.Put(key, value)
 		//log.Println("ADD: ", key)
@@ -122,6 +227,7 @@ func RemKey(key string, commit bool, transID string) {
 		if transID == "" { // Lock per operation not transaction
 			cacheMux.Lock()
 			defer cacheMux.Unlock()
+			replicate([]string{key}, nil)
 		}
 		cache.Delete(key)
 	} else {
@@ -137,6 +243,7 @@ func RemPrefixKey(prefix string, commit bool, transID string) {
 		if transID == "" { // Lock locally
 			cacheMux.Lock()
 			defer cacheMux.Unlock()
+			replicate(nil, []string{prefix})
 		}
 		cache.DeletePrefix(prefix)
 	} else {
@@ -198,3 +305,20 @@ func GetEntryKeys(prefix string) (keys []string) {
 	defer cacheMux.RUnlock()
 	return cache.GetKeysForPrefix(prefix)
 }
+
+// Metrics returns the number of items cached per partition, to be used as utils.MetricsCollectorFunc
+func Metrics() []*utils.MetricFamily {
+	partitions := make([]string, 0, len(utils.CacheInstanceToPrefix))
+	for partition := range utils.CacheInstanceToPrefix {
+		partitions = append(partitions, partition)
+	}
+	sort.Strings(partitions)
+	mf := &utils.MetricFamily{Name: "cgr_cache_items", Type: utils.MetricGauge,
+		Help: "Number of items cached, per partition"}
+	for _, partition := range partitions {
+		mf.Samples = append(mf.Samples, &utils.MetricSample{
+			Labels: map[string]string{"partition": partition},
+			Value:  float64(CountEntries(utils.CacheInstanceToPrefix[partition]))})
+	}
+	return []*utils.MetricFamily{mf}
+}
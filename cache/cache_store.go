@@ -19,6 +19,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package cache
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/cgrates/cgrates/config"
@@ -34,6 +35,7 @@ type cacheStore interface {
 	CountEntriesForPrefix(string) int
 	GetKeysForPrefix(string) []string
 	Clear()
+	reconfigure(oldCfg, newCfg config.CacheConfig)
 }
 
 type cacheLRUTTL map[string]*ltcache.Cache
@@ -47,15 +49,19 @@ func newLRUTTL(cfg config.CacheConfig) (c cacheLRUTTL) {
 	}
 	// dynamically configure cache instances based on CacheConfig
 	for cfgKey := range cfg {
-		cacheInstanceID := cfgKey
-		if prefixKey, has := utils.CacheInstanceToPrefix[cfgKey]; has {
-			cacheInstanceID = prefixKey // old aliases, backwards compatibility purpose
-		}
-		c[cacheInstanceID] = ltcache.New(cfg[cfgKey].Limit, cfg[cfgKey].TTL, cfg[cfgKey].StaticTTL, nil)
+		c[cacheInstanceID(cfgKey)] = ltcache.New(cfg[cfgKey].Limit, cfg[cfgKey].TTL, cfg[cfgKey].StaticTTL, nil)
 	}
 	return
 }
 
+// cacheInstanceID returns the instance configured by cfgKey
+func cacheInstanceID(cfgKey string) string {
+	if prefixKey, has := utils.CacheInstanceToPrefix[cfgKey]; has {
+		return prefixKey // old aliases, backwards compatibility purpose
+	}
+	return cfgKey
+}
+
 func (cs cacheLRUTTL) cacheInstance(instID string) (c *ltcache.Cache) {
 	var ok bool
 	if c, ok = cs[instID]; !ok {
@@ -107,3 +113,18 @@ func (cs cacheLRUTTL) Clear() {
 		cInst.Clear()
 	}
 }
+
+// reconfigure replaces the instances with changed settings, dropping the items cached within them
+func (cs cacheLRUTTL) reconfigure(oldCfg, newCfg config.CacheConfig) {
+	for cfgKey := range oldCfg {
+		if _, has := newCfg[cfgKey]; !has { // items will be cached in the default instance
+			delete(cs, cacheInstanceID(cfgKey))
+		}
+	}
+	for cfgKey, cacheParam := range newCfg {
+		if reflect.DeepEqual(oldCfg[cfgKey], cacheParam) {
+			continue
+		}
+		cs[cacheInstanceID(cfgKey)] = ltcache.New(cacheParam.Limit, cacheParam.TTL, cacheParam.StaticTTL, nil)
+	}
+}
@@ -19,10 +19,12 @@ package cache
 
 import (
 	"math/rand"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/cgrates/cgrates/config"
 	"github.com/cgrates/cgrates/utils"
 )
 
@@ -190,3 +192,99 @@ func BenchmarkGet(b *testing.B) {
 		cache.Get(cacheItems[rand.Intn(max-min)+min][0])
 	}
 }
+
+type testCacheReplicator struct {
+	invalidations chan *utils.ArgsCacheInvalidation
+}
+
+func (tr *testCacheReplicator) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	tr.invalidations <- args.(*utils.ArgsCacheInvalidation)
+	*reply.(*string) = utils.OK
+	return nil
+}
+
+func (tr *testCacheReplicator) expect(t *testing.T, eArgs *utils.ArgsCacheInvalidation) {
+	select {
+	case args := <-tr.invalidations:
+		if eArgs == nil {
+			t.Errorf("Unexpected invalidation: %+v", args)
+		} else if !reflect.DeepEqual(eArgs, args) {
+			t.Errorf("Expecting: %+v, received: %+v", eArgs, args)
+		}
+	case <-time.After(20 * time.Millisecond):
+		if eArgs != nil {
+			t.Errorf("Expecting invalidation: %+v", eArgs)
+		}
+	}
+}
+
+func TestCacheReplication(t *testing.T) {
+	dfCfg, _ := config.NewDefaultCGRConfig()
+	defer NewCache(dfCfg.CacheConfig)
+	NewCache(config.CacheConfig{
+		utils.CacheDestinations: &config.CacheParamConfig{Limit: -1, Replicate: true},
+		utils.CacheRatingPlans:  &config.CacheParamConfig{Limit: -1},
+	})
+	repl := &testCacheReplicator{invalidations: make(chan *utils.ArgsCacheInvalidation, 10)}
+	SetReplicator(repl)
+	defer SetReplicator(nil)
+	Set("dst_DST1", "v1", true, "") // fresh item, read out of DataDB
+	repl.expect(t, nil)
+	Set("dst_DST1", "v2", true, "") // item changed
+	repl.expect(t, &utils.ArgsCacheInvalidation{Keys: []string{"dst_DST1"}})
+	Set("rpl_RP1", "v1", true, "")
+	Set("rpl_RP1", "v2", true, "") // partition not replicated
+	repl.expect(t, nil)
+	RemKey("dst_DST1", true, "")
+	repl.expect(t, &utils.ArgsCacheInvalidation{Keys: []string{"dst_DST1"}})
+	Set("dst_DST2", "v1", true, "")
+	Set("dst_DST3", "v1", true, "")
+	transID := BeginTransaction()
+	Set("dst_DST2", "v2", false, transID)
+	Set("dst_DST4", "v1", false, transID)
+	RemKey("dst_DST3", false, transID)
+	RemKey("rpl_RP1", false, transID)
+	RemPrefixKey(utils.DESTINATION_PREFIX, false, transID)
+	repl.expect(t, nil) // nothing until commit
+	CommitTransaction(transID)
+	repl.expect(t, &utils.ArgsCacheInvalidation{Keys: []string{"dst_DST2", "dst_DST3"},
+		Prefixes: []string{utils.DESTINATION_PREFIX}})
+	// invalidations received from peers are not replicated further
+	Set("dst_DST5", "v1", true, "")
+	InvalidateItems([]string{"dst_DST5"}, nil)
+	if _, has := Get("dst_DST5"); has {
+		t.Error("Item not invalidated")
+	}
+	repl.expect(t, nil)
+}
+
+func TestCacheReconfigure(t *testing.T) {
+	dfCfg, _ := config.NewDefaultCGRConfig()
+	defer NewCache(dfCfg.CacheConfig)
+	NewCache(config.CacheConfig{
+		utils.CacheDestinations: &config.CacheParamConfig{Limit: -1},
+		utils.CacheRatingPlans:  &config.CacheParamConfig{Limit: -1},
+	})
+	Set("dst_DST1", "v1", true, "")
+	Set("rpl_RP1", "v1", true, "")
+	Reconfigure(config.CacheConfig{
+		utils.CacheDestinations: &config.CacheParamConfig{Limit: -1},
+		utils.CacheRatingPlans:  &config.CacheParamConfig{Limit: 1, Replicate: true},
+	})
+	if _, has := Get("dst_DST1"); !has {
+		t.Error("unchanged partition should keep its items")
+	}
+	if _, has := Get("rpl_RP1"); has {
+		t.Error("reconfigured partition should be empty")
+	}
+	Set("rpl_RP1", "v1", true, "")
+	Set("rpl_RP2", "v1", true, "")
+	if cnt := CountEntries(utils.RATING_PLAN_PREFIX); cnt != 1 {
+		t.Errorf("limit not applied, items: %d", cnt)
+	}
+	replMux.RLock()
+	if !replicatedPrefixes[utils.RATING_PLAN_PREFIX] {
+		t.Errorf("replicated prefixes: %+v", replicatedPrefixes)
+	}
+	replMux.RUnlock()
+}
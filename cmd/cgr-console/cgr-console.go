@@ -40,6 +40,7 @@ var (
 	verbose      = flag.Bool("verbose", false, "Show extra info about command execution.")
 	server       = flag.String("server", "127.0.0.1:2012", "server address host:port")
 	rpc_encoding = flag.String("rpc_encoding", "json", "RPC encoding used <gob|json>")
+	apiKey       = flag.String("api_key", "", "API key authenticating the connection, when the server requires it")
 	client       *rpcclient.RpcClient
 )
 
@@ -92,7 +93,14 @@ func executeCommand(command string) {
 		}
 		//log.Printf("Param: %+v", param)
 
-		if rpcErr := client.Call(cmd.RpcMethod(), param, res); rpcErr != nil {
+		rpcErr := client.Call(cmd.RpcMethod(), param, res)
+		if rpcErr != nil && rpcErr.Error() == utils.ErrUnauthenticated.Error() && *apiKey != "" {
+			// reconnected meanwhile, the new connection needs authenticating again
+			if rpcErr = authenticate(); rpcErr == nil {
+				rpcErr = client.Call(cmd.RpcMethod(), param, res)
+			}
+		}
+		if rpcErr != nil {
 			fmt.Println("Error executing command: " + rpcErr.Error())
 		} else {
 			result, _ := json.MarshalIndent(res, "", " ")
@@ -103,6 +111,12 @@ func executeCommand(command string) {
 	}
 }
 
+// authenticate presents the API key on the current connection
+func authenticate() error {
+	var reply string
+	return client.Call(utils.AuthV1Authenticate, *apiKey, &reply)
+}
+
 func main() {
 	flag.Parse()
 	if *version {
@@ -116,6 +130,11 @@ func main() {
 		flag.PrintDefaults()
 		log.Fatal("Could not connect to server " + *server)
 	}
+	if *apiKey != "" {
+		if err = authenticate(); err != nil {
+			log.Fatal("Could not authenticate, error: " + err.Error())
+		}
+	}
 
 	if len(flag.Args()) != 0 {
 		executeCommand(strings.Join(flag.Args(), " "))
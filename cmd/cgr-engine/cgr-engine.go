@@ -19,15 +19,19 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"runtime"
 	"runtime/pprof"
 	"strconv"
+	"syscall"
 	"time"
 
+	"github.com/cenk/rpc2"
 	"github.com/cgrates/cgrates/agents"
 	"github.com/cgrates/cgrates/apier/v1"
 	"github.com/cgrates/cgrates/apier/v2"
@@ -86,7 +90,7 @@ func startCdrcs(internalCdrSChan, internalRaterChan chan rpcclient.RpcClientConn
 			cdrcChildrenChan = make(chan struct{})
 		}
 		// Start CDRCs
-		for _, cdrcCfgs := range cfg.CdrcProfiles {
+		for _, cdrcCfgs := range cfg.CdrcProfilesCfg() {
 			var enabledCfgs []*config.CdrcConfig
 			for _, cdrcCfg := range cdrcCfgs { // Take a random config out since they should be the same
 				if cdrcCfg.Enabled {
@@ -111,13 +115,15 @@ func startCdrc(internalCdrSChan, internalRaterChan chan rpcclient.RpcClientConne
 	for _, cdrcCfg = range cdrcCfgs { // Take the first config out, does not matter which one
 		break
 	}
-	cdrsConn, err := engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
-		cdrcCfg.CdrsConns, internalCdrSChan, cfg.InternalTtl)
+	connAttempts, reconnects, connectTimeout, replyTimeout, internalTTL := cfg.RPCConnCfg() // restarted on reloads
+	cdrsConn, err := engine.NewRPCPool(rpcclient.POOL_FIRST, connAttempts, reconnects, connectTimeout, replyTimeout,
+		cdrcCfg.CdrsConns, internalCdrSChan, internalTTL)
 	if err != nil {
 		utils.Logger.Crit(fmt.Sprintf("<CDRC> Could not connect to CDRS via RPC: %s", err.Error()))
 		exitChan <- true
 		return
 	}
+	defer cdrsConn.Close() // the CDRCs are stopped on reloads, the next ones connect again
 	cdrc, err := cdrc.NewCdrc(cdrcCfgs, httpSkipTlsCheck, cdrsConn, closeChan, cfg.DefaultTimezone, cfg.RoundingDecimals)
 	if err != nil {
 		utils.Logger.Crit(fmt.Sprintf("Cdrc config parsing error: %s", err.Error()))
@@ -131,9 +137,10 @@ func startCdrc(internalCdrSChan, internalRaterChan chan rpcclient.RpcClientConne
 	}
 }
 
-func startSmGeneric(internalSMGChan chan *sessionmanager.SMGeneric, internalRaterChan, internalCDRSChan chan rpcclient.RpcClientConnection, server *utils.Server, exitChan chan bool) {
+func startSmGeneric(internalSMGChan chan *sessionmanager.SMGeneric, internalRaterChan, internalCDRSChan chan rpcclient.RpcClientConnection,
+	dm *engine.DataManager, server *utils.Server, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS SMGeneric service.")
-	var ralsConns, cdrsConn *rpcclient.RpcClientPool
+	var ralsConns, cdrsConn *engine.RPCPool
 	if len(cfg.SmGenericConfig.RALsConns) != 0 {
 		ralsConns, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
 			cfg.SmGenericConfig.RALsConns, internalRaterChan, cfg.InternalTtl)
@@ -158,10 +165,11 @@ func startSmGeneric(internalSMGChan chan *sessionmanager.SMGeneric, internalRate
 		exitChan <- true
 		return
 	}
-	sm := sessionmanager.NewSMGeneric(cfg, ralsConns, cdrsConn, smgReplConns, cfg.DefaultTimezone)
+	sm := sessionmanager.NewSMGeneric(cfg, ralsConns, cdrsConn, smgReplConns, dm, cfg.DefaultTimezone)
 	if err = sm.Connect(); err != nil {
 		utils.Logger.Err(fmt.Sprintf("<SMGeneric> error: %s!", err))
 	}
+	utils.Metrics.RegisterCollector(utils.SMG, sm)
 	// Pass internal connection via BiRPCClient
 	internalSMGChan <- sm
 	// Register RPC handler
@@ -169,20 +177,28 @@ func startSmGeneric(internalSMGChan chan *sessionmanager.SMGeneric, internalRate
 	server.RpcRegister(smgRpc)
 	server.RpcRegister(&v2.SMGenericV2{*smgRpc})
 	// Register BiRpc handlers
-	if cfg.SmGenericConfig.ListenBijson != "" {
+	if cfg.SmGenericConfig.ListenBijson != "" || cfg.SmGenericConfig.ListenBijsonTLS != "" {
 		smgBiRpc := v1.NewSMGenericBiRpcV1(sm)
 		for method, handler := range smgBiRpc.Handlers() {
 			server.BiRPCRegisterName(method, handler)
 		}
-		server.ServeBiJSON(cfg.SmGenericConfig.ListenBijson)
-		exitChan <- true
+		if cfg.SmGenericConfig.ListenBijsonTLS != "" {
+			go func() {
+				server.ServeBiJSONTLS(cfg.SmGenericConfig.ListenBijsonTLS, serverTLSConfig())
+				exitChan <- true
+			}()
+		}
+		if cfg.SmGenericConfig.ListenBijson != "" {
+			server.ServeBiJSON(cfg.SmGenericConfig.ListenBijson)
+			exitChan <- true
+		}
 	}
 }
 
 func startSMAsterisk(internalSMGChan chan *sessionmanager.SMGeneric, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS SMAsterisk service.")
 	/*
-		var smgConn *rpcclient.RpcClientPool
+		var smgConn *engine.RPCPool
 		if len(cfg.SMAsteriskCfg().SMGConns) != 0 {
 			smgConn, err = engine.NewRPCPool(rpcclient.POOL_BROADCAST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
 				cfg.SMAsteriskCfg().SMGConns, internalSMGChan, cfg.InternalTtl)
@@ -212,18 +228,21 @@ func startSMAsterisk(internalSMGChan chan *sessionmanager.SMGeneric, exitChan ch
 
 func startDiameterAgent(internalSMGChan chan *sessionmanager.SMGeneric, internalPubSubSChan chan rpcclient.RpcClientConnection, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS DiameterAgent service")
-	smgChan := make(chan rpcclient.RpcClientConnection, 1) // Use it to pass smg
+	smgChan := make(chan rpcclient.RpcClientConnection, 1)    // Use it to pass smg
+	birpcClntChan := make(chan *utils.BiRPCInternalClient, 1) // so we can pass the DiameterAgent back to smg
 	go func(internalSMGChan chan *sessionmanager.SMGeneric, smgChan chan rpcclient.RpcClientConnection) {
 		// Need this to pass from *sessionmanager.SMGeneric to rpcclient.RpcClientConnection
 		smg := <-internalSMGChan
 		internalSMGChan <- smg
-		smgChan <- smg
+		birpcClnt := utils.NewBiRPCInternalClient(smg)
+		smgChan <- birpcClnt
+		birpcClntChan <- birpcClnt
 	}(internalSMGChan, smgChan)
-	var smgConn, pubsubConn *rpcclient.RpcClientPool
-
+	var smgConn, pubsubConn *engine.RPCPool
+	daChan := make(chan rpcclient.RpcClientConnection, 1) // so the remote SMGs can reach the DiameterAgent back
 	if len(cfg.DiameterAgentCfg().SMGenericConns) != 0 {
-		smgConn, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
-			cfg.DiameterAgentCfg().SMGenericConns, smgChan, cfg.InternalTtl)
+		smgConn, err = engine.NewBiRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+			cfg.DiameterAgentCfg().SMGenericConns, smgChan, cfg.InternalTtl, agentBiRPCHandlers(daChan))
 		if err != nil {
 			utils.Logger.Crit(fmt.Sprintf("<DiameterAgent> Could not connect to SMG: %s", err.Error()))
 			exitChan <- true
@@ -245,25 +264,53 @@ func startDiameterAgent(internalSMGChan chan *sessionmanager.SMGeneric, internal
 		exitChan <- true
 		return
 	}
+	daChan <- da // RAR/ASR out of the SMGs connected over *bijson
+	for _, smgCfg := range cfg.DiameterAgentCfg().SMGenericConns {
+		if smgCfg.Address == utils.MetaInternal {
+			(<-birpcClntChan).SetClientConn(da)
+			break
+		}
+	}
 	if err = da.ListenAndServe(); err != nil {
 		utils.Logger.Err(fmt.Sprintf("<DiameterAgent> error: %s!", err))
 	}
 	exitChan <- true
 }
 
+// agentBiRPCHandlers serve the SMGs calling back over *bijson connections, forwarding to the agent out of agentChan
+func agentBiRPCHandlers(agentChan chan rpcclient.RpcClientConnection) map[string]interface{} {
+	forward := func(method string, args interface{}, reply *string) error {
+		agent := <-agentChan
+		agentChan <- agent
+		return agent.Call(method, args, reply)
+	}
+	return map[string]interface{}{
+		"SMGClientV1.DisconnectSession": func(clnt *rpc2.Client, args utils.AttrDisconnectSession, reply *string) error {
+			return forward("SMGClientV1.DisconnectSession", args, reply)
+		},
+		"SMGClientV1.ReAuthorizeSession": func(clnt *rpc2.Client, args utils.AttrReAuthorizeSession, reply *string) error {
+			return forward("SMGClientV1.ReAuthorizeSession", args, reply)
+		},
+	}
+}
+
 func startRadiusAgent(internalSMGChan chan *sessionmanager.SMGeneric, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS RadiusAgent service")
-	smgChan := make(chan rpcclient.RpcClientConnection, 1) // Use it to pass smg
+	smgChan := make(chan rpcclient.RpcClientConnection, 1)    // Use it to pass smg
+	birpcClntChan := make(chan *utils.BiRPCInternalClient, 1) // so we can pass the RadiusAgent back to smg
 	go func(internalSMGChan chan *sessionmanager.SMGeneric, smgChan chan rpcclient.RpcClientConnection) {
 		// Need this to pass from *sessionmanager.SMGeneric to rpcclient.RpcClientConnection
 		smg := <-internalSMGChan
 		internalSMGChan <- smg
-		smgChan <- smg
+		birpcClnt := utils.NewBiRPCInternalClient(smg)
+		smgChan <- birpcClnt
+		birpcClntChan <- birpcClnt
 	}(internalSMGChan, smgChan)
-	var smgConn *rpcclient.RpcClientPool
+	var smgConn *engine.RPCPool
+	raChan := make(chan rpcclient.RpcClientConnection, 1) // so the remote SMGs can reach the RadiusAgent back
 	if len(cfg.RadiusAgentCfg().SMGenericConns) != 0 {
-		smgConn, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
-			cfg.RadiusAgentCfg().SMGenericConns, smgChan, cfg.InternalTtl)
+		smgConn, err = engine.NewBiRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+			cfg.RadiusAgentCfg().SMGenericConns, smgChan, cfg.InternalTtl, agentBiRPCHandlers(raChan))
 		if err != nil {
 			utils.Logger.Crit(fmt.Sprintf("<RadiusAgent> Could not connect to SMG: %s", err.Error()))
 			exitChan <- true
@@ -276,6 +323,13 @@ func startRadiusAgent(internalSMGChan chan *sessionmanager.SMGeneric, exitChan c
 		exitChan <- true
 		return
 	}
+	raChan <- ra // CoA/Disconnect requests out of the SMGs connected over *bijson
+	for _, smgCfg := range cfg.RadiusAgentCfg().SMGenericConns {
+		if smgCfg.Address == utils.MetaInternal {
+			(<-birpcClntChan).SetClientConn(ra)
+			break
+		}
+	}
 	if err = ra.ListenAndServe(); err != nil {
 		utils.Logger.Err(fmt.Sprintf("<RadiusAgent> error: <%s>", err.Error()))
 	}
@@ -284,7 +338,7 @@ func startRadiusAgent(internalSMGChan chan *sessionmanager.SMGeneric, exitChan c
 
 func startSmFreeSWITCH(internalRaterChan, internalCDRSChan, rlsChan chan rpcclient.RpcClientConnection, cdrDb engine.CdrStorage, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS SMFreeSWITCH service")
-	var ralsConn, cdrsConn, rlsConn *rpcclient.RpcClientPool
+	var ralsConn, cdrsConn, rlsConn *engine.RPCPool
 	if len(cfg.SmFsConfig.RALsConns) != 0 {
 		ralsConn, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
 			cfg.SmFsConfig.RALsConns, internalRaterChan, cfg.InternalTtl)
@@ -322,7 +376,7 @@ func startSmFreeSWITCH(internalRaterChan, internalCDRSChan, rlsChan chan rpcclie
 
 func startSmKamailio(internalRaterChan, internalCDRSChan, internalRsChan chan rpcclient.RpcClientConnection, cdrDb engine.CdrStorage, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS SMKamailio service.")
-	var ralsConn, cdrsConn, rlSConn *rpcclient.RpcClientPool
+	var ralsConn, cdrsConn, rlSConn *engine.RPCPool
 	if len(cfg.SmKamConfig.RALsConns) != 0 {
 		ralsConn, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
 			cfg.SmKamConfig.RALsConns, internalRaterChan, cfg.InternalTtl)
@@ -360,7 +414,7 @@ func startSmKamailio(internalRaterChan, internalCDRSChan, internalRsChan chan rp
 
 func startSmOpenSIPS(internalRaterChan, internalCDRSChan chan rpcclient.RpcClientConnection, cdrDb engine.CdrStorage, exitChan chan bool) {
 	utils.Logger.Info("Starting CGRateS SMOpenSIPS service.")
-	var ralsConn, cdrsConn *rpcclient.RpcClientPool
+	var ralsConn, cdrsConn *engine.RPCPool
 	if len(cfg.SmOsipsConfig.RALsConns) != 0 {
 		ralsConn, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
 			cfg.SmOsipsConfig.RALsConns, internalRaterChan, cfg.InternalTtl)
@@ -391,9 +445,11 @@ func startCDRS(internalCdrSChan chan rpcclient.RpcClientConnection,
 	cdrDb engine.CdrStorage, dm *engine.DataManager,
 	internalRaterChan, internalPubSubSChan, internalUserSChan, internalAliaseSChan,
 	internalCdrStatSChan, internalThresholdSChan, internalStatSChan chan rpcclient.RpcClientConnection,
-	server *utils.Server, exitChan chan bool) {
+	server *utils.Server, exitChan chan bool, filterSChan chan *engine.FilterS) {
+	filterS := <-filterSChan
+	filterSChan <- filterS
 	utils.Logger.Info("Starting CGRateS CDRS service.")
-	var ralConn, pubSubConn, usersConn, aliasesConn, cdrstatsConn, thresholdSConn, statsConn *rpcclient.RpcClientPool
+	var ralConn, pubSubConn, usersConn, aliasesConn, cdrstatsConn, thresholdSConn, statsConn *engine.RPCPool
 	if len(cfg.CDRSRaterConns) != 0 { // Conn pool towards RAL
 		ralConn, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
 			cfg.CDRSRaterConns, internalRaterChan, cfg.InternalTtl)
@@ -458,8 +514,16 @@ func startCDRS(internalCdrSChan chan rpcclient.RpcClientConnection,
 		}
 	}
 	cdrServer, _ := engine.NewCdrServer(cfg, cdrDb, dm, ralConn, pubSubConn,
-		usersConn, aliasesConn, cdrstatsConn, thresholdSConn, statsConn)
+		usersConn, aliasesConn, cdrstatsConn, thresholdSConn, statsConn, filterS)
 	cdrServer.SetTimeToLive(cfg.ResponseCacheTTL, nil)
+	go func() {
+		if err := cdrServer.ListenAndServe(exitChan); err != nil {
+			utils.Logger.Crit(fmt.Sprintf("<CDRS> Error: %s listening for packets", err.Error()))
+		}
+		cdrServer.Shutdown()
+		exitChan <- true
+		return
+	}()
 	utils.Logger.Info("Registering CDRS HTTP Handlers.")
 	cdrServer.RegisterHandlersToServer(server)
 	utils.Logger.Info("Registering CDRS RPC service.")
@@ -477,6 +541,7 @@ func startScheduler(internalSchedulerChan chan *scheduler.Scheduler, cacheDoneCh
 	cacheDoneChan <- cacheDone
 	utils.Logger.Info("Starting CGRateS Scheduler.")
 	sched := scheduler.NewScheduler(dm)
+	utils.Metrics.RegisterCollector(utils.SchedulerS, sched)
 	internalSchedulerChan <- sched
 
 	sched.Loop()
@@ -538,7 +603,7 @@ func startUsersServer(internalUserSChan chan rpcclient.RpcClientConnection, dm *
 
 func startResourceService(internalRsChan, internalThresholdSChan chan rpcclient.RpcClientConnection, cfg *config.CGRConfig,
 	dm *engine.DataManager, server *utils.Server, exitChan chan bool, filterSChan chan *engine.FilterS) {
-	var thdSConn *rpcclient.RpcClientPool
+	var thdSConn *engine.RPCPool
 	filterS := <-filterSChan
 	filterSChan <- filterS
 	if len(cfg.ResourceSCfg().ThresholdSConns) != 0 { // Stats connection init
@@ -566,6 +631,7 @@ func startResourceService(internalRsChan, internalThresholdSChan chan rpcclient.
 		exitChan <- true
 		return
 	}()
+	utils.Metrics.RegisterCollector(utils.ResourceS, rS)
 	rsV1 := v1.NewResourceSV1(rS)
 	server.RpcRegister(rsV1)
 	internalRsChan <- rsV1
@@ -574,7 +640,7 @@ func startResourceService(internalRsChan, internalThresholdSChan chan rpcclient.
 // startStatService fires up the StatS
 func startStatService(internalStatSChan, internalThresholdSChan chan rpcclient.RpcClientConnection, cfg *config.CGRConfig,
 	dm *engine.DataManager, server *utils.Server, exitChan chan bool, filterSChan chan *engine.FilterS) {
-	var thdSConn *rpcclient.RpcClientPool
+	var thdSConn *engine.RPCPool
 	filterS := <-filterSChan
 	filterSChan <- filterS
 	if len(cfg.StatSCfg().ThresholdSConns) != 0 { // Stats connection init
@@ -601,6 +667,8 @@ func startStatService(internalStatSChan, internalThresholdSChan chan rpcclient.R
 		exitChan <- true
 		return
 	}()
+	cfg.AddReloadHook(config.STATS_JSON, func() { sS.SetIndexedFields(cfg.StatSCfg().IndexedFields) })
+	utils.Metrics.RegisterCollector(utils.StatService, sS)
 	stsV1 := v1.NewStatSV1(sS)
 	server.RpcRegister(stsV1)
 	internalStatSChan <- stsV1
@@ -627,11 +695,123 @@ func startThresholdService(internalThresholdSChan chan rpcclient.RpcClientConnec
 		exitChan <- true
 		return
 	}()
+	cfg.AddReloadHook(config.THRESHOLDS_JSON, func() { tS.SetIndexedFields(cfg.ThresholdSCfg().IndexedFields) })
+	utils.Metrics.RegisterCollector(utils.ThresholdS, tS)
 	tSv1 := v1.NewThresholdSV1(tS)
 	server.RpcRegister(tSv1)
 	internalThresholdSChan <- tSv1
 }
 
+// startSupplierService fires up the SupplierS
+func startSupplierService(internalSupplierSChan, internalRaterChan, internalRsChan, internalStatSChan chan rpcclient.RpcClientConnection,
+	cfg *config.CGRConfig, dm *engine.DataManager, server *utils.Server, exitChan chan bool, filterSChan chan *engine.FilterS) {
+	filterS := <-filterSChan
+	filterSChan <- filterS
+	var ralsConns, resourceSConn, statSConn *engine.RPCPool
+	if len(cfg.SupplierSCfg().RALsConns) != 0 {
+		ralsConns, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+			cfg.SupplierSCfg().RALsConns, internalRaterChan, cfg.InternalTtl)
+		if err != nil {
+			utils.Logger.Crit(fmt.Sprintf("<SupplierS> Could not connect to RALs: %s", err.Error()))
+			exitChan <- true
+			return
+		}
+	}
+	if len(cfg.SupplierSCfg().ResourceSConns) != 0 {
+		resourceSConn, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+			cfg.SupplierSCfg().ResourceSConns, internalRsChan, cfg.InternalTtl)
+		if err != nil {
+			utils.Logger.Crit(fmt.Sprintf("<SupplierS> Could not connect to ResourceS: %s", err.Error()))
+			exitChan <- true
+			return
+		}
+	}
+	if len(cfg.SupplierSCfg().StatSConns) != 0 {
+		statSConn, err = engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+			cfg.SupplierSCfg().StatSConns, internalStatSChan, cfg.InternalTtl)
+		if err != nil {
+			utils.Logger.Crit(fmt.Sprintf("<SupplierS> Could not connect to StatS: %s", err.Error()))
+			exitChan <- true
+			return
+		}
+	}
+	splS, err := engine.NewSupplierService(dm, filterS, cfg.SupplierSCfg().IndexedFields,
+		ralsConns, resourceSConn, statSConn)
+	if err != nil {
+		utils.Logger.Crit(fmt.Sprintf("<SupplierS> Could not init, error: %s", err.Error()))
+		exitChan <- true
+		return
+	}
+	utils.Logger.Info(fmt.Sprintf("Starting Supplier Service"))
+	go func() {
+		if err := splS.ListenAndServe(exitChan); err != nil {
+			utils.Logger.Crit(fmt.Sprintf("<SupplierS> Error: %s listening for packets", err.Error()))
+		}
+		splS.Shutdown()
+		exitChan <- true
+		return
+	}()
+	splV1 := v1.NewSupplierSv1(splS)
+	server.RpcRegister(splV1)
+	internalSupplierSChan <- splV1
+}
+
+// startInvoiceService fires up the InvoiceS
+func startInvoiceService(cfg *config.CGRConfig, dm *engine.DataManager, cdrDb engine.CdrStorage,
+	server *utils.Server, exitChan chan bool, filterSChan chan *engine.FilterS) {
+	filterS := <-filterSChan
+	filterSChan <- filterS
+	iS := engine.NewInvoiceService(cfg, dm, cdrDb, filterS)
+	utils.Logger.Info("Starting Invoice Service")
+	go func() {
+		if err := iS.ListenAndServe(exitChan); err != nil {
+			utils.Logger.Crit(fmt.Sprintf("<InvoiceS> Error: %s listening for packets", err.Error()))
+		}
+		iS.Shutdown()
+		exitChan <- true
+		return
+	}()
+	server.RpcRegister(v1.NewInvoiceSv1(iS))
+}
+
+// cacheReplConns are the connections of the cache replication, closed when replaced on reloads
+var cacheReplConns []rpcclient.RpcClientConnection
+
+// initCacheReplication connects to the engines receiving our cache invalidations, replacing the previous connections
+// connections are lazy since the peers are usually starting in the same time with us
+func initCacheReplication(cfg *config.CGRConfig) error {
+	connAttempts, reconnects, connectTimeout, replyTimeout, _ := cfg.RPCConnCfg()
+	replPool := rpcclient.NewRpcClientPool(rpcclient.POOL_BROADCAST, replyTimeout)
+	var replConns []rpcclient.RpcClientConnection
+	for _, connCfg := range cfg.CacheReplicationConns {
+		replConn, err := engine.NewRemoteRPCClient(connCfg, connAttempts, reconnects,
+			connectTimeout, replyTimeout, true)
+		if err != nil {
+			closeRPCConns(replConns)
+			return err
+		}
+		replPool.AddClient(replConn)
+		replConns = append(replConns, replConn)
+	}
+	if len(replConns) == 0 {
+		cache.SetReplicator(nil)
+	} else {
+		cache.SetReplicator(replPool)
+	}
+	closeRPCConns(cacheReplConns)
+	cacheReplConns = replConns
+	return nil
+}
+
+// closeRPCConns drops the remote connections out of engine.NewRemoteRPCClient
+func closeRPCConns(conns []rpcclient.RpcClientConnection) {
+	for _, conn := range conns {
+		if rmtConn, isRemote := conn.(*utils.RemoteRPCClient); isRemote {
+			rmtConn.Close()
+		}
+	}
+}
+
 // startFilterService fires up the FilterS
 func startFilterService(filterSChan chan *engine.FilterS,
 	internalStatSChan chan rpcclient.RpcClientConnection, cfg *config.CGRConfig,
@@ -672,9 +852,41 @@ func startRpc(server *utils.Server, internalRaterChan,
 		cfg.HTTPListen,
 		cfg.HTTPJsonRPCURL,
 		cfg.HTTPWSURL,
+		cfg.HTTPMetricsURL,
 		cfg.HTTPUseBasicAuth,
 		cfg.HTTPAuthUsers,
 	)
+	if cfg.RPCJSONTLSListen == "" && cfg.RPCGOBTLSListen == "" && cfg.HTTPTLSListen == "" {
+		return
+	}
+	tlsCfg := serverTLSConfig()
+	if cfg.RPCJSONTLSListen != "" {
+		go server.ServeJSONTLS(cfg.RPCJSONTLSListen, tlsCfg)
+	}
+	if cfg.RPCGOBTLSListen != "" {
+		go server.ServeGOBTLS(cfg.RPCGOBTLSListen, tlsCfg)
+	}
+	if cfg.HTTPTLSListen != "" {
+		go server.ServeHTTPTLS(
+			cfg.HTTPTLSListen,
+			tlsCfg,
+			cfg.HTTPJsonRPCURL,
+			cfg.HTTPWSURL,
+			cfg.HTTPMetricsURL,
+			cfg.HTTPUseBasicAuth,
+			cfg.HTTPAuthUsers,
+		)
+	}
+}
+
+// serverTLSConfig loads the certificates of the TLS listeners, exiting on errors
+func serverTLSConfig() *tls.Config {
+	tlsCfg, err := utils.NewServerTLSConfig(cfg.TLSCertificate, cfg.TLSKey,
+		cfg.TLSCACertificate, cfg.TLSClientAuth)
+	if err != nil {
+		log.Fatalf("Could not load the TLS certificates, error: %s", err.Error())
+	}
+	return tlsCfg
 }
 
 func writePid() {
@@ -702,6 +914,18 @@ func initLogger(cfg *config.CGRConfig) error {
 	return nil
 }
 
+// reloadConfigOnSIGHUP reloads the configuration out of the config_dir each time SIGHUP is received
+func reloadConfigOnSIGHUP(cfgSv1 *v1.ConfigSv1) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	for range sigChan {
+		var rpl config.ConfigReloadReport
+		if err := cfgSv1.ReloadConfig(v1.AttrReloadConfig{}, &rpl); err != nil {
+			utils.Logger.Err(fmt.Sprintf("<ConfigS> Could not reload configuration on SIGHUP, error: %s", err.Error()))
+		}
+	}
+}
+
 func main() {
 	flag.Parse()
 	if *version {
@@ -752,15 +976,22 @@ func main() {
 		lgLevel = *logLevel
 	}
 	utils.Logger.SetLogLevel(lgLevel)
+	if *logLevel == -1 { // the command argument has precedence over reloads
+		cfg.AddReloadHook(config.GENERAL_JSN, func() { utils.Logger.SetLogLevel(cfg.LogLevel) })
+	}
 
 	// Init cache
 	cache.NewCache(cfg.CacheConfig)
+	cfg.AddReloadHook(config.CACHE_JSN, func() { cache.Reconfigure(cfg.CacheConfig) })
+	utils.Metrics.RegisterCollector(utils.Cache, utils.MetricsCollectorFunc(cache.Metrics))
 
 	var loadDb engine.LoadStorage
 	var cdrDb engine.CdrStorage
 	var dm *engine.DataManager
 
-	if cfg.RALsEnabled || cfg.CDRStatsEnabled || cfg.PubSubServerEnabled || cfg.AliasesServerEnabled || cfg.UserServerEnabled || cfg.SchedulerEnabled {
+	if cfg.RALsEnabled || cfg.CDRStatsEnabled || cfg.PubSubServerEnabled || cfg.AliasesServerEnabled || cfg.UserServerEnabled || cfg.SchedulerEnabled ||
+		cfg.InvoiceSCfg().Enabled || cfg.APIAuthCfg().Enabled ||
+		cfg.SmGenericConfig.StoreSessions {
 		dm, err = engine.ConfigureDataStorage(cfg.DataDbType, cfg.DataDbHost, cfg.DataDbPort,
 			cfg.DataDbName, cfg.DataDbUser, cfg.DataDbPass, cfg.DBDataEncoding, cfg.CacheConfig, cfg.LoadHistorySize)
 		if err != nil { // Cannot configure getter database, show stopper
@@ -774,7 +1005,7 @@ func main() {
 			return
 		}
 	}
-	if cfg.RALsEnabled || cfg.CDRSEnabled || cfg.SchedulerEnabled { // Only connect to storDb if necessary
+	if cfg.RALsEnabled || cfg.CDRSEnabled || cfg.SchedulerEnabled || cfg.InvoiceSCfg().Enabled { // Only connect to storDb if necessary
 		storDb, err := engine.ConfigureStorStorage(cfg.StorDBType, cfg.StorDBHost, cfg.StorDBPort,
 			cfg.StorDBName, cfg.StorDBUser, cfg.StorDBPass, cfg.DBDataEncoding, cfg.StorDBMaxOpenConns, cfg.StorDBMaxIdleConns, cfg.StorDBConnMaxLifetime, cfg.StorDBCDRSIndexes)
 		if err != nil { // Cannot configure logger database, show stopper
@@ -801,6 +1032,40 @@ func main() {
 	// Rpc/http server
 	server := new(utils.Server)
 
+	if cfg.APIAuthCfg().Enabled { // before starting the services so no request escapes the checks
+		apiAuth := engine.NewAPIAuthorizer(dm, cfg.DefaultTenant)
+		if cfg.APIAuthCfg().AdminKey != "" {
+			if err := apiAuth.SetAdminKey(cfg.APIAuthCfg().AdminKey); err != nil {
+				utils.Logger.Crit(fmt.Sprintf("<APIAuth> could not set the admin key: %s exiting!", err))
+				return
+			}
+		}
+		utils.SetRPCAuthorizer(apiAuth)
+		authV1 := v1.NewAuthV1(apiAuth)
+		server.RpcRegister(authV1)
+		server.BiRPCRegisterName(utils.AuthV1Authenticate, authV1.BiRPCv1Authenticate)
+	}
+
+	// Configuration reloads over API and SIGHUP
+	cfgSv1 := v1.NewConfigSv1(cfg, *cfgDir)
+	server.RpcRegister(cfgSv1)
+	go reloadConfigOnSIGHUP(cfgSv1)
+
+	// Cache invalidations between engines sharing the DataDB
+	server.RpcRegister(v1.NewCacheSv1())
+	if len(cfg.CacheReplicationConns) != 0 {
+		if err = initCacheReplication(cfg); err != nil {
+			utils.Logger.Crit(fmt.Sprintf("<Cache> Could not init replication, error: %s", err.Error()))
+			return
+		}
+	}
+	cfg.AddReloadHook(config.GENERAL_JSN, func() {
+		engine.ReloadRPCPools(cfg)
+		if err := initCacheReplication(cfg); err != nil {
+			utils.Logger.Err(fmt.Sprintf("<Cache> Keeping the previous replication, error: %s", err.Error()))
+		}
+	})
+
 	// Async starts here, will follow cgrates.json start order
 
 	// Define internal connections via channels
@@ -816,6 +1081,7 @@ func main() {
 	internalRsChan := make(chan rpcclient.RpcClientConnection, 1)
 	internalStatSChan := make(chan rpcclient.RpcClientConnection, 1)
 	internalThresholdSChan := make(chan rpcclient.RpcClientConnection, 1)
+	internalSupplierSChan := make(chan rpcclient.RpcClientConnection, 1)
 	filterSChan := make(chan *engine.FilterS, 1)
 
 	// Start ServiceManager
@@ -824,7 +1090,7 @@ func main() {
 	// Start rater service
 	if cfg.RALsEnabled {
 		go startRater(internalRaterChan, cacheDoneChan, internalThresholdSChan,
-			internalCdrStatSChan, internalStatSChan,
+			internalCdrStatSChan, internalStatSChan, internalRsChan,
 			internalHistorySChan, internalPubSubSChan, internalUserSChan, internalAliaseSChan,
 			srvManager, server, dm, loadDb, cdrDb, &stopHandled, exitChan)
 	}
@@ -838,7 +1104,7 @@ func main() {
 	if cfg.CDRSEnabled {
 		go startCDRS(internalCdrSChan, cdrDb, dm,
 			internalRaterChan, internalPubSubSChan, internalUserSChan, internalAliaseSChan,
-			internalCdrStatSChan, internalThresholdSChan, internalStatSChan, server, exitChan)
+			internalCdrStatSChan, internalThresholdSChan, internalStatSChan, server, exitChan, filterSChan)
 	}
 
 	// Start CDR Stats server
@@ -851,7 +1117,7 @@ func main() {
 
 	// Start SM-Generic
 	if cfg.SmGenericConfig.Enabled {
-		go startSmGeneric(internalSMGChan, internalRaterChan, internalCdrSChan, server, exitChan)
+		go startSmGeneric(internalSMGChan, internalRaterChan, internalCdrSChan, dm, server, exitChan)
 	}
 	// Start SM-FreeSWITCH
 	if cfg.SmFsConfig.Enabled {
@@ -924,6 +1190,15 @@ func main() {
 		go startThresholdService(internalThresholdSChan, cfg, dm, server, exitChan, filterSChan)
 	}
 
+	if cfg.SupplierSCfg().Enabled {
+		go startSupplierService(internalSupplierSChan, internalRaterChan, internalRsChan,
+			internalStatSChan, cfg, dm, server, exitChan, filterSChan)
+	}
+
+	if cfg.InvoiceSCfg().Enabled {
+		go startInvoiceService(cfg, dm, cdrDb, server, exitChan, filterSChan)
+	}
+
 	// Serve rpc connections
 	go startRpc(server, internalRaterChan, internalCdrSChan, internalCdrStatSChan, internalHistorySChan,
 		internalPubSubSChan, internalUserSChan, internalAliaseSChan, internalRsChan, internalStatSChan, internalSMGChan)
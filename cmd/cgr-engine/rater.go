@@ -19,6 +19,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package main
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/cgrates/cgrates/apier/v1"
@@ -32,7 +33,7 @@ import (
 
 // Starts rater and reports on chan
 func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneChan chan struct{},
-	internalThdSChan, internalCdrStatSChan, internalStatSChan, internalHistorySChan,
+	internalThdSChan, internalCdrStatSChan, internalStatSChan, internalRsChan, internalHistorySChan,
 	internalPubSubSChan, internalUserSChan, internalAliaseSChan chan rpcclient.RpcClientConnection,
 	serviceManager *servmanager.ServiceManager, server *utils.Server,
 	dm *engine.DataManager, loadDb engine.LoadStorage, cdrDb engine.CdrStorage, stopHandled *bool, exitChan chan bool) {
@@ -114,7 +115,7 @@ func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneC
 		cacheDoneChan <- struct{}{}
 	}()
 
-	var thdS *rpcclient.RpcClientPool
+	var thdS *engine.RPCPool
 	if len(cfg.RALsThresholdSConns) != 0 { // Connections to ThresholdS
 		thdsTaskChan := make(chan struct{})
 		waitTasks = append(waitTasks, thdsTaskChan)
@@ -130,7 +131,7 @@ func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneC
 		}()
 	}
 
-	var cdrStats *rpcclient.RpcClientPool
+	var cdrStats *engine.RPCPool
 	if len(cfg.RALsCDRStatSConns) != 0 { // Connections to CDRStats
 		cdrstatTaskChan := make(chan struct{})
 		waitTasks = append(waitTasks, cdrstatTaskChan)
@@ -146,7 +147,7 @@ func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneC
 		}()
 	}
 
-	var stats *rpcclient.RpcClientPool
+	var stats *engine.RPCPool
 	if len(cfg.RALsStatSConns) != 0 { // Connections to CDRStats
 		statsTaskChan := make(chan struct{})
 		waitTasks = append(waitTasks, statsTaskChan)
@@ -225,6 +226,37 @@ func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneC
 			engine.SetUserService(usersConns)
 		}()
 	}
+	if len(cfg.RALsResourceSConns) != 0 { // Connection to ResourceS
+		resTaskChan := make(chan struct{})
+		waitTasks = append(waitTasks, resTaskChan)
+		go func() {
+			defer close(resTaskChan)
+			if resSConns, err := engine.NewRPCPool(rpcclient.POOL_FIRST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+				cfg.RALsResourceSConns, internalRsChan, cfg.InternalTtl); err != nil {
+				utils.Logger.Crit(fmt.Sprintf("<RALs> Could not connect to ResourceS, error: %s", err.Error()))
+				exitChan <- true
+				return
+			} else {
+				engine.SetResourceS(resSConns)
+			}
+		}()
+	}
+
+	if len(cfg.RALsLCRReplicationConns) != 0 { // Connection to the RALs sharing the LCR counters
+		lcrReplTaskChan := make(chan struct{})
+		waitTasks = append(waitTasks, lcrReplTaskChan)
+		go func() {
+			defer close(lcrReplTaskChan)
+			if lcrReplConns, err := engine.NewRPCPool(rpcclient.POOL_BROADCAST, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout, cfg.ReplyTimeout,
+				cfg.RALsLCRReplicationConns, nil, cfg.InternalTtl); err != nil {
+				utils.Logger.Crit(fmt.Sprintf("<RALs> Could not connect to LCR replication RALs, error: %s", err.Error()))
+				exitChan <- true
+				return
+			} else {
+				engine.SetLCRReplication(lcrReplConns)
+			}
+		}()
+	}
 	// Wait for all connections to complete before going further
 	for _, chn := range waitTasks {
 		<-chn
@@ -237,6 +269,17 @@ func startRater(internalRaterChan chan rpcclient.RpcClientConnection, cacheDoneC
 	if thdS != nil {
 		engine.SetThresholdS(thdS) // temporary architectural fix until we will have separate AccountS
 	}
+	if stats != nil {
+		engine.SetStatS(stats) // used by *reset_stat_queue action
+	}
+	engine.SetSchedulerReload(func() error { // used by *set_account_action_plan action
+		sched := serviceManager.GetScheduler()
+		if sched == nil {
+			return errors.New(utils.SchedulerNotRunningCaps)
+		}
+		sched.Reload()
+		return nil
+	})
 	if cdrStats != nil { // ToDo: Fix here properly the init of stats
 		responder.Stats = cdrStats
 		apierRpcV1.CdrStatsSrv = cdrStats
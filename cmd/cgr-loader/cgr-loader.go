@@ -148,6 +148,8 @@ func main() {
 			path.Join(*dataPath, utils.StatsCsv),
 			path.Join(*dataPath, utils.ThresholdsCsv),
 			path.Join(*dataPath, utils.FiltersCsv),
+			path.Join(*dataPath, utils.SuppliersCsv),
+			path.Join(*dataPath, utils.ExchangeRatesCsv),
 		)
 	}
 
@@ -19,6 +19,8 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package config
 
 import (
+	"time"
+
 	"github.com/cgrates/cgrates/utils"
 )
 
@@ -28,11 +30,19 @@ type CdreConfig struct {
 	ExportPath          string
 	FallbackPath        string
 	CDRFilter           utils.RSRFields
+	FilterIDs           []string // FilterS profiles the CDRs need to pass for online exports
+	Ordering            string   // online exports: deliver in order CDRs with the same <""|*account|*cgrid>
 	Synchronous         bool
 	Attempts            int
 	FieldSeparator      rune
 	UsageMultiplyFactor utils.FieldMultiplyFactor
 	CostMultiplyFactor  float64
+	RotateSize          int           // rotate *file_jsonl exports once reaching this size in bytes, 0 to disable
+	RotateInterval      time.Duration // rotate *file_jsonl exports once older than this interval, 0 to disable
+	BatchSize           int           // number of rows inserted at once by *sql_table exports, 0 for all
+	RetryInterval       time.Duration // online exports: first retry of the queued failed CDRs, 0 disables the retry queue
+	RetryMaxInterval    time.Duration // online exports: maximum delay between retries
+	RetryAttempts       int           // online exports: retries before considering the CDR failed, 0 for unlimited
 	HeaderFields        []*CfgCdrField
 	ContentFields       []*CfgCdrField
 	TrailerFields       []*CfgCdrField
@@ -54,6 +64,15 @@ func (self *CdreConfig) loadFromJsonCfg(jsnCfg *CdreJsonCfg) error {
 			return err
 		}
 	}
+	if jsnCfg.Filters != nil {
+		self.FilterIDs = make([]string, len(*jsnCfg.Filters))
+		for i, fltrID := range *jsnCfg.Filters {
+			self.FilterIDs[i] = fltrID
+		}
+	}
+	if jsnCfg.Ordering != nil {
+		self.Ordering = *jsnCfg.Ordering
+	}
 	if jsnCfg.Synchronous != nil {
 		self.Synchronous = *jsnCfg.Synchronous
 	}
@@ -75,6 +94,30 @@ func (self *CdreConfig) loadFromJsonCfg(jsnCfg *CdreJsonCfg) error {
 	if jsnCfg.Cost_multiply_factor != nil {
 		self.CostMultiplyFactor = *jsnCfg.Cost_multiply_factor
 	}
+	if jsnCfg.Rotate_size != nil {
+		self.RotateSize = *jsnCfg.Rotate_size
+	}
+	if jsnCfg.Rotate_interval != nil {
+		if self.RotateInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Rotate_interval); err != nil {
+			return err
+		}
+	}
+	if jsnCfg.Batch_size != nil {
+		self.BatchSize = *jsnCfg.Batch_size
+	}
+	if jsnCfg.Retry_interval != nil {
+		if self.RetryInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Retry_interval); err != nil {
+			return err
+		}
+	}
+	if jsnCfg.Retry_max_interval != nil {
+		if self.RetryMaxInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Retry_max_interval); err != nil {
+			return err
+		}
+	}
+	if jsnCfg.Retry_attempts != nil {
+		self.RetryAttempts = *jsnCfg.Retry_attempts
+	}
 	if jsnCfg.Header_fields != nil {
 		if self.HeaderFields, err = CfgCdrFieldsFromCdrFieldsJsonCfg(*jsnCfg.Header_fields); err != nil {
 			return err
@@ -93,11 +136,23 @@ func (self *CdreConfig) loadFromJsonCfg(jsnCfg *CdreJsonCfg) error {
 	return nil
 }
 
+// RetryQueue returns true if the CDRs failing online export are queued for retries
+func (self *CdreConfig) RetryQueue() bool {
+	return self.RetryInterval > 0
+}
+
 // Clone itself into a new CdreConfig
 func (self *CdreConfig) Clone() *CdreConfig {
 	clnCdre := new(CdreConfig)
 	clnCdre.ExportFormat = self.ExportFormat
 	clnCdre.ExportPath = self.ExportPath
+	if self.FilterIDs != nil {
+		clnCdre.FilterIDs = make([]string, len(self.FilterIDs))
+		for i, fltrID := range self.FilterIDs {
+			clnCdre.FilterIDs[i] = fltrID
+		}
+	}
+	clnCdre.Ordering = self.Ordering
 	clnCdre.Synchronous = self.Synchronous
 	clnCdre.Attempts = self.Attempts
 	clnCdre.FieldSeparator = self.FieldSeparator
@@ -106,6 +161,12 @@ func (self *CdreConfig) Clone() *CdreConfig {
 		clnCdre.UsageMultiplyFactor[k] = v
 	}
 	clnCdre.CostMultiplyFactor = self.CostMultiplyFactor
+	clnCdre.RotateSize = self.RotateSize
+	clnCdre.RotateInterval = self.RotateInterval
+	clnCdre.BatchSize = self.BatchSize
+	clnCdre.RetryInterval = self.RetryInterval
+	clnCdre.RetryMaxInterval = self.RetryMaxInterval
+	clnCdre.RetryAttempts = self.RetryAttempts
 	clnCdre.HeaderFields = make([]*CfgCdrField, len(self.HeaderFields))
 	for idx, fld := range self.HeaderFields {
 		clonedVal := *fld
@@ -20,6 +20,7 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cgrates/cgrates/utils"
 )
@@ -49,6 +50,9 @@ func TestCdreCfgClone(t *testing.T) {
 			utils.DATA: 1024,
 		},
 		CostMultiplyFactor: 1.0,
+		RotateSize:         1048576,
+		RotateInterval:     time.Hour,
+		BatchSize:          50,
 		ContentFields:      initContentFlds,
 	}
 	eClnContentFlds := []*CfgCdrField{
@@ -72,6 +76,9 @@ func TestCdreCfgClone(t *testing.T) {
 			utils.DATA: 1024.0,
 		},
 		CostMultiplyFactor: 1.0,
+		RotateSize:         1048576,
+		RotateInterval:     time.Hour,
+		BatchSize:          50,
 		HeaderFields:       emptyFields,
 		ContentFields:      eClnContentFlds,
 		TrailerFields:      emptyFields,
@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cgrates/cgrates/utils"
@@ -66,6 +67,7 @@ func SetCgrConfig(cfg *CGRConfig) {
 func NewDefaultCGRConfig() (*CGRConfig, error) {
 	cfg := new(CGRConfig)
 	cfg.InstanceID = utils.GenUUID()
+	cfg.instanceIDGenerated = true
 	cfg.DataFolderPath = "/usr/share/cgrates/"
 	cfg.SmGenericConfig = new(SmGenericConfig)
 	cfg.CacheConfig = make(CacheConfig)
@@ -183,6 +185,7 @@ func NewCGRConfigFromFolder(cfgDir string) (*CGRConfig, error) {
 // Holds system configuration, defaults are overwritten with values from config file if found
 type CGRConfig struct {
 	InstanceID               string // Identifier for this engine instance
+	instanceIDGenerated      bool   // InstanceID not configured, changes on each start
 	DataDbType               string
 	DataDbHost               string // The host to connect to. Values that start with / are for UNIX domain sockets.
 	DataDbPort               string // The port to bind to.
@@ -205,8 +208,16 @@ type CGRConfig struct {
 	RPCJSONListen            string            // RPC JSON listening address
 	RPCGOBListen             string            // RPC GOB listening address
 	HTTPListen               string            // HTTP listening address
+	RPCJSONTLSListen         string            // RPC JSON over TLS listening address
+	RPCGOBTLSListen          string            // RPC GOB over TLS listening address
+	HTTPTLSListen            string            // HTTPS listening address
+	TLSCertificate           string            // certificate of the TLS listeners
+	TLSKey                   string            // key of the TLS listeners
+	TLSCACertificate         string            // CA certificate verifying the clients of the TLS listeners
+	TLSClientAuth            string            // client certificate verification of the TLS listeners
 	HTTPJsonRPCURL           string            // JSON RPC relative URL ("" to disable)
 	HTTPWSURL                string            // WebSocket relative URL ("" to disable)
+	HTTPMetricsURL           string            // Prometheus metrics relative URL ("" to disable)
 	HTTPUseBasicAuth         bool              // Use basic auth for HTTP API
 	HTTPAuthUsers            map[string]string // Basic auth user:password map (base64 passwords)
 	DefaultReqType           string            // Use this request type if not defined on top
@@ -218,6 +229,7 @@ type CGRConfig struct {
 	ReplyTimeout             time.Duration     // timeout replies if not reaching back
 	ConnectAttempts          int               // number of initial connection attempts before giving up
 	ResponseCacheTTL         time.Duration     // the life span of a cached response
+	IdempotencyTTL           time.Duration     // retention of the replies to requests carrying an IdempotencyKey
 	InternalTtl              time.Duration     // maximum duration to wait for internal connections before giving up
 	RoundingDecimals         int               // Number of decimals to round end prices at
 	HttpSkipTlsVerify        bool              // If enabled Http Client will accept any TLS certificate
@@ -226,6 +238,10 @@ type CGRConfig struct {
 	FailedPostsDir           string          // Directory path where we store failed http requests
 	MaxCallDuration          time.Duration   // The maximum call duration (used by responder when querying DerivedCharging) // ToDo: export it in configuration file
 	LockingTimeout           time.Duration   // locking mechanism timeout to avoid deadlocks
+	CacheReplicationConns    []*HaPoolConfig // engines receiving the invalidations of the replicated cache partitions
+	TLSClientCertificate     string          // certificate presented by the TLS connections towards other engines
+	TLSClientKey             string          // key of the client certificate
+	TLSClientCACertificate   string          // CA certificate verifying the engines we connect to over TLS
 	Logger                   string          // dictates the way logs are displayed/stored
 	LogLevel                 int             // system wide log level, nothing higher than this will be logged
 	RALsEnabled              bool            // start standalone server (no balancer)
@@ -236,6 +252,8 @@ type CGRConfig struct {
 	RALsPubSubSConns         []*HaPoolConfig
 	RALsUserSConns           []*HaPoolConfig
 	RALsAliasSConns          []*HaPoolConfig
+	RALsResourceSConns       []*HaPoolConfig
+	RALsLCRReplicationConns  []*HaPoolConfig
 	RpSubjectPrefixMatching  bool // enables prefix matching for the rating profile subject
 	LcrSubjectPrefixMatching bool // enables prefix matching for the lcr subject
 	SchedulerEnabled         bool
@@ -274,6 +292,9 @@ type CGRConfig struct {
 	resourceSCfg             *ResourceSConfig         // Configuration for resource limiter
 	statsCfg                 *StatSCfg                // Configuration for StatS
 	thresholdSCfg            *ThresholdSCfg           // configuration for ThresholdS
+	supplierSCfg             *SupplierSCfg            // configuration for SupplierS
+	invoiceSCfg              *InvoiceSCfg             // configuration for InvoiceS
+	apiAuthCfg               *APIAuthCfg              // authorization of the RPC requests
 	MailerServer             string                   // The server to use when sending emails out
 	MailerAuthUser           string                   // Authenticate to email server using this user
 	MailerAuthPass           string                   // Authenticate to email server with this password
@@ -281,12 +302,34 @@ type CGRConfig struct {
 	DataFolderPath           string                   // Path towards data folder, for tests internal usage, not loading out of .json options
 	sureTaxCfg               *SureTaxCfg              // Load here SureTax configuration, as pointer so we can have runtime reloads in the future
 	ConfigReloads            map[string]chan struct{} // Signals to specific entities that a config reload should occur
+	reloadHooks              map[string][]func()      // called per section after the live settings were reloaded
+	reloadMux                sync.Mutex               // serializes the reloads
+	liveMux                  sync.RWMutex             // protects the settings changed by reloads while the services read them
 	// Cache defaults loaded from json and needing clones
 	dfltCdreProfile *CdreConfig // Default cdreConfig profile
 	dfltCdrcProfile *CdrcConfig // Default cdrcConfig profile
 }
 
 func (self *CGRConfig) checkConfigSanity() error {
+	for _, connCfg := range self.CacheReplicationConns {
+		if connCfg.Address == utils.MetaInternal {
+			return errors.New("Unsupported transport *internal for cache replication.")
+		}
+	}
+	// TLS listeners checks
+	if self.RPCJSONTLSListen != "" || self.RPCGOBTLSListen != "" || self.HTTPTLSListen != "" ||
+		self.SmGenericConfig.ListenBijsonTLS != "" {
+		if self.TLSCertificate == "" || self.TLSKey == "" {
+			return errors.New("TLS listeners require tls_certificate and tls_key.")
+		}
+	}
+	if _, has := utils.TLSClientAuthTypes[self.TLSClientAuth]; !has {
+		return fmt.Errorf("Unsupported tls_client_auth: <%s>.", self.TLSClientAuth)
+	}
+	if utils.IsSliceMember([]string{utils.MetaVerifyClientCertIfGiven, utils.MetaRequireAndVerifyCert}, self.TLSClientAuth) &&
+		self.TLSCACertificate == "" {
+		return fmt.Errorf("tls_client_auth: <%s> requires tls_ca_certificate.", self.TLSClientAuth)
+	}
 	// Rater checks
 	if self.RALsEnabled {
 		for _, connCfg := range self.RALsCDRStatSConns {
@@ -324,6 +367,16 @@ func (self *CGRConfig) checkConfigSanity() error {
 				return errors.New("ThresholdS not enabled but requested by RALs component.")
 			}
 		}
+		for _, connCfg := range self.RALsResourceSConns {
+			if connCfg.Address == utils.MetaInternal && !self.resourceSCfg.Enabled {
+				return errors.New("ResourceS not enabled but requested by RALs component.")
+			}
+		}
+		for _, connCfg := range self.RALsLCRReplicationConns {
+			if connCfg.Address == utils.MetaInternal {
+				return errors.New("Unsupported transport *internal for RALs LCR replication.")
+			}
+		}
 	}
 	// CDRServer checks
 	if self.CDRSEnabled {
@@ -358,9 +411,13 @@ func (self *CGRConfig) checkConfigSanity() error {
 			}
 		}
 		for _, cdrePrfl := range self.CDRSOnlineCDRExports {
-			if _, hasIt := self.CdreProfiles[cdrePrfl]; !hasIt {
+			cdreCfg, hasIt := self.CdreProfiles[cdrePrfl]
+			if !hasIt {
 				return fmt.Errorf("<CDRS> Cannot find CDR export template with ID: <%s>", cdrePrfl)
 			}
+			if !utils.IsSliceMember([]string{"", utils.MetaAccount, utils.MetaCGRID}, cdreCfg.Ordering) {
+				return fmt.Errorf("<CDRS> Unsupported ordering: <%s> for CDR export template with ID: <%s>", cdreCfg.Ordering, cdrePrfl)
+			}
 		}
 		for _, connCfg := range self.CDRSThresholdSConns {
 			if connCfg.Address == utils.MetaInternal && !self.thresholdSCfg.Enabled {
@@ -414,6 +471,9 @@ func (self *CGRConfig) checkConfigSanity() error {
 				return errors.New("<SMGeneric> CDRS not enabled but referenced by SMGeneric component")
 			}
 		}
+		if self.SmGenericConfig.StoreSessions && self.instanceIDGenerated {
+			return errors.New("<SMGeneric> store_sessions requires instance_id, sessions are recovered per instance")
+		}
 	}
 	// SMFreeSWITCH checks
 	if self.SmFsConfig.Enabled {
@@ -534,6 +594,24 @@ func (self *CGRConfig) checkConfigSanity() error {
 			}
 		}
 	}
+	// SupplierS checks
+	if self.supplierSCfg != nil && self.supplierSCfg.Enabled {
+		for _, connCfg := range self.supplierSCfg.RALsConns {
+			if connCfg.Address == utils.MetaInternal && !self.RALsEnabled {
+				return errors.New("RALs not enabled but requested by SupplierS component.")
+			}
+		}
+		for _, connCfg := range self.supplierSCfg.ResourceSConns {
+			if connCfg.Address == utils.MetaInternal && !self.resourceSCfg.Enabled {
+				return errors.New("ResourceS not enabled but requested by SupplierS component.")
+			}
+		}
+		for _, connCfg := range self.supplierSCfg.StatSConns {
+			if connCfg.Address == utils.MetaInternal && !self.statsCfg.Enabled {
+				return errors.New("StatS not enabled but requested by SupplierS component.")
+			}
+		}
+	}
 
 	return nil
 }
@@ -678,6 +756,21 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) (err error) {
 		return err
 	}
 
+	jsnSupplierSCfg, err := jsnCfg.SupplierSJsonCfg()
+	if err != nil {
+		return err
+	}
+
+	jsnInvoiceSCfg, err := jsnCfg.InvoiceSJsonCfg()
+	if err != nil {
+		return err
+	}
+
+	jsnAPIAuthCfg, err := jsnCfg.APIAuthJsonCfg()
+	if err != nil {
+		return err
+	}
+
 	jsnMailerCfg, err := jsnCfg.MailerJsonCfg()
 	if err != nil {
 		return err
@@ -748,6 +841,7 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) (err error) {
 	if jsnGeneralCfg != nil {
 		if jsnGeneralCfg.Instance_id != nil && *jsnGeneralCfg.Instance_id != "" {
 			self.InstanceID = *jsnGeneralCfg.Instance_id
+			self.instanceIDGenerated = false
 		}
 		if jsnGeneralCfg.Logger != nil {
 			self.Logger = *jsnGeneralCfg.Logger
@@ -776,6 +870,11 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) (err error) {
 				return err
 			}
 		}
+		if jsnGeneralCfg.Idempotency_ttl != nil {
+			if self.IdempotencyTTL, err = utils.ParseDurationWithSecs(*jsnGeneralCfg.Idempotency_ttl); err != nil {
+				return err
+			}
+		}
 		if jsnGeneralCfg.Reconnects != nil {
 			self.Reconnects = *jsnGeneralCfg.Reconnects
 		}
@@ -817,6 +916,22 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) (err error) {
 				return err
 			}
 		}
+		if jsnGeneralCfg.Cache_replication_conns != nil {
+			self.CacheReplicationConns = make([]*HaPoolConfig, len(*jsnGeneralCfg.Cache_replication_conns))
+			for idx, jsnHaCfg := range *jsnGeneralCfg.Cache_replication_conns {
+				self.CacheReplicationConns[idx] = NewDfltHaPoolConfig()
+				self.CacheReplicationConns[idx].loadFromJsonCfg(jsnHaCfg)
+			}
+		}
+		if jsnGeneralCfg.Tls_client_certificate != nil {
+			self.TLSClientCertificate = *jsnGeneralCfg.Tls_client_certificate
+		}
+		if jsnGeneralCfg.Tls_client_key != nil {
+			self.TLSClientKey = *jsnGeneralCfg.Tls_client_key
+		}
+		if jsnGeneralCfg.Tls_client_ca_certificate != nil {
+			self.TLSClientCACertificate = *jsnGeneralCfg.Tls_client_ca_certificate
+		}
 	}
 
 	if jsnCacheCfg != nil {
@@ -835,6 +950,27 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) (err error) {
 		if jsnListenCfg.Http != nil {
 			self.HTTPListen = *jsnListenCfg.Http
 		}
+		if jsnListenCfg.Rpc_json_tls != nil {
+			self.RPCJSONTLSListen = *jsnListenCfg.Rpc_json_tls
+		}
+		if jsnListenCfg.Rpc_gob_tls != nil {
+			self.RPCGOBTLSListen = *jsnListenCfg.Rpc_gob_tls
+		}
+		if jsnListenCfg.Http_tls != nil {
+			self.HTTPTLSListen = *jsnListenCfg.Http_tls
+		}
+		if jsnListenCfg.Tls_certificate != nil {
+			self.TLSCertificate = *jsnListenCfg.Tls_certificate
+		}
+		if jsnListenCfg.Tls_key != nil {
+			self.TLSKey = *jsnListenCfg.Tls_key
+		}
+		if jsnListenCfg.Tls_ca_certificate != nil {
+			self.TLSCACertificate = *jsnListenCfg.Tls_ca_certificate
+		}
+		if jsnListenCfg.Tls_client_auth != nil {
+			self.TLSClientAuth = *jsnListenCfg.Tls_client_auth
+		}
 	}
 
 	if jsnHttpCfg != nil {
@@ -844,6 +980,9 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) (err error) {
 		if jsnHttpCfg.Ws_url != nil {
 			self.HTTPWSURL = *jsnHttpCfg.Ws_url
 		}
+		if jsnHttpCfg.Metrics_url != nil {
+			self.HTTPMetricsURL = *jsnHttpCfg.Metrics_url
+		}
 		if jsnHttpCfg.Use_basic_auth != nil {
 			self.HTTPUseBasicAuth = *jsnHttpCfg.Use_basic_auth
 		}
@@ -911,6 +1050,20 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) (err error) {
 				self.RALsUserSConns[idx].loadFromJsonCfg(jsnHaCfg)
 			}
 		}
+		if jsnRALsCfg.Resources_conns != nil {
+			self.RALsResourceSConns = make([]*HaPoolConfig, len(*jsnRALsCfg.Resources_conns))
+			for idx, jsnHaCfg := range *jsnRALsCfg.Resources_conns {
+				self.RALsResourceSConns[idx] = NewDfltHaPoolConfig()
+				self.RALsResourceSConns[idx].loadFromJsonCfg(jsnHaCfg)
+			}
+		}
+		if jsnRALsCfg.Lcr_replication_conns != nil {
+			self.RALsLCRReplicationConns = make([]*HaPoolConfig, len(*jsnRALsCfg.Lcr_replication_conns))
+			for idx, jsnHaCfg := range *jsnRALsCfg.Lcr_replication_conns {
+				self.RALsLCRReplicationConns[idx] = NewDfltHaPoolConfig()
+				self.RALsLCRReplicationConns[idx].loadFromJsonCfg(jsnHaCfg)
+			}
+		}
 		if jsnRALsCfg.Rp_subject_prefix_matching != nil {
 			self.RpSubjectPrefixMatching = *jsnRALsCfg.Rp_subject_prefix_matching
 		}
@@ -1161,6 +1314,33 @@ func (self *CGRConfig) loadFromJsonCfg(jsnCfg *CgrJsonCfg) (err error) {
 		}
 	}
 
+	if jsnSupplierSCfg != nil {
+		if self.supplierSCfg == nil {
+			self.supplierSCfg = new(SupplierSCfg)
+		}
+		if self.supplierSCfg.loadFromJsonCfg(jsnSupplierSCfg); err != nil {
+			return err
+		}
+	}
+
+	if jsnInvoiceSCfg != nil {
+		if self.invoiceSCfg == nil {
+			self.invoiceSCfg = new(InvoiceSCfg)
+		}
+		if err = self.invoiceSCfg.loadFromJsonCfg(jsnInvoiceSCfg); err != nil {
+			return err
+		}
+	}
+
+	if jsnAPIAuthCfg != nil {
+		if self.apiAuthCfg == nil {
+			self.apiAuthCfg = new(APIAuthCfg)
+		}
+		if err = self.apiAuthCfg.loadFromJsonCfg(jsnAPIAuthCfg); err != nil {
+			return err
+		}
+	}
+
 	if jsnUserServCfg != nil {
 		if jsnUserServCfg.Enabled != nil {
 			self.UserServerEnabled = *jsnUserServCfg.Enabled
@@ -1210,6 +1390,59 @@ func (self *CGRConfig) DiameterAgentCfg() *DiameterAgentCfg {
 	return self.diameterAgentCfg
 }
 
+// SMGSessionTTL returns the session TTL settings of SMGeneric, changing on reloads
+func (self *CGRConfig) SMGSessionTTL() (ttl time.Duration, maxDelay *time.Duration) {
+	self.liveMux.RLock()
+	defer self.liveMux.RUnlock()
+	return self.SmGenericConfig.SessionTTL, self.SmGenericConfig.SessionTTLMaxDelay
+}
+
+// RPCConnCfg returns the settings of the connections towards the other services, replaced on reloads
+func (self *CGRConfig) RPCConnCfg() (connectAttempts, reconnects int, connectTimeout, replyTimeout, internalTTL time.Duration) {
+	self.liveMux.RLock()
+	defer self.liveMux.RUnlock()
+	return self.ConnectAttempts, self.Reconnects, self.ConnectTimeout, self.ReplyTimeout, self.InternalTtl
+}
+
+// TLSClientCfg returns the files used by the TLS connections towards the other engines, replaced on reloads
+func (self *CGRConfig) TLSClientCfg() (certificate, key, caCertificate string) {
+	self.liveMux.RLock()
+	defer self.liveMux.RUnlock()
+	return self.TLSClientCertificate, self.TLSClientKey, self.TLSClientCACertificate
+}
+
+// CdrcProfilesCfg returns the CDRC profiles, replaced on reloads
+func (self *CGRConfig) CdrcProfilesCfg() map[string][]*CdrcConfig {
+	self.liveMux.RLock()
+	defer self.liveMux.RUnlock()
+	return self.CdrcProfiles
+}
+
+// SetCdrcProfiles replaces the CDRC profiles out of a reload, restarting the CDRCs is up to the caller
+func (self *CGRConfig) SetCdrcProfiles(cdrcProfiles map[string][]*CdrcConfig) {
+	self.reloadMux.Lock()
+	defer self.reloadMux.Unlock()
+	self.liveMux.Lock()
+	self.CdrcProfiles = cdrcProfiles
+	self.liveMux.Unlock()
+}
+
+// CdreProfilesCfg returns the CDRE profiles, replaced on reloads
+func (self *CGRConfig) CdreProfilesCfg() map[string]*CdreConfig {
+	self.liveMux.RLock()
+	defer self.liveMux.RUnlock()
+	return self.CdreProfiles
+}
+
+// SetCdreProfiles replaces the CDRE profiles out of a reload
+func (self *CGRConfig) SetCdreProfiles(cdreProfiles map[string]*CdreConfig) {
+	self.reloadMux.Lock()
+	defer self.reloadMux.Unlock()
+	self.liveMux.Lock()
+	self.CdreProfiles = cdreProfiles
+	self.liveMux.Unlock()
+}
+
 func (self *CGRConfig) RadiusAgentCfg() *RadiusAgentCfg {
 	return self.radiusAgentCfg
 }
@@ -1228,6 +1461,18 @@ func (cfg *CGRConfig) ThresholdSCfg() *ThresholdSCfg {
 	return cfg.thresholdSCfg
 }
 
+func (cfg *CGRConfig) SupplierSCfg() *SupplierSCfg {
+	return cfg.supplierSCfg
+}
+
+func (cfg *CGRConfig) InvoiceSCfg() *InvoiceSCfg {
+	return cfg.invoiceSCfg
+}
+
+func (cfg *CGRConfig) APIAuthCfg() *APIAuthCfg {
+	return cfg.apiAuthCfg
+}
+
 // ToDo: fix locking here
 func (self *CGRConfig) SMAsteriskCfg() *SMAsteriskCfg {
 	cfgChan := <-self.ConfigReloads[utils.SMAsterisk] // Lock config for read or reloads
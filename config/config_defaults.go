@@ -46,14 +46,19 @@ const CGRATES_CFG_JSON = `
 	"connect_timeout": "1s",								// consider connection unsuccessful on timeout, 0 to disable the feature
 	"reply_timeout": "2s",									// consider connection down for replies taking longer than this value
 	"response_cache_ttl": "0s",								// the life span of a cached response
+	"idempotency_ttl": "24h",								// retention of the replies to charging requests carrying an IdempotencyKey, replayed on retries, 0 to disable
 	"internal_ttl": "2m",									// maximum duration to wait for internal connections before giving up
 	"locking_timeout": "5s",								// timeout internal locks to avoid deadlocks
+	"cache_replication_conns": [],							// engines sharing the data_db, invalidating the cache partitions with "replicate": true: <""|x.y.z.y:1234>
+	"tls_client_certificate": "",							// path towards the certificate presented by the connections with "tls": true
+	"tls_client_key": "",									// path towards the key of the client certificate
+	"tls_client_ca_certificate": "",						// path towards the CA certificate verifying the servers, empty to use the system pool
 },
 
 
 "data_db": {								// database used to store runtime data (eg: accounts, cdr stats)
-	"db_type": "redis",						// data_db type: <redis|mongo>
-	"db_host": "127.0.0.1",					// data_db host address
+	"db_type": "redis",						// data_db type: <redis|redis_sharded|mongo>
+	"db_host": "127.0.0.1",					// data_db host address, shard addresses separated by ; for redis_sharded
 	"db_port": 6379, 						// data_db port to reach the database
 	"db_name": "10", 						// data_db database name to connect to
 	"db_user": "cgrates", 					// username to use when connecting to data_db
@@ -80,12 +85,20 @@ const CGRATES_CFG_JSON = `
 	"rpc_json": "127.0.0.1:2012",			// RPC JSON listening address
 	"rpc_gob": "127.0.0.1:2013",			// RPC GOB listening address
 	"http": "127.0.0.1:2080",				// HTTP listening address
+	"rpc_json_tls": "",						// RPC JSON over TLS listening address, empty to disable
+	"rpc_gob_tls": "",						// RPC GOB over TLS listening address, empty to disable
+	"http_tls": "",							// HTTPS listening address, empty to disable
+	"tls_certificate": "",					// path towards the certificate of the TLS listeners
+	"tls_key": "",							// path towards the key of the TLS listeners
+	"tls_ca_certificate": "",				// path towards the CA certificate verifying the clients
+	"tls_client_auth": "*none",				// client certificate verification: <*none|*request|*require_any|*verify_if_given|*require_and_verify>
 },
 
 
 "http": {									// HTTP server configuration
 	"json_rpc_url": "/jsonrpc",				// JSON RPC relative URL ("" to disable)
 	"ws_url": "/ws",						// WebSockets relative URL ("" to disable)
+	"metrics_url": "/metrics",				// Prometheus metrics relative URL ("" to disable)
 	"use_basic_auth": false,				// use basic authentication
 	"auth_users": {}						// basic authentication usernames and base64-encoded passwords (eg: { "username1": "cGFzc3dvcmQ=", "username2": "cGFzc3dvcmQy "})
 },
@@ -120,6 +133,10 @@ const CGRATES_CFG_JSON = `
 	"threshold_profiles": {"limit": -1, "ttl": "", "static_ttl": false, "precache": false},		// control threshold profiles caching
 	"thresholds": {"limit": -1, "ttl": "", "static_ttl": false, "precache": false},				// control thresholds caching
 	"filters": {"limit": -1, "ttl": "", "static_ttl": false, "precache": false},				// control filters caching
+	"supplier_profiles": {"limit": -1, "ttl": "", "static_ttl": false, "precache": false},		// control supplier profile caching
+	"exchange_rates": {"limit": -1, "ttl": "", "static_ttl": false, "precache": false},			// control exchange rates caching
+	"api_keys": {"limit": -1, "ttl": "1m", "static_ttl": false},								// API keys, changes done by other engines apply after ttl
+	"api_roles": {"limit": -1, "ttl": "1m", "static_ttl": false},								// API roles, changes done by other engines apply after ttl
 },
 
 
@@ -137,6 +154,8 @@ const CGRATES_CFG_JSON = `
 	"pubsubs_conns": [],					// address where to reach the pubusb service, empty to disable pubsub functionality: <""|*internal|x.y.z.y:1234>
 	"users_conns": [],						// address where to reach the user service, empty to disable user profile functionality: <""|*internal|x.y.z.y:1234>
 	"aliases_conns": [],					// address where to reach the aliases service, empty to disable aliases functionality: <""|*internal|x.y.z.y:1234>
+	"resources_conns": [],					// address where to reach the resource service, used by *least_concurrent LCR strategy: <""|*internal|x.y.z.y:1234>
+	"lcr_replication_conns": [],			// replicate the *weighted_round_robin LCR counters towards these RALs: <""|x.y.z.y:1234>
 	"rp_subject_prefix_matching": false,	// enables prefix matching for the rating profile subject
 	"lcr_subject_prefix_matching": false	// enables prefix matching for the lcr subject
 },
@@ -163,9 +182,11 @@ const CGRATES_CFG_JSON = `
 
 "cdre": {
 	"*default": {
-		"export_format": "*file_csv",					// exported CDRs format <*file_csv|*file_fwv|*http_post|*http_json_cdr|*http_json_map|*amqp_json_cdr|*amqp_json_map>
+		"export_format": "*file_csv",					// exported CDRs format <*file_csv|*file_fwv|*file_jsonl|*sql_table|*http_post|*http_json_cdr|*http_json_map|*amqp_json_cdr|*amqp_json_map>
 		"export_path": "/var/spool/cgrates/cdre",		// path where the exported CDRs will be placed
 		"cdr_filter": "",								// filter CDRs exported by this template
+		"filters": [],									// online exports: FilterS profiles the CDRs need to pass in order to be exported
+		"ordering": "",									// online exports: deliver in order the CDRs with the same <""|*account|*cgrid>
 		"synchronous": false,							// block processing until export has a result
 		"attempts": 1,									// Number of attempts if not success
 		"field_separator": ",",							// used field separator in some export formats, eg: *file_csv
@@ -173,6 +194,12 @@ const CGRATES_CFG_JSON = `
 			"*any": 1									// multiply usage based on ToR field or *any for all
 		},
 		"cost_multiply_factor": 1,						// multiply cost before export, eg: add VAT
+		"rotate_size": 0,								// *file_jsonl: rotate the export file once reaching this size in bytes, 0 to disable
+		"rotate_interval": "0s",						// *file_jsonl: rotate the export file once older than this interval, 0 to disable
+		"batch_size": 100,								// *sql_table: number of rows inserted with one query, 0 to insert all at once
+		"retry_interval": "0s",							// online exports: delay of the first retry for failed CDRs, 0 disables the persistent retry queue
+		"retry_max_interval": "1h",						// online exports: backoff limit between retries
+		"retry_attempts": 0,							// online exports: retries before the CDR is considered failed, 0 for unlimited
 		"header_fields": [],							// template of the exported header fields
 		"content_fields": [								// template of the exported content fields
 			{"tag": "CGRID", "type": "*composed", "value": "CGRID"},
@@ -265,6 +292,7 @@ const CGRATES_CFG_JSON = `
 "sm_generic": {
 	"enabled": false,						// starts SessionManager service: <true|false>
 	"listen_bijson": "127.0.0.1:2014",		// address where to listen for bidirectional JSON-RPC requests
+	"listen_bijson_tls": "",				// address where to listen for bidirectional JSON-RPC requests over TLS, empty to disable
 	"rals_conns": [
 		{"address": "*internal"}			// address where to reach the Rater <""|*internal|127.0.0.1:2013>
 	],
@@ -279,7 +307,9 @@ const CGRATES_CFG_JSON = `
 	//"session_ttl_max_delay": "",			// activates session_ttl randomization and limits the maximum possible delay
 	//"session_ttl_last_used": "",			// tweak LastUsed for sessions timing-out, not defined by default
 	//"session_ttl_usage": "",				// tweak Usage for sessions timing-out, not defined by default
+	"session_ttl_disconnect": false,		// disconnect the sessions timing-out over the BiRPC clients supporting it, ie: RadiusAgent
 	"session_indexes": [],					// index sessions based on these fields for GetActiveSessions API
+	"store_sessions": false,				// persist the active sessions in DataDB so they can be recovered after restart, requires instance_id
 },
 
 
@@ -332,6 +362,7 @@ const CGRATES_CFG_JSON = `
 	"debit_interval": "10s",				// interval to perform debits on.
 	"min_call_duration": "0s",				// only authorize calls with allowed duration higher than this
 	"max_call_duration": "3h",				// maximum call duration a prepaid call can last
+	"dialog_sync_interval": "0s",			// sync sessions with the dialogs active on Kamailio regularly, 0 to disable
 	"evapi_conns":[							// instantiate connections to multiple Kamailio servers
 		{"address": "127.0.0.1:8448", "reconnects": 5}
 	],
@@ -361,12 +392,12 @@ const CGRATES_CFG_JSON = `
 	"listen": "127.0.0.1:3868",									// address where to listen for diameter requests <x.y.z.y:1234>
 	"dictionaries_dir": "/usr/share/cgrates/diameter/dict/",	// path towards directory holding additional dictionaries to load
 	"sm_generic_conns": [
-		{"address": "*internal"}								// connection towards SMG component for session management
+		{"address": "*internal"}								// connection towards SMG component for session management, "transport": "*bijson" towards its listen_bijson so a remote SMG can reach back the sessions
 	],
 	"pubsubs_conns": [],										// address where to reach the pubusb service, empty to disable pubsub functionality: <""|*internal|x.y.z.y:1234>
 	"create_cdr": true,											// create CDR out of CCR terminate and send it to SMG component
 	"cdr_requires_session": true,								// only create CDR if there is an active session at terminate
-	"debit_interval": "5m",										// interval for CCR updates
+	"debit_interval": "5m",										// interval for CCR updates, sessions recovered by SMG are synced after it
 	"timezone": "",												// timezone for timestamps where not specified, empty for general defaults <""|UTC|Local|$IANA_TZ_DB>
 	"origin_host": "CGR-DA",									// diameter Origin-Host AVP used in replies
 	"origin_realm": "cgrates.org",								// diameter Origin-Realm AVP used in replies
@@ -388,11 +419,22 @@ const CGRATES_CFG_JSON = `
 		"*default": "/usr/share/cgrates/radius/dict/",			// key represents the client IP or catch-all <*default|$client_ip>
 	},
 	"sm_generic_conns": [
-		{"address": "*internal"}								// connection towards SMG component for session management
+		{"address": "*internal"}								// connection towards SMG component for session management, "transport": "*bijson" towards its listen_bijson so a remote SMG can reach back the sessions
 	],
 	"create_cdr": true,											// create CDR out of Accounting-Stop and send it to SMG component
 	"cdr_requires_session": false,								// only create CDR if there is an active session at terminate
+	"interim_interval": "5m",									// interval for Interim-Update, sessions recovered by SMG are synced after it
 	"timezone": "",												// timezone for timestamps where not specified, empty for general defaults <""|UTC|Local|$IANA_TZ_DB>
+	"nas_address": "NAS-IP-Address",							// template composing the NAS address out of Accounting-Request, used for CoA and Disconnect requests
+	"dynauth_port": 3799,										// port on the NAS listening for CoA and Disconnect requests (RFC 5176)
+	"coa_template": [											// attributes of the CoA-Request, composed out of the Accounting-Request starting the session
+		{"tag": "AcctSessionId", "field_id": "Acct-Session-Id", "type": "*composed", "value": "Acct-Session-Id"},
+		{"tag": "UserName", "field_id": "User-Name", "type": "*composed", "value": "User-Name"},
+	],
+	"dm_template": [											// attributes of the Disconnect-Request, composed out of the Accounting-Request starting the session
+		{"tag": "AcctSessionId", "field_id": "Acct-Session-Id", "type": "*composed", "value": "Acct-Session-Id"},
+		{"tag": "UserName", "field_id": "User-Name", "type": "*composed", "value": "User-Name"},
+	],
 	"request_processors": [],
 },
 
@@ -443,6 +485,36 @@ const CGRATES_CFG_JSON = `
 },
 
 
+"suppliers": {
+	"enabled": false,				// starts SupplierS service: <true|false>.
+	"indexed_fields": [],			// query indexes based on these fields for faster processing
+	"rals_conns": [					// address where to reach the RALs for cost based sorting <""|*internal|x.y.z.y:1234>
+		{"address": "*internal"},
+	],
+	"resources_conns": [],			// address where to reach the resources service, empty to disable capacity checks: <""|*internal|x.y.z.y:1234>
+	"stats_conns": [],				// address where to reach the stats service, empty to disable QoS sorting: <""|*internal|x.y.z.y:1234>
+},
+
+
+"invoices": {
+	"enabled": false,				// starts InvoiceS service: <true|false>.
+	"run_ids": ["*default"],		// derived charging runs billed on the invoices
+	"tax_percent": 0,				// tax applied on the invoice subtotal, in percents
+	"local_taxes": false,			// add the taxes out of the TaxProfiles matching each CDR: <true|false>
+	"id_format": "%06d",			// layout of the invoice IDs out of the numbering sequence of the tenant
+	"templates": {					// text/template files rendering the invoices, *json is built-in
+		"*html": "/usr/share/cgrates/invoices/default.html",
+		"*text": "/usr/share/cgrates/invoices/default.txt",
+	},
+},
+
+
+"api_auth": {
+	"enabled": false,				// require API keys on the RPC requests received over the network, *internal ones are not checked, connections towards such engines set their "api_key": <true|false>
+	"admin_key": "",				// API key granted all methods on all tenants, created on start to bootstrap the other keys, empty to disable
+},
+
+
 "mailer": {
 	"server": "localhost",								// the server to use when sending emails out
 	"auth_user": "cgrates",								// authenticate to email server using this user
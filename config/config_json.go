@@ -60,6 +60,9 @@ const (
 	RESOURCES_JSON  = "resources"
 	STATS_JSON      = "stats"
 	THRESHOLDS_JSON = "thresholds"
+	SUPPLIERS_JSON  = "suppliers"
+	INVOICES_JSON   = "invoices"
+	APIAUTH_JSON    = "api_auth"
 	FILTERS_JSON    = "filters"
 	MAILER_JSN      = "mailer"
 	SURETAX_JSON    = "suretax"
@@ -400,6 +403,42 @@ func (self CgrJsonCfg) ThresholdSJsonCfg() (*ThresholdSJsonCfg, error) {
 	return cfg, nil
 }
 
+func (self CgrJsonCfg) SupplierSJsonCfg() (*SupplierSJsonCfg, error) {
+	rawCfg, hasKey := self[SUPPLIERS_JSON]
+	if !hasKey {
+		return nil, nil
+	}
+	cfg := new(SupplierSJsonCfg)
+	if err := json.Unmarshal(*rawCfg, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (self CgrJsonCfg) InvoiceSJsonCfg() (*InvoiceSJsonCfg, error) {
+	rawCfg, hasKey := self[INVOICES_JSON]
+	if !hasKey {
+		return nil, nil
+	}
+	cfg := new(InvoiceSJsonCfg)
+	if err := json.Unmarshal(*rawCfg, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (self CgrJsonCfg) APIAuthJsonCfg() (*APIAuthJsonCfg, error) {
+	rawCfg, hasKey := self[APIAUTH_JSON]
+	if !hasKey {
+		return nil, nil
+	}
+	cfg := new(APIAuthJsonCfg)
+	if err := json.Unmarshal(*rawCfg, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 func (self CgrJsonCfg) MailerJsonCfg() (*MailerJsonCfg, error) {
 	rawCfg, hasKey := self[MAILER_JSN]
 	if !hasKey {
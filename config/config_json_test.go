@@ -38,26 +38,31 @@ func TestDfNewdfCgrJsonCfgFromReader(t *testing.T) {
 
 func TestDfGeneralJsonCfg(t *testing.T) {
 	eCfg := &GeneralJsonCfg{
-		Instance_id:          utils.StringPointer(""),
-		Logger:               utils.StringPointer(utils.MetaSysLog),
-		Log_level:            utils.IntPointer(utils.LOGLEVEL_INFO),
-		Http_skip_tls_verify: utils.BoolPointer(false),
-		Rounding_decimals:    utils.IntPointer(5),
-		Dbdata_encoding:      utils.StringPointer("msgpack"),
-		Tpexport_dir:         utils.StringPointer("/var/spool/cgrates/tpe"),
-		Poster_attempts:      utils.IntPointer(3),
-		Failed_posts_dir:     utils.StringPointer("/var/spool/cgrates/failed_posts"),
-		Default_request_type: utils.StringPointer(utils.META_RATED),
-		Default_category:     utils.StringPointer("call"),
-		Default_tenant:       utils.StringPointer("cgrates.org"),
-		Default_timezone:     utils.StringPointer("Local"),
-		Connect_attempts:     utils.IntPointer(3),
-		Reconnects:           utils.IntPointer(-1),
-		Connect_timeout:      utils.StringPointer("1s"),
-		Reply_timeout:        utils.StringPointer("2s"),
-		Response_cache_ttl:   utils.StringPointer("0s"),
-		Internal_ttl:         utils.StringPointer("2m"),
-		Locking_timeout:      utils.StringPointer("5s"),
+		Instance_id:               utils.StringPointer(""),
+		Logger:                    utils.StringPointer(utils.MetaSysLog),
+		Log_level:                 utils.IntPointer(utils.LOGLEVEL_INFO),
+		Http_skip_tls_verify:      utils.BoolPointer(false),
+		Rounding_decimals:         utils.IntPointer(5),
+		Dbdata_encoding:           utils.StringPointer("msgpack"),
+		Tpexport_dir:              utils.StringPointer("/var/spool/cgrates/tpe"),
+		Poster_attempts:           utils.IntPointer(3),
+		Failed_posts_dir:          utils.StringPointer("/var/spool/cgrates/failed_posts"),
+		Default_request_type:      utils.StringPointer(utils.META_RATED),
+		Default_category:          utils.StringPointer("call"),
+		Default_tenant:            utils.StringPointer("cgrates.org"),
+		Default_timezone:          utils.StringPointer("Local"),
+		Connect_attempts:          utils.IntPointer(3),
+		Reconnects:                utils.IntPointer(-1),
+		Connect_timeout:           utils.StringPointer("1s"),
+		Reply_timeout:             utils.StringPointer("2s"),
+		Response_cache_ttl:        utils.StringPointer("0s"),
+		Idempotency_ttl:           utils.StringPointer("24h"),
+		Internal_ttl:              utils.StringPointer("2m"),
+		Locking_timeout:           utils.StringPointer("5s"),
+		Cache_replication_conns:   &[]*HaPoolJsonCfg{},
+		Tls_client_certificate:    utils.StringPointer(""),
+		Tls_client_key:            utils.StringPointer(""),
+		Tls_client_ca_certificate: utils.StringPointer(""),
 	}
 	if gCfg, err := dfCgrJsonCfg.GeneralJsonCfg(); err != nil {
 		t.Error(err)
@@ -136,6 +141,16 @@ func TestCacheJsonCfg(t *testing.T) {
 		utils.CacheFilters: &CacheParamJsonCfg{Limit: utils.IntPointer(-1),
 			Ttl: utils.StringPointer(""), Static_ttl: utils.BoolPointer(false),
 			Precache: utils.BoolPointer(false)},
+		utils.CacheSupplierProfiles: &CacheParamJsonCfg{Limit: utils.IntPointer(-1),
+			Ttl: utils.StringPointer(""), Static_ttl: utils.BoolPointer(false),
+			Precache: utils.BoolPointer(false)},
+		utils.CacheExchangeRates: &CacheParamJsonCfg{Limit: utils.IntPointer(-1),
+			Ttl: utils.StringPointer(""), Static_ttl: utils.BoolPointer(false),
+			Precache: utils.BoolPointer(false)},
+		utils.CacheAPIKeys: &CacheParamJsonCfg{Limit: utils.IntPointer(-1),
+			Ttl: utils.StringPointer("1m"), Static_ttl: utils.BoolPointer(false)},
+		utils.CacheAPIRoles: &CacheParamJsonCfg{Limit: utils.IntPointer(-1),
+			Ttl: utils.StringPointer("1m"), Static_ttl: utils.BoolPointer(false)},
 	}
 
 	if gCfg, err := dfCgrJsonCfg.CacheJsonCfg(); err != nil {
@@ -147,9 +162,16 @@ func TestCacheJsonCfg(t *testing.T) {
 
 func TestDfListenJsonCfg(t *testing.T) {
 	eCfg := &ListenJsonCfg{
-		Rpc_json: utils.StringPointer("127.0.0.1:2012"),
-		Rpc_gob:  utils.StringPointer("127.0.0.1:2013"),
-		Http:     utils.StringPointer("127.0.0.1:2080")}
+		Rpc_json:           utils.StringPointer("127.0.0.1:2012"),
+		Rpc_gob:            utils.StringPointer("127.0.0.1:2013"),
+		Http:               utils.StringPointer("127.0.0.1:2080"),
+		Rpc_json_tls:       utils.StringPointer(""),
+		Rpc_gob_tls:        utils.StringPointer(""),
+		Http_tls:           utils.StringPointer(""),
+		Tls_certificate:    utils.StringPointer(""),
+		Tls_key:            utils.StringPointer(""),
+		Tls_ca_certificate: utils.StringPointer(""),
+		Tls_client_auth:    utils.StringPointer(utils.META_NONE)}
 	if cfg, err := dfCgrJsonCfg.ListenJsonCfg(); err != nil {
 		t.Error(err)
 	} else if !reflect.DeepEqual(eCfg, cfg) {
@@ -199,6 +221,7 @@ func TestDfRalsJsonCfg(t *testing.T) {
 		Cdrstats_conns: &[]*HaPoolJsonCfg{}, Stats_conns: &[]*HaPoolJsonCfg{},
 		Historys_conns: &[]*HaPoolJsonCfg{}, Pubsubs_conns: &[]*HaPoolJsonCfg{},
 		Users_conns: &[]*HaPoolJsonCfg{}, Aliases_conns: &[]*HaPoolJsonCfg{},
+		Resources_conns: &[]*HaPoolJsonCfg{}, Lcr_replication_conns: &[]*HaPoolJsonCfg{},
 		Rp_subject_prefix_matching: utils.BoolPointer(false), Lcr_subject_prefix_matching: utils.BoolPointer(false)}
 	if cfg, err := dfCgrJsonCfg.RalsJsonCfg(); err != nil {
 		t.Error(err)
@@ -311,11 +334,19 @@ func TestDfCdreJsonCfgs(t *testing.T) {
 			Export_format:         utils.StringPointer(utils.MetaFileCSV),
 			Export_path:           utils.StringPointer("/var/spool/cgrates/cdre"),
 			Cdr_filter:            utils.StringPointer(""),
+			Filters:               &[]string{},
+			Ordering:              utils.StringPointer(""),
 			Synchronous:           utils.BoolPointer(false),
 			Attempts:              utils.IntPointer(1),
 			Field_separator:       utils.StringPointer(","),
 			Usage_multiply_factor: &map[string]float64{utils.ANY: 1.0},
 			Cost_multiply_factor:  utils.Float64Pointer(1.0),
+			Rotate_size:           utils.IntPointer(0),
+			Rotate_interval:       utils.StringPointer("0s"),
+			Batch_size:            utils.IntPointer(100),
+			Retry_interval:        utils.StringPointer("0s"),
+			Retry_max_interval:    utils.StringPointer("1h"),
+			Retry_attempts:        utils.IntPointer(0),
 			Header_fields:         &eFields,
 			Content_fields:        &eContentFlds,
 			Trailer_fields:        &eFields,
@@ -445,8 +476,9 @@ func TestDfCdrcJsonCfg(t *testing.T) {
 
 func TestSmGenericJsonCfg(t *testing.T) {
 	eCfg := &SmGenericJsonCfg{
-		Enabled:       utils.BoolPointer(false),
-		Listen_bijson: utils.StringPointer("127.0.0.1:2014"),
+		Enabled:           utils.BoolPointer(false),
+		Listen_bijson:     utils.StringPointer("127.0.0.1:2014"),
+		Listen_bijson_tls: utils.StringPointer(""),
 		Rals_conns: &[]*HaPoolJsonCfg{
 			&HaPoolJsonCfg{
 				Address: utils.StringPointer(utils.MetaInternal),
@@ -455,12 +487,14 @@ func TestSmGenericJsonCfg(t *testing.T) {
 			&HaPoolJsonCfg{
 				Address: utils.StringPointer(utils.MetaInternal),
 			}},
-		Smg_replication_conns: &[]*HaPoolJsonCfg{},
-		Debit_interval:        utils.StringPointer("0s"),
-		Min_call_duration:     utils.StringPointer("0s"),
-		Max_call_duration:     utils.StringPointer("3h"),
-		Session_ttl:           utils.StringPointer("0s"),
-		Session_indexes:       utils.StringSlicePointer([]string{}),
+		Smg_replication_conns:  &[]*HaPoolJsonCfg{},
+		Debit_interval:         utils.StringPointer("0s"),
+		Min_call_duration:      utils.StringPointer("0s"),
+		Max_call_duration:      utils.StringPointer("3h"),
+		Session_ttl:            utils.StringPointer("0s"),
+		Session_ttl_disconnect: utils.BoolPointer(false),
+		Session_indexes:        utils.StringSlicePointer([]string{}),
+		Store_sessions:         utils.BoolPointer(false),
 	}
 	if cfg, err := dfCgrJsonCfg.SmGenericJsonCfg(); err != nil {
 		t.Error(err)
@@ -518,11 +552,12 @@ func TestSmKamJsonCfg(t *testing.T) {
 			&HaPoolJsonCfg{
 				Address: utils.StringPointer(utils.MetaInternal),
 			}},
-		Resources_conns:   &[]*HaPoolJsonCfg{},
-		Create_cdr:        utils.BoolPointer(false),
-		Debit_interval:    utils.StringPointer("10s"),
-		Min_call_duration: utils.StringPointer("0s"),
-		Max_call_duration: utils.StringPointer("3h"),
+		Resources_conns:      &[]*HaPoolJsonCfg{},
+		Create_cdr:           utils.BoolPointer(false),
+		Debit_interval:       utils.StringPointer("10s"),
+		Min_call_duration:    utils.StringPointer("0s"),
+		Max_call_duration:    utils.StringPointer("3h"),
+		Dialog_sync_interval: utils.StringPointer("0s"),
 		Evapi_conns: &[]*KamConnJsonCfg{
 			&KamConnJsonCfg{
 				Address:    utils.StringPointer("127.0.0.1:8448"),
@@ -630,8 +665,23 @@ func TestRadiusAgentJsonCfg(t *testing.T) {
 			}},
 		Create_cdr:           utils.BoolPointer(true),
 		Cdr_requires_session: utils.BoolPointer(false),
+		Interim_interval:     utils.StringPointer("5m"),
 		Timezone:             utils.StringPointer(""),
-		Request_processors:   &[]*RAReqProcessorJsnCfg{},
+		Nas_address:          utils.StringPointer("NAS-IP-Address"),
+		Dynauth_port:         utils.IntPointer(3799),
+		Coa_template: &[]*CdrFieldJsonCfg{
+			&CdrFieldJsonCfg{Tag: utils.StringPointer("AcctSessionId"), Field_id: utils.StringPointer("Acct-Session-Id"),
+				Type: utils.StringPointer(utils.META_COMPOSED), Value: utils.StringPointer("Acct-Session-Id")},
+			&CdrFieldJsonCfg{Tag: utils.StringPointer("UserName"), Field_id: utils.StringPointer("User-Name"),
+				Type: utils.StringPointer(utils.META_COMPOSED), Value: utils.StringPointer("User-Name")},
+		},
+		Dm_template: &[]*CdrFieldJsonCfg{
+			&CdrFieldJsonCfg{Tag: utils.StringPointer("AcctSessionId"), Field_id: utils.StringPointer("Acct-Session-Id"),
+				Type: utils.StringPointer(utils.META_COMPOSED), Value: utils.StringPointer("Acct-Session-Id")},
+			&CdrFieldJsonCfg{Tag: utils.StringPointer("UserName"), Field_id: utils.StringPointer("User-Name"),
+				Type: utils.StringPointer(utils.META_COMPOSED), Value: utils.StringPointer("User-Name")},
+		},
+		Request_processors: &[]*RAReqProcessorJsnCfg{},
 	}
 	if cfg, err := dfCgrJsonCfg.RadiusAgentJsonCfg(); err != nil {
 		t.Error(err)
@@ -729,6 +779,54 @@ func TestDfThresholdSJsonCfg(t *testing.T) {
 	}
 }
 
+func TestDfSupplierSJsonCfg(t *testing.T) {
+	eCfg := &SupplierSJsonCfg{
+		Enabled:        utils.BoolPointer(false),
+		Indexed_fields: utils.StringSlicePointer([]string{}),
+		Rals_conns: &[]*HaPoolJsonCfg{&HaPoolJsonCfg{
+			Address: utils.StringPointer(utils.MetaInternal),
+		}},
+		Resources_conns: &[]*HaPoolJsonCfg{},
+		Stats_conns:     &[]*HaPoolJsonCfg{},
+	}
+	if cfg, err := dfCgrJsonCfg.SupplierSJsonCfg(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eCfg, cfg) {
+		t.Errorf("expecting: %+v, received: %+v", utils.ToJSON(eCfg), utils.ToJSON(cfg))
+	}
+}
+
+func TestDfInvoiceSJsonCfg(t *testing.T) {
+	eCfg := &InvoiceSJsonCfg{
+		Enabled:     utils.BoolPointer(false),
+		Run_ids:     utils.StringSlicePointer([]string{utils.META_DEFAULT}),
+		Tax_percent: utils.Float64Pointer(0),
+		Local_taxes: utils.BoolPointer(false),
+		Id_format:   utils.StringPointer("%06d"),
+		Templates: &map[string]string{
+			"*html": "/usr/share/cgrates/invoices/default.html",
+			"*text": "/usr/share/cgrates/invoices/default.txt",
+		},
+	}
+	if cfg, err := dfCgrJsonCfg.InvoiceSJsonCfg(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eCfg, cfg) {
+		t.Errorf("expecting: %+v, received: %+v", utils.ToJSON(eCfg), utils.ToJSON(cfg))
+	}
+}
+
+func TestDfAPIAuthJsonCfg(t *testing.T) {
+	eCfg := &APIAuthJsonCfg{
+		Enabled:   utils.BoolPointer(false),
+		Admin_key: utils.StringPointer(""),
+	}
+	if cfg, err := dfCgrJsonCfg.APIAuthJsonCfg(); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eCfg, cfg) {
+		t.Errorf("expecting: %+v, received: %+v", utils.ToJSON(eCfg), utils.ToJSON(cfg))
+	}
+}
+
 func TestDfMailerJsonCfg(t *testing.T) {
 	eCfg := &MailerJsonCfg{
 		Server:        utils.StringPointer("localhost"),
@@ -845,6 +943,7 @@ func TestDfHttpJsonCfg(t *testing.T) {
 	eCfg := &HTTPJsonCfg{
 		Json_rpc_url:   utils.StringPointer("/jsonrpc"),
 		Ws_url:         utils.StringPointer("/ws"),
+		Metrics_url:    utils.StringPointer("/metrics"),
 		Use_basic_auth: utils.BoolPointer(false),
 		Auth_users:     utils.MapStringStringPointer(map[string]string{})}
 	if cfg, err := dfCgrJsonCfg.HttpJsonCfg(); err != nil {
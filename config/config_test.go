@@ -187,12 +187,18 @@ func TestCgrCfgJSONDefaultsGeneral(t *testing.T) {
 	if cgrCfg.ResponseCacheTTL != 0*time.Second {
 		t.Error(cgrCfg.ResponseCacheTTL)
 	}
+	if cgrCfg.IdempotencyTTL != 24*time.Hour {
+		t.Error(cgrCfg.IdempotencyTTL)
+	}
 	if cgrCfg.InternalTtl != 2*time.Minute {
 		t.Error(cgrCfg.InternalTtl)
 	}
 	if cgrCfg.LockingTimeout != 5*time.Second {
 		t.Error(cgrCfg.LockingTimeout)
 	}
+	if !reflect.DeepEqual(cgrCfg.CacheReplicationConns, []*HaPoolConfig{}) {
+		t.Error(cgrCfg.CacheReplicationConns)
+	}
 	if cgrCfg.Logger != utils.MetaSysLog {
 		t.Error(cgrCfg.Logger)
 	}
@@ -211,6 +217,68 @@ func TestCgrCfgJSONDefaultsListen(t *testing.T) {
 	if cgrCfg.HTTPListen != "127.0.0.1:2080" {
 		t.Error(cgrCfg.HTTPListen)
 	}
+	if cgrCfg.RPCJSONTLSListen != "" {
+		t.Error(cgrCfg.RPCJSONTLSListen)
+	}
+	if cgrCfg.HTTPTLSListen != "" {
+		t.Error(cgrCfg.HTTPTLSListen)
+	}
+	if cgrCfg.TLSClientAuth != utils.META_NONE {
+		t.Error(cgrCfg.TLSClientAuth)
+	}
+}
+
+func TestCgrCfgTLSListenSanity(t *testing.T) {
+	cfgJSONStr := `{
+"listen": {
+	"rpc_json_tls": "127.0.0.1:2022",
+},
+}`
+	if cfg, err := NewCGRConfigFromJsonStringWithDefaults(cfgJSONStr); err != nil {
+		t.Error(err)
+	} else if err := cfg.checkConfigSanity(); err == nil {
+		t.Error("expecting error for missing certificates")
+	}
+	cfgJSONStr = `{
+"listen": {
+	"rpc_json_tls": "127.0.0.1:2022",
+	"tls_certificate": "/usr/share/cgrates/tls/server.crt",
+	"tls_key": "/usr/share/cgrates/tls/server.key",
+	"tls_client_auth": "*require_and_verify",
+},
+}`
+	if cfg, err := NewCGRConfigFromJsonStringWithDefaults(cfgJSONStr); err != nil {
+		t.Error(err)
+	} else if err := cfg.checkConfigSanity(); err == nil {
+		t.Error("expecting error for missing CA certificate")
+	}
+	cfgJSONStr = `{
+"general": {
+	"tls_client_certificate": "/usr/share/cgrates/tls/client.crt",
+},
+"listen": {
+	"rpc_json_tls": "127.0.0.1:2022",
+	"tls_certificate": "/usr/share/cgrates/tls/server.crt",
+	"tls_key": "/usr/share/cgrates/tls/server.key",
+	"tls_ca_certificate": "/usr/share/cgrates/tls/ca.crt",
+	"tls_client_auth": "*require_and_verify",
+},
+"sm_generic": {
+	"rals_conns": [
+		{"address": "127.0.0.1:2022", "transport": "*json", "tls": true, "api_key": "smg_key"},
+	],
+},
+}`
+	if cfg, err := NewCGRConfigFromJsonStringWithDefaults(cfgJSONStr); err != nil {
+		t.Error(err)
+	} else if err := cfg.checkConfigSanity(); err != nil {
+		t.Error(err)
+	} else if cfg.RPCJSONTLSListen != "127.0.0.1:2022" ||
+		cfg.TLSClientCertificate != "/usr/share/cgrates/tls/client.crt" ||
+		!cfg.SmGenericConfig.RALsConns[0].TLS ||
+		cfg.SmGenericConfig.RALsConns[0].ApiKey != "smg_key" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
 }
 
 func TestCgrCfgJSONDefaultsjsnDataDb(t *testing.T) {
@@ -294,6 +362,12 @@ func TestCgrCfgJSONDefaultsRALs(t *testing.T) {
 	if !reflect.DeepEqual(cgrCfg.RALsAliasSConns, eHaPoolcfg) {
 		t.Error(cgrCfg.RALsAliasSConns)
 	}
+	if !reflect.DeepEqual(cgrCfg.RALsResourceSConns, eHaPoolcfg) {
+		t.Error(cgrCfg.RALsResourceSConns)
+	}
+	if !reflect.DeepEqual(cgrCfg.RALsLCRReplicationConns, eHaPoolcfg) {
+		t.Error(cgrCfg.RALsLCRReplicationConns)
+	}
 	if cgrCfg.RpSubjectPrefixMatching != false {
 		t.Error(cgrCfg.RpSubjectPrefixMatching)
 	}
@@ -389,6 +463,9 @@ func TestCgrCfgJSONDefaultsCdreProfiles(t *testing.T) {
 			FieldSeparator:      ',',
 			UsageMultiplyFactor: map[string]float64{utils.ANY: 1.0},
 			CostMultiplyFactor:  1.0,
+			FilterIDs:           []string{},
+			BatchSize:           100,
+			RetryMaxInterval:    time.Hour,
 			HeaderFields:        eFields,
 			ContentFields:       eContentFlds,
 			TrailerFields:       eFields,
@@ -465,7 +542,15 @@ func TestCgrCfgJSONDefaultsCacheCFG(t *testing.T) {
 		utils.CacheThresholds: &CacheParamConfig{Limit: -1,
 			TTL: time.Duration(0), StaticTTL: false, Precache: false},
 		utils.CacheFilters: &CacheParamConfig{Limit: -1,
-			TTL: time.Duration(0), StaticTTL: false, Precache: false}}
+			TTL: time.Duration(0), StaticTTL: false, Precache: false},
+		utils.CacheSupplierProfiles: &CacheParamConfig{Limit: -1,
+			TTL: time.Duration(0), StaticTTL: false, Precache: false},
+		utils.CacheExchangeRates: &CacheParamConfig{Limit: -1,
+			TTL: time.Duration(0), StaticTTL: false, Precache: false},
+		utils.CacheAPIKeys: &CacheParamConfig{Limit: -1,
+			TTL: time.Duration(1 * time.Minute), StaticTTL: false},
+		utils.CacheAPIRoles: &CacheParamConfig{Limit: -1,
+			TTL: time.Duration(1 * time.Minute), StaticTTL: false}}
 	if !reflect.DeepEqual(eCacheCfg, cgrCfg.CacheConfig) {
 		t.Errorf("received: %s, \nexpecting: %s", utils.ToJSON(eCacheCfg), utils.ToJSON(cgrCfg.CacheConfig))
 	}
@@ -499,15 +584,16 @@ func TestCgrCfgJSONDefaultsSMFsConfig(t *testing.T) {
 
 func TestCgrCfgJSONDefaultsSMKamConfig(t *testing.T) {
 	eSmKaCfg := &SmKamConfig{
-		Enabled:         false,
-		RALsConns:       []*HaPoolConfig{&HaPoolConfig{Address: "*internal"}},
-		CDRsConns:       []*HaPoolConfig{&HaPoolConfig{Address: "*internal"}},
-		RLsConns:        []*HaPoolConfig{},
-		CreateCdr:       false,
-		DebitInterval:   10 * time.Second,
-		MinCallDuration: 0 * time.Second,
-		MaxCallDuration: 3 * time.Hour,
-		EvapiConns:      []*KamConnConfig{&KamConnConfig{Address: "127.0.0.1:8448", Reconnects: 5}},
+		Enabled:            false,
+		RALsConns:          []*HaPoolConfig{&HaPoolConfig{Address: "*internal"}},
+		CDRsConns:          []*HaPoolConfig{&HaPoolConfig{Address: "*internal"}},
+		RLsConns:           []*HaPoolConfig{},
+		CreateCdr:          false,
+		DebitInterval:      10 * time.Second,
+		MinCallDuration:    0 * time.Second,
+		MaxCallDuration:    3 * time.Hour,
+		DialogSyncInterval: 0,
+		EvapiConns:         []*KamConnConfig{&KamConnConfig{Address: "127.0.0.1:8448", Reconnects: 5}},
 	}
 	if !reflect.DeepEqual(cgrCfg.SmKamConfig, eSmKaCfg) {
 		t.Errorf("received: %+v, expecting: %+v", cgrCfg.SmKamConfig, eSmKaCfg)
@@ -616,6 +702,44 @@ func TestCgrCfgJSONDefaultThresholdSCfg(t *testing.T) {
 	}
 }
 
+func TestCgrCfgJSONDefaultSupplierSCfg(t *testing.T) {
+	eSupplSCfg := &SupplierSCfg{
+		Enabled:       false,
+		IndexedFields: []string{},
+		RALsConns: []*HaPoolConfig{
+			&HaPoolConfig{Address: utils.MetaInternal},
+		},
+		ResourceSConns: []*HaPoolConfig{},
+		StatSConns:     []*HaPoolConfig{},
+	}
+	if !reflect.DeepEqual(eSupplSCfg, cgrCfg.supplierSCfg) {
+		t.Errorf("received: %+v, expecting: %+v", utils.ToJSON(cgrCfg.supplierSCfg), utils.ToJSON(eSupplSCfg))
+	}
+}
+
+func TestCgrCfgJSONDefaultInvoiceSCfg(t *testing.T) {
+	eInvSCfg := &InvoiceSCfg{
+		Enabled:    false,
+		RunIDs:     []string{utils.META_DEFAULT},
+		TaxPercent: 0,
+		LocalTaxes: false,
+		IDFormat:   "%06d",
+		Templates: map[string]string{
+			"*html": "/usr/share/cgrates/invoices/default.html",
+			"*text": "/usr/share/cgrates/invoices/default.txt",
+		},
+	}
+	if !reflect.DeepEqual(eInvSCfg, cgrCfg.invoiceSCfg) {
+		t.Errorf("received: %+v, expecting: %+v", utils.ToJSON(cgrCfg.invoiceSCfg), utils.ToJSON(eInvSCfg))
+	}
+}
+
+func TestCgrCfgJSONDefaultAPIAuthCfg(t *testing.T) {
+	if eAPIAuthCfg := (&APIAuthCfg{Enabled: false, AdminKey: ""}); !reflect.DeepEqual(eAPIAuthCfg, cgrCfg.apiAuthCfg) {
+		t.Errorf("received: %+v, expecting: %+v", utils.ToJSON(cgrCfg.apiAuthCfg), utils.ToJSON(eAPIAuthCfg))
+	}
+}
+
 func TestCgrCfgJSONDefaultsDiameterAgentCfg(t *testing.T) {
 	testDA := &DiameterAgentCfg{
 		Enabled:           false,
@@ -734,6 +858,9 @@ func TestCgrCfgJSONDefaultsHTTP(t *testing.T) {
 	if cgrCfg.HTTPWSURL != "/ws" {
 		t.Error(cgrCfg.HTTPWSURL)
 	}
+	if cgrCfg.HTTPMetricsURL != "/metrics" {
+		t.Error(cgrCfg.HTTPMetricsURL)
+	}
 	if cgrCfg.HTTPUseBasicAuth != false {
 		t.Error(cgrCfg.HTTPUseBasicAuth)
 	}
@@ -753,8 +880,17 @@ func TestRadiusAgentCfg(t *testing.T) {
 		SMGenericConns:     []*HaPoolConfig{&HaPoolConfig{Address: utils.MetaInternal}},
 		CreateCDR:          true,
 		CDRRequiresSession: false,
+		InterimInterval:    5 * time.Minute,
 		Timezone:           "",
-		RequestProcessors:  nil,
+		NasAddress:         utils.ParseRSRFieldsMustCompile("NAS-IP-Address", utils.INFIELD_SEP),
+		DynAuthPort:        3799,
+		DMTemplate: []*CfgCdrField{
+			&CfgCdrField{Tag: "AcctSessionId", FieldId: "Acct-Session-Id", Type: utils.META_COMPOSED,
+				Value: utils.ParseRSRFieldsMustCompile("Acct-Session-Id", utils.INFIELD_SEP)},
+			&CfgCdrField{Tag: "UserName", FieldId: "User-Name", Type: utils.META_COMPOSED,
+				Value: utils.ParseRSRFieldsMustCompile("User-Name", utils.INFIELD_SEP)},
+		},
+		RequestProcessors: nil,
 	}
 	if !reflect.DeepEqual(cgrCfg.radiusAgentCfg.Enabled, testRA.Enabled) {
 		t.Errorf("expecting: %+v, received: %+v", cgrCfg.radiusAgentCfg.Enabled, testRA.Enabled)
@@ -783,10 +919,89 @@ func TestRadiusAgentCfg(t *testing.T) {
 	if !reflect.DeepEqual(cgrCfg.radiusAgentCfg.CDRRequiresSession, testRA.CDRRequiresSession) {
 		t.Errorf("received: %+v, expecting: %+v", cgrCfg.radiusAgentCfg.CDRRequiresSession, testRA.CDRRequiresSession)
 	}
+	if cgrCfg.radiusAgentCfg.InterimInterval != testRA.InterimInterval {
+		t.Errorf("received: %+v, expecting: %+v", cgrCfg.radiusAgentCfg.InterimInterval, testRA.InterimInterval)
+	}
 	if !reflect.DeepEqual(cgrCfg.radiusAgentCfg.Timezone, testRA.Timezone) {
 		t.Errorf("received: %+v, expecting: %+v", cgrCfg.radiusAgentCfg.Timezone, testRA.Timezone)
 	}
+	if !reflect.DeepEqual(cgrCfg.radiusAgentCfg.NasAddress, testRA.NasAddress) {
+		t.Errorf("received: %+v, expecting: %+v", cgrCfg.radiusAgentCfg.NasAddress, testRA.NasAddress)
+	}
+	if cgrCfg.radiusAgentCfg.DynAuthPort != testRA.DynAuthPort {
+		t.Errorf("received: %+v, expecting: %+v", cgrCfg.radiusAgentCfg.DynAuthPort, testRA.DynAuthPort)
+	}
+	if !reflect.DeepEqual(cgrCfg.radiusAgentCfg.DMTemplate, testRA.DMTemplate) {
+		t.Errorf("received: %s, expecting: %s", utils.ToJSON(cgrCfg.radiusAgentCfg.DMTemplate), utils.ToJSON(testRA.DMTemplate))
+	}
 	if !reflect.DeepEqual(cgrCfg.radiusAgentCfg.RequestProcessors, testRA.RequestProcessors) {
 		t.Errorf("received: %+v, expecting: %+v", cgrCfg.radiusAgentCfg.RequestProcessors, testRA.RequestProcessors)
 	}
 }
+
+func TestCgrCfgStoreSessionsSanity(t *testing.T) {
+	cfgJSONStr := `{
+"rals": {
+	"enabled": true,
+},
+"cdrs": {
+	"enabled": true,
+},
+"sm_generic": {
+	"enabled": true,
+	"store_sessions": true,
+},
+}`
+	if cfg, err := NewCGRConfigFromJsonStringWithDefaults(cfgJSONStr); err != nil {
+		t.Error(err)
+	} else if err := cfg.checkConfigSanity(); err == nil {
+		t.Error("expecting error for missing instance_id")
+	}
+	cfgJSONStr = `{
+"general": {
+	"instance_id": "node1",
+},
+"rals": {
+	"enabled": true,
+},
+"cdrs": {
+	"enabled": true,
+},
+"sm_generic": {
+	"enabled": true,
+	"store_sessions": true,
+},
+}`
+	if cfg, err := NewCGRConfigFromJsonStringWithDefaults(cfgJSONStr); err != nil {
+		t.Error(err)
+	} else if err := cfg.checkConfigSanity(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDiameterAgentCfgProcessorSMGAction(t *testing.T) {
+	cfgJSONStr := `{
+"diameter_agent": {
+	"request_processors": [
+		{"id": "RfStart", "command_code": 271, "request_filter": "Accounting-Record-Type(2)"},
+	],
+},
+}`
+	if _, err := NewCGRConfigFromJsonStringWithDefaults(cfgJSONStr); err == nil {
+		t.Error("expecting error for missing smg_action")
+	}
+	cfgJSONStr = `{
+"diameter_agent": {
+	"request_processors": [
+		{"id": "RfStart", "command_code": 271, "request_filter": "Accounting-Record-Type(2)", "smg_action": "*initiate"},
+		{"id": "CCR", "command_code": 272},
+	],
+},
+}`
+	if cfg, err := NewCGRConfigFromJsonStringWithDefaults(cfgJSONStr); err != nil {
+		t.Error(err)
+	} else if len(cfg.DiameterAgentCfg().RequestProcessors) != 2 ||
+		cfg.DiameterAgentCfg().RequestProcessors[0].SMGAction != "*initiate" {
+		t.Errorf("unexpected processors: %s", utils.ToJSON(cfg.DiameterAgentCfg().RequestProcessors))
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package config
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ConfigReloadReport lists the sections changed by a configuration reload
+type ConfigReloadReport struct {
+	Reloaded        []string // sections applied on the running engine
+	RestartRequired []string // sections with changes applied only after a restart
+}
+
+// cfgSection describes how a configuration section is compared and reloaded
+type cfgSection struct {
+	values func(cfg *CGRConfig) interface{} // settings of the section, compared between the running and the new configuration
+	reload func(cfg, newCfg *CGRConfig)     // applies the live settings out of newCfg, nil if all need a restart
+}
+
+// cfgSections are the configuration sections considered by reloads.
+// InstanceID is left out since it is generated on each load if not configured.
+var cfgSections = map[string]*cfgSection{
+	GENERAL_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.Logger, cfg.LogLevel, cfg.DBDataEncoding, cfg.DefaultReqType, cfg.DefaultCategory,
+				cfg.DefaultTenant, cfg.DefaultTimezone, cfg.ConnectAttempts, cfg.Reconnects, cfg.ConnectTimeout,
+				cfg.ReplyTimeout, cfg.ResponseCacheTTL, cfg.IdempotencyTTL, cfg.InternalTtl, cfg.RoundingDecimals,
+				cfg.HttpSkipTlsVerify, cfg.TpExportPath, cfg.PosterAttempts, cfg.FailedPostsDir, cfg.LockingTimeout,
+				cfg.CacheReplicationConns, cfg.TLSClientCertificate, cfg.TLSClientKey, cfg.TLSClientCACertificate}
+		},
+		reload: func(cfg, newCfg *CGRConfig) { // the connection settings are picked up by the hooks rebuilding the pools
+			cfg.LogLevel = newCfg.LogLevel
+			cfg.ConnectAttempts = newCfg.ConnectAttempts
+			cfg.Reconnects = newCfg.Reconnects
+			cfg.ConnectTimeout = newCfg.ConnectTimeout
+			cfg.ReplyTimeout = newCfg.ReplyTimeout
+			cfg.InternalTtl = newCfg.InternalTtl
+			cfg.CacheReplicationConns = newCfg.CacheReplicationConns
+			cfg.TLSClientCertificate = newCfg.TLSClientCertificate
+			cfg.TLSClientKey = newCfg.TLSClientKey
+			cfg.TLSClientCACertificate = newCfg.TLSClientCACertificate
+		},
+	},
+	CACHE_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.CacheConfig },
+		reload: func(cfg, newCfg *CGRConfig) {
+			cfg.CacheConfig = newCfg.CacheConfig
+		},
+	},
+	LISTEN_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.RPCJSONListen, cfg.RPCGOBListen, cfg.HTTPListen, cfg.RPCJSONTLSListen,
+				cfg.RPCGOBTLSListen, cfg.HTTPTLSListen, cfg.TLSCertificate, cfg.TLSKey, cfg.TLSCACertificate, cfg.TLSClientAuth}
+		},
+	},
+	HTTP_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.HTTPJsonRPCURL, cfg.HTTPWSURL, cfg.HTTPMetricsURL, cfg.HTTPUseBasicAuth, cfg.HTTPAuthUsers}
+		},
+	},
+	DATADB_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.DataDbType, cfg.DataDbHost, cfg.DataDbPort, cfg.DataDbName,
+				cfg.DataDbUser, cfg.DataDbPass, cfg.LoadHistorySize}
+		},
+	},
+	STORDB_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.StorDBType, cfg.StorDBHost, cfg.StorDBPort, cfg.StorDBName, cfg.StorDBUser,
+				cfg.StorDBPass, cfg.StorDBMaxOpenConns, cfg.StorDBMaxIdleConns, cfg.StorDBConnMaxLifetime, cfg.StorDBCDRSIndexes}
+		},
+	},
+	FilterSjsn: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.filterSCfg },
+	},
+	RALS_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.RALsEnabled, cfg.RALsThresholdSConns, cfg.RALsCDRStatSConns, cfg.RALsStatSConns,
+				cfg.RALsHistorySConns, cfg.RALsPubSubSConns, cfg.RALsUserSConns, cfg.RALsAliasSConns, cfg.RALsResourceSConns,
+				cfg.RALsLCRReplicationConns, cfg.RpSubjectPrefixMatching, cfg.LcrSubjectPrefixMatching}
+		},
+	},
+	SCHEDULER_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.SchedulerEnabled },
+	},
+	CDRS_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.CDRSEnabled, cfg.CDRSExtraFields, cfg.CDRSStoreCdrs, cfg.CDRScdrAccountSummary,
+				cfg.CDRSSMCostRetries, cfg.CDRSRaterConns, cfg.CDRSPubSubSConns, cfg.CDRSUserSConns, cfg.CDRSAliaseSConns,
+				cfg.CDRSCDRStatSConns, cfg.CDRSThresholdSConns, cfg.CDRSStatSConns, cfg.CDRSOnlineCDRExports}
+		},
+	},
+	CDRSTATS_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.CDRStatsEnabled, cfg.CDRStatsSaveInterval}
+		},
+	},
+	CDRE_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.CdreProfiles },
+		reload: func(cfg, newCfg *CGRConfig) {
+			cdreReloadStruct := <-cfg.ConfigReloads[utils.CDRE] // Lock the exports out while replacing the templates
+			cfg.CdreProfiles = newCfg.CdreProfiles
+			cfg.ConfigReloads[utils.CDRE] <- cdreReloadStruct
+		},
+	},
+	CDRC_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.CdrcProfiles },
+		reload: func(cfg, newCfg *CGRConfig) {
+			cfg.CdrcProfiles = newCfg.CdrcProfiles
+			select { // restart the CDRCs unless a restart is already pending
+			case cfg.ConfigReloads[utils.CDRC] <- struct{}{}:
+			default:
+			}
+		},
+	},
+	SMGENERIC_JSON: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.SmGenericConfig },
+		reload: func(cfg, newCfg *CGRConfig) { // TTLs are read when sessions are created
+			cfg.SmGenericConfig.SessionTTL = newCfg.SmGenericConfig.SessionTTL
+			cfg.SmGenericConfig.SessionTTLMaxDelay = newCfg.SmGenericConfig.SessionTTLMaxDelay
+			cfg.SmGenericConfig.SessionTTLLastUsed = newCfg.SmGenericConfig.SessionTTLLastUsed
+			cfg.SmGenericConfig.SessionTTLUsage = newCfg.SmGenericConfig.SessionTTLUsage
+		},
+	},
+	SMFS_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.SmFsConfig },
+	},
+	SMKAM_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.SmKamConfig },
+	},
+	SMOSIPS_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.SmOsipsConfig },
+	},
+	SMAsteriskJSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.smAsteriskCfg },
+	},
+	DA_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.diameterAgentCfg },
+	},
+	RA_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.radiusAgentCfg },
+	},
+	HISTSERV_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.HistoryServerEnabled, cfg.HistoryDir, cfg.HistorySaveInterval}
+		},
+	},
+	PUBSUBSERV_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.PubSubServerEnabled },
+	},
+	ALIASESSERV_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.AliasesServerEnabled },
+	},
+	USERSERV_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.UserServerEnabled, cfg.UserServerIndexes}
+		},
+	},
+	RESOURCES_JSON: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.resourceSCfg },
+	},
+	STATS_JSON: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.statsCfg },
+		reload: func(cfg, newCfg *CGRConfig) {
+			cfg.statsCfg.IndexedFields = newCfg.statsCfg.IndexedFields
+		},
+	},
+	THRESHOLDS_JSON: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.thresholdSCfg },
+		reload: func(cfg, newCfg *CGRConfig) {
+			cfg.thresholdSCfg.IndexedFields = newCfg.thresholdSCfg.IndexedFields
+		},
+	},
+	SUPPLIERS_JSON: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.supplierSCfg },
+	},
+	INVOICES_JSON: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.invoiceSCfg },
+	},
+	APIAUTH_JSON: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.apiAuthCfg },
+	},
+	MAILER_JSN: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} {
+			return []interface{}{cfg.MailerServer, cfg.MailerAuthUser, cfg.MailerAuthPass, cfg.MailerFromAddr}
+		},
+	},
+	SURETAX_JSON: &cfgSection{
+		values: func(cfg *CGRConfig) interface{} { return cfg.sureTaxCfg },
+	},
+}
+
+// AddReloadHook registers hook to be called after the live settings of section were reloaded,
+// so the services can pick them up
+func (self *CGRConfig) AddReloadHook(section string, hook func()) {
+	self.reloadMux.Lock()
+	defer self.reloadMux.Unlock()
+	if self.reloadHooks == nil {
+		self.reloadHooks = make(map[string][]func())
+	}
+	self.reloadHooks[section] = append(self.reloadHooks[section], hook)
+}
+
+// Reload compares newCfg with the running configuration, applying the settings which can change live.
+// Sections with other changes are reported as requiring a restart.
+func (self *CGRConfig) Reload(newCfg *CGRConfig) (rpl *ConfigReloadReport) {
+	self.reloadMux.Lock()
+	defer self.reloadMux.Unlock()
+	rpl = new(ConfigReloadReport)
+	sections := make([]string, 0, len(cfgSections))
+	for section := range cfgSections {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		cfgSect := cfgSections[section]
+		if reflect.DeepEqual(cfgSect.values(self), cfgSect.values(newCfg)) {
+			continue
+		}
+		if cfgSect.reload != nil {
+			self.liveMux.Lock()
+			cfgSect.reload(self, newCfg)
+			self.liveMux.Unlock()
+			for _, hook := range self.reloadHooks[section] {
+				hook()
+			}
+		}
+		if reflect.DeepEqual(cfgSect.values(self), cfgSect.values(newCfg)) {
+			rpl.Reloaded = append(rpl.Reloaded, section)
+		} else {
+			rpl.RestartRequired = append(rpl.RestartRequired, section)
+		}
+	}
+	return
+}
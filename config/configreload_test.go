@@ -0,0 +1,91 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestCgrCfgReload(t *testing.T) {
+	cfg, _ := NewDefaultCGRConfig()
+	var hooked []string
+	for _, section := range []string{GENERAL_JSN, STATS_JSON, LISTEN_JSN} {
+		sect := section
+		cfg.AddReloadHook(sect, func() { hooked = append(hooked, sect) })
+	}
+	dfltCfg, _ := NewDefaultCGRConfig() // different InstanceID
+	if rpl := cfg.Reload(dfltCfg); len(rpl.Reloaded) != 0 || len(rpl.RestartRequired) != 0 {
+		t.Errorf("unexpected report for unchanged config: %+v", rpl)
+	}
+	newCfg, err := NewCGRConfigFromJsonStringWithDefaults(`{
+"general": {"log_level": 7, "reply_timeout": "5s"},
+"listen": {"rpc_json": ":3012"},
+"stats": {"indexed_fields": ["Account"]},
+"thresholds": {"enabled": true, "indexed_fields": ["Account"]},
+"sm_generic": {"session_ttl": "30s"},
+"cdre": {"*default": {"export_format": "*file_fwv"}},
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpl := cfg.Reload(newCfg)
+	eRpl := &ConfigReloadReport{
+		Reloaded:        []string{CDRE_JSN, GENERAL_JSN, SMGENERIC_JSON, STATS_JSON},
+		RestartRequired: []string{LISTEN_JSN, THRESHOLDS_JSON},
+	}
+	if !reflect.DeepEqual(eRpl, rpl) {
+		t.Errorf("expecting: %+v, received: %+v", eRpl, rpl)
+	}
+	if eHooked := []string{GENERAL_JSN, STATS_JSON}; !reflect.DeepEqual(eHooked, hooked) {
+		t.Errorf("expecting hooks: %+v, received: %+v", eHooked, hooked)
+	}
+	if _, _, _, replyTimeout, _ := cfg.RPCConnCfg(); cfg.LogLevel != 7 || replyTimeout != 5*time.Second {
+		t.Errorf("general, log_level: %d, reply_timeout: %v", cfg.LogLevel, replyTimeout)
+	}
+	if ttl, _ := cfg.SMGSessionTTL(); ttl != 30*time.Second {
+		t.Errorf("session_ttl: %v", ttl)
+	}
+	if !reflect.DeepEqual([]string{utils.ACCOUNT}, cfg.StatSCfg().IndexedFields) {
+		t.Errorf("stats indexed_fields: %+v", cfg.StatSCfg().IndexedFields)
+	}
+	if cfg.CdreProfilesCfg()[utils.META_DEFAULT].ExportFormat != utils.MetaFileFWV {
+		t.Errorf("cdre export_format: %s", cfg.CdreProfilesCfg()[utils.META_DEFAULT].ExportFormat)
+	}
+	// live settings of the sections requiring restart are still applied, the others not
+	if !reflect.DeepEqual([]string{utils.ACCOUNT}, cfg.ThresholdSCfg().IndexedFields) || cfg.ThresholdSCfg().Enabled {
+		t.Errorf("thresholds: %+v", cfg.ThresholdSCfg())
+	}
+	if cfg.RPCJSONListen == ":3012" {
+		t.Error("listen should not be reloaded")
+	}
+	tntCfg, err := NewCGRConfigFromJsonStringWithDefaults(`{"general": {"log_level": 3, "default_tenant": "cgrates.net"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rpl := dfltCfg.Reload(tntCfg); len(rpl.Reloaded) != 0 ||
+		!reflect.DeepEqual([]string{GENERAL_JSN}, rpl.RestartRequired) {
+		t.Errorf("default_tenant, received: %+v", rpl)
+	} else if dfltCfg.LogLevel != 3 || dfltCfg.DefaultTenant == "cgrates.net" {
+		t.Errorf("log_level: %d, default_tenant: %s", dfltCfg.LogLevel, dfltCfg.DefaultTenant)
+	}
+}
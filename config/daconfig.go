@@ -19,11 +19,15 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/cgrates/cgrates/utils"
 )
 
+// DiameterCCRCommandCode is the Credit-Control command, SMG action for it is derived out of CC-Request-Type
+const DiameterCCRCommandCode = 272
+
 type DiameterAgentCfg struct {
 	Enabled            bool   // enables the diameter agent: <true|false>
 	Listen             string // address where to listen for diameter requests <x.y.z.y:1234>
@@ -107,12 +111,19 @@ func (self *DiameterAgentCfg) loadFromJsonCfg(jsnCfg *DiameterAgentJsonCfg) erro
 				}
 			}
 			if err := rp.loadFromJsonCfg(reqProcJsn); err != nil {
-				return nil
+				return err
 			}
 			if !haveID {
 				self.RequestProcessors = append(self.RequestProcessors, rp)
 			}
 		}
+		for _, rp := range self.RequestProcessors { // checked once all are loaded since they can be built out of multiple files
+			if rp.CommandCode != 0 && rp.CommandCode != DiameterCCRCommandCode &&
+				rp.SMGAction == "" && !rp.DryRun {
+				return fmt.Errorf("<DiameterAgent> request processor: <%s> for command code: %d requires smg_action",
+					rp.Id, rp.CommandCode)
+			}
+		}
 	}
 	return nil
 }
@@ -122,8 +133,11 @@ type DARequestProcessor struct {
 	Id                string
 	DryRun            bool
 	PublishEvent      bool
+	CommandCode       int // Diameter command matched by processor, CCR if not defined
+	ApplicationID     int // Diameter application matched by processor, any if not defined
 	RequestFilter     utils.RSRFields
 	Flags             utils.StringMap // Various flags to influence behavior
+	SMGAction         string          // SMG API called, derived out of CC-Request-Type if not defined
 	ContinueOnSuccess bool
 	AppendCCA         bool
 	CCRFields         []*CfgCdrField
@@ -143,6 +157,12 @@ func (self *DARequestProcessor) loadFromJsonCfg(jsnCfg *DARequestProcessorJsnCfg
 	if jsnCfg.Publish_event != nil {
 		self.PublishEvent = *jsnCfg.Publish_event
 	}
+	if jsnCfg.Command_code != nil {
+		self.CommandCode = *jsnCfg.Command_code
+	}
+	if jsnCfg.Application_id != nil {
+		self.ApplicationID = *jsnCfg.Application_id
+	}
 	var err error
 	if jsnCfg.Request_filter != nil {
 		if self.RequestFilter, err = utils.ParseRSRFields(*jsnCfg.Request_filter, utils.INFIELD_SEP); err != nil {
@@ -152,6 +172,9 @@ func (self *DARequestProcessor) loadFromJsonCfg(jsnCfg *DARequestProcessorJsnCfg
 	if jsnCfg.Flags != nil {
 		self.Flags = utils.StringMapFromSlice(*jsnCfg.Flags)
 	}
+	if jsnCfg.Smg_action != nil {
+		self.SMGAction = *jsnCfg.Smg_action
+	}
 	if jsnCfg.Continue_on_success != nil {
 		self.ContinueOnSuccess = *jsnCfg.Continue_on_success
 	}
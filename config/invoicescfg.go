@@ -0,0 +1,61 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package config
+
+type InvoiceSCfg struct {
+	Enabled    bool
+	RunIDs     []string          // derived charging runs billed on the invoices
+	TaxPercent float64           // applied on the invoice subtotal
+	LocalTaxes bool              // add the taxes of the local TaxProfiles, computed per CDR
+	IDFormat   string            // fmt layout of the invoice ID out of the tenant sequence number
+	Templates  map[string]string // template name to text/template file path
+}
+
+func (inv *InvoiceSCfg) loadFromJsonCfg(jsnCfg *InvoiceSJsonCfg) (err error) {
+	if jsnCfg == nil {
+		return nil
+	}
+	if jsnCfg.Enabled != nil {
+		inv.Enabled = *jsnCfg.Enabled
+	}
+	if jsnCfg.Run_ids != nil {
+		inv.RunIDs = make([]string, len(*jsnCfg.Run_ids))
+		for i, runID := range *jsnCfg.Run_ids {
+			inv.RunIDs[i] = runID
+		}
+	}
+	if jsnCfg.Tax_percent != nil {
+		inv.TaxPercent = *jsnCfg.Tax_percent
+	}
+	if jsnCfg.Local_taxes != nil {
+		inv.LocalTaxes = *jsnCfg.Local_taxes
+	}
+	if jsnCfg.Id_format != nil {
+		inv.IDFormat = *jsnCfg.Id_format
+	}
+	if jsnCfg.Templates != nil {
+		if inv.Templates == nil {
+			inv.Templates = make(map[string]string)
+		}
+		for tplName, tplPath := range *jsnCfg.Templates {
+			inv.Templates[tplName] = tplPath
+		}
+	}
+	return nil
+}
@@ -20,39 +20,52 @@ package config
 
 // General config section
 type GeneralJsonCfg struct {
-	Instance_id          *string
-	Logger               *string
-	Log_level            *int
-	Http_skip_tls_verify *bool
-	Rounding_decimals    *int
-	Dbdata_encoding      *string
-	Tpexport_dir         *string
-	Poster_attempts      *int
-	Failed_posts_dir     *string
-	Default_request_type *string
-	Default_category     *string
-	Default_tenant       *string
-	Default_timezone     *string
-	Connect_attempts     *int
-	Reconnects           *int
-	Connect_timeout      *string
-	Reply_timeout        *string
-	Response_cache_ttl   *string
-	Internal_ttl         *string
-	Locking_timeout      *string
+	Instance_id               *string
+	Logger                    *string
+	Log_level                 *int
+	Http_skip_tls_verify      *bool
+	Rounding_decimals         *int
+	Dbdata_encoding           *string
+	Tpexport_dir              *string
+	Poster_attempts           *int
+	Failed_posts_dir          *string
+	Default_request_type      *string
+	Default_category          *string
+	Default_tenant            *string
+	Default_timezone          *string
+	Connect_attempts          *int
+	Reconnects                *int
+	Connect_timeout           *string
+	Reply_timeout             *string
+	Response_cache_ttl        *string
+	Idempotency_ttl           *string
+	Internal_ttl              *string
+	Locking_timeout           *string
+	Cache_replication_conns   *[]*HaPoolJsonCfg
+	Tls_client_certificate    *string
+	Tls_client_key            *string
+	Tls_client_ca_certificate *string
 }
 
 // Listen config section
 type ListenJsonCfg struct {
-	Rpc_json *string
-	Rpc_gob  *string
-	Http     *string
+	Rpc_json           *string
+	Rpc_gob            *string
+	Http               *string
+	Rpc_json_tls       *string
+	Rpc_gob_tls        *string
+	Http_tls           *string
+	Tls_certificate    *string
+	Tls_key            *string
+	Tls_ca_certificate *string
+	Tls_client_auth    *string
 }
 
 // HTTP config section
 type HTTPJsonCfg struct {
 	Json_rpc_url   *string
 	Ws_url         *string
+	Metrics_url    *string
 	Use_basic_auth *bool
 	Auth_users     *map[string]string
 }
@@ -87,6 +100,8 @@ type RalsJsonCfg struct {
 	Pubsubs_conns               *[]*HaPoolJsonCfg
 	Aliases_conns               *[]*HaPoolJsonCfg
 	Users_conns                 *[]*HaPoolJsonCfg
+	Resources_conns             *[]*HaPoolJsonCfg
+	Lcr_replication_conns       *[]*HaPoolJsonCfg
 	Rp_subject_prefix_matching  *bool
 	Lcr_subject_prefix_matching *bool
 }
@@ -155,11 +170,19 @@ type CdreJsonCfg struct {
 	Export_format         *string
 	Export_path           *string
 	Cdr_filter            *string
+	Filters               *[]string
+	Ordering              *string
 	Synchronous           *bool
 	Attempts              *int
 	Field_separator       *string
 	Usage_multiply_factor *map[string]float64
 	Cost_multiply_factor  *float64
+	Rotate_size           *int
+	Rotate_interval       *string
+	Batch_size            *int
+	Retry_interval        *string
+	Retry_max_interval    *string
+	Retry_attempts        *int
 	Header_fields         *[]*CdrFieldJsonCfg
 	Content_fields        *[]*CdrFieldJsonCfg
 	Trailer_fields        *[]*CdrFieldJsonCfg
@@ -194,19 +217,22 @@ type CdrcJsonCfg struct {
 
 // SM-Generic config section
 type SmGenericJsonCfg struct {
-	Enabled               *bool
-	Listen_bijson         *string
-	Rals_conns            *[]*HaPoolJsonCfg
-	Cdrs_conns            *[]*HaPoolJsonCfg
-	Smg_replication_conns *[]*HaPoolJsonCfg
-	Debit_interval        *string
-	Min_call_duration     *string
-	Max_call_duration     *string
-	Session_ttl           *string
-	Session_ttl_max_delay *string
-	Session_ttl_last_used *string
-	Session_ttl_usage     *string
-	Session_indexes       *[]string
+	Enabled                *bool
+	Listen_bijson          *string
+	Listen_bijson_tls      *string
+	Rals_conns             *[]*HaPoolJsonCfg
+	Cdrs_conns             *[]*HaPoolJsonCfg
+	Smg_replication_conns  *[]*HaPoolJsonCfg
+	Debit_interval         *string
+	Min_call_duration      *string
+	Max_call_duration      *string
+	Session_ttl            *string
+	Session_ttl_max_delay  *string
+	Session_ttl_last_used  *string
+	Session_ttl_usage      *string
+	Session_ttl_disconnect *bool
+	Session_indexes        *[]string
+	Store_sessions         *bool
 }
 
 // SM-FreeSWITCH config section
@@ -235,6 +261,8 @@ type HaPoolJsonCfg struct {
 	Address     *string
 	Transport   *string
 	Synchronous *bool
+	Tls         *bool
+	Api_key     *string
 }
 
 type AstConnJsonCfg struct {
@@ -257,6 +285,7 @@ type CacheParamJsonCfg struct {
 	Ttl        *string
 	Static_ttl *bool
 	Precache   *bool
+	Replicate  *bool
 }
 
 type CacheJsonCfg map[string]*CacheParamJsonCfg
@@ -270,15 +299,16 @@ type FsConnJsonCfg struct {
 
 // SM-Kamailio config section
 type SmKamJsonCfg struct {
-	Enabled           *bool
-	Rals_conns        *[]*HaPoolJsonCfg
-	Cdrs_conns        *[]*HaPoolJsonCfg
-	Resources_conns   *[]*HaPoolJsonCfg
-	Create_cdr        *bool
-	Debit_interval    *string
-	Min_call_duration *string
-	Max_call_duration *string
-	Evapi_conns       *[]*KamConnJsonCfg
+	Enabled              *bool
+	Rals_conns           *[]*HaPoolJsonCfg
+	Cdrs_conns           *[]*HaPoolJsonCfg
+	Resources_conns      *[]*HaPoolJsonCfg
+	Create_cdr           *bool
+	Debit_interval       *string
+	Min_call_duration    *string
+	Max_call_duration    *string
+	Dialog_sync_interval *string
+	Evapi_conns          *[]*KamConnJsonCfg
 }
 
 // Represents one connection instance towards Kamailio
@@ -330,8 +360,11 @@ type DARequestProcessorJsnCfg struct {
 	Id                  *string
 	Dry_run             *bool
 	Publish_event       *bool
+	Command_code        *int
+	Application_id      *int
 	Request_filter      *string
 	Flags               *[]string
+	Smg_action          *string
 	Continue_on_success *bool
 	Append_cca          *bool
 	CCR_fields          *[]*CdrFieldJsonCfg
@@ -349,7 +382,12 @@ type RadiusAgentJsonCfg struct {
 	Sm_generic_conns     *[]*HaPoolJsonCfg
 	Create_cdr           *bool
 	Cdr_requires_session *bool
+	Interim_interval     *string
 	Timezone             *string
+	Nas_address          *string
+	Dynauth_port         *int
+	Coa_template         *[]*CdrFieldJsonCfg
+	Dm_template          *[]*CdrFieldJsonCfg
 	Request_processors   *[]*RAReqProcessorJsnCfg
 }
 
@@ -410,6 +448,30 @@ type ThresholdSJsonCfg struct {
 	Indexed_fields *[]string
 }
 
+// Invoice service config section
+type APIAuthJsonCfg struct {
+	Enabled   *bool
+	Admin_key *string
+}
+
+type InvoiceSJsonCfg struct {
+	Enabled     *bool
+	Run_ids     *[]string
+	Tax_percent *float64
+	Local_taxes *bool
+	Id_format   *string
+	Templates   *map[string]string
+}
+
+// Supplier service config section
+type SupplierSJsonCfg struct {
+	Enabled         *bool
+	Indexed_fields  *[]string
+	Rals_conns      *[]*HaPoolJsonCfg
+	Resources_conns *[]*HaPoolJsonCfg
+	Stats_conns     *[]*HaPoolJsonCfg
+}
+
 // Mailer config section
 type MailerJsonCfg struct {
 	Server        *string
@@ -19,6 +19,8 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package config
 
 import (
+	"time"
+
 	"github.com/cgrates/cgrates/utils"
 )
 
@@ -32,7 +34,12 @@ type RadiusAgentCfg struct {
 	SMGenericConns     []*HaPoolConfig
 	CreateCDR          bool
 	CDRRequiresSession bool
+	InterimInterval    time.Duration // Acct-Interim-Interval used by the NAS
 	Timezone           string
+	NasAddress         utils.RSRFields // composes the NAS address out of the Accounting-Request starting the session
+	DynAuthPort        int             // port on the NAS receiving CoA and Disconnect requests
+	CoATemplate        []*CfgCdrField  // attributes of the CoA-Request
+	DMTemplate         []*CfgCdrField  // attributes of the Disconnect-Request
 	RequestProcessors  []*RARequestProcessor
 }
 
@@ -81,9 +88,33 @@ func (self *RadiusAgentCfg) loadFromJsonCfg(jsnCfg *RadiusAgentJsonCfg) error {
 	if jsnCfg.Cdr_requires_session != nil {
 		self.CDRRequiresSession = *jsnCfg.Cdr_requires_session
 	}
+	var err error
+	if jsnCfg.Interim_interval != nil {
+		if self.InterimInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Interim_interval); err != nil {
+			return err
+		}
+	}
 	if jsnCfg.Timezone != nil {
 		self.Timezone = *jsnCfg.Timezone
 	}
+	if jsnCfg.Nas_address != nil {
+		if self.NasAddress, err = utils.ParseRSRFields(*jsnCfg.Nas_address, utils.INFIELD_SEP); err != nil {
+			return err
+		}
+	}
+	if jsnCfg.Dynauth_port != nil {
+		self.DynAuthPort = *jsnCfg.Dynauth_port
+	}
+	if jsnCfg.Coa_template != nil {
+		if self.CoATemplate, err = CfgCdrFieldsFromCdrFieldsJsonCfg(*jsnCfg.Coa_template); err != nil {
+			return err
+		}
+	}
+	if jsnCfg.Dm_template != nil {
+		if self.DMTemplate, err = CfgCdrFieldsFromCdrFieldsJsonCfg(*jsnCfg.Dm_template); err != nil {
+			return err
+		}
+	}
 	if jsnCfg.Request_processors != nil {
 		for _, reqProcJsn := range *jsnCfg.Request_processors {
 			rp := new(RARequestProcessor)
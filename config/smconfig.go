@@ -38,6 +38,8 @@ type HaPoolConfig struct {
 	Address     string
 	Transport   string
 	Synchronous bool
+	TLS         bool   // encrypt the connection using the tls_client_* settings of the general section
+	ApiKey      string // authenticates the connection towards engines with api_auth enabled
 }
 
 func (self *HaPoolConfig) loadFromJsonCfg(jsnCfg *HaPoolJsonCfg) error {
@@ -53,6 +55,12 @@ func (self *HaPoolConfig) loadFromJsonCfg(jsnCfg *HaPoolJsonCfg) error {
 	if jsnCfg.Synchronous != nil {
 		self.Synchronous = *jsnCfg.Synchronous
 	}
+	if jsnCfg.Tls != nil {
+		self.TLS = *jsnCfg.Tls
+	}
+	if jsnCfg.Api_key != nil {
+		self.ApiKey = *jsnCfg.Api_key
+	}
 	return nil
 }
 
@@ -89,19 +97,22 @@ func (self *FsConnConfig) loadFromJsonCfg(jsnCfg *FsConnJsonCfg) error {
 }
 
 type SmGenericConfig struct {
-	Enabled             bool
-	ListenBijson        string
-	RALsConns           []*HaPoolConfig
-	CDRsConns           []*HaPoolConfig
-	SMGReplicationConns []*HaPoolConfig
-	DebitInterval       time.Duration
-	MinCallDuration     time.Duration
-	MaxCallDuration     time.Duration
-	SessionTTL          time.Duration
-	SessionTTLMaxDelay  *time.Duration
-	SessionTTLLastUsed  *time.Duration
-	SessionTTLUsage     *time.Duration
-	SessionIndexes      utils.StringMap
+	Enabled              bool
+	ListenBijson         string
+	ListenBijsonTLS      string
+	RALsConns            []*HaPoolConfig
+	CDRsConns            []*HaPoolConfig
+	SMGReplicationConns  []*HaPoolConfig
+	DebitInterval        time.Duration
+	MinCallDuration      time.Duration
+	MaxCallDuration      time.Duration
+	SessionTTL           time.Duration
+	SessionTTLMaxDelay   *time.Duration
+	SessionTTLLastUsed   *time.Duration
+	SessionTTLUsage      *time.Duration
+	SessionTTLDisconnect bool // the client is asked to disconnect the sessions terminated on TTL
+	SessionIndexes       utils.StringMap
+	StoreSessions        bool
 }
 
 func (self *SmGenericConfig) loadFromJsonCfg(jsnCfg *SmGenericJsonCfg) error {
@@ -115,6 +126,9 @@ func (self *SmGenericConfig) loadFromJsonCfg(jsnCfg *SmGenericJsonCfg) error {
 	if jsnCfg.Listen_bijson != nil {
 		self.ListenBijson = *jsnCfg.Listen_bijson
 	}
+	if jsnCfg.Listen_bijson_tls != nil {
+		self.ListenBijsonTLS = *jsnCfg.Listen_bijson_tls
+	}
 	if jsnCfg.Rals_conns != nil {
 		self.RALsConns = make([]*HaPoolConfig, len(*jsnCfg.Rals_conns))
 		for idx, jsnHaCfg := range *jsnCfg.Rals_conns {
@@ -170,9 +184,15 @@ func (self *SmGenericConfig) loadFromJsonCfg(jsnCfg *SmGenericJsonCfg) error {
 			self.SessionTTLLastUsed = &sessionTTLLastUsed
 		}
 	}
+	if jsnCfg.Session_ttl_disconnect != nil {
+		self.SessionTTLDisconnect = *jsnCfg.Session_ttl_disconnect
+	}
 	if jsnCfg.Session_indexes != nil {
 		self.SessionIndexes = utils.StringMapFromSlice(*jsnCfg.Session_indexes)
 	}
+	if jsnCfg.Store_sessions != nil {
+		self.StoreSessions = *jsnCfg.Store_sessions
+	}
 	return nil
 }
 
@@ -315,15 +335,16 @@ func (self *KamConnConfig) loadFromJsonCfg(jsnCfg *KamConnJsonCfg) error {
 
 // SM-Kamailio config section
 type SmKamConfig struct {
-	Enabled         bool
-	RALsConns       []*HaPoolConfig
-	CDRsConns       []*HaPoolConfig
-	RLsConns        []*HaPoolConfig
-	CreateCdr       bool
-	DebitInterval   time.Duration
-	MinCallDuration time.Duration
-	MaxCallDuration time.Duration
-	EvapiConns      []*KamConnConfig
+	Enabled            bool
+	RALsConns          []*HaPoolConfig
+	CDRsConns          []*HaPoolConfig
+	RLsConns           []*HaPoolConfig
+	CreateCdr          bool
+	DebitInterval      time.Duration
+	MinCallDuration    time.Duration
+	MaxCallDuration    time.Duration
+	DialogSyncInterval time.Duration
+	EvapiConns         []*KamConnConfig
 }
 
 func (self *SmKamConfig) loadFromJsonCfg(jsnCfg *SmKamJsonCfg) error {
@@ -373,6 +394,11 @@ func (self *SmKamConfig) loadFromJsonCfg(jsnCfg *SmKamJsonCfg) error {
 			return err
 		}
 	}
+	if jsnCfg.Dialog_sync_interval != nil {
+		if self.DialogSyncInterval, err = utils.ParseDurationWithSecs(*jsnCfg.Dialog_sync_interval); err != nil {
+			return err
+		}
+	}
 	if jsnCfg.Evapi_conns != nil {
 		self.EvapiConns = make([]*KamConnConfig, len(*jsnCfg.Evapi_conns))
 		for idx, jsnConnCfg := range *jsnCfg.Evapi_conns {
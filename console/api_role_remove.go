@@ -0,0 +1,60 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package console
+
+func init() {
+	c := &CmdRemAPIRole{
+		name:      "api_role_remove",
+		rpcMethod: "ApierV1.RemAPIRole",
+	}
+	commands[c.Name()] = c
+	c.CommandExecuter = &CommandExecuter{c}
+}
+
+// Commander implementation
+type CmdRemAPIRole struct {
+	name      string
+	rpcMethod string
+	rpcParams *StringWrapper
+	*CommandExecuter
+}
+
+func (self *CmdRemAPIRole) Name() string {
+	return self.name
+}
+
+func (self *CmdRemAPIRole) RpcMethod() string {
+	return self.rpcMethod
+}
+
+func (self *CmdRemAPIRole) RpcParams(reset bool) interface{} {
+	if reset || self.rpcParams == nil {
+		self.rpcParams = &StringWrapper{}
+	}
+	return self.rpcParams
+}
+
+func (self *CmdRemAPIRole) PostprocessRpcParams() error {
+	return nil
+}
+
+func (self *CmdRemAPIRole) RpcResult() interface{} {
+	var s string
+	return &s
+}
@@ -0,0 +1 @@
+ALTER TABLE cdrs MODIFY cost DECIMAL(30,10) NOT NULL;
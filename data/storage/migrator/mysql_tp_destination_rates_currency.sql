@@ -0,0 +1,2 @@
+use cgrates;
+ALTER TABLE tp_destination_rates ADD COLUMN `currency` varchar(3) NOT NULL DEFAULT '' AFTER `max_cost_strategy`;
@@ -0,0 +1,4 @@
+use cgrates;
+ALTER TABLE tp_stats ADD COLUMN `bucket` varchar(16) NOT NULL DEFAULT '' AFTER `thresholds`;
+ALTER TABLE tp_stats ADD COLUMN `bucket_timezone` varchar(64) NOT NULL DEFAULT '' AFTER `bucket`;
+ALTER TABLE tp_stats ADD COLUMN `bucket_history` int(11) NOT NULL DEFAULT 0 AFTER `bucket_timezone`;
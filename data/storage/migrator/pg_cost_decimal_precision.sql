@@ -0,0 +1 @@
+ALTER TABLE cdrs ALTER COLUMN cost TYPE NUMERIC(30,10);
@@ -0,0 +1 @@
+ALTER TABLE tp_destination_rates ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '';
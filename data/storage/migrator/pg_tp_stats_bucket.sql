@@ -0,0 +1,3 @@
+ALTER TABLE tp_stats ADD COLUMN bucket VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE tp_stats ADD COLUMN bucket_timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE tp_stats ADD COLUMN bucket_history INTEGER NOT NULL DEFAULT 0;
@@ -26,7 +26,7 @@ CREATE TABLE cdrs (
   disconnect_cause varchar(64) NOT NULL,
   extra_fields text NOT NULL,
   cost_source varchar(64) NOT NULL,
-  cost DECIMAL(20,4) NOT NULL,
+  cost DECIMAL(30,10) NOT NULL,
   cost_details text,
   account_summary text,
   extra_info text,
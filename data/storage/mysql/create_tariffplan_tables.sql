@@ -71,6 +71,7 @@ CREATE TABLE `tp_destination_rates` (
   `rounding_decimals` tinyint(4) NOT NULL,
   `max_cost` decimal(7,4) NOT NULL,
   `max_cost_strategy` varchar(16) NOT NULL,
+  `currency` varchar(3) NOT NULL DEFAULT '',
   `created_at` TIMESTAMP,
   PRIMARY KEY (`id`),
   KEY `tpid` (`tpid`),
@@ -436,6 +437,9 @@ CREATE TABLE tp_stats (
   `weight` decimal(8,2) NOT NULL,
   `min_items` int(11) NOT NULL,
   `thresholds` varchar(64) NOT NULL,
+  `bucket` varchar(16) NOT NULL DEFAULT '',
+  `bucket_timezone` varchar(64) NOT NULL DEFAULT '',
+  `bucket_history` int(11) NOT NULL DEFAULT 0,
   `created_at` TIMESTAMP,
   PRIMARY KEY (`pk`),
   KEY `tpid` (`tpid`),
@@ -467,6 +471,51 @@ CREATE TABLE tp_thresholds (
   UNIQUE KEY `unique_tp_thresholds` (`tpid`,`tenant`, `id`,`filter_ids`,`action_ids`)
 );
 
+--
+-- Table structure for table `tp_suppliers`
+--
+
+DROP TABLE IF EXISTS tp_suppliers;
+CREATE TABLE tp_suppliers (
+  `pk` int(11) NOT NULL AUTO_INCREMENT,
+  `tpid` varchar(64) NOT NULL,
+  `tenant` varchar(64) NOT NULL,
+  `id` varchar(64) NOT NULL,
+  `filter_ids` varchar(64) NOT NULL,
+  `activation_interval` varchar(64) NOT NULL,
+  `sorting` varchar(32) NOT NULL,
+  `sorting_params` varchar(64) NOT NULL,
+  `supplier_id` varchar(32) NOT NULL,
+  `supplier_filter_ids` varchar(64) NOT NULL,
+  `supplier_rating_subject` varchar(64) NOT NULL,
+  `supplier_resource_ids` varchar(64) NOT NULL,
+  `supplier_stat_ids` varchar(64) NOT NULL,
+  `supplier_weight` decimal(8,2) NOT NULL,
+  `supplier_parameters` varchar(64) NOT NULL,
+  `weight` decimal(8,2) NOT NULL,
+  `created_at` TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  KEY `tpid` (`tpid`),
+  UNIQUE KEY `unique_tp_suppliers` (`tpid`,`tenant`, `id`,`filter_ids`,`supplier_id`)
+);
+
+--
+-- Table structure for table `tp_exchange_rates`
+--
+
+DROP TABLE IF EXISTS tp_exchange_rates;
+CREATE TABLE tp_exchange_rates (
+  `pk` int(11) NOT NULL AUTO_INCREMENT,
+  `tpid` varchar(64) NOT NULL,
+  `from_currency` varchar(3) NOT NULL,
+  `to_currency` varchar(3) NOT NULL,
+  `rate` decimal(16,8) NOT NULL,
+  `created_at` TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  KEY `tpid` (`tpid`),
+  UNIQUE KEY `unique_tp_exchange_rates` (`tpid`,`from_currency`,`to_currency`)
+);
+
 --
 -- Table structure for table `tp_filter`
 --
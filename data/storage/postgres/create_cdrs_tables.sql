@@ -26,7 +26,7 @@ CREATE TABLE cdrs (
  disconnect_cause VARCHAR(64) NOT NULL,
  extra_fields jsonb NOT NULL,
  cost_source VARCHAR(64) NOT NULL,
- cost NUMERIC(20,4) DEFAULT NULL,
+ cost NUMERIC(30,10) DEFAULT NULL,
  cost_details jsonb,
  account_summary jsonb,
  extra_info text,
@@ -68,6 +68,7 @@ CREATE TABLE tp_destination_rates (
   rounding_decimals SMALLINT NOT NULL,
   max_cost NUMERIC(7,4) NOT NULL,
   max_cost_strategy VARCHAR(16) NOT NULL,
+  currency VARCHAR(3) NOT NULL DEFAULT '',
   created_at TIMESTAMP WITH TIME ZONE,
   UNIQUE (tpid, tag , destinations_tag)
 );
@@ -432,6 +433,9 @@ CREATE TABLE tp_stats (
   "weight" decimal(8,2) NOT NULL,
   "min_items" INTEGER NOT NULL,
   "thresholds" varchar(64) NOT NULL,
+  "bucket" varchar(16) NOT NULL DEFAULT '',
+  "bucket_timezone" varchar(64) NOT NULL DEFAULT '',
+  "bucket_history" INTEGER NOT NULL DEFAULT 0,
   "created_at" TIMESTAMP WITH TIME ZONE
 );
 CREATE INDEX tp_stats_idx ON tp_stats (tpid);
@@ -461,6 +465,49 @@ CREATE TABLE tp_thresholds (
 CREATE INDEX tp_thresholds_idx ON tp_thresholds (tpid);
 CREATE INDEX tp_thresholds_unique ON tp_thresholds  ("tpid","tenant", "id","filter_ids","action_ids");
 
+--
+-- Table structure for table `tp_suppliers`
+--
+
+DROP TABLE IF EXISTS tp_suppliers;
+CREATE TABLE tp_suppliers (
+  "pk" SERIAL PRIMARY KEY,
+  "tpid" varchar(64) NOT NULL,
+  "tenant" varchar(64) NOT NULL,
+  "id" varchar(64) NOT NULL,
+  "filter_ids" varchar(64) NOT NULL,
+  "activation_interval" varchar(64) NOT NULL,
+  "sorting" varchar(32) NOT NULL,
+  "sorting_params" varchar(64) NOT NULL,
+  "supplier_id" varchar(32) NOT NULL,
+  "supplier_filter_ids" varchar(64) NOT NULL,
+  "supplier_rating_subject" varchar(64) NOT NULL,
+  "supplier_resource_ids" varchar(64) NOT NULL,
+  "supplier_stat_ids" varchar(64) NOT NULL,
+  "supplier_weight" decimal(8,2) NOT NULL,
+  "supplier_parameters" varchar(64) NOT NULL,
+  "weight" decimal(8,2) NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX tp_suppliers_idx ON tp_suppliers (tpid);
+CREATE INDEX tp_suppliers_unique ON tp_suppliers  ("tpid","tenant", "id","filter_ids","supplier_id");
+
+--
+-- Table structure for table `tp_exchange_rates`
+--
+
+DROP TABLE IF EXISTS tp_exchange_rates;
+CREATE TABLE tp_exchange_rates (
+  "pk" SERIAL PRIMARY KEY,
+  "tpid" varchar(64) NOT NULL,
+  "from_currency" varchar(3) NOT NULL,
+  "to_currency" varchar(3) NOT NULL,
+  "rate" NUMERIC(16,8) NOT NULL,
+  "created_at" TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX tp_exchange_rates_idx ON tp_exchange_rates (tpid);
+CREATE INDEX tp_exchange_rates_unique ON tp_exchange_rates  ("tpid","from_currency","to_currency");
+
 --
 -- Table structure for table `tp_filter`
 --
@@ -505,7 +505,9 @@ func (ub *Account) debitCreditBalance(cd *CallDescriptor, count bool, dryRun boo
 
 		if initialLength == 0 {
 			// this is the first add, debit the connect fee
-			ok, debitedConnectFeeBalance = ub.DebitConnectionFee(cc, usefulMoneyBalances, count, true)
+			if ok, debitedConnectFeeBalance, err = ub.DebitConnectionFee(cc, usefulMoneyBalances, count, true); err != nil {
+				return nil, err
+			}
 		}
 		//log.Printf("Left CC: %+v ", leftCC)
 		// get the default money balanance
@@ -525,11 +527,7 @@ func (ub *Account) debitCreditBalance(cd *CallDescriptor, count bool, dryRun boo
 					Duration: 0,
 					Cost:     ts.RateInterval.Rating.ConnectFee,
 					BalanceInfo: &DebitInfo{
-						Monetary: &MonetaryInfo{
-							UUID:  debitedConnectFeeBalance.Uuid,
-							ID:    debitedConnectFeeBalance.ID,
-							Value: debitedConnectFeeBalance.Value,
-						},
+						Monetary:  connectFeeInfo(&debitedConnectFeeBalance, ts.RateInterval.rateCurrency()),
 						AccountID: ub.ID,
 					},
 				}
@@ -545,13 +543,18 @@ func (ub *Account) debitCreditBalance(cd *CallDescriptor, count bool, dryRun boo
 					continue
 				}
 
-				cost := increment.Cost
 				defaultBalance := ub.GetDefaultMoneyBalance()
+				var xr float64
+				if xr, err = defaultBalance.defaultExchangeRate(ts.RateInterval.rateCurrency()); err != nil {
+					return nil, err
+				}
+				cost := convertCost(increment.Cost, xr)
 				defaultBalance.SubstractValue(cost)
 				increment.BalanceInfo.Monetary = &MonetaryInfo{
-					UUID:  defaultBalance.Uuid,
-					ID:    defaultBalance.ID,
-					Value: defaultBalance.Value,
+					UUID:         defaultBalance.Uuid,
+					ID:           defaultBalance.ID,
+					Value:        defaultBalance.Value,
+					ExchangeRate: recordedExchangeRate(xr),
 				}
 				increment.BalanceInfo.AccountID = ub.ID
 				increment.paid = true
@@ -841,26 +844,31 @@ func (acc *Account) Clone() *Account {
 	return newAcc
 }
 
-func (acc *Account) DebitConnectionFee(cc *CallCost, usefulMoneyBalances Balances, count bool, block bool) (bool, Balance) {
+func (acc *Account) DebitConnectionFee(cc *CallCost, usefulMoneyBalances Balances, count bool, block bool) (bool, Balance, error) {
 	var debitedBalance Balance
 
 	if cc.deductConnectFee {
 		connectFee := cc.GetConnectFee()
+		rateCurrency := cc.GetCurrency()
 		//log.Print("CONNECT FEE: %f", connectFee)
 		connectFeePaid := false
 		for _, b := range usefulMoneyBalances {
-			if b.GetValue() >= connectFee {
-				b.SubstractValue(connectFee)
+			xr, err := b.exchangeRate(rateCurrency)
+			if err != nil { // balance cannot pay in this currency
+				continue
+			}
+			if fee := convertCost(connectFee, xr); b.GetValue() >= fee {
+				b.SubstractValue(fee)
 				// the conect fee is not refundable!
 				if count {
-					acc.countUnits(connectFee, utils.MONETARY, cc, b)
+					acc.countUnits(fee, utils.MONETARY, cc, b)
 				}
 				connectFeePaid = true
 				debitedBalance = *b
 				break
 			}
 			if b.Blocker && block { // stop here
-				return false, debitedBalance
+				return false, debitedBalance, nil
 			}
 		}
 		// debit connect fee
@@ -868,15 +876,20 @@ func (acc *Account) DebitConnectionFee(cc *CallCost, usefulMoneyBalances Balance
 			cc.negativeConnectFee = true
 			// there are no money for the connect fee; go negative
 			b := acc.GetDefaultMoneyBalance()
-			b.SubstractValue(connectFee)
+			xr, err := b.defaultExchangeRate(rateCurrency)
+			if err != nil {
+				return false, debitedBalance, err
+			}
+			fee := convertCost(connectFee, xr)
+			b.SubstractValue(fee)
 			debitedBalance = *b
 			// the conect fee is not refundable!
 			if count {
-				acc.countUnits(connectFee, utils.MONETARY, cc, b)
+				acc.countUnits(fee, utils.MONETARY, cc, b)
 			}
 		}
 	}
-	return true, debitedBalance
+	return true, debitedBalance, nil
 }
 
 func (acc *Account) matchActionFilter(condition string) (bool, error) {
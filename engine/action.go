@@ -79,6 +79,12 @@ const (
 	SET_DDESTINATIONS         = "*set_ddestinations"
 	TRANSFER_MONETARY_DEFAULT = "*transfer_monetary_default"
 	CGR_RPC                   = "*cgr_rpc"
+	EXTEND_EXPIRY             = "*extend_expiry"
+	CLONE_BALANCE             = "*clone_balance"
+	SET_ACCOUNT_ACTION_PLAN   = "*set_account_action_plan"
+	RESET_STAT_QUEUE          = "*reset_stat_queue"
+	RESET_THRESHOLD           = "*reset_threshold"
+	PUBLISH_EVENT             = "*publish_event"
 )
 
 func (a *Action) Clone() *Action {
@@ -115,6 +121,12 @@ func getActionFunc(typ string) (actionTypeFunc, bool) {
 		SET_BALANCE:               setBalanceAction,
 		TRANSFER_MONETARY_DEFAULT: transferMonetaryDefaultAction,
 		CGR_RPC:                   cgrRPCAction,
+		EXTEND_EXPIRY:             extendExpiryAction,
+		CLONE_BALANCE:             cloneBalanceAction,
+		SET_ACCOUNT_ACTION_PLAN:   setAccountActionPlanAction,
+		RESET_STAT_QUEUE:          resetStatQueueAction,
+		RESET_THRESHOLD:           resetThresholdAction,
+		PUBLISH_EVENT:             publishEventAction,
 	}
 	f, exists := actionFuncMap[typ]
 	return f, exists
@@ -650,14 +662,306 @@ func transferMonetaryDefaultAction(acc *Account, sq *CDRStatsQueueTriggered, a *
 			balance.ID != defaultBalance.ID && // extra caution
 			balance.MatchFilter(a.Balance, false) {
 			if balance.Value > 0 {
-				defaultBalance.Value += balance.Value
-				balance.Value = 0
+				defaultBalance.AddValue(balance.Value)
+				balance.SetValue(0)
 			}
 		}
 	}
 	return nil
 }
 
+// verifyActionParameters checks the ExtraParameters of the action types which cannot execute without them
+func verifyActionParameters(actionType, params string) (err error) {
+	switch actionType {
+	case EXTEND_EXPIRY:
+		_, err = parseExtendExpiryParams(params)
+	case CLONE_BALANCE, SET_ACCOUNT_ACTION_PLAN, RESET_STAT_QUEUE, RESET_THRESHOLD:
+		if params == "" {
+			err = errors.New("missing parameters")
+		}
+	case PUBLISH_EVENT:
+		_, err = parsePublishEventParams(params)
+	}
+	return
+}
+
+// actionTenantID parses the tenant:id out of the action parameters, the tenant of the account being used if missing
+func actionTenantID(acc *Account, params string) (tntID *utils.TenantID, err error) {
+	tntID = utils.NewTenantID(params)
+	if tntID.Tenant == "" && acc != nil {
+		var ta *utils.TenantAccount
+		if ta, err = utils.NewTAFromAccountKey(acc.ID); err != nil {
+			return nil, err
+		}
+		tntID.Tenant = ta.Tenant
+	}
+	if tntID.Tenant == "" || tntID.ID == "" {
+		return nil, fmt.Errorf("invalid parameters: <%s>", params)
+	}
+	return
+}
+
+// actionBalanceFilter returns the balance filter of the action without the expiry date,
+// which is populated out of the ExpiryTime of the action on execution
+func actionBalanceFilter(a *Action) (fltr *BalanceFilter) {
+	if a.Balance == nil {
+		return
+	}
+	bf := *a.Balance
+	bf.ExpirationDate = nil
+	return &bf
+}
+
+func parseExtendExpiryParams(params string) (d time.Duration, err error) {
+	if d, err = utils.ParseDurationWithSecs(params); err != nil {
+		return
+	}
+	if d == 0 {
+		return 0, errors.New("missing duration")
+	}
+	return
+}
+
+// extendExpiryAction pushes the expiry of the balances matching the filter with the duration in ExtraParameters
+// balances never expiring are left as they are
+func extendExpiryAction(acc *Account, sq *CDRStatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if acc == nil {
+		return errors.New("nil account")
+	}
+	d, err := parseExtendExpiryParams(a.ExtraParameters)
+	if err != nil {
+		return
+	}
+	fltr := actionBalanceFilter(a)
+	var found bool
+	for balType, bChain := range acc.BalanceMap {
+		if fltr.GetType() != "" && balType != fltr.GetType() {
+			continue
+		}
+		for _, b := range bChain {
+			if b.ExpirationDate.IsZero() || !b.MatchFilter(fltr, false) {
+				continue
+			}
+			b.ExpirationDate = b.ExpirationDate.Add(d)
+			found = true
+		}
+	}
+	if !found {
+		return utils.ErrNotFound
+	}
+	return
+}
+
+// cloneBalanceAction copies the balances matching the filter into the account in ExtraParameters, created if missing
+// balances with the same ID in the destination account are overwritten
+// the destination account is locked by the caller together with the source one, see cloneBalanceDestinations
+func cloneBalanceAction(acc *Account, sq *CDRStatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if acc == nil {
+		return errors.New("nil account")
+	}
+	dstTntID, err := actionTenantID(acc, a.ExtraParameters)
+	if err != nil {
+		return
+	}
+	dstAcntID := dstTntID.TenantID()
+	if dstAcntID == acc.ID {
+		return errors.New("cannot clone balances into the same account")
+	}
+	fltr := actionBalanceFilter(a)
+	clones := make(map[string]Balances)
+	for balType, bChain := range acc.BalanceMap {
+		if fltr.GetType() != "" && balType != fltr.GetType() {
+			continue
+		}
+		for _, b := range bChain {
+			if !b.MatchFilter(fltr, false) {
+				continue
+			}
+			bClone := b.Clone()
+			bClone.Uuid = utils.GenUUID()
+			clones[balType] = append(clones[balType], bClone)
+		}
+	}
+	if len(clones) == 0 {
+		return utils.ErrNotFound
+	}
+	dstAcc, err := dm.DataDB().GetAccount(dstAcntID)
+	if err != nil {
+		if err != utils.ErrNotFound {
+			return
+		}
+		dstAcc = &Account{ID: dstAcntID}
+	}
+	if dstAcc.BalanceMap == nil {
+		dstAcc.BalanceMap = make(map[string]Balances)
+	}
+	for balType, bClones := range clones {
+		for _, bClone := range bClones {
+			var replaced bool
+			if bClone.ID != "" {
+				for i, b := range dstAcc.BalanceMap[balType] {
+					if b.ID == bClone.ID {
+						dstAcc.BalanceMap[balType][i] = bClone
+						replaced = true
+						break
+					}
+				}
+			}
+			if !replaced {
+				dstAcc.BalanceMap[balType] = append(dstAcc.BalanceMap[balType], bClone)
+			}
+		}
+	}
+	return dm.DataDB().SetAccount(dstAcc)
+}
+
+// cloneBalanceDestinations returns the sorted IDs of the accounts the *clone_balance actions in acs write into,
+// so they can be locked together with the account accID in one guardian call
+func cloneBalanceDestinations(accID string, acs Actions) (dstAcntIDs []string) {
+	for _, a := range acs {
+		if a.ActionType != CLONE_BALANCE {
+			continue
+		}
+		dstTntID, err := actionTenantID(&Account{ID: accID}, a.ExtraParameters)
+		if err != nil { // reported by the action on execution
+			continue
+		}
+		if dstAcntID := dstTntID.TenantID(); dstAcntID != accID &&
+			!utils.IsSliceMember(dstAcntIDs, dstAcntID) {
+			dstAcntIDs = append(dstAcntIDs, dstAcntID)
+		}
+	}
+	sort.Strings(dstAcntIDs)
+	return
+}
+
+// setAccountActionPlanAction moves the account out of its action plans into the one in ExtraParameters
+// and reloads the scheduler so the change is applied right away
+func setAccountActionPlanAction(acc *Account, sq *CDRStatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if acc == nil {
+		return errors.New("nil account")
+	}
+	apID := a.ExtraParameters
+	if apID == "" {
+		return errors.New("missing ActionPlan ID")
+	}
+	_, err = guardian.Guardian.Guard(func() (interface{}, error) {
+		ap, err := dm.DataDB().GetActionPlan(apID, false, utils.NonTransactional)
+		if err != nil {
+			return 0, err
+		}
+		acntAPids, err := dm.DataDB().GetAccountActionPlans(acc.ID, false, utils.NonTransactional)
+		if err != nil && err != utils.ErrNotFound {
+			return 0, err
+		}
+		dirtyActionPlans := make(map[string]*ActionPlan)
+		for _, oldAPID := range acntAPids {
+			if oldAPID == apID {
+				continue
+			}
+			oldAP, err := dm.DataDB().GetActionPlan(oldAPID, false, utils.NonTransactional)
+			if err != nil {
+				return 0, err
+			}
+			delete(oldAP.AccountIDs, acc.ID)
+			dirtyActionPlans[oldAPID] = oldAP
+		}
+		if !utils.IsSliceMember(acntAPids, apID) {
+			if ap.AccountIDs == nil {
+				ap.AccountIDs = make(utils.StringMap)
+			}
+			ap.AccountIDs[acc.ID] = true
+			dirtyActionPlans[apID] = ap
+			for _, at := range ap.ActionTimings {
+				if at.IsASAP() {
+					if err = dm.DataDB().PushTask(&Task{Uuid: utils.GenUUID(),
+						AccountID: acc.ID, ActionsID: at.ActionsID}); err != nil {
+						return 0, err
+					}
+				}
+			}
+		}
+		apIDs := make([]string, 0, len(dirtyActionPlans))
+		for dirtyAPID, dirtyAP := range dirtyActionPlans {
+			if err = dm.DataDB().SetActionPlan(dirtyAPID, dirtyAP, true, utils.NonTransactional); err != nil {
+				return 0, err
+			}
+			apIDs = append(apIDs, dirtyAPID)
+		}
+		if err = dm.CacheDataFromDB(utils.ACTION_PLAN_PREFIX, apIDs, true); err != nil {
+			return 0, err
+		}
+		if err = dm.DataDB().SetAccountActionPlans(acc.ID, []string{apID}, true); err != nil {
+			return 0, err
+		}
+		return 0, dm.CacheDataFromDB(utils.AccountActionPlansPrefix, []string{acc.ID}, true)
+	}, 0, utils.ACTION_PLAN_PREFIX)
+	if err != nil || schedulerReload == nil {
+		return
+	}
+	return schedulerReload()
+}
+
+// resetStatQueueAction clears the StatQueue with the tenant:id in ExtraParameters
+func resetStatQueueAction(acc *Account, sq *CDRStatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if statS == nil {
+		return errors.New("StatS not connected")
+	}
+	tntID, err := actionTenantID(acc, a.ExtraParameters)
+	if err != nil {
+		return
+	}
+	var reply string
+	return statS.Call(utils.StatSv1ResetStatQueue, tntID, &reply)
+}
+
+// resetThresholdAction clears the hits of the Threshold with the tenant:id in ExtraParameters
+func resetThresholdAction(acc *Account, sq *CDRStatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if thresholdS == nil {
+		return errors.New("ThresholdS not connected")
+	}
+	tntID, err := actionTenantID(acc, a.ExtraParameters)
+	if err != nil {
+		return
+	}
+	var reply string
+	return thresholdS.Call(utils.ThresholdSv1ResetThreshold, tntID, &reply)
+}
+
+func parsePublishEventParams(params string) (evFlds map[string]string, err error) {
+	if params == "" {
+		return
+	}
+	err = json.Unmarshal([]byte(params), &evFlds)
+	return
+}
+
+// publishEventAction sends a CgrEvent to PubSub, the ExtraParameters being a JSON object with the fields to add
+func publishEventAction(acc *Account, sq *CDRStatsQueueTriggered, a *Action, acs Actions) (err error) {
+	if pubSubServer == nil {
+		return errors.New("PubSub not connected")
+	}
+	evFlds, err := parsePublishEventParams(a.ExtraParameters)
+	if err != nil {
+		return
+	}
+	evt := CgrEvent{
+		"EventName": utils.EVT_ACTION_EVENT,
+		"ActionIds": a.Id,
+	}
+	if acc != nil {
+		evt["Account"] = acc.ID
+	}
+	if sq != nil {
+		evt["CDRStatsQueueId"] = sq.Id
+	}
+	for fldName, fldVal := range evFlds {
+		evt[fldName] = fldVal
+	}
+	var reply string
+	return pubSubServer.Call("PubSubV1.Publish", evt, &reply)
+}
+
 type RPCRequest struct {
 	Address   string
 	Transport string
@@ -293,6 +293,8 @@ func (at *ActionTiming) Execute(successActions, failedActions chan *Action) (err
 		return
 	}
 	for accID, _ := range at.accountIDs {
+		lkIDs := append(cloneBalanceDestinations(accID, aac), accID)
+		sort.Strings(lkIDs) // same order for all executions so the ones cloning in opposite directions do not deadlock
 		_, err = guardian.Guardian.Guard(func() (interface{}, error) {
 			acc, err := dm.DataDB().GetAccount(accID)
 			if err != nil {
@@ -350,7 +352,7 @@ func (at *ActionTiming) Execute(successActions, failedActions chan *Action) (err
 				dm.DataDB().SetAccount(acc)
 			}
 			return 0, nil
-		}, 0, accID)
+		}, 0, lkIDs...)
 	}
 	if len(at.accountIDs) == 0 { // action timing executing without accounts
 		for _, a := range aac {
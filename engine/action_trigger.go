@@ -24,6 +24,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/cgrates/cgrates/guardian"
 	"github.com/cgrates/cgrates/utils"
 )
 
@@ -63,6 +64,12 @@ func (at *ActionTrigger) Execute(ub *Account, sq *CDRStatsQueueTriggered) (err e
 		return
 	}
 	aac.Sort()
+	if ub != nil { // the account itself is locked by the caller
+		if dstAcntIDs := cloneBalanceDestinations(ub.ID, aac); len(dstAcntIDs) != 0 {
+			guardian.Guardian.GuardIDs(0, dstAcntIDs...)
+			defer guardian.Guardian.UnguardIDs(dstAcntIDs...)
+		}
+	}
 	at.Executed = true
 	transactionFailed := false
 	removeAccountActionFound := false
@@ -2446,3 +2446,251 @@ func BenchmarkUUID(b *testing.B) {
 		b.StartTimer()
 	}
 }
+
+func TestActionExtendExpiry(t *testing.T) {
+	expTime := time.Date(2017, 12, 1, 0, 0, 0, 0, time.UTC)
+	if err := dm.DataDB().SetAccount(&Account{
+		ID: "cgrates.org:extexp",
+		BalanceMap: map[string]Balances{
+			utils.VOICE: Balances{
+				&Balance{ID: "v1", Uuid: utils.GenUUID(), Value: 10, ExpirationDate: expTime},
+				&Balance{ID: "v2", Uuid: utils.GenUUID(), Value: 20}, // never expiring
+			},
+			utils.MONETARY: Balances{
+				&Balance{ID: "m1", Uuid: utils.GenUUID(), Value: 1, ExpirationDate: expTime},
+			},
+		}}); err != nil {
+		t.Fatal(err)
+	}
+	at := &ActionTiming{
+		accountIDs: utils.StringMap{"cgrates.org:extexp": true},
+		actions: Actions{&Action{ActionType: EXTEND_EXPIRY, ExtraParameters: "720h",
+			ExpirationString: utils.UNLIMITED, // not considered when matching the balances
+			Balance:          &BalanceFilter{Type: utils.StringPointer(utils.VOICE)}}},
+	}
+	at.Execute(nil, nil)
+	acc, err := dm.DataDB().GetAccount("cgrates.org:extexp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eExp := expTime.Add(720 * time.Hour); !acc.BalanceMap[utils.VOICE][0].ExpirationDate.Equal(eExp) {
+		t.Errorf("expecting: %v, received: %v", eExp, acc.BalanceMap[utils.VOICE][0].ExpirationDate)
+	}
+	if !acc.BalanceMap[utils.VOICE][1].ExpirationDate.IsZero() {
+		t.Errorf("unlimited balance expiring: %v", acc.BalanceMap[utils.VOICE][1].ExpirationDate)
+	}
+	if !acc.BalanceMap[utils.MONETARY][0].ExpirationDate.Equal(expTime) {
+		t.Errorf("filtered balance modified: %v", acc.BalanceMap[utils.MONETARY][0].ExpirationDate)
+	}
+	if err := extendExpiryAction(acc, nil, &Action{ExtraParameters: "24h",
+		Balance: &BalanceFilter{ID: utils.StringPointer("v2")}}, nil); err != utils.ErrNotFound {
+		t.Errorf("expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+}
+
+func TestActionCloneBalance(t *testing.T) {
+	if err := dm.DataDB().SetAccount(&Account{
+		ID: "cgrates.org:clonesrc",
+		BalanceMap: map[string]Balances{
+			utils.MONETARY: Balances{
+				&Balance{ID: "bonus", Uuid: "uuid_src", Value: 5, Weight: 10},
+				&Balance{ID: utils.META_DEFAULT, Uuid: utils.GenUUID(), Value: 100},
+			},
+		}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.DataDB().SetAccount(&Account{
+		ID: "cgrates.org:clonedst",
+		BalanceMap: map[string]Balances{
+			utils.MONETARY: Balances{&Balance{ID: "bonus", Uuid: "uuid_dst", Value: 1}},
+		}}); err != nil {
+		t.Fatal(err)
+	}
+	at := &ActionTiming{
+		accountIDs: utils.StringMap{"cgrates.org:clonesrc": true},
+		actions: Actions{
+			&Action{ActionType: CLONE_BALANCE, ExtraParameters: "clonedst",
+				Balance: &BalanceFilter{ID: utils.StringPointer("bonus"), Type: utils.StringPointer(utils.MONETARY)}},
+			&Action{ActionType: CLONE_BALANCE, ExtraParameters: "cgrates.org:clonenew",
+				Balance: &BalanceFilter{Type: utils.StringPointer(utils.MONETARY)}},
+		},
+	}
+	at.Execute(nil, nil)
+	dstAcc, err := dm.DataDB().GetAccount("cgrates.org:clonedst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bChain := dstAcc.BalanceMap[utils.MONETARY]; len(bChain) != 1 ||
+		bChain[0].Value != 5 || bChain[0].Weight != 10 ||
+		bChain[0].Uuid == "uuid_src" || bChain[0].Uuid == "uuid_dst" {
+		t.Errorf("unexpected balances: %s", utils.ToJSON(bChain))
+	}
+	if newAcc, err := dm.DataDB().GetAccount("cgrates.org:clonenew"); err != nil {
+		t.Error(err)
+	} else if newAcc.BalanceMap[utils.MONETARY].GetTotalValue() != 105 {
+		t.Errorf("unexpected balances: %s", utils.ToJSON(newAcc.BalanceMap))
+	}
+	if srcAcc, err := dm.DataDB().GetAccount("cgrates.org:clonesrc"); err != nil {
+		t.Error(err)
+	} else if srcAcc.BalanceMap[utils.MONETARY].GetTotalValue() != 105 {
+		t.Errorf("source account modified: %s", utils.ToJSON(srcAcc.BalanceMap))
+	}
+	if err := cloneBalanceAction(dstAcc, nil, &Action{ExtraParameters: "clonedst"}, nil); err == nil {
+		t.Error("expecting error when cloning into the same account")
+	}
+}
+
+func TestActionSetAccountActionPlan(t *testing.T) {
+	accID := "cgrates.org:apswitch"
+	if err := dm.DataDB().SetAccount(&Account{ID: accID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.DataDB().SetActionPlan("AP_PREMIUM", &ActionPlan{Id: "AP_PREMIUM",
+		AccountIDs: utils.StringMap{accID: true, "cgrates.org:other": true}}, true, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.DataDB().SetActionPlan("AP_BASIC", &ActionPlan{Id: "AP_BASIC"}, true, utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.DataDB().SetAccountActionPlans(accID, []string{"AP_PREMIUM"}, true); err != nil {
+		t.Fatal(err)
+	}
+	var reloads int
+	SetSchedulerReload(func() error {
+		reloads++
+		return nil
+	})
+	defer SetSchedulerReload(nil)
+	at := &ActionTiming{
+		accountIDs: utils.StringMap{accID: true},
+		actions:    Actions{&Action{ActionType: SET_ACCOUNT_ACTION_PLAN, ExtraParameters: "AP_BASIC"}},
+	}
+	if err := at.Execute(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if reloads != 1 {
+		t.Errorf("expecting the scheduler reloaded once, reloads: %d", reloads)
+	}
+	if ap, err := dm.DataDB().GetActionPlan("AP_PREMIUM", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(ap.AccountIDs, utils.StringMap{"cgrates.org:other": true}) {
+		t.Errorf("unexpected accounts: %+v", ap.AccountIDs)
+	}
+	if ap, err := dm.DataDB().GetActionPlan("AP_BASIC", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(ap.AccountIDs, utils.StringMap{accID: true}) {
+		t.Errorf("unexpected accounts: %+v", ap.AccountIDs)
+	}
+	if apIDs, err := dm.DataDB().GetAccountActionPlans(accID, true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(apIDs, []string{"AP_BASIC"}) {
+		t.Errorf("unexpected action plans: %+v", apIDs)
+	}
+	if err := setAccountActionPlanAction(&Account{ID: accID}, nil,
+		&Action{ExtraParameters: "AP_MISSING"}, nil); err != utils.ErrNotFound {
+		t.Errorf("expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+}
+
+func TestActionCloneBalanceDestinations(t *testing.T) {
+	acs := Actions{
+		&Action{ActionType: CLONE_BALANCE, ExtraParameters: "cgrates.org:1003"},
+		&Action{ActionType: TOPUP, ExtraParameters: "cgrates.org:1004"},
+		&Action{ActionType: CLONE_BALANCE, ExtraParameters: "1002"},
+		&Action{ActionType: CLONE_BALANCE, ExtraParameters: "cgrates.org:1003"},
+		&Action{ActionType: CLONE_BALANCE, ExtraParameters: "1001"},
+	}
+	eIDs := []string{"cgrates.org:1002", "cgrates.org:1003"}
+	if dstIDs := cloneBalanceDestinations("cgrates.org:1001", acs); !reflect.DeepEqual(eIDs, dstIDs) {
+		t.Errorf("expecting: %+v, received: %+v", eIDs, dstIDs)
+	}
+}
+
+type testActionConn struct {
+	calls map[string]interface{} // serviceMethod: args
+}
+
+func (tc *testActionConn) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	tc.calls[serviceMethod] = args
+	*reply.(*string) = utils.OK
+	return nil
+}
+
+func TestActionResetStatQueueThreshold(t *testing.T) {
+	acc := &Account{ID: "cgrates.org:1001"}
+	if err := resetStatQueueAction(acc, nil, &Action{ExtraParameters: "SQ_1"}, nil); err == nil {
+		t.Error("expecting error without StatS")
+	}
+	conn := &testActionConn{calls: make(map[string]interface{})}
+	prevStatS, prevThdS := statS, thresholdS
+	SetStatS(conn)
+	SetThresholdS(conn)
+	defer func() {
+		SetStatS(prevStatS)
+		SetThresholdS(prevThdS)
+	}()
+	if err := resetStatQueueAction(acc, nil, &Action{ExtraParameters: "SQ_1"}, nil); err != nil {
+		t.Error(err)
+	}
+	if err := resetThresholdAction(acc, nil, &Action{ExtraParameters: "other.org:THD_1"}, nil); err != nil {
+		t.Error(err)
+	}
+	eCalls := map[string]interface{}{
+		utils.StatSv1ResetStatQueue:      &utils.TenantID{Tenant: "cgrates.org", ID: "SQ_1"},
+		utils.ThresholdSv1ResetThreshold: &utils.TenantID{Tenant: "other.org", ID: "THD_1"},
+	}
+	if !reflect.DeepEqual(eCalls, conn.calls) {
+		t.Errorf("expecting: %s, received: %s", utils.ToJSON(eCalls), utils.ToJSON(conn.calls))
+	}
+	if err := resetThresholdAction(nil, nil, &Action{ExtraParameters: "THD_1"}, nil); err == nil {
+		t.Error("expecting error for missing tenant")
+	}
+}
+
+func TestActionPublishEvent(t *testing.T) {
+	conn := &testActionConn{calls: make(map[string]interface{})}
+	prevPubSub := pubSubServer
+	SetPubSub(conn)
+	defer SetPubSub(prevPubSub)
+	a := &Action{Id: "OVERDUE", ActionType: PUBLISH_EVENT,
+		ExtraParameters: `{"EventName":"ACCOUNT_OVERDUE","Reason":"unpaid"}`}
+	if err := publishEventAction(&Account{ID: "cgrates.org:1001"}, nil, a, nil); err != nil {
+		t.Error(err)
+	}
+	eEvt := CgrEvent{"EventName": "ACCOUNT_OVERDUE", "ActionIds": "OVERDUE",
+		"Account": "cgrates.org:1001", "Reason": "unpaid"}
+	if evt := conn.calls["PubSubV1.Publish"]; !reflect.DeepEqual(eEvt, evt) {
+		t.Errorf("expecting: %+v, received: %+v", eEvt, evt)
+	}
+	a.ExtraParameters = ""
+	if err := publishEventAction(nil, nil, a, nil); err != nil {
+		t.Error(err)
+	}
+	eEvt = CgrEvent{"EventName": utils.EVT_ACTION_EVENT, "ActionIds": "OVERDUE"}
+	if evt := conn.calls["PubSubV1.Publish"]; !reflect.DeepEqual(eEvt, evt) {
+		t.Errorf("expecting: %+v, received: %+v", eEvt, evt)
+	}
+}
+
+func TestActionVerifyParameters(t *testing.T) {
+	for _, tc := range []struct {
+		actType, params string
+		valid           bool
+	}{
+		{EXTEND_EXPIRY, "720h", true},
+		{EXTEND_EXPIRY, "", false},
+		{EXTEND_EXPIRY, "one month", false},
+		{CLONE_BALANCE, "cgrates.org:1002", true},
+		{SET_ACCOUNT_ACTION_PLAN, "", false},
+		{RESET_STAT_QUEUE, "SQ_1", true},
+		{RESET_THRESHOLD, "", false},
+		{PUBLISH_EVENT, "", true},
+		{PUBLISH_EVENT, `{"EventName":"ACCOUNT_OVERDUE"}`, true},
+		{PUBLISH_EVENT, `{"EventName":`, false},
+		{TOPUP, "", true},
+	} {
+		if err := verifyActionParameters(tc.actType, tc.params); (err == nil) != tc.valid {
+			t.Errorf("action: %s, params: <%s>, received error: %v", tc.actType, tc.params, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// APIKeyID returns the ID an API key is stored with, so the keys themselves cannot be read out of dataDB
+func APIKeyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKey grants the methods of its roles on the requests of its tenants
+type APIKey struct {
+	ID          string // APIKeyID of the key
+	Description string
+	Tenants     []string // tenants the requests can address, *any for all
+	RoleIDs     []string
+	Disabled    bool
+}
+
+// ActsOnTenant checks if the key can address requests to the tenant
+func (apiKey *APIKey) ActsOnTenant(tenant string) bool {
+	for _, tnt := range apiKey.Tenants {
+		if tnt == utils.ANY || tnt == tenant {
+			return true
+		}
+	}
+	return false
+}
+
+// APIRole groups the API methods granted to the keys
+type APIRole struct {
+	ID      string
+	Methods []string // patterns of the method names, ie: ApierV1.Get*, *any for all
+}
+
+// Permits checks if the method matches one of the patterns of the role
+func (apiRole *APIRole) Permits(serviceMethod string) bool {
+	for _, pattern := range apiRole.Methods {
+		if pattern == utils.ANY {
+			return true
+		}
+		if matched, err := path.Match(pattern, serviceMethod); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// apiAuthMethods manage the keys and roles, reserved to the keys acting on all tenants so the others cannot escalate
+var apiAuthMethods = utils.NewStringMap("ApierV1.SetAPIKey", "ApierV1.GetAPIKey", "ApierV1.GetAPIKeyIDs", "ApierV1.RemAPIKey",
+	"ApierV1.SetAPIRole", "ApierV1.GetAPIRole", "ApierV1.GetAPIRoleIDs", "ApierV1.RemAPIRole")
+
+// indirectValue dereferences the pointers and interfaces, invalid Value for nil ones
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// requestTenants returns the tenants addressed by the RPC arguments, out of the Tenant field or map key
+// or the Tenants field of the filters. has is false if the tenants cannot be resolved, including empty Tenants filters matching all of them.
+// Empty Tenant is returned as such, to be replaced with the default one.
+func requestTenants(args interface{}) (tenants []string, has bool) {
+	v := indirectValue(reflect.ValueOf(args))
+	var tntVal, tntsVal reflect.Value
+	switch v.Kind() {
+	case reflect.Struct:
+		tntVal = v.FieldByName(utils.TENANT)
+		tntsVal = v.FieldByName(utils.TENANT + "s")
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		tntVal = v.MapIndex(reflect.ValueOf(utils.TENANT).Convert(v.Type().Key()))
+	}
+	if tntVal = indirectValue(tntVal); tntVal.IsValid() && tntVal.Kind() == reflect.String {
+		return []string{tntVal.String()}, true
+	}
+	if tntsVal = indirectValue(tntsVal); !tntsVal.IsValid() || tntsVal.Kind() != reflect.Slice ||
+		tntsVal.Type().Elem().Kind() != reflect.String || tntsVal.Len() == 0 {
+		return
+	}
+	for i := 0; i < tntsVal.Len(); i++ {
+		tenants = append(tenants, tntsVal.Index(i).String())
+	}
+	return tenants, true
+}
+
+// AdminAPIRoleID is the role granted to the admin key out of the configuration
+const AdminAPIRoleID = "*admin"
+
+// NewAPIAuthorizer initializes an APIAuthorizer.
+// The requests with empty Tenant are considered addressing the defaultTenant.
+func NewAPIAuthorizer(dm *DataManager, defaultTenant string) *APIAuthorizer {
+	return &APIAuthorizer{dm: dm, defaultTenant: defaultTenant}
+}
+
+// APIAuthorizer checks the RPC requests against the APIKeys and APIRoles stored in dataDB.
+// Implements utils.RPCAuthorizer interface.
+type APIAuthorizer struct {
+	dm            *DataManager
+	defaultTenant string
+}
+
+// apiKey returns the active APIKey out of the key presented by the client
+func (apiAuth *APIAuthorizer) apiKey(key string) (apiKey *APIKey, err error) {
+	if key == "" {
+		return nil, utils.ErrUnauthenticated
+	}
+	if apiKey, err = apiAuth.dm.GetAPIKey(APIKeyID(key), false); err != nil {
+		if err == utils.ErrNotFound {
+			err = utils.ErrUnauthenticated
+		}
+		return nil, err
+	}
+	if apiKey.Disabled {
+		return nil, utils.ErrUnauthenticated
+	}
+	return
+}
+
+// auditDenied logs the requests refused
+func (apiAuth *APIAuthorizer) auditDenied(key, serviceMethod, tenant, remoteAddr string, err error) {
+	keyID := utils.META_NONE
+	if key != "" {
+		keyID = APIKeyID(key)
+	}
+	utils.Logger.Warning(fmt.Sprintf("<APIAuth> denied method: <%s>, tenant: <%s>, key: <%s>, remote: <%s>, error: <%s>",
+		serviceMethod, tenant, keyID, remoteAddr, err.Error()))
+}
+
+// Authenticate checks the key presented by a client when opening the connection
+func (apiAuth *APIAuthorizer) Authenticate(key, remoteAddr string) (err error) {
+	if _, err = apiAuth.apiKey(key); err != nil {
+		apiAuth.auditDenied(key, utils.AuthV1Authenticate, "", remoteAddr, err)
+	}
+	return
+}
+
+// SetAdminKey makes sure the key out of configuration can call all the methods on all the tenants,
+// so the first keys can be created over the API
+func (apiAuth *APIAuthorizer) SetAdminKey(key string) (err error) {
+	if err = apiAuth.dm.SetAPIRole(&APIRole{ID: AdminAPIRoleID, Methods: []string{utils.ANY}}); err != nil {
+		return
+	}
+	return apiAuth.dm.SetAPIKey(&APIKey{ID: APIKeyID(key), Description: "admin key out of configuration",
+		Tenants: []string{utils.ANY}, RoleIDs: []string{AdminAPIRoleID}})
+}
+
+// Authorize checks the method is granted by one of the roles of the key
+// and the tenants of the request are the key's.
+// Requests without resolvable tenants are granted only to the keys acting on all of them.
+func (apiAuth *APIAuthorizer) Authorize(key, serviceMethod string, args interface{}, remoteAddr string) (err error) {
+	tenants, hasTenants := requestTenants(args)
+	for i, tnt := range tenants {
+		if tnt == "" {
+			tenants[i] = apiAuth.defaultTenant
+		}
+	}
+	defer func() {
+		if err != nil {
+			apiAuth.auditDenied(key, serviceMethod, strings.Join(tenants, utils.INFIELD_SEP), remoteAddr, err)
+		}
+	}()
+	apiKey, err := apiAuth.apiKey(key)
+	if err != nil {
+		return
+	}
+	var permitted bool
+	for _, roleID := range apiKey.RoleIDs {
+		var apiRole *APIRole
+		if apiRole, err = apiAuth.dm.GetAPIRole(roleID, false); err != nil {
+			if err == utils.ErrNotFound { // role removed, the others may still grant the method
+				err = nil
+				continue
+			}
+			return
+		}
+		if permitted = apiRole.Permits(serviceMethod); permitted {
+			break
+		}
+	}
+	if !permitted || ((!hasTenants || apiAuthMethods[serviceMethod]) && !apiKey.ActsOnTenant(utils.ANY)) {
+		return utils.ErrUnauthorized
+	}
+	for _, tnt := range tenants {
+		if !apiKey.ActsOnTenant(tnt) {
+			return utils.ErrUnauthorized
+		}
+	}
+	return
+}
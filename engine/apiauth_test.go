@@ -0,0 +1,161 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestAPIRolePermits(t *testing.T) {
+	apiRole := &APIRole{ID: "READER", Methods: []string{"ApierV1.Get*", "Responder.Status"}}
+	for method, ePermit := range map[string]bool{
+		"ApierV1.GetAccount":  true,
+		"ApierV1.SetAccount":  false,
+		"Responder.Status":    true,
+		"Responder.GetCost":   false,
+		"ApierV2.GetAccounts": false,
+	} {
+		if permit := apiRole.Permits(method); permit != ePermit {
+			t.Errorf("method: %s, expecting: %v, received: %v", method, ePermit, permit)
+		}
+	}
+	if !(&APIRole{Methods: []string{utils.ANY}}).Permits("ApierV1.RemAccount") {
+		t.Error("*any should permit all the methods")
+	}
+}
+
+func TestRequestTenants(t *testing.T) {
+	type tntArgs struct {
+		Tenant  string
+		Account string
+	}
+	type tntPtrArgs struct {
+		Tenant *string
+	}
+	type tntsArgs struct {
+		Tenants []string
+	}
+	tnt := "cgrates.org"
+	for i, args := range []interface{}{
+		tntArgs{Tenant: tnt},
+		&tntArgs{Tenant: tnt},
+		&tntPtrArgs{Tenant: &tnt},
+		map[string]string{utils.TENANT: tnt},
+		map[string]interface{}{utils.TENANT: tnt},
+		&tntsArgs{Tenants: []string{tnt}},
+	} {
+		if rcv, has := requestTenants(args); !has || !reflect.DeepEqual([]string{tnt}, rcv) {
+			t.Errorf("args %d, received: %+v, %v", i, rcv, has)
+		}
+	}
+	if rcv, has := requestTenants(&tntsArgs{Tenants: []string{tnt, "itsyscom.com"}}); !has ||
+		!reflect.DeepEqual([]string{tnt, "itsyscom.com"}, rcv) {
+		t.Errorf("received: %+v, %v", rcv, has)
+	}
+	for i, args := range []interface{}{
+		nil,
+		"cgrates.org",
+		&tntPtrArgs{},
+		map[string]string{utils.ACCOUNT: "1001"},
+		(*tntArgs)(nil),
+		&tntsArgs{}, // all tenants
+	} {
+		if rcv, has := requestTenants(args); has {
+			t.Errorf("args %d, unexpected tenants: %+v", i, rcv)
+		}
+	}
+}
+
+func TestAPIAuthorizer(t *testing.T) {
+	dataDB, _ := NewMapStorage()
+	dmAuth := NewDataManager(dataDB)
+	for _, apiRole := range []*APIRole{
+		&APIRole{ID: "READER", Methods: []string{"ApierV1.Get*"}},
+		&APIRole{ID: "ADMIN", Methods: []string{utils.ANY}},
+	} {
+		if err := dmAuth.SetAPIRole(apiRole); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for key, apiKey := range map[string]*APIKey{
+		"reader": &APIKey{Tenants: []string{"cgrates.org"}, RoleIDs: []string{"MISSING", "READER"}},
+		"tntadm": &APIKey{Tenants: []string{"itsyscom.com"}, RoleIDs: []string{"ADMIN"}},
+		"admin":  &APIKey{Tenants: []string{utils.ANY}, RoleIDs: []string{"ADMIN"}},
+		"old":    &APIKey{Tenants: []string{utils.ANY}, RoleIDs: []string{"ADMIN"}, Disabled: true},
+	} {
+		apiKey.ID = APIKeyID(key)
+		if err := dmAuth.SetAPIKey(apiKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+	apiAuth := NewAPIAuthorizer(dmAuth, "cgrates.org")
+	if err := apiAuth.SetAdminKey("boot"); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiAuth.Authenticate("reader", "127.0.0.1:1234"); err != nil {
+		t.Error(err)
+	}
+	for _, key := range []string{"", "unknown", "old"} {
+		if err := apiAuth.Authenticate(key, "127.0.0.1:1234"); err != utils.ErrUnauthenticated {
+			t.Errorf("key: <%s>, received: %v", key, err)
+		}
+	}
+	type accountArgs struct {
+		Tenant  string
+		Account string
+	}
+	type cdrsFilter struct {
+		Tenants  []string
+		Accounts []string
+	}
+	for i, tc := range []struct {
+		key    string
+		method string
+		args   interface{}
+		eErr   error
+	}{
+		{"reader", "ApierV1.GetAccount", &accountArgs{Tenant: "cgrates.org"}, nil},
+		{"reader", "ApierV1.GetAccount", &accountArgs{}, nil}, // default tenant
+		{"reader", "ApierV1.GetAccount", &accountArgs{Tenant: "itsyscom.com"}, utils.ErrUnauthorized},
+		{"reader", "ApierV1.SetAccount", &accountArgs{Tenant: "cgrates.org"}, utils.ErrUnauthorized},
+		{"reader", "ApierV1.GetAPIKeyIDs", utils.StringPointer(""), utils.ErrUnauthorized},
+		{"tntadm", "ApierV1.SetAccount", &accountArgs{Tenant: "itsyscom.com"}, nil},
+		{"tntadm", "ApierV1.SetAccount", &accountArgs{}, utils.ErrUnauthorized},
+		{"tntadm", "ApierV1.SetAPIKey", map[string]interface{}{}, utils.ErrUnauthorized},
+		{"tntadm", "ApierV1.GetCdrs", &cdrsFilter{Tenants: []string{"itsyscom.com"}}, nil},
+		{"tntadm", "ApierV1.GetCdrs", &cdrsFilter{Tenants: []string{"itsyscom.com", "cgrates.org"}}, utils.ErrUnauthorized},
+		{"tntadm", "ApierV1.GetCdrs", &cdrsFilter{Accounts: []string{"1001"}}, utils.ErrUnauthorized}, // all tenants
+		{"tntadm", "InvoiceSv1.GetInvoiceIDs", utils.StringPointer("cgrates.org"), utils.ErrUnauthorized},
+		{"tntadm", "ApierV2.RefundCDR", map[string]interface{}{"CGRID": "abc"}, utils.ErrUnauthorized},
+		{"admin", "ApierV2.RefundCDR", map[string]interface{}{"CGRID": "abc"}, nil},
+		{"admin", "ApierV1.SetAPIKey", map[string]interface{}{}, nil},
+		{"admin", "ApierV1.RemAccount", &accountArgs{Tenant: "itsyscom.com"}, nil},
+		{"old", "ApierV1.GetAccount", &accountArgs{Tenant: "cgrates.org"}, utils.ErrUnauthenticated},
+		{"", "ApierV1.GetAccount", &accountArgs{Tenant: "cgrates.org"}, utils.ErrUnauthenticated},
+		{"boot", "ApierV1.SetAPIKey", map[string]interface{}{}, nil}, // admin key out of config
+	} {
+		if err := apiAuth.Authorize(tc.key, tc.method, tc.args, "127.0.0.1:1234"); err != tc.eErr {
+			t.Errorf("case %d, key: <%s>, method: <%s>, expecting: %v, received: %v",
+				i, tc.key, tc.method, tc.eErr, err)
+		}
+	}
+}
@@ -39,6 +39,7 @@ type Balance struct {
 	Weight         float64
 	DestinationIDs utils.StringMap
 	RatingSubject  string
+	Currency       string // monetary balances only, empty for the default currency
 	Categories     utils.StringMap
 	SharedGroups   utils.StringMap
 	Timings        []*RITiming
@@ -65,6 +66,7 @@ func (b *Balance) Equal(o *Balance) bool {
 		b.DestinationIDs.Equal(o.DestinationIDs) &&
 		b.Directions.Equal(o.Directions) &&
 		b.RatingSubject == o.RatingSubject &&
+		b.Currency == o.Currency &&
 		b.Categories.Equal(o.Categories) &&
 		b.SharedGroups.Equal(o.SharedGroups) &&
 		b.Disabled == o.Disabled &&
@@ -90,7 +92,8 @@ func (b *Balance) MatchFilter(o *BalanceFilter, skipIds bool) bool {
 		(o.Categories == nil || b.Categories.Includes(*o.Categories)) &&
 		(o.TimingIDs == nil || b.TimingIDs.Includes(*o.TimingIDs)) &&
 		(o.SharedGroups == nil || b.SharedGroups.Includes(*o.SharedGroups)) &&
-		(o.RatingSubject == nil || b.RatingSubject == *o.RatingSubject)
+		(o.RatingSubject == nil || b.RatingSubject == *o.RatingSubject) &&
+		(o.Currency == nil || b.Currency == *o.Currency)
 }
 
 func (b *Balance) HardMatchFilter(o *BalanceFilter, skipIds bool) bool {
@@ -112,7 +115,8 @@ func (b *Balance) HardMatchFilter(o *BalanceFilter, skipIds bool) bool {
 		(o.Categories == nil || b.Categories.Equal(*o.Categories)) &&
 		(o.TimingIDs == nil || b.TimingIDs.Equal(*o.TimingIDs)) &&
 		(o.SharedGroups == nil || b.SharedGroups.Equal(*o.SharedGroups)) &&
-		(o.RatingSubject == nil || b.RatingSubject == *o.RatingSubject)
+		(o.RatingSubject == nil || b.RatingSubject == *o.RatingSubject) &&
+		(o.Currency == nil || b.Currency == *o.Currency)
 }
 
 // the default balance has standard Id
@@ -175,6 +179,7 @@ func (b *Balance) Clone() *Balance {
 		ExpirationDate: b.ExpirationDate,
 		Weight:         b.Weight,
 		RatingSubject:  b.RatingSubject,
+		Currency:       b.Currency,
 		Categories:     b.Categories,
 		SharedGroups:   b.SharedGroups,
 		TimingIDs:      b.TimingIDs,
@@ -223,7 +228,7 @@ func (b *Balance) GetMinutesForCredit(origCD *CallDescriptor, initialCredit floa
 		if connectFee <= credit {
 			credit -= connectFee
 			// remove connect fee from the total cost
-			cc.Cost -= connectFee
+			cc.Cost = utils.SumFloat64(cc.Cost, -connectFee).Float64()
 		} else {
 			return 0, credit
 		}
@@ -286,16 +291,21 @@ func (b *Balance) GetValue() float64 {
 }
 
 func (b *Balance) AddValue(amount float64) {
-	b.SetValue(b.GetValue() + amount)
+	b.setValueDecimal(utils.SumFloat64(b.GetValue(), amount))
 }
 
 func (b *Balance) SubstractValue(amount float64) {
-	b.SetValue(b.GetValue() - amount)
+	b.setValueDecimal(utils.NewDecimalFromFloat64(b.GetValue()).
+		Sub(utils.NewDecimalFromFloat64(amount)))
 }
 
 func (b *Balance) SetValue(amount float64) {
-	b.Value = amount
-	b.Value = utils.Round(b.GetValue(), globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	b.setValueDecimal(utils.NewDecimalFromFloat64(amount))
+}
+
+// setValueDecimal rounds the value before exporting it so repeated debits do not drift
+func (b *Balance) setValueDecimal(amount *utils.Decimal) {
+	b.Value = amount.Round(globalRoundingDecimals, utils.ROUNDING_MIDDLE).Float64()
 	b.dirty = true
 }
 
@@ -396,7 +406,9 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 		}
 		if debitConnectFee {
 			// this is the first add, debit the connect fee
-			if ok, debitedConnectFeeBalance = ub.DebitConnectionFee(cc, moneyBalances, count, true); !ok {
+			if ok, debitedConnectFeeBalance, err = ub.DebitConnectionFee(cc, moneyBalances, count, true); err != nil {
+				return nil, err
+			} else if !ok {
 				// found blocker balance
 				return nil, nil
 			}
@@ -419,11 +431,7 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 					Duration: 0,
 					Cost:     ts.RateInterval.Rating.ConnectFee,
 					BalanceInfo: &DebitInfo{
-						Monetary: &MonetaryInfo{
-							UUID:  debitedConnectFeeBalance.Uuid,
-							ID:    debitedConnectFeeBalance.ID,
-							Value: debitedConnectFeeBalance.Value,
-						},
+						Monetary:  connectFeeInfo(&debitedConnectFeeBalance, ts.RateInterval.rateCurrency()),
 						AccountID: ub.ID,
 					},
 				}
@@ -477,15 +485,23 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 					continue
 				}
 				var moneyBal *Balance
+				xr := 1.0 // converts cost into the currency of moneyBal
 				for _, mb := range moneyBalances {
-					if mb.GetValue() >= cost {
-						moneyBal = mb
+					mbXR, err := mb.exchangeRate(ts.RateInterval.rateCurrency())
+					if err != nil { // balance cannot pay in this currency
+						continue
+					}
+					if mb.GetValue() >= convertCost(cost, mbXR) {
+						moneyBal, xr = mb, mbXR
 						break
 					}
 				}
 				if cost != 0 && moneyBal == nil && (!dryRun || ub.AllowNegative) { // Fix for issue #685
 					utils.Logger.Warning(fmt.Sprintf("<RALs> Going negative on account %s with AllowNegative: false", cd.GetAccountKey()))
 					moneyBal = ub.GetDefaultMoneyBalance()
+					if xr, err = moneyBal.defaultExchangeRate(ts.RateInterval.rateCurrency()); err != nil {
+						return nil, err
+					}
 				}
 				if b.GetValue() >= amount && (moneyBal != nil || cost == 0) {
 					b.SubstractValue(amount)
@@ -500,19 +516,20 @@ func (b *Balance) debitUnits(cd *CallDescriptor, ub *Account, moneyBalances Bala
 					}
 					inc.BalanceInfo.AccountID = ub.ID
 					if cost != 0 {
-						moneyBal.SubstractValue(cost)
+						moneyBal.SubstractValue(convertCost(cost, xr))
 						inc.BalanceInfo.Monetary = &MonetaryInfo{
-							UUID:  moneyBal.Uuid,
-							ID:    moneyBal.ID,
-							Value: moneyBal.Value,
+							UUID:         moneyBal.Uuid,
+							ID:           moneyBal.ID,
+							Value:        moneyBal.Value,
+							ExchangeRate: recordedExchangeRate(xr),
 						}
-						cd.MaxCostSoFar += cost
+						cd.MaxCostSoFar = utils.SumFloat64(cd.MaxCostSoFar, cost).Float64()
 					}
 					inc.paid = true
 					if count {
 						ub.countUnits(amount, cc.TOR, cc, b)
 						if cost != 0 {
-							ub.countUnits(cost, utils.MONETARY, cc, moneyBal)
+							ub.countUnits(convertCost(cost, xr), utils.MONETARY, cc, moneyBal)
 						}
 					}
 				} else {
@@ -546,6 +563,10 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 	if err != nil {
 		return nil, err
 	}
+	if _, errXR := b.exchangeRate(cc.GetCurrency()); errXR != nil {
+		// balance cannot pay in this currency, check it before debiting the connect fee
+		return nil, nil
+	}
 
 	var debitedConnectFeeBalance Balance
 	var ok bool
@@ -553,7 +574,9 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 	if debitConnectFee {
 
 		// this is the first add, debit the connect fee
-		if ok, debitedConnectFeeBalance = ub.DebitConnectionFee(cc, moneyBalances, count, true); !ok {
+		if ok, debitedConnectFeeBalance, err = ub.DebitConnectionFee(cc, moneyBalances, count, true); err != nil {
+			return nil, err
+		} else if !ok {
 			// balance is blocker
 			return nil, nil
 		}
@@ -573,6 +596,17 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 			utils.Logger.Err(fmt.Sprintf("Nil RateInterval ERROR on TS: %+v, CC: %+v, from CD: %+v", ts, cc, cd))
 			return nil, errors.New("timespan with no rate interval assigned")
 		}
+		xr, errXR := b.exchangeRate(ts.RateInterval.rateCurrency()) // converts costs into balance currency
+		if errXR != nil {
+			utils.Logger.Warning(fmt.Sprintf("<RALs> cannot convert costs in <%s> for balance %s of account %s, err: %s",
+				ts.RateInterval.rateCurrency(), b.ID, ub.ID, errXR.Error()))
+			// leave the rest of the timespans to the next balances
+			cc.Timespans = cc.Timespans[:tsIndex]
+			if len(cc.Timespans) == 0 {
+				cc = nil
+			}
+			return cc, nil
+		}
 
 		if tsIndex == 0 && ts.RateInterval.Rating.ConnectFee > 0 && debitConnectFee && cc.deductConnectFee && ok {
 
@@ -580,11 +614,7 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 				Duration: 0,
 				Cost:     ts.RateInterval.Rating.ConnectFee,
 				BalanceInfo: &DebitInfo{
-					Monetary: &MonetaryInfo{
-						UUID:  debitedConnectFeeBalance.Uuid,
-						ID:    debitedConnectFeeBalance.ID,
-						Value: debitedConnectFeeBalance.Value,
-					},
+					Monetary:  connectFeeInfo(&debitedConnectFeeBalance, ts.RateInterval.rateCurrency()),
 					AccountID: ub.ID,
 				},
 			}
@@ -605,7 +635,7 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 				continue
 			}
 
-			amount := inc.Cost
+			amount := convertCost(inc.Cost, xr)
 			inc.paid = false
 			if strategy == utils.MAX_COST_DISCONNECT && cd.MaxCostSoFar >= maxCost {
 				// cut the entire current timespan
@@ -644,11 +674,12 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 
 			if b.GetValue() >= amount {
 				b.SubstractValue(amount)
-				cd.MaxCostSoFar += amount
+				cd.MaxCostSoFar = utils.SumFloat64(cd.MaxCostSoFar, inc.Cost).Float64() // max cost is expressed in rate currency
 				inc.BalanceInfo.Monetary = &MonetaryInfo{
-					UUID:  b.Uuid,
-					ID:    b.ID,
-					Value: b.Value,
+					UUID:         b.Uuid,
+					ID:           b.ID,
+					Value:        b.Value,
+					ExchangeRate: recordedExchangeRate(xr),
 				}
 				inc.BalanceInfo.AccountID = ub.ID
 				if b.RatingSubject != "" {
@@ -684,7 +715,8 @@ func (b *Balance) debitMoney(cd *CallDescriptor, ub *Account, moneyBalances Bala
 
 // Converts the balance towards compressed information to be displayed
 func (b *Balance) AsBalanceSummary(typ string) *BalanceSummary {
-	bd := &BalanceSummary{UUID: b.Uuid, ID: b.ID, Type: typ, Value: b.Value, Disabled: b.Disabled}
+	bd := &BalanceSummary{UUID: b.Uuid, ID: b.ID, Type: typ, Value: b.Value,
+		Currency: b.Currency, Disabled: b.Disabled}
 	if bd.ID == "" {
 		bd.ID = b.Uuid
 	}
@@ -861,5 +893,6 @@ type BalanceSummary struct {
 	ID       string // Balance ID  if not defined
 	Type     string // *voice, *data, etc
 	Value    float64
+	Currency string // monetary balances only
 	Disabled bool
 }
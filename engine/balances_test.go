@@ -19,6 +19,8 @@ package engine
 
 import (
 	"testing"
+	"testing/quick"
+	"time"
 
 	"github.com/cgrates/cgrates/utils"
 )
@@ -242,3 +244,67 @@ func TestBalanceIsDefault(t *testing.T) {
 		t.Errorf("Balance should be default: %+v", b)
 	}
 }
+
+// TestBalanceDebitMoneyIncrementsSum makes sure that the sum of the charged increments
+// equals the amount debited out of the balance as well as the reported costs
+func TestBalanceDebitMoneyIncrementsSum(t *testing.T) {
+	f := func(rates []uint16, durations []uint8) bool {
+		if len(rates) == 0 || len(durations) == 0 {
+			return true
+		}
+		cc := &CallCost{Direction: utils.OUT, Destination: "0723", TOR: utils.VOICE}
+		tStart := time.Date(2017, 11, 1, 10, 0, 0, 0, time.UTC)
+		for i, rate := range rates {
+			dur := time.Duration(1+int(durations[i%len(durations)])%120) * time.Second
+			cc.Timespans = append(cc.Timespans, &TimeSpan{
+				TimeStart: tStart,
+				TimeEnd:   tStart.Add(dur),
+				RateInterval: &RateInterval{Rating: &RIRate{
+					Rates: RateGroups{&Rate{Value: float64(rate) / 10000, // per minute, charged per second
+						RateIncrement: time.Second, RateUnit: time.Minute}}}},
+			})
+			tStart = tStart.Add(dur)
+		}
+		cd := &CallDescriptor{
+			TimeStart:    cc.Timespans[0].TimeStart,
+			TimeEnd:      tStart,
+			Direction:    cc.Direction,
+			Destination:  cc.Destination,
+			TOR:          cc.TOR,
+			testCallcost: cc,
+		}
+		initialValue := 1000000.0
+		acnt := &Account{ID: "cgrates.org:decimal", BalanceMap: map[string]Balances{
+			utils.MONETARY: Balances{&Balance{Uuid: "money", Value: initialValue, Weight: 10}}}}
+		rcvCC, err := acnt.debitCreditBalance(cd, false, false, true)
+		if err != nil {
+			t.Error(err)
+			return false
+		}
+		incrementsSum := utils.NewDecimalFromInt64(0)
+		for _, ts := range rcvCC.Timespans {
+			for _, inc := range ts.Increments {
+				incrementsSum = incrementsSum.Add(utils.NewDecimalFromFloat64(inc.GetCost()))
+			}
+		}
+		debited := utils.NewDecimalFromFloat64(initialValue).
+			Sub(utils.NewDecimalFromFloat64(acnt.BalanceMap[utils.MONETARY][0].GetValue()))
+		if debited.Cmp(incrementsSum) != 0 {
+			t.Logf("Debited: %s, increments sum: %s", debited, incrementsSum)
+			return false
+		}
+		rcvCC.updateCost()
+		if rcvCC.Cost != incrementsSum.Float64() {
+			t.Logf("CallCost: %v, increments sum: %s", rcvCC.Cost, incrementsSum)
+			return false
+		}
+		if ecCost := NewEventCostFromCallCost(rcvCC, "decimal", utils.META_DEFAULT).GetCost(); ecCost != incrementsSum.Float64() {
+			t.Logf("EventCost: %v, increments sum: %s", ecCost, incrementsSum)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 50}); err != nil {
+		t.Error(err)
+	}
+}
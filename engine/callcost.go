@@ -40,7 +40,7 @@ type CallCost struct {
 // Merges the received timespan if they are similar (same activation period, same interval, same minute info.
 func (cc *CallCost) Merge(other *CallCost) {
 	cc.Timespans = append(cc.Timespans, other.Timespans...)
-	cc.Cost += other.Cost
+	cc.Cost = utils.SumFloat64(cc.Cost, other.Cost).Float64()
 }
 
 func (cc *CallCost) GetStartTime() time.Time {
@@ -82,6 +82,14 @@ func (cc *CallCost) GetConnectFee() float64 {
 	return cc.Timespans[0].RateInterval.Rating.ConnectFee
 }
 
+// GetCurrency returns the currency the costs are expressed in, empty for the default one
+func (cc *CallCost) GetCurrency() string {
+	if len(cc.Timespans) == 0 {
+		return ""
+	}
+	return cc.Timespans[0].RateInterval.rateCurrency()
+}
+
 // Creates a CallDescriptor structure copying related data from CallCost
 func (cc *CallCost) CreateCallDescriptor() *CallDescriptor {
 	return &CallDescriptor{
@@ -170,16 +178,16 @@ func (cc *CallCost) UpdateCost() {
 }
 
 func (cc *CallCost) updateCost() {
-	cost := 0.0
+	cost := utils.NewDecimalFromInt64(0)
 	//if cc.deductConnectFee { // add back the connectFee
 	//	cost += cc.GetConnectFee()
 	//}
 	for _, ts := range cc.Timespans {
-		ts.Cost = ts.CalculateCost()
-		cost += ts.Cost
-		cost = utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE) // just get rid of the extra decimals
+		tsCost := ts.calculateCostDecimal()
+		ts.Cost = tsCost.Float64()
+		cost = cost.Add(tsCost).Round(globalRoundingDecimals, utils.ROUNDING_MIDDLE) // just get rid of the extra decimals
 	}
-	cc.Cost = cost
+	cc.Cost = cost.Float64()
 }
 
 // Round creates the RoundIncrements in timespans
@@ -187,7 +195,7 @@ func (cc *CallCost) Round() {
 	if len(cc.Timespans) == 0 || cc.Timespans[0] == nil {
 		return
 	}
-	var totalCorrectionCost float64
+	totalCorrectionCost := utils.NewDecimalFromInt64(0)
 	for _, ts := range cc.Timespans {
 		if len(ts.Increments) == 0 {
 			continue // safe check
@@ -197,22 +205,22 @@ func (cc *CallCost) Round() {
 			// this is a unit paid timespan, nothing to round
 			continue
 		}
-		cost := ts.CalculateCost()
-		roundedCost := utils.Round(cost,
+		cost := ts.calculateCostDecimal()
+		roundedCost := cost.Round(
 			ts.RateInterval.Rating.RoundingDecimals,
 			ts.RateInterval.Rating.RoundingMethod)
-		correctionCost := roundedCost - cost
+		correctionCost := roundedCost.Sub(cost)
 		//log.Print(cost, roundedCost, correctionCost)
-		if correctionCost != 0 {
+		if correctionCost.Sign() != 0 {
 			ts.RoundIncrement = &Increment{
-				Cost:        correctionCost,
+				Cost:        correctionCost.Float64(),
 				BalanceInfo: inc.BalanceInfo,
 			}
-			totalCorrectionCost += correctionCost
-			ts.Cost += correctionCost
+			totalCorrectionCost = totalCorrectionCost.Add(correctionCost)
+			ts.Cost = utils.NewDecimalFromFloat64(ts.Cost).Add(correctionCost).Float64()
 		}
 	}
-	cc.Cost += totalCorrectionCost
+	cc.Cost = utils.NewDecimalFromFloat64(cc.Cost).Add(totalCorrectionCost).Float64()
 }
 
 func (cc *CallCost) GetRoundIncrements() (roundIncrements Increments) {
@@ -72,10 +72,15 @@ var (
 	debitPeriod              = 10 * time.Second
 	globalRoundingDecimals   = 6
 	thresholdS               rpcclient.RpcClientConnection // used by RALs to communicate with ThresholdS
+	statS                    rpcclient.RpcClientConnection // used by *reset_stat_queue action
 	historyScribe            rpcclient.RpcClientConnection
 	pubSubServer             rpcclient.RpcClientConnection
 	userService              rpcclient.RpcClientConnection
 	aliasService             rpcclient.RpcClientConnection
+	resourceS                rpcclient.RpcClientConnection // used by *least_concurrent LCR strategy
+	lcrReplication           rpcclient.RpcClientConnection // RALs receiving the *weighted_round_robin counters
+	schedulerReload          func() error                  // reloads the scheduler once the action plans are changed by actions
+	lcrCounters              = NewLCRCounters()
 	rpSubjectPrefixMatching  bool
 	lcrSubjectPrefixMatching bool
 )
@@ -89,6 +94,10 @@ func SetThresholdS(thdS rpcclient.RpcClientConnection) {
 	thresholdS = thdS
 }
 
+func SetStatS(stS rpcclient.RpcClientConnection) {
+	statS = stS
+}
+
 // Sets the global rounding method and decimal precision for GetCost method
 func SetRoundingDecimals(rd int) {
 	globalRoundingDecimals = rd
@@ -126,6 +135,19 @@ func SetAliasService(as rpcclient.RpcClientConnection) {
 	aliasService = as
 }
 
+func SetResourceS(rS rpcclient.RpcClientConnection) {
+	resourceS = rS
+}
+
+func SetLCRReplication(replConn rpcclient.RpcClientConnection) {
+	lcrReplication = replConn
+}
+
+// SetSchedulerReload sets the function reloading the scheduler after *set_account_action_plan
+func SetSchedulerReload(reload func() error) {
+	schedulerReload = reload
+}
+
 func Publish(event CgrEvent) {
 	if pubSubServer != nil {
 		var s string
@@ -157,7 +179,8 @@ type CallDescriptor struct {
 	ForceDuration       bool // for Max debit if less than duration return err
 	PerformRounding     bool // flag for rating info rounding
 	DryRun              bool
-	DenyNegativeAccount bool // prevent account going on negative during debit
+	DenyNegativeAccount bool   // prevent account going on negative during debit
+	IdempotencyKey      string // retries with the same key replay the first reply instead of debiting again
 	account             *Account
 	testCallcost        *CallCost // testing purpose only!
 }
@@ -468,35 +491,35 @@ func (cd *CallDescriptor) GetCost() (*CallCost, error) {
 		return cc, err
 	}
 
-	cost := 0.0
+	cost := utils.NewDecimalFromInt64(0)
 	for i, ts := range cc.Timespans {
 		// only add connect fee if this is the first/only call cost request
 		//log.Printf("Interval: %+v", ts.RateInterval.Timing)
 		if cd.LoopIndex == 0 && i == 0 && ts.RateInterval != nil {
-			cost += ts.RateInterval.Rating.ConnectFee
+			cost = cost.Add(utils.NewDecimalFromFloat64(ts.RateInterval.Rating.ConnectFee))
 		}
 		//log.Printf("TS: %+v", ts)
 		// handle max cost
 		maxCost, strategy := ts.RateInterval.GetMaxCost()
 
-		ts.Cost = ts.CalculateCost()
-		cost += ts.Cost
-		cd.MaxCostSoFar += cost
+		tsCost := ts.calculateCostDecimal()
+		ts.Cost = tsCost.Float64()
+		cost = cost.Add(tsCost)
+		cd.MaxCostSoFar = utils.NewDecimalFromFloat64(cd.MaxCostSoFar).Add(cost).Float64()
 		//log.Print("Before: ", cost)
 		if strategy != "" && maxCost > 0 {
 			//log.Print("HERE: ", strategy, maxCost)
 			if strategy == utils.MAX_COST_FREE && cd.MaxCostSoFar >= maxCost {
-				cost = maxCost
+				cost = utils.NewDecimalFromFloat64(maxCost)
 				cd.MaxCostSoFar = maxCost
 			}
 
 		}
 		//log.Print("Cost: ", cost)
 	}
-	cc.Cost = cost
 	// global rounding
 	roundingDecimals, roundingMethod := cc.GetLongestRounding()
-	cc.Cost = utils.Round(cc.Cost, roundingDecimals, roundingMethod)
+	cc.Cost = cost.Round(roundingDecimals, roundingMethod).Float64()
 
 	return cc, nil
 }
@@ -531,26 +554,25 @@ func (cd *CallDescriptor) getCost() (*CallCost, error) {
 		return &CallCost{Cost: -1}, err
 	}
 	timespans := cd.splitInTimeSpans()
-	cost := 0.0
+	cost := utils.NewDecimalFromInt64(0)
 
 	for i, ts := range timespans {
 		ts.createIncrementsSlice()
 		// only add connect fee if this is the first/only call cost request
 		//log.Printf("Interval: %+v", ts.RateInterval.Timing)
 		if cd.LoopIndex == 0 && i == 0 && ts.RateInterval != nil {
-			cost += ts.RateInterval.Rating.ConnectFee
+			cost = cost.Add(utils.NewDecimalFromFloat64(ts.RateInterval.Rating.ConnectFee))
 		}
-		cost += ts.CalculateCost()
+		cost = cost.Add(ts.calculateCostDecimal())
 	}
 
 	//startIndex := len(fmt.Sprintf("%s:%s:%s:", cd.Direction, cd.Tenant, cd.Category))
 	cc := cd.CreateCallCost()
-	cc.Cost = cost
 	cc.Timespans = timespans
 
 	// global rounding
 	roundingDecimals, roundingMethod := cc.GetLongestRounding()
-	cc.Cost = utils.Round(cc.Cost, roundingDecimals, roundingMethod)
+	cc.Cost = cost.Round(roundingDecimals, roundingMethod).Float64()
 	//utils.Logger.Info(fmt.Sprintf("<Rater> Get Cost: %s => %v", cd.GetKey(), cc))
 	cc.Timespans.Compress()
 	cc.UpdateRatedUsage()
@@ -800,44 +822,67 @@ func (cd *CallDescriptor) MaxDebit() (cc *CallCost, err error) {
 }
 
 // refundIncrements has no locks
+// The accounts and balances are checked upfront so the increments are refunded either all or none
 func (cd *CallDescriptor) refundIncrements() (err error) {
 	accountsCache := make(map[string]*Account)
+	unitType := cd.TOR
 	for _, increment := range cd.Increments {
+		if increment.BalanceInfo == nil {
+			continue
+		}
+		hasUnit := increment.BalanceInfo.Unit != nil && increment.BalanceInfo.Unit.UUID != ""
+		hasMonetary := increment.BalanceInfo.Monetary != nil && increment.BalanceInfo.Monetary.UUID != ""
+		if !hasUnit && !hasMonetary { // nothing debited
+			continue
+		}
 		account, found := accountsCache[increment.BalanceInfo.AccountID]
 		if !found {
-			if acc, err := dm.DataDB().GetAccount(increment.BalanceInfo.AccountID); err == nil && acc != nil {
-				account = acc
-				accountsCache[increment.BalanceInfo.AccountID] = account
-				// will save the account only once at the end of the function
-				defer dm.DataDB().SetAccount(account)
+			if account, err = dm.DataDB().GetAccount(increment.BalanceInfo.AccountID); err != nil || account == nil {
+				return fmt.Errorf("could not get the account to be refunded: %s", increment.BalanceInfo.AccountID)
 			}
+			accountsCache[increment.BalanceInfo.AccountID] = account
 		}
-		if account == nil {
-			utils.Logger.Warning(fmt.Sprintf("Could not get the account to be refunded: %s", increment.BalanceInfo.AccountID))
+		if hasUnit && account.BalanceMap[unitType].GetBalance(increment.BalanceInfo.Unit.UUID) == nil {
+			return fmt.Errorf("could not find the balance: %s to be refunded on account: %s",
+				increment.BalanceInfo.Unit.UUID, increment.BalanceInfo.AccountID)
+		}
+		if hasMonetary && account.BalanceMap[utils.MONETARY].GetBalance(increment.BalanceInfo.Monetary.UUID) == nil {
+			return fmt.Errorf("could not find the balance: %s to be refunded on account: %s",
+				increment.BalanceInfo.Monetary.UUID, increment.BalanceInfo.AccountID)
+		}
+	}
+	for _, increment := range cd.Increments {
+		if increment.BalanceInfo == nil {
+			continue
+		}
+		account, found := accountsCache[increment.BalanceInfo.AccountID]
+		if !found { // nothing debited
 			continue
 		}
 		//utils.Logger.Info(fmt.Sprintf("Refunding increment %+v", increment))
-		var balance *Balance
-		unitType := cd.TOR
 		cc := cd.CreateCallCost()
 		if increment.BalanceInfo.Unit != nil && increment.BalanceInfo.Unit.UUID != "" {
-			if balance = account.BalanceMap[unitType].GetBalance(increment.BalanceInfo.Unit.UUID); balance == nil {
-				return
-			}
+			balance := account.BalanceMap[unitType].GetBalance(increment.BalanceInfo.Unit.UUID)
 			balance.AddValue(increment.Duration.Seconds())
 			account.countUnits(-increment.Duration.Seconds(), unitType, cc, balance)
 		}
 		// check money too
 		if increment.BalanceInfo.Monetary != nil && increment.BalanceInfo.Monetary.UUID != "" {
-			if balance = account.BalanceMap[utils.MONETARY].GetBalance(increment.BalanceInfo.Monetary.UUID); balance == nil {
-				return
+			balance := account.BalanceMap[utils.MONETARY].GetBalance(increment.BalanceInfo.Monetary.UUID)
+			refundValue := increment.Cost
+			if xr := increment.BalanceInfo.Monetary.ExchangeRate; xr != 0 { // cost was converted into balance currency
+				refundValue = convertCost(refundValue, xr)
 			}
-			balance.AddValue(increment.Cost)
-			account.countUnits(-increment.Cost, utils.MONETARY, cc, balance)
+			balance.AddValue(refundValue)
+			account.countUnits(-refundValue, utils.MONETARY, cc, balance)
+		}
+	}
+	for _, account := range accountsCache {
+		if err = dm.DataDB().SetAccount(account); err != nil {
+			return
 		}
 	}
 	return
-
 }
 
 func (cd *CallDescriptor) RefundIncrements() (err error) {
@@ -982,7 +1027,7 @@ func (cd *CallDescriptor) GetLCR(stats rpcclient.RpcClientConnection, lcrFltr *L
 	lcr.Sort()
 	// find if one ore more entries apply to this cd (create lcr timespans)
 	// create timespans and attach lcr entries to them
-	lcrCost := &LCRCost{}
+	lcrCost := &LCRCost{lcrID: lcr.GetId()}
 	for _, lcrActivation := range lcr.Activations {
 		lcrEntry := lcrActivation.GetLCREntryForPrefix(cd.Destination)
 		if lcrActivation.ActivationTime.Before(cd.TimeStart) ||
@@ -1079,6 +1124,25 @@ func (cd *CallDescriptor) GetLCR(stats rpcclient.RpcClientConnection, lcrFltr *L
 			accNeverConsidered := true
 			tccNeverConsidered := true
 			ddcNeverConsidered := true
+			var concurrentCalls float64
+			if lcrCost.Entry.Strategy == LCR_STRATEGY_LEAST_CONCURRENT {
+				if resourceS == nil {
+					lcrCost.SupplierCosts = append(lcrCost.SupplierCosts, &LCRSupplierCost{
+						Supplier: fullSupplier,
+						Error:    "Resource service not configured",
+					})
+					continue
+				}
+				resID := lcrCost.GetSupplierResourceID(fullSupplier)
+				if err := resourceS.Call(utils.ResourceSv1GetUsage,
+					&utils.TenantID{Tenant: lcrCD.Tenant, ID: resID}, &concurrentCalls); err != nil {
+					lcrCost.SupplierCosts = append(lcrCost.SupplierCosts, &LCRSupplierCost{
+						Supplier: fullSupplier,
+						Error:    fmt.Sprintf("Get usage for resource id %s, error %s", resID, err.Error()),
+					})
+					continue
+				}
+			}
 			if utils.IsSliceMember([]string{LCR_STRATEGY_QOS, LCR_STRATEGY_QOS_THRESHOLD, LCR_STRATEGY_LOAD}, lcrCost.Entry.Strategy) {
 				if stats == nil {
 					lcrCost.SupplierCosts = append(lcrCost.SupplierCosts, &LCRSupplierCost{
@@ -1279,9 +1343,10 @@ func (cd *CallDescriptor) GetLCR(stats rpcclient.RpcClientConnection, lcrFltr *L
 					}
 				}
 				supplCost := &LCRSupplierCost{
-					Supplier: fullSupplier,
-					Cost:     cc.Cost,
-					Duration: cc.GetDuration(),
+					Supplier:        fullSupplier,
+					Cost:            cc.Cost,
+					Duration:        cc.GetDuration(),
+					concurrentCalls: concurrentCalls,
 				}
 				qos := make(map[string]float64, 5)
 				if !asrNeverConsidered {
@@ -1712,6 +1712,36 @@ func TestCDRefundIncrementsZeroValue(t *testing.T) {
 	}
 }
 
+func TestCDRefundIncrementsMissingBalance(t *testing.T) {
+	ub := &Account{
+		ID: "test:refmissing",
+		BalanceMap: map[string]Balances{
+			utils.MONETARY: Balances{
+				&Balance{Uuid: "moneya", Value: 100},
+			},
+		},
+	}
+	dm.DataDB().SetAccount(ub)
+	increments := Increments{
+		&Increment{Cost: 2, BalanceInfo: &DebitInfo{Monetary: &MonetaryInfo{UUID: "moneya"}, AccountID: ub.ID}},
+		&Increment{Duration: 4 * time.Second, BalanceInfo: &DebitInfo{Unit: &UnitInfo{UUID: "minuteb"}, AccountID: ub.ID}},
+	}
+	cd := &CallDescriptor{TOR: utils.VOICE, Increments: increments}
+	if err := cd.RefundIncrements(); err == nil {
+		t.Error("Expecting error for removed balance")
+	}
+	ub, _ = dm.DataDB().GetAccount(ub.ID)
+	if ub.BalanceMap[utils.MONETARY][0].GetValue() != 100 {
+		t.Error("Refunded partially: ", utils.ToIJSON(ub.BalanceMap))
+	}
+	cd.Increments = Increments{
+		&Increment{Cost: 2, BalanceInfo: &DebitInfo{Monetary: &MonetaryInfo{UUID: "moneya"}, AccountID: "test:removed"}},
+	}
+	if err := cd.RefundIncrements(); err == nil {
+		t.Error("Expecting error for removed account")
+	}
+}
+
 func TestCDDebitBalanceSubjectWithFallback(t *testing.T) {
 	acnt := &Account{
 		ID: "TCDDBSWF:account1",
@@ -24,7 +24,6 @@ import (
 	"fmt"
 	"io"
 	"net/url"
-	"os"
 	"path"
 	"strconv"
 	"sync"
@@ -73,6 +72,10 @@ func NewCDRExporter(cdrs []*CDR, exportTemplate *config.CdreConfig, exportFormat
 		httpPoster:          httpPoster,
 		negativeExports:     make(map[string]string),
 	}
+	var err error
+	if cdre.target, err = newCDRExportTarget(cdre); err != nil {
+		return nil, err
+	}
 	return cdre, nil
 }
 
@@ -92,6 +95,7 @@ type CDRExporter struct {
 	roundingDecimals    int
 	httpSkipTlsCheck    bool
 	httpPoster          *utils.HTTPPoster
+	target              CDRExportTarget // where the CDRs are delivered, based on exportFormat
 
 	header, trailer []string   // Header and Trailer fields
 	content         [][]string // Rows of cdr fields
@@ -278,21 +282,7 @@ func (cdre *CDRExporter) processCDR(cdr *CDR) (err error) {
 	if cdre.costMultiplyFactor != 0.0 {
 		cdr.CostMultiply(cdre.costMultiplyFactor, cdre.roundingDecimals)
 	}
-	switch cdre.exportFormat {
-	case utils.MetaFileFWV, utils.MetaFileCSV:
-		var cdrRow []string
-		cdrRow, err = cdr.AsExportRecord(cdre.exportTemplate.ContentFields, cdre.httpSkipTlsCheck, cdre.cdrs, cdre.roundingDecimals)
-		if len(cdrRow) == 0 { // No CDR data, most likely no configuration fields defined
-			return
-		} else {
-			cdre.Lock()
-			cdre.content = append(cdre.content, cdrRow)
-			cdre.Unlock()
-		}
-	default: // attempt posting CDR
-		err = cdre.postCdr(cdr)
-	}
-	if err != nil {
+	if err = cdre.target.ExportCDR(cdr); err != nil {
 		utils.Logger.Err(fmt.Sprintf("<CDRE> Cannot export CDR with CGRID: %s and runid: %s, error: %s", cdr.CGRID, cdr.RunID, err.Error()))
 		return
 	}
@@ -349,9 +339,8 @@ func (cdre *CDRExporter) processCDRs() (err error) {
 		if !passesFilters { // Not passes filters, ignore this CDR
 			continue
 		}
-		if cdre.synchronous ||
-			utils.IsSliceMember([]string{utils.MetaFileCSV, utils.MetaFileFWV}, cdre.exportFormat) {
-			wg.Add(1) // wait for synchronous or file ones since these need to be done before continuing
+		if cdre.synchronous || cdre.target.Buffered() {
+			wg.Add(1) // wait for synchronous or buffered ones since these need to be done before continuing
 		}
 		go func(cdr *CDR) {
 			if err := cdre.processCDR(cdr); err != nil {
@@ -363,8 +352,7 @@ func (cdre *CDRExporter) processCDRs() (err error) {
 				cdre.positiveExports = append(cdre.positiveExports, cdr.CGRID)
 				cdre.Unlock()
 			}
-			if cdre.synchronous ||
-				utils.IsSliceMember([]string{utils.MetaFileCSV, utils.MetaFileFWV}, cdre.exportFormat) {
+			if cdre.synchronous || cdre.target.Buffered() {
 				wg.Done()
 			}
 		}(cdr)
@@ -440,24 +428,7 @@ func (cdre *CDRExporter) ExportCDRs() (err error) {
 	if err = cdre.processCDRs(); err != nil {
 		return
 	}
-	if utils.IsSliceMember([]string{utils.MetaFileCSV, utils.MetaFileFWV}, cdre.exportFormat) { // files are written after processing all CDRs
-		cdre.RLock()
-		contLen := len(cdre.content)
-		cdre.RUnlock()
-		if contLen == 0 {
-			return
-		}
-		fileOut, err := os.Create(cdre.exportPath)
-		if err != nil {
-			return err
-		}
-		defer fileOut.Close()
-		if cdre.exportFormat == utils.MetaFileCSV {
-			return cdre.writeCsv(csv.NewWriter(fileOut))
-		}
-		return cdre.writeOut(fileOut)
-	}
-	return
+	return cdre.target.Flush() // buffered targets write their content after processing all CDRs
 }
 
 // Return the first exported Cdr OrderId
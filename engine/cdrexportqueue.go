@@ -0,0 +1,125 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+const (
+	maxCDRExportFailures     = 1000 // failures kept per export, the older ones are only in logs
+	cdrExportQueueStoreBatch = 100  // processed items persisted at once, the whole queue is written each time
+)
+
+// CDRExportItem is one CDR waiting in the retry queue of an online export
+type CDRExportItem struct {
+	OrderKey    string // CDRs sharing the key are delivered in order
+	CDR         *CDR
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+}
+
+// CDRExportQueue holds the CDRs failing online export, persisted in DataDB so the retries survive restarts
+type CDRExportQueue struct {
+	ExportID string
+	Pending  []*CDRExportItem // in arrival order
+	Failed   []*CDRExportItem // last items which exceeded the retry attempts
+}
+
+// Remove takes the item out of the pending ones
+func (q *CDRExportQueue) Remove(itm *CDRExportItem) {
+	for i, pItm := range q.Pending {
+		if pItm == itm {
+			q.Pending = append(q.Pending[:i], q.Pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// AddFailed moves the item out of the pending ones to the failed ones, dropping the oldest failures over maxCDRExportFailures
+func (q *CDRExportQueue) AddFailed(itm *CDRExportItem) {
+	q.Remove(itm)
+	q.Failed = append(q.Failed, itm)
+	if len(q.Failed) > maxCDRExportFailures {
+		q.Failed = append([]*CDRExportItem{}, q.Failed[len(q.Failed)-maxCDRExportFailures:]...)
+	}
+}
+
+// AsStatus summarizes the queue for APIs
+func (q *CDRExportQueue) AsStatus() (qs *CDRExportQueueStatus) {
+	qs = &CDRExportQueueStatus{ExportID: q.ExportID,
+		Pending: len(q.Pending), Failed: len(q.Failed),
+		Failures: make([]*CDRExportFailure, len(q.Failed))}
+	for _, itm := range q.Pending {
+		if qs.NextAttempt.IsZero() || itm.NextAttempt.Before(qs.NextAttempt) {
+			qs.NextAttempt = itm.NextAttempt
+		}
+	}
+	for i, itm := range q.Failed {
+		qs.Failures[i] = &CDRExportFailure{CGRID: itm.CDR.CGRID, RunID: itm.CDR.RunID,
+			Attempts: itm.Attempts, LastError: itm.LastError}
+	}
+	return
+}
+
+// CDRExportQueueStatus is the view over the retry queue of one online export
+type CDRExportQueueStatus struct {
+	ExportID    string
+	Pending     int       // CDRs waiting for retries
+	Failed      int       // CDRs which exceeded the retry attempts
+	NextAttempt time.Time // earliest retry of the pending CDRs
+	Failures    []*CDRExportFailure
+}
+
+// CDRExportFailure describes one CDR which could not be exported
+type CDRExportFailure struct {
+	CGRID     string
+	RunID     string
+	Attempts  int
+	LastError string
+}
+
+// cdrExportOrderKey returns the key used to deliver in order the CDRs of one export
+func cdrExportOrderKey(ordering string, cdr *CDR) string {
+	switch ordering {
+	case utils.MetaAccount:
+		return utils.ConcatenatedKey(cdr.Tenant, cdr.Account)
+	case utils.MetaCGRID:
+		return cdr.CGRID
+	}
+	return utils.ConcatenatedKey(cdr.CGRID, cdr.RunID)
+}
+
+// retryBackoff doubles the retry interval with each attempt, up to maxInterval
+func retryBackoff(interval, maxInterval time.Duration, attempts int) (d time.Duration) {
+	d = interval
+	for i := 1; i < attempts; i++ {
+		if maxInterval > 0 && d >= maxInterval {
+			break
+		}
+		d *= 2
+	}
+	if maxInterval > 0 && d > maxInterval {
+		d = maxInterval
+	}
+	return
+}
@@ -0,0 +1,213 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestCDRExportRetryBackoff(t *testing.T) {
+	for attempts, eDur := range []time.Duration{time.Second, time.Second,
+		2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		if rcv := retryBackoff(time.Second, 10*time.Second, attempts); rcv != eDur {
+			t.Errorf("Attempts: %d, expecting: %v, received: %v", attempts, eDur, rcv)
+		}
+	}
+	if rcv := retryBackoff(time.Second, 0, 5); rcv != 16*time.Second {
+		t.Errorf("Expecting: %v, received: %v", 16*time.Second, rcv)
+	}
+}
+
+func TestCDRExportOrderKey(t *testing.T) {
+	cdr := &CDR{CGRID: "cgrid1", RunID: utils.MetaRaw, Tenant: "cgrates.org", Account: "1001"}
+	if key := cdrExportOrderKey(utils.MetaAccount, cdr); key != "cgrates.org:1001" {
+		t.Errorf("Unexpected key: %s", key)
+	}
+	if key := cdrExportOrderKey(utils.MetaCGRID, cdr); key != "cgrid1" {
+		t.Errorf("Unexpected key: %s", key)
+	}
+	if key := cdrExportOrderKey("", cdr); key != "cgrid1:*raw" {
+		t.Errorf("Unexpected key: %s", key)
+	}
+}
+
+func TestCDRExportQueue(t *testing.T) {
+	nextAttempt := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	itm1 := &CDRExportItem{OrderKey: "cgrates.org:1001", CDR: &CDR{CGRID: "cgrid1", RunID: utils.MetaRaw},
+		Attempts: 1, NextAttempt: nextAttempt.Add(time.Minute), LastError: "timeout"}
+	itm2 := &CDRExportItem{OrderKey: "cgrates.org:1001", CDR: &CDR{CGRID: "cgrid2", RunID: utils.MetaRaw},
+		NextAttempt: nextAttempt}
+	itm3 := &CDRExportItem{OrderKey: "cgrates.org:1002", CDR: &CDR{CGRID: "cgrid3", RunID: utils.MetaRaw},
+		Attempts: 4, LastError: "timeout"}
+	q := &CDRExportQueue{ExportID: "fraud", Pending: []*CDRExportItem{itm1, itm2},
+		Failed: []*CDRExportItem{itm3}}
+	eStatus := &CDRExportQueueStatus{ExportID: "fraud", Pending: 2, Failed: 1,
+		NextAttempt: nextAttempt,
+		Failures: []*CDRExportFailure{&CDRExportFailure{CGRID: "cgrid3", RunID: utils.MetaRaw,
+			Attempts: 4, LastError: "timeout"}}}
+	if rcv := q.AsStatus(); !reflect.DeepEqual(eStatus, rcv) {
+		t.Errorf("Expecting: %+v, received: %+v", eStatus, rcv)
+	}
+	q.Remove(itm1)
+	q.AddFailed(itm2)
+	if len(q.Pending) != 0 || !reflect.DeepEqual([]*CDRExportItem{itm3, itm2}, q.Failed) {
+		t.Errorf("Unexpected queue: %s", utils.ToJSON(q))
+	}
+	for i := 0; i < maxCDRExportFailures; i++ {
+		itm := &CDRExportItem{CDR: &CDR{CGRID: "cgrid" + strconv.Itoa(i+4)}}
+		q.Pending = append(q.Pending, itm)
+		q.AddFailed(itm)
+	}
+	if len(q.Failed) != maxCDRExportFailures || q.Failed[0].CDR.CGRID != "cgrid4" {
+		t.Errorf("Unexpected failures: %d, oldest: %s", len(q.Failed), q.Failed[0].CDR.CGRID)
+	}
+}
+
+// testFlakyExportTarget records the exported CDRs, failing while its error is set
+type testFlakyExportTarget struct {
+	sync.Mutex
+	err      error
+	exported []string
+}
+
+func (ft *testFlakyExportTarget) ExportCDR(cdr *CDR) error {
+	ft.Lock()
+	defer ft.Unlock()
+	if ft.err != nil {
+		return ft.err
+	}
+	ft.exported = append(ft.exported, cdr.CGRID)
+	return nil
+}
+
+func (ft *testFlakyExportTarget) Buffered() bool {
+	return false
+}
+
+func (ft *testFlakyExportTarget) Flush() error {
+	return nil
+}
+
+func TestCDRSOnlineExportsFilteredOrdered(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	dataDB, _ := NewMapStorage()
+	dmCDRs := NewDataManager(dataDB)
+	fltr, _ := NewRequestFilter(MetaString, utils.MEDI_RUNID, []string{utils.MetaRaw})
+	if err := dmCDRs.SetFilter(&Filter{Tenant: "cgrates.org", ID: "FLTR_RAW",
+		RequestFilters: []*RequestFilter{fltr}}); err != nil {
+		t.Fatal(err)
+	}
+	tgt := new(testFlakyExportTarget)
+	RegisterCDRExportTarget("*test_flaky", func(cdre *CDRExporter) (CDRExportTarget, error) {
+		return tgt, nil
+	})
+	expTpl := cfg.CdreProfiles[utils.META_DEFAULT].Clone()
+	expTpl.ExportFormat = "*test_flaky"
+	expTpl.FilterIDs = []string{"FLTR_RAW"}
+	expTpl.Ordering = utils.MetaAccount
+	expTpl.RetryInterval = time.Millisecond
+	expTpl.RetryMaxInterval = time.Millisecond
+	expTpl.RetryAttempts = 2
+	cfg.CdreProfiles["fraud"] = expTpl
+	cfg.CDRSOnlineCDRExports = []string{"fraud"}
+	cdrS := &CdrServer{cgrCfg: cfg, dm: dmCDRs, guard: guardian.Guardian,
+		filterS: NewFilterS(cfg, nil, dmCDRs), expQueues: make(map[string]*CDRExportQueue),
+		expQSignals: make(map[string]chan struct{}), stopExpQueues: make(chan struct{})}
+	tgt.err = errors.New("connection refused")
+	cdrS.replicateCDRs([]*CDR{ // only queued, the export is up to the queue of the export
+		&CDR{CGRID: "cgrid1", RunID: utils.MetaRaw, Tenant: "cgrates.org", Account: "1001", Cost: -1},
+		&CDR{CGRID: "cgrid1", RunID: utils.META_DEFAULT, Tenant: "cgrates.org", Account: "1001", Cost: -1}, // filtered out
+		&CDR{CGRID: "cgrid2", RunID: utils.MetaRaw, Tenant: "cgrates.org", Account: "1001", Cost: -1}})
+	if q, err := dmCDRs.GetCDRExportQueue("fraud"); err != nil { // persisted before export
+		t.Error(err)
+	} else if len(q.Pending) != 2 {
+		t.Errorf("Unexpected stored queue: %s", utils.ToJSON(q))
+	}
+	if nextAttempt := cdrS.processExportQueue("fraud", expTpl); nextAttempt.IsZero() {
+		t.Error("Expecting retry scheduled")
+	}
+	var qs []*CDRExportQueueStatus
+	if err := cdrS.V1GetCDRExportQueues(nil, &qs); err != nil {
+		t.Fatal(err)
+	} else if len(qs) != 1 || qs[0].Pending != 2 {
+		t.Fatalf("Unexpected queues: %s", utils.ToJSON(qs))
+	}
+	tgt.err = nil
+	cdrS.replicateCDRs([]*CDR{ // queued behind the pending ones of the same account
+		&CDR{CGRID: "cgrid3", RunID: utils.MetaRaw, Tenant: "cgrates.org", Account: "1001", Cost: -1}})
+	if nextAttempt := cdrS.processExportQueue("fraud", expTpl); nextAttempt.IsZero() {
+		t.Error("Expecting retry scheduled")
+	} else if len(tgt.exported) != 0 {
+		t.Errorf("Unexpected exported CDRs: %+v", tgt.exported)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if nextAttempt := cdrS.processExportQueue("fraud", expTpl); !nextAttempt.IsZero() {
+		t.Errorf("Unexpected retry at: %v", nextAttempt)
+	}
+	if eExported := []string{"cgrid1", "cgrid2", "cgrid3"}; !reflect.DeepEqual(eExported, tgt.exported) {
+		t.Errorf("Expecting: %+v, received: %+v", eExported, tgt.exported)
+	}
+	// exceed the retry attempts
+	tgt.err = errors.New("connection refused")
+	cdrS.replicateCDRs([]*CDR{
+		&CDR{CGRID: "cgrid4", RunID: utils.MetaRaw, Tenant: "cgrates.org", Account: "1002", Cost: -1}})
+	for i := 0; i < 3; i++ {
+		cdrS.processExportQueue("fraud", expTpl)
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := cdrS.V1GetCDRExportQueues([]string{"fraud"}, &qs); err != nil {
+		t.Fatal(err)
+	} else if len(qs) != 1 || qs[0].Pending != 0 || qs[0].Failed != 1 ||
+		qs[0].Failures[0].CGRID != "cgrid4" || qs[0].Failures[0].Attempts != 3 {
+		t.Errorf("Unexpected queues: %s", utils.ToJSON(qs))
+	}
+	// the queue delivers on its own, on arrival of the CDRs
+	tgt.Lock()
+	tgt.err = nil
+	tgt.Unlock()
+	go cdrS.runExportQueue("fraud")
+	cdrS.replicateCDRs([]*CDR{
+		&CDR{CGRID: "cgrid5", RunID: utils.MetaRaw, Tenant: "cgrates.org", Account: "1002", Cost: -1}})
+	for i := 0; i < 100; i++ {
+		time.Sleep(time.Millisecond)
+		tgt.Lock()
+		exported := len(tgt.exported)
+		tgt.Unlock()
+		if exported == 4 {
+			break
+		}
+	}
+	close(cdrS.stopExpQueues)
+	tgt.Lock()
+	if eExported := []string{"cgrid1", "cgrid2", "cgrid3", "cgrid5"}; !reflect.DeepEqual(eExported, tgt.exported) {
+		t.Errorf("Expecting: %+v, received: %+v", eExported, tgt.exported)
+	}
+	tgt.Unlock()
+	if err := cdrS.V1GetCDRExportQueues([]string{"unknown"}, &qs); err != utils.ErrNotFound {
+		t.Error(err)
+	}
+}
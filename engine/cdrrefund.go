@@ -0,0 +1,236 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// CDRRefund is the outcome of refunding the charges of a CDR
+type CDRRefund struct {
+	CGRID       string
+	RunID       string
+	Usage       time.Duration // refunded by this request
+	Cost        float64
+	TotalUsage  time.Duration // refunded so far, including the previous partial refunds
+	TotalCost   float64
+	CDRLogCGRID string // CGRID of the *cdrlog CDR recording the refund
+}
+
+// cdrRefundState tracks the refunds already done on a CDR, stored within its ExtraFields.
+// Refunds consume the increments from the end of the event backwards, the first one
+// not consumed can have part of its money given back by a previous refund by amount.
+type cdrRefundState struct {
+	usage   time.Duration
+	cost    float64
+	incrs   int    // whole increments refunded
+	pending string // CGRID of the *cdrlog CDR of a refund not confirmed on the CDR
+}
+
+func newCDRRefundState(cdr *CDR) (st *cdrRefundState, err error) {
+	st = new(cdrRefundState)
+	if usageStr, has := cdr.ExtraFields[utils.CGRRefundedUsage]; has {
+		if st.usage, err = utils.ParseDurationWithSecs(usageStr); err != nil {
+			return
+		}
+	}
+	if costStr, has := cdr.ExtraFields[utils.CGRRefundedCost]; has {
+		if st.cost, err = strconv.ParseFloat(costStr, 64); err != nil {
+			return
+		}
+	}
+	if incrsStr, has := cdr.ExtraFields[utils.CGRRefundedIncrements]; has {
+		if st.incrs, err = strconv.Atoi(incrsStr); err != nil {
+			return
+		}
+	}
+	st.pending = cdr.ExtraFields[utils.CGRRefundPending]
+	return
+}
+
+// setPendingOnCDR marks the CDR with the refund about to be given back to the accounts,
+// refusing other refunds until confirmed by setOnCDR
+func (st *cdrRefundState) setPendingOnCDR(cdr *CDR, logCGRID string) {
+	if cdr.ExtraFields == nil {
+		cdr.ExtraFields = make(map[string]string)
+	}
+	st.pending = logCGRID
+	cdr.ExtraFields[utils.CGRRefundPending] = logCGRID
+}
+
+// clearPendingOnCDR removes the mark of a refund which did not reach the accounts
+func (st *cdrRefundState) clearPendingOnCDR(cdr *CDR) {
+	st.pending = ""
+	delete(cdr.ExtraFields, utils.CGRRefundPending)
+}
+
+// setOnCDR marks the CDR as refunded
+func (st *cdrRefundState) setOnCDR(cdr *CDR) {
+	if cdr.ExtraFields == nil {
+		cdr.ExtraFields = make(map[string]string)
+	}
+	cdr.ExtraFields[utils.CGRRefundedUsage] = st.usage.String()
+	cdr.ExtraFields[utils.CGRRefundedCost] = strconv.FormatFloat(st.cost, 'f', -1, 64)
+	cdr.ExtraFields[utils.CGRRefundedIncrements] = strconv.Itoa(st.incrs)
+	st.clearPendingOnCDR(cdr)
+}
+
+// incrementMoney returns the part of the increment cost paid out of monetary balances
+func incrementMoney(inc *Increment) *utils.Decimal {
+	if inc.BalanceInfo == nil || inc.BalanceInfo.Monetary == nil {
+		return utils.NewDecimalFromInt64(0)
+	}
+	return utils.NewDecimalFromFloat64(inc.Cost)
+}
+
+// selectRefundIncrements picks the increments to be refunded out of the decompressed ones of the event, updating the state.
+// With usage only the whole increments fitting in it are refunded, with amount only the money is given back.
+func (st *cdrRefundState) selectRefundIncrements(incs Increments, usage *time.Duration, amount *float64) (rfIncs Increments, rfUsage time.Duration, rfCost *utils.Decimal) {
+	paidBack := utils.NewDecimalFromFloat64(st.cost) // money of the first increment not consumed, refunded already by amount
+	for i := len(incs) - 1; i >= len(incs)-st.incrs && i >= 0; i-- {
+		paidBack = paidBack.Sub(incrementMoney(incs[i]))
+	}
+	rfCost = utils.NewDecimalFromInt64(0)
+	for i := len(incs) - 1 - st.incrs; i >= 0; i-- {
+		inc := incs[i]
+		if usage != nil && rfUsage+inc.Duration > *usage {
+			break
+		}
+		incCost := incrementMoney(inc)
+		if paidBack.Sign() > 0 {
+			incCost = incCost.Sub(paidBack)
+			paidBack = utils.NewDecimalFromInt64(0)
+		}
+		if amount != nil {
+			if inc.BalanceInfo == nil || inc.BalanceInfo.Monetary == nil {
+				break // units are given back only when refunding usage
+			}
+			if remaining := utils.NewDecimalFromFloat64(*amount).Sub(rfCost); incCost.Cmp(remaining) > 0 {
+				if remaining.Sign() > 0 { // give back part of the increment money, its units stay consumed
+					rfIncs = append(rfIncs, &Increment{Cost: remaining.Float64(), CompressFactor: 1,
+						BalanceInfo: &DebitInfo{Monetary: inc.BalanceInfo.Monetary.Clone(), AccountID: inc.BalanceInfo.AccountID}})
+					rfCost = rfCost.Add(remaining)
+				}
+				break
+			}
+		}
+		rfInc := inc.Clone()
+		rfInc.Cost = incCost.Float64()
+		rfInc.CompressFactor = 1
+		rfIncs = append(rfIncs, rfInc)
+		rfUsage += inc.Duration
+		rfCost = rfCost.Add(incCost)
+		st.incrs++
+	}
+	st.usage += rfUsage
+	st.cost = utils.NewDecimalFromFloat64(st.cost).Add(rfCost).Float64()
+	return
+}
+
+// RefundCDR gives back to the accounts the charges of a stored CDR, all of them or only the
+// ones for the last usage or amount. The CDR is marked as refunded and a negative *cdrlog CDR is written.
+// CDRs left with a pending refund by storage errors are refused with ErrRefundPending.
+func RefundCDR(cdrDB CdrStorage, cgrID, runID string, usage *time.Duration, amount *float64) (rf *CDRRefund, err error) {
+	if runID == "" {
+		runID = utils.META_DEFAULT
+	}
+	var reply interface{}
+	if reply, err = guardian.Guardian.Guard(func() (interface{}, error) {
+		return refundCDR(cdrDB, cgrID, runID, usage, amount)
+	}, config.CgrConfig().LockingTimeout, utils.MetaRefund+utils.ConcatenatedKey(cgrID, runID)); err != nil {
+		return
+	} else if reply == nil { // locking timeout, the refund goes on in background
+		return nil, utils.ErrTimedOut
+	}
+	return reply.(*CDRRefund), nil
+}
+
+func refundCDR(cdrDB CdrStorage, cgrID, runID string, usage *time.Duration, amount *float64) (rf *CDRRefund, err error) {
+	cdrs, _, err := cdrDB.GetCDRs(&utils.CDRsFilter{CGRIDs: []string{cgrID}, RunIDs: []string{runID}}, false)
+	if err != nil {
+		return nil, err
+	} else if len(cdrs) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	cdr := cdrs[0]
+	if cdr.CostDetails == nil {
+		return nil, utils.ErrNotRefundable
+	}
+	st, err := newCDRRefundState(cdr)
+	if err != nil {
+		return nil, err
+	}
+	if st.pending != "" { // outcome of a previous refund unknown, needs to be checked manually
+		return nil, utils.ErrRefundPending
+	}
+	cc := cdr.CostDetails
+	cc.Timespans.Decompress()
+	var incs Increments
+	for _, ts := range cc.Timespans {
+		incs = append(incs, ts.Increments...)
+	}
+	rfIncs, rfUsage, rfCost := st.selectRefundIncrements(incs, usage, amount)
+	cc.Timespans.Compress()
+	if len(rfIncs) == 0 {
+		return nil, utils.ErrNotRefundable
+	}
+	cd := cc.CreateCallDescriptor()
+	cd.CgrID = cdr.CGRID
+	cd.RunID = cdr.RunID
+	cd.Increments = rfIncs
+	now := time.Now()
+	logCDR := &CDR{RunID: utils.MetaRefund, Source: CDRLOG, OriginID: utils.GenUUID(),
+		ToR: cdr.ToR, RequestType: cdr.RequestType, Direction: cdr.Direction, Tenant: cdr.Tenant,
+		Category: cdr.Category, Account: cdr.Account, Subject: cdr.Subject, Destination: cdr.Destination,
+		SetupTime: now, AnswerTime: now, Usage: -rfUsage, Cost: rfCost.Neg().Float64(),
+		ExtraFields: map[string]string{utils.RefundedCGRID: cdr.CGRID, utils.RefundedRunID: cdr.RunID}}
+	logCDR.CGRID = utils.Sha1(logCDR.OriginID, logCDR.SetupTime.String())
+	// mark the CDR before touching the accounts so failing to store the outcome cannot refund twice
+	st.setPendingOnCDR(cdr, logCDR.CGRID)
+	if err = cdrDB.SetCDR(cdr, true); err != nil {
+		return nil, err
+	}
+	if err = cd.RefundIncrements(); err != nil { // locks the accounts
+		st.clearPendingOnCDR(cdr)
+		if errSet := cdrDB.SetCDR(cdr, true); errSet != nil {
+			utils.Logger.Warning(fmt.Sprintf("<CDRRefund> CDR with CGRID: %s, RunID: %s left refund pending, error: %s",
+				cdr.CGRID, cdr.RunID, errSet.Error()))
+		}
+		return nil, err
+	}
+	st.setOnCDR(cdr)
+	if err = cdrDB.SetCDR(cdr, true); err != nil {
+		utils.Logger.Crit(fmt.Sprintf("<CDRRefund> refunded CDR with CGRID: %s, RunID: %s left refund pending for *cdrlog CGRID: %s, error: %s",
+			cdr.CGRID, cdr.RunID, logCDR.CGRID, err.Error()))
+		return nil, err
+	}
+	if err = cdrDB.SetCDR(logCDR, false); err != nil {
+		return nil, err
+	}
+	return &CDRRefund{CGRID: cdr.CGRID, RunID: cdr.RunID,
+		Usage: rfUsage, Cost: rfCost.Float64(),
+		TotalUsage: st.usage, TotalCost: st.cost,
+		CDRLogCGRID: logCDR.CGRID}, nil
+}
@@ -0,0 +1,125 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func testRefundIncrements() Increments {
+	unitInc := &Increment{Duration: time.Minute,
+		BalanceInfo: &DebitInfo{Unit: &UnitInfo{UUID: "unit1"}, AccountID: "cgrates.org:1001"}}
+	moneyInc := func() *Increment {
+		return &Increment{Duration: time.Minute, Cost: 0.5,
+			BalanceInfo: &DebitInfo{Monetary: &MonetaryInfo{UUID: "money1"}, AccountID: "cgrates.org:1001"}}
+	}
+	return Increments{unitInc, moneyInc(), moneyInc(), moneyInc()}
+}
+
+func TestCDRRefundSelectFull(t *testing.T) {
+	st := new(cdrRefundState)
+	rfIncs, rfUsage, rfCost := st.selectRefundIncrements(testRefundIncrements(), nil, nil)
+	if len(rfIncs) != 4 {
+		t.Errorf("expecting 4 increments, received: %d", len(rfIncs))
+	}
+	if rfUsage != 4*time.Minute || rfCost.Float64() != 1.5 {
+		t.Errorf("usage: %v, cost: %v", rfUsage, rfCost)
+	}
+	if st.incrs != 4 || st.usage != 4*time.Minute || st.cost != 1.5 {
+		t.Errorf("unexpected state: %+v", st)
+	}
+	if rfIncs, _, _ = st.selectRefundIncrements(testRefundIncrements(), nil, nil); len(rfIncs) != 0 {
+		t.Errorf("refunded twice: %+v", rfIncs)
+	}
+}
+
+func TestCDRRefundSelectUsage(t *testing.T) {
+	st := new(cdrRefundState)
+	usage := 150 * time.Second
+	rfIncs, rfUsage, rfCost := st.selectRefundIncrements(testRefundIncrements(), &usage, nil)
+	if len(rfIncs) != 2 || rfUsage != 2*time.Minute || rfCost.Float64() != 1.0 {
+		t.Errorf("increments: %d, usage: %v, cost: %v", len(rfIncs), rfUsage, rfCost)
+	}
+	// the rest of the event, including the units
+	rfIncs, rfUsage, rfCost = st.selectRefundIncrements(testRefundIncrements(), nil, nil)
+	if len(rfIncs) != 2 || rfUsage != 2*time.Minute || rfCost.Float64() != 0.5 {
+		t.Errorf("increments: %d, usage: %v, cost: %v", len(rfIncs), rfUsage, rfCost)
+	} else if rfIncs[1].BalanceInfo.Unit == nil {
+		t.Errorf("units not refunded: %+v", rfIncs[1].BalanceInfo)
+	}
+}
+
+func TestCDRRefundSelectAmount(t *testing.T) {
+	st := new(cdrRefundState)
+	amount := 0.7
+	rfIncs, rfUsage, rfCost := st.selectRefundIncrements(testRefundIncrements(), nil, &amount)
+	if len(rfIncs) != 2 || rfUsage != time.Minute || rfCost.Float64() != 0.7 {
+		t.Errorf("increments: %d, usage: %v, cost: %v", len(rfIncs), rfUsage, rfCost)
+	} else if rfIncs[1].Duration != 0 || rfIncs[1].Cost != 0.2 {
+		t.Errorf("unexpected partial increment: %+v", rfIncs[1])
+	}
+	// the partially refunded increment gives back only its remaining money
+	amount = 10
+	rfIncs, _, rfCost = st.selectRefundIncrements(testRefundIncrements(), nil, &amount)
+	if len(rfIncs) != 2 || rfCost.Float64() != 0.8 {
+		t.Errorf("increments: %d, cost: %v", len(rfIncs), rfCost)
+	}
+	if st.cost != 1.5 || st.incrs != 3 {
+		t.Errorf("unexpected state: %+v", st)
+	}
+}
+
+func TestCDRRefundStateOnCDR(t *testing.T) {
+	cdr := &CDR{CGRID: "cgrid1"}
+	st := &cdrRefundState{usage: 90 * time.Second, cost: 0.75, incrs: 3}
+	st.setOnCDR(cdr)
+	if cdr.ExtraFields[utils.CGRRefundedIncrements] != "3" {
+		t.Errorf("unexpected extra fields: %+v", cdr.ExtraFields)
+	}
+	if rcv, err := newCDRRefundState(cdr); err != nil {
+		t.Error(err)
+	} else if *rcv != *st {
+		t.Errorf("expecting: %+v, received: %+v", st, rcv)
+	}
+}
+
+func TestCDRRefundStatePending(t *testing.T) {
+	cdr := &CDR{CGRID: "cgrid1"}
+	st := &cdrRefundState{usage: 90 * time.Second, cost: 0.75, incrs: 3}
+	st.setPendingOnCDR(cdr, "logcgrid1")
+	if rcv, err := newCDRRefundState(cdr); err != nil {
+		t.Error(err)
+	} else if rcv.pending != "logcgrid1" {
+		t.Errorf("received: %+v", rcv)
+	}
+	st.setOnCDR(cdr) // confirmed
+	if _, has := cdr.ExtraFields[utils.CGRRefundPending]; has {
+		t.Errorf("unexpected extra fields: %+v", cdr.ExtraFields)
+	}
+	st.setPendingOnCDR(cdr, "logcgrid2")
+	st.clearPendingOnCDR(cdr) // not reaching the accounts
+	if rcv, err := newCDRRefundState(cdr); err != nil {
+		t.Error(err)
+	} else if *rcv != *st {
+		t.Errorf("expecting: %+v, received: %+v", st, rcv)
+	}
+}
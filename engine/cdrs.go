@@ -19,11 +19,13 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cgrates/cgrates/cache"
@@ -70,7 +72,7 @@ func fsCdrHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func NewCdrServer(cgrCfg *config.CGRConfig, cdrDb CdrStorage, dm *DataManager, rater, pubsub, users,
-	aliases, cdrstats, thdS, stats rpcclient.RpcClientConnection) (*CdrServer, error) {
+	aliases, cdrstats, thdS, stats rpcclient.RpcClientConnection, filterS *FilterS) (*CdrServer, error) {
 	if rater != nil && reflect.ValueOf(rater).IsNil() { // Work around so we store actual nil instead of nil interface value, faster to check here than in CdrServer code
 		rater = nil
 	}
@@ -92,10 +94,20 @@ func NewCdrServer(cgrCfg *config.CGRConfig, cdrDb CdrStorage, dm *DataManager, r
 	if stats != nil && reflect.ValueOf(stats).IsNil() {
 		stats = nil
 	}
+	httpPoster := utils.NewHTTPPoster(cgrCfg.HttpSkipTlsVerify, cgrCfg.ReplyTimeout)
+	if cgrCfg.TLSClientCertificate != "" || cgrCfg.TLSClientCACertificate != "" { // mutual TLS for the online exports
+		tlsCfg, err := utils.NewClientTLSConfig(cgrCfg.TLSClientCertificate, cgrCfg.TLSClientKey, cgrCfg.TLSClientCACertificate)
+		if err != nil {
+			return nil, err
+		}
+		httpPoster.SetClientTLSConfig(tlsCfg)
+	}
 	return &CdrServer{cgrCfg: cgrCfg, cdrDb: cdrDb, dm: dm,
 		rals: rater, pubsub: pubsub, users: users, aliases: aliases,
 		cdrstats: cdrstats, stats: stats, thdS: thdS, guard: guardian.Guardian,
-		httpPoster: utils.NewHTTPPoster(cgrCfg.HttpSkipTlsVerify, cgrCfg.ReplyTimeout)}, nil
+		httpPoster: httpPoster,
+		filterS:    filterS, taxS: NewTaxService(dm, filterS), expQueues: make(map[string]*CDRExportQueue),
+		expQSignals: make(map[string]chan struct{}), stopExpQueues: make(chan struct{})}, nil
 }
 
 type CdrServer struct {
@@ -112,6 +124,37 @@ type CdrServer struct {
 	guard         *guardian.GuardianLock
 	responseCache *cache.ResponseCache
 	httpPoster    *utils.HTTPPoster // used for replication
+	filterS       *FilterS          // filters the CDRs of online exports
+	taxS          *TaxService       // taxes the *tax derived runs
+	expQueues     map[string]*CDRExportQueue
+	expQSignals   map[string]chan struct{} // wake up the queue of the export on new CDRs
+	expQMux       sync.Mutex               // protects expQueues and expQSignals
+	stopExpQueues chan struct{}
+}
+
+// ListenAndServe delivers the queued online exports until shutdown
+func (self *CdrServer) ListenAndServe(exitChan chan bool) error {
+	for _, exportID := range self.cgrCfg.CDRSOnlineCDRExports {
+		expTpl := self.cgrCfg.CdreProfilesCfg()[exportID]
+		if expTpl.RetryQueue() && self.dm == nil {
+			utils.Logger.Warning(
+				fmt.Sprintf("<CDRS> No DataDB to persist the retry queue for export: <%s>, the retries are lost on restart", exportID))
+		}
+		if expTpl.Ordering != "" || expTpl.RetryQueue() {
+			go self.runExportQueue(exportID)
+		}
+	}
+	e := <-exitChan
+	exitChan <- e // put back for the others listening for shutdown request
+	return nil
+}
+
+// Shutdown is called to shutdown the service
+func (self *CdrServer) Shutdown() error {
+	utils.Logger.Info("<CDRS> service shutdown initialized")
+	close(self.stopExpQueues)
+	utils.Logger.Info("<CDRS> service shutdown complete")
+	return nil
 }
 
 func (self *CdrServer) Timezone() string {
@@ -266,13 +309,18 @@ func (self *CdrServer) deriveRateStoreStatsReplicate(cdr *CDR, store, cdrstats,
 		}
 		ratedCDRs = append(ratedCDRs, rcvRatedCDRs...)
 	}
-	// Request should be processed by SureTax
+	// Request should be processed by SureTax or the local TaxService
 	for _, ratedCDR := range ratedCDRs {
 		if ratedCDR.RunID == utils.META_SURETAX {
 			if err := SureTaxProcessCdr(ratedCDR); err != nil {
 				ratedCDR.Cost = -1.0
 				ratedCDR.ExtraInfo = err.Error() // Something failed, write the error in the ExtraInfo
 			}
+		} else if ratedCDR.RunID == utils.MetaTax && ratedCDR.Cost != -1 {
+			if err := self.taxS.TaxCDR(ratedCDR); err != nil {
+				ratedCDR.Cost = -1.0
+				ratedCDR.ExtraInfo = err.Error()
+			}
 		}
 	}
 	// Store AccountSummary if requested
@@ -495,22 +543,255 @@ func (self *CdrServer) getCostFromRater(cdr *CDR) (*CallCost, error) {
 
 func (self *CdrServer) replicateCDRs(cdrs []*CDR) (err error) {
 	for _, exportID := range self.cgrCfg.CDRSOnlineCDRExports {
-		expTpl := self.cgrCfg.CdreProfiles[exportID] // not checking for existence of profile since this should be done in a higher layer
-		var cdre *CDRExporter
-		if cdre, err = NewCDRExporter(cdrs, expTpl, expTpl.ExportFormat, expTpl.ExportPath, self.cgrCfg.FailedPostsDir, "CDRSReplication",
-			expTpl.Synchronous, expTpl.Attempts, expTpl.FieldSeparator, expTpl.UsageMultiplyFactor,
-			expTpl.CostMultiplyFactor, self.cgrCfg.RoundingDecimals, self.cgrCfg.HttpSkipTlsVerify, self.httpPoster); err != nil {
-			utils.Logger.Err(fmt.Sprintf("<CDRS> Building CDRExporter for online exports got error: <%s>", err.Error()))
+		expTpl := self.cgrCfg.CdreProfilesCfg()[exportID] // not checking for existence of profile since this should be done in a higher layer
+		var expCDRs []*CDR
+		for _, cdr := range cdrs {
+			if pass, err := self.passesExportFilters(expTpl, cdr); err != nil {
+				utils.Logger.Warning(
+					fmt.Sprintf("<CDRS> Filtering CDR with CGRID: <%s> for export: <%s>, got error: <%s>",
+						cdr.CGRID, exportID, err.Error()))
+			} else if pass {
+				expCDRs = append(expCDRs, cdr)
+			}
+		}
+		if len(expCDRs) == 0 {
 			continue
 		}
-		if err = cdre.ExportCDRs(); err != nil {
-			utils.Logger.Err(fmt.Sprintf("<CDRS> Replicating CDR: %+v, got error: <%s>", err.Error()))
+		if expTpl.Ordering != "" || expTpl.RetryQueue() { // delivered by the queue of the export
+			self.queueExports(exportID, expTpl, expCDRs)
+			continue
+		}
+		if _, err = self.exportCDRs(expTpl, expCDRs, expTpl.Synchronous, self.cgrCfg.FailedPostsDir); err != nil {
+			utils.Logger.Err(fmt.Sprintf("<CDRS> Replicating CDRs with export: <%s>, got error: <%s>", exportID, err.Error()))
 			continue
 		}
 	}
 	return
 }
 
+// passesExportFilters checks the CDR against the FilterS profiles of the export template
+func (self *CdrServer) passesExportFilters(expTpl *config.CdreConfig, cdr *CDR) (pass bool, err error) {
+	if len(expTpl.FilterIDs) == 0 {
+		return true, nil
+	}
+	if self.filterS == nil {
+		return false, errors.New("FilterS not available")
+	}
+	cdrIf, err := cdr.AsMapStringIface()
+	if err != nil {
+		return
+	}
+	return self.filterS.PassFiltersForEvent(cdr.Tenant, cdrIf, expTpl.FilterIDs)
+}
+
+// exportCDRs builds one CDRExporter out of the export template, returning the CDRs failing to be exported
+func (self *CdrServer) exportCDRs(expTpl *config.CdreConfig, cdrs []*CDR,
+	synchronous bool, fallbackPath string) (failed map[string]string, err error) {
+	var cdre *CDRExporter
+	if cdre, err = NewCDRExporter(cdrs, expTpl, expTpl.ExportFormat, expTpl.ExportPath, fallbackPath, "CDRSReplication",
+		synchronous, expTpl.Attempts, expTpl.FieldSeparator, expTpl.UsageMultiplyFactor,
+		expTpl.CostMultiplyFactor, self.cgrCfg.RoundingDecimals, self.cgrCfg.HttpSkipTlsVerify, self.httpPoster); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<CDRS> Building CDRExporter for online exports got error: <%s>", err.Error()))
+		return
+	}
+	if cdre == nil { // nothing to export
+		return
+	}
+	if err = cdre.ExportCDRs(); err != nil {
+		return
+	}
+	return cdre.NegativeExports(), nil
+}
+
+// exportCDR synchronously exports one CDR, returning the error as string if any
+func (self *CdrServer) exportCDR(expTpl *config.CdreConfig, cdr *CDR, fallbackPath string) string {
+	failed, err := self.exportCDRs(expTpl, []*CDR{cdr}, true, fallbackPath)
+	if err != nil {
+		return err.Error()
+	}
+	for _, errStr := range failed {
+		return errStr
+	}
+	return ""
+}
+
+// cdrExportQueue returns the retry queue of the export, restoring it out of DataDB on first access
+// expQMux should be locked by the caller
+func (self *CdrServer) cdrExportQueue(exportID string) (q *CDRExportQueue) {
+	if q = self.expQueues[exportID]; q != nil {
+		return
+	}
+	if self.dm != nil {
+		var err error
+		if q, err = self.dm.GetCDRExportQueue(exportID); err != nil && err != utils.ErrNotFound {
+			utils.Logger.Warning(
+				fmt.Sprintf("<CDRS> Restoring retry queue for export: <%s>, got error: <%s>", exportID, err.Error()))
+		}
+	}
+	if q == nil {
+		q = &CDRExportQueue{ExportID: exportID}
+	}
+	self.expQueues[exportID] = q
+	return
+}
+
+// storeCDRExportQueue persists the queue so the retries survive restarts, expQMux should be locked by the caller
+func (self *CdrServer) storeCDRExportQueue(q *CDRExportQueue) {
+	if self.dm == nil {
+		return
+	}
+	if err := self.dm.SetCDRExportQueue(q); err != nil {
+		utils.Logger.Warning(
+			fmt.Sprintf("<CDRS> Storing retry queue for export: <%s>, got error: <%s>", q.ExportID, err.Error()))
+	}
+}
+
+// exportQueueSignal returns the channel waking up the queue of the export, expQMux should be locked by the caller
+func (self *CdrServer) exportQueueSignal(exportID string) (sig chan struct{}) {
+	if sig = self.expQSignals[exportID]; sig == nil {
+		sig = make(chan struct{}, 1)
+		self.expQSignals[exportID] = sig
+	}
+	return
+}
+
+// queueExports adds the CDRs at the end of the queue of the export, in arrival order,
+// persisting it if the export template enables the retry queue
+func (self *CdrServer) queueExports(exportID string, expTpl *config.CdreConfig, cdrs []*CDR) {
+	self.expQMux.Lock()
+	q := self.cdrExportQueue(exportID)
+	now := time.Now()
+	for _, cdr := range cdrs {
+		q.Pending = append(q.Pending,
+			&CDRExportItem{OrderKey: cdrExportOrderKey(expTpl.Ordering, cdr), CDR: cdr, NextAttempt: now})
+	}
+	if expTpl.RetryQueue() {
+		self.storeCDRExportQueue(q)
+	}
+	sig := self.exportQueueSignal(exportID)
+	self.expQMux.Unlock()
+	select { // a pass is already pending otherwise
+	case sig <- struct{}{}:
+	default:
+	}
+}
+
+// runExportQueue delivers the queued CDRs of the export on arrival and when the retries are due, until shutdown.
+// One goroutine per export keeps the order and does not block the processing of the CDRs.
+func (self *CdrServer) runExportQueue(exportID string) {
+	self.expQMux.Lock()
+	sig := self.exportQueueSignal(exportID)
+	self.expQMux.Unlock()
+	for {
+		var retry <-chan time.Time
+		if nextAttempt := self.processExportQueue(exportID,
+			self.cgrCfg.CdreProfilesCfg()[exportID]); !nextAttempt.IsZero() {
+			retry = time.After(nextAttempt.Sub(time.Now()))
+		}
+		select {
+		case <-self.stopExpQueues:
+			return
+		case <-sig:
+		case <-retry:
+		}
+	}
+}
+
+// processExportQueue goes once through the queue of the export, stopping for one order key
+// at the first CDR which is not yet due or fails. Without retry queue the failed CDRs go to the failed posts.
+// The queue is persisted every cdrExportQueueStoreBatch processed items and at the end of the pass.
+// Returns the time of the earliest retry, zero if none.
+func (self *CdrServer) processExportQueue(exportID string, expTpl *config.CdreConfig) (nextAttempt time.Time) {
+	self.expQMux.Lock()
+	q := self.cdrExportQueue(exportID)
+	pending := make([]*CDRExportItem, len(q.Pending))
+	copy(pending, q.Pending)
+	self.expQMux.Unlock()
+	fallbackPath := self.cgrCfg.FailedPostsDir
+	if expTpl.RetryQueue() { // the queue takes care of failures
+		fallbackPath = utils.META_NONE
+	}
+	blocked := make(map[string]bool) // order keys with older CDRs still pending
+	var unstored int                 // items processed since the queue was persisted, a crash exports them again
+	for _, itm := range pending {
+		if blocked[itm.OrderKey] {
+			continue
+		}
+		if itm.NextAttempt.After(time.Now()) {
+			blocked[itm.OrderKey] = true
+			if nextAttempt.IsZero() || itm.NextAttempt.Before(nextAttempt) {
+				nextAttempt = itm.NextAttempt
+			}
+			continue
+		}
+		errStr := self.exportCDR(expTpl, itm.CDR, fallbackPath)
+		if errStr != "" && itm.Attempts == 0 {
+			utils.Logger.Warning(
+				fmt.Sprintf("<CDRS> Exporting CDR with CGRID: <%s> with export: <%s>, got error: <%s>",
+					itm.CDR.CGRID, exportID, errStr))
+		}
+		self.expQMux.Lock()
+		if errStr == "" || !expTpl.RetryQueue() {
+			q.Remove(itm)
+		} else {
+			itm.Attempts++
+			itm.LastError = errStr
+			if expTpl.RetryAttempts > 0 && itm.Attempts > expTpl.RetryAttempts {
+				utils.Logger.Warning(
+					fmt.Sprintf("<CDRS> Giving up exporting CDR with CGRID: <%s> with export: <%s>, last error: <%s>",
+						itm.CDR.CGRID, exportID, errStr))
+				q.AddFailed(itm)
+			} else {
+				itm.NextAttempt = time.Now().Add(
+					retryBackoff(expTpl.RetryInterval, expTpl.RetryMaxInterval, itm.Attempts))
+				blocked[itm.OrderKey] = true
+				if nextAttempt.IsZero() || itm.NextAttempt.Before(nextAttempt) {
+					nextAttempt = itm.NextAttempt
+				}
+			}
+		}
+		if expTpl.RetryQueue() {
+			if unstored++; unstored == cdrExportQueueStoreBatch {
+				self.storeCDRExportQueue(q)
+				unstored = 0
+			}
+		}
+		self.expQMux.Unlock()
+	}
+	if unstored != 0 {
+		self.expQMux.Lock()
+		self.storeCDRExportQueue(q)
+		self.expQMux.Unlock()
+	}
+	return
+}
+
+// V1GetCDRExportQueues returns the status of the retry queues for the online exports, all if exportIDs are empty
+func (self *CdrServer) V1GetCDRExportQueues(exportIDs []string, reply *[]*CDRExportQueueStatus) error {
+	if len(exportIDs) == 0 {
+		for _, exportID := range self.cgrCfg.CDRSOnlineCDRExports {
+			if self.cgrCfg.CdreProfilesCfg()[exportID].RetryQueue() {
+				exportIDs = append(exportIDs, exportID)
+			}
+		}
+	}
+	stats := make([]*CDRExportQueueStatus, 0, len(exportIDs))
+	self.expQMux.Lock()
+	for _, exportID := range exportIDs {
+		if !utils.IsSliceMember(self.cgrCfg.CDRSOnlineCDRExports, exportID) {
+			self.expQMux.Unlock()
+			return utils.ErrNotFound
+		}
+		stats = append(stats, self.cdrExportQueue(exportID).AsStatus())
+	}
+	self.expQMux.Unlock()
+	if len(stats) == 0 {
+		return utils.ErrNotFound
+	}
+	*reply = stats
+	return nil
+}
+
 // Called by rate/re-rate API, FixMe: deprecate it once new APIer structure is operational
 func (self *CdrServer) RateCDRs(cdrFltr *utils.CDRsFilter, sendToStats bool) error {
 	cdrs, _, err := self.cdrDb.GetCDRs(cdrFltr, false)
@@ -17,8 +17,11 @@ package engine
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/cgrates/cgrates/cache"
 	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/guardian"
 	"github.com/cgrates/cgrates/utils"
 )
 
@@ -134,7 +137,9 @@ func (dm *DataManager) CacheDataFromDB(prfx string, ids []string, mustBeCached b
 		utils.StatQueueProfilePrefix,
 		utils.ThresholdPrefix,
 		utils.ThresholdProfilePrefix,
-		utils.FilterPrefix}, prfx) {
+		utils.FilterPrefix,
+		utils.SupplierProfilePrefix,
+		utils.ExchangeRatePrefix}, prfx) {
 		return utils.NewCGRError(utils.MONGO,
 			utils.MandatoryIEMissingCaps,
 			utils.UnsupportedCachePrefix,
@@ -220,6 +225,15 @@ func (dm *DataManager) CacheDataFromDB(prfx string, ids []string, mustBeCached b
 		case utils.FilterPrefix:
 			tntID := utils.NewTenantID(dataID)
 			_, err = dm.GetFilter(tntID.Tenant, tntID.ID, true, utils.NonTransactional)
+		case utils.SupplierProfilePrefix:
+			tntID := utils.NewTenantID(dataID)
+			_, err = dm.GetSupplierProfile(tntID.Tenant, tntID.ID, true, utils.NonTransactional)
+		case utils.ExchangeRatePrefix:
+			currencies := strings.Split(dataID, utils.CONCATENATED_KEY_SEP)
+			if len(currencies) != 2 {
+				return fmt.Errorf("invalid exchange rate ID: %s", dataID)
+			}
+			_, err = dm.GetExchangeRate(currencies[0], currencies[1], true, utils.NonTransactional)
 		}
 		if err != nil {
 			return utils.NewCGRError(utils.MONGO,
@@ -275,6 +289,210 @@ func (dm *DataManager) RemStatQueue(tenant, id string, transactionID string) (er
 	return
 }
 
+// GetCDRExportQueue returns the retry queue of an online CDR export, not cached since owned by CDRs
+func (dm *DataManager) GetCDRExportQueue(exportID string) (q *CDRExportQueue, err error) {
+	return dm.dataDB.GetCDRExportQueueDrv(exportID)
+}
+
+// SetCDRExportQueue stores the retry queue of an online CDR export
+func (dm *DataManager) SetCDRExportQueue(q *CDRExportQueue) (err error) {
+	return dm.dataDB.SetCDRExportQueueDrv(q)
+}
+
+// RemCDRExportQueue removes the retry queue of an online CDR export
+func (dm *DataManager) RemCDRExportQueue(exportID string) (err error) {
+	return dm.dataDB.RemCDRExportQueueDrv(exportID)
+}
+
+// GetIdempotencyRecord returns the stored reply of a request carrying an IdempotencyKey, not cached since read once per retry
+func (dm *DataManager) GetIdempotencyRecord(id string) (rec *IdempotencyRecord, err error) {
+	return dm.dataDB.GetIdempotencyRecordDrv(id)
+}
+
+// SetIdempotencyRecord stores the reply of a request carrying an IdempotencyKey
+func (dm *DataManager) SetIdempotencyRecord(rec *IdempotencyRecord) (err error) {
+	return dm.dataDB.SetIdempotencyRecordDrv(rec)
+}
+
+// RemIdempotencyRecord removes the reply of a request carrying an IdempotencyKey
+func (dm *DataManager) RemIdempotencyRecord(id string) (err error) {
+	return dm.dataDB.RemIdempotencyRecordDrv(id)
+}
+
+// GetInvoice returns a stored invoice, not cached since rarely read
+func (dm *DataManager) GetInvoice(tenant, id string) (inv *Invoice, err error) {
+	return dm.dataDB.GetInvoiceDrv(tenant, id)
+}
+
+// SetInvoice stores an invoice
+func (dm *DataManager) SetInvoice(inv *Invoice) (err error) {
+	return dm.dataDB.SetInvoiceDrv(inv)
+}
+
+// RemoveInvoice removes a stored invoice
+func (dm *DataManager) RemoveInvoice(tenant, id string) (err error) {
+	return dm.dataDB.RemInvoiceDrv(tenant, id)
+}
+
+// GetInvoiceSequence returns the last invoice number given out within the tenant
+func (dm *DataManager) GetInvoiceSequence(tenant string) (seq *InvoiceSequence, err error) {
+	return dm.dataDB.GetInvoiceSequenceDrv(tenant)
+}
+
+// SetInvoiceSequence stores the last invoice number given out within the tenant
+func (dm *DataManager) SetInvoiceSequence(seq *InvoiceSequence) (err error) {
+	return dm.dataDB.SetInvoiceSequenceDrv(seq)
+}
+
+// GetTaxProfile returns a TaxProfile out of dataDB
+func (dm *DataManager) GetTaxProfile(tenant, id string) (txPrfl *TaxProfile, err error) {
+	return dm.dataDB.GetTaxProfileDrv(tenant, id)
+}
+
+// SetTaxProfile stores a TaxProfile and indexes it for the filters
+func (dm *DataManager) SetTaxProfile(txPrfl *TaxProfile) (err error) {
+	var reqFltrs []*RequestFilter
+	for _, fltrID := range txPrfl.FilterIDs {
+		fltr, err := dm.GetFilter(txPrfl.Tenant, fltrID, false, utils.NonTransactional)
+		if err != nil {
+			if err == utils.ErrNotFound {
+				err = fmt.Errorf("broken reference to filter: %s for tax profile: %s", fltrID, txPrfl.TenantID())
+			}
+			return err
+		}
+		reqFltrs = append(reqFltrs, fltr.RequestFilters...)
+	}
+	if err = dm.dataDB.SetTaxProfileDrv(txPrfl); err != nil {
+		return
+	}
+	return dm.indexTaxProfile(txPrfl.Tenant, txPrfl.ID, reqFltrs, true)
+}
+
+// RemoveTaxProfile removes a TaxProfile together with its filter indexes
+func (dm *DataManager) RemoveTaxProfile(tenant, id string) (err error) {
+	if err = dm.dataDB.RemTaxProfileDrv(tenant, id); err != nil {
+		return
+	}
+	return dm.indexTaxProfile(tenant, id, nil, false)
+}
+
+// indexTaxProfile replaces the filter indexes of a TaxProfile, removing the cached matches of the changed keys
+func (dm *DataManager) indexTaxProfile(tenant, id string, reqFltrs []*RequestFilter, index bool) (err error) {
+	dbKey := utils.TaxProfilesStringIndex + tenant
+	guardian.Guardian.GuardIDs(config.CgrConfig().LockingTimeout, dbKey)
+	defer guardian.Guardian.UnguardIDs(dbKey)
+	rfi, err := NewReqFilterIndexer(dm, dbKey)
+	if err != nil {
+		return
+	}
+	rfi.RemoveItemID(id)
+	if index {
+		rfi.IndexFilters(id, reqFltrs)
+	}
+	if err = rfi.StoreIndexes(); err != nil {
+		return
+	}
+	for key := range rfi.ChangedKeys() {
+		cache.RemKey(dbKey+key, true, utils.NonTransactional)
+	}
+	// profiles without *string filters are not marked as changed
+	cache.RemKey(dbKey+utils.ConcatenatedKey(utils.NOT_AVAILABLE, utils.NOT_AVAILABLE), true, utils.NonTransactional)
+	return
+}
+
+// GetAPIKey returns an APIKey out of cache or dataDB
+func (dm *DataManager) GetAPIKey(id string, skipCache bool) (apiKey *APIKey, err error) {
+	key := utils.APIKeyPrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*APIKey), nil
+		}
+	}
+	if apiKey, err = dm.dataDB.GetAPIKeyDrv(id); err != nil {
+		if err == utils.ErrNotFound {
+			cache.Set(key, nil, true, utils.NonTransactional)
+		}
+		return nil, err
+	}
+	cache.Set(key, apiKey, true, utils.NonTransactional)
+	return
+}
+
+// SetAPIKey stores an APIKey
+func (dm *DataManager) SetAPIKey(apiKey *APIKey) (err error) {
+	if err = dm.dataDB.SetAPIKeyDrv(apiKey); err != nil {
+		return
+	}
+	cache.RemKey(utils.APIKeyPrefix+apiKey.ID, true, utils.NonTransactional)
+	return
+}
+
+// RemoveAPIKey removes an APIKey
+func (dm *DataManager) RemoveAPIKey(id string) (err error) {
+	if err = dm.dataDB.RemAPIKeyDrv(id); err != nil {
+		return
+	}
+	cache.RemKey(utils.APIKeyPrefix+id, true, utils.NonTransactional)
+	return
+}
+
+// GetAPIRole returns an APIRole out of cache or dataDB
+func (dm *DataManager) GetAPIRole(id string, skipCache bool) (apiRole *APIRole, err error) {
+	key := utils.APIRolePrefix + id
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*APIRole), nil
+		}
+	}
+	if apiRole, err = dm.dataDB.GetAPIRoleDrv(id); err != nil {
+		if err == utils.ErrNotFound {
+			cache.Set(key, nil, true, utils.NonTransactional)
+		}
+		return nil, err
+	}
+	cache.Set(key, apiRole, true, utils.NonTransactional)
+	return
+}
+
+// SetAPIRole stores an APIRole
+func (dm *DataManager) SetAPIRole(apiRole *APIRole) (err error) {
+	if err = dm.dataDB.SetAPIRoleDrv(apiRole); err != nil {
+		return
+	}
+	cache.RemKey(utils.APIRolePrefix+apiRole.ID, true, utils.NonTransactional)
+	return
+}
+
+// RemoveAPIRole removes an APIRole
+func (dm *DataManager) RemoveAPIRole(id string) (err error) {
+	if err = dm.dataDB.RemAPIRoleDrv(id); err != nil {
+		return
+	}
+	cache.RemKey(utils.APIRolePrefix+id, true, utils.NonTransactional)
+	return
+}
+
+// GetStoredSession returns the session run state persisted by SMGeneric
+func (dm *DataManager) GetStoredSession(nodeID, cgrID, runID string) (ss *StoredSession, err error) {
+	return dm.dataDB.GetStoredSessionDrv(nodeID, cgrID, runID)
+}
+
+// SetStoredSession persists the session run state
+func (dm *DataManager) SetStoredSession(ss *StoredSession) (err error) {
+	return dm.dataDB.SetStoredSessionDrv(ss)
+}
+
+// RemoveStoredSession removes the persisted session run state
+func (dm *DataManager) RemoveStoredSession(nodeID, cgrID, runID string) (err error) {
+	return dm.dataDB.RemStoredSessionDrv(nodeID, cgrID, runID)
+}
+
 // GetFilter returns
 func (dm *DataManager) GetFilter(tenant, id string, skipCache bool, transactionID string) (fltr *Filter, err error) {
 	key := utils.FilterPrefix + utils.ConcatenatedKey(tenant, id)
@@ -293,6 +511,9 @@ func (dm *DataManager) GetFilter(tenant, id string, skipCache bool, transactionI
 		}
 		return nil, err
 	}
+	if err = fltr.Compile(); err != nil { // compiled values are not stored
+		return nil, err
+	}
 	cache.Set(key, fltr, cacheCommit(transactionID), transactionID)
 	return
 }
@@ -378,6 +599,79 @@ func (dm *DataManager) RemoveThresholdProfile(tenant, id, transactionID string)
 	return
 }
 
+func (dm *DataManager) GetSupplierProfile(tenant, id string, skipCache bool, transactionID string) (spp *SupplierProfile, err error) {
+	key := utils.SupplierProfilePrefix + utils.ConcatenatedKey(tenant, id)
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*SupplierProfile), nil
+		}
+	}
+	spp, err = dm.dataDB.GetSupplierProfileDrv(tenant, id)
+	if err != nil {
+		if err == utils.ErrNotFound {
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		}
+		return nil, err
+	}
+	cache.Set(key, spp, cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (dm *DataManager) SetSupplierProfile(spp *SupplierProfile) (err error) {
+	return dm.DataDB().SetSupplierProfileDrv(spp)
+}
+
+func (dm *DataManager) RemoveSupplierProfile(tenant, id, transactionID string) (err error) {
+	if err = dm.DataDB().RemSupplierProfileDrv(tenant, id); err != nil {
+		return
+	}
+	cache.RemKey(utils.SupplierProfilePrefix+utils.ConcatenatedKey(tenant, id),
+		cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (dm *DataManager) GetExchangeRate(fromCurrency, toCurrency string, skipCache bool, transactionID string) (xr *ExchangeRate, err error) {
+	key := utils.ExchangeRatePrefix + utils.ConcatenatedKey(fromCurrency, toCurrency)
+	if !skipCache {
+		if x, ok := cache.Get(key); ok {
+			if x == nil {
+				return nil, utils.ErrNotFound
+			}
+			return x.(*ExchangeRate), nil
+		}
+	}
+	xr, err = dm.dataDB.GetExchangeRateDrv(fromCurrency, toCurrency)
+	if err != nil {
+		if err == utils.ErrNotFound {
+			cache.Set(key, nil, cacheCommit(transactionID), transactionID)
+		}
+		return nil, err
+	}
+	cache.Set(key, xr, cacheCommit(transactionID), transactionID)
+	return
+}
+
+// SetExchangeRate stores the rate and drops the cached one so new debits use it right away
+func (dm *DataManager) SetExchangeRate(xr *ExchangeRate, transactionID string) (err error) {
+	if err = dm.DataDB().SetExchangeRateDrv(xr); err != nil {
+		return
+	}
+	cache.RemKey(utils.ExchangeRatePrefix+xr.ID(), cacheCommit(transactionID), transactionID)
+	return
+}
+
+func (dm *DataManager) RemoveExchangeRate(fromCurrency, toCurrency, transactionID string) (err error) {
+	if err = dm.DataDB().RemExchangeRateDrv(fromCurrency, toCurrency); err != nil {
+		return
+	}
+	cache.RemKey(utils.ExchangeRatePrefix+utils.ConcatenatedKey(fromCurrency, toCurrency),
+		cacheCommit(transactionID), transactionID)
+	return
+}
+
 func (dm *DataManager) GetStatQueueProfile(tenant, id string, skipCache bool, transactionID string) (sqp *StatQueueProfile, err error) {
 	key := utils.StatQueueProfilePrefix + utils.ConcatenatedKey(tenant, id)
 	if !skipCache {
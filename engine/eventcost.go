@@ -67,10 +67,11 @@ func NewEventCostFromCallCost(cc *CallCost, cgrID, runID string) (ec *EventCost)
 				if incr.BalanceInfo.Monetary != nil {
 					if uuid := ec.Accounting.GetIDWithSet(
 						&BalanceCharge{
-							AccountID:   incr.BalanceInfo.AccountID,
-							BalanceUUID: incr.BalanceInfo.Monetary.UUID,
-							Units:       incr.Cost,
-							RatingID:    ec.ratingIDForRateInterval(incr.BalanceInfo.Monetary.RateInterval, rf),
+							AccountID:    incr.BalanceInfo.AccountID,
+							BalanceUUID:  incr.BalanceInfo.Monetary.UUID,
+							Units:        incr.Cost,
+							RatingID:     ec.ratingIDForRateInterval(incr.BalanceInfo.Monetary.RateInterval, rf),
+							ExchangeRate: incr.BalanceInfo.Monetary.ExchangeRate,
 						}); uuid != "" {
 						ecUUID = uuid
 					}
@@ -85,10 +86,11 @@ func NewEventCostFromCallCost(cc *CallCost, cgrID, runID string) (ec *EventCost)
 			} else if incr.BalanceInfo.Monetary != nil { // Only monetary
 				cIt.AccountingID = ec.Accounting.GetIDWithSet(
 					&BalanceCharge{
-						AccountID:   incr.BalanceInfo.AccountID,
-						BalanceUUID: incr.BalanceInfo.Monetary.UUID,
-						Units:       incr.Cost,
-						RatingID:    ec.ratingIDForRateInterval(incr.BalanceInfo.Monetary.RateInterval, rf)})
+						AccountID:    incr.BalanceInfo.AccountID,
+						BalanceUUID:  incr.BalanceInfo.Monetary.UUID,
+						Units:        incr.Cost,
+						RatingID:     ec.ratingIDForRateInterval(incr.BalanceInfo.Monetary.RateInterval, rf),
+						ExchangeRate: incr.BalanceInfo.Monetary.ExchangeRate})
 			}
 			cIl.Increments[j] = cIt
 		}
@@ -142,6 +144,7 @@ func (ec *EventCost) ratingIDForRateInterval(ri *RateInterval, rf RatingMatchedF
 			RoundingDecimals: ri.Rating.RoundingDecimals,
 			MaxCost:          ri.Rating.MaxCost,
 			MaxCostStrategy:  ri.Rating.MaxCostStrategy,
+			Currency:         ri.Rating.Currency,
 			TimingID:         tmID,
 			RatesID:          rtUUID,
 			RatingFiltersID:  rfUUID})
@@ -156,7 +159,8 @@ func (ec *EventCost) rateIntervalForRatingID(ratingID string) (ri *RateInterval)
 	ri.Rating = &RIRate{ConnectFee: cIlRU.ConnectFee,
 		RoundingMethod:   cIlRU.RoundingMethod,
 		RoundingDecimals: cIlRU.RoundingDecimals,
-		MaxCost:          cIlRU.MaxCost, MaxCostStrategy: cIlRU.MaxCostStrategy}
+		MaxCost:          cIlRU.MaxCost, MaxCostStrategy: cIlRU.MaxCostStrategy,
+		Currency: cIlRU.Currency}
 	if cIlRU.RatesID != "" {
 		ri.Rating.Rates = ec.Rates[cIlRU.RatesID]
 	}
@@ -217,12 +221,11 @@ func (ec *EventCost) ResetCounters() {
 // ComputeCost iterates through Charges, computing EventCost.Cost
 func (ec *EventCost) GetCost() float64 {
 	if ec.Cost == nil {
-		var cost float64
+		cost := utils.NewDecimalFromInt64(0)
 		for _, ci := range ec.Charges {
-			cost += ci.TotalCost()
+			cost = cost.Add(ci.totalCostDecimal())
 		}
-		cost = utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
-		ec.Cost = &cost
+		ec.Cost = utils.Float64Pointer(cost.Round(globalRoundingDecimals, utils.ROUNDING_MIDDLE).Float64())
 	}
 	return *ec.Cost
 }
@@ -307,7 +310,8 @@ func (ec *EventCost) AsCallCost() *CallCost {
 					}
 				}
 				if cBC.ExtraChargeID != utils.META_NONE {
-					incr.BalanceInfo.Monetary = &MonetaryInfo{UUID: cBC.BalanceUUID}
+					incr.BalanceInfo.Monetary = &MonetaryInfo{UUID: cBC.BalanceUUID,
+						ExchangeRate: cBC.ExchangeRate}
 					incr.BalanceInfo.Monetary.RateInterval = ec.rateIntervalForRatingID(cBC.RatingID)
 				}
 			}
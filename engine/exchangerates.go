@@ -0,0 +1,110 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// ExchangeRate converts amounts expressed in one currency into another
+type ExchangeRate struct {
+	FromCurrency string
+	ToCurrency   string
+	Rate         float64 // units of ToCurrency for one unit of FromCurrency
+}
+
+// ID identifies the exchange rate within DataDB
+func (xr *ExchangeRate) ID() string {
+	return utils.ConcatenatedKey(xr.FromCurrency, xr.ToCurrency)
+}
+
+// getExchangeRate returns the rate converting amounts from fromCurrency into toCurrency.
+// Empty currencies stand for the default one and are never converted.
+// When only the reverse rate is defined its inverse is used.
+func getExchangeRate(fromCurrency, toCurrency string) (rate float64, err error) {
+	if fromCurrency == "" || toCurrency == "" || fromCurrency == toCurrency {
+		return 1, nil
+	}
+	var xr *ExchangeRate
+	if xr, err = dm.GetExchangeRate(fromCurrency, toCurrency, false, utils.NonTransactional); err == nil {
+		if xr.Rate <= 0 { // invalid rate, would zero the costs
+			return 0, utils.ErrNotFound
+		}
+		return xr.Rate, nil
+	} else if err != utils.ErrNotFound {
+		return
+	}
+	if xr, err = dm.GetExchangeRate(toCurrency, fromCurrency, false, utils.NonTransactional); err != nil {
+		return
+	}
+	if xr.Rate <= 0 {
+		return 0, utils.ErrNotFound
+	}
+	return 1 / xr.Rate, nil
+}
+
+// exchangeRate returns the rate converting costs in rateCurrency into the balance currency
+func (b *Balance) exchangeRate(rateCurrency string) (float64, error) {
+	return getExchangeRate(rateCurrency, b.Currency)
+}
+
+// rateCurrency returns the currency the costs of the RateInterval are expressed in
+func (ri *RateInterval) rateCurrency() string {
+	if ri == nil || ri.Rating == nil {
+		return ""
+	}
+	return ri.Rating.Currency
+}
+
+// convertCost converts cost using the exchange rate, avoiding float errors
+func convertCost(cost, xr float64) float64 {
+	if xr == 1 {
+		return cost
+	}
+	return utils.NewDecimalFromFloat64(cost).Mul(utils.NewDecimalFromFloat64(xr)).Float64()
+}
+
+// recordedExchangeRate is the rate stored within the debit information, 0 when no conversion took place
+func recordedExchangeRate(xr float64) float64 {
+	if xr == 1 {
+		return 0
+	}
+	return xr
+}
+
+// connectFeeInfo returns the MonetaryInfo of the balance which paid the connect fee
+func connectFeeInfo(b *Balance, rateCurrency string) (mi *MonetaryInfo) {
+	mi = &MonetaryInfo{UUID: b.Uuid, ID: b.ID, Value: b.Value}
+	if xr, err := b.exchangeRate(rateCurrency); err == nil {
+		mi.ExchangeRate = recordedExchangeRate(xr)
+	}
+	return
+}
+
+// defaultExchangeRate returns the rate used when going negative on the default balance,
+// failing on missing rate since the unconverted cost would be debited in the wrong currency
+func (b *Balance) defaultExchangeRate(rateCurrency string) (xr float64, err error) {
+	if xr, err = b.exchangeRate(rateCurrency); err != nil {
+		return 0, fmt.Errorf("no exchange rate from <%s> into <%s> for the default balance, err: %s",
+			rateCurrency, b.Currency, err.Error())
+	}
+	return
+}
@@ -0,0 +1,157 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestGetExchangeRate(t *testing.T) {
+	if err := dm.SetExchangeRate(&ExchangeRate{FromCurrency: "CHF", ToCurrency: "SEK", Rate: 10},
+		utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		from, to string
+		rate     float64
+	}{
+		{"CHF", "SEK", 10},
+		{"SEK", "CHF", 0.1}, // reverse rate
+		{"CHF", "CHF", 1},
+		{"", "SEK", 1}, // default currency is never converted
+		{"CHF", "", 1},
+	} {
+		if rate, err := getExchangeRate(tc.from, tc.to); err != nil {
+			t.Error(err)
+		} else if rate != tc.rate {
+			t.Errorf("From %s to %s, expecting: %v, received: %v", tc.from, tc.to, tc.rate, rate)
+		}
+	}
+	if _, err := getExchangeRate("CHF", "JPY"); err != utils.ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+	// zero rates would debit nothing
+	if err := dm.SetExchangeRate(&ExchangeRate{FromCurrency: "CHF", ToCurrency: "NOK", Rate: 0},
+		utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getExchangeRate("CHF", "NOK"); err != utils.ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+	if _, err := getExchangeRate("NOK", "CHF"); err != utils.ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+}
+
+func TestDefaultExchangeRate(t *testing.T) {
+	b := &Balance{ID: utils.META_DEFAULT, Currency: "SEK"}
+	if err := dm.SetExchangeRate(&ExchangeRate{FromCurrency: "CHF", ToCurrency: "SEK", Rate: 10},
+		utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	if xr, err := b.defaultExchangeRate("CHF"); err != nil {
+		t.Error(err)
+	} else if xr != 10 {
+		t.Errorf("Expecting: 10, received: %v", xr)
+	}
+	if _, err := b.defaultExchangeRate("JPY"); err == nil { // debiting unconverted would land in the wrong currency
+		t.Error("Expecting error for missing exchange rate")
+	}
+}
+
+func TestDebitCreditMoneyExchangeRate(t *testing.T) {
+	if err := dm.SetExchangeRate(&ExchangeRate{FromCurrency: "CHF", ToCurrency: "SEK", Rate: 10},
+		utils.NonTransactional); err != nil {
+		t.Fatal(err)
+	}
+	cc := &CallCost{
+		Direction:   utils.OUT,
+		Destination: "0723045326",
+		Timespans: []*TimeSpan{
+			&TimeSpan{
+				TimeStart:     time.Date(2013, 9, 24, 10, 48, 0, 0, time.UTC),
+				TimeEnd:       time.Date(2013, 9, 24, 10, 48, 10, 0, time.UTC),
+				DurationIndex: 0,
+				RateInterval: &RateInterval{Rating: &RIRate{Currency: "CHF",
+					Rates: RateGroups{&Rate{GroupIntervalStart: 0, Value: 1, RateIncrement: 10 * time.Second, RateUnit: time.Second}}}},
+			},
+		},
+		TOR: utils.VOICE,
+	}
+	cd := &CallDescriptor{
+		TimeStart:     cc.Timespans[0].TimeStart,
+		TimeEnd:       cc.Timespans[0].TimeEnd,
+		Direction:     cc.Direction,
+		Destination:   cc.Destination,
+		TOR:           cc.TOR,
+		DurationIndex: cc.GetDuration(),
+		testCallcost:  cc,
+	}
+	acnt := &Account{ID: "cgrates.org:xrt", BalanceMap: map[string]Balances{
+		utils.MONETARY: Balances{
+			&Balance{Uuid: "jpy", Value: 1000, Currency: "JPY", Weight: 20}, // no exchange rate defined
+			&Balance{Uuid: "sek", Value: 150, Currency: "SEK", Weight: 10},
+		},
+	}}
+	var err error
+	if cc, err = acnt.debitCreditBalance(cd, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if acnt.BalanceMap[utils.MONETARY][0].GetValue() != 1000 {
+		t.Errorf("Balance without exchange rate debited: %+v", acnt.BalanceMap[utils.MONETARY][0])
+	}
+	if acnt.BalanceMap[utils.MONETARY][1].GetValue() != 50 {
+		t.Errorf("Expecting 100 SEK debited, balance: %+v", acnt.BalanceMap[utils.MONETARY][1])
+	}
+	if cc.Cost != 10 { // cost stays in rate currency
+		t.Errorf("Unexpected cost: %v", cc.Cost)
+	}
+	mi := cc.Timespans[0].Increments[0].BalanceInfo.Monetary
+	if mi.UUID != "sek" || mi.ExchangeRate != 10 {
+		t.Errorf("Unexpected MonetaryInfo: %s", utils.ToJSON(mi))
+	}
+	ec := NewEventCostFromCallCost(cc, "xrtcgrid", utils.META_DEFAULT)
+	var found bool
+	for _, bc := range ec.Accounting {
+		if bc.BalanceUUID == "sek" && bc.ExchangeRate == 10 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ExchangeRate not recorded in EventCost: %s", utils.ToJSON(ec.Accounting))
+	}
+	if ecCC := ec.AsCallCost(); ecCC.Timespans[0].Increments[0].BalanceInfo.Monetary.ExchangeRate != 10 {
+		t.Errorf("ExchangeRate lost converting back to CallCost: %s", utils.ToJSON(ecCC))
+	}
+	// refunds are converted with the recorded rate
+	if err := dm.DataDB().SetAccount(acnt); err != nil {
+		t.Fatal(err)
+	}
+	rfndCD := &CallDescriptor{TOR: utils.VOICE, Increments: cc.Timespans[0].Increments}
+	if err := rfndCD.RefundIncrements(); err != nil {
+		t.Error(err)
+	}
+	if acnt, err = dm.DataDB().GetAccount(acnt.ID); err != nil {
+		t.Fatal(err)
+	} else if acnt.BalanceMap[utils.MONETARY][1].GetValue() != 150 {
+		t.Errorf("Unexpected balance after refund: %+v", acnt.BalanceMap[utils.MONETARY][1])
+	}
+}
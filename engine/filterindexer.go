@@ -111,6 +111,20 @@ func (rfi *ReqFilterIndexer) IndexTPFilter(tpFltr *utils.TPFilter, itemID string
 	return
 }
 
+// RemoveItemID removes itemID out of the indexes and marks the changed keys in chngdIndxKeys,
+// emptied values are kept so the stores updating per key overwrite them
+func (rfi *ReqFilterIndexer) RemoveItemID(itemID string) {
+	for fldName, fldValMp := range rfi.indexes {
+		for fldVal, itemIDs := range fldValMp {
+			if _, hasIt := itemIDs[itemID]; !hasIt {
+				continue
+			}
+			delete(itemIDs, itemID)
+			rfi.chngdIndxKeys[utils.ConcatenatedKey(fldName, fldVal)] = true
+		}
+	}
+}
+
 // StoreIndexes handles storing the indexes to dataDB
 func (rfi *ReqFilterIndexer) StoreIndexes() error {
 	return rfi.dm.SetReqFilterIndexes(rfi.dbKey, rfi.indexes)
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -42,6 +43,25 @@ const (
 	MetaLessOrEqual    = "*lte"
 	MetaGreaterThan    = "*gt"
 	MetaGreaterOrEqual = "*gte"
+	MetaRegex          = "*regex"
+	MetaExists         = "*exists"
+	MetaEmpty          = "*empty"
+	MetaNot            = "*not" // negation prefix, ie: *notstring
+)
+
+var (
+	// supportedFilterTypes lists the filter types which can be negated with MetaNot prefix
+	supportedFilterTypes = []string{MetaString, MetaStringPrefix, MetaTimings, MetaRSRFields, MetaStatS,
+		MetaDestinations, MetaLessThan, MetaLessOrEqual, MetaGreaterThan, MetaGreaterOrEqual,
+		MetaRegex, MetaExists, MetaEmpty}
+	// needsFieldName lists the filter types which cannot be used without FieldName
+	needsFieldName = []string{MetaString, MetaStringPrefix, MetaTimings, MetaDestinations,
+		MetaLessThan, MetaLessOrEqual, MetaGreaterThan, MetaGreaterOrEqual,
+		MetaRegex, MetaExists, MetaEmpty}
+	// needsValues lists the filter types which cannot be used without Values
+	needsValues = []string{MetaString, MetaStringPrefix, MetaTimings, MetaRSRFields, MetaStatS,
+		MetaDestinations, MetaLessThan, MetaLessOrEqual, MetaGreaterThan, MetaGreaterOrEqual,
+		MetaRegex}
 )
 
 func NewFilterS(cfg *config.CGRConfig, statSChan chan rpcclient.RpcClientConnection, dm *DataManager) *FilterS {
@@ -53,7 +73,7 @@ func NewFilterS(cfg *config.CGRConfig, statSChan chan rpcclient.RpcClientConnect
 type FilterS struct {
 	cfg        *config.CGRConfig
 	statSChan  chan rpcclient.RpcClientConnection // reference towards internal statS connection, used for lazy connect
-	statSConns *rpcclient.RpcClientPool
+	statSConns *RPCPool
 	sSConnMux  sync.RWMutex // make sure only one goroutine attempts connecting
 	dm         *DataManager
 }
@@ -65,8 +85,9 @@ func (fS *FilterS) connStatS() (err error) {
 	if fS.statSConns != nil { // connection was populated between locks
 		return
 	}
-	fS.statSConns, err = NewRPCPool(rpcclient.POOL_FIRST, fS.cfg.ConnectAttempts, fS.cfg.Reconnects, fS.cfg.ConnectTimeout, fS.cfg.ReplyTimeout,
-		fS.cfg.FilterSCfg().StatSConns, fS.statSChan, fS.cfg.InternalTtl)
+	connAttempts, reconnects, connectTimeout, replyTimeout, internalTTL := fS.cfg.RPCConnCfg()
+	fS.statSConns, err = NewRPCPool(rpcclient.POOL_FIRST, connAttempts, reconnects, connectTimeout, replyTimeout,
+		fS.cfg.FilterSCfg().StatSConns, fS.statSChan, internalTTL)
 	return
 }
 
@@ -84,27 +105,15 @@ func (fS *FilterS) PassFiltersForEvent(tenant string, ev map[string]interface{},
 			continue
 		}
 		for _, fltr := range f.RequestFilters {
-			switch fltr.Type {
-			case MetaString:
-				pass, err = fltr.passString(ev, "")
-			case MetaStringPrefix:
-				pass, err = fltr.passStringPrefix(ev, "")
-			case MetaTimings:
-				pass, err = fltr.passTimings(ev, "")
-			case MetaDestinations:
-				pass, err = fltr.passDestinations(ev, "")
-			case MetaRSRFields:
-				pass, err = fltr.passRSRFields(ev, "")
-			case MetaStatS:
+			if !utils.IsSliceMember(supportedFilterTypes, fltr.baseType()) {
+				return false, fmt.Errorf("tenant: %s filter: %s unsupported filter type: <%s>", tenant, fltrID, fltr.Type)
+			}
+			if fltr.baseType() == MetaStatS {
 				if err = fS.connStatS(); err != nil {
 					return false, err
 				}
-				pass, err = fltr.passStatS(ev, "", fS.statSConns)
-			case MetaLessThan, MetaLessOrEqual, MetaGreaterThan, MetaGreaterOrEqual:
-				pass, err = fltr.passGreaterThan(ev, "")
-			default:
-				return false, fmt.Errorf("tenant: %s filter: %s unsupported filter type: <%s>", tenant, fltrID, fltr.Type)
 			}
+			pass, err = fltr.Pass(ev, "", fS.statSConns)
 			if !pass || err != nil {
 				return pass, err
 			}
@@ -136,19 +145,16 @@ func (f *Filter) Compile() (err error) {
 }
 
 func NewRequestFilter(rfType, fieldName string, vals []string) (*RequestFilter, error) {
-	if !utils.IsSliceMember([]string{MetaStringPrefix, MetaTimings, MetaRSRFields, MetaStatS, MetaDestinations,
-		MetaLessThan, MetaLessOrEqual, MetaGreaterThan, MetaGreaterOrEqual}, rfType) {
+	rf := &RequestFilter{Type: rfType, FieldName: fieldName, Values: vals}
+	if !utils.IsSliceMember(supportedFilterTypes, rf.baseType()) {
 		return nil, fmt.Errorf("Unsupported filter Type: %s", rfType)
 	}
-	if fieldName == "" && utils.IsSliceMember([]string{MetaStringPrefix, MetaTimings, MetaDestinations,
-		MetaLessThan, MetaLessOrEqual, MetaGreaterThan, MetaGreaterOrEqual}, rfType) {
+	if fieldName == "" && utils.IsSliceMember(needsFieldName, rf.baseType()) {
 		return nil, fmt.Errorf("FieldName is mandatory for Type: %s", rfType)
 	}
-	if len(vals) == 0 && utils.IsSliceMember([]string{MetaStringPrefix, MetaTimings, MetaRSRFields,
-		MetaDestinations, MetaDestinations, MetaLessThan, MetaLessOrEqual, MetaGreaterThan, MetaGreaterOrEqual}, rfType) {
+	if len(vals) == 0 && utils.IsSliceMember(needsValues, rf.baseType()) {
 		return nil, fmt.Errorf("Values is mandatory for Type: %s", rfType)
 	}
-	rf := &RequestFilter{Type: rfType, FieldName: fieldName, Values: vals}
 	if err := rf.CompileValues(); err != nil {
 		return nil, err
 	}
@@ -164,20 +170,42 @@ type RFStatSThreshold struct {
 // RequestFilter filters requests coming into various places
 // Pass rule: default negative, one mathing rule should pass the filter
 type RequestFilter struct {
-	Type            string              // Filter type (*string, *timing, *rsr_filters, *stats, *lt, *lte, *gt, *gte)
+	Type            string              // Filter type (*string, *timing, *rsr_filters, *stats, *lt, *lte, *gt, *gte, *regex, *exists, *empty), negated with *not prefix
 	FieldName       string              // Name of the field providing us the Values to check (used in case of some )
 	Values          []string            // Filter definition
 	rsrFields       utils.RSRFields     // Cache here the RSRFilter Values
 	statSThresholds []*RFStatSThreshold // Cached compiled RFStatsThreshold out of Values
+	regexps         []*regexp.Regexp    // Cached compiled regexps out of Values
+}
+
+// baseType returns the filter type stripped of negation prefix, ie: *string out of *notstring
+func (rf *RequestFilter) baseType() string {
+	if strings.HasPrefix(rf.Type, MetaNot) {
+		return utils.MetaPrefix + rf.Type[len(MetaNot):]
+	}
+	return rf.Type
+}
+
+// negative returns true if the filter result should be negated
+func (rf *RequestFilter) negative() bool {
+	return strings.HasPrefix(rf.Type, MetaNot)
 }
 
 // Separate method to compile RSR fields
 func (rf *RequestFilter) CompileValues() (err error) {
-	if rf.Type == MetaRSRFields {
+	switch rf.baseType() {
+	case MetaRegex:
+		rf.regexps = make([]*regexp.Regexp, len(rf.Values))
+		for i, val := range rf.Values {
+			if rf.regexps[i], err = regexp.Compile(val); err != nil {
+				return
+			}
+		}
+	case MetaRSRFields:
 		if rf.rsrFields, err = utils.ParseRSRFieldsFromSlice(rf.Values); err != nil {
 			return
 		}
-	} else if rf.Type == MetaStatS {
+	case MetaStatS:
 		rf.statSThresholds = make([]*RFStatSThreshold, len(rf.Values))
 		for i, val := range rf.Values {
 			valSplt := strings.Split(val, utils.InInFieldSep)
@@ -202,25 +230,35 @@ func (rf *RequestFilter) CompileValues() (err error) {
 }
 
 // Pass is the method which should be used from outside.
-func (fltr *RequestFilter) Pass(req interface{}, extraFieldsLabel string, rpcClnt rpcclient.RpcClientConnection) (bool, error) {
-	switch fltr.Type {
+func (fltr *RequestFilter) Pass(req interface{}, extraFieldsLabel string, rpcClnt rpcclient.RpcClientConnection) (pass bool, err error) {
+	switch fltr.baseType() {
 	case MetaString:
-		return fltr.passString(req, extraFieldsLabel)
+		pass, err = fltr.passString(req, extraFieldsLabel)
 	case MetaStringPrefix:
-		return fltr.passStringPrefix(req, extraFieldsLabel)
+		pass, err = fltr.passStringPrefix(req, extraFieldsLabel)
 	case MetaTimings:
-		return fltr.passTimings(req, extraFieldsLabel)
+		pass, err = fltr.passTimings(req, extraFieldsLabel)
 	case MetaDestinations:
-		return fltr.passDestinations(req, extraFieldsLabel)
+		pass, err = fltr.passDestinations(req, extraFieldsLabel)
 	case MetaRSRFields:
-		return fltr.passRSRFields(req, extraFieldsLabel)
+		pass, err = fltr.passRSRFields(req, extraFieldsLabel)
 	case MetaStatS:
-		return fltr.passStatS(req, extraFieldsLabel, rpcClnt)
+		pass, err = fltr.passStatS(req, extraFieldsLabel, rpcClnt)
 	case MetaLessThan, MetaLessOrEqual, MetaGreaterThan, MetaGreaterOrEqual:
-		return fltr.passGreaterThan(req, extraFieldsLabel)
+		pass, err = fltr.passGreaterThan(req, extraFieldsLabel)
+	case MetaRegex:
+		pass, err = fltr.passRegex(req, extraFieldsLabel)
+	case MetaExists:
+		pass, err = fltr.passExists(req, extraFieldsLabel)
+	case MetaEmpty:
+		pass, err = fltr.passEmpty(req, extraFieldsLabel)
 	default:
-		return false, utils.ErrNotImplemented
+		err = utils.ErrNotImplemented
+	}
+	if err != nil {
+		return false, err
 	}
+	return pass != fltr.negative(), nil
 }
 
 func (fltr *RequestFilter) passString(req interface{}, extraFieldsLabel string) (bool, error) {
@@ -320,6 +358,55 @@ func (fltr *RequestFilter) passStatS(req interface{}, extraFieldsLabel string, s
 	return false, nil
 }
 
+// passRegex passes if the field value matches one of the regexps in Values
+func (fltr *RequestFilter) passRegex(req interface{}, extraFieldsLabel string) (bool, error) {
+	strVal, err := utils.ReflectFieldAsString(req, fltr.FieldName, extraFieldsLabel)
+	if err != nil {
+		if err == utils.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, rgx := range fltr.regexps {
+		if rgx.MatchString(strVal) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// passExists passes if the field is present in request
+func (fltr *RequestFilter) passExists(req interface{}, extraFieldsLabel string) (bool, error) {
+	if _, err := utils.ReflectFieldInterface(req, fltr.FieldName, extraFieldsLabel); err != nil {
+		if err == utils.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// passEmpty passes if the field is missing from request or has no value
+func (fltr *RequestFilter) passEmpty(req interface{}, extraFieldsLabel string) (bool, error) {
+	fldIf, err := utils.ReflectFieldInterface(req, fltr.FieldName, extraFieldsLabel)
+	if err != nil {
+		if err == utils.ErrNotFound {
+			return true, nil
+		}
+		return false, err
+	}
+	if fldIf == nil {
+		return true, nil
+	}
+	switch fldVal := reflect.ValueOf(fldIf); fldVal.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return fldVal.Len() == 0, nil
+	case reflect.Ptr, reflect.Interface:
+		return fldVal.IsNil(), nil
+	}
+	return false, nil
+}
+
 func (fltr *RequestFilter) passGreaterThan(req interface{}, extraFieldsLabel string) (bool, error) {
 	fldIf, err := utils.ReflectFieldInterface(req, fltr.FieldName, extraFieldsLabel)
 	if err != nil {
@@ -331,17 +418,18 @@ func (fltr *RequestFilter) passGreaterThan(req interface{}, extraFieldsLabel str
 	if fldStr, castStr := fldIf.(string); castStr { // attempt converting string since deserialization fails here (ie: time.Time fields)
 		fldIf = utils.StringToInterface(fldStr)
 	}
+	fltrType := fltr.baseType() // negation applied by Pass
 	for _, val := range fltr.Values {
 		orEqual := false
-		if fltr.Type == MetaGreaterOrEqual ||
-			fltr.Type == MetaLessThan {
+		if fltrType == MetaGreaterOrEqual ||
+			fltrType == MetaLessThan {
 			orEqual = true
 		}
 		if gte, err := utils.GreaterThan(fldIf, utils.StringToInterface(val), orEqual); err != nil {
 			return false, err
-		} else if utils.IsSliceMember([]string{MetaGreaterThan, MetaGreaterOrEqual}, fltr.Type) && gte {
+		} else if utils.IsSliceMember([]string{MetaGreaterThan, MetaGreaterOrEqual}, fltrType) && gte {
 			return true, nil
-		} else if !gte && utils.IsSliceMember([]string{MetaLessThan, MetaLessOrEqual}, fltr.Type) && !gte {
+		} else if !gte && utils.IsSliceMember([]string{MetaLessThan, MetaLessOrEqual}, fltrType) && !gte {
 			return true, nil
 		}
 	}
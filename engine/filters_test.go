@@ -200,3 +200,210 @@ func TestReqFilterPassGreaterThan(t *testing.T) {
 		t.Error("not pass")
 	}
 }
+
+func TestNewRequestFilterValidation(t *testing.T) {
+	if _, err := NewRequestFilter("*unsupported", "Account", []string{"1001"}); err == nil {
+		t.Error("expecting error for unsupported type")
+	}
+	if _, err := NewRequestFilter("*notunsupported", "Account", []string{"1001"}); err == nil {
+		t.Error("expecting error for unsupported type")
+	}
+	if _, err := NewRequestFilter(MetaRegex, "", []string{"^10"}); err == nil {
+		t.Error("expecting error for missing FieldName")
+	}
+	if _, err := NewRequestFilter(MetaRegex, "Account", nil); err == nil {
+		t.Error("expecting error for missing Values")
+	}
+	if _, err := NewRequestFilter(MetaRegex, "Account", []string{"(10"}); err == nil {
+		t.Error("expecting error for invalid regexp")
+	}
+	if _, err := NewRequestFilter(MetaExists, "Account", nil); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewRequestFilter(MetaNot+"string", "Account", []string{"1001"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReqFilterPassRegex(t *testing.T) {
+	cd := &CallDescriptor{Direction: "*out", Category: "call", Tenant: "cgrates.org", Subject: "dan", Destination: "+4986517174963",
+		TimeStart: time.Date(2013, time.October, 7, 14, 50, 0, 0, time.UTC), TimeEnd: time.Date(2013, time.October, 7, 14, 52, 12, 0, time.UTC),
+		DurationIndex: 132 * time.Second, ExtraFields: map[string]string{"navigation": "off"}}
+	rf, err := NewRequestFilter(MetaRegex, "Destination", []string{`^\+49\d+$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes, err := rf.Pass(cd, "", nil); err != nil {
+		t.Error(err)
+	} else if !passes {
+		t.Error("Not passing")
+	}
+	rf, err = NewRequestFilter(MetaRegex, "navigation", []string{"^on$", "^of+$"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes, err := rf.Pass(cd, "ExtraFields", nil); err != nil {
+		t.Error(err)
+	} else if !passes {
+		t.Error("Not passing")
+	}
+	rf, err = NewRequestFilter(MetaRegex, "Subject", []string{"^10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes, err := rf.Pass(cd, "", nil); err != nil {
+		t.Error(err)
+	} else if passes {
+		t.Error("Passing")
+	}
+	rf, err = NewRequestFilter(MetaRegex, "NonExisting", []string{".*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes, err := rf.Pass(cd, "ExtraFields", nil); err != nil {
+		t.Error(err)
+	} else if passes {
+		t.Error("Passing")
+	}
+}
+
+func TestReqFilterPassExistsEmpty(t *testing.T) {
+	ev := map[string]interface{}{
+		"Account":    "1001",
+		"Subject":    "",
+		"Supplier":   nil,
+		"Categories": []string{},
+	}
+	rf, _ := NewRequestFilter(MetaExists, "Account", nil)
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if !passes {
+		t.Error("Not passing")
+	}
+	rf, _ = NewRequestFilter(MetaExists, "Destination", nil)
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if passes {
+		t.Error("Passing")
+	}
+	for _, fldName := range []string{"Subject", "Supplier", "Categories", "Destination"} {
+		rf, _ = NewRequestFilter(MetaEmpty, fldName, nil)
+		if passes, err := rf.Pass(ev, "", nil); err != nil {
+			t.Error(err)
+		} else if !passes {
+			t.Errorf("Not passing for field: %s", fldName)
+		}
+	}
+	rf, _ = NewRequestFilter(MetaEmpty, "Account", nil)
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if passes {
+		t.Error("Passing")
+	}
+}
+
+func TestReqFilterPassNot(t *testing.T) {
+	cache.Set(utils.REVERSE_DESTINATION_PREFIX+"+49", []string{"DE", "EU_LANDLINE"}, true, "")
+	ev := map[string]interface{}{
+		"Account":     "1001",
+		"Destination": "+4986517174963",
+	}
+	rf, _ := NewRequestFilter(MetaNot+"string", "Account", []string{"1001", "1002"})
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if passes {
+		t.Error("Passing")
+	}
+	rf, _ = NewRequestFilter(MetaNot+"string_prefix", "Destination", []string{"+40"})
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if !passes {
+		t.Error("Not passing")
+	}
+	rf, _ = NewRequestFilter(MetaNot+"destinations", "Destination", []string{"DE"})
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if passes {
+		t.Error("Passing")
+	}
+	rf, _ = NewRequestFilter(MetaNot+"destinations", "Destination", []string{"RO"})
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if !passes {
+		t.Error("Not passing")
+	}
+	rf, _ = NewRequestFilter(MetaNot+"exists", "Subject", nil)
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if !passes {
+		t.Error("Not passing")
+	}
+	rf, _ = NewRequestFilter(MetaNot+"empty", "Account", nil)
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if !passes {
+		t.Error("Not passing")
+	}
+	rf, _ = NewRequestFilter(MetaNot+"regex", "Account", []string{"^100"})
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if passes {
+		t.Error("Passing")
+	}
+}
+
+func TestReqFilterPassNotGreaterThan(t *testing.T) {
+	ev := map[string]interface{}{
+		"ASR": 40,
+	}
+	for fltrType, ePass := range map[string]bool{
+		MetaNot + "gt":  true,  // 40 > 40 fails
+		MetaNot + "gte": false, // 40 >= 40 passes
+		MetaNot + "lt":  true,  // 40 < 40 fails
+		MetaNot + "lte": false, // 40 <= 40 passes
+	} {
+		rf, err := NewRequestFilter(fltrType, "ASR", []string{"40"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if passes, err := rf.Pass(ev, "", nil); err != nil {
+			t.Error(err)
+		} else if passes != ePass {
+			t.Errorf("%s expecting: %v, received: %v", fltrType, ePass, passes)
+		}
+	}
+	ev["ASR"] = 20
+	rf, _ := NewRequestFilter(MetaNot+"lt", "ASR", []string{"40"})
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if passes {
+		t.Error("Passing")
+	}
+	rf, _ = NewRequestFilter(MetaNot+"gte", "ASR", []string{"40"})
+	if passes, err := rf.Pass(ev, "", nil); err != nil {
+		t.Error(err)
+	} else if !passes {
+		t.Error("Not passing")
+	}
+}
+
+func TestReqFilterIndexerNegativeFilters(t *testing.T) {
+	rfi := &ReqFilterIndexer{indexes: make(map[string]map[string]utils.StringMap),
+		chngdIndxKeys: make(utils.StringMap)}
+	notStr, _ := NewRequestFilter(MetaNot+"string", "Account", []string{"1001"})
+	rfi.IndexFilters("ITEM1", []*RequestFilter{notStr})
+	if _, has := rfi.indexes["Account"]; has {
+		t.Errorf("negative filter indexed: %+v", rfi.indexes)
+	}
+	if !rfi.indexes[utils.NOT_AVAILABLE][utils.NOT_AVAILABLE]["ITEM1"] {
+		t.Errorf("item not indexed globally: %+v", rfi.indexes)
+	}
+	str, _ := NewRequestFilter(MetaString, "Tenant", []string{"cgrates.org"})
+	rfi.IndexFilters("ITEM2", []*RequestFilter{notStr, str})
+	if !rfi.indexes["Tenant"]["cgrates.org"]["ITEM2"] {
+		t.Errorf("item not indexed: %+v", rfi.indexes)
+	}
+	if rfi.indexes[utils.NOT_AVAILABLE][utils.NOT_AVAILABLE]["ITEM2"] {
+		t.Errorf("item indexed globally: %+v", rfi.indexes)
+	}
+}
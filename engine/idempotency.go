@@ -0,0 +1,117 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// IdempotencyRecord keeps the reply of a request carrying an IdempotencyKey so retries do not charge twice
+type IdempotencyRecord struct {
+	ID         string // tenant:account:method:key
+	ArgsHash   string // of the original request, retries need to match it
+	Pending    bool   // the original request is still processing
+	Reply      string // JSON encoded reply of the original request
+	ExpiryTime time.Time
+}
+
+// IsExpired checks the record against its retention window
+func (ir *IdempotencyRecord) IsExpired() bool {
+	return !ir.ExpiryTime.IsZero() && time.Now().After(ir.ExpiryTime)
+}
+
+// IdempotencyRecordID scopes the IdempotencyKey to the account charged and the method called
+func IdempotencyRecordID(tenant, account, method, key string) string {
+	return utils.ConcatenatedKey(tenant, account, method, key)
+}
+
+// idempotencyArgsHash fingerprints the request so a key reused with other arguments is detected
+func idempotencyArgsHash(args interface{}) (string, error) {
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	return utils.Sha1(string(argsBytes)), nil
+}
+
+// ProcessIdempotent executes f only once per account, method and key, replaying the stored reply for the retries.
+// The record is marked pending before executing f so concurrent retries are refused instead of charging again,
+// independent of how long f takes. Failed requests are not recorded since they did not charge,
+// the caller can retry them with the same key.
+func (dm *DataManager) ProcessIdempotent(tenant, account, method, key string,
+	args, reply interface{}, f func() error) (err error) {
+	ttl := config.CgrConfig().IdempotencyTTL
+	if key == "" || ttl <= 0 {
+		return f()
+	}
+	recID := IdempotencyRecordID(tenant, account, method, key)
+	var argsHash string
+	if argsHash, err = idempotencyArgsHash(args); err != nil {
+		return
+	}
+	var replay bool
+	if _, err = guardian.Guardian.Guard(func() (interface{}, error) {
+		rec, err := dm.GetIdempotencyRecord(recID)
+		if err != nil && err != utils.ErrNotFound {
+			return nil, err
+		}
+		if err == nil && !rec.IsExpired() {
+			if rec.ArgsHash != argsHash {
+				return nil, utils.ErrIdempotencyKeyReused
+			}
+			if rec.Pending {
+				return nil, utils.ErrIdempotencyKeyPending
+			}
+			replay = true
+			return nil, json.Unmarshal([]byte(rec.Reply), reply)
+		}
+		return nil, dm.SetIdempotencyRecord(&IdempotencyRecord{ID: recID, ArgsHash: argsHash,
+			Pending: true, ExpiryTime: time.Now().Add(ttl)})
+	}, config.CgrConfig().LockingTimeout, utils.IdempotencyRecordPrefix+recID); err != nil || replay {
+		return
+	}
+	if err = f(); err != nil {
+		if errRem := dm.RemIdempotencyRecord(recID); errRem != nil {
+			utils.Logger.Warning(fmt.Sprintf("<Idempotency> cannot remove record <%s>, error: %s", recID, errRem.Error()))
+		}
+		return
+	}
+	// a record left pending refuses the retries until expiry, safer than charging twice
+	if rplBytes, errRpl := json.Marshal(reply); errRpl != nil {
+		utils.Logger.Warning(fmt.Sprintf("<Idempotency> cannot encode reply for <%s>, error: %s", recID, errRpl.Error()))
+	} else if errRpl := dm.SetIdempotencyRecord(&IdempotencyRecord{ID: recID, ArgsHash: argsHash,
+		Reply: string(rplBytes), ExpiryTime: time.Now().Add(ttl)}); errRpl != nil {
+		utils.Logger.Warning(fmt.Sprintf("<Idempotency> cannot store record <%s>, error: %s", recID, errRpl.Error()))
+	}
+	return
+}
+
+// ProcessIdempotent uses the DataManager of the engine, executing f directly when there is no DataDB connected
+func ProcessIdempotent(tenant, account, method, key string, args, reply interface{}, f func() error) error {
+	if dm == nil {
+		return f()
+	}
+	return dm.ProcessIdempotent(tenant, account, method, key, args, reply, f)
+}
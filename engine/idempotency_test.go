@@ -0,0 +1,174 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func TestProcessIdempotentReplay(t *testing.T) {
+	var calls int
+	charge := func(reply *float64) func() error {
+		return func() error {
+			calls++
+			*reply = 1.5
+			return nil
+		}
+	}
+	var rpl1, rpl2 float64
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key1", 1.5, &rpl1, charge(&rpl1)); err != nil {
+		t.Error(err)
+	}
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key1", 1.5, &rpl2, charge(&rpl2)); err != nil {
+		t.Error(err)
+	}
+	if calls != 1 {
+		t.Errorf("expecting 1 execution, received: %d", calls)
+	}
+	if rpl2 != 1.5 {
+		t.Errorf("expecting replayed reply 1.5, received: %v", rpl2)
+	}
+	// different method, different account or no key are executed
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Refund", "key1", 1.5, &rpl2, charge(&rpl2)); err != nil {
+		t.Error(err)
+	}
+	if err := dm.ProcessIdempotent("cgrates.org", "1002", "Test.Charge", "key1", 1.5, &rpl2, charge(&rpl2)); err != nil {
+		t.Error(err)
+	}
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "", 1.5, &rpl2, charge(&rpl2)); err != nil {
+		t.Error(err)
+	}
+	if calls != 4 {
+		t.Errorf("expecting 4 executions, received: %d", calls)
+	}
+	// same key with other arguments is refused
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key1", 2.5, &rpl2, charge(&rpl2)); err != utils.ErrIdempotencyKeyReused {
+		t.Errorf("expecting: %v, received: %v", utils.ErrIdempotencyKeyReused, err)
+	}
+	if calls != 4 {
+		t.Errorf("expecting 4 executions, received: %d", calls)
+	}
+}
+
+func TestProcessIdempotentErrorNotRecorded(t *testing.T) {
+	var calls int
+	var rpl string
+	errCharge := errors.New("CHARGE_FAILED")
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key2", "", &rpl, func() error {
+		calls++
+		return errCharge
+	}); err != errCharge {
+		t.Errorf("expecting: %v, received: %v", errCharge, err)
+	}
+	if _, err := dm.GetIdempotencyRecord(IdempotencyRecordID("cgrates.org", "1001", "Test.Charge", "key2")); err != utils.ErrNotFound {
+		t.Errorf("expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key2", "", &rpl, func() error {
+		calls++
+		rpl = utils.OK
+		return nil
+	}); err != nil {
+		t.Error(err)
+	}
+	if calls != 2 || rpl != utils.OK {
+		t.Errorf("calls: %d, reply: %s", calls, rpl)
+	}
+}
+
+func TestIdempotencyRecordExpired(t *testing.T) {
+	recID := IdempotencyRecordID("cgrates.org", "1001", "Test.Charge", "key3")
+	if err := dm.SetIdempotencyRecord(&IdempotencyRecord{ID: recID, Reply: "1",
+		ExpiryTime: time.Now().Add(-time.Second)}); err != nil {
+		t.Error(err)
+	}
+	if _, err := dm.GetIdempotencyRecord(recID); err != utils.ErrNotFound {
+		t.Errorf("expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+	var calls int
+	var rpl int
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key3", "", &rpl, func() error {
+		calls++
+		rpl = 2
+		return nil
+	}); err != nil {
+		t.Error(err)
+	}
+	if calls != 1 || rpl != 2 {
+		t.Errorf("calls: %d, reply: %d", calls, rpl)
+	}
+}
+
+func TestProcessIdempotentPending(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var rpl1, rpl2 string
+	errChan := make(chan error)
+	go func() {
+		errChan <- dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key4", "", &rpl1, func() error {
+			close(started)
+			<-release // slower than any locking timeout
+			rpl1 = utils.OK
+			return nil
+		})
+	}()
+	<-started
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key4", "", &rpl2, func() error {
+		t.Error("executed twice")
+		return nil
+	}); err != utils.ErrIdempotencyKeyPending {
+		t.Errorf("expecting: %v, received: %v", utils.ErrIdempotencyKeyPending, err)
+	}
+	close(release)
+	if err := <-errChan; err != nil {
+		t.Error(err)
+	}
+	if err := dm.ProcessIdempotent("cgrates.org", "1001", "Test.Charge", "key4", "", &rpl2, func() error {
+		t.Error("executed twice")
+		return nil
+	}); err != nil {
+		t.Error(err)
+	} else if rpl2 != utils.OK {
+		t.Errorf("received: %s", rpl2)
+	}
+}
+
+func TestMapStoragePurgeIdempotencyRecords(t *testing.T) {
+	ms, _ := NewMapStorage()
+	expRecID := IdempotencyRecordID("cgrates.org", "1001", "Test.Charge", "key5")
+	if err := ms.SetIdempotencyRecordDrv(&IdempotencyRecord{ID: expRecID, Reply: "1",
+		ExpiryTime: time.Now().Add(10 * time.Millisecond)}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	ms.idkPurged = time.Time{} // purge on the next write
+	if err := ms.SetIdempotencyRecordDrv(&IdempotencyRecord{ID: "other", Reply: "1",
+		ExpiryTime: time.Now().Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, has := ms.dict[utils.IdempotencyRecordPrefix+expRecID]; has {
+		t.Error("expired record not purged")
+	}
+	if _, has := ms.dict[utils.IdempotencyRecordPrefix+"other"]; !has {
+		t.Error("active record purged")
+	}
+}
@@ -0,0 +1,328 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// InvoiceLine aggregates the charges of one kind within an invoice
+type InvoiceLine struct {
+	Type             string // <*usage|*charge|*refund>
+	ToR              string
+	Category         string
+	DestinationGroup string // destination matched when rating
+	Events           int
+	Usage            time.Duration
+	Cost             float64
+}
+
+// Invoice is the billing statement of an account for one period
+type Invoice struct {
+	Tenant      string
+	ID          string // out of the numbering sequence of the tenant
+	Account     string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Lines       []*InvoiceLine
+	Subtotal    float64
+	TaxPercent  float64
+	Taxes       []*TaxItem // local taxes summed per TaxProfile, included in Tax
+	Tax         float64
+	Total       float64
+	CreatedAt   time.Time
+}
+
+// TenantID returns the unique identifier of the invoice
+func (inv *Invoice) TenantID() string {
+	return utils.ConcatenatedKey(inv.Tenant, inv.ID)
+}
+
+// InvoiceSequence keeps the last invoice number given out within a tenant
+type InvoiceSequence struct {
+	Tenant string
+	Last   int64
+}
+
+// invoiceLineKey groups the CDRs into invoice lines
+type invoiceLineKey struct {
+	lineType, tor, category, dstGroup string
+}
+
+// ArgsGenerateInvoice selects the account and the billing period
+type ArgsGenerateInvoice struct {
+	Tenant      string
+	Account     string
+	PeriodStart string // including
+	PeriodEnd   string // excluding
+	DryRun      bool   // compute the invoice without storing it or using up a number
+}
+
+// ArgsRenderInvoice selects the invoice and the template rendering it
+type ArgsRenderInvoice struct {
+	utils.TenantID
+	Template string // name of the template in config, *json for the built-in one
+}
+
+// NewInvoiceService initializes an InvoiceService
+func NewInvoiceService(cgrCfg *config.CGRConfig, dm *DataManager, cdrDB CdrStorage, filterS *FilterS) *InvoiceService {
+	return &InvoiceService{cgrCfg: cgrCfg, dm: dm, cdrDB: cdrDB, taxS: NewTaxService(dm, filterS)}
+}
+
+// InvoiceService builds the invoices of the accounts out of their rated CDRs
+type InvoiceService struct {
+	cgrCfg *config.CGRConfig
+	dm     *DataManager
+	cdrDB  CdrStorage
+	taxS   *TaxService
+}
+
+// ListenAndServe keeps the service running until shutdown
+func (iS *InvoiceService) ListenAndServe(exitChan chan bool) error {
+	e := <-exitChan
+	exitChan <- e // put back for the others listening for shutdown request
+	return nil
+}
+
+// Shutdown is called to shutdown the service
+func (iS *InvoiceService) Shutdown() error {
+	utils.Logger.Info("<InvoiceS> shutdown initialized")
+	utils.Logger.Info("<InvoiceS> shutdown complete")
+	return nil
+}
+
+// buildInvoice aggregates the CDRs of the account into invoice lines and computes the totals
+func (iS *InvoiceService) buildInvoice(tenant, account string, periodStart, periodEnd time.Time, cdrs []*CDR) (inv *Invoice, err error) {
+	inv = &Invoice{Tenant: tenant, Account: account,
+		PeriodStart: periodStart, PeriodEnd: periodEnd,
+		TaxPercent: iS.cgrCfg.InvoiceSCfg().TaxPercent,
+		CreatedAt:  time.Now()}
+	lines := make(map[invoiceLineKey]*InvoiceLine)
+	costs := make(map[invoiceLineKey]*utils.Decimal)
+	var keys []invoiceLineKey
+	localTaxes := make(map[string]*TaxItem)
+	taxAmounts := make(map[string]*utils.Decimal)
+	var taxIDs []string
+	for _, cdr := range cdrs {
+		var key invoiceLineKey
+		switch {
+		case cdr.Source != CDRLOG:
+			if cdr.Cost < 0 || !utils.IsSliceMember(iS.cgrCfg.InvoiceSCfg().RunIDs, cdr.RunID) {
+				continue // not rated or not billed to the account
+			}
+			key = invoiceLineKey{lineType: utils.MetaUsage, tor: cdr.ToR, category: cdr.Category}
+			if cdr.CostDetails != nil && len(cdr.CostDetails.Timespans) != 0 {
+				key.dstGroup = cdr.CostDetails.Timespans[0].MatchedDestId
+			}
+		case cdr.RunID == utils.MetaRefund:
+			key = invoiceLineKey{lineType: utils.MetaRefund, tor: cdr.ToR, category: cdr.Category}
+		case (cdr.RunID == DEBIT || cdr.RunID == DEBIT_RESET) && cdr.ToR == utils.MONETARY: // recurring charges executed by actions
+			key = invoiceLineKey{lineType: utils.MetaCharge, tor: cdr.ToR}
+		default:
+			continue
+		}
+		line, has := lines[key]
+		if !has {
+			line = &InvoiceLine{Type: key.lineType, ToR: key.tor,
+				Category: key.category, DestinationGroup: key.dstGroup}
+			lines[key] = line
+			costs[key] = utils.NewDecimalFromInt64(0)
+			keys = append(keys, key)
+		}
+		line.Events++
+		line.Usage += cdr.Usage
+		costs[key] = costs[key].Add(utils.NewDecimalFromFloat64(cdr.Cost))
+		if !iS.cgrCfg.InvoiceSCfg().LocalTaxes {
+			continue
+		}
+		var ev map[string]interface{}
+		if ev, err = cdr.AsMapStringIface(); err != nil {
+			return
+		}
+		var taxes []*TaxItem
+		if taxes, _, err = iS.taxS.TaxesForEvent(cdr.Tenant, ev, cdr.Cost, cdr.AnswerTime); err != nil {
+			return
+		}
+		for _, tax := range taxes {
+			if _, has := localTaxes[tax.ID]; !has {
+				localTaxes[tax.ID] = &TaxItem{ID: tax.ID, Jurisdiction: tax.Jurisdiction}
+				taxAmounts[tax.ID] = utils.NewDecimalFromInt64(0)
+				taxIDs = append(taxIDs, tax.ID)
+			}
+			taxAmounts[tax.ID] = taxAmounts[tax.ID].Add(utils.NewDecimalFromFloat64(tax.Amount))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].lineType != keys[j].lineType {
+			return keys[i].lineType > keys[j].lineType // *usage, *refund, *charge
+		}
+		if keys[i].tor != keys[j].tor {
+			return keys[i].tor < keys[j].tor
+		}
+		if keys[i].category != keys[j].category {
+			return keys[i].category < keys[j].category
+		}
+		return keys[i].dstGroup < keys[j].dstGroup
+	})
+	subtotal := utils.NewDecimalFromInt64(0)
+	for _, key := range keys {
+		cost := costs[key].Round(iS.cgrCfg.RoundingDecimals, utils.ROUNDING_MIDDLE)
+		lines[key].Cost = cost.Float64()
+		inv.Lines = append(inv.Lines, lines[key])
+		subtotal = subtotal.Add(cost)
+	}
+	tax := subtotal.Mul(utils.NewDecimalFromFloat64(inv.TaxPercent)).Div(utils.NewDecimalFromInt64(100)).
+		Round(iS.cgrCfg.RoundingDecimals, utils.ROUNDING_MIDDLE)
+	sort.Strings(taxIDs)
+	for _, taxID := range taxIDs {
+		localTaxes[taxID].Amount = taxAmounts[taxID].Float64()
+		inv.Taxes = append(inv.Taxes, localTaxes[taxID])
+		tax = tax.Add(taxAmounts[taxID])
+	}
+	inv.Subtotal = subtotal.Float64()
+	inv.Tax = tax.Float64()
+	inv.Total = subtotal.Add(tax).Float64()
+	return
+}
+
+// nextInvoiceID increments the numbering sequence of the tenant, the caller should lock it
+func (iS *InvoiceService) nextInvoiceID(tenant string) (invID string, err error) {
+	seq, err := iS.dm.GetInvoiceSequence(tenant)
+	if err != nil {
+		if err != utils.ErrNotFound {
+			return
+		}
+		seq = &InvoiceSequence{Tenant: tenant}
+	}
+	seq.Last++
+	if err = iS.dm.SetInvoiceSequence(seq); err != nil {
+		return
+	}
+	return fmt.Sprintf(iS.cgrCfg.InvoiceSCfg().IDFormat, seq.Last), nil
+}
+
+// V1GenerateInvoice builds the invoice of an account out of its CDRs within the period, storing it with the next number of the tenant
+func (iS *InvoiceService) V1GenerateInvoice(args *ArgsGenerateInvoice, reply *Invoice) (err error) {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "Account", "PeriodStart", "PeriodEnd"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	periodStart, err := utils.ParseTimeDetectLayout(args.PeriodStart, iS.cgrCfg.DefaultTimezone)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	periodEnd, err := utils.ParseTimeDetectLayout(args.PeriodEnd, iS.cgrCfg.DefaultTimezone)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	cdrs, _, err := iS.cdrDB.GetCDRs(&utils.CDRsFilter{Tenants: []string{args.Tenant}, Accounts: []string{args.Account},
+		AnswerTimeStart: &periodStart, AnswerTimeEnd: &periodEnd}, false)
+	if err != nil && err != utils.ErrNotFound {
+		return utils.NewErrServerError(err)
+	}
+	inv, err := iS.buildInvoice(args.Tenant, args.Account, periodStart, periodEnd, cdrs)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if !args.DryRun {
+		if _, err = guardian.Guardian.Guard(func() (interface{}, error) {
+			if inv.ID, err = iS.nextInvoiceID(inv.Tenant); err != nil {
+				return nil, err
+			}
+			return nil, iS.dm.SetInvoice(inv)
+		}, iS.cgrCfg.LockingTimeout, utils.InvoiceSequencePrefix+inv.Tenant); err != nil {
+			return utils.NewErrServerError(err)
+		}
+	}
+	*reply = *inv
+	return
+}
+
+// V1GetInvoice returns a stored invoice
+func (iS *InvoiceService) V1GetInvoice(tntID *utils.TenantID, reply *Invoice) (err error) {
+	inv, err := iS.dm.GetInvoice(tntID.Tenant, tntID.ID)
+	if err != nil {
+		return
+	}
+	*reply = *inv
+	return
+}
+
+// V1GetInvoiceIDs returns the IDs of the invoices stored for a tenant
+func (iS *InvoiceService) V1GetInvoiceIDs(tenant string, invIDs *[]string) (err error) {
+	prfx := utils.InvoicePrefix + tenant + ":"
+	keys, err := iS.dm.DataDB().GetKeysForPrefix(prfx)
+	if err != nil {
+		return err
+	}
+	retIDs := make([]string, len(keys))
+	for i, key := range keys {
+		retIDs[i] = key[len(prfx):]
+	}
+	sort.Strings(retIDs)
+	*invIDs = retIDs
+	return
+}
+
+// RenderInvoice formats the invoice with the template, *json or a text/template file configured
+func (iS *InvoiceService) RenderInvoice(inv *Invoice, tplName string) (out string, err error) {
+	if tplName == utils.MetaJSON {
+		var b []byte
+		if b, err = json.MarshalIndent(inv, "", " "); err != nil {
+			return
+		}
+		return string(b), nil
+	}
+	tplPath, has := iS.cgrCfg.InvoiceSCfg().Templates[tplName]
+	if !has {
+		return "", utils.ErrNotFound
+	}
+	tpl, err := template.ParseFiles(tplPath) // parsed on each render so templates can be changed without restart
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, inv); err != nil {
+		return
+	}
+	return buf.String(), nil
+}
+
+// V1RenderInvoice returns a stored invoice formatted with one of the templates
+func (iS *InvoiceService) V1RenderInvoice(args *ArgsRenderInvoice, reply *string) (err error) {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID", "Template"}); len(missing) != 0 {
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	inv, err := iS.dm.GetInvoice(args.Tenant, args.ID)
+	if err != nil {
+		return
+	}
+	if *reply, err = iS.RenderInvoice(inv, args.Template); err != nil && err != utils.ErrNotFound {
+		err = utils.NewErrServerError(err)
+	}
+	return
+}
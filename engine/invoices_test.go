@@ -0,0 +1,124 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func testInvoiceService(t *testing.T) *InvoiceService {
+	cfg, err := config.NewDefaultCGRConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.InvoiceSCfg().TaxPercent = 19
+	return NewInvoiceService(cfg, dm, nil, nil)
+}
+
+func TestInvoiceServiceBuildInvoice(t *testing.T) {
+	iS := testInvoiceService(t)
+	periodStart := time.Date(2017, 10, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2017, 11, 1, 0, 0, 0, 0, time.UTC)
+	cc := &CallCost{Timespans: TimeSpans{&TimeSpan{MatchedDestId: "DST_DE_MOBILE"}}}
+	cdrs := []*CDR{
+		&CDR{RunID: utils.META_DEFAULT, ToR: utils.VOICE, Category: "call",
+			Usage: time.Minute, Cost: 0.1, CostDetails: cc},
+		&CDR{RunID: utils.META_DEFAULT, ToR: utils.VOICE, Category: "call",
+			Usage: 2 * time.Minute, Cost: 0.2, CostDetails: cc},
+		&CDR{RunID: utils.META_DEFAULT, ToR: utils.SMS, Category: "sms", Usage: 1, Cost: 0.05},
+		&CDR{RunID: "supplier1", ToR: utils.VOICE, Category: "call", Usage: time.Minute, Cost: 0.01},      // not billed
+		&CDR{RunID: utils.META_DEFAULT, ToR: utils.VOICE, Category: "call", Usage: time.Minute, Cost: -1}, // not rated
+		&CDR{RunID: DEBIT, Source: CDRLOG, ToR: utils.MONETARY, Cost: 5},
+		&CDR{RunID: TOPUP, Source: CDRLOG, ToR: utils.MONETARY, Cost: 10}, // not billed
+		&CDR{RunID: utils.MetaRefund, Source: CDRLOG, ToR: utils.VOICE, Category: "call",
+			Usage: -time.Minute, Cost: -0.1},
+	}
+	inv, err := iS.buildInvoice("cgrates.org", "1001", periodStart, periodEnd, cdrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eLines := []*InvoiceLine{
+		&InvoiceLine{Type: utils.MetaUsage, ToR: utils.SMS, Category: "sms", Events: 1, Usage: 1, Cost: 0.05},
+		&InvoiceLine{Type: utils.MetaUsage, ToR: utils.VOICE, Category: "call", DestinationGroup: "DST_DE_MOBILE",
+			Events: 2, Usage: 3 * time.Minute, Cost: 0.3},
+		&InvoiceLine{Type: utils.MetaRefund, ToR: utils.VOICE, Category: "call", Events: 1, Usage: -time.Minute, Cost: -0.1},
+		&InvoiceLine{Type: utils.MetaCharge, ToR: utils.MONETARY, Events: 1, Cost: 5},
+	}
+	if len(inv.Lines) != len(eLines) {
+		t.Fatalf("expecting: %s, received: %s", utils.ToJSON(eLines), utils.ToJSON(inv.Lines))
+	}
+	for i, eLine := range eLines {
+		if *inv.Lines[i] != *eLine {
+			t.Errorf("line %d, expecting: %+v, received: %+v", i, eLine, inv.Lines[i])
+		}
+	}
+	if inv.Subtotal != 5.25 || inv.Tax != 0.9975 || inv.Total != 6.2475 {
+		t.Errorf("subtotal: %v, tax: %v, total: %v", inv.Subtotal, inv.Tax, inv.Total)
+	}
+}
+
+func TestInvoiceServiceNextInvoiceID(t *testing.T) {
+	iS := testInvoiceService(t)
+	for _, eID := range []string{"000001", "000002"} {
+		if invID, err := iS.nextInvoiceID("invoices.org"); err != nil {
+			t.Error(err)
+		} else if invID != eID {
+			t.Errorf("expecting: %s, received: %s", eID, invID)
+		}
+	}
+	if invID, err := iS.nextInvoiceID("other.org"); err != nil {
+		t.Error(err)
+	} else if invID != "000001" {
+		t.Errorf("sequence shared between tenants, received: %s", invID)
+	}
+}
+
+func TestInvoiceServiceRender(t *testing.T) {
+	iS := testInvoiceService(t)
+	inv := &Invoice{Tenant: "cgrates.org", ID: "000007", Account: "1001", Total: 1.19,
+		Lines: []*InvoiceLine{&InvoiceLine{Type: utils.MetaUsage, ToR: utils.VOICE, Cost: 1}}}
+	if out, err := iS.RenderInvoice(inv, utils.MetaJSON); err != nil {
+		t.Error(err)
+	} else if !strings.Contains(out, `"ID": "000007"`) {
+		t.Errorf("unexpected output: %s", out)
+	}
+	tplFile, err := ioutil.TempFile("", "invoice_tpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tplFile.Name())
+	tplFile.WriteString(`{{.ID}}:{{range .Lines}}{{.Type}}={{.Cost}};{{end}}total={{.Total}}`)
+	tplFile.Close()
+	iS.cgrCfg.InvoiceSCfg().Templates["*test"] = tplFile.Name()
+	if out, err := iS.RenderInvoice(inv, "*test"); err != nil {
+		t.Error(err)
+	} else if out != "000007:*usage=1;total=1.19" {
+		t.Errorf("unexpected output: %s", out)
+	}
+	if _, err := iS.RenderInvoice(inv, "*missing"); err != utils.ErrNotFound {
+		t.Errorf("expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cgrates/cgrates/config"
@@ -31,12 +32,14 @@ import (
 )
 
 const (
-	LCR_STRATEGY_STATIC        = "*static"
-	LCR_STRATEGY_LOWEST        = "*lowest_cost"
-	LCR_STRATEGY_HIGHEST       = "*highest_cost"
-	LCR_STRATEGY_QOS_THRESHOLD = "*qos_threshold"
-	LCR_STRATEGY_QOS           = "*qos"
-	LCR_STRATEGY_LOAD          = "*load_distribution"
+	LCR_STRATEGY_STATIC           = "*static"
+	LCR_STRATEGY_LOWEST           = "*lowest_cost"
+	LCR_STRATEGY_HIGHEST          = "*highest_cost"
+	LCR_STRATEGY_QOS_THRESHOLD    = "*qos_threshold"
+	LCR_STRATEGY_QOS              = "*qos"
+	LCR_STRATEGY_LOAD             = "*load_distribution"
+	LCR_STRATEGY_WEIGHTED_RR      = "*weighted_round_robin"
+	LCR_STRATEGY_LEAST_CONCURRENT = "*least_concurrent"
 
 	// used for load distribution sorting
 	RAND_LIMIT          = 99
@@ -154,16 +157,57 @@ type LCREntry struct {
 type LCRCost struct {
 	Entry         *LCREntry
 	SupplierCosts []*LCRSupplierCost
+	lcrID         string // used to index the round-robin counters
 }
 
 type LCRSupplierCost struct {
-	Supplier       string
-	Cost           float64
-	Duration       time.Duration
-	Error          string // Not error due to JSON automatic serialization into struct
-	QOS            map[string]float64
-	qosSortParams  []string
-	supplierQueues []*CDRStatsQueue // used for load distribution
+	Supplier        string
+	Cost            float64
+	Duration        time.Duration
+	Error           string // Not error due to JSON automatic serialization into struct
+	QOS             map[string]float64
+	qosSortParams   []string
+	supplierQueues  []*CDRStatsQueue // used for load distribution
+	concurrentCalls float64          // used for least concurrent
+}
+
+// AttrLCRCounter identifies the round-robin counter of a supplier within an LCR entry
+type AttrLCRCounter struct {
+	EntryID  string
+	Supplier string
+}
+
+// NewLCRCounters creates the LCRCounters
+func NewLCRCounters() *LCRCounters {
+	return &LCRCounters{cnts: make(map[string]map[string]int64)}
+}
+
+// LCRCounters keeps in memory the calls routed to each supplier by the weighted round-robin strategy
+type LCRCounters struct {
+	sync.RWMutex
+	cnts map[string]map[string]int64 // map[entryID]map[supplier]calls
+}
+
+// Counts returns a copy of the counters for one LCR entry
+func (lc *LCRCounters) Counts(entryID string) (cnts map[string]int64) {
+	lc.RLock()
+	defer lc.RUnlock()
+	cnts = make(map[string]int64, len(lc.cnts[entryID]))
+	for supplier, cnt := range lc.cnts[entryID] {
+		cnts[supplier] = cnt
+	}
+	return
+}
+
+// Increment increases the counter of a supplier, returning the new value
+func (lc *LCRCounters) Increment(entryID, supplier string) int64 {
+	lc.Lock()
+	defer lc.Unlock()
+	if _, has := lc.cnts[entryID]; !has {
+		lc.cnts[entryID] = make(map[string]int64)
+	}
+	lc.cnts[entryID][supplier] += 1
+	return lc.cnts[entryID][supplier]
 }
 
 func (lcr *LCR) GetId() string {
@@ -315,9 +359,61 @@ func (lc *LCRCost) Sort() {
 	case LCR_STRATEGY_LOAD:
 		lc.SortLoadDistribution()
 		sort.Sort(HighestSupplierCostSorter(lc.SupplierCosts))
+	case LCR_STRATEGY_WEIGHTED_RR:
+		lc.SortWeightedRoundRobin()
+	case LCR_STRATEGY_LEAST_CONCURRENT:
+		sort.Stable(LeastConcurrentSorter(lc.SupplierCosts))
 	}
 }
 
+// counterID returns the key of the round-robin counters for this LCR entry
+func (lc *LCRCost) counterID() string {
+	return utils.ConcatenatedKey(lc.lcrID, lc.Entry.DestinationId)
+}
+
+// SortWeightedRoundRobin orders the suppliers by the calls already routed to them relative to their ratio.
+// The first supplier is considered routed and its counter is increased, replicating the increase if configured.
+// Suppliers with errors go last and the ones without ratio are excluded.
+func (lc *LCRCost) SortWeightedRoundRobin() {
+	entryID := lc.counterID()
+	cnts := lcrCounters.Counts(entryID)
+	ratios := make(map[string]int64)
+	var rrSuppliers, errSuppliers []*LCRSupplierCost
+	for _, supCost := range lc.SupplierCosts {
+		if supCost.Error != "" {
+			errSuppliers = append(errSuppliers, supCost)
+			continue
+		}
+		ratio := lc.GetSupplierRatio(supCost.Supplier)
+		if ratio <= 0 {
+			continue
+		}
+		ratios[supCost.Supplier] = int64(ratio)
+		rrSuppliers = append(rrSuppliers, supCost)
+	}
+	sort.SliceStable(rrSuppliers, func(i, j int) bool {
+		supI, supJ := rrSuppliers[i].Supplier, rrSuppliers[j].Supplier
+		// compare cnts[supI]/ratios[supI] with cnts[supJ]/ratios[supJ] without losing precision
+		loadI, loadJ := cnts[supI]*ratios[supJ], cnts[supJ]*ratios[supI]
+		if loadI == loadJ {
+			return ratios[supI] > ratios[supJ]
+		}
+		return loadI < loadJ
+	})
+	if len(rrSuppliers) != 0 {
+		lcrCounters.Increment(entryID, rrSuppliers[0].Supplier)
+		if lcrReplication != nil {
+			go func(attr *AttrLCRCounter) {
+				var reply string
+				if err := lcrReplication.Call("Responder.IncrementLCRCounter", attr, &reply); err != nil {
+					utils.Logger.Warning(fmt.Sprintf("<RALs> replicating LCR counter for supplier: %s, error: %s", attr.Supplier, err.Error()))
+				}
+			}(&AttrLCRCounter{EntryID: entryID, Supplier: rrSuppliers[0].Supplier})
+		}
+	}
+	lc.SupplierCosts = append(rrSuppliers, errSuppliers...)
+}
+
 func (lc *LCRCost) SortLoadDistribution() {
 	// find the time window that is common to all qeues
 	scoreBoard := make(map[time.Duration]int) // register TimeWindow across suppliers
@@ -421,6 +517,27 @@ func (lc *LCRCost) GetSupplierRatio(supplier string) int {
 	return -1 // exclude missing suppliers
 }
 
+// used in least concurrent strategy only
+// receives a long supplier id and will return the resource ID found in strategy params, defaulting to supplier subject
+func (lc *LCRCost) GetSupplierResourceID(supplier string) string {
+	parts := strings.Split(supplier, utils.CONCATENATED_KEY_SEP)
+	supplierSubject := parts[len(parts)-1]
+	for _, param := range strings.Split(lc.Entry.StrategyParams, utils.INFIELD_SEP) {
+		if param == "" {
+			continue
+		}
+		resSlice := strings.Split(param, utils.CONCATENATED_KEY_SEP)
+		if len(resSlice) != 2 {
+			utils.Logger.Warning(fmt.Sprintf("bad format in least concurrent strategy param: %s", lc.Entry.StrategyParams))
+			continue
+		}
+		if resSlice[0] == supplierSubject {
+			return resSlice[1]
+		}
+	}
+	return supplierSubject
+}
+
 func (lc *LCRCost) HasErrors() bool {
 	for _, supplCost := range lc.SupplierCosts {
 
@@ -538,3 +655,24 @@ func (qoss QOSSorter) Less(i, j int) bool {
 	}
 	return false
 }
+
+// LeastConcurrentSorter places first the suppliers with less calls in progress, falling back on cost
+type LeastConcurrentSorter []*LCRSupplierCost
+
+func (lcs LeastConcurrentSorter) Len() int {
+	return len(lcs)
+}
+
+func (lcs LeastConcurrentSorter) Swap(i, j int) {
+	lcs[i], lcs[j] = lcs[j], lcs[i]
+}
+
+func (lcs LeastConcurrentSorter) Less(i, j int) bool {
+	if (lcs[i].Error == "") != (lcs[j].Error == "") {
+		return lcs[i].Error == "" // errors go last
+	}
+	if lcs[i].concurrentCalls == lcs[j].concurrentCalls {
+		return lcs[i].Cost < lcs[j].Cost
+	}
+	return lcs[i].concurrentCalls < lcs[j].concurrentCalls
+}
@@ -790,3 +790,128 @@ func TestLCRCostSuppliersLoadAllOverMisingParams(t *testing.T) {
 		t.Error("Error soring on load distribution: ", utils.ToIJSON(lcrCost))
 	}
 }
+
+type testLCRReplConn struct {
+	calls chan *AttrLCRCounter
+}
+
+func (tc *testLCRReplConn) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	tc.calls <- args.(*AttrLCRCounter)
+	*reply.(*string) = utils.OK
+	return nil
+}
+
+func TestLCRCostSuppliersWeightedRoundRobin(t *testing.T) {
+	ratios := map[string]int{"ivo12": 3, "dan12": 1, "rif12": 2}
+	routed := make(map[string]int)
+	for i := 1; i <= 600; i++ {
+		lcrCost := &LCRCost{
+			Entry: &LCREntry{DestinationId: utils.ANY, RPCategory: "call", Strategy: LCR_STRATEGY_WEIGHTED_RR,
+				StrategyParams: "ivo12:3;dan12:1;*default:2", Weight: 10.0},
+			SupplierCosts: []*LCRSupplierCost{
+				&LCRSupplierCost{Supplier: "*out:tenant12:call:err12", Error: "rating plan not found"},
+				&LCRSupplierCost{Supplier: "*out:tenant12:call:ivo12", Cost: 1},
+				&LCRSupplierCost{Supplier: "*out:tenant12:call:dan12", Cost: 2},
+				&LCRSupplierCost{Supplier: "*out:tenant12:call:rif12", Cost: 3},
+			},
+			lcrID: "*out:tenant12:call:*any:testWRR",
+		}
+		lcrCost.Sort()
+		if len(lcrCost.SupplierCosts) != 4 || lcrCost.SupplierCosts[3].Error == "" {
+			t.Fatalf("Unexpected suppliers: %s", utils.ToIJSON(lcrCost.SupplierCosts))
+		}
+		supps, _ := lcrCost.SuppliersSlice()
+		routed[supps[0]] += 1
+		for suppl, ratio := range ratios { // never further than one call from the ideal distribution
+			if ideal := float64(i*ratio) / 6; float64(routed[suppl]) < ideal-1 || float64(routed[suppl]) > ideal+1 {
+				t.Fatalf("Call %d, supplier %s routed %d times, expecting around %f", i, suppl, routed[suppl], ideal)
+			}
+		}
+	}
+	if eRouted := map[string]int{"ivo12": 300, "dan12": 100, "rif12": 200}; !reflect.DeepEqual(eRouted, routed) {
+		t.Errorf("Expecting: %+v, received: %+v", eRouted, routed)
+	}
+	if cnts := lcrCounters.Counts("*out:tenant12:call:*any:testWRR:*any"); cnts["*out:tenant12:call:ivo12"] != 300 {
+		t.Errorf("Unexpected counters: %+v", cnts)
+	}
+}
+
+func TestLCRCostSuppliersWeightedRoundRobinReplication(t *testing.T) {
+	replConn := &testLCRReplConn{calls: make(chan *AttrLCRCounter, 1)}
+	SetLCRReplication(replConn)
+	defer SetLCRReplication(nil)
+	lcrCost := &LCRCost{
+		Entry: &LCREntry{DestinationId: "DST_49", RPCategory: "call", Strategy: LCR_STRATEGY_WEIGHTED_RR},
+		SupplierCosts: []*LCRSupplierCost{
+			&LCRSupplierCost{Supplier: "*out:tenant12:call:ivo12"},
+			&LCRSupplierCost{Supplier: "*out:tenant12:call:dan12"},
+		},
+		lcrID: "testWRRRepl",
+	}
+	lcrCost.Sort()
+	select {
+	case attr := <-replConn.calls:
+		if eAttr := (&AttrLCRCounter{EntryID: "testWRRRepl:DST_49", Supplier: "*out:tenant12:call:ivo12"}); !reflect.DeepEqual(eAttr, attr) {
+			t.Errorf("Expecting: %+v, received: %+v", eAttr, attr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Counter not replicated")
+	}
+	// increase replicated by a peer
+	var reply string
+	if err := new(Responder).IncrementLCRCounter(&AttrLCRCounter{EntryID: "testWRRRepl:DST_49",
+		Supplier: "*out:tenant12:call:dan12"}, &reply); err != nil {
+		t.Error(err)
+	}
+	if cnts := lcrCounters.Counts("testWRRRepl:DST_49"); !reflect.DeepEqual(map[string]int64{
+		"*out:tenant12:call:ivo12": 1, "*out:tenant12:call:dan12": 1}, cnts) {
+		t.Errorf("Unexpected counters: %+v", cnts)
+	}
+	lcrCost.Sort()
+	if lcrCost.SupplierCosts[0].Supplier != "*out:tenant12:call:ivo12" {
+		t.Errorf("Unexpected suppliers: %s", utils.ToIJSON(lcrCost.SupplierCosts))
+	}
+}
+
+func TestLCRCostGetSupplierResourceID(t *testing.T) {
+	lcrCost := &LCRCost{
+		Entry: &LCREntry{DestinationId: utils.ANY, RPCategory: "call", Strategy: LCR_STRATEGY_LEAST_CONCURRENT,
+			StrategyParams: "ivo12:RES_IVO;dan12:RES_DAN"},
+	}
+	if resID := lcrCost.GetSupplierResourceID("*out:tenant12:call:dan12"); resID != "RES_DAN" {
+		t.Errorf("Received: %s", resID)
+	}
+	if resID := lcrCost.GetSupplierResourceID("*out:tenant12:call:rif12"); resID != "rif12" {
+		t.Errorf("Received: %s", resID)
+	}
+}
+
+func TestLCRCostSuppliersLeastConcurrent(t *testing.T) {
+	concurrent := map[string]float64{"ivo12": 5, "dan12": 0, "rif12": 2}
+	// keep routing calls without releasing them, the least loaded supplier gets the call
+	for i := 0; i < 300; i++ {
+		lcrCost := &LCRCost{
+			Entry: &LCREntry{DestinationId: utils.ANY, RPCategory: "call", Strategy: LCR_STRATEGY_LEAST_CONCURRENT},
+			SupplierCosts: []*LCRSupplierCost{
+				&LCRSupplierCost{Supplier: "*out:tenant12:call:err12", Error: "resource not found"},
+				&LCRSupplierCost{Supplier: "*out:tenant12:call:ivo12", Cost: 1, concurrentCalls: concurrent["ivo12"]},
+				&LCRSupplierCost{Supplier: "*out:tenant12:call:dan12", Cost: 2, concurrentCalls: concurrent["dan12"]},
+				&LCRSupplierCost{Supplier: "*out:tenant12:call:rif12", Cost: 3, concurrentCalls: concurrent["rif12"]},
+			},
+		}
+		lcrCost.Sort()
+		if lcrCost.SupplierCosts[3].Error == "" {
+			t.Fatalf("Unexpected suppliers: %s", utils.ToIJSON(lcrCost.SupplierCosts))
+		}
+		supps, _ := lcrCost.SuppliersSlice()
+		concurrent[supps[0]] += 1
+		if i == 4 { // dan12 caught up with rif12 and wins on cost
+			if eConcurrent := map[string]float64{"ivo12": 5, "dan12": 4, "rif12": 3}; !reflect.DeepEqual(eConcurrent, concurrent) {
+				t.Errorf("Expecting: %+v, received: %+v", eConcurrent, concurrent)
+			}
+		}
+	}
+	if eConcurrent := map[string]float64{"ivo12": 103, "dan12": 102, "rif12": 102}; !reflect.DeepEqual(eConcurrent, concurrent) {
+		t.Errorf("Expecting: %+v, received: %+v", eConcurrent, concurrent)
+	}
+}
@@ -19,47 +19,176 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/cenk/rpc2"
 	"github.com/cgrates/cgrates/config"
 	"github.com/cgrates/cgrates/utils"
 	"github.com/cgrates/rpcclient"
 )
 
+// rpcPools are the pools out of NewRPCPool, rebuilt by ReloadRPCPools
+var (
+	rpcPools    = make(map[*RPCPool]struct{})
+	rpcPoolsMux sync.Mutex
+)
+
+// NewRPCPool connects to rpcConnCfgs, returning the pool which is rebuilt on reloads of the connection settings
 func NewRPCPool(dispatchStrategy string, connAttempts, reconnects int, connectTimeout, replyTimeout time.Duration,
-	rpcConnCfgs []*config.HaPoolConfig, internalConnChan chan rpcclient.RpcClientConnection, ttl time.Duration) (*rpcclient.RpcClientPool, error) {
-	var rpcClient *rpcclient.RpcClient
-	var err error
-	rpcPool := rpcclient.NewRpcClientPool(dispatchStrategy, replyTimeout)
+	rpcConnCfgs []*config.HaPoolConfig, internalConnChan chan rpcclient.RpcClientConnection, ttl time.Duration) (*RPCPool, error) {
+	rpcPool := &RPCPool{dispatchStrategy: dispatchStrategy, rpcConnCfgs: rpcConnCfgs, internalConnChan: internalConnChan}
+	err := rpcPool.connect(connAttempts, reconnects, connectTimeout, replyTimeout, ttl, false)
+	if rpcPool.pool == nil {
+		return nil, err
+	}
+	rpcPoolsMux.Lock()
+	rpcPools[rpcPool] = struct{}{}
+	rpcPoolsMux.Unlock()
+	return rpcPool, err
+}
+
+// NewBiRPCPool is NewRPCPool also connecting over bidirectional JSON-RPC to the *bijson transports, the peers calling back
+// biRPCHandlers over them. Not rebuilt on reloads since the peers reach back their sessions over the same connections.
+func NewBiRPCPool(dispatchStrategy string, connAttempts, reconnects int, connectTimeout, replyTimeout time.Duration,
+	rpcConnCfgs []*config.HaPoolConfig, internalConnChan chan rpcclient.RpcClientConnection, ttl time.Duration,
+	biRPCHandlers map[string]interface{}) (*RPCPool, error) {
+	rpcPool := &RPCPool{dispatchStrategy: dispatchStrategy, rpcConnCfgs: rpcConnCfgs,
+		internalConnChan: internalConnChan, biRPCHandlers: biRPCHandlers}
+	err := rpcPool.connect(connAttempts, reconnects, connectTimeout, replyTimeout, ttl, false)
+	if rpcPool.pool == nil {
+		return nil, err
+	}
+	return rpcPool, err
+}
+
+// ReloadRPCPools rebuilds the pools out of NewRPCPool with the connection settings of cfg.
+// The new connections are lazy so the reload does not wait for the peers
+func ReloadRPCPools(cfg *config.CGRConfig) {
+	connAttempts, reconnects, connectTimeout, replyTimeout, ttl := cfg.RPCConnCfg()
+	rpcPoolsMux.Lock()
+	defer rpcPoolsMux.Unlock()
+	for rpcPool := range rpcPools {
+		if err := rpcPool.connect(connAttempts, reconnects, connectTimeout, replyTimeout, ttl, true); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<RPCPool> Keeping the previous connections, could not rebuild the pool: %s", err.Error()))
+		}
+	}
+}
+
+// RPCPool is a rpcclient.RpcClientPool which can be rebuilt out of the same connection configs
+type RPCPool struct {
+	dispatchStrategy string
+	rpcConnCfgs      []*config.HaPoolConfig
+	internalConnChan chan rpcclient.RpcClientConnection
+	biRPCHandlers    map[string]interface{} // served over the *bijson connections
+	poolMux          sync.RWMutex
+	pool             *rpcclient.RpcClientPool
+	clients          []rpcclient.RpcClientConnection // closed once the pool is replaced
+}
+
+// connect builds the pool, replacing and closing the previous one.
+// On errors not due to the peers being down the previous pool is kept
+func (rpcPool *RPCPool) connect(connAttempts, reconnects int, connectTimeout, replyTimeout, ttl time.Duration,
+	lazyConnect bool) (err error) {
+	pool := rpcclient.NewRpcClientPool(rpcPool.dispatchStrategy, replyTimeout)
+	var clients []rpcclient.RpcClientConnection
 	atLestOneConnected := false // If one connected we don't longer return errors
-	for _, rpcConnCfg := range rpcConnCfgs {
+	for _, rpcConnCfg := range rpcPool.rpcConnCfgs {
+		var rpcClient rpcclient.RpcClientConnection
 		if rpcConnCfg.Address == utils.MetaInternal {
 			var internalConn rpcclient.RpcClientConnection
 			select {
-			case internalConn = <-internalConnChan:
-				internalConnChan <- internalConn
+			case internalConn = <-rpcPool.internalConnChan:
+				rpcPool.internalConnChan <- internalConn
 			case <-time.After(ttl):
-				return nil, errors.New("TTL triggered")
+				closeRPCClients(clients)
+				return errors.New("TTL triggered")
 			}
 			rpcClient, err = rpcclient.NewRpcClient("", "", connAttempts, reconnects, connectTimeout, replyTimeout, rpcclient.INTERNAL_RPC, internalConn, false)
 		} else if utils.IsSliceMember([]string{utils.MetaJSONrpc, utils.MetaGOBrpc, ""}, rpcConnCfg.Transport) {
-			codec := utils.GOB
-			if rpcConnCfg.Transport != "" {
-				codec = rpcConnCfg.Transport[1:] // Transport contains always * before codec understood by rpcclient
+			if rpcClient, err = NewRemoteRPCClient(rpcConnCfg, connAttempts, reconnects, connectTimeout, replyTimeout, lazyConnect); rpcClient == nil {
+				closeRPCClients(clients)
+				return // misconfigured, retrying will not help
 			}
-			rpcClient, err = rpcclient.NewRpcClient("tcp", rpcConnCfg.Address, connAttempts, reconnects, connectTimeout, replyTimeout, codec, nil, false)
+		} else if rpcConnCfg.Transport == utils.MetaBiJSONrpc && rpcPool.biRPCHandlers != nil {
+			var biRPCClnt *rpc2.Client
+			if biRPCClnt, err = utils.NewBiJSONrpcClient(rpcConnCfg.Address, rpcConnCfg.ApiKey, rpcPool.biRPCHandlers); err != nil {
+				closeRPCClients(clients)
+				return // no reconnects over bidirectional connections
+			}
+			rpcClient = biRPCClnt
 		} else {
-			return nil, fmt.Errorf("Unsupported transport: <%s>", rpcConnCfg.Transport)
+			closeRPCClients(clients)
+			return fmt.Errorf("Unsupported transport: <%s>", rpcConnCfg.Transport)
 		}
 		if err == nil {
 			atLestOneConnected = true
 		}
-		rpcPool.AddClient(rpcClient)
+		pool.AddClient(rpcClient)
+		clients = append(clients, rpcClient)
 	}
 	if atLestOneConnected {
 		err = nil
 	}
-	return rpcPool, err
+	rpcPool.poolMux.Lock()
+	prevClients := rpcPool.clients
+	rpcPool.pool, rpcPool.clients = pool, clients
+	rpcPool.poolMux.Unlock()
+	closeRPCClients(prevClients)
+	return
+}
+
+// Call implements rpcclient.RpcClientConnection interface
+func (rpcPool *RPCPool) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	rpcPool.poolMux.RLock()
+	pool := rpcPool.pool
+	rpcPool.poolMux.RUnlock()
+	return pool.Call(serviceMethod, args, reply)
+}
+
+// Close drops the connections of a pool not used anymore, it will not be rebuilt on reloads
+func (rpcPool *RPCPool) Close() {
+	rpcPoolsMux.Lock()
+	delete(rpcPools, rpcPool)
+	rpcPoolsMux.Unlock()
+	rpcPool.poolMux.RLock()
+	closeRPCClients(rpcPool.clients)
+	rpcPool.poolMux.RUnlock()
+}
+
+// closeRPCClients drops the connections of the remote clients, the internal ones have nothing to close
+func closeRPCClients(clients []rpcclient.RpcClientConnection) {
+	for _, clnt := range clients {
+		switch rmtClnt := clnt.(type) {
+		case *utils.RemoteRPCClient:
+			rmtClnt.Close()
+		case *rpc2.Client:
+			rmtClnt.Close()
+		}
+	}
+}
+
+// NewRemoteRPCClient connects to another engine, over TLS and/or authenticated with an API key if the connection config requests it
+func NewRemoteRPCClient(rpcConnCfg *config.HaPoolConfig, connAttempts, reconnects int,
+	connectTimeout, replyTimeout time.Duration, lazyConnect bool) (rpcclient.RpcClientConnection, error) {
+	codec := utils.GOB
+	if rpcConnCfg.Transport != "" {
+		codec = rpcConnCfg.Transport[1:] // Transport contains always * before codec understood by rpcclient
+	}
+	var tlsCfg *tls.Config
+	if rpcConnCfg.TLS {
+		var err error
+		if tlsCfg, err = utils.NewClientTLSConfig(config.CgrConfig().TLSClientCfg()); err != nil {
+			return nil, err
+		}
+	}
+	rmtClnt, err := utils.NewRemoteRPCClient(rpcConnCfg.Address, codec, tlsCfg, rpcConnCfg.ApiKey,
+		connAttempts, reconnects, connectTimeout, replyTimeout, lazyConnect)
+	if rmtClnt == nil { // avoid returning typed nil
+		return nil, err
+	}
+	return rmtClnt, err
 }
@@ -95,19 +95,24 @@ func (cIl *ChargingInterval) EndTime(cIlST time.Time) (et time.Time) {
 // Cost computes the total cost on this ChargingInterval
 func (cIl *ChargingInterval) Cost() float64 {
 	if cIl.cost == nil {
-		var cost float64
+		cost := utils.NewDecimalFromInt64(0)
 		for _, incr := range cIl.Increments {
-			cost += incr.Cost * float64(incr.CompressFactor)
+			cost = cost.Add(incr.totalCostDecimal())
 		}
-		cost = utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
-		cIl.cost = &cost
+		cost = cost.Round(globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+		cIl.cost = utils.Float64Pointer(cost.Float64())
 	}
 	return *cIl.cost
 }
 
 func (cIl *ChargingInterval) TotalCost() float64 {
-	return utils.Round((cIl.Cost() * float64(cIl.CompressFactor)),
-		globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	return cIl.totalCostDecimal().Float64()
+}
+
+func (cIl *ChargingInterval) totalCostDecimal() *utils.Decimal {
+	return utils.NewDecimalFromFloat64(cIl.Cost()).
+		Mul(utils.NewDecimalFromInt64(int64(cIl.CompressFactor))).
+		Round(globalRoundingDecimals, utils.ROUNDING_MIDDLE)
 }
 
 // Clone returns a new instance of ChargingInterval with independent data
@@ -149,7 +154,12 @@ func (cIt *ChargingIncrement) TotalUsage() time.Duration {
 }
 
 func (cIt *ChargingIncrement) TotalCost() float64 {
-	return cIt.Cost * float64(cIt.CompressFactor)
+	return cIt.totalCostDecimal().Float64()
+}
+
+func (cIt *ChargingIncrement) totalCostDecimal() *utils.Decimal {
+	return utils.NewDecimalFromFloat64(cIt.Cost).
+		Mul(utils.NewDecimalFromInt64(int64(cIt.CompressFactor)))
 }
 
 // BalanceCharge represents one unit charged to a balance
@@ -159,6 +169,7 @@ type BalanceCharge struct {
 	RatingID      string  // special price applied on this balance
 	Units         float64 // number of units charged
 	ExtraChargeID string  // used in cases when paying *voice with *monetary
+	ExchangeRate  float64 // converting Units into the balance currency, 0 when not converted
 }
 
 func (bc *BalanceCharge) Equals(oBC *BalanceCharge) bool {
@@ -166,7 +177,8 @@ func (bc *BalanceCharge) Equals(oBC *BalanceCharge) bool {
 		bc.BalanceUUID == oBC.BalanceUUID &&
 		bc.RatingID == oBC.RatingID &&
 		bc.Units == oBC.Units &&
-		bc.ExtraChargeID == oBC.ExtraChargeID
+		bc.ExtraChargeID == oBC.ExtraChargeID &&
+		bc.ExchangeRate == oBC.ExchangeRate
 }
 
 func (bc *BalanceCharge) Clone() *BalanceCharge {
@@ -224,6 +236,7 @@ type RatingUnit struct {
 	RoundingDecimals int
 	MaxCost          float64
 	MaxCostStrategy  string
+	Currency         string
 	TimingID         string // This RatingUnit is bounded to specific timing profile
 	RatesID          string
 	RatingFiltersID  string
@@ -235,6 +248,7 @@ func (ru *RatingUnit) Equals(oRU *RatingUnit) bool {
 		ru.RoundingDecimals == oRU.RoundingDecimals &&
 		ru.MaxCost == oRU.MaxCost &&
 		ru.MaxCostStrategy == oRU.MaxCostStrategy &&
+		ru.Currency == oRU.Currency &&
 		ru.TimingID == oRU.TimingID &&
 		ru.RatesID == oRU.RatesID &&
 		ru.RatingFiltersID == oRU.RatingFiltersID
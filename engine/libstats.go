@@ -21,6 +21,7 @@ package engine
 import (
 	"errors"
 	"fmt"
+	"github.com/cgrates/cgrates/config"
 	"github.com/cgrates/cgrates/utils"
 	"sort"
 	"strconv"
@@ -41,12 +42,41 @@ type StatQueueProfile struct {
 	Stored             bool
 	Weight             float64
 	MinItems           int
+	Bucket             string // calendar bucket resetting the metrics: *hourly, *daily or *monthly, empty for none
+	BucketTimezone     string // timezone of the bucket boundaries, defaults to general timezone
+	BucketHistory      int    // number of closed buckets to keep
 }
 
 func (sqp *StatQueueProfile) TenantID() string {
 	return utils.ConcatenatedKey(sqp.Tenant, sqp.ID)
 }
 
+// StatQueueBucket holds the metric values of a closed calendar bucket
+type StatQueueBucket struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Metrics   map[string]float64 // map[metricID]value at bucket closing
+}
+
+// bucketInterval returns the boundaries of the calendar bucket containing t
+func bucketInterval(t time.Time, bucket string, loc *time.Location) (start, end time.Time, err error) {
+	t = t.In(loc)
+	switch bucket {
+	case utils.MetaHourly:
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		end = start.Add(time.Hour)
+	case utils.MetaDaily:
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 1)
+	case utils.MetaMonthly:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	default:
+		err = fmt.Errorf("unsupported bucket: <%s>", bucket)
+	}
+	return
+}
+
 // StatEvent is an event processed by StatService
 type StatEvent struct {
 	Tenant string
@@ -145,6 +175,47 @@ func (se StatEvent) Destination() (ddc string, err error) {
 	return ddcStr, nil
 }
 
+// FieldAsFloat64 returns the value of a generic field of StatEvent as float64
+func (se StatEvent) FieldAsFloat64(fldName string) (val float64, err error) {
+	fldIf, has := se.Event[fldName]
+	if !has {
+		return val, utils.ErrNotFound
+	}
+	switch fldVal := fldIf.(type) {
+	case float64:
+		return fldVal, nil
+	case int:
+		return float64(fldVal), nil
+	case int64:
+		return float64(fldVal), nil
+	case time.Duration:
+		return fldVal.Seconds(), nil
+	case string:
+		if val, err = strconv.ParseFloat(fldVal, 64); err == nil {
+			return
+		}
+		var dur time.Duration
+		if dur, err = utils.ParseDurationWithSecs(fldVal); err != nil {
+			return
+		}
+		return dur.Seconds(), nil
+	}
+	return val, errors.New("cannot cast to float64")
+}
+
+// FieldAsString returns the value of a generic field of StatEvent as string
+func (se StatEvent) FieldAsString(fldName string) (val string, err error) {
+	fldIf, has := se.Event[fldName]
+	if !has {
+		return val, utils.ErrNotFound
+	}
+	val, canCast := utils.CastFieldIfToString(fldIf)
+	if !canCast {
+		return val, errors.New("cannot cast to string")
+	}
+	return
+}
+
 // NewStoredStatQueue initiates a StoredStatQueue out of StatQueue
 func NewStoredStatQueue(sq *StatQueue, ms Marshaler) (sSQ *StoredStatQueue, err error) {
 	sSQ = &StoredStatQueue{
@@ -154,8 +225,10 @@ func NewStoredStatQueue(sq *StatQueue, ms Marshaler) (sSQ *StoredStatQueue, err
 			EventID    string
 			ExpiryTime *time.Time
 		}, len(sq.SQItems)),
-		SQMetrics: make(map[string][]byte, len(sq.SQMetrics)),
-		MinItems:  sq.MinItems,
+		SQMetrics:   make(map[string][]byte, len(sq.SQMetrics)),
+		MinItems:    sq.MinItems,
+		BucketStart: sq.BucketStart,
+		History:     sq.History,
 	}
 	for i, sqItm := range sq.SQItems {
 		sSQ.SQItems[i] = sqItm
@@ -178,8 +251,10 @@ type StoredStatQueue struct {
 		EventID    string     // Bounded to the original StatEvent
 		ExpiryTime *time.Time // Used to auto-expire events
 	}
-	SQMetrics map[string][]byte
-	MinItems  int
+	SQMetrics   map[string][]byte
+	MinItems    int
+	BucketStart *time.Time // start of the current calendar bucket
+	History     []*StatQueueBucket
 }
 
 // SqID will compose the unique identifier for the StatQueue out of Tenant and ID
@@ -196,8 +271,10 @@ func (ssq *StoredStatQueue) AsStatQueue(ms Marshaler) (sq *StatQueue, err error)
 			EventID    string
 			ExpiryTime *time.Time
 		}, len(ssq.SQItems)),
-		SQMetrics: make(map[string]StatMetric, len(ssq.SQMetrics)),
-		MinItems:  ssq.MinItems,
+		SQMetrics:   make(map[string]StatMetric, len(ssq.SQMetrics)),
+		MinItems:    ssq.MinItems,
+		BucketStart: ssq.BucketStart,
+		History:     ssq.History,
 	}
 	for i, sqItm := range ssq.SQItems {
 		sq.SQItems[i] = sqItm
@@ -222,11 +299,13 @@ type StatQueue struct {
 		EventID    string     // Bounded to the original StatEvent
 		ExpiryTime *time.Time // Used to auto-expire events
 	}
-	SQMetrics map[string]StatMetric
-	MinItems  int
-	sqPrfl    *StatQueueProfile
-	dirty     *bool          // needs save
-	ttl       *time.Duration // timeToLeave, picked on each init
+	SQMetrics   map[string]StatMetric
+	MinItems    int
+	BucketStart *time.Time         // start of the current calendar bucket
+	History     []*StatQueueBucket // closed calendar buckets, oldest first
+	sqPrfl      *StatQueueProfile
+	dirty       *bool          // needs save
+	ttl         *time.Duration // timeToLeave, picked on each init
 }
 
 // SqID will compose the unique identifier for the StatQueue out of Tenant and ID
@@ -236,12 +315,64 @@ func (sq *StatQueue) TenantID() string {
 
 // ProcessEvent processes a StatEvent, returns true if processed
 func (sq *StatQueue) ProcessEvent(ev *StatEvent) (err error) {
+	if _, err = sq.rotateBucket(time.Now()); err != nil {
+		return
+	}
 	sq.remExpired()
 	sq.remOnQueueLength()
 	sq.addStatEvent(ev)
 	return
 }
 
+// rotateBucket closes the current calendar bucket if now is outside of it,
+// recording its metrics into History and resetting them
+// returns true if the queue was modified
+func (sq *StatQueue) rotateBucket(now time.Time) (rotated bool, err error) {
+	if sq.sqPrfl == nil || sq.sqPrfl.Bucket == "" {
+		return
+	}
+	tz := sq.sqPrfl.BucketTimezone
+	if tz == "" {
+		tz = config.CgrConfig().DefaultTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return
+	}
+	start, _, err := bucketInterval(now, sq.sqPrfl.Bucket, loc)
+	if err != nil {
+		return
+	}
+	if sq.BucketStart == nil {
+		sq.BucketStart = &start
+		return true, nil
+	}
+	if !start.After(*sq.BucketStart) {
+		return
+	}
+	bktStart, bktEnd, err := bucketInterval(*sq.BucketStart, sq.sqPrfl.Bucket, loc)
+	if err != nil {
+		return
+	}
+	closed := &StatQueueBucket{StartTime: bktStart, EndTime: bktEnd,
+		Metrics: make(map[string]float64, len(sq.SQMetrics))}
+	for metricID, metric := range sq.SQMetrics {
+		closed.Metrics[metricID] = metric.GetFloat64Value()
+		if sq.SQMetrics[metricID], err = NewStatMetric(metricID, sq.sqPrfl.MinItems); err != nil {
+			return
+		}
+	}
+	if sq.sqPrfl.BucketHistory > 0 {
+		sq.History = append(sq.History, closed)
+	}
+	if len(sq.History) > sq.sqPrfl.BucketHistory {
+		sq.History = sq.History[len(sq.History)-sq.sqPrfl.BucketHistory:]
+	}
+	sq.SQItems = sq.SQItems[:0]
+	sq.BucketStart = &start
+	return true, nil
+}
+
 // remStatEvent removes an event from metrics
 func (sq *StatQueue) remEventWithID(evTenantID string) {
 	for metricID, metric := range sq.SQMetrics {
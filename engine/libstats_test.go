@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cgrates/cgrates/cache"
 	"github.com/cgrates/cgrates/utils"
 )
 
@@ -205,3 +206,143 @@ func TestStatAddStatEvent(t *testing.T) {
 		t.Errorf("ASR: %v", asrMetric)
 	}
 }
+
+func TestStatBucketInterval(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	evTime := time.Date(2017, 12, 31, 23, 30, 0, 0, time.UTC) // 00:30 in Berlin
+	if start, end, err := bucketInterval(evTime, utils.MetaHourly, loc); err != nil {
+		t.Error(err)
+	} else if !start.Equal(time.Date(2018, 1, 1, 0, 0, 0, 0, loc)) ||
+		!end.Equal(time.Date(2018, 1, 1, 1, 0, 0, 0, loc)) {
+		t.Errorf("start: %v, end: %v", start, end)
+	}
+	if start, end, err := bucketInterval(evTime, utils.MetaDaily, loc); err != nil {
+		t.Error(err)
+	} else if !start.Equal(time.Date(2018, 1, 1, 0, 0, 0, 0, loc)) ||
+		!end.Equal(time.Date(2018, 1, 2, 0, 0, 0, 0, loc)) {
+		t.Errorf("start: %v, end: %v", start, end)
+	}
+	if start, end, err := bucketInterval(evTime, utils.MetaMonthly, time.UTC); err != nil {
+		t.Error(err)
+	} else if !start.Equal(time.Date(2017, 12, 1, 0, 0, 0, 0, time.UTC)) ||
+		!end.Equal(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("start: %v, end: %v", start, end)
+	}
+	if _, _, err := bucketInterval(evTime, "*weekly", time.UTC); err == nil {
+		t.Error("expecting error for unsupported bucket")
+	}
+}
+
+func TestStatRotateBucket(t *testing.T) {
+	asr, _ := NewASR(0, "")
+	sq = &StatQueue{
+		SQMetrics: map[string]StatMetric{utils.MetaASR: asr},
+		sqPrfl: &StatQueueProfile{Bucket: utils.MetaHourly, BucketTimezone: "UTC",
+			BucketHistory: 2},
+	}
+	now := time.Date(2017, 12, 1, 14, 10, 0, 0, time.UTC)
+	if rotated, err := sq.rotateBucket(now); err != nil {
+		t.Error(err)
+	} else if !rotated || !sq.BucketStart.Equal(time.Date(2017, 12, 1, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("rotated: %v, bucket start: %v", rotated, sq.BucketStart)
+	}
+	ev1 := &StatEvent{Tenant: "cgrates.org", ID: "TestStatRotateBucket_1",
+		Event: map[string]interface{}{utils.ANSWER_TIME: now}}
+	sq.SQItems = append(sq.SQItems, struct {
+		EventID    string
+		ExpiryTime *time.Time
+	}{ev1.TenantID(), nil})
+	sq.addStatEvent(ev1)
+	if rotated, err := sq.rotateBucket(now.Add(40 * time.Minute)); err != nil {
+		t.Error(err)
+	} else if rotated {
+		t.Error("rotated inside the same bucket")
+	}
+	for i := 1; i <= 3; i++ { // three hourly rotations, history keeps last two
+		if rotated, err := sq.rotateBucket(now.Add(time.Duration(i) * time.Hour)); err != nil {
+			t.Error(err)
+		} else if !rotated {
+			t.Errorf("not rotated at iteration: %d", i)
+		}
+	}
+	if len(sq.History) != 2 {
+		t.Fatalf("history: %+v", sq.History)
+	}
+	eBkt := &StatQueueBucket{
+		StartTime: time.Date(2017, 12, 1, 16, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2017, 12, 1, 17, 0, 0, 0, time.UTC),
+		Metrics:   map[string]float64{utils.MetaASR: -1}}
+	if !reflect.DeepEqual(eBkt, sq.History[1]) {
+		t.Errorf("expecting: %+v, received: %+v", eBkt, sq.History[1])
+	}
+	if len(sq.SQItems) != 0 {
+		t.Errorf("items not reset: %+v", sq.SQItems)
+	}
+}
+
+func TestStatRotateBucketHistory(t *testing.T) {
+	asr, _ := NewASR(0, "")
+	sq = &StatQueue{
+		SQMetrics: map[string]StatMetric{utils.MetaASR: asr},
+		sqPrfl: &StatQueueProfile{Bucket: utils.MetaDaily, BucketTimezone: "UTC",
+			BucketHistory: 1},
+		BucketStart: utils.TimePointer(time.Date(2017, 12, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	sq.addStatEvent(&StatEvent{Tenant: "cgrates.org", ID: "TestStatRotateBucketHistory_1",
+		Event: map[string]interface{}{utils.ANSWER_TIME: time.Date(2017, 12, 1, 10, 0, 0, 0, time.UTC)}})
+	sq.addStatEvent(&StatEvent{Tenant: "cgrates.org", ID: "TestStatRotateBucketHistory_2"})
+	if _, err := sq.rotateBucket(time.Date(2017, 12, 2, 0, 0, 1, 0, time.UTC)); err != nil {
+		t.Error(err)
+	}
+	eHistory := []*StatQueueBucket{
+		&StatQueueBucket{
+			StartTime: time.Date(2017, 12, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2017, 12, 2, 0, 0, 0, 0, time.UTC),
+			Metrics:   map[string]float64{utils.MetaASR: 50}},
+	}
+	if !reflect.DeepEqual(eHistory, sq.History) {
+		t.Errorf("expecting: %+v, received: %+v", eHistory[0], sq.History[0])
+	}
+	if asr := sq.SQMetrics[utils.MetaASR].GetFloat64Value(); asr != -1 {
+		t.Errorf("metrics not reset, ASR: %v", asr)
+	}
+}
+
+func TestStatServiceGetStatQueueReloadedProfile(t *testing.T) {
+	dataDB, _ := NewMapStorage()
+	dm := NewDataManager(dataDB)
+	sS, _ := NewStatService(dm, 0, nil, nil, nil)
+	sqPrfl := &StatQueueProfile{Tenant: "cgrates.org", ID: "SQ_BUCKET",
+		QueueLength: 10, Metrics: []string{utils.MetaASR}}
+	if err := dm.SetStatQueueProfile(sqPrfl); err != nil {
+		t.Fatal(err)
+	}
+	asr, _ := NewASR(0, "")
+	if err := dm.SetStatQueue(&StatQueue{Tenant: "cgrates.org", ID: "SQ_BUCKET",
+		SQMetrics: map[string]StatMetric{utils.MetaASR: asr}}); err != nil {
+		t.Fatal(err)
+	}
+	if rcv, err := sS.getStatQueue("cgrates.org", "SQ_BUCKET"); err != nil {
+		t.Fatal(err)
+	} else if rcv.BucketStart != nil {
+		t.Errorf("bucket started without profile bucket: %v", rcv.BucketStart)
+	}
+	// reload the profile with bucket, the queue stays cached
+	sqPrfl = &StatQueueProfile{Tenant: "cgrates.org", ID: "SQ_BUCKET",
+		QueueLength: 10, Metrics: []string{utils.MetaASR},
+		Bucket: utils.MetaHourly, BucketTimezone: "UTC"}
+	if err := dm.SetStatQueueProfile(sqPrfl); err != nil {
+		t.Fatal(err)
+	}
+	cache.RemKey(utils.StatQueueProfilePrefix+sqPrfl.TenantID(), true, utils.NonTransactional)
+	if rcv, err := sS.getStatQueue("cgrates.org", "SQ_BUCKET"); err != nil {
+		t.Fatal(err)
+	} else if rcv.BucketStart == nil {
+		t.Error("bucket not started with the reloaded profile")
+	} else if rcv.sqPrfl.Bucket != utils.MetaHourly {
+		t.Errorf("stale profile: %+v", rcv.sqPrfl)
+	}
+}
@@ -135,6 +135,8 @@ func LoadTariffPlanFromFolder(tpPath, timezone string, dm *DataManager, disable_
 		path.Join(tpPath, utils.StatsCsv),
 		path.Join(tpPath, utils.ThresholdsCsv),
 		path.Join(tpPath, utils.FiltersCsv),
+		path.Join(tpPath, utils.SuppliersCsv),
+		path.Join(tpPath, utils.ExchangeRatesCsv),
 	), "", timezone)
 	if err := loader.LoadAll(); err != nil {
 		return utils.NewErrServerError(err)
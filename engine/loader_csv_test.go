@@ -270,10 +270,10 @@ cgrates.org,ResGroup21,FLTR_1,2014-07-29T15:00:00Z,1s,2,call,true,true,10,
 cgrates.org,ResGroup22,FLTR_ACNT_dan,2014-07-29T15:00:00Z,3600s,2,premium_call,true,true,10,
 `
 	stats = `
-#Tenant[0],Id[1],FilterIDs[2],ActivationInterval[3],QueueLength[4],TTL[5],Metrics[6],Blocker[7],Stored[8],Weight[9],MinItems[10],Thresholds[11]
+#Tenant[0],Id[1],FilterIDs[2],ActivationInterval[3],QueueLength[4],TTL[5],Metrics[6],Blocker[7],Stored[8],Weight[9],MinItems[10],Thresholds[11],Bucket[12],BucketTimezone[13],BucketHistory[14]
 cgrates.org,Stats1,FLTR_1,2014-07-29T15:00:00Z,100,1s,*asr;*acc;*tcc;*acd;*tcd;*pdd,true,true,20,2,THRESH1;THRESH2
 cgrates.org,Stats2,FLTR_1,2014-07-29T15:00:00Z,100,1s,*asr;*acc;*tcc;*acd;*tcd;*pdd,true,true,20,2,THRESH1;THRESH2
-cgrates.org,Stats3,FLTR_1,2014-07-29T15:00:00Z,100,1s,*asr;*acc;*tcc;*acd;*tcd;*pdd,true,true,20,2,THRESH1;THRESH2
+cgrates.org,Stats3,FLTR_1,2014-07-29T15:00:00Z,100,1s,*asr;*acc;*tcc;*acd;*tcd;*pdd,true,true,20,2,THRESH1;THRESH2,*daily,UTC,7
 `
 
 	thresholds = `
@@ -289,6 +289,17 @@ cgrates.org,FLTR_1,*rsr_fields,,Subject(~^1.*1$);Destination(1002),
 cgrates.org,FLTR_ACNT_dan,*string,Account,dan,2014-07-29T15:00:00Z
 cgrates.org,FLTR_DST_DE,*destinations,Destination,DST_DE,2014-07-29T15:00:00Z
 cgrates.org,FLTR_DST_NL,*destinations,Destination,DST_NL,2014-07-29T15:00:00Z
+`
+	suppliers = `
+#Tenant[0],ID[1],FilterIDs[2],ActivationInterval[3],Sorting[4],SortingParams[5],SupplierID[6],SupplierFilterIDs[7],SupplierRatingSubject[8],SupplierResourceIDs[9],SupplierStatIDs[10],SupplierWeight[11],SupplierParameters[12],Weight[13]
+cgrates.org,SPP_1,FLTR_ACNT_dan,2014-07-29T15:00:00Z,*qos,*acd;*asr,supplier1,FLTR_DST_DE,rpf_supplier1,RES_SPL1,STATS_SPL1;STATS_SPL1_DAILY,20,,10
+cgrates.org,SPP_1,,,,,supplier2,,,,STATS_SPL2,10,gw2,
+`
+	exchangeRates = `
+#FromCurrency[0],ToCurrency[1],Rate[2]
+EUR,USD,1.1
+USD,RON,4.2
+EUR,USD,1.2
 `
 )
 
@@ -296,7 +307,7 @@ var csvr *TpReader
 
 func init() {
 	csvr = NewTpReader(dm.dataDB, NewStringCSVStorage(',', destinations, timings, rates, destinationRates, ratingPlans, ratingProfiles,
-		sharedGroups, lcrs, actions, actionPlans, actionTriggers, accountActions, derivedCharges, cdrStats, users, aliases, resProfiles, stats, thresholds, filters), testTPID, "")
+		sharedGroups, lcrs, actions, actionPlans, actionTriggers, accountActions, derivedCharges, cdrStats, users, aliases, resProfiles, stats, thresholds, filters, suppliers, exchangeRates), testTPID, "")
 
 	if err := csvr.LoadDestinations(); err != nil {
 		log.Print("error in LoadDestinations:", err)
@@ -358,6 +369,12 @@ func init() {
 	if err := csvr.LoadThresholds(); err != nil {
 		log.Print("error in LoadThresholds:", err)
 	}
+	if err := csvr.LoadSupplierProfiles(); err != nil {
+		log.Print("error in LoadSupplierProfiles:", err)
+	}
+	if err := csvr.LoadExchangeRates(); err != nil {
+		log.Print("error in LoadExchangeRates:", err)
+	}
 	csvr.WriteToDatabase(false, false, false)
 	cache.Flush()
 	dm.LoadDataDBCache(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
@@ -1486,14 +1503,17 @@ func TestLoadStatProfiles(t *testing.T) {
 			ActivationInterval: &utils.TPActivationInterval{
 				ActivationTime: "2014-07-29T15:00:00Z",
 			},
-			QueueLength: 100,
-			TTL:         "1s",
-			Metrics:     []string{"*asr", "*acc", "*tcc", "*acd", "*tcd", "*pdd"},
-			Thresholds:  []string{"THRESH1", "THRESH2"},
-			Blocker:     true,
-			Stored:      true,
-			Weight:      20,
-			MinItems:    2,
+			QueueLength:    100,
+			TTL:            "1s",
+			Metrics:        []string{"*asr", "*acc", "*tcc", "*acd", "*tcd", "*pdd"},
+			Thresholds:     []string{"THRESH1", "THRESH2"},
+			Blocker:        true,
+			Stored:         true,
+			Weight:         20,
+			MinItems:       2,
+			Bucket:         "*daily",
+			BucketTimezone: "UTC",
+			BucketHistory:  7,
 		},
 	}
 	stKey := utils.TenantID{Tenant: "cgrates.org", ID: "Stats1"}
@@ -1502,6 +1522,10 @@ func TestLoadStatProfiles(t *testing.T) {
 	} else if !reflect.DeepEqual(eStats[stKey], csvr.sqProfiles[stKey]) {
 		t.Errorf("Expecting: %+v, received: %+v", eStats[stKey], csvr.sqProfiles[stKey])
 	}
+	stKey = utils.TenantID{Tenant: "cgrates.org", ID: "Stats3"}
+	if !reflect.DeepEqual(eStats[stKey], csvr.sqProfiles[stKey]) {
+		t.Errorf("Expecting: %+v, received: %+v", eStats[stKey], csvr.sqProfiles[stKey])
+	}
 }
 
 func TestLoadThresholdProfiles(t *testing.T) {
@@ -1531,6 +1555,64 @@ func TestLoadThresholdProfiles(t *testing.T) {
 	}
 }
 
+func TestLoadSupplierProfiles(t *testing.T) {
+	eSPP := &utils.TPSupplierProfile{
+		TPid:      testTPID,
+		Tenant:    "cgrates.org",
+		ID:        "SPP_1",
+		FilterIDs: []string{"FLTR_ACNT_dan"},
+		ActivationInterval: &utils.TPActivationInterval{
+			ActivationTime: "2014-07-29T15:00:00Z",
+		},
+		Sorting:       utils.MetaQOS,
+		SortingParams: []string{utils.MetaACD, utils.MetaASR},
+		Suppliers: []*utils.TPSupplier{
+			&utils.TPSupplier{
+				ID:            "supplier1",
+				FilterIDs:     []string{"FLTR_DST_DE"},
+				RatingSubject: "rpf_supplier1",
+				ResourceIDs:   []string{"RES_SPL1"},
+				StatIDs:       []string{"STATS_SPL1", "STATS_SPL1_DAILY"},
+				Weight:        20,
+			},
+			&utils.TPSupplier{
+				ID:                 "supplier2",
+				StatIDs:            []string{"STATS_SPL2"},
+				Weight:             10,
+				SupplierParameters: "gw2",
+			},
+		},
+		Weight: 10,
+	}
+	sppKey := utils.TenantID{Tenant: "cgrates.org", ID: "SPP_1"}
+	if len(csvr.sppProfiles) != 1 {
+		t.Errorf("Failed to load SupplierProfiles: %s", utils.ToIJSON(csvr.sppProfiles))
+	} else if !reflect.DeepEqual(eSPP, csvr.sppProfiles[sppKey]) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eSPP), utils.ToJSON(csvr.sppProfiles[sppKey]))
+	}
+	if spp, err := dm.GetSupplierProfile("cgrates.org", "SPP_1", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if len(spp.Suppliers) != 2 || spp.Suppliers[0].ratingSubject() != "rpf_supplier1" ||
+		spp.Suppliers[1].ratingSubject() != "supplier2" {
+		t.Errorf("Unexpected SupplierProfile: %s", utils.ToJSON(spp))
+	}
+}
+
+func TestLoadExchangeRates(t *testing.T) {
+	eXRs := map[string]*utils.TPExchangeRate{
+		"EUR:USD": &utils.TPExchangeRate{TPid: testTPID, FromCurrency: "EUR", ToCurrency: "USD", Rate: 1.2},
+		"USD:RON": &utils.TPExchangeRate{TPid: testTPID, FromCurrency: "USD", ToCurrency: "RON", Rate: 4.2},
+	}
+	if !reflect.DeepEqual(eXRs, csvr.exchangeRates) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eXRs), utils.ToJSON(csvr.exchangeRates))
+	}
+	if xr, err := dm.GetExchangeRate("EUR", "USD", true, utils.NonTransactional); err != nil {
+		t.Error(err)
+	} else if xr.Rate != 1.2 {
+		t.Errorf("Unexpected ExchangeRate: %s", utils.ToJSON(xr))
+	}
+}
+
 func TestLoadFilters(t *testing.T) {
 	eFilters := map[utils.TenantID]*utils.TPFilter{
 		utils.TenantID{Tenant: "cgrates.org", ID: "FLTR_1"}: &utils.TPFilter{
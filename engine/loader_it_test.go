@@ -108,6 +108,8 @@ func TestLoaderITLoadFromCSV(t *testing.T) {
 		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.StatsCsv),
 		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.ThresholdsCsv),
 		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.FiltersCsv),
+		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.SuppliersCsv),
+		path.Join(*dataDir, "tariffplans", *tpCsvScenario, utils.ExchangeRatesCsv),
 	), "", "")
 
 	if err = loader.LoadDestinations(); err != nil {
@@ -40,6 +40,9 @@ func csvLoad(s interface{}, values []string) (interface{}, error) {
 		index := field.Tag.Get("index")
 		if index != "" {
 			idx, err := strconv.Atoi(index)
+			if err == nil && len(values) <= idx && field.Tag.Get("optional") == "true" {
+				continue // trailing column missing out of older files
+			}
 			if err != nil || len(values) <= idx {
 				return nil, fmt.Errorf("invalid %v.%v index %v", st.Name(), field.Name, index)
 			}
@@ -411,6 +414,7 @@ func (tps TpDestinationRates) AsMapDestinationRates() (map[string]*utils.TPDesti
 					RoundingDecimals: tp.RoundingDecimals,
 					MaxCost:          tp.MaxCost,
 					MaxCostStrategy:  tp.MaxCostStrategy,
+					Currency:         tp.Currency,
 				},
 			},
 		}
@@ -460,6 +464,7 @@ func APItoModelDestinationRate(d *utils.TPDestinationRate) (result TpDestination
 				RoundingDecimals: dr.RoundingDecimals,
 				MaxCost:          dr.MaxCost,
 				MaxCostStrategy:  dr.MaxCostStrategy,
+				Currency:         dr.Currency,
 			})
 		}
 		if len(d.DestinationRates) == 0 {
@@ -535,6 +540,7 @@ func GetRateInterval(rpl *utils.TPRatingPlanBinding, dr *utils.DestinationRate)
 			RoundingDecimals: dr.RoundingDecimals,
 			MaxCost:          dr.MaxCost,
 			MaxCostStrategy:  dr.MaxCostStrategy,
+			Currency:         dr.Currency,
 			tag:              dr.Rate.ID,
 		},
 	}
@@ -1994,6 +2000,15 @@ func (tps TpStatsS) AsTPStats() (result []*utils.TPStats) {
 		if tp.Weight != 0 {
 			st.Weight = tp.Weight
 		}
+		if tp.Bucket != "" {
+			st.Bucket = tp.Bucket
+		}
+		if tp.BucketTimezone != "" {
+			st.BucketTimezone = tp.BucketTimezone
+		}
+		if tp.BucketHistory != 0 {
+			st.BucketHistory = tp.BucketHistory
+		}
 		if len(tp.ActivationInterval) != 0 {
 			st.ActivationInterval = new(utils.TPActivationInterval)
 			aiSplt := strings.Split(tp.ActivationInterval, utils.INFIELD_SEP)
@@ -2038,6 +2053,9 @@ func APItoModelStats(st *utils.TPStats) (mdls TpStatsS) {
 				mdl.Weight = st.Weight
 				mdl.QueueLength = st.QueueLength
 				mdl.MinItems = st.MinItems
+				mdl.Bucket = st.Bucket
+				mdl.BucketTimezone = st.BucketTimezone
+				mdl.BucketHistory = st.BucketHistory
 				for i, val := range st.Metrics {
 					if i != 0 {
 						mdl.Metrics += utils.INFIELD_SEP
@@ -2068,13 +2086,16 @@ func APItoModelStats(st *utils.TPStats) (mdls TpStatsS) {
 
 func APItoStats(tpST *utils.TPStats, timezone string) (st *StatQueueProfile, err error) {
 	st = &StatQueueProfile{
-		Tenant:      tpST.Tenant,
-		ID:          tpST.ID,
-		QueueLength: tpST.QueueLength,
-		Weight:      tpST.Weight,
-		Blocker:     tpST.Blocker,
-		Stored:      tpST.Stored,
-		MinItems:    tpST.MinItems,
+		Tenant:         tpST.Tenant,
+		ID:             tpST.ID,
+		QueueLength:    tpST.QueueLength,
+		Weight:         tpST.Weight,
+		Blocker:        tpST.Blocker,
+		Stored:         tpST.Stored,
+		MinItems:       tpST.MinItems,
+		Bucket:         tpST.Bucket,
+		BucketTimezone: tpST.BucketTimezone,
+		BucketHistory:  tpST.BucketHistory,
 	}
 	if tpST.TTL != "" {
 		if st.TTL, err = utils.ParseDurationWithSecs(tpST.TTL); err != nil {
@@ -2419,3 +2440,205 @@ func FilterToTPFilter(f *Filter) (tpFltr *utils.TPFilter) {
 	}
 	return
 }
+
+type TpSuppliers []*TpSupplier
+
+func (tps TpSuppliers) AsTPSuppliers() (result []*utils.TPSupplierProfile) {
+	mst := make(map[utils.TenantID]*utils.TPSupplierProfile)
+	splIdx := make(map[utils.TenantID]map[string]*utils.TPSupplier) // keep order of the suppliers as defined
+	for _, tp := range tps {
+		tntID := utils.TenantID{Tenant: tp.Tenant, ID: tp.ID}
+		spp, found := mst[tntID]
+		if !found {
+			spp = &utils.TPSupplierProfile{
+				TPid:   tp.Tpid,
+				Tenant: tp.Tenant,
+				ID:     tp.ID,
+			}
+			mst[tntID] = spp
+			splIdx[tntID] = make(map[string]*utils.TPSupplier)
+		}
+		if tp.Sorting != "" {
+			spp.Sorting = tp.Sorting
+		}
+		if tp.SortingParams != "" {
+			spp.SortingParams = append(spp.SortingParams,
+				strings.Split(tp.SortingParams, utils.INFIELD_SEP)...)
+		}
+		if tp.Weight != 0 {
+			spp.Weight = tp.Weight
+		}
+		if len(tp.ActivationInterval) != 0 {
+			spp.ActivationInterval = new(utils.TPActivationInterval)
+			aiSplt := strings.Split(tp.ActivationInterval, utils.INFIELD_SEP)
+			if len(aiSplt) == 2 {
+				spp.ActivationInterval.ActivationTime = aiSplt[0]
+				spp.ActivationInterval.ExpiryTime = aiSplt[1]
+			} else if len(aiSplt) == 1 {
+				spp.ActivationInterval.ActivationTime = aiSplt[0]
+			}
+		}
+		if tp.FilterIDs != "" {
+			spp.FilterIDs = append(spp.FilterIDs,
+				strings.Split(tp.FilterIDs, utils.INFIELD_SEP)...)
+		}
+		if tp.SupplierID == "" {
+			continue
+		}
+		spl, has := splIdx[tntID][tp.SupplierID]
+		if !has {
+			spl = &utils.TPSupplier{ID: tp.SupplierID}
+			splIdx[tntID][tp.SupplierID] = spl
+			spp.Suppliers = append(spp.Suppliers, spl)
+		}
+		if tp.SupplierFilterIDs != "" {
+			spl.FilterIDs = append(spl.FilterIDs,
+				strings.Split(tp.SupplierFilterIDs, utils.INFIELD_SEP)...)
+		}
+		if tp.SupplierRatingSubject != "" {
+			spl.RatingSubject = tp.SupplierRatingSubject
+		}
+		if tp.SupplierResourceIDs != "" {
+			spl.ResourceIDs = append(spl.ResourceIDs,
+				strings.Split(tp.SupplierResourceIDs, utils.INFIELD_SEP)...)
+		}
+		if tp.SupplierStatIDs != "" {
+			spl.StatIDs = append(spl.StatIDs,
+				strings.Split(tp.SupplierStatIDs, utils.INFIELD_SEP)...)
+		}
+		if tp.SupplierWeight != 0 {
+			spl.Weight = tp.SupplierWeight
+		}
+		if tp.SupplierParameters != "" {
+			spl.SupplierParameters = tp.SupplierParameters
+		}
+	}
+	result = make([]*utils.TPSupplierProfile, len(mst))
+	i := 0
+	for _, spp := range mst {
+		result[i] = spp
+		i++
+	}
+	return
+}
+
+// APItoModelTPSuppliers converts a TPSupplierProfile into models, one for each supplier
+func APItoModelTPSuppliers(spp *utils.TPSupplierProfile) (mdls TpSuppliers) {
+	if spp == nil {
+		return
+	}
+	mdl := &TpSupplier{
+		Tpid:          spp.TPid,
+		Tenant:        spp.Tenant,
+		ID:            spp.ID,
+		FilterIDs:     strings.Join(spp.FilterIDs, utils.INFIELD_SEP),
+		Sorting:       spp.Sorting,
+		SortingParams: strings.Join(spp.SortingParams, utils.INFIELD_SEP),
+		Weight:        spp.Weight,
+	}
+	if spp.ActivationInterval != nil {
+		if spp.ActivationInterval.ActivationTime != "" {
+			mdl.ActivationInterval = spp.ActivationInterval.ActivationTime
+		}
+		if spp.ActivationInterval.ExpiryTime != "" {
+			mdl.ActivationInterval += utils.INFIELD_SEP + spp.ActivationInterval.ExpiryTime
+		}
+	}
+	if len(spp.Suppliers) == 0 {
+		return TpSuppliers{mdl}
+	}
+	for i, spl := range spp.Suppliers {
+		if i != 0 { // profile details only on the first line
+			mdl = &TpSupplier{
+				Tpid:   spp.TPid,
+				Tenant: spp.Tenant,
+				ID:     spp.ID,
+			}
+		}
+		mdl.SupplierID = spl.ID
+		mdl.SupplierFilterIDs = strings.Join(spl.FilterIDs, utils.INFIELD_SEP)
+		mdl.SupplierRatingSubject = spl.RatingSubject
+		mdl.SupplierResourceIDs = strings.Join(spl.ResourceIDs, utils.INFIELD_SEP)
+		mdl.SupplierStatIDs = strings.Join(spl.StatIDs, utils.INFIELD_SEP)
+		mdl.SupplierWeight = spl.Weight
+		mdl.SupplierParameters = spl.SupplierParameters
+		mdls = append(mdls, mdl)
+	}
+	return
+}
+
+func APItoSupplierProfile(tpSPP *utils.TPSupplierProfile, timezone string) (spp *SupplierProfile, err error) {
+	spp = &SupplierProfile{
+		Tenant:        tpSPP.Tenant,
+		ID:            tpSPP.ID,
+		FilterIDs:     make([]string, len(tpSPP.FilterIDs)),
+		Sorting:       tpSPP.Sorting,
+		SortingParams: make([]string, len(tpSPP.SortingParams)),
+		Suppliers:     make([]*Supplier, len(tpSPP.Suppliers)),
+		Weight:        tpSPP.Weight,
+	}
+	copy(spp.FilterIDs, tpSPP.FilterIDs)
+	copy(spp.SortingParams, tpSPP.SortingParams)
+	for i, tpSpl := range tpSPP.Suppliers {
+		spp.Suppliers[i] = &Supplier{
+			ID:                 tpSpl.ID,
+			FilterIDs:          make([]string, len(tpSpl.FilterIDs)),
+			RatingSubject:      tpSpl.RatingSubject,
+			ResourceIDs:        make([]string, len(tpSpl.ResourceIDs)),
+			StatIDs:            make([]string, len(tpSpl.StatIDs)),
+			Weight:             tpSpl.Weight,
+			SupplierParameters: tpSpl.SupplierParameters,
+		}
+		copy(spp.Suppliers[i].FilterIDs, tpSpl.FilterIDs)
+		copy(spp.Suppliers[i].ResourceIDs, tpSpl.ResourceIDs)
+		copy(spp.Suppliers[i].StatIDs, tpSpl.StatIDs)
+	}
+	if tpSPP.ActivationInterval != nil {
+		if spp.ActivationInterval, err = tpSPP.ActivationInterval.AsActivationInterval(timezone); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+type TpExchangeRates []*TpExchangeRate
+
+func (tps TpExchangeRates) AsTPExchangeRates() (result []*utils.TPExchangeRate) {
+	mxr := make(map[string]*utils.TPExchangeRate)
+	var keys []string // keep the order of the source
+	for _, tp := range tps {
+		key := utils.ConcatenatedKey(tp.FromCurrency, tp.ToCurrency)
+		if _, has := mxr[key]; !has {
+			keys = append(keys, key)
+		}
+		mxr[key] = &utils.TPExchangeRate{ // last definition wins
+			TPid:         tp.Tpid,
+			FromCurrency: tp.FromCurrency,
+			ToCurrency:   tp.ToCurrency,
+			Rate:         tp.Rate,
+		}
+	}
+	result = make([]*utils.TPExchangeRate, len(keys))
+	for i, key := range keys {
+		result[i] = mxr[key]
+	}
+	return
+}
+
+func APItoModelTPExchangeRate(tpXR *utils.TPExchangeRate) *TpExchangeRate {
+	return &TpExchangeRate{
+		Tpid:         tpXR.TPid,
+		FromCurrency: tpXR.FromCurrency,
+		ToCurrency:   tpXR.ToCurrency,
+		Rate:         tpXR.Rate,
+	}
+}
+
+func APItoExchangeRate(tpXR *utils.TPExchangeRate) (xr *ExchangeRate, err error) {
+	if tpXR.Rate <= 0 {
+		return nil, fmt.Errorf("invalid rate %v for exchange rate %s",
+			tpXR.Rate, utils.ConcatenatedKey(tpXR.FromCurrency, tpXR.ToCurrency))
+	}
+	return &ExchangeRate{FromCurrency: tpXR.FromCurrency,
+		ToCurrency: tpXR.ToCurrency, Rate: tpXR.Rate}, nil
+}
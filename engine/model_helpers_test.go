@@ -41,6 +41,22 @@ func TestModelHelperCsvLoadInt(t *testing.T) {
 	}
 }
 
+func TestModelHelperCsvLoadOptional(t *testing.T) {
+	l, err := csvLoad(TpDestinationRate{}, []string{"DR_1", "DST_1", "RT_1", "*middle", "4", "0", ""})
+	tpdr, ok := l.(TpDestinationRate)
+	if err != nil || !ok || tpdr.RatesTag != "RT_1" || tpdr.Currency != "" {
+		t.Errorf("model load failed: %+v, err: %v", tpdr, err)
+	}
+	l, err = csvLoad(TpDestinationRate{}, []string{"DR_1", "DST_1", "RT_1", "*middle", "4", "0", "", "EUR"})
+	tpdr, ok = l.(TpDestinationRate)
+	if err != nil || !ok || tpdr.Currency != "EUR" {
+		t.Errorf("model load failed: %+v, err: %v", tpdr, err)
+	}
+	if _, err = csvLoad(TpDestinationRate{}, []string{"DR_1", "DST_1", "RT_1", "*middle", "4", "0"}); err == nil {
+		t.Error("expecting error on missing mandatory column")
+	}
+}
+
 func TestModelHelperCsvDump(t *testing.T) {
 	tpd := TpDestination{
 		Tag:    "TEST_DEST",
@@ -823,6 +839,9 @@ func TestTPStatsAsTPStats(t *testing.T) {
 			Stored:             false,
 			Blocker:            false,
 			Weight:             20.0,
+			Bucket:             "*hourly",
+			BucketTimezone:     "UTC",
+			BucketHistory:      24,
 		},
 	}
 	eTPs := []*utils.TPStats{
@@ -833,14 +852,17 @@ func TestTPStatsAsTPStats(t *testing.T) {
 			ActivationInterval: &utils.TPActivationInterval{
 				ActivationTime: tps[0].ActivationInterval,
 			},
-			QueueLength: tps[0].QueueLength,
-			TTL:         tps[0].TTL,
-			Metrics:     []string{"*asr", "*acd", "*acc"},
-			MinItems:    tps[0].MinItems,
-			Thresholds:  []string{"THRESH1", "THRESH2"},
-			Stored:      tps[0].Stored,
-			Blocker:     tps[0].Blocker,
-			Weight:      tps[0].Weight,
+			QueueLength:    tps[0].QueueLength,
+			TTL:            tps[0].TTL,
+			Metrics:        []string{"*asr", "*acd", "*acc"},
+			MinItems:       tps[0].MinItems,
+			Thresholds:     []string{"THRESH1", "THRESH2"},
+			Stored:         tps[0].Stored,
+			Blocker:        tps[0].Blocker,
+			Weight:         tps[0].Weight,
+			Bucket:         tps[0].Bucket,
+			BucketTimezone: tps[0].BucketTimezone,
+			BucketHistory:  tps[0].BucketHistory,
 		},
 	}
 	rcvTPs := TpStatsS(tps).AsTPStats()
@@ -863,17 +885,23 @@ func TestAPItoTPStats(t *testing.T) {
 		Stored:             false,
 		Blocker:            false,
 		Weight:             20.0,
+		Bucket:             "*hourly",
+		BucketTimezone:     "UTC",
+		BucketHistory:      24,
 	}
 
 	eTPs := &StatQueueProfile{ID: tps.ID,
-		QueueLength: tps.QueueLength,
-		Metrics:     []string{"*asr", "*acd", "*acc"},
-		Thresholds:  []string{"THRESH1", "THRESH2"},
-		FilterIDs:   []string{"FLTR_1"},
-		Stored:      tps.Stored,
-		Blocker:     tps.Blocker,
-		Weight:      20.0,
-		MinItems:    tps.MinItems,
+		QueueLength:    tps.QueueLength,
+		Metrics:        []string{"*asr", "*acd", "*acc"},
+		Thresholds:     []string{"THRESH1", "THRESH2"},
+		FilterIDs:      []string{"FLTR_1"},
+		Stored:         tps.Stored,
+		Blocker:        tps.Blocker,
+		Weight:         20.0,
+		MinItems:       tps.MinItems,
+		Bucket:         tps.Bucket,
+		BucketTimezone: tps.BucketTimezone,
+		BucketHistory:  tps.BucketHistory,
 	}
 	if eTPs.TTL, err = utils.ParseDurationWithSecs(tps.TTL); err != nil {
 		t.Errorf("Got error: %+v", err)
@@ -71,6 +71,7 @@ type TpDestinationRate struct {
 	RoundingDecimals int     `index:"4" re:"\d+"`
 	MaxCost          float64 `index:"5" re:"\d+\.*\d*s*"`
 	MaxCostStrategy  string  `index:"6" re:"\*free|\*disconnect"`
+	Currency         string  `index:"7" re:"\w*" optional:"true"` // empty for the currency of the balance paying it
 	CreatedAt        time.Time
 }
 
@@ -493,6 +494,9 @@ type TpStats struct {
 	Weight             float64 `index:"9" re:"\d+\.?\d*"`
 	MinItems           int     `index:"10" re:""`
 	Thresholds         string  `index:"11" re:""`
+	Bucket             string  `index:"12" re:"" optional:"true"`
+	BucketTimezone     string  `index:"13" re:"" optional:"true"`
+	BucketHistory      int     `index:"14" re:"" optional:"true"`
 	CreatedAt          time.Time
 }
 
@@ -513,6 +517,35 @@ type TpThreshold struct {
 	CreatedAt          time.Time
 }
 
+type TpSupplier struct {
+	PK                    uint `gorm:"primary_key"`
+	Tpid                  string
+	Tenant                string  `index:"0" re:""`
+	ID                    string  `index:"1" re:""`
+	FilterIDs             string  `index:"2" re:""`
+	ActivationInterval    string  `index:"3" re:""`
+	Sorting               string  `index:"4" re:""`
+	SortingParams         string  `index:"5" re:""`
+	SupplierID            string  `index:"6" re:""`
+	SupplierFilterIDs     string  `index:"7" re:""`
+	SupplierRatingSubject string  `index:"8" re:""`
+	SupplierResourceIDs   string  `index:"9" re:""`
+	SupplierStatIDs       string  `index:"10" re:""`
+	SupplierWeight        float64 `index:"11" re:""`
+	SupplierParameters    string  `index:"12" re:""`
+	Weight                float64 `index:"13" re:""`
+	CreatedAt             time.Time
+}
+
+type TpExchangeRate struct {
+	PK           uint `gorm:"primary_key"`
+	Tpid         string
+	FromCurrency string  `index:"0" re:"\w+"`
+	ToCurrency   string  `index:"1" re:"\w+"`
+	Rate         float64 `index:"2" re:"\d+\.*\d*"`
+	CreatedAt    time.Time
+}
+
 type TpFilter struct {
 	PK                 uint `gorm:"primary_key"`
 	Tpid               string
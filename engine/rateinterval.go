@@ -214,12 +214,16 @@ type RIRate struct {
 	RoundingDecimals int
 	MaxCost          float64
 	MaxCostStrategy  string
+	Currency         string     // currency the rates are expressed in, empty for the default one
 	Rates            RateGroups // GroupRateInterval (start time): Rate
 	tag              string     // loading validation only
 }
 
 func (rir *RIRate) Stringify() string {
 	str := fmt.Sprintf("%v %v %v %v %v", rir.ConnectFee, rir.RoundingMethod, rir.RoundingDecimals, rir.MaxCost, rir.MaxCostStrategy)
+	if rir.Currency != "" { // keep the old IDs for rates without currency
+		str += " " + rir.Currency
+	}
 	for _, r := range rir.Rates {
 		str += r.Stringify()
 	}
@@ -348,11 +352,16 @@ func (i *RateInterval) Equal(o *RateInterval) bool {
 }
 
 func (i *RateInterval) GetCost(duration, startSecond time.Duration) float64 {
+	return i.getCostDecimal(duration, startSecond).Float64()
+}
+
+// getCostDecimal computes the cost avoiding float errors on price per rate unit
+func (i *RateInterval) getCostDecimal(duration, startSecond time.Duration) *utils.Decimal {
 	price, _, rateUnit := i.
 		GetRateParameters(startSecond)
-	price /= rateUnit.Seconds()
-	d := duration.Seconds()
-	return d * price
+	return utils.NewDecimalFromFloat64(price).
+		Mul(utils.NewDecimalFromDuration(duration)).
+		Div(utils.NewDecimalFromDuration(rateUnit))
 }
 
 // Gets the price for a the provided start second
@@ -646,3 +646,78 @@ func (rS *ResourceService) V1ReleaseResource(args utils.ArgRSv1ResourceUsage, re
 	*reply = utils.OK
 	return nil
 }
+
+// activeUnits returns the units used by the active usages of a resource
+func (rS *ResourceService) activeUnits(tntID *utils.TenantID) (units float64, err error) {
+	lockID := utils.ResourcesPrefix + tntID.TenantID()
+	guardian.Guardian.GuardIDs(config.CgrConfig().LockingTimeout, lockID)
+	defer guardian.Guardian.UnguardIDs(lockID)
+	r, err := rS.dm.GetResource(tntID.Tenant, tntID.ID, false, "")
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, ru := range r.Usages {
+		if ru.isActive(now) { // expired usages are not yet cleaned
+			units += ru.Units
+		}
+	}
+	return
+}
+
+// V1GetResourceAvailability returns the units still available on a resource
+func (rS *ResourceService) V1GetResourceAvailability(args *utils.TenantID, reply *float64) (err error) {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	rPrf, err := rS.dm.GetResourceProfile(args.Tenant, args.ID, false, utils.NonTransactional)
+	if err != nil {
+		return err
+	}
+	units, err := rS.activeUnits(args)
+	if err != nil {
+		return err
+	}
+	*reply = rPrf.Limit - units
+	return
+}
+
+// V1GetResourceUsage returns the units used by the active usages of a resource
+func (rS *ResourceService) V1GetResourceUsage(args *utils.TenantID, reply *float64) (err error) {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	units, err := rS.activeUnits(args)
+	if err != nil {
+		return err
+	}
+	*reply = units
+	return
+}
+
+// Metrics implements utils.MetricsCollector, exporting the usage of the resources in cache
+func (rS *ResourceService) Metrics() []*utils.MetricFamily {
+	usage := &utils.MetricFamily{Name: "cgr_resource_usage", Type: utils.MetricGauge,
+		Help: "Units used by the active usages of a resource"}
+	limit := &utils.MetricFamily{Name: "cgr_resource_limit", Type: utils.MetricGauge,
+		Help: "Configured limit of a resource"}
+	rKeys := cache.GetEntryKeys(utils.ResourcesPrefix)
+	sort.Strings(rKeys)
+	for _, rKey := range rKeys {
+		rIf, has := cache.Get(rKey)
+		if !has || rIf == nil {
+			continue
+		}
+		r := rIf.(*Resource)
+		lbls := map[string]string{"tenant": r.Tenant, "id": r.ID}
+		units, err := rS.activeUnits(&utils.TenantID{Tenant: r.Tenant, ID: r.ID})
+		if err != nil {
+			continue
+		}
+		usage.Samples = append(usage.Samples, &utils.MetricSample{Labels: lbls, Value: units})
+		if rPrf, err := rS.dm.GetResourceProfile(r.Tenant, r.ID, false, utils.NonTransactional); err == nil {
+			limit.Samples = append(limit.Samples, &utils.MetricSample{Labels: lbls, Value: rPrf.Limit})
+		}
+	}
+	return []*utils.MetricFamily{usage, limit}
+}
@@ -124,13 +124,15 @@ func (rs *Responder) Debit(arg *CallDescriptor, reply *CallCost) (err error) {
 		}, arg, utils.EXTRA_FIELDS); err != nil && err != utils.ErrNotFound {
 		return err
 	}
-	r, e := arg.Debit()
-	if e != nil {
-		return e
-	} else if r != nil {
-		*reply = *r
-	}
-	return
+	return ProcessIdempotent(arg.Tenant, arg.Account, "Responder.Debit", arg.IdempotencyKey, arg, reply, func() error {
+		r, e := arg.Debit()
+		if e != nil {
+			return e
+		} else if r != nil {
+			*reply = *r
+		}
+		return nil
+	})
 }
 
 func (rs *Responder) MaxDebit(arg *CallDescriptor, reply *CallCost) (err error) {
@@ -161,14 +163,19 @@ func (rs *Responder) MaxDebit(arg *CallDescriptor, reply *CallCost) (err error)
 		}, arg, utils.EXTRA_FIELDS); err != nil && err != utils.ErrNotFound {
 		return err
 	}
-	r, e := arg.MaxDebit()
-	if e != nil {
+	if e := ProcessIdempotent(arg.Tenant, arg.Account, "Responder.MaxDebit", arg.IdempotencyKey, arg, reply, func() error {
+		r, e := arg.MaxDebit()
+		if e != nil {
+			return e
+		} else if r != nil {
+			*reply = *r
+		}
+		return nil
+	}); e != nil {
 		rs.getCache().Cache(cacheKey, &cache.CacheItem{
 			Err: e,
 		})
 		return e
-	} else if r != nil {
-		*reply = *r
 	}
 	rs.getCache().Cache(cacheKey, &cache.CacheItem{
 		Value: reply,
@@ -208,7 +215,8 @@ func (rs *Responder) RefundIncrements(arg *CallDescriptor, reply *float64) (err
 		})
 		return err
 	}
-	err = arg.RefundIncrements()
+	err = ProcessIdempotent(arg.Tenant, arg.Account, "Responder.RefundIncrements", arg.IdempotencyKey, arg, reply,
+		arg.RefundIncrements)
 	rs.getCache().Cache(cacheKey, &cache.CacheItem{
 		Value: reply,
 		Err:   err,
@@ -530,6 +538,13 @@ func (rs *Responder) GetLCR(attrs *AttrGetLcr, reply *LCRCost) error {
 	return nil
 }
 
+// IncrementLCRCounter applies locally the round-robin counter increase replicated by other RALs
+func (rs *Responder) IncrementLCRCounter(attr *AttrLCRCounter, reply *string) error {
+	lcrCounters.Increment(attr.EntryID, attr.Supplier)
+	*reply = utils.OK
+	return nil
+}
+
 func (rs *Responder) Status(arg string, reply *map[string]interface{}) (err error) {
 	if arg != "" { // Introduce  delay in answer, used in some automated tests
 		if delay, err := utils.ParseDurationWithSecs(arg); err == nil {
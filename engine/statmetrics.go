@@ -19,29 +19,45 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"errors"
 	"fmt"
-	"github.com/cgrates/cgrates/config"
-	"github.com/cgrates/cgrates/utils"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
 )
 
 // NewStatMetric instantiates the StatMetric
-// cfg serves as general purpose container to pass config options to metric
+// metricID can carry extra parameters after the first separator, ie: *sum:Cost
 func NewStatMetric(metricID string, minItems int) (sm StatMetric, err error) {
-	metrics := map[string]func(int) (StatMetric, error){
-		utils.MetaASR: NewASR,
-		utils.MetaACD: NewACD,
-		utils.MetaTCD: NewTCD,
-		utils.MetaACC: NewACC,
-		utils.MetaTCC: NewTCC,
-		utils.MetaPDD: NewPDD,
-		utils.MetaDDC: NewDCC,
-	}
-	if _, has := metrics[metricID]; !has {
+	metrics := map[string]func(int, string) (StatMetric, error){
+		utils.MetaASR:             NewASR,
+		utils.MetaACD:             NewACD,
+		utils.MetaTCD:             NewTCD,
+		utils.MetaACC:             NewACC,
+		utils.MetaTCC:             NewTCC,
+		utils.MetaPDD:             NewPDD,
+		utils.MetaDDC:             NewDCC,
+		utils.MetaUsagePercentile: NewStatUsagePercentile,
+		utils.MetaCostPercentile:  NewStatCostPercentile,
+		utils.MetaStdDevACD:       NewStatStdDevACD,
+		utils.MetaStdDevACC:       NewStatStdDevACC,
+		utils.MetaSum:             NewStatSum,
+		utils.MetaDistinct:        NewStatDistinct,
+		utils.MetaAverage:         NewStatAverage,
+	}
+	metricType, extraParams := metricID, ""
+	if idx := strings.Index(metricID, utils.CONCATENATED_KEY_SEP); idx != -1 {
+		metricType, extraParams = metricID[:idx], metricID[idx+1:]
+	}
+	if _, has := metrics[metricType]; !has {
 		return nil, fmt.Errorf("unsupported metric: %s", metricID)
 	}
-	return metrics[metricID](minItems)
+	return metrics[metricType](minItems, extraParams)
 }
 
 // StatMetric is the interface which a metric should implement
@@ -55,7 +71,7 @@ type StatMetric interface {
 	LoadMarshaled(ms Marshaler, marshaled []byte) (err error)
 }
 
-func NewASR(minItems int) (StatMetric, error) {
+func NewASR(minItems int, extraParams string) (StatMetric, error) {
 	return &StatASR{Events: make(map[string]bool), MinItems: minItems}, nil
 }
 
@@ -142,7 +158,7 @@ func (asr *StatASR) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
 	return ms.Unmarshal(marshaled, asr)
 }
 
-func NewACD(minItems int) (StatMetric, error) {
+func NewACD(minItems int, extraParams string) (StatMetric, error) {
 	return &StatACD{Events: make(map[string]time.Duration), MinItems: minItems}, nil
 }
 
@@ -229,7 +245,7 @@ func (acd *StatACD) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
 	return ms.Unmarshal(marshaled, acd)
 }
 
-func NewTCD(minItems int) (StatMetric, error) {
+func NewTCD(minItems int, extraParams string) (StatMetric, error) {
 	return &StatTCD{Events: make(map[string]time.Duration), MinItems: minItems}, nil
 }
 
@@ -318,7 +334,7 @@ func (tcd *StatTCD) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
 	return ms.Unmarshal(marshaled, tcd)
 }
 
-func NewACC(minItems int) (StatMetric, error) {
+func NewACC(minItems int, extraParams string) (StatMetric, error) {
 	return &StatACC{Events: make(map[string]float64), MinItems: minItems}, nil
 }
 
@@ -403,7 +419,7 @@ func (acc *StatACC) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
 	return ms.Unmarshal(marshaled, acc)
 }
 
-func NewTCC(minItems int) (StatMetric, error) {
+func NewTCC(minItems int, extraParams string) (StatMetric, error) {
 	return &StatTCC{Events: make(map[string]float64), MinItems: minItems}, nil
 }
 
@@ -487,7 +503,7 @@ func (tcc *StatTCC) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
 	return ms.Unmarshal(marshaled, tcc)
 }
 
-func NewPDD(minItems int) (StatMetric, error) {
+func NewPDD(minItems int, extraParams string) (StatMetric, error) {
 	return &StatPDD{Events: make(map[string]time.Duration), MinItems: minItems}, nil
 }
 
@@ -575,7 +591,7 @@ func (pdd *StatPDD) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
 	return ms.Unmarshal(marshaled, pdd)
 }
 
-func NewDCC(minItems int) (StatMetric, error) {
+func NewDCC(minItems int, extraParams string) (StatMetric, error) {
 	return &StatDDC{Destinations: make(map[string]utils.StringMap), Events: make(map[string]string), MinItems: minItems}, nil
 }
 
@@ -636,8 +652,631 @@ func (ddc *StatDDC) RemEvent(evTenantID string) (err error) {
 }
 
 func (ddc *StatDDC) Marshal(ms Marshaler) (marshaled []byte, err error) {
-	return ms.Marshal(DDC)
+	return ms.Marshal(ddc)
 }
 func (ddc *StatDDC) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
 	return ms.Unmarshal(marshaled, ddc)
 }
+
+// parsePercentile validates the percentile received as metric parameter, ie: 95 out of *usage_percentile:95
+func parsePercentile(extraParams string) (pctl float64, err error) {
+	if extraParams == "" {
+		return 0, errors.New("missing percentile")
+	}
+	if pctl, err = strconv.ParseFloat(extraParams, 64); err != nil {
+		return
+	}
+	if pctl <= 0 || pctl > 100 {
+		return 0, fmt.Errorf("invalid percentile: %s", extraParams)
+	}
+	return
+}
+
+// percentileIndex returns the nearest-rank index of the percentile inside a sorted list of length n
+func percentileIndex(pctl float64, n int) (idx int) {
+	if idx = int(math.Ceil(pctl/100*float64(n))) - 1; idx < 0 {
+		idx = 0
+	}
+	return
+}
+
+// stdDev returns the population standard deviation of values
+func stdDev(values []float64) float64 {
+	var sum float64
+	for _, val := range values {
+		sum += val
+	}
+	mean := sum / float64(len(values))
+	var sqDiffs float64
+	for _, val := range values {
+		sqDiffs += (val - mean) * (val - mean)
+	}
+	return math.Sqrt(sqDiffs / float64(len(values)))
+}
+
+func NewStatUsagePercentile(minItems int, extraParams string) (StatMetric, error) {
+	pctl, err := parsePercentile(extraParams)
+	if err != nil {
+		return nil, err
+	}
+	return &StatUsagePercentile{Events: make(map[string]time.Duration),
+		MinItems: minItems, Percentile: pctl}, nil
+}
+
+// StatUsagePercentile implements the percentile of call durations metric (ie: P95)
+type StatUsagePercentile struct {
+	Percentile float64
+	Events     map[string]time.Duration // map[EventTenantID]Duration
+	MinItems   int
+	val        *time.Duration // cached percentile value
+}
+
+// getValue returns up.val
+func (up *StatUsagePercentile) getValue() time.Duration {
+	if up.val == nil {
+		if (up.MinItems > 0 && len(up.Events) < up.MinItems) || (len(up.Events) == 0) {
+			up.val = utils.DurationPointer(time.Duration((-1) * time.Nanosecond))
+		} else {
+			durs := make([]time.Duration, 0, len(up.Events))
+			for _, dur := range up.Events {
+				durs = append(durs, dur)
+			}
+			sort.Slice(durs, func(i, j int) bool { return durs[i] < durs[j] })
+			up.val = utils.DurationPointer(durs[percentileIndex(up.Percentile, len(durs))])
+		}
+	}
+	return *up.val
+}
+
+func (up *StatUsagePercentile) GetStringValue(fmtOpts string) (valStr string) {
+	if val := up.getValue(); val == time.Duration((-1)*time.Nanosecond) {
+		valStr = utils.NOT_AVAILABLE
+	} else {
+		valStr = fmt.Sprintf("%+v", up.getValue())
+	}
+	return
+}
+
+func (up *StatUsagePercentile) GetValue() (v interface{}) {
+	return up.getValue()
+}
+
+func (up *StatUsagePercentile) GetFloat64Value() (v float64) {
+	if val := up.getValue(); val == time.Duration((-1)*time.Nanosecond) {
+		v = -1.0
+	} else {
+		v = up.getValue().Seconds()
+	}
+	return
+}
+
+func (up *StatUsagePercentile) AddEvent(ev *StatEvent) (err error) {
+	var value time.Duration
+	if at, err := ev.AnswerTime(config.CgrConfig().DefaultTimezone); err != nil &&
+		err != utils.ErrNotFound {
+		return err
+	} else if !at.IsZero() {
+		if duration, err := ev.Usage(); err != nil &&
+			err != utils.ErrNotFound {
+			return err
+		} else {
+			value = duration
+		}
+	}
+	up.Events[ev.TenantID()] = value
+	up.val = nil
+	return
+}
+
+func (up *StatUsagePercentile) RemEvent(evTenantID string) (err error) {
+	if _, has := up.Events[evTenantID]; !has {
+		return utils.ErrNotFound
+	}
+	delete(up.Events, evTenantID)
+	up.val = nil
+	return
+}
+
+func (up *StatUsagePercentile) Marshal(ms Marshaler) (marshaled []byte, err error) {
+	return ms.Marshal(up)
+}
+
+func (up *StatUsagePercentile) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
+	return ms.Unmarshal(marshaled, up)
+}
+
+func NewStatCostPercentile(minItems int, extraParams string) (StatMetric, error) {
+	pctl, err := parsePercentile(extraParams)
+	if err != nil {
+		return nil, err
+	}
+	return &StatCostPercentile{Events: make(map[string]float64),
+		MinItems: minItems, Percentile: pctl}, nil
+}
+
+// StatCostPercentile implements the percentile of call costs metric (ie: P95)
+type StatCostPercentile struct {
+	Percentile float64
+	Events     map[string]float64 // map[EventTenantID]Cost
+	MinItems   int
+	val        *float64 // cached percentile value
+}
+
+// getValue returns cp.val
+func (cp *StatCostPercentile) getValue() float64 {
+	if cp.val == nil {
+		if (cp.MinItems > 0 && len(cp.Events) < cp.MinItems) || (len(cp.Events) == 0) {
+			cp.val = utils.Float64Pointer(STATS_NA)
+		} else {
+			costs := make([]float64, 0, len(cp.Events))
+			for _, cost := range cp.Events {
+				costs = append(costs, cost)
+			}
+			sort.Float64s(costs)
+			cp.val = utils.Float64Pointer(utils.Round(costs[percentileIndex(cp.Percentile, len(costs))],
+				config.CgrConfig().RoundingDecimals, utils.ROUNDING_MIDDLE))
+		}
+	}
+	return *cp.val
+}
+
+func (cp *StatCostPercentile) GetStringValue(fmtOpts string) (valStr string) {
+	if val := cp.getValue(); val == STATS_NA {
+		valStr = utils.NOT_AVAILABLE
+	} else {
+		valStr = strconv.FormatFloat(cp.getValue(), 'f', -1, 64)
+	}
+	return
+}
+
+func (cp *StatCostPercentile) GetValue() (v interface{}) {
+	return cp.getValue()
+}
+
+func (cp *StatCostPercentile) GetFloat64Value() (v float64) {
+	return cp.getValue()
+}
+
+func (cp *StatCostPercentile) AddEvent(ev *StatEvent) (err error) {
+	var value float64
+	if at, err := ev.AnswerTime(config.CgrConfig().DefaultTimezone); err != nil &&
+		err != utils.ErrNotFound {
+		return err
+	} else if !at.IsZero() {
+		if cost, err := ev.Cost(); err != nil &&
+			err != utils.ErrNotFound {
+			return err
+		} else if cost >= 0 {
+			value = cost
+		}
+	}
+	cp.Events[ev.TenantID()] = value
+	cp.val = nil
+	return
+}
+
+func (cp *StatCostPercentile) RemEvent(evTenantID string) (err error) {
+	if _, has := cp.Events[evTenantID]; !has {
+		return utils.ErrNotFound
+	}
+	delete(cp.Events, evTenantID)
+	cp.val = nil
+	return
+}
+
+func (cp *StatCostPercentile) Marshal(ms Marshaler) (marshaled []byte, err error) {
+	return ms.Marshal(cp)
+}
+
+func (cp *StatCostPercentile) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
+	return ms.Unmarshal(marshaled, cp)
+}
+
+func NewStatStdDevACD(minItems int, extraParams string) (StatMetric, error) {
+	return &StatStdDevACD{Events: make(map[string]time.Duration), MinItems: minItems}, nil
+}
+
+// StatStdDevACD implements the standard deviation of call durations metric
+// computed over the same events as ACD
+type StatStdDevACD struct {
+	Events   map[string]time.Duration // map[EventTenantID]Duration
+	MinItems int
+	val      *time.Duration // cached standard deviation value
+}
+
+// getValue returns sd.val
+func (sd *StatStdDevACD) getValue() time.Duration {
+	if sd.val == nil {
+		if (sd.MinItems > 0 && len(sd.Events) < sd.MinItems) || (len(sd.Events) == 0) {
+			sd.val = utils.DurationPointer(time.Duration((-1) * time.Nanosecond))
+		} else {
+			values := make([]float64, 0, len(sd.Events))
+			for _, dur := range sd.Events {
+				values = append(values, float64(dur.Nanoseconds()))
+			}
+			sd.val = utils.DurationPointer(time.Duration(stdDev(values)))
+		}
+	}
+	return *sd.val
+}
+
+func (sd *StatStdDevACD) GetStringValue(fmtOpts string) (valStr string) {
+	if val := sd.getValue(); val == time.Duration((-1)*time.Nanosecond) {
+		valStr = utils.NOT_AVAILABLE
+	} else {
+		valStr = fmt.Sprintf("%+v", sd.getValue())
+	}
+	return
+}
+
+func (sd *StatStdDevACD) GetValue() (v interface{}) {
+	return sd.getValue()
+}
+
+func (sd *StatStdDevACD) GetFloat64Value() (v float64) {
+	if val := sd.getValue(); val == time.Duration((-1)*time.Nanosecond) {
+		v = -1.0
+	} else {
+		v = sd.getValue().Seconds()
+	}
+	return
+}
+
+func (sd *StatStdDevACD) AddEvent(ev *StatEvent) (err error) {
+	var value time.Duration
+	if at, err := ev.AnswerTime(config.CgrConfig().DefaultTimezone); err != nil &&
+		err != utils.ErrNotFound {
+		return err
+	} else if !at.IsZero() {
+		if duration, err := ev.Usage(); err != nil &&
+			err != utils.ErrNotFound {
+			return err
+		} else {
+			value = duration
+		}
+	}
+	sd.Events[ev.TenantID()] = value
+	sd.val = nil
+	return
+}
+
+func (sd *StatStdDevACD) RemEvent(evTenantID string) (err error) {
+	if _, has := sd.Events[evTenantID]; !has {
+		return utils.ErrNotFound
+	}
+	delete(sd.Events, evTenantID)
+	sd.val = nil
+	return
+}
+
+func (sd *StatStdDevACD) Marshal(ms Marshaler) (marshaled []byte, err error) {
+	return ms.Marshal(sd)
+}
+
+func (sd *StatStdDevACD) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
+	return ms.Unmarshal(marshaled, sd)
+}
+
+func NewStatStdDevACC(minItems int, extraParams string) (StatMetric, error) {
+	return &StatStdDevACC{Events: make(map[string]float64), MinItems: minItems}, nil
+}
+
+// StatStdDevACC implements the standard deviation of call costs metric
+// computed over the same events as ACC
+type StatStdDevACC struct {
+	Events   map[string]float64 // map[EventTenantID]Cost
+	MinItems int
+	val      *float64 // cached standard deviation value
+}
+
+// getValue returns sd.val
+func (sd *StatStdDevACC) getValue() float64 {
+	if sd.val == nil {
+		if (sd.MinItems > 0 && len(sd.Events) < sd.MinItems) || (len(sd.Events) == 0) {
+			sd.val = utils.Float64Pointer(STATS_NA)
+		} else {
+			values := make([]float64, 0, len(sd.Events))
+			for _, cost := range sd.Events {
+				values = append(values, cost)
+			}
+			sd.val = utils.Float64Pointer(utils.Round(stdDev(values),
+				config.CgrConfig().RoundingDecimals, utils.ROUNDING_MIDDLE))
+		}
+	}
+	return *sd.val
+}
+
+func (sd *StatStdDevACC) GetStringValue(fmtOpts string) (valStr string) {
+	if val := sd.getValue(); val == STATS_NA {
+		valStr = utils.NOT_AVAILABLE
+	} else {
+		valStr = strconv.FormatFloat(sd.getValue(), 'f', -1, 64)
+	}
+	return
+}
+
+func (sd *StatStdDevACC) GetValue() (v interface{}) {
+	return sd.getValue()
+}
+
+func (sd *StatStdDevACC) GetFloat64Value() (v float64) {
+	return sd.getValue()
+}
+
+func (sd *StatStdDevACC) AddEvent(ev *StatEvent) (err error) {
+	var value float64
+	if at, err := ev.AnswerTime(config.CgrConfig().DefaultTimezone); err != nil &&
+		err != utils.ErrNotFound {
+		return err
+	} else if !at.IsZero() {
+		if cost, err := ev.Cost(); err != nil &&
+			err != utils.ErrNotFound {
+			return err
+		} else if cost >= 0 {
+			value = cost
+		}
+	}
+	sd.Events[ev.TenantID()] = value
+	sd.val = nil
+	return
+}
+
+func (sd *StatStdDevACC) RemEvent(evTenantID string) (err error) {
+	if _, has := sd.Events[evTenantID]; !has {
+		return utils.ErrNotFound
+	}
+	delete(sd.Events, evTenantID)
+	sd.val = nil
+	return
+}
+
+func (sd *StatStdDevACC) Marshal(ms Marshaler) (marshaled []byte, err error) {
+	return ms.Marshal(sd)
+}
+
+func (sd *StatStdDevACC) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
+	return ms.Unmarshal(marshaled, sd)
+}
+
+func NewStatSum(minItems int, extraParams string) (StatMetric, error) {
+	if extraParams == "" {
+		return nil, fmt.Errorf("missing field name for metric: %s", utils.MetaSum)
+	}
+	return &StatSum{Events: make(map[string]float64), MinItems: minItems,
+		FieldName: extraParams}, nil
+}
+
+// StatSum implements the sum over a numeric event field, ie: *sum:Cost
+type StatSum struct {
+	FieldName string
+	Sum       float64
+	Events    map[string]float64 // map[EventTenantID]Value
+	MinItems  int
+	val       *float64 // cached sum value
+}
+
+// getValue returns sum.val
+func (sum *StatSum) getValue() float64 {
+	if sum.val == nil {
+		if (sum.MinItems > 0 && len(sum.Events) < sum.MinItems) || (len(sum.Events) == 0) {
+			sum.val = utils.Float64Pointer(STATS_NA)
+		} else {
+			sum.val = utils.Float64Pointer(utils.Round(sum.Sum,
+				config.CgrConfig().RoundingDecimals, utils.ROUNDING_MIDDLE))
+		}
+	}
+	return *sum.val
+}
+
+func (sum *StatSum) GetStringValue(fmtOpts string) (valStr string) {
+	if val := sum.getValue(); val == STATS_NA {
+		valStr = utils.NOT_AVAILABLE
+	} else {
+		valStr = strconv.FormatFloat(sum.getValue(), 'f', -1, 64)
+	}
+	return
+}
+
+func (sum *StatSum) GetValue() (v interface{}) {
+	return sum.getValue()
+}
+
+func (sum *StatSum) GetFloat64Value() (v float64) {
+	return sum.getValue()
+}
+
+func (sum *StatSum) AddEvent(ev *StatEvent) (err error) {
+	if _, has := sum.Events[ev.TenantID()]; has { // replaced events count only once
+		sum.RemEvent(ev.TenantID())
+	}
+	var value float64
+	if value, err = ev.FieldAsFloat64(sum.FieldName); err != nil {
+		return
+	}
+	sum.Events[ev.TenantID()] = value
+	sum.Sum += value
+	sum.val = nil
+	return
+}
+
+func (sum *StatSum) RemEvent(evTenantID string) (err error) {
+	value, has := sum.Events[evTenantID]
+	if !has {
+		return utils.ErrNotFound
+	}
+	sum.Sum -= value
+	delete(sum.Events, evTenantID)
+	sum.val = nil
+	return
+}
+
+func (sum *StatSum) Marshal(ms Marshaler) (marshaled []byte, err error) {
+	return ms.Marshal(sum)
+}
+
+func (sum *StatSum) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
+	return ms.Unmarshal(marshaled, sum)
+}
+
+func NewStatDistinct(minItems int, extraParams string) (StatMetric, error) {
+	if extraParams == "" {
+		return nil, fmt.Errorf("missing field name for metric: %s", utils.MetaDistinct)
+	}
+	return &StatDistinct{FieldValues: make(map[string]utils.StringMap),
+		Events: make(map[string]string), MinItems: minItems, FieldName: extraParams}, nil
+}
+
+// StatDistinct implements the distinct count over an event field, ie: *distinct:Account
+type StatDistinct struct {
+	FieldName   string
+	FieldValues map[string]utils.StringMap // map[FieldValue]map[EventTenantID]bool
+	Events      map[string]string          // map[EventTenantID]FieldValue
+	MinItems    int
+}
+
+func (dst *StatDistinct) GetStringValue(fmtOpts string) (valStr string) {
+	if val := len(dst.FieldValues); (val == 0) || (dst.MinItems > 0 && len(dst.Events) < dst.MinItems) {
+		valStr = utils.NOT_AVAILABLE
+	} else {
+		valStr = fmt.Sprintf("%+v", len(dst.FieldValues))
+	}
+	return
+}
+
+func (dst *StatDistinct) GetValue() (v interface{}) {
+	return len(dst.FieldValues)
+}
+
+func (dst *StatDistinct) GetFloat64Value() (v float64) {
+	if val := len(dst.FieldValues); (val == 0) || (dst.MinItems > 0 && len(dst.Events) < dst.MinItems) {
+		v = -1.0
+	} else {
+		v = float64(len(dst.FieldValues))
+	}
+	return
+}
+
+func (dst *StatDistinct) AddEvent(ev *StatEvent) (err error) {
+	if _, has := dst.Events[ev.TenantID()]; has { // replaced events count with their new value
+		dst.RemEvent(ev.TenantID())
+	}
+	var fldVal string
+	if fldVal, err = ev.FieldAsString(dst.FieldName); err != nil {
+		return err
+	}
+	if _, has := dst.FieldValues[fldVal]; !has {
+		dst.FieldValues[fldVal] = make(utils.StringMap)
+	}
+	dst.FieldValues[fldVal][ev.TenantID()] = true
+	dst.Events[ev.TenantID()] = fldVal
+	return
+}
+
+func (dst *StatDistinct) RemEvent(evTenantID string) (err error) {
+	fldVal, has := dst.Events[evTenantID]
+	if !has {
+		return utils.ErrNotFound
+	}
+	delete(dst.Events, evTenantID)
+	if len(dst.FieldValues[fldVal]) == 1 {
+		delete(dst.FieldValues, fldVal)
+		return
+	}
+	delete(dst.FieldValues[fldVal], evTenantID)
+	return
+}
+
+func (dst *StatDistinct) Marshal(ms Marshaler) (marshaled []byte, err error) {
+	return ms.Marshal(dst)
+}
+
+func (dst *StatDistinct) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
+	return ms.Unmarshal(marshaled, dst)
+}
+
+func NewStatAverage(minItems int, extraParams string) (StatMetric, error) {
+	if extraParams == "" {
+		return nil, fmt.Errorf("missing field name for metric: %s", utils.MetaAverage)
+	}
+	return &StatAverage{Events: make(map[string]float64), MinItems: minItems,
+		FieldName: extraParams}, nil
+}
+
+// StatAverage implements the average over a numeric event field, ie: *average:MOS or *average:Jitter
+// events not carrying the field are not considered
+type StatAverage struct {
+	FieldName string
+	Sum       float64
+	Count     float64
+	Events    map[string]float64 // map[EventTenantID]Value
+	MinItems  int
+	val       *float64 // cached average value
+}
+
+// getValue returns avg.val
+func (avg *StatAverage) getValue() float64 {
+	if avg.val == nil {
+		if (avg.MinItems > 0 && len(avg.Events) < avg.MinItems) || (avg.Count == 0) {
+			avg.val = utils.Float64Pointer(STATS_NA)
+		} else {
+			avg.val = utils.Float64Pointer(utils.Round((avg.Sum / avg.Count),
+				config.CgrConfig().RoundingDecimals, utils.ROUNDING_MIDDLE))
+		}
+	}
+	return *avg.val
+}
+
+func (avg *StatAverage) GetStringValue(fmtOpts string) (valStr string) {
+	if val := avg.getValue(); val == STATS_NA {
+		valStr = utils.NOT_AVAILABLE
+	} else {
+		valStr = strconv.FormatFloat(avg.getValue(), 'f', -1, 64)
+	}
+	return
+}
+
+func (avg *StatAverage) GetValue() (v interface{}) {
+	return avg.getValue()
+}
+
+func (avg *StatAverage) GetFloat64Value() (v float64) {
+	return avg.getValue()
+}
+
+func (avg *StatAverage) AddEvent(ev *StatEvent) (err error) {
+	if _, has := avg.Events[ev.TenantID()]; has { // replaced events count only once
+		avg.RemEvent(ev.TenantID())
+	}
+	var value float64
+	if value, err = ev.FieldAsFloat64(avg.FieldName); err != nil {
+		if err == utils.ErrNotFound { // events without the field are not considered
+			err = nil
+		}
+		return
+	}
+	avg.Events[ev.TenantID()] = value
+	avg.Sum += value
+	avg.Count += 1
+	avg.val = nil
+	return
+}
+
+func (avg *StatAverage) RemEvent(evTenantID string) (err error) {
+	value, has := avg.Events[evTenantID]
+	if !has {
+		return utils.ErrNotFound
+	}
+	avg.Sum -= value
+	avg.Count -= 1
+	delete(avg.Events, evTenantID)
+	avg.val = nil
+	return
+}
+
+func (avg *StatAverage) Marshal(ms Marshaler) (marshaled []byte, err error) {
+	return ms.Marshal(avg)
+}
+
+func (avg *StatAverage) LoadMarshaled(ms Marshaler, marshaled []byte) (err error) {
+	return ms.Unmarshal(marshaled, avg)
+}
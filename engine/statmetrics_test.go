@@ -18,6 +18,8 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package engine
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -25,7 +27,7 @@ import (
 )
 
 func TestASRGetStringValue(t *testing.T) {
-	asr, _ := NewASR(2)
+	asr, _ := NewASR(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC)}}
@@ -74,7 +76,7 @@ func TestASRGetStringValue(t *testing.T) {
 }
 
 func TestASRGetValue(t *testing.T) {
-	asr, _ := NewASR(2)
+	asr, _ := NewASR(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC)}}
@@ -120,7 +122,7 @@ func TestASRGetValue(t *testing.T) {
 }
 
 func TestACDGetStringValue(t *testing.T) {
-	acd, _ := NewACD(2)
+	acd, _ := NewACD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			utils.USAGE:  time.Duration(10 * time.Second),
@@ -180,7 +182,7 @@ func TestACDGetStringValue(t *testing.T) {
 }
 
 func TestACDGetFloat64Value(t *testing.T) {
-	acd, _ := NewACD(2)
+	acd, _ := NewACD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -233,7 +235,7 @@ func TestACDGetFloat64Value(t *testing.T) {
 }
 
 func TestACDGetValue(t *testing.T) {
-	acd, _ := NewACD(2)
+	acd, _ := NewACD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -289,7 +291,7 @@ func TestACDGetValue(t *testing.T) {
 }
 
 func TestTCDGetStringValue(t *testing.T) {
-	tcd, _ := NewTCD(2)
+	tcd, _ := NewTCD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"Usage":      time.Duration(10 * time.Second),
@@ -350,7 +352,7 @@ func TestTCDGetStringValue(t *testing.T) {
 }
 
 func TestTCDGetFloat64Value(t *testing.T) {
-	tcd, _ := NewTCD(2)
+	tcd, _ := NewTCD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -403,7 +405,7 @@ func TestTCDGetFloat64Value(t *testing.T) {
 }
 
 func TestTCDGetValue(t *testing.T) {
-	tcd, _ := NewTCD(2)
+	tcd, _ := NewTCD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -459,7 +461,7 @@ func TestTCDGetValue(t *testing.T) {
 }
 
 func TestACCGetStringValue(t *testing.T) {
-	acc, _ := NewACC(2)
+	acc, _ := NewACC(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -511,7 +513,7 @@ func TestACCGetStringValue(t *testing.T) {
 }
 
 func TestACCGetValue(t *testing.T) {
-	acc, _ := NewACC(2)
+	acc, _ := NewACC(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -560,7 +562,7 @@ func TestACCGetValue(t *testing.T) {
 }
 
 func TestTCCGetStringValue(t *testing.T) {
-	tcc, _ := NewTCC(2)
+	tcc, _ := NewTCC(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -612,7 +614,7 @@ func TestTCCGetStringValue(t *testing.T) {
 }
 
 func TestTCCGetValue(t *testing.T) {
-	tcc, _ := NewTCC(2)
+	tcc, _ := NewTCC(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -664,7 +666,7 @@ func TestTCCGetValue(t *testing.T) {
 }
 
 func TestPDDGetStringValue(t *testing.T) {
-	pdd, _ := NewPDD(2)
+	pdd, _ := NewPDD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			utils.USAGE:  time.Duration(10 * time.Second),
@@ -726,7 +728,7 @@ func TestPDDGetStringValue(t *testing.T) {
 }
 
 func TestPDDGetFloat64Value(t *testing.T) {
-	pdd, _ := NewPDD(2)
+	pdd, _ := NewPDD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -781,7 +783,7 @@ func TestPDDGetFloat64Value(t *testing.T) {
 }
 
 func TestPDDGetValue(t *testing.T) {
-	pdd, _ := NewPDD(2)
+	pdd, _ := NewPDD(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -840,7 +842,7 @@ func TestPDDGetValue(t *testing.T) {
 }
 
 func TestDDCGetStringValue(t *testing.T) {
-	ddc, _ := NewDCC(2)
+	ddc, _ := NewDCC(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime":      time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -882,7 +884,7 @@ func TestDDCGetStringValue(t *testing.T) {
 }
 
 func TestDDCGetFloat64Value(t *testing.T) {
-	ddc, _ := NewDCC(2)
+	ddc, _ := NewDCC(2, "")
 	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
 		Event: map[string]interface{}{
 			"AnswerTime":      time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
@@ -938,3 +940,269 @@ func TestDDCGetFloat64Value(t *testing.T) {
 		t.Errorf("wrong ddc value: %v", strVal)
 	}
 }
+
+func TestNewStatMetricWithParams(t *testing.T) {
+	if _, err := NewStatMetric("*sum", 0); err == nil {
+		t.Error("expecting error for missing field name")
+	}
+	if _, err := NewStatMetric("*usage_percentile:101", 0); err == nil {
+		t.Error("expecting error for invalid percentile")
+	}
+	if _, err := NewStatMetric("*unsupported:Cost", 0); err == nil {
+		t.Error("expecting error for unsupported metric")
+	}
+	if metric, err := NewStatMetric("*sum:Cost", 2); err != nil {
+		t.Error(err)
+	} else if sum, canCast := metric.(*StatSum); !canCast {
+		t.Errorf("unexpected metric: %+v", metric)
+	} else if sum.FieldName != "Cost" || sum.MinItems != 2 {
+		t.Errorf("unexpected metric: %+v", sum)
+	}
+	if metric, err := NewStatMetric("*cost_percentile:99.9", 0); err != nil {
+		t.Error(err)
+	} else if cp, canCast := metric.(*StatCostPercentile); !canCast {
+		t.Errorf("unexpected metric: %+v", metric)
+	} else if cp.Percentile != 99.9 {
+		t.Errorf("unexpected percentile: %v", cp.Percentile)
+	}
+}
+
+func TestUsagePercentileGetValue(t *testing.T) {
+	up, _ := NewStatUsagePercentile(2, "95")
+	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
+		Event: map[string]interface{}{
+			"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
+			"Usage":      time.Duration(10 * time.Second)}}
+	up.AddEvent(ev)
+	if v := up.GetValue(); v != time.Duration((-1)*time.Nanosecond) {
+		t.Errorf("wrong percentile value: %+v", v)
+	}
+	if strVal := up.GetStringValue(""); strVal != utils.NOT_AVAILABLE {
+		t.Errorf("wrong percentile value: %s", strVal)
+	}
+	for i := 2; i <= 20; i++ {
+		up.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: fmt.Sprintf("EVENT_%d", i),
+			Event: map[string]interface{}{
+				"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
+				"Usage":      time.Duration(i*10) * time.Second}})
+	}
+	if v := up.GetValue(); v != time.Duration(190*time.Second) {
+		t.Errorf("wrong percentile value: %+v", v)
+	}
+	if v := up.GetFloat64Value(); v != 190.0 {
+		t.Errorf("wrong percentile value: %v", v)
+	}
+	up.RemEvent("cgrates.org:EVENT_19")
+	if strVal := up.GetStringValue(""); strVal != "3m20s" {
+		t.Errorf("wrong percentile value: %s", strVal)
+	}
+	// unanswered calls are considered with 0 duration, same as for ACD
+	p50, _ := NewStatUsagePercentile(0, "50")
+	p50.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_1"})
+	p50.AddEvent(ev)
+	if v := p50.GetValue(); v != time.Duration(0) {
+		t.Errorf("wrong percentile value: %+v", v)
+	}
+}
+
+func TestCostPercentileGetValue(t *testing.T) {
+	cp, _ := NewStatCostPercentile(0, "50")
+	if v := cp.GetValue(); v != -1.0 {
+		t.Errorf("wrong percentile value: %v", v)
+	}
+	for i, cost := range []float64{12.3, 1.2, 5.5, 3.1} {
+		cp.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: fmt.Sprintf("EVENT_%d", i),
+			Event: map[string]interface{}{
+				"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
+				utils.COST:   cost}})
+	}
+	if v := cp.GetValue(); v != 3.1 {
+		t.Errorf("wrong percentile value: %v", v)
+	}
+	cp.RemEvent("cgrates.org:EVENT_1")
+	if strVal := cp.GetStringValue(""); strVal != "5.5" {
+		t.Errorf("wrong percentile value: %s", strVal)
+	}
+	if err := cp.RemEvent("cgrates.org:EVENT_1"); err != utils.ErrNotFound {
+		t.Error(err)
+	}
+}
+
+func TestStdDevACDGetValue(t *testing.T) {
+	sd, _ := NewStatStdDevACD(2, "")
+	for i, usage := range []time.Duration{2 * time.Second, 4 * time.Second, 4 * time.Second,
+		4 * time.Second, 5 * time.Second, 5 * time.Second, 7 * time.Second, 9 * time.Second} {
+		sd.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: fmt.Sprintf("EVENT_%d", i),
+			Event: map[string]interface{}{
+				"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
+				"Usage":      usage}})
+	}
+	if v := sd.GetValue(); v != time.Duration(2*time.Second) {
+		t.Errorf("wrong stddev value: %+v", v)
+	}
+	for i := 0; i < 7; i++ {
+		sd.RemEvent(fmt.Sprintf("cgrates.org:EVENT_%d", i))
+	}
+	if v := sd.GetFloat64Value(); v != -1.0 {
+		t.Errorf("wrong stddev value: %v", v)
+	}
+}
+
+func TestStdDevACCGetValue(t *testing.T) {
+	sd, _ := NewStatStdDevACC(0, "")
+	if strVal := sd.GetStringValue(""); strVal != utils.NOT_AVAILABLE {
+		t.Errorf("wrong stddev value: %s", strVal)
+	}
+	for i, cost := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		sd.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: fmt.Sprintf("EVENT_%d", i),
+			Event: map[string]interface{}{
+				"AnswerTime": time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
+				utils.COST:   cost}})
+	}
+	if v := sd.GetValue(); v != 2.0 {
+		t.Errorf("wrong stddev value: %v", v)
+	}
+	sd.RemEvent("cgrates.org:EVENT_7")
+	if v := sd.GetFloat64Value(); v != 1.39971 {
+		t.Errorf("wrong stddev value: %v", v)
+	}
+}
+
+func TestStatSumGetValue(t *testing.T) {
+	sum, _ := NewStatSum(2, "ExtraCost")
+	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
+		Event: map[string]interface{}{
+			"ExtraCost": 1.5}}
+	sum.AddEvent(ev)
+	if strVal := sum.GetStringValue(""); strVal != utils.NOT_AVAILABLE {
+		t.Errorf("wrong sum value: %s", strVal)
+	}
+	ev2 := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_2",
+		Event: map[string]interface{}{
+			"ExtraCost": "2.25"}}
+	sum.AddEvent(ev2)
+	if v := sum.GetValue(); v != 3.75 {
+		t.Errorf("wrong sum value: %v", v)
+	}
+	if err := sum.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_3"}); err != utils.ErrNotFound {
+		t.Error(err)
+	}
+	// replacing an event counts only its new value
+	sum.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_2",
+		Event: map[string]interface{}{
+			"ExtraCost": 1.0}})
+	if v := sum.GetValue(); v != 2.5 {
+		t.Errorf("wrong sum value: %v", v)
+	}
+	sum.RemEvent(ev.TenantID())
+	if v := sum.GetFloat64Value(); v != -1.0 {
+		t.Errorf("wrong sum value: %v", v)
+	}
+	usageSum, _ := NewStatSum(0, utils.USAGE)
+	usageSum.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
+		Event: map[string]interface{}{utils.USAGE: time.Duration(90 * time.Second)}})
+	usageSum.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_2",
+		Event: map[string]interface{}{utils.USAGE: "30s"}})
+	if v := usageSum.GetValue(); v != 120.0 {
+		t.Errorf("wrong sum value: %v", v)
+	}
+}
+
+func TestStatDistinctGetValue(t *testing.T) {
+	dst, _ := NewStatDistinct(2, utils.ACCOUNT)
+	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
+		Event: map[string]interface{}{utils.ACCOUNT: "1001"}}
+	ev2 := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_2",
+		Event: map[string]interface{}{utils.ACCOUNT: "1001"}}
+	ev3 := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_3",
+		Event: map[string]interface{}{utils.ACCOUNT: "1002"}}
+	dst.AddEvent(ev)
+	if strVal := dst.GetStringValue(""); strVal != utils.NOT_AVAILABLE {
+		t.Errorf("wrong distinct value: %s", strVal)
+	}
+	dst.AddEvent(ev2)
+	dst.AddEvent(ev3)
+	if v := dst.GetFloat64Value(); v != 2 {
+		t.Errorf("wrong distinct value: %v", v)
+	}
+	// replacing an event drops its previous value
+	dst.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_3",
+		Event: map[string]interface{}{utils.ACCOUNT: "1001"}})
+	if v := dst.GetFloat64Value(); v != 1 {
+		t.Errorf("wrong distinct value: %v", v)
+	}
+	dst.AddEvent(ev3)
+	dst.RemEvent(ev.TenantID())
+	if strVal := dst.GetStringValue(""); strVal != "2" {
+		t.Errorf("wrong distinct value: %s", strVal)
+	}
+	dst.RemEvent(ev2.TenantID())
+	if v := dst.GetFloat64Value(); v != -1.0 {
+		t.Errorf("wrong distinct value: %v", v)
+	}
+}
+
+func TestStatAverageGetValue(t *testing.T) {
+	avg, _ := NewStatAverage(2, "MOS")
+	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
+		Event: map[string]interface{}{"MOS": 4.2}}
+	avg.AddEvent(ev)
+	if v := avg.GetValue(); v != -1.0 {
+		t.Errorf("wrong average value: %v", v)
+	}
+	// events without MOS are not considered
+	if err := avg.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_2"}); err != nil {
+		t.Error(err)
+	}
+	if v := avg.GetValue(); v != -1.0 {
+		t.Errorf("wrong average value: %v", v)
+	}
+	avg.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_3",
+		Event: map[string]interface{}{"MOS": "3.1"}})
+	if strVal := avg.GetStringValue(""); strVal != "3.65" {
+		t.Errorf("wrong average value: %s", strVal)
+	}
+	// replacing an event counts only its new value
+	avg.AddEvent(&StatEvent{Tenant: "cgrates.org", ID: "EVENT_3",
+		Event: map[string]interface{}{"MOS": 3.8}})
+	if v := avg.GetValue(); v != 4.0 {
+		t.Errorf("wrong average value: %v", v)
+	}
+	avg.RemEvent(ev.TenantID())
+	if v := avg.GetFloat64Value(); v != -1.0 {
+		t.Errorf("wrong average value: %v", v)
+	}
+}
+
+func TestStatMetricsMarshaling(t *testing.T) {
+	ms := NewCodecMsgpackMarshaler()
+	ev := &StatEvent{Tenant: "cgrates.org", ID: "EVENT_1",
+		Event: map[string]interface{}{
+			"AnswerTime":      time.Date(2014, 7, 14, 14, 25, 0, 0, time.UTC),
+			utils.USAGE:       time.Duration(10 * time.Second),
+			utils.COST:        1.2,
+			utils.DESTINATION: "1002",
+			"MOS":             4.1}}
+	for _, metricID := range []string{utils.MetaDDC, "*usage_percentile:95", "*cost_percentile:50",
+		utils.MetaStdDevACD, utils.MetaStdDevACC, "*sum:Cost", "*distinct:Destination", "*average:MOS"} {
+		metric, err := NewStatMetric(metricID, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = metric.AddEvent(ev); err != nil {
+			t.Fatal(err)
+		}
+		marshaled, err := metric.Marshal(ms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded, _ := NewStatMetric(metricID, 0)
+		if err = loaded.LoadMarshaled(ms, marshaled); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(metric.GetStringValue(""), loaded.GetStringValue("")) {
+			t.Errorf("metric: %s, expecting: %s, received: %s",
+				metricID, metric.GetStringValue(""), loaded.GetStringValue(""))
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -55,11 +56,19 @@ type StatService struct {
 	thdS             rpcclient.RpcClientConnection // rpc connection towards ThresholdS
 	filterS          *FilterS
 	indexedFields    []string
+	idxFldsMux       sync.RWMutex // protects indexedFields
 	stopBackup       chan struct{}
 	storedStatQueues utils.StringMap // keep a record of stats which need saving, map[statsTenantID]bool
 	ssqMux           sync.RWMutex    // protects storedStatQueues
 }
 
+// SetIndexedFields replaces the fields considered when searching for matching queues, ie: on config reloads
+func (sS *StatService) SetIndexedFields(indexedFields []string) {
+	sS.idxFldsMux.Lock()
+	sS.indexedFields = indexedFields
+	sS.idxFldsMux.Unlock()
+}
+
 // ListenAndServe loops keeps the service alive
 func (sS *StatService) ListenAndServe(exitChan chan bool) error {
 	go sS.runBackup() // start backup loop
@@ -141,7 +150,10 @@ func (sS *StatService) StoreStatQueue(sq *StatQueue) (err error) {
 // matchingStatQueuesForEvent returns ordered list of matching resources which are active by the time of the call
 func (sS *StatService) matchingStatQueuesForEvent(ev *StatEvent) (sqs StatQueues, err error) {
 	matchingSQs := make(map[string]*StatQueue)
-	sqIDs, err := matchingItemIDsForEvent(ev.Event, sS.indexedFields, sS.dm, utils.StatQueuesStringIndex+ev.Tenant)
+	sS.idxFldsMux.RLock()
+	indexedFields := sS.indexedFields
+	sS.idxFldsMux.RUnlock()
+	sqIDs, err := matchingItemIDsForEvent(ev.Event, indexedFields, sS.dm, utils.StatQueuesStringIndex+ev.Tenant)
 	if err != nil {
 		return nil, err
 	}
@@ -195,6 +207,35 @@ func (sS *StatService) matchingStatQueuesForEvent(ev *StatEvent) (sqs StatQueues
 	return
 }
 
+// getStatQueue returns the StatQueue with it's calendar bucket rotated
+// so the metrics do not show values out of a bucket already closed
+func (sS *StatService) getStatQueue(tenant, sqID string) (sq *StatQueue, err error) {
+	lockID := utils.StatQueuesStringIndex + sqID
+	guardian.Guardian.GuardIDs(config.CgrConfig().LockingTimeout, lockID)
+	defer guardian.Guardian.UnguardIDs(lockID)
+	if sq, err = sS.dm.GetStatQueue(tenant, sqID, false, ""); err != nil {
+		return
+	}
+	// always read the profile out of DataManager, the one attached to the queue goes stale on reload
+	sqPrfl, err := sS.dm.GetStatQueueProfile(tenant, sqID, false, utils.NonTransactional)
+	if err != nil {
+		if err != utils.ErrNotFound {
+			return nil, err
+		}
+		return sq, nil // queue without profile, nothing to rotate
+	}
+	sq.sqPrfl = sqPrfl
+	if rotated, err := sq.rotateBucket(time.Now()); err != nil {
+		return nil, err
+	} else if rotated && sq.dirty != nil {
+		*sq.dirty = true
+		sS.ssqMux.Lock()
+		sS.storedStatQueues[sq.TenantID()] = true
+		sS.ssqMux.Unlock()
+	}
+	return
+}
+
 // Call implements rpcclient.RpcClientConnection interface for internal RPC
 // here for cases when passing StatsService as rpccclient.RpcClientConnection (ie. in ResourceS)
 func (ss *StatService) Call(serviceMethod string, args interface{}, reply interface{}) error {
@@ -282,7 +323,7 @@ func (sS *StatService) V1GetQueueStringMetrics(args *utils.TenantID, reply *map[
 	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
 		return utils.NewErrMandatoryIeMissing(missing...)
 	}
-	sq, err := sS.dm.GetStatQueue(args.Tenant, args.ID, false, "")
+	sq, err := sS.getStatQueue(args.Tenant, args.ID)
 	if err != nil {
 		if err != utils.ErrNotFound {
 			err = utils.NewErrServerError(err)
@@ -302,7 +343,7 @@ func (sS *StatService) V1GetQueueFloatMetrics(args *utils.TenantID, reply *map[s
 	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
 		return utils.NewErrMandatoryIeMissing(missing...)
 	}
-	sq, err := sS.dm.GetStatQueue(args.Tenant, args.ID, false, "")
+	sq, err := sS.getStatQueue(args.Tenant, args.ID)
 	if err != nil {
 		if err != utils.ErrNotFound {
 			err = utils.NewErrServerError(err)
@@ -317,6 +358,58 @@ func (sS *StatService) V1GetQueueFloatMetrics(args *utils.TenantID, reply *map[s
 	return
 }
 
+// V1GetQueueHistory returns the closed calendar buckets of a Queue, oldest first
+func (sS *StatService) V1GetQueueHistory(args *utils.TenantID, reply *[]*StatQueueBucket) (err error) {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	sq, err := sS.getStatQueue(args.Tenant, args.ID)
+	if err != nil {
+		if err != utils.ErrNotFound {
+			err = utils.NewErrServerError(err)
+		}
+		return err
+	}
+	if len(sq.History) == 0 {
+		return utils.ErrNotFound
+	}
+	history := make([]*StatQueueBucket, len(sq.History))
+	copy(history, sq.History)
+	*reply = history
+	return
+}
+
+// V1ResetStatQueue clears the events and the metrics of a Queue, keeping its closed buckets
+func (sS *StatService) V1ResetStatQueue(args *utils.TenantID, reply *string) (err error) {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	lockID := utils.StatQueuesStringIndex + args.ID
+	guardian.Guardian.GuardIDs(config.CgrConfig().LockingTimeout, lockID)
+	defer guardian.Guardian.UnguardIDs(lockID)
+	sq, err := sS.dm.GetStatQueue(args.Tenant, args.ID, false, "")
+	if err != nil {
+		if err != utils.ErrNotFound {
+			err = utils.NewErrServerError(err)
+		}
+		return err
+	}
+	sq.SQItems = sq.SQItems[:0]
+	for metricID := range sq.SQMetrics {
+		if sq.SQMetrics[metricID], err = NewStatMetric(metricID, sq.MinItems); err != nil {
+			return utils.NewErrServerError(err)
+		}
+	}
+	if err = sS.dm.SetStatQueue(sq); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if sq.dirty != nil {
+		*sq.dirty = false
+	}
+	*reply = utils.OK
+	return
+}
+
 // V1GetQueueIDs returns list of queueIDs registered for a tenant
 func (sS *StatService) V1GetQueueIDs(tenant string, qIDs *[]string) (err error) {
 	prfx := utils.StatQueuePrefix + tenant + ":"
@@ -331,3 +424,33 @@ func (sS *StatService) V1GetQueueIDs(tenant string, qIDs *[]string) (err error)
 	*qIDs = retIDs
 	return
 }
+
+// Metrics implements utils.MetricsCollector, exporting the values of the stat queues in cache
+// metrics without enough data to be computed are not exported
+func (sS *StatService) Metrics() []*utils.MetricFamily {
+	mf := &utils.MetricFamily{Name: "cgr_stat_metric", Type: utils.MetricGauge,
+		Help: "Value of a metric computed by a stat queue"}
+	sqKeys := cache.GetEntryKeys(utils.StatQueuePrefix)
+	sort.Strings(sqKeys)
+	for _, sqKey := range sqKeys {
+		tntID := utils.NewTenantID(sqKey[len(utils.StatQueuePrefix):])
+		var metrics map[string]float64
+		if err := sS.V1GetQueueFloatMetrics(tntID, &metrics); err != nil {
+			continue
+		}
+		metricIDs := make([]string, 0, len(metrics))
+		for metricID := range metrics {
+			metricIDs = append(metricIDs, metricID)
+		}
+		sort.Strings(metricIDs)
+		for _, metricID := range metricIDs {
+			if metrics[metricID] == STATS_NA {
+				continue
+			}
+			mf.Samples = append(mf.Samples, &utils.MetricSample{
+				Labels: map[string]string{"tenant": tntID.Tenant, "id": tntID.ID, "metric": metricID},
+				Value:  metrics[metricID]})
+		}
+	}
+	return []*utils.MetricFamily{mf}
+}
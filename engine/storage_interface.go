@@ -119,12 +119,41 @@ type DataDB interface {
 	GetStoredStatQueueDrv(tenant, id string) (sq *StoredStatQueue, err error)
 	SetStoredStatQueueDrv(sq *StoredStatQueue) (err error)
 	RemStoredStatQueueDrv(tenant, id string) (err error)
+	GetCDRExportQueueDrv(exportID string) (q *CDRExportQueue, err error)
+	SetCDRExportQueueDrv(q *CDRExportQueue) (err error)
+	RemCDRExportQueueDrv(exportID string) (err error)
+	GetIdempotencyRecordDrv(id string) (rec *IdempotencyRecord, err error)
+	SetIdempotencyRecordDrv(rec *IdempotencyRecord) (err error)
+	RemIdempotencyRecordDrv(id string) (err error)
+	GetInvoiceDrv(tenant, id string) (inv *Invoice, err error)
+	SetInvoiceDrv(inv *Invoice) (err error)
+	RemInvoiceDrv(tenant, id string) (err error)
+	GetInvoiceSequenceDrv(tenant string) (seq *InvoiceSequence, err error)
+	SetInvoiceSequenceDrv(seq *InvoiceSequence) (err error)
+	GetTaxProfileDrv(tenant, id string) (txPrfl *TaxProfile, err error)
+	SetTaxProfileDrv(txPrfl *TaxProfile) (err error)
+	RemTaxProfileDrv(tenant, id string) (err error)
+	GetAPIKeyDrv(id string) (apiKey *APIKey, err error)
+	SetAPIKeyDrv(apiKey *APIKey) (err error)
+	RemAPIKeyDrv(id string) (err error)
+	GetAPIRoleDrv(id string) (apiRole *APIRole, err error)
+	SetAPIRoleDrv(apiRole *APIRole) (err error)
+	RemAPIRoleDrv(id string) (err error)
+	GetStoredSessionDrv(nodeID, cgrID, runID string) (ss *StoredSession, err error)
+	SetStoredSessionDrv(ss *StoredSession) (err error)
+	RemStoredSessionDrv(nodeID, cgrID, runID string) (err error)
 	GetThresholdProfileDrv(tenant string, ID string) (tp *ThresholdProfile, err error)
 	SetThresholdProfileDrv(tp *ThresholdProfile) (err error)
 	RemThresholdProfileDrv(tenant, id string) (err error)
 	GetThresholdDrv(string, string) (*Threshold, error)
 	SetThresholdDrv(*Threshold) error
 	RemoveThresholdDrv(string, string) error
+	GetSupplierProfileDrv(tenant, id string) (spp *SupplierProfile, err error)
+	SetSupplierProfileDrv(spp *SupplierProfile) (err error)
+	RemSupplierProfileDrv(tenant, id string) (err error)
+	GetExchangeRateDrv(fromCurrency, toCurrency string) (xr *ExchangeRate, err error)
+	SetExchangeRateDrv(xr *ExchangeRate) (err error)
+	RemExchangeRateDrv(fromCurrency, toCurrency string) (err error)
 	GetFilterDrv(string, string) (*Filter, error)
 	SetFilterDrv(*Filter) error
 	RemoveFilterDrv(string, string) error
@@ -175,6 +204,8 @@ type LoadReader interface {
 	GetTPStats(string, string) ([]*utils.TPStats, error)
 	GetTPThresholds(string, string) ([]*utils.TPThreshold, error)
 	GetTPFilters(string, string) ([]*utils.TPFilter, error)
+	GetTPSuppliers(string, string) ([]*utils.TPSupplierProfile, error)
+	GetTPExchangeRates(string, string) ([]*utils.TPExchangeRate, error)
 }
 
 type LoadWriter interface {
@@ -199,6 +230,8 @@ type LoadWriter interface {
 	SetTPStats([]*utils.TPStats) error
 	SetTPThresholds([]*utils.TPThreshold) error
 	SetTPFilters([]*utils.TPFilter) error
+	SetTPSuppliers([]*utils.TPSupplierProfile) error
+	SetTPExchangeRates([]*utils.TPExchangeRate) error
 }
 
 // NewMarshaler returns the marshaler type selected by mrshlerStr
@@ -25,6 +25,7 @@ import (
 	"io/ioutil"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cgrates/cgrates/cache"
 	"github.com/cgrates/cgrates/config"
@@ -32,13 +33,17 @@ import (
 )
 
 type MapStorage struct {
-	dict     storage
-	tasks    [][]byte
-	ms       Marshaler
-	mu       sync.RWMutex
-	cacheCfg config.CacheConfig
+	dict      storage
+	tasks     [][]byte
+	ms        Marshaler
+	mu        sync.RWMutex
+	cacheCfg  config.CacheConfig
+	idkPurged time.Time // last purge of the expired idempotency records
 }
 
+// mapIdempotencyPurgeInterval is the minimum time between two purges of the expired idempotency records
+var mapIdempotencyPurgeInterval = time.Minute
+
 type storage map[string][]byte
 
 func (s storage) sadd(key, value string, ms Marshaler) {
@@ -1274,6 +1279,277 @@ func (ms *MapStorage) RemStoredStatQueueDrv(tenant, id string) (err error) {
 	return
 }
 
+// GetCDRExportQueueDrv retrieves the retry queue of an online CDR export
+func (ms *MapStorage) GetCDRExportQueueDrv(exportID string) (q *CDRExportQueue, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.CDRExportQueuePrefix+exportID]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	err = ms.ms.Unmarshal(values, &q)
+	return
+}
+
+// SetCDRExportQueueDrv stores the retry queue of an online CDR export
+func (ms *MapStorage) SetCDRExportQueueDrv(q *CDRExportQueue) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var result []byte
+	if result, err = ms.ms.Marshal(q); err != nil {
+		return
+	}
+	ms.dict[utils.CDRExportQueuePrefix+q.ExportID] = result
+	return
+}
+
+// RemCDRExportQueueDrv removes the retry queue of an online CDR export
+func (ms *MapStorage) RemCDRExportQueueDrv(exportID string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.CDRExportQueuePrefix+exportID)
+	return
+}
+
+// GetIdempotencyRecordDrv retrieves the reply of a request carrying an IdempotencyKey, expired ones are dropped on read
+func (ms *MapStorage) GetIdempotencyRecordDrv(id string) (rec *IdempotencyRecord, err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	values, ok := ms.dict[utils.IdempotencyRecordPrefix+id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	if err = ms.ms.Unmarshal(values, &rec); err != nil {
+		return nil, err
+	}
+	if rec.IsExpired() {
+		delete(ms.dict, utils.IdempotencyRecordPrefix+id)
+		return nil, utils.ErrNotFound
+	}
+	return
+}
+
+// SetIdempotencyRecordDrv stores the reply of a request carrying an IdempotencyKey
+func (ms *MapStorage) SetIdempotencyRecordDrv(rec *IdempotencyRecord) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var result []byte
+	if result, err = ms.ms.Marshal(rec); err != nil {
+		return
+	}
+	ms.dict[utils.IdempotencyRecordPrefix+rec.ID] = result
+	if time.Since(ms.idkPurged) >= mapIdempotencyPurgeInterval {
+		ms.purgeIdempotencyRecords()
+	}
+	return
+}
+
+// purgeIdempotencyRecords removes the expired idempotency records, the ones never read again would stay forever otherwise
+func (ms *MapStorage) purgeIdempotencyRecords() {
+	for key, values := range ms.dict {
+		if !strings.HasPrefix(key, utils.IdempotencyRecordPrefix) {
+			continue
+		}
+		var rec *IdempotencyRecord
+		if err := ms.ms.Unmarshal(values, &rec); err != nil || rec.IsExpired() {
+			delete(ms.dict, key)
+		}
+	}
+	ms.idkPurged = time.Now()
+}
+
+// RemIdempotencyRecordDrv removes the reply of a request carrying an IdempotencyKey
+func (ms *MapStorage) RemIdempotencyRecordDrv(id string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.IdempotencyRecordPrefix+id)
+	return
+}
+
+// GetInvoiceDrv retrieves an Invoice from dataDB
+func (ms *MapStorage) GetInvoiceDrv(tenant, id string) (inv *Invoice, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.InvoicePrefix+utils.ConcatenatedKey(tenant, id)]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	err = ms.ms.Unmarshal(values, &inv)
+	return
+}
+
+// SetInvoiceDrv stores an Invoice into dataDB
+func (ms *MapStorage) SetInvoiceDrv(inv *Invoice) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var result []byte
+	if result, err = ms.ms.Marshal(inv); err != nil {
+		return
+	}
+	ms.dict[utils.InvoicePrefix+inv.TenantID()] = result
+	return
+}
+
+// RemInvoiceDrv removes an Invoice from dataDB
+func (ms *MapStorage) RemInvoiceDrv(tenant, id string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.InvoicePrefix+utils.ConcatenatedKey(tenant, id))
+	return
+}
+
+// GetInvoiceSequenceDrv retrieves the invoice numbering sequence of a tenant
+func (ms *MapStorage) GetInvoiceSequenceDrv(tenant string) (seq *InvoiceSequence, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.InvoiceSequencePrefix+tenant]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	err = ms.ms.Unmarshal(values, &seq)
+	return
+}
+
+// SetInvoiceSequenceDrv stores the invoice numbering sequence of a tenant
+func (ms *MapStorage) SetInvoiceSequenceDrv(seq *InvoiceSequence) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var result []byte
+	if result, err = ms.ms.Marshal(seq); err != nil {
+		return
+	}
+	ms.dict[utils.InvoiceSequencePrefix+seq.Tenant] = result
+	return
+}
+
+// GetTaxProfileDrv retrieves a TaxProfile from dataDB
+func (ms *MapStorage) GetTaxProfileDrv(tenant, id string) (txPrfl *TaxProfile, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.TaxProfilePrefix+utils.ConcatenatedKey(tenant, id)]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	err = ms.ms.Unmarshal(values, &txPrfl)
+	return
+}
+
+// SetTaxProfileDrv stores a TaxProfile into dataDB
+func (ms *MapStorage) SetTaxProfileDrv(txPrfl *TaxProfile) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var result []byte
+	if result, err = ms.ms.Marshal(txPrfl); err != nil {
+		return
+	}
+	ms.dict[utils.TaxProfilePrefix+txPrfl.TenantID()] = result
+	return
+}
+
+// RemTaxProfileDrv removes a TaxProfile from dataDB
+func (ms *MapStorage) RemTaxProfileDrv(tenant, id string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.TaxProfilePrefix+utils.ConcatenatedKey(tenant, id))
+	return
+}
+
+// GetAPIKeyDrv retrieves an APIKey from dataDB
+func (ms *MapStorage) GetAPIKeyDrv(id string) (apiKey *APIKey, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.APIKeyPrefix+id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	err = ms.ms.Unmarshal(values, &apiKey)
+	return
+}
+
+// SetAPIKeyDrv stores an APIKey into dataDB
+func (ms *MapStorage) SetAPIKeyDrv(apiKey *APIKey) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var result []byte
+	if result, err = ms.ms.Marshal(apiKey); err != nil {
+		return
+	}
+	ms.dict[utils.APIKeyPrefix+apiKey.ID] = result
+	return
+}
+
+// RemAPIKeyDrv removes an APIKey from dataDB
+func (ms *MapStorage) RemAPIKeyDrv(id string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.APIKeyPrefix+id)
+	return
+}
+
+// GetAPIRoleDrv retrieves an APIRole from dataDB
+func (ms *MapStorage) GetAPIRoleDrv(id string) (apiRole *APIRole, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.APIRolePrefix+id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	err = ms.ms.Unmarshal(values, &apiRole)
+	return
+}
+
+// SetAPIRoleDrv stores an APIRole into dataDB
+func (ms *MapStorage) SetAPIRoleDrv(apiRole *APIRole) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var result []byte
+	if result, err = ms.ms.Marshal(apiRole); err != nil {
+		return
+	}
+	ms.dict[utils.APIRolePrefix+apiRole.ID] = result
+	return
+}
+
+// RemAPIRoleDrv removes an APIRole from dataDB
+func (ms *MapStorage) RemAPIRoleDrv(id string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.APIRolePrefix+id)
+	return
+}
+
+// GetStoredSessionDrv retrieves a StoredSession from dataDB
+func (ms *MapStorage) GetStoredSessionDrv(nodeID, cgrID, runID string) (ss *StoredSession, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.SMGSessionPrefix+StoredSessionID(nodeID, cgrID, runID)]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	err = ms.ms.Unmarshal(values, &ss)
+	return
+}
+
+// SetStoredSessionDrv stores a StoredSession into dataDB
+func (ms *MapStorage) SetStoredSessionDrv(ss *StoredSession) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var result []byte
+	if result, err = ms.ms.Marshal(ss); err != nil {
+		return
+	}
+	ms.dict[utils.SMGSessionPrefix+StoredSessionID(ss.NodeID, ss.CGRID, ss.RunID)] = result
+	return
+}
+
+// RemStoredSessionDrv removes a StoredSession from dataDB
+func (ms *MapStorage) RemStoredSessionDrv(nodeID, cgrID, runID string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.SMGSessionPrefix+StoredSessionID(nodeID, cgrID, runID))
+	return
+}
+
 // GetThresholdProfileDrv retrieves a ThresholdProfile from dataDB
 func (ms *MapStorage) GetThresholdProfileDrv(tenant, ID string) (tp *ThresholdProfile, err error) {
 	ms.mu.RLock()
@@ -1311,6 +1587,74 @@ func (ms *MapStorage) RemThresholdProfileDrv(tenant, id string) (err error) {
 	return
 }
 
+// GetSupplierProfileDrv retrieves a SupplierProfile from dataDB
+func (ms *MapStorage) GetSupplierProfileDrv(tenant, id string) (spp *SupplierProfile, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.SupplierProfilePrefix+utils.ConcatenatedKey(tenant, id)]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	if err = ms.ms.Unmarshal(values, &spp); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// SetSupplierProfileDrv stores a SupplierProfile into DataDB
+func (ms *MapStorage) SetSupplierProfileDrv(spp *SupplierProfile) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, err := ms.ms.Marshal(spp)
+	if err != nil {
+		return err
+	}
+	ms.dict[utils.SupplierProfilePrefix+spp.TenantID()] = result
+	return
+}
+
+// RemSupplierProfileDrv removes a SupplierProfile from dataDB
+func (ms *MapStorage) RemSupplierProfileDrv(tenant, id string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.SupplierProfilePrefix+utils.ConcatenatedKey(tenant, id))
+	return
+}
+
+// GetExchangeRateDrv retrieves an ExchangeRate from dataDB
+func (ms *MapStorage) GetExchangeRateDrv(fromCurrency, toCurrency string) (xr *ExchangeRate, err error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	values, ok := ms.dict[utils.ExchangeRatePrefix+utils.ConcatenatedKey(fromCurrency, toCurrency)]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	if err = ms.ms.Unmarshal(values, &xr); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// SetExchangeRateDrv stores an ExchangeRate into DataDB
+func (ms *MapStorage) SetExchangeRateDrv(xr *ExchangeRate) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, err := ms.ms.Marshal(xr)
+	if err != nil {
+		return err
+	}
+	ms.dict[utils.ExchangeRatePrefix+xr.ID()] = result
+	return
+}
+
+// RemExchangeRateDrv removes an ExchangeRate from dataDB
+func (ms *MapStorage) RemExchangeRateDrv(fromCurrency, toCurrency string) (err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.dict, utils.ExchangeRatePrefix+utils.ConcatenatedKey(fromCurrency, toCurrency))
+	return
+}
+
 func (ms *MapStorage) GetThresholdDrv(tenant, id string) (r *Threshold, err error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
@@ -61,9 +61,19 @@ const (
 	colRes   = "resources"
 	colSqs   = "statqueues"
 	colSqp   = "statqueue_profiles"
+	colCeq   = "cdr_export_queues"
 	colTps   = "threshold_profiles"
 	colThs   = "thresholds"
 	colFlt   = "filters"
+	colSpp   = "supplier_profiles"
+	colXrt   = "exchange_rates"
+	colIdk   = "idempotency_records"
+	colInv   = "invoices"
+	colIvs   = "invoice_sequences"
+	colTxp   = "tax_profiles"
+	colApk   = "api_keys"
+	colApr   = "api_roles"
+	colSmg   = "smg_sessions"
 )
 
 var (
@@ -175,6 +185,33 @@ func (ms *MongoStorage) EnsureIndexes() (err error) {
 			return
 		}
 	}
+	if ms.storageType == utils.DataDB { // idempotency records are dropped by mongo once expired
+		for _, idx = range []mgo.Index{{Key: []string{"id"}, Unique: true},
+			{Key: []string{"expirytime"}, ExpireAfter: time.Second}} {
+			if err = db.C(colIdk).EnsureIndex(idx); err != nil {
+				return
+			}
+		}
+	}
+	if ms.storageType == utils.DataDB {
+		if err = db.C(colInv).EnsureIndex(mgo.Index{Key: []string{"tenant", "id"}, Unique: true}); err != nil {
+			return
+		}
+		if err = db.C(colIvs).EnsureIndex(mgo.Index{Key: []string{"tenant"}, Unique: true}); err != nil {
+			return
+		}
+		if err = db.C(colTxp).EnsureIndex(mgo.Index{Key: []string{"tenant", "id"}, Unique: true}); err != nil {
+			return
+		}
+		for _, col := range []string{colApk, colApr} {
+			if err = db.C(col).EnsureIndex(mgo.Index{Key: []string{"id"}, Unique: true}); err != nil {
+				return
+			}
+		}
+		if err = db.C(colSmg).EnsureIndex(mgo.Index{Key: []string{"nodeid", "cgrid", "runid"}, Unique: true}); err != nil {
+			return
+		}
+	}
 	if ms.storageType == utils.StorDB {
 		idx = mgo.Index{
 			Key:        []string{"tpid", "id"},
@@ -334,6 +371,13 @@ func (ms *MongoStorage) getColNameForPrefix(prefix string) (name string, ok bool
 		utils.ThresholdProfilePrefix: colTps,
 		utils.ThresholdPrefix:        colThs,
 		utils.FilterPrefix:           colFlt,
+		utils.SupplierProfilePrefix:  colSpp,
+		utils.ExchangeRatePrefix:     colXrt,
+		utils.InvoicePrefix:          colInv,
+		utils.TaxProfilePrefix:       colTxp,
+		utils.APIKeyPrefix:           colApk,
+		utils.APIRolePrefix:          colApr,
+		utils.SMGSessionPrefix:       colSmg,
 	}
 	name, ok = colMap[prefix]
 	return
@@ -573,6 +617,51 @@ func (ms *MongoStorage) GetKeysForPrefix(prefix string) (result []string, err er
 		for iter.Next(&idResult) {
 			result = append(result, utils.ThresholdProfilePrefix+utils.ConcatenatedKey(idResult.Tenant, idResult.Id))
 		}
+	case utils.SupplierProfilePrefix:
+		iter := db.C(colSpp).Find(bson.M{"id": bson.M{"$regex": bson.RegEx{Pattern: subject}}}).Select(bson.M{"tenant": 1, "id": 1}).Iter()
+		for iter.Next(&idResult) {
+			result = append(result, utils.SupplierProfilePrefix+utils.ConcatenatedKey(idResult.Tenant, idResult.Id))
+		}
+	case utils.ExchangeRatePrefix:
+		var xr ExchangeRate
+		iter := db.C(colXrt).Find(nil).Select(bson.M{"fromcurrency": 1, "tocurrency": 1}).Iter()
+		for iter.Next(&xr) {
+			if strings.HasPrefix(xr.ID(), prefix[keyLen:]) {
+				result = append(result, utils.ExchangeRatePrefix+xr.ID())
+			}
+		}
+	case utils.InvoicePrefix:
+		iter := db.C(colInv).Find(nil).Select(bson.M{"tenant": 1, "id": 1}).Iter()
+		for iter.Next(&idResult) {
+			if invID := utils.ConcatenatedKey(idResult.Tenant, idResult.Id); strings.HasPrefix(invID, prefix[keyLen:]) {
+				result = append(result, utils.InvoicePrefix+invID)
+			}
+		}
+	case utils.TaxProfilePrefix:
+		iter := db.C(colTxp).Find(nil).Select(bson.M{"tenant": 1, "id": 1}).Iter()
+		for iter.Next(&idResult) {
+			if txpID := utils.ConcatenatedKey(idResult.Tenant, idResult.Id); strings.HasPrefix(txpID, prefix[keyLen:]) {
+				result = append(result, utils.TaxProfilePrefix+txpID)
+			}
+		}
+	case utils.APIKeyPrefix:
+		iter := db.C(colApk).Find(bson.M{"id": bson.M{"$regex": bson.RegEx{Pattern: subject}}}).Select(bson.M{"id": 1}).Iter()
+		for iter.Next(&idResult) {
+			result = append(result, utils.APIKeyPrefix+idResult.Id)
+		}
+	case utils.APIRolePrefix:
+		iter := db.C(colApr).Find(bson.M{"id": bson.M{"$regex": bson.RegEx{Pattern: subject}}}).Select(bson.M{"id": 1}).Iter()
+		for iter.Next(&idResult) {
+			result = append(result, utils.APIRolePrefix+idResult.Id)
+		}
+	case utils.SMGSessionPrefix:
+		var ssResult struct{ NodeID, CGRID, RunID string }
+		iter := db.C(colSmg).Find(nil).Select(bson.M{"nodeid": 1, "cgrid": 1, "runid": 1}).Iter()
+		for iter.Next(&ssResult) {
+			if ssID := StoredSessionID(ssResult.NodeID, ssResult.CGRID, ssResult.RunID); strings.HasPrefix(ssID, prefix[keyLen:]) {
+				result = append(result, utils.SMGSessionPrefix+ssID)
+			}
+		}
 	default:
 		err = fmt.Errorf("unsupported prefix in GetKeysForPrefix: %s", prefix)
 	}
@@ -1873,6 +1962,247 @@ func (ms *MongoStorage) RemStoredStatQueueDrv(tenant, id string) (err error) {
 	return err
 }
 
+// GetCDRExportQueueDrv retrieves the retry queue of an online CDR export
+func (ms *MongoStorage) GetCDRExportQueueDrv(exportID string) (q *CDRExportQueue, err error) {
+	session, col := ms.conn(colCeq)
+	defer session.Close()
+	if err = col.Find(bson.M{"exportid": exportID}).One(&q); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetCDRExportQueueDrv stores the retry queue of an online CDR export
+func (ms *MongoStorage) SetCDRExportQueueDrv(q *CDRExportQueue) (err error) {
+	session, col := ms.conn(colCeq)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"exportid": q.ExportID}, q)
+	return
+}
+
+// RemCDRExportQueueDrv removes the retry queue of an online CDR export
+func (ms *MongoStorage) RemCDRExportQueueDrv(exportID string) (err error) {
+	session, col := ms.conn(colCeq)
+	defer session.Close()
+	if err = col.Remove(bson.M{"exportid": exportID}); err == mgo.ErrNotFound {
+		err = utils.ErrNotFound
+	}
+	return
+}
+
+// GetIdempotencyRecordDrv retrieves the reply of a request carrying an IdempotencyKey
+func (ms *MongoStorage) GetIdempotencyRecordDrv(id string) (rec *IdempotencyRecord, err error) {
+	session, col := ms.conn(colIdk)
+	defer session.Close()
+	if err = col.Find(bson.M{"id": id}).One(&rec); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	if rec.IsExpired() { // TTL monitor did not run yet
+		return nil, utils.ErrNotFound
+	}
+	return
+}
+
+// SetIdempotencyRecordDrv stores the reply of a request carrying an IdempotencyKey, expired by the TTL index
+func (ms *MongoStorage) SetIdempotencyRecordDrv(rec *IdempotencyRecord) (err error) {
+	session, col := ms.conn(colIdk)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"id": rec.ID}, rec)
+	return
+}
+
+// RemIdempotencyRecordDrv removes the reply of a request carrying an IdempotencyKey
+func (ms *MongoStorage) RemIdempotencyRecordDrv(id string) (err error) {
+	session, col := ms.conn(colIdk)
+	defer session.Close()
+	if err = col.Remove(bson.M{"id": id}); err == mgo.ErrNotFound {
+		err = utils.ErrNotFound
+	}
+	return
+}
+
+// GetInvoiceDrv retrieves an Invoice from dataDB
+func (ms *MongoStorage) GetInvoiceDrv(tenant, id string) (inv *Invoice, err error) {
+	session, col := ms.conn(colInv)
+	defer session.Close()
+	if err = col.Find(bson.M{"tenant": tenant, "id": id}).One(&inv); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetInvoiceDrv stores an Invoice into dataDB
+func (ms *MongoStorage) SetInvoiceDrv(inv *Invoice) (err error) {
+	session, col := ms.conn(colInv)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"tenant": inv.Tenant, "id": inv.ID}, inv)
+	return
+}
+
+// RemInvoiceDrv removes an Invoice from dataDB
+func (ms *MongoStorage) RemInvoiceDrv(tenant, id string) (err error) {
+	session, col := ms.conn(colInv)
+	defer session.Close()
+	if err = col.Remove(bson.M{"tenant": tenant, "id": id}); err == mgo.ErrNotFound {
+		err = utils.ErrNotFound
+	}
+	return
+}
+
+// GetInvoiceSequenceDrv retrieves the invoice numbering sequence of a tenant
+func (ms *MongoStorage) GetInvoiceSequenceDrv(tenant string) (seq *InvoiceSequence, err error) {
+	session, col := ms.conn(colIvs)
+	defer session.Close()
+	if err = col.Find(bson.M{"tenant": tenant}).One(&seq); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetInvoiceSequenceDrv stores the invoice numbering sequence of a tenant
+func (ms *MongoStorage) SetInvoiceSequenceDrv(seq *InvoiceSequence) (err error) {
+	session, col := ms.conn(colIvs)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"tenant": seq.Tenant}, seq)
+	return
+}
+
+// GetTaxProfileDrv retrieves a TaxProfile from dataDB
+func (ms *MongoStorage) GetTaxProfileDrv(tenant, id string) (txPrfl *TaxProfile, err error) {
+	session, col := ms.conn(colTxp)
+	defer session.Close()
+	if err = col.Find(bson.M{"tenant": tenant, "id": id}).One(&txPrfl); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetTaxProfileDrv stores a TaxProfile into dataDB
+func (ms *MongoStorage) SetTaxProfileDrv(txPrfl *TaxProfile) (err error) {
+	session, col := ms.conn(colTxp)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"tenant": txPrfl.Tenant, "id": txPrfl.ID}, txPrfl)
+	return
+}
+
+// RemTaxProfileDrv removes a TaxProfile from dataDB
+func (ms *MongoStorage) RemTaxProfileDrv(tenant, id string) (err error) {
+	session, col := ms.conn(colTxp)
+	defer session.Close()
+	if err = col.Remove(bson.M{"tenant": tenant, "id": id}); err == mgo.ErrNotFound {
+		err = utils.ErrNotFound
+	}
+	return
+}
+
+// GetAPIKeyDrv retrieves an APIKey from dataDB
+func (ms *MongoStorage) GetAPIKeyDrv(id string) (apiKey *APIKey, err error) {
+	session, col := ms.conn(colApk)
+	defer session.Close()
+	if err = col.Find(bson.M{"id": id}).One(&apiKey); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetAPIKeyDrv stores an APIKey into dataDB
+func (ms *MongoStorage) SetAPIKeyDrv(apiKey *APIKey) (err error) {
+	session, col := ms.conn(colApk)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"id": apiKey.ID}, apiKey)
+	return
+}
+
+// RemAPIKeyDrv removes an APIKey from dataDB
+func (ms *MongoStorage) RemAPIKeyDrv(id string) (err error) {
+	session, col := ms.conn(colApk)
+	defer session.Close()
+	if err = col.Remove(bson.M{"id": id}); err == mgo.ErrNotFound {
+		err = utils.ErrNotFound
+	}
+	return
+}
+
+// GetAPIRoleDrv retrieves an APIRole from dataDB
+func (ms *MongoStorage) GetAPIRoleDrv(id string) (apiRole *APIRole, err error) {
+	session, col := ms.conn(colApr)
+	defer session.Close()
+	if err = col.Find(bson.M{"id": id}).One(&apiRole); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetAPIRoleDrv stores an APIRole into dataDB
+func (ms *MongoStorage) SetAPIRoleDrv(apiRole *APIRole) (err error) {
+	session, col := ms.conn(colApr)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"id": apiRole.ID}, apiRole)
+	return
+}
+
+// RemAPIRoleDrv removes an APIRole from dataDB
+func (ms *MongoStorage) RemAPIRoleDrv(id string) (err error) {
+	session, col := ms.conn(colApr)
+	defer session.Close()
+	if err = col.Remove(bson.M{"id": id}); err == mgo.ErrNotFound {
+		err = utils.ErrNotFound
+	}
+	return
+}
+
+// GetStoredSessionDrv retrieves a StoredSession from dataDB
+func (ms *MongoStorage) GetStoredSessionDrv(nodeID, cgrID, runID string) (ss *StoredSession, err error) {
+	session, col := ms.conn(colSmg)
+	defer session.Close()
+	if err = col.Find(bson.M{"nodeid": nodeID, "cgrid": cgrID, "runid": runID}).One(&ss); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetStoredSessionDrv stores a StoredSession into dataDB
+func (ms *MongoStorage) SetStoredSessionDrv(ss *StoredSession) (err error) {
+	session, col := ms.conn(colSmg)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"nodeid": ss.NodeID, "cgrid": ss.CGRID, "runid": ss.RunID}, ss)
+	return
+}
+
+// RemStoredSessionDrv removes a StoredSession from dataDB
+func (ms *MongoStorage) RemStoredSessionDrv(nodeID, cgrID, runID string) (err error) {
+	session, col := ms.conn(colSmg)
+	defer session.Close()
+	if err = col.Remove(bson.M{"nodeid": nodeID, "cgrid": cgrID, "runid": runID}); err == mgo.ErrNotFound {
+		err = utils.ErrNotFound
+	}
+	return
+}
+
 // GetThresholdProfileDrv retrieves a ThresholdProfile from dataDB
 func (ms *MongoStorage) GetThresholdProfileDrv(tenant, ID string) (tp *ThresholdProfile, err error) {
 	session, col := ms.conn(colTps)
@@ -1905,6 +2235,74 @@ func (ms *MongoStorage) RemThresholdProfileDrv(tenant, id string) (err error) {
 	return
 }
 
+// GetSupplierProfileDrv retrieves a SupplierProfile from dataDB
+func (ms *MongoStorage) GetSupplierProfileDrv(tenant, id string) (spp *SupplierProfile, err error) {
+	session, col := ms.conn(colSpp)
+	defer session.Close()
+	if err = col.Find(bson.M{"tenant": tenant, "id": id}).One(&spp); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetSupplierProfileDrv stores a SupplierProfile into DataDB
+func (ms *MongoStorage) SetSupplierProfileDrv(spp *SupplierProfile) (err error) {
+	session, col := ms.conn(colSpp)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"tenant": spp.Tenant, "id": spp.ID}, spp)
+	return
+}
+
+// RemSupplierProfileDrv removes a SupplierProfile from dataDB
+func (ms *MongoStorage) RemSupplierProfileDrv(tenant, id string) (err error) {
+	session, col := ms.conn(colSpp)
+	defer session.Close()
+	if err = col.Remove(bson.M{"tenant": tenant, "id": id}); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	return
+}
+
+// GetExchangeRateDrv retrieves an ExchangeRate from dataDB
+func (ms *MongoStorage) GetExchangeRateDrv(fromCurrency, toCurrency string) (xr *ExchangeRate, err error) {
+	session, col := ms.conn(colXrt)
+	defer session.Close()
+	if err = col.Find(bson.M{"fromcurrency": fromCurrency, "tocurrency": toCurrency}).One(&xr); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return
+}
+
+// SetExchangeRateDrv stores an ExchangeRate into DataDB
+func (ms *MongoStorage) SetExchangeRateDrv(xr *ExchangeRate) (err error) {
+	session, col := ms.conn(colXrt)
+	defer session.Close()
+	_, err = col.Upsert(bson.M{"fromcurrency": xr.FromCurrency, "tocurrency": xr.ToCurrency}, xr)
+	return
+}
+
+// RemExchangeRateDrv removes an ExchangeRate from dataDB
+func (ms *MongoStorage) RemExchangeRateDrv(fromCurrency, toCurrency string) (err error) {
+	session, col := ms.conn(colXrt)
+	defer session.Close()
+	if err = col.Remove(bson.M{"fromcurrency": fromCurrency, "tocurrency": toCurrency}); err != nil {
+		if err == mgo.ErrNotFound {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	return
+}
+
 func (ms *MongoStorage) GetThresholdDrv(tenant, id string) (r *Threshold, err error) {
 	session, col := ms.conn(colThs)
 	defer session.Close()
@@ -1241,6 +1241,68 @@ func (ms *MongoStorage) SetTPFilters(tpTHs []*utils.TPFilter) (err error) {
 	return
 }
 
+func (ms *MongoStorage) GetTPSuppliers(tpid, id string) ([]*utils.TPSupplierProfile, error) {
+	filter := bson.M{
+		"tpid": tpid,
+	}
+	if id != "" {
+		filter["id"] = id
+	}
+	var results []*utils.TPSupplierProfile
+	session, col := ms.conn(utils.TBLTPSuppliers)
+	defer session.Close()
+	err := col.Find(filter).All(&results)
+	if len(results) == 0 {
+		return results, utils.ErrNotFound
+	}
+	return results, err
+}
+
+func (ms *MongoStorage) SetTPSuppliers(tpSPPs []*utils.TPSupplierProfile) (err error) {
+	if len(tpSPPs) == 0 {
+		return
+	}
+	session, col := ms.conn(utils.TBLTPSuppliers)
+	defer session.Close()
+	tx := col.Bulk()
+	for _, tp := range tpSPPs {
+		tx.Upsert(bson.M{"tpid": tp.TPid, "id": tp.ID}, tp)
+	}
+	_, err = tx.Run()
+	return
+}
+
+func (ms *MongoStorage) GetTPExchangeRates(tpid, fromCurrency string) ([]*utils.TPExchangeRate, error) {
+	filter := bson.M{
+		"tpid": tpid,
+	}
+	if fromCurrency != "" {
+		filter["fromcurrency"] = fromCurrency
+	}
+	var results []*utils.TPExchangeRate
+	session, col := ms.conn(utils.TBLTPExchangeRates)
+	defer session.Close()
+	err := col.Find(filter).All(&results)
+	if len(results) == 0 {
+		return results, utils.ErrNotFound
+	}
+	return results, err
+}
+
+func (ms *MongoStorage) SetTPExchangeRates(tpXRs []*utils.TPExchangeRate) (err error) {
+	if len(tpXRs) == 0 {
+		return
+	}
+	session, col := ms.conn(utils.TBLTPExchangeRates)
+	defer session.Close()
+	tx := col.Bulk()
+	for _, tp := range tpXRs {
+		tx.Upsert(bson.M{"tpid": tp.TPid, "fromcurrency": tp.FromCurrency, "tocurrency": tp.ToCurrency}, tp)
+	}
+	_, err = tx.Run()
+	return
+}
+
 func (ms *MongoStorage) GetVersions(itm string) (vrs Versions, err error) {
 	session, col := ms.conn(colVer)
 	defer session.Close()
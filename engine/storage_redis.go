@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/cgrates/cgrates/cache"
 	"github.com/cgrates/cgrates/config"
@@ -1420,6 +1421,224 @@ func (rs *RedisStorage) RemStoredStatQueueDrv(tenant, id string) (err error) {
 	return
 }
 
+// GetCDRExportQueueDrv retrieves the retry queue of an online CDR export
+func (rs *RedisStorage) GetCDRExportQueueDrv(exportID string) (q *CDRExportQueue, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.CDRExportQueuePrefix+exportID).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &q)
+	return
+}
+
+// SetCDRExportQueueDrv stores the retry queue of an online CDR export
+func (rs *RedisStorage) SetCDRExportQueueDrv(q *CDRExportQueue) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(q); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.CDRExportQueuePrefix+q.ExportID, result).Err
+}
+
+// RemCDRExportQueueDrv removes the retry queue of an online CDR export
+func (rs *RedisStorage) RemCDRExportQueueDrv(exportID string) (err error) {
+	return rs.Cmd("DEL", utils.CDRExportQueuePrefix+exportID).Err
+}
+
+// GetIdempotencyRecordDrv retrieves the reply of a request carrying an IdempotencyKey
+func (rs *RedisStorage) GetIdempotencyRecordDrv(id string) (rec *IdempotencyRecord, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.IdempotencyRecordPrefix+id).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &rec)
+	return
+}
+
+// SetIdempotencyRecordDrv stores the reply of a request carrying an IdempotencyKey, letting redis expire it
+func (rs *RedisStorage) SetIdempotencyRecordDrv(rec *IdempotencyRecord) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(rec); err != nil {
+		return
+	}
+	if rec.ExpiryTime.IsZero() {
+		return rs.Cmd("SET", utils.IdempotencyRecordPrefix+rec.ID, result).Err
+	}
+	ttl := rec.ExpiryTime.Sub(time.Now()) / time.Millisecond
+	if ttl <= 0 {
+		return
+	}
+	return rs.Cmd("SET", utils.IdempotencyRecordPrefix+rec.ID, result, "PX", int64(ttl)).Err
+}
+
+// RemIdempotencyRecordDrv removes the reply of a request carrying an IdempotencyKey
+func (rs *RedisStorage) RemIdempotencyRecordDrv(id string) (err error) {
+	return rs.Cmd("DEL", utils.IdempotencyRecordPrefix+id).Err
+}
+
+// GetInvoiceDrv retrieves an Invoice from dataDB
+func (rs *RedisStorage) GetInvoiceDrv(tenant, id string) (inv *Invoice, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.InvoicePrefix+utils.ConcatenatedKey(tenant, id)).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &inv)
+	return
+}
+
+// SetInvoiceDrv stores an Invoice into dataDB
+func (rs *RedisStorage) SetInvoiceDrv(inv *Invoice) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(inv); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.InvoicePrefix+inv.TenantID(), result).Err
+}
+
+// RemInvoiceDrv removes an Invoice from dataDB
+func (rs *RedisStorage) RemInvoiceDrv(tenant, id string) (err error) {
+	return rs.Cmd("DEL", utils.InvoicePrefix+utils.ConcatenatedKey(tenant, id)).Err
+}
+
+// GetInvoiceSequenceDrv retrieves the invoice numbering sequence of a tenant
+func (rs *RedisStorage) GetInvoiceSequenceDrv(tenant string) (seq *InvoiceSequence, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.InvoiceSequencePrefix+tenant).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &seq)
+	return
+}
+
+// SetInvoiceSequenceDrv stores the invoice numbering sequence of a tenant
+func (rs *RedisStorage) SetInvoiceSequenceDrv(seq *InvoiceSequence) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(seq); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.InvoiceSequencePrefix+seq.Tenant, result).Err
+}
+
+// GetTaxProfileDrv retrieves a TaxProfile from dataDB
+func (rs *RedisStorage) GetTaxProfileDrv(tenant, id string) (txPrfl *TaxProfile, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.TaxProfilePrefix+utils.ConcatenatedKey(tenant, id)).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &txPrfl)
+	return
+}
+
+// SetTaxProfileDrv stores a TaxProfile into dataDB
+func (rs *RedisStorage) SetTaxProfileDrv(txPrfl *TaxProfile) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(txPrfl); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.TaxProfilePrefix+txPrfl.TenantID(), result).Err
+}
+
+// RemTaxProfileDrv removes a TaxProfile from dataDB
+func (rs *RedisStorage) RemTaxProfileDrv(tenant, id string) (err error) {
+	return rs.Cmd("DEL", utils.TaxProfilePrefix+utils.ConcatenatedKey(tenant, id)).Err
+}
+
+// GetAPIKeyDrv retrieves an APIKey from dataDB
+func (rs *RedisStorage) GetAPIKeyDrv(id string) (apiKey *APIKey, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.APIKeyPrefix+id).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &apiKey)
+	return
+}
+
+// SetAPIKeyDrv stores an APIKey into dataDB
+func (rs *RedisStorage) SetAPIKeyDrv(apiKey *APIKey) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(apiKey); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.APIKeyPrefix+apiKey.ID, result).Err
+}
+
+// RemAPIKeyDrv removes an APIKey from dataDB
+func (rs *RedisStorage) RemAPIKeyDrv(id string) (err error) {
+	return rs.Cmd("DEL", utils.APIKeyPrefix+id).Err
+}
+
+// GetAPIRoleDrv retrieves an APIRole from dataDB
+func (rs *RedisStorage) GetAPIRoleDrv(id string) (apiRole *APIRole, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.APIRolePrefix+id).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &apiRole)
+	return
+}
+
+// SetAPIRoleDrv stores an APIRole into dataDB
+func (rs *RedisStorage) SetAPIRoleDrv(apiRole *APIRole) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(apiRole); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.APIRolePrefix+apiRole.ID, result).Err
+}
+
+// RemAPIRoleDrv removes an APIRole from dataDB
+func (rs *RedisStorage) RemAPIRoleDrv(id string) (err error) {
+	return rs.Cmd("DEL", utils.APIRolePrefix+id).Err
+}
+
+// GetStoredSessionDrv retrieves a StoredSession from dataDB
+func (rs *RedisStorage) GetStoredSessionDrv(nodeID, cgrID, runID string) (ss *StoredSession, err error) {
+	var values []byte
+	if values, err = rs.Cmd("GET", utils.SMGSessionPrefix+StoredSessionID(nodeID, cgrID, runID)).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	err = rs.ms.Unmarshal(values, &ss)
+	return
+}
+
+// SetStoredSessionDrv stores a StoredSession into dataDB
+func (rs *RedisStorage) SetStoredSessionDrv(ss *StoredSession) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(ss); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.SMGSessionPrefix+StoredSessionID(ss.NodeID, ss.CGRID, ss.RunID), result).Err
+}
+
+// RemStoredSessionDrv removes a StoredSession from dataDB
+func (rs *RedisStorage) RemStoredSessionDrv(nodeID, cgrID, runID string) (err error) {
+	return rs.Cmd("DEL", utils.SMGSessionPrefix+StoredSessionID(nodeID, cgrID, runID)).Err
+}
+
 // GetThresholdProfileDrv retrieves a ThresholdProfile from dataDB
 func (rs *RedisStorage) GetThresholdProfileDrv(tenant, ID string) (tp *ThresholdProfile, err error) {
 	key := utils.ThresholdProfilePrefix + utils.ConcatenatedKey(tenant, ID)
@@ -1453,6 +1672,66 @@ func (rs *RedisStorage) RemThresholdProfileDrv(tenant, id string) (err error) {
 	return
 }
 
+// GetSupplierProfileDrv retrieves a SupplierProfile from dataDB
+func (rs *RedisStorage) GetSupplierProfileDrv(tenant, id string) (spp *SupplierProfile, err error) {
+	key := utils.SupplierProfilePrefix + utils.ConcatenatedKey(tenant, id)
+	var values []byte
+	if values, err = rs.Cmd("GET", key).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	if err = rs.ms.Unmarshal(values, &spp); err != nil {
+		return
+	}
+	return
+}
+
+// SetSupplierProfileDrv stores a SupplierProfile into DataDB
+func (rs *RedisStorage) SetSupplierProfileDrv(spp *SupplierProfile) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(spp); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.SupplierProfilePrefix+spp.TenantID(), result).Err
+}
+
+// RemSupplierProfileDrv removes a SupplierProfile from dataDB
+func (rs *RedisStorage) RemSupplierProfileDrv(tenant, id string) (err error) {
+	return rs.Cmd("DEL", utils.SupplierProfilePrefix+utils.ConcatenatedKey(tenant, id)).Err
+}
+
+// GetExchangeRateDrv retrieves an ExchangeRate from dataDB
+func (rs *RedisStorage) GetExchangeRateDrv(fromCurrency, toCurrency string) (xr *ExchangeRate, err error) {
+	key := utils.ExchangeRatePrefix + utils.ConcatenatedKey(fromCurrency, toCurrency)
+	var values []byte
+	if values, err = rs.Cmd("GET", key).Bytes(); err != nil {
+		if err == redis.ErrRespNil {
+			err = utils.ErrNotFound
+		}
+		return
+	}
+	if err = rs.ms.Unmarshal(values, &xr); err != nil {
+		return
+	}
+	return
+}
+
+// SetExchangeRateDrv stores an ExchangeRate into DataDB
+func (rs *RedisStorage) SetExchangeRateDrv(xr *ExchangeRate) (err error) {
+	var result []byte
+	if result, err = rs.ms.Marshal(xr); err != nil {
+		return
+	}
+	return rs.Cmd("SET", utils.ExchangeRatePrefix+xr.ID(), result).Err
+}
+
+// RemExchangeRateDrv removes an ExchangeRate from dataDB
+func (rs *RedisStorage) RemExchangeRateDrv(fromCurrency, toCurrency string) (err error) {
+	return rs.Cmd("DEL", utils.ExchangeRatePrefix+utils.ConcatenatedKey(fromCurrency, toCurrency)).Err
+}
+
 func (rs *RedisStorage) GetThresholdDrv(tenant, id string) (r *Threshold, err error) {
 	key := utils.ThresholdPrefix + utils.ConcatenatedKey(tenant, id)
 	var values []byte
@@ -0,0 +1,618 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// shardVirtualNodes is the number of points each shard occupies on the hash ring
+const shardVirtualNodes = 160
+
+// newHashRing builds the consistent hashing ring out of the shard IDs
+// the position of a shard depends only on its ID so adding a shard moves only the keys it takes over
+func newHashRing(shardIDs []string) (hr *hashRing) {
+	hr = &hashRing{owners: make(map[uint32]int)}
+	for idx, shardID := range shardIDs {
+		for i := 0; i < shardVirtualNodes; i++ {
+			point := crc32.ChecksumIEEE([]byte(shardID + utils.CONCATENATED_KEY_SEP + strconv.Itoa(i)))
+			if _, has := hr.owners[point]; has {
+				continue // collision, first shard keeps the point
+			}
+			hr.owners[point] = idx
+			hr.points = append(hr.points, point)
+		}
+	}
+	sort.Slice(hr.points, func(i, j int) bool { return hr.points[i] < hr.points[j] })
+	return
+}
+
+// hashRing maps keys to shard indexes
+type hashRing struct {
+	points []uint32       // sorted positions on the ring
+	owners map[uint32]int // position on the ring, shard index
+}
+
+// shardIdx returns the index of the shard owning the key
+func (hr *hashRing) shardIdx(key string) int {
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(hr.points), func(i int) bool { return hr.points[i] >= h })
+	if i == len(hr.points) { // wrap around the ring
+		i = 0
+	}
+	return hr.owners[hr.points[i]]
+}
+
+// NewShardedStorage builds a DataDB on top of multiple shards
+// shardIDs identify the shards on the hash ring (eg: their addresses) and should be kept stable
+func NewShardedStorage(shardIDs []string, shards []DataDB) (*ShardedStorage, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("no shards defined")
+	}
+	if len(shardIDs) != len(shards) {
+		return nil, fmt.Errorf("%d shard IDs for %d shards", len(shardIDs), len(shards))
+	}
+	return &ShardedStorage{shards: shards, ring: newHashRing(shardIDs)}, nil
+}
+
+// ShardedStorage partitions the runtime data (accounts, resources, stored stat queues,
+// thresholds, cdr stats queues) on the shards by consistent hashing on their tenant/ID.
+// The rest of the data is shared by all subsystems and replicated on every shard,
+// reads going to the first one. Task queue, load history and CDR export queues live only on the first shard.
+type ShardedStorage struct {
+	shards []DataDB
+	ring   *hashRing
+}
+
+// shard returns the shard owning the item identified by tntID
+func (ss *ShardedStorage) shard(tntID string) DataDB {
+	return ss.shards[ss.ring.shardIdx(tntID)]
+}
+
+// first returns the shard used to read the replicated data
+func (ss *ShardedStorage) first() DataDB {
+	return ss.shards[0]
+}
+
+// onAll executes f on each of the shards, returning the first error encountered
+func (ss *ShardedStorage) onAll(f func(DataDB) error) (err error) {
+	for _, shard := range ss.shards {
+		if errShrd := f(shard); errShrd != nil && err == nil {
+			err = errShrd
+		}
+	}
+	return
+}
+
+func (ss *ShardedStorage) Close() {
+	for _, shard := range ss.shards {
+		shard.Close()
+	}
+}
+
+func (ss *ShardedStorage) Flush(ignore string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.Flush(ignore) })
+}
+
+// GetKeysForPrefix merges the keys found on the shards
+func (ss *ShardedStorage) GetKeysForPrefix(prefix string) (keys []string, err error) {
+	found := make(utils.StringMap)
+	for _, shard := range ss.shards {
+		shrdKeys, err := shard.GetKeysForPrefix(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range shrdKeys {
+			if _, has := found[key]; !has {
+				found[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return
+}
+
+func (ss *ShardedStorage) RebuildReverseForPrefix(prefix string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RebuildReverseForPrefix(prefix) })
+}
+
+func (ss *ShardedStorage) GetVersions(itm string) (vrs Versions, err error) {
+	return ss.first().GetVersions(itm)
+}
+
+func (ss *ShardedStorage) SetVersions(vrs Versions, overwrite bool) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.SetVersions(vrs, overwrite) })
+}
+
+func (ss *ShardedStorage) RemoveVersions(vrs Versions) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveVersions(vrs) })
+}
+
+func (ss *ShardedStorage) SelectDatabase(dbName string) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.SelectDatabase(dbName) })
+}
+
+func (ss *ShardedStorage) GetStorageType() string {
+	return ss.first().GetStorageType()
+}
+
+func (ss *ShardedStorage) IsDBEmpty() (resp bool, err error) {
+	for _, shard := range ss.shards {
+		if resp, err = shard.IsDBEmpty(); err != nil || !resp {
+			return
+		}
+	}
+	return
+}
+
+func (ss *ShardedStorage) Marshaler() Marshaler {
+	return ss.first().Marshaler()
+}
+
+func (ss *ShardedStorage) HasDataDrv(category, subject string) (bool, error) {
+	switch category {
+	case utils.ACCOUNT_PREFIX, utils.ResourcesPrefix, utils.StatQueuePrefix, utils.ThresholdPrefix:
+		return ss.shard(subject).HasDataDrv(category, subject)
+	}
+	return ss.first().HasDataDrv(category, subject)
+}
+
+func (ss *ShardedStorage) GetRatingPlanDrv(key string) (*RatingPlan, error) {
+	return ss.first().GetRatingPlanDrv(key)
+}
+
+func (ss *ShardedStorage) SetRatingPlanDrv(rp *RatingPlan) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetRatingPlanDrv(rp) })
+}
+
+func (ss *ShardedStorage) GetRatingProfileDrv(key string) (*RatingProfile, error) {
+	return ss.first().GetRatingProfileDrv(key)
+}
+
+func (ss *ShardedStorage) SetRatingProfileDrv(rpf *RatingProfile) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetRatingProfileDrv(rpf) })
+}
+
+func (ss *ShardedStorage) RemoveRatingProfileDrv(key string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveRatingProfileDrv(key) })
+}
+
+func (ss *ShardedStorage) GetDestination(key string, skipCache bool, transactionID string) (*Destination, error) {
+	return ss.first().GetDestination(key, skipCache, transactionID)
+}
+
+func (ss *ShardedStorage) SetDestination(dest *Destination, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetDestination(dest, transactionID) })
+}
+
+func (ss *ShardedStorage) RemoveDestination(destID, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveDestination(destID, transactionID) })
+}
+
+func (ss *ShardedStorage) SetReverseDestination(dest *Destination, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetReverseDestination(dest, transactionID) })
+}
+
+func (ss *ShardedStorage) GetReverseDestination(key string, skipCache bool, transactionID string) ([]string, error) {
+	return ss.first().GetReverseDestination(key, skipCache, transactionID)
+}
+
+func (ss *ShardedStorage) UpdateReverseDestination(oldDest, newDest *Destination, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.UpdateReverseDestination(oldDest, newDest, transactionID) })
+}
+
+func (ss *ShardedStorage) GetLCRDrv(id string) (*LCR, error) {
+	return ss.first().GetLCRDrv(id)
+}
+
+func (ss *ShardedStorage) SetLCRDrv(lcr *LCR) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetLCRDrv(lcr) })
+}
+
+func (ss *ShardedStorage) SetCdrStatsDrv(cs *CdrStats) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetCdrStatsDrv(cs) })
+}
+
+func (ss *ShardedStorage) GetCdrStatsDrv(key string) (*CdrStats, error) {
+	return ss.first().GetCdrStatsDrv(key)
+}
+
+func (ss *ShardedStorage) GetAllCdrStatsDrv() ([]*CdrStats, error) {
+	return ss.first().GetAllCdrStatsDrv()
+}
+
+func (ss *ShardedStorage) GetDerivedChargersDrv(key string) (*utils.DerivedChargers, error) {
+	return ss.first().GetDerivedChargersDrv(key)
+}
+
+func (ss *ShardedStorage) SetDerivedChargers(key string, dcs *utils.DerivedChargers, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetDerivedChargers(key, dcs, transactionID) })
+}
+
+func (ss *ShardedStorage) GetActionsDrv(key string) (Actions, error) {
+	return ss.first().GetActionsDrv(key)
+}
+
+func (ss *ShardedStorage) SetActionsDrv(key string, as Actions) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetActionsDrv(key, as) })
+}
+
+func (ss *ShardedStorage) RemoveActionsDrv(key string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveActionsDrv(key) })
+}
+
+func (ss *ShardedStorage) GetSharedGroupDrv(key string) (*SharedGroup, error) {
+	return ss.first().GetSharedGroupDrv(key)
+}
+
+func (ss *ShardedStorage) SetSharedGroupDrv(sg *SharedGroup) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetSharedGroupDrv(sg) })
+}
+
+func (ss *ShardedStorage) GetActionTriggersDrv(key string) (ActionTriggers, error) {
+	return ss.first().GetActionTriggersDrv(key)
+}
+
+func (ss *ShardedStorage) SetActionTriggersDrv(key string, atrs ActionTriggers) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetActionTriggersDrv(key, atrs) })
+}
+
+func (ss *ShardedStorage) RemoveActionTriggersDrv(key string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveActionTriggersDrv(key) })
+}
+
+func (ss *ShardedStorage) GetActionPlan(key string, skipCache bool, transactionID string) (*ActionPlan, error) {
+	return ss.first().GetActionPlan(key, skipCache, transactionID)
+}
+
+func (ss *ShardedStorage) SetActionPlan(key string, ats *ActionPlan, overwrite bool, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetActionPlan(key, ats, overwrite, transactionID) })
+}
+
+func (ss *ShardedStorage) GetAllActionPlans() (map[string]*ActionPlan, error) {
+	return ss.first().GetAllActionPlans()
+}
+
+// GetAccountActionPlans reads the index replicated out of the action plans
+func (ss *ShardedStorage) GetAccountActionPlans(acntID string, skipCache bool, transactionID string) (apIDs []string, err error) {
+	return ss.first().GetAccountActionPlans(acntID, skipCache, transactionID)
+}
+
+func (ss *ShardedStorage) SetAccountActionPlans(acntID string, apIDs []string, overwrite bool) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.SetAccountActionPlans(acntID, apIDs, overwrite) })
+}
+
+func (ss *ShardedStorage) RemAccountActionPlans(acntID string, apIDs []string) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.RemAccountActionPlans(acntID, apIDs) })
+}
+
+// PushTask queues the task on the first shard so it is executed only once
+func (ss *ShardedStorage) PushTask(t *Task) error {
+	return ss.first().PushTask(t)
+}
+
+func (ss *ShardedStorage) PopTask() (*Task, error) {
+	return ss.first().PopTask()
+}
+
+func (ss *ShardedStorage) GetAccount(key string) (*Account, error) {
+	return ss.shard(key).GetAccount(key)
+}
+
+func (ss *ShardedStorage) SetAccount(acc *Account) error {
+	return ss.shard(acc.ID).SetAccount(acc)
+}
+
+func (ss *ShardedStorage) RemoveAccount(key string) error {
+	return ss.shard(key).RemoveAccount(key)
+}
+
+func (ss *ShardedStorage) GetCdrStatsQueueDrv(key string) (*CDRStatsQueue, error) {
+	return ss.shard(key).GetCdrStatsQueueDrv(key)
+}
+
+func (ss *ShardedStorage) SetCdrStatsQueueDrv(sq *CDRStatsQueue) error {
+	return ss.shard(sq.GetId()).SetCdrStatsQueueDrv(sq)
+}
+
+func (ss *ShardedStorage) GetSubscribersDrv() (map[string]*SubscriberData, error) {
+	return ss.first().GetSubscribersDrv()
+}
+
+func (ss *ShardedStorage) SetSubscriberDrv(key string, sub *SubscriberData) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetSubscriberDrv(key, sub) })
+}
+
+func (ss *ShardedStorage) RemoveSubscriberDrv(key string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveSubscriberDrv(key) })
+}
+
+func (ss *ShardedStorage) SetUserDrv(up *UserProfile) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetUserDrv(up) })
+}
+
+func (ss *ShardedStorage) GetUserDrv(key string) (*UserProfile, error) {
+	return ss.first().GetUserDrv(key)
+}
+
+func (ss *ShardedStorage) GetUsersDrv() ([]*UserProfile, error) {
+	return ss.first().GetUsersDrv()
+}
+
+func (ss *ShardedStorage) RemoveUserDrv(key string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveUserDrv(key) })
+}
+
+func (ss *ShardedStorage) SetAlias(al *Alias, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetAlias(al, transactionID) })
+}
+
+func (ss *ShardedStorage) GetAlias(key string, skipCache bool, transactionID string) (*Alias, error) {
+	return ss.first().GetAlias(key, skipCache, transactionID)
+}
+
+func (ss *ShardedStorage) RemoveAlias(id, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveAlias(id, transactionID) })
+}
+
+func (ss *ShardedStorage) SetReverseAlias(al *Alias, transactionID string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetReverseAlias(al, transactionID) })
+}
+
+func (ss *ShardedStorage) GetReverseAlias(reverseID string, skipCache bool, transactionID string) ([]string, error) {
+	return ss.first().GetReverseAlias(reverseID, skipCache, transactionID)
+}
+
+func (ss *ShardedStorage) GetResourceProfileDrv(tenant, id string) (*ResourceProfile, error) {
+	return ss.first().GetResourceProfileDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetResourceProfileDrv(rsp *ResourceProfile) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetResourceProfileDrv(rsp) })
+}
+
+func (ss *ShardedStorage) RemoveResourceProfileDrv(tenant, id string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveResourceProfileDrv(tenant, id) })
+}
+
+func (ss *ShardedStorage) GetResourceDrv(tenant, id string) (*Resource, error) {
+	return ss.shard(utils.ConcatenatedKey(tenant, id)).GetResourceDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetResourceDrv(r *Resource) error {
+	return ss.shard(r.TenantID()).SetResourceDrv(r)
+}
+
+func (ss *ShardedStorage) RemoveResourceDrv(tenant, id string) error {
+	return ss.shard(utils.ConcatenatedKey(tenant, id)).RemoveResourceDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) GetTimingDrv(id string) (*utils.TPTiming, error) {
+	return ss.first().GetTimingDrv(id)
+}
+
+func (ss *ShardedStorage) SetTimingDrv(t *utils.TPTiming) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetTimingDrv(t) })
+}
+
+func (ss *ShardedStorage) RemoveTimingDrv(id string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveTimingDrv(id) })
+}
+
+func (ss *ShardedStorage) GetLoadHistory(limit int, skipCache bool, transactionID string) ([]*utils.LoadInstance, error) {
+	return ss.first().GetLoadHistory(limit, skipCache, transactionID)
+}
+
+func (ss *ShardedStorage) AddLoadHistory(ldInst *utils.LoadInstance, loadHistSize int, transactionID string) error {
+	return ss.first().AddLoadHistory(ldInst, loadHistSize, transactionID)
+}
+
+func (ss *ShardedStorage) GetReqFilterIndexesDrv(dbKey string) (indexes map[string]map[string]utils.StringMap, err error) {
+	return ss.first().GetReqFilterIndexesDrv(dbKey)
+}
+
+func (ss *ShardedStorage) SetReqFilterIndexesDrv(dbKey string, indexes map[string]map[string]utils.StringMap) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.SetReqFilterIndexesDrv(dbKey, indexes) })
+}
+
+func (ss *ShardedStorage) MatchReqFilterIndex(dbKey, fieldName, fieldVal string) (itemIDs utils.StringMap, err error) {
+	return ss.first().MatchReqFilterIndex(dbKey, fieldName, fieldVal)
+}
+
+func (ss *ShardedStorage) GetStatQueueProfileDrv(tenant, id string) (sq *StatQueueProfile, err error) {
+	return ss.first().GetStatQueueProfileDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetStatQueueProfileDrv(sq *StatQueueProfile) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.SetStatQueueProfileDrv(sq) })
+}
+
+func (ss *ShardedStorage) RemStatQueueProfileDrv(tenant, id string) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.RemStatQueueProfileDrv(tenant, id) })
+}
+
+func (ss *ShardedStorage) GetStoredStatQueueDrv(tenant, id string) (sq *StoredStatQueue, err error) {
+	return ss.shard(utils.ConcatenatedKey(tenant, id)).GetStoredStatQueueDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetStoredStatQueueDrv(sq *StoredStatQueue) (err error) {
+	return ss.shard(utils.ConcatenatedKey(sq.Tenant, sq.ID)).SetStoredStatQueueDrv(sq)
+}
+
+func (ss *ShardedStorage) RemStoredStatQueueDrv(tenant, id string) (err error) {
+	return ss.shard(utils.ConcatenatedKey(tenant, id)).RemStoredStatQueueDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) GetCDRExportQueueDrv(exportID string) (q *CDRExportQueue, err error) {
+	return ss.first().GetCDRExportQueueDrv(exportID)
+}
+
+func (ss *ShardedStorage) SetCDRExportQueueDrv(q *CDRExportQueue) (err error) {
+	return ss.first().SetCDRExportQueueDrv(q)
+}
+
+func (ss *ShardedStorage) RemCDRExportQueueDrv(exportID string) (err error) {
+	return ss.first().RemCDRExportQueueDrv(exportID)
+}
+
+func (ss *ShardedStorage) GetIdempotencyRecordDrv(id string) (rec *IdempotencyRecord, err error) {
+	return ss.shard(id).GetIdempotencyRecordDrv(id)
+}
+
+func (ss *ShardedStorage) SetIdempotencyRecordDrv(rec *IdempotencyRecord) (err error) {
+	return ss.shard(rec.ID).SetIdempotencyRecordDrv(rec)
+}
+
+func (ss *ShardedStorage) RemIdempotencyRecordDrv(id string) (err error) {
+	return ss.shard(id).RemIdempotencyRecordDrv(id)
+}
+
+func (ss *ShardedStorage) GetInvoiceDrv(tenant, id string) (inv *Invoice, err error) {
+	return ss.first().GetInvoiceDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetInvoiceDrv(inv *Invoice) (err error) {
+	return ss.first().SetInvoiceDrv(inv)
+}
+
+func (ss *ShardedStorage) RemInvoiceDrv(tenant, id string) (err error) {
+	return ss.first().RemInvoiceDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) GetInvoiceSequenceDrv(tenant string) (seq *InvoiceSequence, err error) {
+	return ss.first().GetInvoiceSequenceDrv(tenant)
+}
+
+func (ss *ShardedStorage) SetInvoiceSequenceDrv(seq *InvoiceSequence) (err error) {
+	return ss.first().SetInvoiceSequenceDrv(seq)
+}
+
+func (ss *ShardedStorage) GetTaxProfileDrv(tenant, id string) (txPrfl *TaxProfile, err error) {
+	return ss.first().GetTaxProfileDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetTaxProfileDrv(txPrfl *TaxProfile) (err error) {
+	return ss.first().SetTaxProfileDrv(txPrfl)
+}
+
+func (ss *ShardedStorage) RemTaxProfileDrv(tenant, id string) (err error) {
+	return ss.first().RemTaxProfileDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) GetAPIKeyDrv(id string) (apiKey *APIKey, err error) {
+	return ss.first().GetAPIKeyDrv(id)
+}
+
+func (ss *ShardedStorage) SetAPIKeyDrv(apiKey *APIKey) (err error) {
+	return ss.first().SetAPIKeyDrv(apiKey)
+}
+
+func (ss *ShardedStorage) RemAPIKeyDrv(id string) (err error) {
+	return ss.first().RemAPIKeyDrv(id)
+}
+
+func (ss *ShardedStorage) GetAPIRoleDrv(id string) (apiRole *APIRole, err error) {
+	return ss.first().GetAPIRoleDrv(id)
+}
+
+func (ss *ShardedStorage) SetAPIRoleDrv(apiRole *APIRole) (err error) {
+	return ss.first().SetAPIRoleDrv(apiRole)
+}
+
+func (ss *ShardedStorage) RemAPIRoleDrv(id string) (err error) {
+	return ss.first().RemAPIRoleDrv(id)
+}
+
+func (ss *ShardedStorage) GetStoredSessionDrv(nodeID, cgrID, runID string) (s *StoredSession, err error) {
+	return ss.shard(StoredSessionID(nodeID, cgrID, runID)).GetStoredSessionDrv(nodeID, cgrID, runID)
+}
+
+func (ss *ShardedStorage) SetStoredSessionDrv(s *StoredSession) (err error) {
+	return ss.shard(StoredSessionID(s.NodeID, s.CGRID, s.RunID)).SetStoredSessionDrv(s)
+}
+
+func (ss *ShardedStorage) RemStoredSessionDrv(nodeID, cgrID, runID string) (err error) {
+	return ss.shard(StoredSessionID(nodeID, cgrID, runID)).RemStoredSessionDrv(nodeID, cgrID, runID)
+}
+
+func (ss *ShardedStorage) GetThresholdProfileDrv(tenant, id string) (tp *ThresholdProfile, err error) {
+	return ss.first().GetThresholdProfileDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetThresholdProfileDrv(tp *ThresholdProfile) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.SetThresholdProfileDrv(tp) })
+}
+
+func (ss *ShardedStorage) RemThresholdProfileDrv(tenant, id string) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.RemThresholdProfileDrv(tenant, id) })
+}
+
+func (ss *ShardedStorage) GetThresholdDrv(tenant, id string) (*Threshold, error) {
+	return ss.shard(utils.ConcatenatedKey(tenant, id)).GetThresholdDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetThresholdDrv(t *Threshold) error {
+	return ss.shard(t.TenantID()).SetThresholdDrv(t)
+}
+
+func (ss *ShardedStorage) RemoveThresholdDrv(tenant, id string) error {
+	return ss.shard(utils.ConcatenatedKey(tenant, id)).RemoveThresholdDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) GetSupplierProfileDrv(tenant, id string) (spp *SupplierProfile, err error) {
+	return ss.first().GetSupplierProfileDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetSupplierProfileDrv(spp *SupplierProfile) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.SetSupplierProfileDrv(spp) })
+}
+
+func (ss *ShardedStorage) RemSupplierProfileDrv(tenant, id string) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.RemSupplierProfileDrv(tenant, id) })
+}
+
+func (ss *ShardedStorage) GetExchangeRateDrv(fromCurrency, toCurrency string) (xr *ExchangeRate, err error) {
+	return ss.first().GetExchangeRateDrv(fromCurrency, toCurrency)
+}
+
+func (ss *ShardedStorage) SetExchangeRateDrv(xr *ExchangeRate) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.SetExchangeRateDrv(xr) })
+}
+
+func (ss *ShardedStorage) RemExchangeRateDrv(fromCurrency, toCurrency string) (err error) {
+	return ss.onAll(func(shard DataDB) error { return shard.RemExchangeRateDrv(fromCurrency, toCurrency) })
+}
+
+func (ss *ShardedStorage) GetFilterDrv(tenant, id string) (*Filter, error) {
+	return ss.first().GetFilterDrv(tenant, id)
+}
+
+func (ss *ShardedStorage) SetFilterDrv(fltr *Filter) error {
+	return ss.onAll(func(shard DataDB) error { return shard.SetFilterDrv(fltr) })
+}
+
+func (ss *ShardedStorage) RemoveFilterDrv(tenant, id string) error {
+	return ss.onAll(func(shard DataDB) error { return shard.RemoveFilterDrv(tenant, id) })
+}
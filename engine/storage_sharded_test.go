@@ -0,0 +1,153 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+func newTestShardedStorage(t *testing.T, shardIDs []string) (ss *ShardedStorage, shards []DataDB) {
+	shards = make([]DataDB, len(shardIDs))
+	for i := range shardIDs {
+		shards[i], _ = NewMapStorage()
+	}
+	ss, err := NewShardedStorage(shardIDs, shards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestShardedStorageHashRing(t *testing.T) {
+	hr := newHashRing([]string{"127.0.0.1:6379", "127.0.0.1:6380", "127.0.0.1:6381"})
+	hrExtended := newHashRing([]string{"127.0.0.1:6379", "127.0.0.1:6380", "127.0.0.1:6381", "127.0.0.1:6382"})
+	shardKeys := make(map[int]int)
+	var moved int
+	for i := 0; i < 3000; i++ {
+		key := utils.ConcatenatedKey("cgrates.org", fmt.Sprintf("%d", 1000+i))
+		idx := hr.shardIdx(key)
+		shardKeys[idx]++
+		if idxExt := hrExtended.shardIdx(key); idxExt != idx {
+			if idxExt != 3 {
+				t.Errorf("Key %s moved from shard %d to existing shard %d", key, idx, idxExt)
+			}
+			moved++
+		}
+	}
+	for idx := 0; idx < 3; idx++ {
+		if shardKeys[idx] < 500 { // 1000 expected on each
+			t.Errorf("Unbalanced shards: %+v", shardKeys)
+		}
+	}
+	if moved < 400 || moved > 1200 { // 750 expected to move on the new shard
+		t.Errorf("Moved %d keys", moved)
+	}
+}
+
+func TestShardedStoragePartitionedData(t *testing.T) {
+	ss, shards := newTestShardedStorage(t, []string{"shard1", "shard2"})
+	for i := 0; i < 20; i++ {
+		acntID := utils.ConcatenatedKey("cgrates.org", fmt.Sprintf("acnt%d", i))
+		if err := ss.SetAccount(&Account{ID: acntID, AllowNegative: true}); err != nil {
+			t.Fatal(err)
+		}
+		if acnt, err := ss.GetAccount(acntID); err != nil {
+			t.Error(err)
+		} else if acnt.ID != acntID {
+			t.Errorf("Received account: %+v", acnt)
+		}
+		var found int
+		for idx, shard := range shards {
+			if _, err := shard.GetAccount(acntID); err == nil {
+				found++
+				if idx != ss.ring.shardIdx(acntID) {
+					t.Errorf("Account %s stored on shard %d", acntID, idx)
+				}
+			}
+		}
+		if found != 1 {
+			t.Errorf("Account %s found on %d shards", acntID, found)
+		}
+	}
+	for idx, shard := range shards {
+		if keys, _ := shard.GetKeysForPrefix(utils.ACCOUNT_PREFIX); len(keys) == 0 {
+			t.Errorf("No accounts on shard %d", idx)
+		}
+	}
+	if keys, err := ss.GetKeysForPrefix(utils.ACCOUNT_PREFIX); err != nil {
+		t.Error(err)
+	} else if len(keys) != 20 {
+		t.Errorf("Received keys: %+v", keys)
+	}
+	r := &Resource{Tenant: "cgrates.org", ID: "RES1",
+		Usages: map[string]*ResourceUsage{"RU1": &ResourceUsage{Tenant: "cgrates.org", ID: "RU1", Units: 2}}}
+	if err := ss.SetResourceDrv(r); err != nil {
+		t.Fatal(err)
+	}
+	if rcv, err := ss.shard(r.TenantID()).GetResourceDrv(r.Tenant, r.ID); err != nil {
+		t.Error(err)
+	} else if rcv.Usages["RU1"].Units != 2 {
+		t.Errorf("Received resource: %+v", rcv)
+	}
+	if has, err := ss.HasDataDrv(utils.ResourcesPrefix, r.TenantID()); err != nil || !has {
+		t.Errorf("Resource not found, err: %v", err)
+	}
+	if err := ss.RemoveResourceDrv(r.Tenant, r.ID); err != nil {
+		t.Error(err)
+	}
+	if _, err := ss.GetResourceDrv(r.Tenant, r.ID); err != utils.ErrNotFound {
+		t.Error(err)
+	}
+}
+
+func TestShardedStorageReplicatedData(t *testing.T) {
+	ss, shards := newTestShardedStorage(t, []string{"shard1", "shard2", "shard3"})
+	fltr := &Filter{Tenant: "cgrates.org", ID: "FLTR_1",
+		RequestFilters: []*RequestFilter{
+			&RequestFilter{FieldName: utils.ACCOUNT, Type: MetaString, Values: []string{"1001"}}}}
+	if err := ss.SetFilterDrv(fltr); err != nil {
+		t.Fatal(err)
+	}
+	for idx, shard := range shards {
+		if rcv, err := shard.GetFilterDrv(fltr.Tenant, fltr.ID); err != nil {
+			t.Errorf("Shard %d, error: %v", idx, err)
+		} else if !reflect.DeepEqual(fltr.RequestFilters[0].Values, rcv.RequestFilters[0].Values) {
+			t.Errorf("Shard %d, received filter: %+v", idx, rcv)
+		}
+	}
+	if keys, err := ss.GetKeysForPrefix(utils.FilterPrefix); err != nil {
+		t.Error(err)
+	} else if len(keys) != 1 {
+		t.Errorf("Received keys: %+v", keys)
+	}
+	if err := ss.RemoveFilterDrv(fltr.Tenant, fltr.ID); err != nil {
+		t.Error(err)
+	}
+	for idx, shard := range shards {
+		if _, err := shard.GetFilterDrv(fltr.Tenant, fltr.ID); err != utils.ErrNotFound {
+			t.Errorf("Shard %d, error: %v", idx, err)
+		}
+	}
+	if empty, err := ss.IsDBEmpty(); err != nil || !empty {
+		t.Errorf("Empty: %v, err: %v", empty, err)
+	}
+}
@@ -209,7 +209,7 @@ func (self *SQLStorage) RemTpData(table, tpid string, args map[string]string) er
 	if len(table) == 0 { // Remove tpid out of all tables
 		for _, tblName := range []string{utils.TBLTPTimings, utils.TBLTPDestinations, utils.TBLTPRates, utils.TBLTPDestinationRates, utils.TBLTPRatingPlans, utils.TBLTPRateProfiles,
 			utils.TBLTPSharedGroups, utils.TBLTPCdrStats, utils.TBLTPLcrs, utils.TBLTPActions, utils.TBLTPActionPlans, utils.TBLTPActionTriggers, utils.TBLTPAccountActions,
-			utils.TBLTPDerivedChargers, utils.TBLTPAliases, utils.TBLTPUsers, utils.TBLTPResources, utils.TBLTPStats, utils.TBLTPFilters} {
+			utils.TBLTPDerivedChargers, utils.TBLTPAliases, utils.TBLTPUsers, utils.TBLTPResources, utils.TBLTPStats, utils.TBLTPFilters, utils.TBLTPSuppliers, utils.TBLTPExchangeRates} {
 			if err := tx.Table(tblName).Where("tpid = ?", tpid).Delete(nil).Error; err != nil {
 				tx.Rollback()
 				return err
@@ -664,6 +664,49 @@ func (self *SQLStorage) SetTPFilters(ths []*utils.TPFilter) error {
 	return nil
 }
 
+func (self *SQLStorage) SetTPSuppliers(tpSPPs []*utils.TPSupplierProfile) error {
+	if len(tpSPPs) == 0 {
+		return nil
+	}
+	tx := self.db.Begin()
+	for _, spp := range tpSPPs {
+		// Remove previous
+		if err := tx.Where(&TpSupplier{Tpid: spp.TPid, ID: spp.ID}).Delete(TpSupplier{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		for _, mst := range APItoModelTPSuppliers(spp) {
+			if err := tx.Save(&mst).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+func (self *SQLStorage) SetTPExchangeRates(tpXRs []*utils.TPExchangeRate) error {
+	if len(tpXRs) == 0 {
+		return nil
+	}
+	tx := self.db.Begin()
+	for _, xr := range tpXRs {
+		// Remove previous
+		if err := tx.Where(&TpExchangeRate{Tpid: xr.TPid, FromCurrency: xr.FromCurrency,
+			ToCurrency: xr.ToCurrency}).Delete(TpExchangeRate{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Save(APItoModelTPExchangeRate(xr)).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
 func (self *SQLStorage) SetSMCost(smc *SMCost) error {
 	if smc.CostDetails == nil {
 		return nil
@@ -1642,6 +1685,38 @@ func (self *SQLStorage) GetTPFilters(tpid, id string) ([]*utils.TPFilter, error)
 	return aths, nil
 }
 
+func (self *SQLStorage) GetTPSuppliers(tpid, id string) ([]*utils.TPSupplierProfile, error) {
+	var spps TpSuppliers
+	q := self.db.Where("tpid = ?", tpid)
+	if len(id) != 0 {
+		q = q.Where("id = ?", id)
+	}
+	if err := q.Find(&spps).Error; err != nil {
+		return nil, err
+	}
+	aSpps := spps.AsTPSuppliers()
+	if len(aSpps) == 0 {
+		return aSpps, utils.ErrNotFound
+	}
+	return aSpps, nil
+}
+
+func (self *SQLStorage) GetTPExchangeRates(tpid, fromCurrency string) ([]*utils.TPExchangeRate, error) {
+	var xrs TpExchangeRates
+	q := self.db.Where("tpid = ?", tpid)
+	if len(fromCurrency) != 0 {
+		q = q.Where("from_currency = ?", fromCurrency)
+	}
+	if err := q.Find(&xrs).Error; err != nil {
+		return nil, err
+	}
+	aXRs := xrs.AsTPExchangeRates()
+	if len(aXRs) == 0 {
+		return aXRs, utils.ErrNotFound
+	}
+	return aXRs, nil
+}
+
 // GetVersions returns slice of all versions or a specific version if tag is specified
 func (self *SQLStorage) GetVersions(itm string) (vrs Versions, err error) {
 	q := self.db.Model(&TBLVersion{})
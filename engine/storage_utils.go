@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/cgrates/cgrates/config"
 	"github.com/cgrates/cgrates/utils"
@@ -44,12 +45,32 @@ func ConfigureDataStorage(db_type, host, port, name, user, pass, marshaler strin
 		}
 		d, err = NewRedisStorage(host, db_nb, pass, marshaler, utils.REDIS_MAX_CONNS, cacheCfg, loadHistorySize)
 		dm = NewDataManager(d.(DataDB))
+	case utils.REDIS_SHARDED: // host contains the addresses of the shards, separated by ;
+		var db_nb int
+		db_nb, err = strconv.Atoi(name)
+		if err != nil {
+			utils.Logger.Crit("Redis db name must be an integer!")
+			return nil, err
+		}
+		shardAddrs := strings.Split(host, utils.INFIELD_SEP)
+		shards := make([]DataDB, len(shardAddrs))
+		for i, shardAddr := range shardAddrs {
+			if port != "" && !strings.Contains(shardAddr, ":") {
+				shardAddr += ":" + port
+				shardAddrs[i] = shardAddr
+			}
+			if shards[i], err = NewRedisStorage(shardAddr, db_nb, pass, marshaler, utils.REDIS_MAX_CONNS, cacheCfg, loadHistorySize); err != nil {
+				return nil, err
+			}
+		}
+		d, err = NewShardedStorage(shardAddrs, shards)
+		dm = NewDataManager(d)
 	case utils.MONGO:
 		d, err = NewMongoStorage(host, port, name, user, pass, utils.DataDB, nil, cacheCfg, loadHistorySize)
 		dm = NewDataManager(d.(DataDB))
 	default:
-		err = errors.New(fmt.Sprintf("Unknown db '%s' valid options are '%s' or '%s'",
-			db_type, utils.REDIS, utils.MONGO))
+		err = errors.New(fmt.Sprintf("Unknown db '%s' valid options are '%s', '%s' or '%s'",
+			db_type, utils.REDIS, utils.REDIS_SHARDED, utils.MONGO))
 	}
 	if err != nil {
 		return nil, err
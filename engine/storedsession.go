@@ -0,0 +1,45 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"time"
+
+	"github.com/cgrates/cgrates/utils"
+)
+
+// StoredSession is the state of one active session run, persisted by SMGeneric so it can be recovered after restarts
+type StoredSession struct {
+	NodeID        string // InstanceID of the engine owning the session, engines can share the DataDB
+	CGRID         string
+	RunID         string
+	Timezone      string
+	EventStart    map[string]interface{}
+	CD            *CallDescriptor // updated on each debit
+	EventCost     *EventCost      // costs debited so far
+	ExtraDuration time.Duration
+	LastUsage     time.Duration
+	LastDebit     time.Duration
+	TotalUsage    time.Duration
+}
+
+// StoredSessionID returns the ID a session run is stored with
+func StoredSessionID(nodeID, cgrID, runID string) string {
+	return utils.ConcatenatedKey(nodeID, cgrID, runID)
+}
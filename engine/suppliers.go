@@ -0,0 +1,522 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/guardian"
+	"github.com/cgrates/cgrates/utils"
+	"github.com/cgrates/rpcclient"
+)
+
+// qosLowerIsBetter lists the metrics where suppliers with smaller values are preferred
+var qosLowerIsBetter = []string{utils.MetaPDD, utils.MetaACC, utils.MetaTCC,
+	utils.MetaCostPercentile, utils.MetaStdDevACD, utils.MetaStdDevACC}
+
+// Supplier defines one supplier within a SupplierProfile
+type Supplier struct {
+	ID                 string // supplier ID
+	FilterIDs          []string
+	RatingSubject      string   // subject used when calculating the cost, defaults to ID
+	ResourceIDs        []string // resources limiting the capacity of the supplier
+	StatIDs            []string // queues providing the QoS metrics of the supplier
+	Weight             float64
+	SupplierParameters string // opaque parameters passed back to the caller
+}
+
+// ratingSubject returns the subject used when rating the supplier
+func (spl *Supplier) ratingSubject() string {
+	if spl.RatingSubject != "" {
+		return spl.RatingSubject
+	}
+	return spl.ID
+}
+
+// SupplierProfile groups the suppliers applying to the events passing the filters
+type SupplierProfile struct {
+	Tenant             string
+	ID                 string
+	FilterIDs          []string
+	ActivationInterval *utils.ActivationInterval // Time when this profile becomes active and expires
+	Sorting            string                    // *weight, *least_cost, *highest_cost, *qos
+	SortingParams      []string                  // metric IDs considered by *qos sorting, in order of importance
+	Suppliers          []*Supplier
+	Weight             float64 // the heaviest matching profile wins
+}
+
+func (spp *SupplierProfile) TenantID() string {
+	return utils.ConcatenatedKey(spp.Tenant, spp.ID)
+}
+
+// SupplierProfiles is a sortable list of SupplierProfile
+type SupplierProfiles []*SupplierProfile
+
+// Sort is part of sort interface, sort based on Weight
+func (lps SupplierProfiles) Sort() {
+	sort.Slice(lps, func(i, j int) bool { return lps[i].Weight > lps[j].Weight })
+}
+
+// SupplierEvent is an event processed by SupplierService
+type SupplierEvent struct {
+	Tenant string
+	ID     string
+	Event  map[string]interface{}
+}
+
+func (se *SupplierEvent) TenantID() string {
+	return utils.ConcatenatedKey(se.Tenant, se.ID)
+}
+
+// FieldAsString returns the value of a field as string, ErrNotFound if missing
+func (se *SupplierEvent) FieldAsString(fldName string) (val string, err error) {
+	iface, has := se.Event[fldName]
+	if !has {
+		return "", utils.ErrNotFound
+	}
+	var canCast bool
+	if val, canCast = utils.CastFieldIfToString(iface); !canCast {
+		return "", fmt.Errorf("cannot cast %s to string", fldName)
+	}
+	return
+}
+
+// FieldAsTime returns the value of a field as time.Time, ErrNotFound if missing
+func (se *SupplierEvent) FieldAsTime(fldName string, timezone string) (t time.Time, err error) {
+	iface, has := se.Event[fldName]
+	if !has {
+		return t, utils.ErrNotFound
+	}
+	if t, canCast := iface.(time.Time); canCast {
+		return t, nil
+	}
+	s, canCast := iface.(string)
+	if !canCast {
+		return t, fmt.Errorf("cannot cast %s to string", fldName)
+	}
+	return utils.ParseTimeDetectLayout(s, timezone)
+}
+
+// FieldAsDuration returns the value of a field as time.Duration, ErrNotFound if missing
+func (se *SupplierEvent) FieldAsDuration(fldName string) (d time.Duration, err error) {
+	iface, has := se.Event[fldName]
+	if !has {
+		return d, utils.ErrNotFound
+	}
+	if d, canCast := iface.(time.Duration); canCast {
+		return d, nil
+	}
+	if f, canCast := iface.(float64); canCast {
+		return time.Duration(int64(f)), nil
+	}
+	s, canCast := iface.(string)
+	if !canCast {
+		return d, fmt.Errorf("cannot cast %s to string", fldName)
+	}
+	return utils.ParseDurationWithSecs(s)
+}
+
+// SortedSupplier is one supplier returned to the caller
+type SortedSupplier struct {
+	SupplierID         string
+	SupplierParameters string
+	Weight             float64
+	Cost               float64            // -1 when not calculated
+	Metrics            map[string]float64 // QoS metrics out of StatS
+	Reasons            []string           // why the supplier was placed or left out
+}
+
+// SortedSuppliers is the reply of SupplierService
+type SortedSuppliers struct {
+	ProfileID        string
+	Sorting          string
+	SortedSuppliers  []*SortedSupplier // ordered, the best one first
+	IgnoredSuppliers []*SortedSupplier // left out of routing, see their Reasons
+}
+
+// SupplierIDs returns the IDs of the sorted suppliers, in order
+func (sSpls *SortedSuppliers) SupplierIDs() (sIDs []string) {
+	sIDs = make([]string, len(sSpls.SortedSuppliers))
+	for i, spl := range sSpls.SortedSuppliers {
+		sIDs[i] = spl.SupplierID
+	}
+	return
+}
+
+// qosBetter compares the metrics of two suppliers based on the metric IDs, in order of importance
+// returns 1 if first is better, -1 if second is better, 0 if equal
+func qosBetter(m1, m2 map[string]float64, metricIDs []string) int {
+	for _, mID := range metricIDs {
+		v1, has1 := m1[mID]
+		if has1 && v1 < 0 { // STATS_NA
+			has1 = false
+		}
+		v2, has2 := m2[mID]
+		if has2 && v2 < 0 {
+			has2 = false
+		}
+		switch {
+		case !has1 && !has2:
+			continue
+		case !has2:
+			return 1
+		case !has1:
+			return -1
+		case v1 == v2:
+			continue
+		}
+		better := v1 > v2
+		metricType := mID // parameterized metrics like *cost_percentile:95 sort as their type
+		if idx := strings.Index(mID, utils.CONCATENATED_KEY_SEP); idx != -1 {
+			metricType = mID[:idx]
+		}
+		if utils.IsSliceMember(qosLowerIsBetter, metricType) {
+			better = !better
+		}
+		if better {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// sortSuppliers orders the suppliers based on strategy, falling back on Weight on ties
+func sortSuppliers(spls []*SortedSupplier, sorting string, sortingParams []string) (err error) {
+	var less func(i, j int) bool
+	switch sorting {
+	case utils.MetaWeight, "":
+		less = func(i, j int) bool { return spls[i].Weight > spls[j].Weight }
+	case utils.MetaLeastCost:
+		less = func(i, j int) bool {
+			if spls[i].Cost == spls[j].Cost {
+				return spls[i].Weight > spls[j].Weight
+			}
+			return spls[i].Cost < spls[j].Cost
+		}
+	case utils.MetaHighestCost:
+		less = func(i, j int) bool {
+			if spls[i].Cost == spls[j].Cost {
+				return spls[i].Weight > spls[j].Weight
+			}
+			return spls[i].Cost > spls[j].Cost
+		}
+	case utils.MetaQOS:
+		less = func(i, j int) bool {
+			if cmp := qosBetter(spls[i].Metrics, spls[j].Metrics, sortingParams); cmp != 0 {
+				return cmp == 1
+			}
+			return spls[i].Weight > spls[j].Weight
+		}
+	default:
+		return fmt.Errorf("unsupported sorting strategy: <%s>", sorting)
+	}
+	sort.SliceStable(spls, less)
+	return
+}
+
+// NewSupplierService initializes a SupplierService
+func NewSupplierService(dm *DataManager, filterS *FilterS, indexedFields []string,
+	ralS, resourceS, statS rpcclient.RpcClientConnection) (spS *SupplierService, err error) {
+	if ralS != nil && reflect.ValueOf(ralS).IsNil() {
+		ralS = nil
+	}
+	if resourceS != nil && reflect.ValueOf(resourceS).IsNil() {
+		resourceS = nil
+	}
+	if statS != nil && reflect.ValueOf(statS).IsNil() {
+		statS = nil
+	}
+	return &SupplierService{dm: dm,
+		filterS:       filterS,
+		indexedFields: indexedFields,
+		ralS:          ralS,
+		resourceS:     resourceS,
+		statS:         statS}, nil
+}
+
+// SupplierService is the service computing the routes for an event
+type SupplierService struct {
+	dm            *DataManager
+	filterS       *FilterS
+	indexedFields []string                      // fields considered when searching for matching profiles
+	ralS          rpcclient.RpcClientConnection // calculates the cost of the suppliers
+	resourceS     rpcclient.RpcClientConnection // checks the capacity of the suppliers
+	statS         rpcclient.RpcClientConnection // provides the QoS metrics of the suppliers
+}
+
+// ListenAndServe will initialize the service
+func (spS *SupplierService) ListenAndServe(exitChan chan bool) error {
+	utils.Logger.Info("Starting Supplier Service")
+	e := <-exitChan
+	exitChan <- e // put back for the others listening for shutdown request
+	return nil
+}
+
+// Shutdown is called to shutdown the service
+func (spS *SupplierService) Shutdown() error {
+	utils.Logger.Info("<SupplierS> shutdown initialized")
+	utils.Logger.Info("<SupplierS> shutdown complete")
+	return nil
+}
+
+// matchingSupplierProfilesForEvent returns ordered list of matching profiles which are active for an Event
+func (spS *SupplierService) matchingSupplierProfilesForEvent(ev *SupplierEvent) (matchingLP SupplierProfiles, err error) {
+	sppIDs, err := matchingItemIDsForEvent(ev.Event, spS.indexedFields, spS.dm, utils.SupplierProfilesStringIndex+ev.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	lockIDs := utils.PrefixSliceItems(sppIDs.Slice(), utils.SupplierProfilesStringIndex)
+	guardian.Guardian.GuardIDs(config.CgrConfig().LockingTimeout, lockIDs...)
+	defer guardian.Guardian.UnguardIDs(lockIDs...)
+	for lpID := range sppIDs {
+		splPrfl, err := spS.dm.GetSupplierProfile(ev.Tenant, lpID, false, utils.NonTransactional)
+		if err != nil {
+			if err == utils.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if splPrfl.ActivationInterval != nil &&
+			!splPrfl.ActivationInterval.IsActiveAtTime(time.Now()) { // not active
+			continue
+		}
+		if pass, err := spS.filterS.PassFiltersForEvent(ev.Tenant, ev.Event, splPrfl.FilterIDs); err != nil {
+			return nil, err
+		} else if !pass {
+			continue
+		}
+		matchingLP = append(matchingLP, splPrfl)
+	}
+	matchingLP.Sort()
+	return
+}
+
+// costForEvent calculates the cost of the event when routed via supplier
+func (spS *SupplierService) costForEvent(ev *SupplierEvent, spl *Supplier) (cost float64, err error) {
+	if spS.ralS == nil {
+		return 0, errors.New("RALs not connected")
+	}
+	cd := &CallDescriptor{
+		Direction: utils.OUT,
+		Tenant:    ev.Tenant,
+		Category:  config.CgrConfig().DefaultCategory,
+		Subject:   spl.ratingSubject(),
+		Account:   spl.ratingSubject(),
+	}
+	if cd.Destination, err = ev.FieldAsString(utils.DESTINATION); err != nil {
+		return
+	}
+	if ctg, err := ev.FieldAsString(utils.CATEGORY); err == nil && ctg != "" {
+		cd.Category = ctg
+	}
+	if cd.TimeStart, err = ev.FieldAsTime(utils.ANSWER_TIME, config.CgrConfig().DefaultTimezone); err != nil {
+		if err != utils.ErrNotFound {
+			return
+		}
+		if cd.TimeStart, err = ev.FieldAsTime(utils.SETUP_TIME, config.CgrConfig().DefaultTimezone); err != nil {
+			if err != utils.ErrNotFound {
+				return
+			}
+			cd.TimeStart = time.Now()
+		}
+	}
+	usage, err := ev.FieldAsDuration(utils.USAGE)
+	if err != nil {
+		if err != utils.ErrNotFound {
+			return
+		}
+		usage = time.Duration(1 * time.Minute) // estimate the cost for a minute
+	}
+	cd.TimeEnd = cd.TimeStart.Add(usage)
+	cd.DurationIndex = usage
+	var cc CallCost
+	if err = spS.ralS.Call("Responder.GetCost", cd, &cc); err != nil {
+		return
+	}
+	return cc.Cost, nil
+}
+
+// statMetrics returns the metrics of the supplier, averaged over its queues
+func (spS *SupplierService) statMetrics(tenant string, spl *Supplier) (metrics map[string]float64, err error) {
+	metrics = make(map[string]float64)
+	if spS.statS == nil || len(spl.StatIDs) == 0 {
+		return
+	}
+	cnts := make(map[string]int)
+	for _, statID := range spl.StatIDs {
+		var qMetrics map[string]float64
+		if err = spS.statS.Call(utils.StatSv1GetQueueFloatMetrics,
+			&utils.TenantID{Tenant: tenant, ID: statID}, &qMetrics); err != nil {
+			return nil, err
+		}
+		for mID, val := range qMetrics {
+			if val < 0 { // not available
+				if _, has := metrics[mID]; !has {
+					metrics[mID] = val
+				}
+				continue
+			}
+			if cnts[mID] == 0 {
+				metrics[mID] = 0
+			}
+			cnts[mID] += 1
+			metrics[mID] += (val - metrics[mID]) / float64(cnts[mID]) // running average
+		}
+	}
+	return
+}
+
+// exhaustedResource returns the ID of the first resource of supplier without capacity left
+func (spS *SupplierService) exhaustedResource(tenant string, spl *Supplier) (resID string, err error) {
+	if spS.resourceS == nil {
+		return
+	}
+	for _, rID := range spl.ResourceIDs {
+		var avail float64
+		if err = spS.resourceS.Call(utils.ResourceSv1GetAvailability,
+			&utils.TenantID{Tenant: tenant, ID: rID}, &avail); err != nil {
+			return
+		}
+		if avail <= 0 {
+			return rID, nil
+		}
+	}
+	return
+}
+
+// sortedSuppliersForEvent applies the profile on event and returns the suppliers ordered
+func (spS *SupplierService) sortedSuppliersForEvent(ev *SupplierEvent, splPrfl *SupplierProfile) (sSpls *SortedSuppliers, err error) {
+	sSpls = &SortedSuppliers{ProfileID: splPrfl.ID, Sorting: splPrfl.Sorting}
+	if sSpls.Sorting == "" {
+		sSpls.Sorting = utils.MetaWeight
+	}
+	for _, spl := range splPrfl.Suppliers {
+		sSpl := &SortedSupplier{SupplierID: spl.ID,
+			SupplierParameters: spl.SupplierParameters,
+			Weight:             spl.Weight,
+			Cost:               -1}
+		if len(spl.FilterIDs) != 0 {
+			if pass, err := spS.filterS.PassFiltersForEvent(ev.Tenant, ev.Event, spl.FilterIDs); err != nil {
+				return nil, err
+			} else if !pass {
+				sSpl.Reasons = append(sSpl.Reasons, "filters not passing")
+				sSpls.IgnoredSuppliers = append(sSpls.IgnoredSuppliers, sSpl)
+				continue
+			}
+		}
+		if resID, err := spS.exhaustedResource(ev.Tenant, spl); err != nil {
+			sSpl.Reasons = append(sSpl.Reasons, fmt.Sprintf("resources unavailable: %s", err.Error()))
+			sSpls.IgnoredSuppliers = append(sSpls.IgnoredSuppliers, sSpl)
+			continue
+		} else if resID != "" {
+			sSpl.Reasons = append(sSpl.Reasons, fmt.Sprintf("resource %s exhausted", resID))
+			sSpls.IgnoredSuppliers = append(sSpls.IgnoredSuppliers, sSpl)
+			continue
+		}
+		if spS.ralS != nil {
+			if cost, err := spS.costForEvent(ev, spl); err != nil {
+				if sSpls.Sorting == utils.MetaLeastCost || sSpls.Sorting == utils.MetaHighestCost {
+					sSpl.Reasons = append(sSpl.Reasons, fmt.Sprintf("cost unavailable: %s", err.Error()))
+					sSpls.IgnoredSuppliers = append(sSpls.IgnoredSuppliers, sSpl)
+					continue
+				}
+			} else {
+				sSpl.Cost = cost
+			}
+		} else if sSpls.Sorting == utils.MetaLeastCost || sSpls.Sorting == utils.MetaHighestCost {
+			return nil, errors.New("RALs not connected")
+		}
+		if sSpls.Sorting == utils.MetaQOS {
+			if sSpl.Metrics, err = spS.statMetrics(ev.Tenant, spl); err != nil {
+				sSpl.Reasons = append(sSpl.Reasons, fmt.Sprintf("metrics unavailable: %s", err.Error()))
+				sSpls.IgnoredSuppliers = append(sSpls.IgnoredSuppliers, sSpl)
+				err = nil
+				continue
+			}
+		}
+		sSpls.SortedSuppliers = append(sSpls.SortedSuppliers, sSpl)
+	}
+	if err = sortSuppliers(sSpls.SortedSuppliers, sSpls.Sorting, splPrfl.SortingParams); err != nil {
+		return nil, err
+	}
+	for _, sSpl := range sSpls.SortedSuppliers {
+		switch sSpls.Sorting {
+		case utils.MetaLeastCost, utils.MetaHighestCost:
+			sSpl.Reasons = append(sSpl.Reasons,
+				fmt.Sprintf("cost: %s", strconv.FormatFloat(sSpl.Cost, 'f', -1, 64)))
+		case utils.MetaQOS:
+			for _, mID := range splPrfl.SortingParams {
+				val, has := sSpl.Metrics[mID]
+				if !has || val < 0 {
+					sSpl.Reasons = append(sSpl.Reasons, fmt.Sprintf("%s: %s", mID, utils.NOT_AVAILABLE))
+					continue
+				}
+				sSpl.Reasons = append(sSpl.Reasons,
+					fmt.Sprintf("%s: %s", mID, strconv.FormatFloat(val, 'f', -1, 64)))
+			}
+		}
+		sSpl.Reasons = append(sSpl.Reasons,
+			fmt.Sprintf("weight: %s", strconv.FormatFloat(sSpl.Weight, 'f', -1, 64)))
+	}
+	return
+}
+
+// V1GetSuppliers returns the ordered list of suppliers for an event, based on the heaviest matching profile
+func (spS *SupplierService) V1GetSuppliers(ev *SupplierEvent, reply *SortedSuppliers) (err error) {
+	if missing := utils.MissingStructFields(ev, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	splPrfls, err := spS.matchingSupplierProfilesForEvent(ev)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if len(splPrfls) == 0 {
+		return utils.ErrNotFound
+	}
+	sSpls, err := spS.sortedSuppliersForEvent(ev, splPrfls[0])
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	*reply = *sSpls
+	return
+}
+
+// V1GetSupplierProfilesForEvent returns the profiles matching an event, ordered by Weight
+func (spS *SupplierService) V1GetSupplierProfilesForEvent(ev *SupplierEvent, reply *SupplierProfiles) (err error) {
+	if missing := utils.MissingStructFields(ev, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	splPrfls, err := spS.matchingSupplierProfilesForEvent(ev)
+	if err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if len(splPrfls) == 0 {
+		return utils.ErrNotFound
+	}
+	*reply = splPrfls
+	return
+}
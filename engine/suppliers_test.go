@@ -0,0 +1,226 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// testSupplierConn answers the RALs, ResourceS and StatS queries of SupplierService with canned data
+type testSupplierConn struct {
+	costs   map[string]float64            // subject: cost
+	avail   map[string]float64            // resourceID: available units
+	metrics map[string]map[string]float64 // statID: metrics
+}
+
+func (tc *testSupplierConn) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	switch serviceMethod {
+	case "Responder.GetCost":
+		cost, has := tc.costs[args.(*CallDescriptor).Subject]
+		if !has {
+			return utils.ErrNotFound
+		}
+		*reply.(*CallCost) = CallCost{Cost: cost}
+	case utils.ResourceSv1GetAvailability:
+		avail, has := tc.avail[args.(*utils.TenantID).ID]
+		if !has {
+			return utils.ErrNotFound
+		}
+		*reply.(*float64) = avail
+	case utils.StatSv1GetQueueFloatMetrics:
+		metrics, has := tc.metrics[args.(*utils.TenantID).ID]
+		if !has {
+			return utils.ErrNotFound
+		}
+		*reply.(*map[string]float64) = metrics
+	default:
+		return errors.New("unsupported method")
+	}
+	return nil
+}
+
+func TestSuppliersSortWeight(t *testing.T) {
+	spls := []*SortedSupplier{
+		&SortedSupplier{SupplierID: "spl1", Weight: 10},
+		&SortedSupplier{SupplierID: "spl2", Weight: 20},
+		&SortedSupplier{SupplierID: "spl3", Weight: 15},
+	}
+	if err := sortSuppliers(spls, utils.MetaWeight, nil); err != nil {
+		t.Fatal(err)
+	}
+	sSpls := &SortedSuppliers{SortedSuppliers: spls}
+	if eIDs := []string{"spl2", "spl3", "spl1"}; !reflect.DeepEqual(eIDs, sSpls.SupplierIDs()) {
+		t.Errorf("Expecting: %+v, received: %+v", eIDs, sSpls.SupplierIDs())
+	}
+	if err := sortSuppliers(spls, "*random", nil); err == nil {
+		t.Error("Expecting error for unsupported strategy")
+	}
+}
+
+func TestSuppliersSortCost(t *testing.T) {
+	spls := []*SortedSupplier{
+		&SortedSupplier{SupplierID: "spl1", Weight: 10, Cost: 0.5},
+		&SortedSupplier{SupplierID: "spl2", Weight: 20, Cost: 0.3},
+		&SortedSupplier{SupplierID: "spl3", Weight: 30, Cost: 0.5},
+	}
+	if err := sortSuppliers(spls, utils.MetaLeastCost, nil); err != nil {
+		t.Fatal(err)
+	}
+	sSpls := &SortedSuppliers{SortedSuppliers: spls}
+	if eIDs := []string{"spl2", "spl3", "spl1"}; !reflect.DeepEqual(eIDs, sSpls.SupplierIDs()) {
+		t.Errorf("Expecting: %+v, received: %+v", eIDs, sSpls.SupplierIDs())
+	}
+	if err := sortSuppliers(spls, utils.MetaHighestCost, nil); err != nil {
+		t.Fatal(err)
+	}
+	if eIDs := []string{"spl3", "spl1", "spl2"}; !reflect.DeepEqual(eIDs, sSpls.SupplierIDs()) {
+		t.Errorf("Expecting: %+v, received: %+v", eIDs, sSpls.SupplierIDs())
+	}
+}
+
+func TestSuppliersQOSBetter(t *testing.T) {
+	if cmp := qosBetter(map[string]float64{utils.MetaASR: 80},
+		map[string]float64{utils.MetaASR: 60}, []string{utils.MetaASR}); cmp != 1 {
+		t.Errorf("Expecting 1, received: %d", cmp)
+	}
+	if cmp := qosBetter(map[string]float64{utils.MetaPDD: 3},
+		map[string]float64{utils.MetaPDD: 2}, []string{utils.MetaPDD}); cmp != -1 { // lower is better
+		t.Errorf("Expecting -1, received: %d", cmp)
+	}
+	if cmp := qosBetter(map[string]float64{utils.MetaASR: 50, utils.MetaACD: 60},
+		map[string]float64{utils.MetaASR: 50, utils.MetaACD: 90}, []string{utils.MetaASR, utils.MetaACD}); cmp != -1 {
+		t.Errorf("Expecting -1, received: %d", cmp)
+	}
+	if cmp := qosBetter(map[string]float64{utils.MetaASR: -1},
+		map[string]float64{utils.MetaASR: 10}, []string{utils.MetaASR}); cmp != -1 { // not available loses
+		t.Errorf("Expecting -1, received: %d", cmp)
+	}
+	if cmp := qosBetter(map[string]float64{utils.MetaASR: 10},
+		map[string]float64{utils.MetaASR: 10}, []string{utils.MetaASR}); cmp != 0 {
+		t.Errorf("Expecting 0, received: %d", cmp)
+	}
+	costP95 := utils.MetaCostPercentile + utils.CONCATENATED_KEY_SEP + "95"
+	if cmp := qosBetter(map[string]float64{costP95: 0.5},
+		map[string]float64{costP95: 0.3}, []string{costP95}); cmp != -1 { // lower is better for the parameterized metric too
+		t.Errorf("Expecting -1, received: %d", cmp)
+	}
+}
+
+func TestSuppliersSortQOS(t *testing.T) {
+	spls := []*SortedSupplier{
+		&SortedSupplier{SupplierID: "spl1", Weight: 10,
+			Metrics: map[string]float64{utils.MetaASR: 50, utils.MetaPDD: 4}},
+		&SortedSupplier{SupplierID: "spl2", Weight: 20,
+			Metrics: map[string]float64{utils.MetaASR: 70, utils.MetaPDD: 5}},
+		&SortedSupplier{SupplierID: "spl3", Weight: 30,
+			Metrics: map[string]float64{utils.MetaASR: 50, utils.MetaPDD: 2}},
+		&SortedSupplier{SupplierID: "spl4", Weight: 40,
+			Metrics: map[string]float64{utils.MetaASR: 50, utils.MetaPDD: 4}},
+	}
+	if err := sortSuppliers(spls, utils.MetaQOS, []string{utils.MetaASR, utils.MetaPDD}); err != nil {
+		t.Fatal(err)
+	}
+	sSpls := &SortedSuppliers{SortedSuppliers: spls}
+	if eIDs := []string{"spl2", "spl3", "spl4", "spl1"}; !reflect.DeepEqual(eIDs, sSpls.SupplierIDs()) {
+		t.Errorf("Expecting: %+v, received: %+v", eIDs, sSpls.SupplierIDs())
+	}
+}
+
+func TestSuppliersV1GetSuppliers(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	dataDB, _ := NewMapStorage()
+	dmSpl := NewDataManager(dataDB)
+	fltrAcnt, _ := NewRequestFilter(MetaString, utils.ACCOUNT, []string{"1001"})
+	fltrDst, _ := NewRequestFilter(MetaStringPrefix, utils.DESTINATION, []string{"+49"})
+	for _, fltr := range []*Filter{
+		&Filter{Tenant: "cgrates.org", ID: "FLTR_SPL_ACNT", RequestFilters: []*RequestFilter{fltrAcnt}},
+		&Filter{Tenant: "cgrates.org", ID: "FLTR_SPL_DST", RequestFilters: []*RequestFilter{fltrDst}},
+	} {
+		if err := dmSpl.SetFilter(fltr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spp := &SupplierProfile{Tenant: "cgrates.org", ID: "SPP_TEST",
+		FilterIDs:     []string{"FLTR_SPL_ACNT"},
+		Sorting:       utils.MetaLeastCost,
+		SortingParams: []string{},
+		Suppliers: []*Supplier{
+			&Supplier{ID: "spl1", RatingSubject: "rs_spl1", Weight: 10},
+			&Supplier{ID: "spl2", Weight: 20, SupplierParameters: "param2"},
+			&Supplier{ID: "spl3", FilterIDs: []string{"FLTR_SPL_DST"}, Weight: 30},
+			&Supplier{ID: "spl4", ResourceIDs: []string{"RES_SPL4"}, Weight: 40},
+			&Supplier{ID: "spl5", Weight: 50},
+		},
+		Weight: 10}
+	if err := dmSpl.SetSupplierProfile(spp); err != nil {
+		t.Fatal(err)
+	}
+	idxr, err := NewReqFilterIndexer(dmSpl, utils.SupplierProfilesStringIndex+"cgrates.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idxr.IndexFilters(spp.ID, []*RequestFilter{fltrAcnt})
+	if err := idxr.StoreIndexes(); err != nil {
+		t.Fatal(err)
+	}
+	conn := &testSupplierConn{
+		costs: map[string]float64{"rs_spl1": 0.1, "spl2": 0.2, "spl3": 0.05, "spl4": 0.01},
+		avail: map[string]float64{"RES_SPL4": 0},
+	}
+	splS, err := NewSupplierService(dmSpl, NewFilterS(cfg, nil, dmSpl), nil, conn, conn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := &SupplierEvent{Tenant: "cgrates.org", ID: "ev1",
+		Event: map[string]interface{}{
+			utils.ACCOUNT:     "1001",
+			utils.DESTINATION: "+3312345",
+			utils.USAGE:       "2m",
+		}}
+	var reply SortedSuppliers
+	if err := splS.V1GetSuppliers(ev, &reply); err != nil {
+		t.Fatal(err)
+	}
+	eSorted := &SortedSuppliers{ProfileID: "SPP_TEST", Sorting: utils.MetaLeastCost,
+		SortedSuppliers: []*SortedSupplier{
+			&SortedSupplier{SupplierID: "spl1", Weight: 10, Cost: 0.1,
+				Reasons: []string{"cost: 0.1", "weight: 10"}},
+			&SortedSupplier{SupplierID: "spl2", SupplierParameters: "param2", Weight: 20, Cost: 0.2,
+				Reasons: []string{"cost: 0.2", "weight: 20"}},
+		},
+		IgnoredSuppliers: []*SortedSupplier{
+			&SortedSupplier{SupplierID: "spl3", Weight: 30, Cost: -1,
+				Reasons: []string{"filters not passing"}},
+			&SortedSupplier{SupplierID: "spl4", Weight: 40, Cost: -1,
+				Reasons: []string{"resource RES_SPL4 exhausted"}},
+			&SortedSupplier{SupplierID: "spl5", Weight: 50, Cost: -1,
+				Reasons: []string{"cost unavailable: NOT_FOUND"}},
+		}}
+	if !reflect.DeepEqual(eSorted, &reply) {
+		t.Errorf("Expecting: %s, received: %s", utils.ToJSON(eSorted), utils.ToJSON(reply))
+	}
+	ev.Event[utils.ACCOUNT] = "1002"
+	if err := splS.V1GetSuppliers(ev, &reply); err != utils.ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+}
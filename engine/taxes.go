@@ -0,0 +1,175 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+// TaxProfile is a tax of one jurisdiction applying to the events passing the filters
+type TaxProfile struct {
+	Tenant             string
+	ID                 string
+	FilterIDs          []string                  // no filters means all the events of the tenant
+	ActivationInterval *utils.ActivationInterval // Time when this tax becomes active and expires
+	Jurisdiction       string                    // ie: federal, state, county
+	Percent            float64                   // out of the taxable amount
+	Fixed              float64                   // added once per charged event
+	Compound           bool                      // taxable amount includes the taxes applied before
+	Exempt             bool                      // matching events pay no taxes within the jurisdiction
+	Weight             float64                   // order of application, heavier first
+}
+
+func (txPrfl *TaxProfile) TenantID() string {
+	return utils.ConcatenatedKey(txPrfl.Tenant, txPrfl.ID)
+}
+
+// TaxProfiles is a sortable list of TaxProfile
+type TaxProfiles []*TaxProfile
+
+// Sort is part of sort interface, sort based on Weight
+func (txPrfls TaxProfiles) Sort() {
+	sort.SliceStable(txPrfls, func(i, j int) bool { return txPrfls[i].Weight > txPrfls[j].Weight })
+}
+
+// TaxItem is one tax within the breakdown of an event
+type TaxItem struct {
+	ID           string // TaxProfile applied
+	Jurisdiction string
+	Amount       float64
+}
+
+// NewTaxService initializes a TaxService
+func NewTaxService(dm *DataManager, filterS *FilterS) *TaxService {
+	return &TaxService{dm: dm, filterS: filterS}
+}
+
+// TaxService computes the taxes of the events out of the TaxProfiles stored locally
+type TaxService struct {
+	dm      *DataManager
+	filterS *FilterS
+}
+
+// matchingTaxProfilesForEvent returns the TaxProfiles of the tenant active and matching the event, ordered by weight
+func (txS *TaxService) matchingTaxProfilesForEvent(tenant string, ev map[string]interface{}, atTime time.Time) (txPrfls TaxProfiles, err error) {
+	var indexedFields []string // only the fields with string values can match the *string indexes
+	for fldName, fldVal := range ev {
+		if _, canCast := utils.CastFieldIfToString(fldVal); canCast {
+			indexedFields = append(indexedFields, fldName)
+		}
+	}
+	txIDs, err := matchingItemIDsForEvent(ev, indexedFields, txS.dm, utils.TaxProfilesStringIndex+tenant)
+	if err != nil {
+		return nil, err
+	}
+	for txID := range txIDs {
+		txPrfl, err := txS.dm.GetTaxProfile(tenant, txID)
+		if err != nil {
+			if err == utils.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if txPrfl.ActivationInterval != nil &&
+			!txPrfl.ActivationInterval.IsActiveAtTime(atTime) { // not active
+			continue
+		}
+		if len(txPrfl.FilterIDs) != 0 {
+			if txS.filterS == nil {
+				return nil, errors.New("FilterS not available")
+			}
+			if pass, err := txS.filterS.PassFiltersForEvent(tenant, ev, txPrfl.FilterIDs); err != nil {
+				return nil, err
+			} else if !pass {
+				continue
+			}
+		}
+		txPrfls = append(txPrfls, txPrfl)
+	}
+	txPrfls.Sort()
+	return
+}
+
+// TaxesForEvent computes the taxes applying on the amount charged for an event.
+// Exemptions cover the whole jurisdiction, compound taxes apply on top of the taxes computed before them.
+func (txS *TaxService) TaxesForEvent(tenant string, ev map[string]interface{}, amount float64, atTime time.Time) (taxes []*TaxItem, total float64, err error) {
+	txPrfls, err := txS.matchingTaxProfilesForEvent(tenant, ev, atTime)
+	if err != nil {
+		return
+	}
+	exempted := make(utils.StringMap)
+	for _, txPrfl := range txPrfls {
+		if txPrfl.Exempt {
+			exempted[txPrfl.Jurisdiction] = true
+		}
+	}
+	base := utils.NewDecimalFromFloat64(amount)
+	taxed := utils.NewDecimalFromInt64(0)
+	for _, txPrfl := range txPrfls {
+		if exempted[txPrfl.Jurisdiction] {
+			continue
+		}
+		taxable := base
+		if txPrfl.Compound {
+			taxable = base.Add(taxed)
+		}
+		tax := taxable.Mul(utils.NewDecimalFromFloat64(txPrfl.Percent)).Div(utils.NewDecimalFromInt64(100))
+		if amount > 0 { // refunds give back only the percentage
+			tax = tax.Add(utils.NewDecimalFromFloat64(txPrfl.Fixed))
+		}
+		tax = tax.Round(config.CgrConfig().RoundingDecimals, utils.ROUNDING_MIDDLE)
+		taxed = taxed.Add(tax)
+		taxes = append(taxes, &TaxItem{ID: txPrfl.ID, Jurisdiction: txPrfl.Jurisdiction, Amount: tax.Float64()})
+	}
+	return taxes, taxed.Float64(), nil
+}
+
+// TaxCDR computes the taxes on the cost of the CDR, which becomes the total tax,
+// the breakdown per TaxProfile being stored within the ExtraFields
+func (txS *TaxService) TaxCDR(cdr *CDR) (err error) {
+	ev, err := cdr.AsMapStringIface()
+	if err != nil {
+		return
+	}
+	taxes, total, err := txS.TaxesForEvent(cdr.Tenant, ev, cdr.Cost, cdr.AnswerTime)
+	if err != nil {
+		return
+	}
+	if taxes == nil {
+		taxes = make([]*TaxItem, 0)
+	}
+	b, err := json.Marshal(taxes)
+	if err != nil {
+		return
+	}
+	if cdr.ExtraFields == nil {
+		cdr.ExtraFields = make(map[string]string)
+	}
+	cdr.ExtraFields[utils.CGRTaxes] = string(b)
+	cdr.ExtraFields[utils.CGRTaxAmount] = strconv.FormatFloat(total, 'f', -1, 64)
+	cdr.Cost = total
+	return
+}
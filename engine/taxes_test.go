@@ -0,0 +1,200 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
+)
+
+func testTaxDataManager(t *testing.T) *DataManager {
+	dataDB, _ := NewMapStorage()
+	dmTax := NewDataManager(dataDB)
+	fltrDst, _ := NewRequestFilter(MetaStringPrefix, utils.DESTINATION, []string{"+1212"})
+	fltrAcnt, _ := NewRequestFilter(MetaString, utils.ACCOUNT, []string{"1002"})
+	for _, fltr := range []*Filter{
+		&Filter{Tenant: "taxes.org", ID: "FLTR_NY", RequestFilters: []*RequestFilter{fltrDst}},
+		&Filter{Tenant: "taxes.org", ID: "FLTR_1002", RequestFilters: []*RequestFilter{fltrAcnt}}} {
+		if err := dmTax.SetFilter(fltr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, txPrfl := range []*TaxProfile{
+		&TaxProfile{Tenant: "taxes.org", ID: "TX_FEDERAL", Jurisdiction: "federal", Percent: 10, Weight: 30},
+		&TaxProfile{Tenant: "taxes.org", ID: "TX_STATE", FilterIDs: []string{"FLTR_NY"},
+			Jurisdiction: "state", Percent: 5, Compound: true, Weight: 20},
+		&TaxProfile{Tenant: "taxes.org", ID: "TX_911", FilterIDs: []string{"FLTR_NY"},
+			Jurisdiction: "county", Fixed: 0.5, Weight: 10},
+		&TaxProfile{Tenant: "taxes.org", ID: "TX_STATE_EXEMPT", FilterIDs: []string{"FLTR_1002"},
+			Jurisdiction: "state", Exempt: true},
+		&TaxProfile{Tenant: "taxes.org", ID: "TX_OLD", Jurisdiction: "federal", Percent: 50,
+			ActivationInterval: &utils.ActivationInterval{
+				ExpiryTime: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	} {
+		if err := dmTax.SetTaxProfile(txPrfl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dmTax
+}
+
+func TestTaxServiceTaxesForEvent(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	dmTax := testTaxDataManager(t)
+	txS := NewTaxService(dmTax, NewFilterS(cfg, nil, dmTax))
+	atTime := time.Date(2017, 10, 1, 0, 0, 0, 0, time.UTC)
+	ev := map[string]interface{}{utils.ACCOUNT: "1001", utils.DESTINATION: "+12125551234"}
+	eTaxes := []*TaxItem{
+		&TaxItem{ID: "TX_FEDERAL", Jurisdiction: "federal", Amount: 1},
+		&TaxItem{ID: "TX_STATE", Jurisdiction: "state", Amount: 0.55}, // compounded on top of federal
+		&TaxItem{ID: "TX_911", Jurisdiction: "county", Amount: 0.5},
+	}
+	if taxes, total, err := txS.TaxesForEvent("taxes.org", ev, 10, atTime); err != nil {
+		t.Error(err)
+	} else if total != 2.05 {
+		t.Errorf("unexpected total: %v", total)
+	} else if len(taxes) != len(eTaxes) {
+		t.Errorf("expecting: %s, received: %s", utils.ToJSON(eTaxes), utils.ToJSON(taxes))
+	} else {
+		for i, eTax := range eTaxes {
+			if *taxes[i] != *eTax {
+				t.Errorf("tax %d, expecting: %+v, received: %+v", i, eTax, taxes[i])
+			}
+		}
+	}
+	// exempted from the state taxes
+	ev[utils.ACCOUNT] = "1002"
+	if _, total, err := txS.TaxesForEvent("taxes.org", ev, 10, atTime); err != nil {
+		t.Error(err)
+	} else if total != 1.5 {
+		t.Errorf("unexpected total: %v", total)
+	}
+	// refunds do not give back the fixed taxes
+	ev[utils.ACCOUNT] = "1001"
+	if _, total, err := txS.TaxesForEvent("taxes.org", ev, -10, atTime); err != nil {
+		t.Error(err)
+	} else if total != -1.55 {
+		t.Errorf("unexpected total: %v", total)
+	}
+	// expired profile applies at older times only
+	ev[utils.DESTINATION] = "+4986517174963"
+	if taxes, total, err := txS.TaxesForEvent("taxes.org", ev, 10,
+		time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Error(err)
+	} else if total != 6 || len(taxes) != 2 {
+		t.Errorf("unexpected taxes: %s, total: %v", utils.ToJSON(taxes), total)
+	}
+	if _, total, err := txS.TaxesForEvent("other.org", ev, 10, atTime); err != nil {
+		t.Error(err)
+	} else if total != 0 {
+		t.Errorf("unexpected total: %v", total)
+	}
+}
+
+func TestTaxProfileIndexes(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	dmTax := testTaxDataManager(t)
+	eIdxs := map[string]map[string]utils.StringMap{
+		utils.ACCOUNT: map[string]utils.StringMap{
+			"1002": utils.StringMap{"TX_STATE_EXEMPT": true}},
+		utils.NOT_AVAILABLE: map[string]utils.StringMap{ // *string_prefix filters are not indexed
+			utils.NOT_AVAILABLE: utils.StringMap{"TX_FEDERAL": true, "TX_STATE": true, "TX_911": true, "TX_OLD": true}},
+	}
+	if idxs, err := dmTax.GetReqFilterIndexes(utils.TaxProfilesStringIndex + "taxes.org"); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eIdxs, idxs) {
+		t.Errorf("expecting: %s, received: %s", utils.ToJSON(eIdxs), utils.ToJSON(idxs))
+	}
+	txS := NewTaxService(dmTax, NewFilterS(cfg, nil, dmTax))
+	ev := map[string]interface{}{utils.ACCOUNT: "1002", utils.DESTINATION: "+12125551234"}
+	if _, total, err := txS.TaxesForEvent("taxes.org", ev, 10, time.Date(2017, 10, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Error(err)
+	} else if total != 1.5 {
+		t.Errorf("unexpected total: %v", total)
+	}
+	if err := dmTax.RemoveTaxProfile("taxes.org", "TX_STATE_EXEMPT"); err != nil {
+		t.Fatal(err)
+	}
+	if _, total, err := txS.TaxesForEvent("taxes.org", ev, 10, time.Date(2017, 10, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Error(err)
+	} else if total != 2.05 {
+		t.Errorf("exemption still applied, total: %v", total)
+	}
+	if err := dmTax.SetTaxProfile(&TaxProfile{Tenant: "taxes.org", ID: "TX_BROKEN",
+		FilterIDs: []string{"FLTR_MISSING"}}); err == nil {
+		t.Error("expecting error on broken filter reference")
+	}
+}
+
+func TestTaxServiceTaxCDR(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	dmTax := testTaxDataManager(t)
+	txS := NewTaxService(dmTax, NewFilterS(cfg, nil, dmTax))
+	cdr := &CDR{CGRID: "cgrid1", RunID: utils.MetaTax, Tenant: "taxes.org", Account: "1001",
+		Destination: "+12125551234", AnswerTime: time.Date(2017, 10, 1, 0, 0, 0, 0, time.UTC), Cost: 10}
+	if err := txS.TaxCDR(cdr); err != nil {
+		t.Fatal(err)
+	}
+	if cdr.Cost != 2.05 || cdr.ExtraFields[utils.CGRTaxAmount] != "2.05" {
+		t.Errorf("cost: %v, extra fields: %+v", cdr.Cost, cdr.ExtraFields)
+	}
+	if eTaxes := `[{"ID":"TX_FEDERAL","Jurisdiction":"federal","Amount":1},` +
+		`{"ID":"TX_STATE","Jurisdiction":"state","Amount":0.55},` +
+		`{"ID":"TX_911","Jurisdiction":"county","Amount":0.5}]`; cdr.ExtraFields[utils.CGRTaxes] != eTaxes {
+		t.Errorf("expecting: %s, received: %s", eTaxes, cdr.ExtraFields[utils.CGRTaxes])
+	}
+}
+
+func TestInvoiceServiceLocalTaxes(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.InvoiceSCfg().LocalTaxes = true
+	dmTax := testTaxDataManager(t)
+	iS := NewInvoiceService(cfg, dmTax, nil, NewFilterS(cfg, nil, dmTax))
+	answerTime := time.Date(2017, 10, 1, 0, 0, 0, 0, time.UTC)
+	cdrs := []*CDR{
+		&CDR{RunID: utils.META_DEFAULT, Tenant: "taxes.org", Account: "1001", ToR: utils.VOICE,
+			Destination: "+12125551234", AnswerTime: answerTime, Usage: time.Minute, Cost: 10},
+		&CDR{RunID: utils.META_DEFAULT, Tenant: "taxes.org", Account: "1001", ToR: utils.VOICE,
+			Destination: "+4986517174963", AnswerTime: answerTime, Usage: time.Minute, Cost: 5},
+	}
+	inv, err := iS.buildInvoice("taxes.org", "1001", answerTime, answerTime.Add(time.Hour), cdrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eTaxes := []*TaxItem{
+		&TaxItem{ID: "TX_911", Jurisdiction: "county", Amount: 0.5},
+		&TaxItem{ID: "TX_FEDERAL", Jurisdiction: "federal", Amount: 1.5},
+		&TaxItem{ID: "TX_STATE", Jurisdiction: "state", Amount: 0.55},
+	}
+	if len(inv.Taxes) != len(eTaxes) {
+		t.Fatalf("expecting: %s, received: %s", utils.ToJSON(eTaxes), utils.ToJSON(inv.Taxes))
+	}
+	for i, eTax := range eTaxes {
+		if *inv.Taxes[i] != *eTax {
+			t.Errorf("tax %d, expecting: %+v, received: %+v", i, eTax, inv.Taxes[i])
+		}
+	}
+	if inv.Subtotal != 15 || inv.Tax != 2.55 || inv.Total != 17.55 {
+		t.Errorf("subtotal: %v, tax: %v, total: %v", inv.Subtotal, inv.Tax, inv.Total)
+	}
+}
@@ -174,7 +174,8 @@ func NewThresholdService(dm *DataManager, indexedFields []string, storeInterval
 // ThresholdService manages Threshold execution and storing them to dataDB
 type ThresholdService struct {
 	dm            *DataManager
-	indexedFields []string // fields considered when searching for matching thresholds
+	indexedFields []string     // fields considered when searching for matching thresholds
+	idxFldsMux    sync.RWMutex // protects indexedFields
 	storeInterval time.Duration
 	filterS       *FilterS
 	stopBackup    chan struct{}
@@ -182,6 +183,13 @@ type ThresholdService struct {
 	stMux         sync.RWMutex    // protects storedTdIDs
 }
 
+// SetIndexedFields replaces the fields considered when searching for matching thresholds, ie: on config reloads
+func (tS *ThresholdService) SetIndexedFields(indexedFields []string) {
+	tS.idxFldsMux.Lock()
+	tS.indexedFields = indexedFields
+	tS.idxFldsMux.Unlock()
+}
+
 // Called to start the service
 func (tS *ThresholdService) ListenAndServe(exitChan chan bool) error {
 	go tS.runBackup() // start backup loop
@@ -264,7 +272,10 @@ func (tS *ThresholdService) StoreThreshold(t *Threshold) (err error) {
 // matchingThresholdsForEvent returns ordered list of matching thresholds which are active for an Event
 func (tS *ThresholdService) matchingThresholdsForEvent(ev *ThresholdEvent) (ts Thresholds, err error) {
 	matchingTs := make(map[string]*Threshold)
-	tIDs, err := matchingItemIDsForEvent(ev.Event, tS.indexedFields, tS.dm, utils.ThresholdStringIndex+ev.Tenant)
+	tS.idxFldsMux.RLock()
+	indexedFields := tS.indexedFields
+	tS.idxFldsMux.RUnlock()
+	tIDs, err := matchingItemIDsForEvent(ev.Event, indexedFields, tS.dm, utils.ThresholdStringIndex+ev.Tenant)
 	if err != nil {
 		return nil, err
 	}
@@ -409,3 +420,53 @@ func (tS *ThresholdService) V1GetThreshold(tntID *utils.TenantID, t *Threshold)
 	}
 	return
 }
+
+// V1ResetThreshold clears the hits and the snooze of a Threshold
+func (tS *ThresholdService) V1ResetThreshold(args *utils.TenantID, reply *string) (err error) {
+	if missing := utils.MissingStructFields(args, []string{"Tenant", "ID"}); len(missing) != 0 { //Params missing
+		return utils.NewErrMandatoryIeMissing(missing...)
+	}
+	lockID := utils.ThresholdStringIndex + args.ID
+	guardian.Guardian.GuardIDs(config.CgrConfig().LockingTimeout, lockID)
+	defer guardian.Guardian.UnguardIDs(lockID)
+	t, err := tS.dm.GetThreshold(args.Tenant, args.ID, false, "")
+	if err != nil {
+		if err != utils.ErrNotFound {
+			err = utils.NewErrServerError(err)
+		}
+		return err
+	}
+	t.Hits = 0
+	t.Snooze = time.Time{}
+	if err = tS.dm.SetThreshold(t); err != nil {
+		return utils.NewErrServerError(err)
+	}
+	if t.dirty != nil {
+		*t.dirty = false
+	}
+	*reply = utils.OK
+	return
+}
+
+// Metrics implements utils.MetricsCollector, exporting the hits of the thresholds in cache
+func (tS *ThresholdService) Metrics() []*utils.MetricFamily {
+	mf := &utils.MetricFamily{Name: "cgr_threshold_hits", Type: utils.MetricGauge,
+		Help: "Number of hits recorded by a threshold"}
+	tKeys := cache.GetEntryKeys(utils.ThresholdPrefix)
+	sort.Strings(tKeys)
+	for _, tKey := range tKeys {
+		tIf, has := cache.Get(tKey)
+		if !has || tIf == nil {
+			continue
+		}
+		t := tIf.(*Threshold)
+		lockID := utils.ThresholdStringIndex + t.ID
+		guardian.Guardian.GuardIDs(config.CgrConfig().LockingTimeout, lockID)
+		hits := t.Hits
+		guardian.Guardian.UnguardIDs(lockID)
+		mf.Samples = append(mf.Samples, &utils.MetricSample{
+			Labels: map[string]string{"tenant": t.Tenant, "id": t.ID},
+			Value:  float64(hits)})
+	}
+	return []*utils.MetricFamily{mf}
+}
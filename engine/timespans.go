@@ -79,6 +79,7 @@ type MonetaryInfo struct {
 	ID           string
 	Value        float64
 	RateInterval *RateInterval
+	ExchangeRate float64 // applied converting the cost into the balance currency, 0 when not converted
 }
 
 func (mi *MonetaryInfo) Clone() *MonetaryInfo {
@@ -94,6 +95,7 @@ func (mi *MonetaryInfo) Equal(other *MonetaryInfo) bool {
 		return false
 	}
 	return mi.UUID == other.UUID &&
+		mi.ExchangeRate == other.ExchangeRate &&
 		reflect.DeepEqual(mi.RateInterval, other.RateInterval)
 }
 
@@ -224,7 +226,7 @@ func (tss *TimeSpans) Compress() { // must be pointer receiver
 		} else {
 			cTs := cTss[len(cTss)-1]
 			cTs.CompressFactor++
-			cTs.Cost += ts.Cost
+			cTs.Cost = utils.SumFloat64(cTs.Cost, ts.Cost).Float64()
 			cTs.TimeEnd = ts.TimeEnd
 			cTs.DurationIndex = ts.DurationIndex
 		}
@@ -303,7 +305,12 @@ func (incr *Increment) GetCompressFactor() int {
 }
 
 func (incr *Increment) GetCost() float64 {
-	return float64(incr.GetCompressFactor()) * incr.Cost
+	return incr.getCostDecimal().Float64()
+}
+
+func (incr *Increment) getCostDecimal() *utils.Decimal {
+	return utils.NewDecimalFromFloat64(incr.Cost).
+		Mul(utils.NewDecimalFromInt64(int64(incr.GetCompressFactor())))
 }
 
 type Increments []*Increment
@@ -350,7 +357,11 @@ func (incs *Increments) Decompress() { // must be pointer receiver
 			// set right Values
 			if incr.BalanceInfo != nil {
 				if incr.BalanceInfo.Monetary != nil {
-					incr.BalanceInfo.Monetary.Value += (float64(cf-(i+1)) * incr.Cost)
+					debited := utils.NewDecimalFromFloat64(incr.Cost).Mul(utils.NewDecimalFromInt64(int64(cf - (i + 1))))
+					if xr := incr.BalanceInfo.Monetary.ExchangeRate; xr != 0 {
+						debited = debited.Mul(utils.NewDecimalFromFloat64(xr))
+					}
+					incr.BalanceInfo.Monetary.Value = utils.NewDecimalFromFloat64(incr.BalanceInfo.Monetary.Value).Add(debited).Float64()
 				}
 				if incr.BalanceInfo.Unit != nil {
 					incr.BalanceInfo.Unit.Value += (float64(cf-(i+1)) * incr.BalanceInfo.Unit.Consumed)
@@ -386,11 +397,15 @@ func (incs Increments) SharingSignature(other Increments) bool {
 }
 
 func (incs Increments) GetTotalCost() float64 {
-	cost := 0.0
+	return incs.getTotalCostDecimal().Float64()
+}
+
+func (incs Increments) getTotalCostDecimal() *utils.Decimal {
+	cost := utils.NewDecimalFromInt64(0)
 	for _, increment := range incs {
-		cost += increment.GetCost()
+		cost = cost.Add(increment.getCostDecimal())
 	}
-	return utils.Round(cost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	return cost.Round(globalRoundingDecimals, utils.ROUNDING_MIDDLE)
 }
 
 func (incs Increments) Length() (length int) {
@@ -432,13 +447,18 @@ func (ts *TimeSpan) SetRateInterval(interval *RateInterval) {
 // It also sets the Cost field of this timespan (used for refund on session
 // manager debit loop where the cost cannot be recalculated)
 func (ts *TimeSpan) CalculateCost() float64 {
+	return ts.calculateCostDecimal().Float64()
+}
+
+func (ts *TimeSpan) calculateCostDecimal() *utils.Decimal {
 	if ts.Increments.Length() == 0 {
 		if ts.RateInterval == nil {
-			return 0
+			return utils.NewDecimalFromInt64(0)
 		}
-		return ts.RateInterval.GetCost(ts.GetDuration(), ts.GetGroupStart())
+		return ts.RateInterval.getCostDecimal(ts.GetDuration(), ts.GetGroupStart())
 	} else {
-		return ts.Increments.GetTotalCost() * float64(ts.GetCompressFactor())
+		return ts.Increments.getTotalCostDecimal().
+			Mul(utils.NewDecimalFromInt64(int64(ts.GetCompressFactor())))
 	}
 }
 
@@ -461,18 +481,19 @@ func (ts *TimeSpan) createIncrementsSlice() {
 	// because ts cost is rounded
 	//incrementCost := rate / rateUnit.Seconds() * rateIncrement.Seconds()
 	nbIncrements := int(ts.GetDuration() / rateIncrement)
-	incrementCost := ts.CalculateCost() / float64(nbIncrements)
-	incrementCost = utils.Round(incrementCost, globalRoundingDecimals, utils.ROUNDING_MIDDLE)
+	incrementCost := ts.calculateCostDecimal().
+		Div(utils.NewDecimalFromInt64(int64(nbIncrements))).
+		Round(globalRoundingDecimals, utils.ROUNDING_MIDDLE)
 	for s := 0; s < nbIncrements; s++ {
 		inc := &Increment{
 			Duration:    rateIncrement,
-			Cost:        incrementCost,
+			Cost:        incrementCost.Float64(),
 			BalanceInfo: &DebitInfo{},
 		}
 		ts.Increments = append(ts.Increments, inc)
 	}
 	// put the rounded cost back in timespan
-	ts.Cost = incrementCost * float64(nbIncrements)
+	ts.Cost = incrementCost.Mul(utils.NewDecimalFromInt64(int64(nbIncrements))).Float64()
 }
 
 // returns whether the timespan has all increments marked as paid and if not
@@ -803,7 +824,7 @@ func (ts *TimeSpan) Merge(other *TimeSpan) bool {
 		return false
 	}
 	ts.TimeEnd = other.TimeEnd
-	ts.Cost += other.Cost
+	ts.Cost = utils.SumFloat64(ts.Cost, other.Cost).Float64()
 	ts.DurationIndex = other.DurationIndex
 	ts.Increments = append(ts.Increments, other.Increments...)
 	return true
@@ -57,6 +57,8 @@ type TpReader struct {
 	sqProfiles       map[utils.TenantID]*utils.TPStats
 	thProfiles       map[utils.TenantID]*utils.TPThreshold
 	filters          map[utils.TenantID]*utils.TPFilter
+	sppProfiles      map[utils.TenantID]*utils.TPSupplierProfile
+	exchangeRates    map[string]*utils.TPExchangeRate
 	resources        []*utils.TenantID // IDs of resources which need creation based on resourceProfiles
 	statQueues       []*utils.TenantID // IDs of statQueues which need creation based on statQueueProfiles
 	thresholds       []*utils.TenantID // IDs of thresholds which need creation based on thresholdProfiles
@@ -66,6 +68,7 @@ type TpReader struct {
 	thdsIndexers map[string]*ReqFilterIndexer // tenant, indexer
 	sqpIndexers  map[string]*ReqFilterIndexer // tenant, indexer
 	resIndexers  map[string]*ReqFilterIndexer // tenant, indexer
+	sppIndexers  map[string]*ReqFilterIndexer // tenant, indexer
 }
 
 func NewTpReader(db DataDB, lr LoadReader, tpid, timezone string) *TpReader {
@@ -137,12 +140,15 @@ func (tpr *TpReader) Init() {
 	tpr.sqProfiles = make(map[utils.TenantID]*utils.TPStats)
 	tpr.thProfiles = make(map[utils.TenantID]*utils.TPThreshold)
 	tpr.filters = make(map[utils.TenantID]*utils.TPFilter)
+	tpr.sppProfiles = make(map[utils.TenantID]*utils.TPSupplierProfile)
+	tpr.exchangeRates = make(map[string]*utils.TPExchangeRate)
 	tpr.revDests = make(map[string][]string)
 	tpr.revAliases = make(map[string][]string)
 	tpr.acntActionPlans = make(map[string][]string)
 	tpr.thdsIndexers = make(map[string]*ReqFilterIndexer)
 	tpr.sqpIndexers = make(map[string]*ReqFilterIndexer)
 	tpr.resIndexers = make(map[string]*ReqFilterIndexer)
+	tpr.sppIndexers = make(map[string]*ReqFilterIndexer)
 }
 
 func (tpr *TpReader) LoadDestinationsFiltered(tag string) (bool, error) {
@@ -576,6 +582,9 @@ func (tpr *TpReader) LoadActions() (err error) {
 					return fmt.Errorf("error parsing action %s filter field: %v", tag, err)
 				}
 			}
+			if err := verifyActionParameters(tpact.Identifier, tpact.ExtraParameters); err != nil {
+				return fmt.Errorf("error parsing action %s extra parameters: %v", tag, err)
+			}
 			acts[idx] = &Action{
 				Id:         tag,
 				ActionType: tpact.Identifier,
@@ -1038,6 +1047,9 @@ func (tpr *TpReader) LoadAccountActionsFiltered(qriedAA *utils.TPAccountActions)
 							return fmt.Errorf("error parsing action %s filter field: %v", tag, err)
 						}
 					}
+					if err := verifyActionParameters(tpact.Identifier, tpact.ExtraParameters); err != nil {
+						return fmt.Errorf("error parsing action %s extra parameters: %v", tag, err)
+					}
 					acts[idx] = &Action{
 						Id:         tag,
 						ActionType: tpact.Identifier,
@@ -1381,6 +1393,9 @@ func (tpr *TpReader) LoadCdrStatsFiltered(tag string, save bool) (err error) {
 							return fmt.Errorf("error parsing action %s filter field: %v", tag, err)
 						}
 					}
+					if err := verifyActionParameters(tpact.Identifier, tpact.ExtraParameters); err != nil {
+						return fmt.Errorf("error parsing action %s extra parameters: %v", tag, err)
+					}
 					acts[idx] = &Action{
 						Id:         tag,
 						ActionType: tpact.Identifier,
@@ -1743,6 +1758,65 @@ func (tpr *TpReader) LoadThresholds() error {
 	return tpr.LoadThresholdsFiltered("")
 }
 
+func (tpr *TpReader) LoadSupplierProfilesFiltered(tag string) (err error) {
+	tps, err := tpr.lr.GetTPSuppliers(tpr.tpid, tag)
+	if err != nil {
+		return err
+	}
+	mapSPPs := make(map[utils.TenantID]*utils.TPSupplierProfile)
+	for _, spp := range tps {
+		mapSPPs[utils.TenantID{Tenant: spp.Tenant, ID: spp.ID}] = spp
+	}
+	tpr.sppProfiles = mapSPPs
+	for tntID, spp := range mapSPPs {
+		// index supplier profiles for filters
+		if _, has := tpr.sppIndexers[tntID.Tenant]; !has {
+			if tpr.sppIndexers[tntID.Tenant], err = NewReqFilterIndexer(tpr.dm, utils.SupplierProfilesStringIndex+tntID.Tenant); err != nil {
+				return
+			}
+		}
+		for _, fltrID := range spp.FilterIDs {
+			tpFltr, has := tpr.filters[utils.TenantID{Tenant: tntID.Tenant, ID: fltrID}]
+			if !has {
+				var fltr *Filter
+				if fltr, err = tpr.dm.GetFilter(tntID.Tenant, fltrID, false, utils.NonTransactional); err != nil {
+					if err == utils.ErrNotFound {
+						err = fmt.Errorf("broken reference to filter: %+v for supplier profile: %+v", fltrID, spp)
+					}
+					return
+				}
+				tpFltr = FilterToTPFilter(fltr)
+			}
+			tpr.sppIndexers[tntID.Tenant].IndexTPFilter(tpFltr, spp.ID)
+		}
+	}
+	return nil
+}
+
+func (tpr *TpReader) LoadSupplierProfiles() error {
+	return tpr.LoadSupplierProfilesFiltered("")
+}
+
+func (tpr *TpReader) LoadExchangeRatesFiltered(fromCurrency string) (err error) {
+	tps, err := tpr.lr.GetTPExchangeRates(tpr.tpid, fromCurrency)
+	if err != nil {
+		return err
+	}
+	mapXRs := make(map[string]*utils.TPExchangeRate)
+	for _, xr := range tps {
+		if _, err = APItoExchangeRate(xr); err != nil {
+			return
+		}
+		mapXRs[utils.ConcatenatedKey(xr.FromCurrency, xr.ToCurrency)] = xr
+	}
+	tpr.exchangeRates = mapXRs
+	return nil
+}
+
+func (tpr *TpReader) LoadExchangeRates() error {
+	return tpr.LoadExchangeRatesFiltered("")
+}
+
 func (tpr *TpReader) LoadFiltersFiltered(tag string) error {
 	tps, err := tpr.lr.GetTPFilters(tpr.tpid, tag)
 	if err != nil {
@@ -1821,6 +1895,12 @@ func (tpr *TpReader) LoadAll() (err error) {
 	if err = tpr.LoadThresholds(); err != nil && err.Error() != utils.NotFoundCaps {
 		return
 	}
+	if err = tpr.LoadSupplierProfiles(); err != nil && err.Error() != utils.NotFoundCaps {
+		return
+	}
+	if err = tpr.LoadExchangeRates(); err != nil && err.Error() != utils.NotFoundCaps {
+		return
+	}
 	return nil
 }
 
@@ -2155,6 +2235,36 @@ func (tpr *TpReader) WriteToDatabase(flush, verbose, disable_reverse bool) (err
 			log.Print("\t", thd.TenantID())
 		}
 	}
+	if verbose {
+		log.Print("SupplierProfiles:")
+	}
+	for _, tpSPP := range tpr.sppProfiles {
+		spp, err := APItoSupplierProfile(tpSPP, tpr.timezone)
+		if err != nil {
+			return err
+		}
+		if err = tpr.dm.SetSupplierProfile(spp); err != nil {
+			return err
+		}
+		if verbose {
+			log.Print("\t", spp.TenantID())
+		}
+	}
+	if verbose {
+		log.Print("ExchangeRates:")
+	}
+	for _, tpXR := range tpr.exchangeRates {
+		xr, err := APItoExchangeRate(tpXR)
+		if err != nil {
+			return err
+		}
+		if err = tpr.dm.SetExchangeRate(xr, utils.NonTransactional); err != nil {
+			return err
+		}
+		if verbose {
+			log.Print("\t", xr.ID())
+		}
+	}
 	if verbose {
 		log.Print("Timings:")
 	}
@@ -2226,6 +2336,18 @@ func (tpr *TpReader) WriteToDatabase(flush, verbose, disable_reverse bool) (err
 				log.Printf("Tenant: %s, keys %+v", tenant, fltrIdxer.ChangedKeys().Slice())
 			}
 		}
+
+		if verbose {
+			log.Print("SupplierProfile filter indexes:")
+		}
+		for tenant, fltrIdxer := range tpr.sppIndexers {
+			if err := fltrIdxer.StoreIndexes(); err != nil {
+				return err
+			}
+			if verbose {
+				log.Printf("Tenant: %s, keys %+v", tenant, fltrIdxer.ChangedKeys().Slice())
+			}
+		}
 	}
 	return
 }
@@ -2295,6 +2417,10 @@ func (tpr *TpReader) ShowStatistics() {
 	log.Print("Thresholds: ", len(tpr.thProfiles))
 	// filters
 	log.Print("Filters: ", len(tpr.filters))
+	// suppliers
+	log.Print("SupplierProfiles: ", len(tpr.sppProfiles))
+	// exchange rates
+	log.Print("ExchangeRates: ", len(tpr.exchangeRates))
 }
 
 // Returns the identities loaded for a specific category, useful for cache reloads
@@ -2452,6 +2578,22 @@ func (tpr *TpReader) GetLoadedIds(categ string) ([]string, error) {
 			i++
 		}
 		return keys, nil
+	case utils.SupplierProfilePrefix:
+		keys := make([]string, len(tpr.sppProfiles))
+		i := 0
+		for k := range tpr.sppProfiles {
+			keys[i] = k.TenantID()
+			i++
+		}
+		return keys, nil
+	case utils.ExchangeRatePrefix:
+		keys := make([]string, len(tpr.exchangeRates))
+		i := 0
+		for k := range tpr.exchangeRates {
+			keys[i] = k
+			i++
+		}
+		return keys, nil
 	}
 	return nil, errors.New("Unsupported load category")
 }
@@ -273,6 +273,25 @@ func (self *TPExporter) Run() error {
 		}
 	}
 
+	storDataSuppliers, err := self.storDb.GetTPSuppliers(self.tpID, "")
+	if err != nil && err.Error() != utils.ErrNotFound.Error() {
+		return err
+	}
+	for _, sd := range storDataSuppliers {
+		sdModels := APItoModelTPSuppliers(sd)
+		for _, sdModel := range sdModels {
+			toExportMap[utils.SuppliersCsv] = append(toExportMap[utils.SuppliersCsv], sdModel)
+		}
+	}
+
+	storDataExchangeRates, err := self.storDb.GetTPExchangeRates(self.tpID, "")
+	if err != nil && err.Error() != utils.ErrNotFound.Error() {
+		return err
+	}
+	for _, sd := range storDataExchangeRates {
+		toExportMap[utils.ExchangeRatesCsv] = append(toExportMap[utils.ExchangeRatesCsv], APItoModelTPExchangeRate(sd))
+	}
+
 	storDataUsers, err := self.storDb.GetTPUsers(&utils.TPUsers{TPid: self.tpID})
 	if err != nil && err.Error() != utils.ErrNotFound.Error() {
 		return err
@@ -61,6 +61,8 @@ var fileHandlers = map[string]func(*TPCSVImporter, string) error{
 	utils.StatsCsv:              (*TPCSVImporter).importStats,
 	utils.ThresholdsCsv:         (*TPCSVImporter).importThresholds,
 	utils.FiltersCsv:            (*TPCSVImporter).importFilters,
+	utils.SuppliersCsv:          (*TPCSVImporter).importSuppliers,
+	utils.ExchangeRatesCsv:      (*TPCSVImporter).importExchangeRates,
 }
 
 func (self *TPCSVImporter) Run() error {
@@ -85,6 +87,8 @@ func (self *TPCSVImporter) Run() error {
 		path.Join(self.DirPath, utils.StatsCsv),
 		path.Join(self.DirPath, utils.ThresholdsCsv),
 		path.Join(self.DirPath, utils.FiltersCsv),
+		path.Join(self.DirPath, utils.SuppliersCsv),
+		path.Join(self.DirPath, utils.ExchangeRatesCsv),
 	)
 	files, _ := ioutil.ReadDir(self.DirPath)
 	for _, f := range files {
@@ -397,3 +401,25 @@ func (self *TPCSVImporter) importFilters(fn string) error {
 	}
 	return self.StorDb.SetTPFilters(sts)
 }
+
+func (self *TPCSVImporter) importSuppliers(fn string) error {
+	if self.Verbose {
+		log.Printf("Processing file: <%s> ", fn)
+	}
+	spps, err := self.csvr.GetTPSuppliers(self.TPid, "")
+	if err != nil {
+		return err
+	}
+	return self.StorDb.SetTPSuppliers(spps)
+}
+
+func (self *TPCSVImporter) importExchangeRates(fn string) error {
+	if self.Verbose {
+		log.Printf("Processing file: <%s> ", fn)
+	}
+	xrs, err := self.csvr.GetTPExchangeRates(self.TPid, "")
+	if err != nil {
+		return err
+	}
+	return self.StorDb.SetTPExchangeRates(xrs)
+}
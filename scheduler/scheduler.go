@@ -42,24 +42,46 @@ type Scheduler struct {
 	actSuccessStats, actFailedStats map[string]map[time.Time]bool // keep here stats regarding executed actions, map[actionType]map[execTime]bool
 }
 
+// defaultActStatsInterval is the period the executed actions are kept in stats
+const defaultActStatsInterval = time.Hour
+
 func NewScheduler(dm *engine.DataManager) *Scheduler {
 	s := &Scheduler{
-		restartLoop: make(chan bool),
-		dm:          dm,
+		restartLoop:      make(chan bool),
+		dm:               dm,
+		actStatsInterval: defaultActStatsInterval,
+		actSucessChan:    make(chan *engine.Action),
+		actFailedChan:    make(chan *engine.Action),
+		actSuccessStats:  make(map[string]map[time.Time]bool),
+		actFailedStats:   make(map[string]map[time.Time]bool),
 	}
+	go s.collectActStats()
 	s.Reload()
 	return s
 }
 
+// collectActStats records the actions reported by the executed ActionPlans
+func (s *Scheduler) collectActStats() {
+	for {
+		select {
+		case act := <-s.actSucessChan:
+			s.updateActStats(act, false)
+		case act := <-s.actFailedChan:
+			s.updateActStats(act, true)
+		}
+	}
+}
+
 func (s *Scheduler) updateActStats(act *engine.Action, isFailed bool) {
-	mux := s.aSMux
+	mux := &s.aSMux
 	statsMp := s.actSuccessStats
 	if isFailed {
-		mux = s.aFMux
+		mux = &s.aFMux
 		statsMp = s.actFailedStats
 	}
 	now := time.Now()
 	mux.Lock()
+	defer mux.Unlock()
 	for aType := range statsMp {
 		for t := range statsMp[aType] {
 			if now.Sub(t) > s.actStatsInterval {
@@ -77,7 +99,47 @@ func (s *Scheduler) updateActStats(act *engine.Action, isFailed bool) {
 		statsMp[act.ActionType] = make(map[time.Time]bool)
 	}
 	statsMp[act.ActionType][now] = true
-	mux.Unlock()
+}
+
+// actStatsSamples returns the number of actions executed during the stats interval, per action type
+func (s *Scheduler) actStatsSamples(isFailed bool) (samples []*utils.MetricSample) {
+	s.updateActStats(nil, isFailed) // expire old stats
+	mux := &s.aSMux
+	statsMp := s.actSuccessStats
+	status := "success"
+	if isFailed {
+		mux = &s.aFMux
+		statsMp = s.actFailedStats
+		status = "failed"
+	}
+	mux.RLock()
+	defer mux.RUnlock()
+	aTypes := make([]string, 0, len(statsMp))
+	for aType := range statsMp {
+		aTypes = append(aTypes, aType)
+	}
+	sort.Strings(aTypes)
+	for _, aType := range aTypes {
+		samples = append(samples, &utils.MetricSample{
+			Labels: map[string]string{"action_type": aType, "status": status},
+			Value:  float64(len(statsMp[aType]))})
+	}
+	return
+}
+
+// Metrics implements utils.MetricsCollector
+func (s *Scheduler) Metrics() []*utils.MetricFamily {
+	s.RLock()
+	qLen := len(s.queue)
+	s.RUnlock()
+	return []*utils.MetricFamily{
+		&utils.MetricFamily{Name: "cgr_scheduler_queue_length", Type: utils.MetricGauge,
+			Help:    "Number of action timings waiting in the scheduler queue",
+			Samples: []*utils.MetricSample{&utils.MetricSample{Value: float64(qLen)}}},
+		&utils.MetricFamily{Name: "cgr_scheduler_actions_executed", Type: utils.MetricGauge,
+			Help:    "Actions executed by the scheduler during the last stats interval, per type and status",
+			Samples: append(s.actStatsSamples(false), s.actStatsSamples(true)...)},
+	}
 }
 
 func (s *Scheduler) Loop() {
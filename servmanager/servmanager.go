@@ -63,6 +63,7 @@ func (srvMngr *ServiceManager) StartScheduler(waitCache bool) error {
 	}
 	utils.Logger.Info("<ServiceManager> Starting CGRateS Scheduler.")
 	sched := scheduler.NewScheduler(srvMngr.dm)
+	utils.Metrics.RegisterCollector(utils.SchedulerS, sched)
 	srvMngr.Lock()
 	srvMngr.sched = sched
 	srvMngr.Unlock()
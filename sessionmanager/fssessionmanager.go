@@ -271,7 +271,7 @@ func (sm *FSSessionManager) onChannelHangupComplete(ev engine.Event) {
 		time.Sleep(time.Duration(i+1) * time.Second)
 	}
 	if s != nil { // Handled by us, cleanup here
-		if err := sm.sessions.removeSession(s, ev); err != nil {
+		if _, err := sm.sessions.removeSession(s, ev); err != nil {
 			utils.Logger.Err(err.Error())
 		}
 	}
@@ -482,7 +482,7 @@ func (sm *FSSessionManager) SyncSessions() error {
 			dur := now.Sub(aTime)
 			fsev[END_TIME] = now.String()
 			fsev[DURATION] = strconv.FormatFloat(dur.Seconds(), 'f', -1, 64)
-			if err := sm.sessions.removeSession(session, fsev); err != nil { // Stop loop, refund advanced charges and save the costs deducted so far to database
+			if _, err := sm.sessions.removeSession(session, fsev); err != nil { // Stop loop, refund advanced charges and save the costs deducted so far to database
 				utils.Logger.Err(fmt.Sprintf("<SM-FreeSWITCH> Error on removing stale session with uuid: %s, error: %s",
 					session.eventStart.GetUUID(), err.Error()))
 				continue
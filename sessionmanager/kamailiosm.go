@@ -24,6 +24,8 @@ import (
 	"log"
 	"reflect"
 	"regexp"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/cgrates/cgrates/config"
@@ -39,18 +41,30 @@ func NewKamailioSessionManager(smKamCfg *config.SmKamConfig, rater, cdrsrv,
 		rlS = nil
 	}
 	ksm = &KamailioSessionManager{cfg: smKamCfg, rater: rater, cdrsrv: cdrsrv, rlS: rlS,
-		timezone: timezone, conns: make(map[string]*kamevapi.KamEvapi), sessions: NewSessions()}
+		timezone: timezone, conns: make(map[string]kamEvapiConn), sessions: NewSessions(),
+		dlgListReplies: make(map[string]chan *KamDlgListReply)}
 	return
 }
 
+// kamEvapiConn is the connection towards the evapi module of Kamailio
+type kamEvapiConn interface {
+	Send(dataStr string) error
+}
+
+// time to wait for Kamailio to reply with the list of dialogs
+var kamDlgListTimeout = 5 * time.Second
+
 type KamailioSessionManager struct {
-	cfg      *config.SmKamConfig
-	rater    rpcclient.RpcClientConnection
-	cdrsrv   rpcclient.RpcClientConnection
-	rlS      rpcclient.RpcClientConnection
-	timezone string
-	conns    map[string]*kamevapi.KamEvapi
-	sessions *Sessions
+	cfg            *config.SmKamConfig
+	rater          rpcclient.RpcClientConnection
+	cdrsrv         rpcclient.RpcClientConnection
+	rlS            rpcclient.RpcClientConnection
+	timezone       string
+	conns          map[string]kamEvapiConn
+	sessions       *Sessions
+	dlgListReplies map[string]chan *KamDlgListReply // waiting for the dialogs of one connection, indexed on connId
+	dlgListMux     sync.Mutex                       // protects dlgListReplies
+	syncMux        sync.Mutex                       // one dialogs sync at a time, replies cannot be told apart
 }
 
 func (self *KamailioSessionManager) getSuppliers(kev KamEvent) (string, error) {
@@ -207,32 +221,82 @@ func (self *KamailioSessionManager) onCallEnd(evData []byte, connId string) {
 	}
 	go self.ProcessCdr(kev.AsStoredCdr(self.Timezone()))
 	if self.rlS != nil { // Release RLs resource
-		go func() {
-			ev, err := kev.AsMapStringIface()
-			if err != nil {
-				utils.Logger.Err(fmt.Sprintf("<SM-Kamailio> RLs error: %s", err.Error()))
-				return
-			}
-			var reply string
-			attrRU := utils.ArgRSv1ResourceUsage{
-				Tenant:  kev.GetTenant(utils.META_DEFAULT),
-				UsageID: kev.GetUUID(),
-				Event:   ev,
-				Units:   1,
-			}
-			if err := self.rlS.Call("ResourceSV1.ReleaseResource", attrRU, &reply); err != nil {
-				utils.Logger.Err(fmt.Sprintf("<SM-Kamailio> RLs API error: %s", err.Error()))
-			}
-		}()
+		go self.releaseResources(kev)
 	}
 	if s := self.sessions.getSession(kev.GetUUID()); s != nil {
-		if err := self.sessions.removeSession(s, kev); err != nil {
+		if _, err := self.sessions.removeSession(s, kev); err != nil {
 			utils.Logger.Err(err.Error())
 		}
 	}
 
 }
 
+func (self *KamailioSessionManager) releaseResources(kev KamEvent) {
+	ev, err := kev.AsMapStringIface()
+	if err != nil {
+		utils.Logger.Err(fmt.Sprintf("<SM-Kamailio> RLs error: %s", err.Error()))
+		return
+	}
+	var reply string
+	attrRU := utils.ArgRSv1ResourceUsage{
+		Tenant:  kev.GetTenant(utils.META_DEFAULT),
+		UsageID: kev.GetUUID(),
+		Event:   ev,
+		Units:   1,
+	}
+	if err := self.rlS.Call("ResourceSV1.ReleaseResource", attrRU, &reply); err != nil {
+		utils.Logger.Err(fmt.Sprintf("<SM-Kamailio> RLs API error: %s", err.Error()))
+	}
+}
+
+// onCgrDlgList is the handler for CGR_DLG_LIST replies coming from Kamailio
+func (self *KamailioSessionManager) onCgrDlgList(evData []byte, connId string) {
+	kdl, err := NewKamDlgListReply(evData)
+	if err != nil {
+		utils.Logger.Err(fmt.Sprintf("<SM-Kamailio> ERROR unmarshalling dialogs: %s, error: %s", evData, err.Error()))
+		return
+	}
+	self.dlgListMux.Lock()
+	defer self.dlgListMux.Unlock()
+	if replyChan, has := self.dlgListReplies[connId]; has {
+		select {
+		case replyChan <- kdl:
+		default: // reply already delivered
+		}
+	}
+}
+
+// activeDialogs queries Kamailio behind connId for the dialogs it has active, indexed on their UUID
+func (self *KamailioSessionManager) activeDialogs(connId string) (dlgs utils.StringMap, err error) {
+	replyChan := make(chan *KamDlgListReply, 1)
+	self.dlgListMux.Lock()
+	if _, has := self.dlgListReplies[connId]; has {
+		self.dlgListMux.Unlock()
+		return nil, utils.ErrExists // the reply would go to the request already waiting
+	}
+	self.dlgListReplies[connId] = replyChan
+	self.dlgListMux.Unlock()
+	defer func() {
+		self.dlgListMux.Lock()
+		delete(self.dlgListReplies, connId)
+		self.dlgListMux.Unlock()
+	}()
+	dlgListReq := &KamDlgListRequest{Event: CGR_DLG_LIST}
+	if err = self.conns[connId].Send(dlgListReq.String()); err != nil {
+		return
+	}
+	select {
+	case kdl := <-replyChan:
+		dlgs = make(utils.StringMap)
+		for _, dlg := range kdl.JsonrplBody.Result {
+			dlgs[dlg.UUID()] = true
+		}
+	case <-time.After(kamDlgListTimeout):
+		err = rpcclient.ErrReplyTimeout
+	}
+	return
+}
+
 func (self *KamailioSessionManager) Connect() error {
 	var err error
 	eventHandlers := map[*regexp.Regexp][]func([]byte, string){
@@ -241,20 +305,39 @@ func (self *KamailioSessionManager) Connect() error {
 		regexp.MustCompile(CGR_RL_REQUEST):   []func([]byte, string){self.onCgrRLReq},
 		regexp.MustCompile(CGR_CALL_START):   []func([]byte, string){self.onCallStart},
 		regexp.MustCompile(CGR_CALL_END):     []func([]byte, string){self.onCallEnd},
+		regexp.MustCompile(CGR_DLG_LIST):     []func([]byte, string){self.onCgrDlgList},
 	}
 	errChan := make(chan error)
+	stopSync := make(chan struct{})
+	defer close(stopSync)
 	for _, connCfg := range self.cfg.EvapiConns {
 		connId := utils.GenUUID()
 		logger := log.New(utils.Logger, "KamEvapi:", 2)
-		if self.conns[connId], err = kamevapi.NewKamEvapi(connCfg.Address, connId, connCfg.Reconnects, eventHandlers, logger); err != nil {
+		evapi, err := kamevapi.NewKamEvapi(connCfg.Address, connId, connCfg.Reconnects, eventHandlers, logger)
+		if err != nil {
 			return err
 		}
+		self.conns[connId] = evapi
 		go func() { // Start reading in own goroutine, return on error
-			if err := self.conns[connId].ReadEvents(); err != nil {
+			if err := evapi.ReadEvents(); err != nil {
 				errChan <- err
 			}
 		}()
 	}
+	if self.cfg.DialogSyncInterval != 0 { // Schedule running of the dialogs sync, stops together with the connections
+		go func() {
+			ticker := time.NewTicker(self.cfg.DialogSyncInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stopSync:
+					return
+				case <-ticker.C:
+					self.SyncSessions()
+				}
+			}
+		}()
+	}
 	err = <-errChan // Will keep the Connect locked until the first error in one of the connections
 	return err
 }
@@ -301,7 +384,58 @@ func (self *KamailioSessionManager) Sessions() []*Session {
 	return self.sessions.getSessions()
 }
 
+// SyncSessions compares our sessions with the dialogs active on Kamailio,
+// the stale ones (ie: missed CGR_CALL_END) are closed at their last debit so the debits stop and the extra charges are refunded
 func (self *KamailioSessionManager) SyncSessions() error {
+	self.syncMux.Lock()
+	defer self.syncMux.Unlock()
+	for connId := range self.conns {
+		// sessions started after the request might miss from the dialogs list, only check the ones we have before it
+		sessions := append([]*Session(nil), self.sessions.getSessions()...)
+		dlgs, err := self.activeDialogs(connId)
+		if err != nil {
+			utils.Logger.Err(fmt.Sprintf("<SM-Kamailio> Error on syncing active dialogs, connection id: %s, error: %s",
+				connId, err.Error()))
+			continue
+		}
+		for _, session := range sessions {
+			if session.connId != connId { // This session belongs to another connectionId
+				continue
+			}
+			if _, stillActive := dlgs[session.eventStart.GetUUID()]; stillActive {
+				continue
+			}
+			utils.Logger.Warning(fmt.Sprintf("<SM-Kamailio> Sync active dialogs, stale session detected, uuid: %s",
+				session.eventStart.GetUUID()))
+			kev := make(KamEvent) // work on a copy so we do not alter the event of the session
+			for fld, val := range session.eventStart.(KamEvent) {
+				kev[fld] = val
+			}
+			kev[EVENT] = CGR_CALL_END
+			if aTime, err := kev.GetAnswerTime(utils.META_DEFAULT, self.timezone); err == nil && !aTime.IsZero() {
+				// the dialog was last known to be alive when we debited it, the rest gets refunded
+				endTime := session.lastDebitTime()
+				if endTime.Before(aTime) {
+					endTime = aTime
+				}
+				kev[CGR_STOPTIME] = strconv.FormatInt(endTime.Unix(), 10)
+				kev[CGR_DURATION] = strconv.FormatFloat(endTime.Sub(aTime).Seconds(), 'f', -1, 64)
+			} else {
+				kev[CGR_STOPTIME] = strconv.FormatInt(time.Now().Unix(), 10)
+			}
+			if closed, err := self.sessions.removeSession(session, kev); err != nil { // Stop loop, refund advanced charges and save the costs deducted so far to database
+				utils.Logger.Err(fmt.Sprintf("<SM-Kamailio> Error on removing stale session with uuid: %s, error: %s",
+					session.eventStart.GetUUID(), err.Error()))
+				continue
+			} else if !closed { // ended normally meanwhile, CDR and resources handled by onCallEnd
+				continue
+			}
+			self.ProcessCdr(kev.AsStoredCdr(self.timezone))
+			if self.rlS != nil {
+				self.releaseResources(kev)
+			}
+		}
+	}
 	return nil
 }
 
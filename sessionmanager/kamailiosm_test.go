@@ -18,9 +18,85 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package sessionmanager
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/utils"
 )
 
 func TestKamSMInterface(t *testing.T) {
 	var _ SessionManager = SessionManager(new(KamailioSessionManager))
 }
+
+// fakeEvapiPeer emulates Kamailio on the other side of evapi, answering CGR_DLG_LIST with its dialogs
+type fakeEvapiPeer struct {
+	ksm       *KamailioSessionManager
+	connId    string
+	reply     string // dialogs reply, empty for not answering
+	onRequest func() // executed before answering, ie: a call starting meanwhile
+}
+
+func (fp *fakeEvapiPeer) Send(dataStr string) error {
+	var req map[string]string
+	if err := json.Unmarshal([]byte(dataStr), &req); err != nil {
+		return err
+	}
+	if req["Event"] == CGR_DLG_LIST && fp.onRequest != nil {
+		fp.onRequest()
+	}
+	if req["Event"] == CGR_DLG_LIST && fp.reply != "" {
+		go fp.ksm.onCgrDlgList([]byte(fp.reply), fp.connId)
+	}
+	return nil
+}
+
+func TestKamSMSyncSessions(t *testing.T) {
+	defer func(timeout time.Duration) { kamDlgListTimeout = timeout }(kamDlgListTimeout)
+	kamDlgListTimeout = 50 * time.Millisecond
+	cdrs := new(clntConnRecorder)
+	ksm, _ := NewKamailioSessionManager(&config.SmKamConfig{CreateCdr: true}, nil, cdrs, nil, "UTC")
+	newSession := func(connId, callID string) *Session {
+		return &Session{connId: connId, sessionManager: ksm, stopDebit: make(chan struct{}),
+			eventStart: KamEvent{EVENT: CGR_CALL_START, CALLID: callID, FROM_TAG: "tag1",
+				CGR_ACCOUNT: "1001", CGR_DESTINATION: "1002", CGR_ANSWERTIME: "1500000000"}}
+	}
+	ksm.conns["conn1"] = &fakeEvapiPeer{ksm: ksm, connId: "conn1",
+		reply: `{"event":"CGR_DLG_LIST","jsonrpl_body":{"jsonrpc":"2.0","id":1,"result":[
+{"h_entry":1,"h_id":1,"call-id":"dlg1","from_uri":"sip:1001@127.0.0.1","caller":{"tag":"tag1"},"callee":{"tag":"tag2"}}]}}`,
+		onRequest: func() {
+			ksm.sessions.indexSession(newSession("conn1", "dlg4")) // started after the dialogs were listed
+			ksm.sessions.unindexSession("dlg5;tag1")               // ended normally meanwhile, onCallEnd sent its CDR
+		}}
+	ksm.conns["conn2"] = &fakeEvapiPeer{ksm: ksm, connId: "conn2"} // not answering
+	for _, s := range []struct{ connId, callID string }{{"conn1", "dlg1"}, {"conn1", "dlg2"}, {"conn2", "dlg3"}, {"conn1", "dlg5"}} {
+		ksm.sessions.indexSession(newSession(s.connId, s.callID))
+	}
+	if err := ksm.SyncSessions(); err != nil {
+		t.Error(err)
+	}
+	uuids := make(map[string]bool)
+	for _, s := range ksm.Sessions() {
+		uuids[s.eventStart.GetUUID()] = true
+	}
+	if eUUIDs := map[string]bool{"dlg1;tag1": true, "dlg3;tag1": true, "dlg4;tag1": true}; !reflect.DeepEqual(eUUIDs, uuids) {
+		t.Errorf("Expecting: %+v, received: %+v", eUUIDs, uuids)
+	}
+	if !reflect.DeepEqual([]string{"CdrsV1.ProcessCDR"}, cdrs.calls) {
+		t.Errorf("Received calls: %+v", cdrs.calls)
+	}
+}
+
+func TestKamSMActiveDialogsPending(t *testing.T) {
+	ksm, _ := NewKamailioSessionManager(&config.SmKamConfig{}, nil, nil, nil, "UTC")
+	ksm.conns["conn1"] = &fakeEvapiPeer{ksm: ksm, connId: "conn1"}
+	ksm.dlgListReplies["conn1"] = make(chan *KamDlgListReply, 1) // other sync waiting for its reply
+	if _, err := ksm.activeDialogs("conn1"); err != utils.ErrExists {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrExists, err)
+	}
+	if _, has := ksm.dlgListReplies["conn1"]; !has {
+		t.Error("Reply channel of the waiting request removed")
+	}
+}
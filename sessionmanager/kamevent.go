@@ -20,6 +20,7 @@ package sessionmanager
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
@@ -40,6 +41,7 @@ const (
 	CGR_CALL_END           = "CGR_CALL_END"
 	CGR_RL_REQUEST         = "CGR_RL_REQUEST"
 	CGR_RL_REPLY           = "CGR_RL_REPLY"
+	CGR_DLG_LIST           = "CGR_DLG_LIST" // request for the active dialogs, the reply comes back with the same event name
 	CGR_SETUPTIME          = "cgr_setuptime"
 	CGR_ANSWERTIME         = "cgr_answertime"
 	CGR_STOPTIME           = "cgr_stoptime"
@@ -95,6 +97,48 @@ func (self *KamSessionDisconnect) String() string {
 	return string(mrsh)
 }
 
+// KamDlgListRequest asks Kamailio for the list of active dialogs
+type KamDlgListRequest struct {
+	Event string
+}
+
+func (self *KamDlgListRequest) String() string {
+	mrsh, _ := json.Marshal(self)
+	return string(mrsh)
+}
+
+// KamDialog is one of the dialogs returned by the dlg.list RPC command of Kamailio
+type KamDialog struct {
+	CallID string `json:"call-id"`
+	Caller struct {
+		Tag string `json:"tag"`
+	} `json:"caller"`
+}
+
+// UUID returns the identifier of the dialog as built by KamEvent.GetUUID
+func (self *KamDialog) UUID() string {
+	return self.CallID + ";" + self.Caller.Tag
+}
+
+// KamDlgListReply is the reply of Kamailio to KamDlgListRequest,
+// JsonrplBody being the output of dlg.list RPC command executed via jsonrpc_exec
+type KamDlgListReply struct {
+	Event       string `json:"event"`
+	JsonrplBody struct {
+		Result []*KamDialog `json:"result"` // nil in case of RPC error
+	} `json:"jsonrpl_body"`
+}
+
+func NewKamDlgListReply(evData []byte) (kdl *KamDlgListReply, err error) {
+	if err = json.Unmarshal(evData, &kdl); err != nil {
+		return nil, err
+	}
+	if kdl.JsonrplBody.Result == nil {
+		return nil, errors.New("missing dialogs in reply")
+	}
+	return
+}
+
 func NewKamEvent(kamEvData []byte) (KamEvent, error) {
 	kev := make(map[string]string)
 	if err := json.Unmarshal(kamEvData, &kev); err != nil {
@@ -125,3 +125,23 @@ func TestKevAsCallDescriptor(t *testing.T) {
 		t.Errorf("Expecting: %+v, received: %+v", eCd, cd)
 	}
 }
+
+func TestNewKamDlgListReply(t *testing.T) {
+	evData := []byte(`{"event":"CGR_DLG_LIST","jsonrpl_body":{"jsonrpc":"2.0","id":1,"result":[
+{"h_entry":1,"h_id":1,"call-id":"dlg1","caller":{"tag":"tag1"},"callee":{"tag":"tag2"}}]}}`)
+	if kdl, err := NewKamDlgListReply(evData); err != nil {
+		t.Error(err)
+	} else if len(kdl.JsonrplBody.Result) != 1 || kdl.JsonrplBody.Result[0].UUID() != "dlg1;tag1" {
+		t.Errorf("Received: %+v", kdl)
+	}
+	evData = []byte(`{"event":"CGR_DLG_LIST","jsonrpl_body":{"jsonrpc":"2.0","id":1,"result":[]}}`)
+	if kdl, err := NewKamDlgListReply(evData); err != nil {
+		t.Error(err)
+	} else if len(kdl.JsonrplBody.Result) != 0 {
+		t.Errorf("Received: %+v", kdl)
+	}
+	evData = []byte(`{"event":"CGR_DLG_LIST","jsonrpl_body":{"jsonrpc":"2.0","id":1,"error":{"code":500,"message":"Internal error"}}}`)
+	if _, err := NewKamDlgListReply(evData); err == nil {
+		t.Error("Expecting error on RPC failure")
+	}
+}
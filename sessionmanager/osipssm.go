@@ -288,7 +288,7 @@ func (osm *OsipsSessionManager) callEnd(osipsEv *OsipsEvent) error {
 	if origEvent.MissingParameter(osm.timezone) {
 		return utils.ErrMandatoryIeMissing
 	}
-	if err := osm.sessions.removeSession(s, origEvent); err != nil { // Unreference it early so we avoid concurrency
+	if _, err := osm.sessions.removeSession(s, origEvent); err != nil { // Unreference it early so we avoid concurrency
 		return err
 	}
 	return nil
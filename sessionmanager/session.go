@@ -114,6 +114,23 @@ func (s *Session) debitLoop(runIdx int) {
 	}
 }
 
+// lastDebitTime returns the start of the last period debited out of the session runs, zero if none was debited
+func (s *Session) lastDebitTime() (lastDebit time.Time) {
+	for _, sr := range s.sessionRuns {
+		if len(sr.CallCosts) == 0 {
+			continue
+		}
+		lastCC := sr.CallCosts[len(sr.CallCosts)-1]
+		if len(lastCC.Timespans) == 0 {
+			continue
+		}
+		if tStart := lastCC.Timespans[0].TimeStart; tStart.After(lastDebit) {
+			lastDebit = tStart
+		}
+	}
+	return
+}
+
 // Stops the debit loop
 func (s *Session) Close(ev engine.Event) error {
 	close(s.stopDebit) // Close the channel so all the sessionRuns listening will be notified
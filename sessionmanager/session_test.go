@@ -178,3 +178,21 @@ func TestSessionRefundManyAll(t *testing.T) {
 		t.Errorf("Error refunding: %+v, %+v", len(mc.refundCd.Increments), cc.Timespans)
 	}
 }
+
+func TestSessionLastDebitTime(t *testing.T) {
+	s := &Session{sessionRuns: []*engine.SessionRun{
+		&engine.SessionRun{},
+		&engine.SessionRun{CallCosts: []*engine.CallCost{
+			&engine.CallCost{Timespans: engine.TimeSpans{
+				&engine.TimeSpan{TimeStart: time.Date(2017, 1, 1, 10, 0, 0, 0, time.UTC), TimeEnd: time.Date(2017, 1, 1, 10, 1, 0, 0, time.UTC)}}},
+			&engine.CallCost{Timespans: engine.TimeSpans{
+				&engine.TimeSpan{TimeStart: time.Date(2017, 1, 1, 10, 1, 0, 0, time.UTC), TimeEnd: time.Date(2017, 1, 1, 10, 2, 0, 0, time.UTC)}}},
+		}},
+	}}
+	if lastDebit := s.lastDebitTime(); !lastDebit.Equal(time.Date(2017, 1, 1, 10, 1, 0, 0, time.UTC)) {
+		t.Errorf("Received: %v", lastDebit)
+	}
+	if lastDebit := new(Session).lastDebitTime(); !lastDebit.IsZero() {
+		t.Errorf("Received: %v", lastDebit)
+	}
+}
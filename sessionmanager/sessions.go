@@ -76,15 +76,17 @@ func (self *Sessions) unindexSession(uuid string) bool {
 	return false
 }
 
-func (self *Sessions) removeSession(s *Session, evStop engine.Event) error {
-	_, err := self.guard.Guard(func() (interface{}, error) { // Lock it on UUID level
+// removeSession closes the session, closed is false if it was already removed by someone else
+func (self *Sessions) removeSession(s *Session, evStop engine.Event) (closed bool, err error) {
+	_, err = self.guard.Guard(func() (interface{}, error) { // Lock it on UUID level
 		if !self.unindexSession(s.eventStart.GetUUID()) { // Unreference it early so we avoid concurrency
 			return nil, nil // Did not find the session so no need to close it anymore
 		}
+		closed = true
 		if err := s.Close(evStop); err != nil { // Stop loop, refund advanced charges and save the costs deducted so far to database
 			return nil, err
 		}
 		return nil, nil
 	}, time.Duration(2)*time.Second, s.eventStart.GetUUID())
-	return err
+	return
 }
@@ -312,10 +312,16 @@ func (self SMGenericEvent) GetCdrSource() string {
 	return utils.SMG + "_" + self.GetName()
 }
 
+// GetIdempotencyKey returns the key identifying retries of the same charging request
+func (self SMGenericEvent) GetIdempotencyKey() string {
+	result, _ := utils.ConvertIfaceToString(self[utils.IdempotencyKey])
+	return result
+}
+
 func (self SMGenericEvent) GetExtraFields() map[string]string {
 	extraFields := make(map[string]string)
 	for key, val := range self {
-		primaryFields := append(utils.PrimaryCdrFields, utils.EVENT_NAME)
+		primaryFields := append(utils.PrimaryCdrFields, utils.EVENT_NAME, utils.CGRConnectorID)
 		if utils.IsSliceMember(primaryFields, key) {
 			continue
 		}
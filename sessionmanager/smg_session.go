@@ -38,6 +38,9 @@ type SMGSession struct {
 	clntConn  rpcclient.RpcClientConnection // Reference towards client connection on SMG side so we can disconnect.
 	rals      rpcclient.RpcClientConnection // Connector to rals service
 	cdrsrv    rpcclient.RpcClientConnection // Connector to CDRS service
+	dm        *engine.DataManager           // persists the session state on each debit, nil if not stored
+	nodeID    string                        // InstanceID of the engine storing the session
+	recovered bool                          // restored out of DataDB, not yet confirmed by the connector
 
 	CGRID      string // Unique identifier for this session
 	RunID      string // Keep a reference for the derived run
@@ -89,6 +92,7 @@ func (self *SMGSession) debitLoop(debitInterval time.Duration) {
 func (self *SMGSession) debit(dur time.Duration, lastUsed *time.Duration) (time.Duration, error) {
 	self.mux.Lock()
 	defer self.mux.Unlock()
+	defer self.store() // persist the new state before releasing the lock
 	requestedDuration := dur
 	if lastUsed != nil {
 		self.ExtraDuration = self.LastDebit - *lastUsed
@@ -151,6 +155,34 @@ func (self *SMGSession) debit(dur time.Duration, lastUsed *time.Duration) (time.
 	return requestedDuration, nil
 }
 
+// asStoredSession converts the session into the format persisted in DataDB, to be called under lock
+func (self *SMGSession) asStoredSession() *engine.StoredSession {
+	return &engine.StoredSession{
+		NodeID:        self.nodeID,
+		CGRID:         self.CGRID,
+		RunID:         self.RunID,
+		Timezone:      self.Timezone,
+		EventStart:    self.EventStart,
+		CD:            self.CD,
+		EventCost:     self.EventCost,
+		ExtraDuration: self.ExtraDuration,
+		LastUsage:     self.LastUsage,
+		LastDebit:     self.LastDebit,
+		TotalUsage:    self.TotalUsage,
+	}
+}
+
+// store persists the session state in DataDB if enabled, to be called under lock
+func (self *SMGSession) store() {
+	if self.dm == nil {
+		return
+	}
+	if err := self.dm.SetStoredSession(self.asStoredSession()); err != nil {
+		utils.Logger.Warning(fmt.Sprintf("<SMGeneric> Could not store session: %s, runId: %s, error: %s",
+			self.CGRID, self.RunID, err.Error()))
+	}
+}
+
 // Send disconnect order to remote connection
 func (self *SMGSession) disconnectSession(reason string) error {
 	self.EventStart[utils.USAGE] = strconv.FormatFloat(self.TotalUsage.Seconds(), 'f', -1, 64) // Set the usage to total one debitted
@@ -166,6 +198,20 @@ func (self *SMGSession) disconnectSession(reason string) error {
 	return nil
 }
 
+// Send re-authorization request to remote connection, ie: so it can ask for new quota after a top-up
+func (self *SMGSession) reAuthorize() error {
+	if self.clntConn == nil || reflect.ValueOf(self.clntConn).IsNil() {
+		return errors.New("Calling SMGClientV1.ReAuthorizeSession requires bidirectional JSON connection")
+	}
+	var reply string
+	if err := self.clntConn.Call("SMGClientV1.ReAuthorizeSession", utils.AttrReAuthorizeSession{EventStart: self.EventStart}, &reply); err != nil {
+		return err
+	} else if reply != utils.OK {
+		return errors.New(fmt.Sprintf("Unexpected re-authorize reply: %s", reply))
+	}
+	return nil
+}
+
 // Session has ended, check debits and refund the extra charged duration
 func (self *SMGSession) close(usage time.Duration) (err error) {
 	self.mux.Lock()
@@ -39,6 +39,9 @@ const (
 	MaxSessionTTL      = 10000 // maximum session TTL in miliseconds
 )
 
+// SessionTTLExpired is the disconnect reason for sessions not updated within their TTL
+const SessionTTLExpired = "SESSION_TTL_EXPIRED"
+
 var (
 	ErrPartiallyExecuted = errors.New("Partially executed")
 	ErrActiveSession     = errors.New("ACTIVE_SESSION")
@@ -47,8 +50,8 @@ var (
 func NewSMGReplicationConns(conns []*config.HaPoolConfig, reconnects int, connTimeout, replyTimeout time.Duration) (smgConns []*SMGReplicationConn, err error) {
 	smgConns = make([]*SMGReplicationConn, len(conns))
 	for i, replConnCfg := range conns {
-		if replCon, err := rpcclient.NewRpcClient("tcp", replConnCfg.Address, 0, reconnects,
-			connTimeout, replyTimeout, replConnCfg.Transport[1:], nil, true); err != nil {
+		if replCon, err := engine.NewRemoteRPCClient(replConnCfg, 0, reconnects,
+			connTimeout, replyTimeout, true); err != nil {
 			return nil, err
 		} else {
 			smgConns[i] = &SMGReplicationConn{Connection: replCon, Synchronous: replConnCfg.Synchronous}
@@ -63,14 +66,19 @@ type SMGReplicationConn struct {
 	Synchronous bool
 }
 
+// NewSMGeneric constructs the SMGeneric, dm is used to persist the sessions in case of store_sessions enabled
 func NewSMGeneric(cgrCfg *config.CGRConfig, rals rpcclient.RpcClientConnection, cdrsrv rpcclient.RpcClientConnection,
-	smgReplConns []*SMGReplicationConn, timezone string) *SMGeneric {
+	smgReplConns []*SMGReplicationConn, dm *engine.DataManager, timezone string) *SMGeneric {
 	ssIdxCfg := cgrCfg.SmGenericConfig.SessionIndexes
 	ssIdxCfg[utils.ACCID] = true // Make sure we have indexing for OriginID since it is a requirement on prefix searching
+	if !cgrCfg.SmGenericConfig.StoreSessions {
+		dm = nil // no persistence of sessions
+	}
 	return &SMGeneric{cgrCfg: cgrCfg,
 		rals:               rals,
 		cdrsrv:             cdrsrv,
 		smgReplConns:       smgReplConns,
+		dm:                 dm,
 		Timezone:           timezone,
 		activeSessions:     make(map[string][]*SMGSession),
 		ssIdxCfg:           ssIdxCfg,
@@ -88,6 +96,7 @@ type SMGeneric struct {
 	rals               rpcclient.RpcClientConnection
 	cdrsrv             rpcclient.RpcClientConnection
 	smgReplConns       []*SMGReplicationConn // list of connections where we will replicate our session data
+	dm                 *engine.DataManager   // persists active sessions so we can recover them on restart, nil if disabled
 	Timezone           string
 	activeSessions     map[string][]*SMGSession // group sessions per sessionId, multiple runs based on derived charging
 	aSessionsMux       sync.RWMutex
@@ -120,8 +129,7 @@ type smgSessionTerminator struct {
 
 // setSessionTerminator installs a new terminator for a session
 func (smg *SMGeneric) setSessionTerminator(s *SMGSession) {
-	ttl := s.EventStart.GetSessionTTL(smg.cgrCfg.SmGenericConfig.SessionTTL,
-		smg.cgrCfg.SmGenericConfig.SessionTTLMaxDelay)
+	ttl := s.EventStart.GetSessionTTL(smg.cgrCfg.SMGSessionTTL())
 	if ttl == 0 {
 		return
 	}
@@ -184,12 +192,29 @@ func (smg *SMGeneric) ttlTerminate(s *SMGSession, tmtr *smgSessionTerminator) {
 	for _, s := range aSessions[s.CGRID] {
 		s.debit(debitUsage, tmtr.ttlLastUsed)
 	}
-	smg.sessionEnd(s.CGRID, s.TotalUsage)
+	s.mux.RLock()
+	hasClnt := s.clntConn != nil && !reflect.ValueOf(s.clntConn).IsNil()
+	s.mux.RUnlock()
+	if hasClnt && smg.cgrCfg.SmGenericConfig.SessionTTLDisconnect { // the client might still consider the session active, ie: NAS missing to send us the updates
+		if err := s.disconnectSession(SessionTTLExpired); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<SMGeneric> Could not disconnect session: %s on TTL, error: %s", s.CGRID, err.Error()))
+		}
+	}
+	smg.forceSessionEnd(s)
+}
+
+// forceSessionEnd ends a session on our side with the usage debited so far and generates its CDR,
+// used when the client will not terminate the session anymore
+func (smg *SMGeneric) forceSessionEnd(s *SMGSession) {
+	s.mux.RLock()
+	cgrID, totalUsage := s.CGRID, s.TotalUsage
+	s.mux.RUnlock()
+	smg.sessionEnd(cgrID, totalUsage)
 	cdr := s.EventStart.AsStoredCdr(smg.cgrCfg, smg.Timezone)
-	cdr.Usage = s.TotalUsage
+	cdr.Usage = totalUsage
 	var reply string
 	smg.cdrsrv.Call("CdrsV1.ProcessCDR", cdr, &reply)
-	smg.replicateSessionsWithID(s.CGRID, false, smg.smgReplConns)
+	smg.replicateSessionsWithID(cgrID, false, smg.smgReplConns)
 }
 
 func (smg *SMGeneric) recordASession(s *SMGSession) {
@@ -198,13 +223,21 @@ func (smg *SMGeneric) recordASession(s *SMGSession) {
 	smg.setSessionTerminator(s)
 	smg.indexSession(s, false)
 	smg.aSessionsMux.Unlock()
+	if smg.dm != nil {
+		s.mux.Lock()
+		s.dm = smg.dm
+		s.nodeID = smg.cgrCfg.InstanceID
+		s.store()
+		s.mux.Unlock()
+	}
 }
 
 // Remove session from session list, removes all related in case of multiple runs, true if item was found
 func (smg *SMGeneric) unrecordASession(cgrID string) bool {
 	smg.aSessionsMux.Lock()
-	defer smg.aSessionsMux.Unlock()
-	if _, found := smg.activeSessions[cgrID]; !found {
+	ss, found := smg.activeSessions[cgrID]
+	if !found {
+		smg.aSessionsMux.Unlock()
 		return false
 	}
 	delete(smg.activeSessions, cgrID)
@@ -214,9 +247,83 @@ func (smg *SMGeneric) unrecordASession(cgrID string) bool {
 	}
 	smg.sTsMux.RUnlock()
 	smg.unindexSession(cgrID, false)
+	smg.aSessionsMux.Unlock()
+	if smg.dm != nil {
+		for _, s := range ss {
+			s.mux.Lock()
+			s.dm = nil // stop persisting the debits done from here on
+			s.mux.Unlock()
+			if err := smg.dm.RemoveStoredSession(smg.cgrCfg.InstanceID, cgrID, s.RunID); err != nil && err != utils.ErrNotFound {
+				utils.Logger.Warning(fmt.Sprintf("<SMGeneric> Could not remove stored session: %s, runId: %s, error: %s",
+					cgrID, s.RunID, err.Error()))
+			}
+		}
+	}
 	return true
 }
 
+// recoverSessions loads the sessions persisted by this engine before restart and makes them active again,
+// the terminators are re-armed so the unused reservations get refunded if the session does not come back.
+// Without session TTL nothing would end them, so these are closed with the usage debited so far.
+func (smg *SMGeneric) recoverSessions() (err error) {
+	if smg.dm == nil {
+		return
+	}
+	nodePrfx := utils.SMGSessionPrefix + smg.cgrCfg.InstanceID + utils.CONCATENATED_KEY_SEP
+	keys, err := smg.dm.DataDB().GetKeysForPrefix(nodePrfx)
+	if err != nil {
+		return
+	}
+	stopDebitChans := make(map[string]chan struct{})
+	var noTTLCGRIDs []string
+	for _, key := range keys {
+		ssID := key[len(nodePrfx):]
+		idx := strings.Index(ssID, utils.CONCATENATED_KEY_SEP) // CGRID is a hash, RunID is the remaining part
+		if idx == -1 {
+			continue
+		}
+		stored, err := smg.dm.GetStoredSession(smg.cgrCfg.InstanceID, ssID[:idx], ssID[idx+1:])
+		if err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<SMGeneric> Could not recover stored session: %s, error: %s", ssID, err.Error()))
+			continue
+		}
+		s := &SMGSession{CGRID: stored.CGRID, RunID: stored.RunID, Timezone: stored.Timezone,
+			EventStart: SMGenericEvent(stored.EventStart), CD: stored.CD, EventCost: stored.EventCost,
+			ExtraDuration: stored.ExtraDuration, LastUsage: stored.LastUsage, LastDebit: stored.LastDebit,
+			TotalUsage: stored.TotalUsage, rals: smg.rals, cdrsrv: smg.cdrsrv, recovered: true}
+		smg.recordASession(s)
+		smg.sTsMux.RLock()
+		_, hasTerminator := smg.sessionTerminators[s.CGRID]
+		smg.sTsMux.RUnlock()
+		if !hasTerminator {
+			if _, has := stopDebitChans[s.CGRID]; !has {
+				stopDebitChans[s.CGRID] = nil // no debit loop
+				noTTLCGRIDs = append(noTTLCGRIDs, s.CGRID)
+			}
+			continue
+		}
+		if smg.cgrCfg.SmGenericConfig.DebitInterval != 0 {
+			if _, has := stopDebitChans[s.CGRID]; !has {
+				stopDebitChans[s.CGRID] = make(chan struct{})
+			}
+			s.stopDebit = stopDebitChans[s.CGRID]
+			go s.debitLoop(smg.cgrCfg.SmGenericConfig.DebitInterval)
+		}
+	}
+	if len(keys) != 0 {
+		utils.Logger.Info(fmt.Sprintf("<SMGeneric> recovered %d stored session runs", len(keys)))
+	}
+	for _, cgrID := range noTTLCGRIDs {
+		aSessions := smg.getSessions(cgrID, false)
+		if len(aSessions[cgrID]) == 0 {
+			continue
+		}
+		utils.Logger.Warning(fmt.Sprintf("<SMGeneric> ending recovered session: %s without session TTL", cgrID))
+		smg.forceSessionEnd(aSessions[cgrID][0])
+	}
+	return
+}
+
 // indexSession explores settings and builds SessionsIndex
 // uses different tables and mutex-es depending on active/passive session
 func (smg *SMGeneric) indexSession(s *SMGSession, passiveSessions bool) {
@@ -588,17 +695,33 @@ func (smg *SMGeneric) passiveToActive(cgrID string) (pSessions map[string][]*SMG
 // asActiveSessions returns sessions from either active or passive table as []*ActiveSession
 func (smg *SMGeneric) asActiveSessions(fltrs map[string]string, count, passiveSessions bool) (aSessions []*ActiveSession, counter int, err error) {
 	aSessions = make([]*ActiveSession, 0) // Make sure we return at least empty list and not nil
+	remainingSessions, err := smg.filterSessions(fltrs, passiveSessions)
+	if err != nil {
+		return nil, 0, err
+	}
+	if count {
+		return nil, len(remainingSessions), nil
+	}
+	for _, s := range remainingSessions {
+		aSessions = append(aSessions, s.AsActiveSession(smg.Timezone)) // Expensive for large number of sessions
+	}
+	return
+}
+
+// filterSessions returns the sessions matching all of the filters
+func (smg *SMGeneric) filterSessions(fltrs map[string]string, passiveSessions bool) (remainingSessions []*SMGSession, err error) {
 	// Check first based on indexes so we can downsize the list of matching sessions
 	matchingSessionIDs, checkedFilters := smg.getSessionIDsMatchingIndexes(fltrs, passiveSessions)
 	if len(matchingSessionIDs) == 0 && len(checkedFilters) != 0 {
 		return
 	}
-	for fltrFldName := range fltrs {
-		if _, alreadyChecked := checkedFilters[fltrFldName]; alreadyChecked && fltrFldName != utils.MEDI_RUNID { // Optimize further checks, RunID should stay since it can create bugs
-			delete(fltrs, fltrFldName)
+	unchecked := make(map[string]string, len(fltrs)) // the filters belong to the caller
+	for fltrFldName, fltrFldVal := range fltrs {
+		if _, alreadyChecked := checkedFilters[fltrFldName]; !alreadyChecked || fltrFldName == utils.MEDI_RUNID { // Optimize further checks, RunID should stay since it can create bugs
+			unchecked[fltrFldName] = fltrFldVal
 		}
 	}
-	var remainingSessions []*SMGSession // Survived index matching
+	fltrs = unchecked
 	var ss map[string][]*SMGSession
 	if passiveSessions {
 		ss = smg.getSessions(fltrs[utils.CGRID], true)
@@ -617,7 +740,7 @@ func (smg *SMGeneric) asActiveSessions(fltrs map[string]string, count, passiveSe
 		for i := 0; i < len(remainingSessions); {
 			sMp, err := remainingSessions[i].EventStart.AsMapStringString()
 			if err != nil {
-				return nil, 0, err
+				return nil, err
 			}
 			if _, hasRunID := sMp[utils.MEDI_RUNID]; !hasRunID {
 				sMp[utils.MEDI_RUNID] = utils.META_DEFAULT
@@ -636,12 +759,6 @@ func (smg *SMGeneric) asActiveSessions(fltrs map[string]string, count, passiveSe
 			i++
 		}
 	}
-	if count {
-		return nil, len(remainingSessions), nil
-	}
-	for _, s := range remainingSessions {
-		aSessions = append(aSessions, s.AsActiveSession(smg.Timezone)) // Expensive for large number of sessions
-	}
 	return
 }
 
@@ -742,7 +859,7 @@ func (smg *SMGeneric) UpdateSession(gev SMGenericEvent, clnt rpcclient.RpcClient
 		smg.replicateSessionsWithID(initialCGRID, false, smg.smgReplConns)
 	}
 	smg.resetTerminatorTimer(cgrID,
-		gev.GetSessionTTL(smg.cgrCfg.SmGenericConfig.SessionTTL, smg.cgrCfg.SmGenericConfig.SessionTTLMaxDelay),
+		gev.GetSessionTTL(smg.cgrCfg.SMGSessionTTL()),
 		gev.GetSessionTTLLastUsed(), gev.GetSessionTTLUsage())
 	var lastUsed *time.Duration
 	var evLastUsed time.Duration
@@ -766,6 +883,7 @@ func (smg *SMGeneric) UpdateSession(gev SMGenericEvent, clnt rpcclient.RpcClient
 		}
 	}
 	defer smg.replicateSessionsWithID(gev.GetCGRID(utils.META_DEFAULT), false, smg.smgReplConns)
+	smg.confirmRecovered(aSessions[cgrID], clnt) // updated by the connector, not to be ended by SyncSessions
 	for _, s := range aSessions[cgrID] {
 		var maxDur time.Duration
 		if maxDur, err = s.debit(maxUsage, lastUsed); err != nil {
@@ -833,6 +951,7 @@ func (smg *SMGeneric) TerminateSession(gev SMGenericEvent, clnt rpcclient.RpcCli
 		}
 		hasActiveSession = true
 		defer smg.replicateSessionsWithID(sessionID, false, smg.smgReplConns)
+		smg.confirmRecovered(aSessions[sessionID], clnt)
 		s := aSessions[sessionID][0]
 		if errUsage != nil {
 			usage = s.TotalUsage - s.LastUsage + lastUsed
@@ -856,6 +975,22 @@ func (smg *SMGeneric) ChargeEvent(gev SMGenericEvent) (maxUsage time.Duration, e
 		return item.Value.(time.Duration), item.Err
 	}
 	defer smg.responseCache.Cache(cacheKey, &cache.CacheItem{Value: maxUsage, Err: err})
+	// retries with the same IdempotencyKey get the first reply back instead of being charged again
+	var chrgErr error
+	if err = engine.ProcessIdempotent(gev.GetTenant(utils.META_DEFAULT), gev.GetAccount(utils.META_DEFAULT),
+		"SMGeneric.ChargeEvent", gev.GetIdempotencyKey(), gev, &maxUsage, func() error {
+			if maxUsage, chrgErr = smg.chargeEvent(gev, cgrID); chrgErr == ErrPartiallyExecuted {
+				return nil // debited already, only storing the costs failed
+			}
+			return chrgErr
+		}); err == nil {
+		err = chrgErr
+	}
+	return
+}
+
+// chargeEvent debits all the session runs of the event, refunding them on errors
+func (smg *SMGeneric) chargeEvent(gev SMGenericEvent, cgrID string) (maxUsage time.Duration, err error) {
 	var sessionRuns []*engine.SessionRun
 	if err = smg.rals.Call("Responder.GetSessionRuns", gev.AsStoredCdr(smg.cgrCfg, smg.Timezone), &sessionRuns); err != nil {
 		return
@@ -967,12 +1102,16 @@ func (smg *SMGeneric) ProcessCDR(gev SMGenericEvent) (err error) {
 	return
 }
 
+// Connect recovers the sessions persisted in DataDB before restart
 func (smg *SMGeneric) Connect() error {
-	return nil
+	return smg.recoverSessions()
 }
 
 // System shutdown
 func (smg *SMGeneric) Shutdown() error {
+	if smg.dm != nil { // sessions stay stored, they will be recovered on restart
+		return nil
+	}
 	for ssId := range smg.getSessions("", false) { // Force sessions shutdown
 		smg.sessionEnd(ssId, time.Duration(smg.cgrCfg.MaxCallDuration))
 	}
@@ -1249,3 +1388,136 @@ func (smg *SMGeneric) BiRPCV1ReplicatePassiveSessions(clnt rpcclient.RpcClientCo
 	*reply = utils.OK
 	return
 }
+
+// clientSessions returns the active sessions matching the filters, one per CGRID so we do not bother the clients with derived runs
+func (smg *SMGeneric) clientSessions(fltr map[string]string) (ss []*SMGSession, err error) {
+	fltrs := make(map[string]string, len(fltr)) // do not alter the filters of the caller
+	for fldName, fldVal := range fltr {
+		if fldVal == "" {
+			fldVal = utils.META_NONE
+		}
+		fltrs[fldName] = fldVal
+	}
+	allSS, err := smg.filterSessions(fltrs, false)
+	if err != nil {
+		return nil, utils.NewErrServerError(err)
+	}
+	cgrIDs := make(utils.StringMap)
+	for _, s := range allSS {
+		if _, has := cgrIDs[s.CGRID]; has {
+			continue
+		}
+		cgrIDs[s.CGRID] = true
+		ss = append(ss, s)
+	}
+	if len(ss) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	return
+}
+
+// BiRPCV1ReAuthorizeSessions asks the clients of the matching sessions to re-authorize them, ie: after a top-up or threshold hit
+func (smg *SMGeneric) BiRPCV1ReAuthorizeSessions(clnt rpcclient.RpcClientConnection, fltr map[string]string, reply *string) error {
+	ss, err := smg.clientSessions(fltr)
+	if err != nil {
+		return err
+	}
+	for _, s := range ss {
+		if err := s.reAuthorize(); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<SMGeneric> Could not re-authorize session: %s, error: %s", s.CGRID, err.Error()))
+		}
+	}
+	*reply = utils.OK
+	return nil
+}
+
+type ArgsDisconnectSessions struct {
+	Filter map[string]string
+	Reason string
+}
+
+// BiRPCV1DisconnectSessions asks the clients of the matching sessions to disconnect them, ie: when the account is disabled
+func (smg *SMGeneric) BiRPCV1DisconnectSessions(clnt rpcclient.RpcClientConnection, args ArgsDisconnectSessions, reply *string) error {
+	ss, err := smg.clientSessions(args.Filter)
+	if err != nil {
+		return err
+	}
+	if args.Reason == "" {
+		args.Reason = utils.ErrAccountDisabled.Error()
+	}
+	for _, s := range ss {
+		if err := s.disconnectSession(args.Reason); err != nil {
+			utils.Logger.Warning(fmt.Sprintf("<SMGeneric> Could not disconnect session: %s, error: %s", s.CGRID, err.Error()))
+		}
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// confirmRecovered marks the recovered session runs as known to the connector, attaching them to its client connection
+func (smg *SMGeneric) confirmRecovered(sRuns []*SMGSession, clnt rpcclient.RpcClientConnection) {
+	for _, sRun := range sRuns {
+		sRun.mux.Lock()
+		if sRun.recovered {
+			sRun.recovered = false
+			if clnt != nil && !reflect.ValueOf(clnt).IsNil() {
+				sRun.clntConn = clnt
+			}
+		}
+		sRun.mux.Unlock()
+	}
+}
+
+// ArgsSyncSessions contains the sessions still active on a connector
+type ArgsSyncSessions struct {
+	Filter    map[string]string // limits the sync to the sessions of the connector, ie: OriginHost
+	OriginIDs []string          // OriginIDs of the sessions active on the connector side
+}
+
+// BiRPCV1SyncSessions reconciles the sessions recovered after a restart with the ones reported by a connector.
+// The sessions unknown to the connector are ended with the usage debited so far, the known ones get attached to the client connection.
+// Sessions started after the restart are left alone since the connector might not have recorded them yet.
+func (smg *SMGeneric) BiRPCV1SyncSessions(clnt rpcclient.RpcClientConnection, args ArgsSyncSessions, reply *string) error {
+	if args.Filter == nil {
+		args.Filter = make(map[string]string)
+	}
+	ss, err := smg.clientSessions(args.Filter)
+	if err != nil && err != utils.ErrNotFound {
+		return err
+	}
+	originIDs := utils.StringMapFromSlice(args.OriginIDs)
+	for _, s := range ss {
+		s.mux.RLock()
+		recovered := s.recovered
+		s.mux.RUnlock()
+		if !recovered {
+			continue
+		}
+		if _, has := originIDs[s.EventStart.GetOriginID(utils.META_DEFAULT)]; !has {
+			utils.Logger.Warning(fmt.Sprintf("<SMGeneric> Ending session: %s, not active on the connector anymore", s.CGRID))
+			smg.forceSessionEnd(s)
+			continue
+		}
+		smg.confirmRecovered(smg.getSessions(s.CGRID, false)[s.CGRID], clnt)
+	}
+	*reply = utils.OK
+	return nil
+}
+
+// Metrics implements utils.MetricsCollector, exporting the number of sessions handled
+func (smg *SMGeneric) Metrics() []*utils.MetricFamily {
+	smg.aSessionsMux.RLock()
+	aSessions := len(smg.activeSessions)
+	smg.aSessionsMux.RUnlock()
+	smg.pSessionsMux.RLock()
+	pSessions := len(smg.passiveSessions)
+	smg.pSessionsMux.RUnlock()
+	return []*utils.MetricFamily{
+		&utils.MetricFamily{Name: "cgr_smg_sessions", Type: utils.MetricGauge,
+			Help: "Number of sessions handled by SMGeneric, per state",
+			Samples: []*utils.MetricSample{
+				&utils.MetricSample{Labels: map[string]string{"state": "active"}, Value: float64(aSessions)},
+				&utils.MetricSample{Labels: map[string]string{"state": "passive"}, Value: float64(pSessions)},
+			}},
+	}
+}
@@ -20,8 +20,10 @@ package sessionmanager
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cgrates/cgrates/config"
+	"github.com/cgrates/cgrates/engine"
 	"github.com/cgrates/cgrates/utils"
 )
 
@@ -34,7 +36,7 @@ func init() {
 }
 
 func TestSMGSessionIndexing(t *testing.T) {
-	smg := NewSMGeneric(smgCfg, nil, nil, nil, "UTC")
+	smg := NewSMGeneric(smgCfg, nil, nil, nil, nil, "UTC")
 	smGev := SMGenericEvent{
 		utils.EVENT_NAME:       "TEST_EVENT",
 		utils.TOR:              "*voice",
@@ -389,7 +391,7 @@ func TestSMGSessionIndexing(t *testing.T) {
 }
 
 func TestSMGActiveSessions(t *testing.T) {
-	smg := NewSMGeneric(smgCfg, nil, nil, nil, "UTC")
+	smg := NewSMGeneric(smgCfg, nil, nil, nil, nil, "UTC")
 	smGev1 := SMGenericEvent{
 		utils.EVENT_NAME:       "TEST_EVENT",
 		utils.TOR:              "*voice",
@@ -463,7 +465,7 @@ func TestSMGActiveSessions(t *testing.T) {
 }
 
 func TestGetPassiveSessions(t *testing.T) {
-	smg := NewSMGeneric(smgCfg, nil, nil, nil, "UTC")
+	smg := NewSMGeneric(smgCfg, nil, nil, nil, nil, "UTC")
 	if pSS := smg.getSessions("", true); len(pSS) != 0 {
 		t.Errorf("PassiveSessions: %+v", pSS)
 	}
@@ -532,3 +534,311 @@ func TestGetPassiveSessions(t *testing.T) {
 		t.Errorf("PassiveSessions: %+v", pSS)
 	}
 }
+
+// clntConnRecorder records the calls received from SMG
+type clntConnRecorder struct {
+	calls []string
+}
+
+func (cr *clntConnRecorder) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	cr.calls = append(cr.calls, serviceMethod)
+	*reply.(*string) = utils.OK
+	return nil
+}
+
+func TestSMGReAuthorizeDisconnectSessions(t *testing.T) {
+	smg := NewSMGeneric(smgCfg, nil, nil, nil, nil, "UTC")
+	clnt := new(clntConnRecorder)
+	for _, acnt := range []string{"1001", "1002"} {
+		ev := SMGenericEvent{
+			utils.EVENT_NAME: "TEST_EVENT",
+			utils.TOR:        utils.DATA,
+			utils.ACCID:      "session" + acnt,
+			utils.TENANT:     "cgrates.org",
+			utils.ACCOUNT:    acnt,
+		}
+		for _, runID := range []string{utils.META_DEFAULT, "derived"} {
+			smg.recordASession(&SMGSession{CGRID: ev.GetCGRID(utils.META_DEFAULT), RunID: runID,
+				EventStart: ev, clntConn: clnt})
+		}
+	}
+	var reply string
+	if err := smg.BiRPCV1ReAuthorizeSessions(nil, map[string]string{utils.ACCOUNT: "1001"}, &reply); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual([]string{"SMGClientV1.ReAuthorizeSession"}, clnt.calls) {
+		t.Errorf("Received calls: %+v", clnt.calls)
+	}
+	clnt.calls = nil
+	if err := smg.BiRPCV1DisconnectSessions(nil, ArgsDisconnectSessions{Filter: map[string]string{utils.TENANT: "cgrates.org"}}, &reply); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual([]string{"SMGClientV1.DisconnectSession", "SMGClientV1.DisconnectSession"}, clnt.calls) {
+		t.Errorf("Received calls: %+v", clnt.calls)
+	}
+	if err := smg.BiRPCV1ReAuthorizeSessions(nil, map[string]string{utils.ACCOUNT: "1003"}, &reply); err != utils.ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+	fltr := map[string]string{utils.TENANT: "cgrates.org", utils.ACCOUNT: "1001", utils.SUBJECT: ""}
+	smg.BiRPCV1ReAuthorizeSessions(nil, fltr, &reply)
+	if eFltr := map[string]string{utils.TENANT: "cgrates.org", utils.ACCOUNT: "1001", utils.SUBJECT: ""}; !reflect.DeepEqual(eFltr, fltr) {
+		t.Errorf("Filter of the caller modified: %+v", fltr)
+	}
+}
+
+func TestSMGStoreRecoverSessions(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.InstanceID = "node1"
+	cfg.SmGenericConfig.StoreSessions = true
+	cfg.SmGenericConfig.SessionTTL = time.Hour
+	dataDB, _ := engine.NewMapStorage()
+	dm := engine.NewDataManager(dataDB)
+	cdrs := new(clntConnRecorder)
+	smg := NewSMGeneric(cfg, nil, cdrs, nil, dm, "UTC")
+	ev := SMGenericEvent{
+		utils.EVENT_NAME: "TEST_EVENT",
+		utils.TOR:        utils.VOICE,
+		utils.ACCID:      "12345",
+		utils.CDRHOST:    "127.0.0.1",
+		utils.TENANT:     "cgrates.org",
+		utils.ACCOUNT:    "1001",
+	}
+	cgrID := ev.GetCGRID(utils.META_DEFAULT)
+	smg.recordASession(&SMGSession{CGRID: cgrID, RunID: utils.META_DEFAULT, EventStart: ev,
+		TotalUsage: 30 * time.Second, LastUsage: 30 * time.Second})
+	if ss, err := dm.GetStoredSession(cfg.InstanceID, cgrID, utils.META_DEFAULT); err != nil {
+		t.Error(err)
+	} else if ss.TotalUsage != 30*time.Second {
+		t.Errorf("Stored session: %+v", ss)
+	}
+	// other engine sharing the DataDB does not pick up our sessions
+	otherCfg, _ := config.NewDefaultCGRConfig()
+	otherCfg.InstanceID = "node2"
+	otherCfg.SmGenericConfig.StoreSessions = true
+	otherCfg.SmGenericConfig.SessionTTL = time.Hour
+	otherSMG := NewSMGeneric(otherCfg, nil, cdrs, nil, dm, "UTC")
+	if err := otherSMG.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if aSessions := otherSMG.getSessions(cgrID, false); len(aSessions) != 0 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+	// engine restart, sessions recovered out of DataDB
+	smg = NewSMGeneric(cfg, nil, cdrs, nil, dm, "UTC")
+	if err := smg.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	aSessions := smg.getSessions(cgrID, false)
+	if len(aSessions[cgrID]) != 1 {
+		t.Fatalf("Active sessions: %+v", aSessions)
+	}
+	if s := aSessions[cgrID][0]; s.RunID != utils.META_DEFAULT || s.TotalUsage != 30*time.Second ||
+		s.EventStart.GetOriginID(utils.META_DEFAULT) != "12345" {
+		t.Errorf("Recovered session: %+v", s)
+	}
+	smg.sTsMux.RLock()
+	_, hasTerminator := smg.sessionTerminators[cgrID]
+	smg.sTsMux.RUnlock()
+	if !hasTerminator {
+		t.Error("Session terminator not re-armed")
+	}
+	// connector still has the session, client connection attached
+	clnt := new(clntConnRecorder)
+	var reply string
+	if err := smg.BiRPCV1SyncSessions(clnt, ArgsSyncSessions{Filter: map[string]string{utils.CDRHOST: "127.0.0.1"},
+		OriginIDs: []string{"12345"}}, &reply); err != nil {
+		t.Error(err)
+	} else if s := smg.getSessions(cgrID, false)[cgrID][0]; s.clntConn != clnt || s.recovered {
+		t.Errorf("Synced session: %+v", s)
+	}
+	// sessions confirmed or started after restart are not ended by later syncs
+	ev2 := SMGenericEvent{
+		utils.EVENT_NAME: "TEST_EVENT",
+		utils.TOR:        utils.VOICE,
+		utils.ACCID:      "12346",
+		utils.CDRHOST:    "127.0.0.1",
+		utils.TENANT:     "cgrates.org",
+		utils.ACCOUNT:    "1001",
+	}
+	cgrID2 := ev2.GetCGRID(utils.META_DEFAULT)
+	smg.recordASession(&SMGSession{CGRID: cgrID2, RunID: utils.META_DEFAULT, EventStart: ev2})
+	if err := smg.BiRPCV1SyncSessions(clnt, ArgsSyncSessions{Filter: map[string]string{utils.CDRHOST: "127.0.0.1"}}, &reply); err != nil {
+		t.Error(err)
+	}
+	if aSessions := smg.getSessions("", false); len(aSessions) != 2 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+	if len(cdrs.calls) != 0 {
+		t.Errorf("Received calls: %+v", cdrs.calls)
+	}
+	// connector lost the recovered session, we end it on our side
+	smg = NewSMGeneric(cfg, nil, cdrs, nil, dm, "UTC")
+	if err := smg.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := smg.BiRPCV1SyncSessions(clnt, ArgsSyncSessions{Filter: map[string]string{utils.CDRHOST: "127.0.0.1"},
+		OriginIDs: []string{"12346"}}, &reply); err != nil {
+		t.Error(err)
+	}
+	if aSessions := smg.getSessions(cgrID, false); len(aSessions) != 0 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+	if aSessions := smg.getSessions(cgrID2, false); len(aSessions[cgrID2]) != 1 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+	if _, err := dm.GetStoredSession(cfg.InstanceID, cgrID, utils.META_DEFAULT); err != utils.ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+	if !reflect.DeepEqual([]string{"CdrsV1.ProcessCDR"}, cdrs.calls) {
+		t.Errorf("Received calls: %+v", cdrs.calls)
+	}
+}
+
+func TestSMGSyncSessionsPerConnector(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.InstanceID = "node1"
+	cfg.SmGenericConfig.StoreSessions = true
+	cfg.SmGenericConfig.SessionTTL = time.Hour
+	dataDB, _ := engine.NewMapStorage()
+	dm := engine.NewDataManager(dataDB)
+	cdrs := new(clntConnRecorder)
+	smg := NewSMGeneric(cfg, nil, cdrs, nil, dm, "UTC")
+	cgrIDs := make(map[string]string)
+	for accID, connID := range map[string]string{"12345": "da1.example.org", "12346": "da2.example.org"} {
+		ev := SMGenericEvent{
+			utils.EVENT_NAME:     "DIAMETER_CCR",
+			utils.TOR:            utils.VOICE,
+			utils.ACCID:          accID,
+			utils.TENANT:         "cgrates.org",
+			utils.ACCOUNT:        "1001",
+			utils.CGRConnectorID: connID,
+		}
+		cgrIDs[connID] = ev.GetCGRID(utils.META_DEFAULT)
+		smg.recordASession(&SMGSession{CGRID: cgrIDs[connID], RunID: utils.META_DEFAULT, EventStart: ev})
+	}
+	smg = NewSMGeneric(cfg, nil, cdrs, nil, dm, "UTC")
+	if err := smg.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	// first agent lost its session, the one of the second agent is not touched
+	clnt := new(clntConnRecorder)
+	var reply string
+	if err := smg.BiRPCV1SyncSessions(clnt, ArgsSyncSessions{Filter: map[string]string{utils.EVENT_NAME: "DIAMETER_CCR",
+		utils.CGRConnectorID: "da1.example.org"}}, &reply); err != nil {
+		t.Error(err)
+	}
+	if aSessions := smg.getSessions(cgrIDs["da1.example.org"], false); len(aSessions) != 0 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+	aSessions := smg.getSessions(cgrIDs["da2.example.org"], false)
+	if len(aSessions[cgrIDs["da2.example.org"]]) != 1 {
+		t.Fatalf("Active sessions: %+v", aSessions)
+	}
+	s := aSessions[cgrIDs["da2.example.org"]][0]
+	if !s.recovered {
+		t.Errorf("Session not recovered anymore: %+v", s)
+	}
+	// update out of the second agent confirms the session
+	smg.confirmRecovered(aSessions[cgrIDs["da2.example.org"]], clnt)
+	if s.recovered || s.clntConn != clnt {
+		t.Errorf("Session not confirmed: %+v", s)
+	}
+	if err := smg.BiRPCV1SyncSessions(clnt, ArgsSyncSessions{Filter: map[string]string{utils.EVENT_NAME: "DIAMETER_CCR",
+		utils.CGRConnectorID: "da2.example.org"}}, &reply); err != nil {
+		t.Error(err)
+	}
+	if aSessions := smg.getSessions(cgrIDs["da2.example.org"], false); len(aSessions) != 1 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+}
+
+func TestSMGRecoverSessionsNoTTL(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.InstanceID = "node1"
+	cfg.SmGenericConfig.StoreSessions = true
+	cfg.SmGenericConfig.DebitInterval = time.Second
+	dataDB, _ := engine.NewMapStorage()
+	dm := engine.NewDataManager(dataDB)
+	cdrs := new(clntConnRecorder)
+	smg := NewSMGeneric(cfg, nil, cdrs, nil, dm, "UTC")
+	ev := SMGenericEvent{
+		utils.EVENT_NAME: "TEST_EVENT",
+		utils.TOR:        utils.VOICE,
+		utils.ACCID:      "12345",
+		utils.CDRHOST:    "127.0.0.1",
+		utils.TENANT:     "cgrates.org",
+		utils.ACCOUNT:    "1001",
+	}
+	cgrID := ev.GetCGRID(utils.META_DEFAULT)
+	smg.recordASession(&SMGSession{CGRID: cgrID, RunID: utils.META_DEFAULT, EventStart: ev,
+		TotalUsage: 30 * time.Second, LastUsage: 30 * time.Second})
+	// engine restart, nothing would terminate the session so it is ended with the usage debited so far
+	smg = NewSMGeneric(cfg, nil, cdrs, nil, dm, "UTC")
+	if err := smg.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if aSessions := smg.getSessions(cgrID, false); len(aSessions) != 0 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+	if _, err := dm.GetStoredSession(cfg.InstanceID, cgrID, utils.META_DEFAULT); err != utils.ErrNotFound {
+		t.Errorf("Expecting: %v, received: %v", utils.ErrNotFound, err)
+	}
+	if !reflect.DeepEqual([]string{"CdrsV1.ProcessCDR"}, cdrs.calls) {
+		t.Errorf("Received calls: %+v", cdrs.calls)
+	}
+}
+
+func TestSMGTTLTerminateDisconnect(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.SmGenericConfig.SessionTTL = 10 * time.Millisecond
+	cfg.SmGenericConfig.SessionTTLDisconnect = true
+	cdrs := new(clntConnRecorder)
+	smg := NewSMGeneric(cfg, nil, cdrs, nil, nil, "UTC")
+	ev := SMGenericEvent{
+		utils.EVENT_NAME: "TEST_EVENT",
+		utils.TOR:        utils.VOICE,
+		utils.ACCID:      "12345",
+		utils.TENANT:     "cgrates.org",
+		utils.ACCOUNT:    "1001",
+	}
+	cgrID := ev.GetCGRID(utils.META_DEFAULT)
+	clnt := new(clntConnRecorder)
+	smg.recordASession(&SMGSession{CGRID: cgrID, RunID: utils.META_DEFAULT, EventStart: ev, clntConn: clnt,
+		ExtraDuration: time.Minute, LastDebit: time.Minute}) // covers the TTL debit, no RALs needed
+	time.Sleep(50 * time.Millisecond)
+	if aSessions := smg.getSessions(cgrID, false); len(aSessions) != 0 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+	if !reflect.DeepEqual([]string{"SMGClientV1.DisconnectSession"}, clnt.calls) {
+		t.Errorf("Received calls: %+v", clnt.calls)
+	}
+	if !reflect.DeepEqual([]string{"CdrsV1.ProcessCDR"}, cdrs.calls) {
+		t.Errorf("Received calls: %+v", cdrs.calls)
+	}
+}
+
+func TestSMGTTLTerminateNoDisconnect(t *testing.T) {
+	cfg, _ := config.NewDefaultCGRConfig()
+	cfg.SmGenericConfig.SessionTTL = 10 * time.Millisecond
+	cdrs := new(clntConnRecorder)
+	smg := NewSMGeneric(cfg, nil, cdrs, nil, nil, "UTC")
+	ev := SMGenericEvent{
+		utils.EVENT_NAME: "TEST_EVENT",
+		utils.TOR:        utils.VOICE,
+		utils.ACCID:      "12345",
+		utils.TENANT:     "cgrates.org",
+		utils.ACCOUNT:    "1001",
+	}
+	cgrID := ev.GetCGRID(utils.META_DEFAULT)
+	clnt := new(clntConnRecorder)
+	smg.recordASession(&SMGSession{CGRID: cgrID, RunID: utils.META_DEFAULT, EventStart: ev, clntConn: clnt,
+		ExtraDuration: time.Minute, LastDebit: time.Minute})
+	time.Sleep(50 * time.Millisecond)
+	if aSessions := smg.getSessions(cgrID, false); len(aSessions) != 0 {
+		t.Errorf("Active sessions: %+v", aSessions)
+	}
+	if len(clnt.calls) != 0 { // session_ttl_disconnect not enabled
+		t.Errorf("Received calls: %+v", clnt.calls)
+	}
+	if !reflect.DeepEqual([]string{"CdrsV1.ProcessCDR"}, cdrs.calls) {
+		t.Errorf("Received calls: %+v", cdrs.calls)
+	}
+}
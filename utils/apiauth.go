@@ -0,0 +1,154 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package utils
+
+import (
+	"io"
+	"net"
+	"net/rpc"
+
+	"github.com/cenk/rpc2"
+)
+
+// RPCAuthorizer decides on the RPC requests received over the network
+type RPCAuthorizer interface {
+	Authenticate(apiKey, remoteAddr string) error
+	Authorize(apiKey, serviceMethod string, args interface{}, remoteAddr string) error
+}
+
+var rpcAuthorizer RPCAuthorizer // nil leaves the RPC requests unchecked
+
+// SetRPCAuthorizer enables the authorization of the RPC requests received by the listeners
+func SetRPCAuthorizer(authz RPCAuthorizer) {
+	rpcAuthorizer = authz
+}
+
+// rpcClientInfo is implemented by the connections identifying their client upfront, ie: HTTP requests
+type rpcClientInfo interface {
+	apiKey() string
+	remoteAddr() string
+}
+
+// identifiedConn attaches the client identity to a connection
+type identifiedConn struct {
+	io.ReadWriteCloser
+	key  string
+	addr string
+}
+
+func (ic *identifiedConn) apiKey() string {
+	return ic.key
+}
+
+func (ic *identifiedConn) remoteAddr() string {
+	return ic.addr
+}
+
+// newAuthServerCodec wraps c with the authorization of the requests when enabled
+func newAuthServerCodec(c rpc.ServerCodec, conn io.ReadWriteCloser) rpc.ServerCodec {
+	if rpcAuthorizer == nil {
+		return c
+	}
+	authC := &authServerCodec{ServerCodec: c, authz: rpcAuthorizer}
+	switch cl := conn.(type) {
+	case rpcClientInfo:
+		authC.key = cl.apiKey()
+		authC.addr = cl.remoteAddr()
+	case net.Conn:
+		authC.addr = cl.RemoteAddr().String()
+	}
+	return authC
+}
+
+// authServerCodec authorizes the requests of one connection.
+// The API key is either known upfront or set by the AuthV1.Authenticate request.
+// Denied requests are answered with the error instead of being dispatched.
+type authServerCodec struct {
+	rpc.ServerCodec
+	authz  RPCAuthorizer
+	key    string
+	addr   string
+	method string // of the request being read, net/rpc reads the header and body in sequence
+}
+
+func (c *authServerCodec) ReadRequestHeader(r *rpc.Request) (err error) {
+	if err = c.ServerCodec.ReadRequestHeader(r); err != nil {
+		return
+	}
+	c.method = r.ServiceMethod
+	return
+}
+
+func (c *authServerCodec) ReadRequestBody(body interface{}) (err error) {
+	if err = c.ServerCodec.ReadRequestBody(body); err != nil ||
+		body == nil { // discarding the body of an invalid request
+		return
+	}
+	if c.method == AuthV1Authenticate {
+		if apiKey, canCast := body.(*string); canCast {
+			if err = c.authz.Authenticate(*apiKey, c.addr); err == nil {
+				c.key = *apiKey
+			}
+		}
+		return
+	}
+	return c.authz.Authorize(c.key, c.method, body, c.addr)
+}
+
+// newAuthBiRPCCodec wraps the bidirectional codec c with the authorization of the requests when enabled
+func newAuthBiRPCCodec(c rpc2.Codec, conn net.Conn) rpc2.Codec {
+	if rpcAuthorizer == nil {
+		return c
+	}
+	return &authBiRPCCodec{Codec: c, authz: rpcAuthorizer, addr: conn.RemoteAddr().String()}
+}
+
+// authBiRPCCodec authorizes the requests received over one bidirectional connection.
+// rpc2 closes the connection on body errors so a denied request ends the connection.
+type authBiRPCCodec struct {
+	rpc2.Codec
+	authz  RPCAuthorizer
+	key    string
+	addr   string
+	method string // of the request being read, empty when reading a response
+}
+
+func (c *authBiRPCCodec) ReadHeader(req *rpc2.Request, resp *rpc2.Response) (err error) {
+	if err = c.Codec.ReadHeader(req, resp); err != nil {
+		return
+	}
+	c.method = req.Method
+	return
+}
+
+func (c *authBiRPCCodec) ReadRequestBody(body interface{}) (err error) {
+	if err = c.Codec.ReadRequestBody(body); err != nil ||
+		body == nil { // discarding the body of an unknown method
+		return
+	}
+	if c.method == AuthV1Authenticate {
+		if apiKey, canCast := body.(*string); canCast {
+			if err = c.authz.Authenticate(*apiKey, c.addr); err == nil {
+				c.key = *apiKey
+			}
+		}
+		return
+	}
+	return c.authz.Authorize(c.key, c.method, body, c.addr)
+}
@@ -0,0 +1,183 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package utils
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"testing"
+
+	"github.com/cenk/rpc2"
+	rpc2_jsonrpc "github.com/cenk/rpc2/jsonrpc"
+)
+
+// testAuthorizer grants everything to the "secret" key
+type testAuthorizer struct{}
+
+func (testAuthorizer) Authenticate(apiKey, remoteAddr string) error {
+	if apiKey != "secret" {
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+func (testAuthorizer) Authorize(apiKey, serviceMethod string, args interface{}, remoteAddr string) error {
+	if apiKey != "secret" {
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+type AuthTestV1 struct{}
+
+func (AuthTestV1) Authenticate(apiKey string, reply *string) error {
+	*reply = OK
+	return nil
+}
+
+func (AuthTestV1) Ping(ign string, reply *string) error {
+	*reply = OK
+	return nil
+}
+
+func TestAuthServerCodec(t *testing.T) {
+	SetRPCAuthorizer(testAuthorizer{})
+	defer SetRPCAuthorizer(nil)
+	rpcSrv := rpc.NewServer()
+	rpcSrv.RegisterName("AuthV1", new(AuthTestV1))
+	srvConn, clntConn := net.Pipe()
+	go rpcSrv.ServeCodec(newAuthServerCodec(jsonrpc.NewServerCodec(srvConn), srvConn))
+	clnt := jsonrpc.NewClient(clntConn)
+	defer clnt.Close()
+	var reply string
+	if err := clnt.Call("AuthV1.Ping", "", &reply); err == nil || err.Error() != ErrUnauthenticated.Error() {
+		t.Errorf("expecting: %v, received: %v", ErrUnauthenticated, err)
+	}
+	if err := clnt.Call(AuthV1Authenticate, "wrong", &reply); err == nil || err.Error() != ErrUnauthenticated.Error() {
+		t.Errorf("expecting: %v, received: %v", ErrUnauthenticated, err)
+	}
+	if err := clnt.Call(AuthV1Authenticate, "secret", &reply); err != nil {
+		t.Error(err)
+	}
+	reply = ""
+	if err := clnt.Call("AuthV1.Ping", "", &reply); err != nil {
+		t.Error(err)
+	} else if reply != OK {
+		t.Errorf("received: %s", reply)
+	}
+}
+
+func TestAuthServerCodecIdentifiedConn(t *testing.T) {
+	SetRPCAuthorizer(testAuthorizer{})
+	defer SetRPCAuthorizer(nil)
+	rpcSrv := rpc.NewServer()
+	rpcSrv.RegisterName("AuthV1", new(AuthTestV1))
+	srvConn, clntConn := net.Pipe()
+	idConn := &identifiedConn{ReadWriteCloser: srvConn, key: "secret", addr: "127.0.0.1:1234"}
+	go rpcSrv.ServeCodec(newAuthServerCodec(jsonrpc.NewServerCodec(idConn), idConn))
+	clnt := jsonrpc.NewClient(clntConn)
+	defer clnt.Close()
+	var reply string
+	if err := clnt.Call("AuthV1.Ping", "", &reply); err != nil {
+		t.Error(err)
+	} else if reply != OK {
+		t.Errorf("received: %s", reply)
+	}
+}
+
+func TestAuthBiRPCCodec(t *testing.T) {
+	SetRPCAuthorizer(testAuthorizer{})
+	defer SetRPCAuthorizer(nil)
+	birpcSrv := rpc2.NewServer()
+	birpcSrv.Handle(AuthV1Authenticate, func(clnt *rpc2.Client, apiKey string, reply *string) error {
+		*reply = OK
+		return nil
+	})
+	birpcSrv.Handle("SMGenericV1.Ping", func(clnt *rpc2.Client, ign string, reply *string) error {
+		*reply = OK
+		return nil
+	})
+	srvConn, clntConn := net.Pipe()
+	go birpcSrv.ServeCodec(newAuthBiRPCCodec(rpc2_jsonrpc.NewJSONCodec(srvConn), srvConn))
+	clnt := rpc2.NewClientWithCodec(rpc2_jsonrpc.NewJSONCodec(clntConn))
+	go clnt.Run()
+	defer clnt.Close()
+	var reply string
+	if err := clnt.Call(AuthV1Authenticate, "secret", &reply); err != nil {
+		t.Error(err)
+	}
+	reply = ""
+	if err := clnt.Call("SMGenericV1.Ping", "", &reply); err != nil {
+		t.Error(err)
+	} else if reply != OK {
+		t.Errorf("received: %s", reply)
+	}
+	// unauthenticated connections are dropped on their first request
+	srvConn, clntConn = net.Pipe()
+	go birpcSrv.ServeCodec(newAuthBiRPCCodec(rpc2_jsonrpc.NewJSONCodec(srvConn), srvConn))
+	clnt2 := rpc2.NewClientWithCodec(rpc2_jsonrpc.NewJSONCodec(clntConn))
+	go clnt2.Run()
+	defer clnt2.Close()
+	if err := clnt2.Call("SMGenericV1.Ping", "", &reply); err == nil {
+		t.Error("expecting the request denied")
+	}
+}
+
+func TestBiJSONrpcClientAPIKey(t *testing.T) {
+	SetRPCAuthorizer(testAuthorizer{})
+	defer SetRPCAuthorizer(nil)
+	birpcSrv := rpc2.NewServer()
+	birpcSrv.Handle(AuthV1Authenticate, func(clnt *rpc2.Client, apiKey string, reply *string) error {
+		*reply = OK
+		return nil
+	})
+	birpcSrv.Handle("SMGenericV1.Ping", func(clnt *rpc2.Client, ign string, reply *string) error {
+		*reply = OK
+		return nil
+	})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go birpcSrv.ServeCodec(newAuthBiRPCCodec(rpc2_jsonrpc.NewJSONCodec(conn), conn))
+		}
+	}()
+	if _, err := NewBiJSONrpcClient(l.Addr().String(), "wrong", nil); err == nil {
+		t.Error("expecting the API key denied")
+	}
+	clnt, err := NewBiJSONrpcClient(l.Addr().String(), "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clnt.Close()
+	var reply string
+	if err := clnt.Call("SMGenericV1.Ping", "", &reply); err != nil {
+		t.Error(err)
+	} else if reply != OK {
+		t.Errorf("received: %s", reply)
+	}
+}
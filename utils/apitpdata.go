@@ -151,6 +151,7 @@ type DestinationRate struct {
 	RoundingDecimals int
 	MaxCost          float64
 	MaxCostStrategy  string
+	Currency         string // ISO 4217 code of the rate, empty for the default currency
 }
 
 type ApierTPTiming struct {
@@ -683,6 +684,12 @@ type AttrReloadCache struct {
 	FlushAll bool // If provided, cache flush will be executed before any action
 }
 
+// ArgsCacheInvalidation is sent by an engine to its peers to invalidate cache items changed locally
+type ArgsCacheInvalidation struct {
+	Keys     []string // items to be removed out of cache
+	Prefixes []string // cache partitions to be flushed
+}
+
 type ArgsCacheKeys struct {
 	ArgsCache
 	Paginator
@@ -1265,6 +1272,7 @@ type AttrSetBalance struct {
 	Value          *float64
 	ExpiryTime     *string
 	RatingSubject  *string
+	Currency       *string // monetary balances only
 	Categories     *string
 	DestinationIds *string
 	TimingIds      *string
@@ -1338,6 +1346,11 @@ type AttrDisconnectSession struct {
 	Reason     string
 }
 
+// Attributes to send on SessionReAuthorize by SMG
+type AttrReAuthorizeSession struct {
+	EventStart map[string]interface{}
+}
+
 // TPStats is used in APIs to manage remotely offline Stats config
 type TPStats struct {
 	TPid               string
@@ -1353,6 +1366,9 @@ type TPStats struct {
 	Weight             float64
 	MinItems           int
 	Thresholds         []string
+	Bucket             string
+	BucketTimezone     string
+	BucketHistory      int
 }
 
 type TPThreshold struct {
@@ -1370,6 +1386,35 @@ type TPThreshold struct {
 	Async              bool
 }
 
+type TPSupplierProfile struct {
+	TPid               string
+	Tenant             string
+	ID                 string
+	FilterIDs          []string
+	ActivationInterval *TPActivationInterval // Time when this profile becomes active and expires
+	Sorting            string                // *weight, *least_cost, *highest_cost, *qos
+	SortingParams      []string              // metric IDs used by *qos sorting
+	Suppliers          []*TPSupplier
+	Weight             float64 // Weight to sort the profiles, the heaviest matching one is used
+}
+
+type TPSupplier struct {
+	ID                 string // supplier ID
+	FilterIDs          []string
+	RatingSubject      string // subject used when calculating the supplier cost, defaults to ID
+	ResourceIDs        []string
+	StatIDs            []string
+	Weight             float64
+	SupplierParameters string // opaque parameters passed back in replies
+}
+
+type TPExchangeRate struct {
+	TPid         string
+	FromCurrency string
+	ToCurrency   string
+	Rate         float64 // units of ToCurrency for one unit of FromCurrency
+}
+
 type TPFilter struct {
 	TPid               string
 	Tenant             string
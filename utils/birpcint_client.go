@@ -52,7 +52,8 @@ func (clnt *BiRPCInternalClient) Call(serviceMethod string, args interface{}, re
 	return clnt.serverConn.CallBiRPC(clnt.clntConn, serviceMethod, args, reply)
 }
 
-func NewBiJSONrpcClient(addr string, handlers map[string]interface{}) (*rpc2.Client, error) {
+// NewBiJSONrpcClient connects over bidirectional JSON-RPC, authenticating with apiKey if not empty
+func NewBiJSONrpcClient(addr, apiKey string, handlers map[string]interface{}) (*rpc2.Client, error) {
 	conn, err := net.Dial("tcp", addr)
 	if err != nil {
 		return nil, err
@@ -63,5 +64,12 @@ func NewBiJSONrpcClient(addr string, handlers map[string]interface{}) (*rpc2.Cli
 		clnt.Handle(method, handlerFunc)
 	}
 	go clnt.Run()
+	if apiKey != "" {
+		var reply string
+		if err = clnt.Call(AuthV1Authenticate, apiKey, &reply); err != nil {
+			clnt.Close()
+			return nil, err
+		}
+	}
 	return clnt, nil
 }
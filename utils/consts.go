@@ -19,7 +19,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>
 package utils
 
 var (
-	CDRExportFormats = []string{DRYRUN, MetaFileCSV, MetaFileFWV, MetaHTTPjsonCDR, MetaHTTPjsonMap, MetaHTTPjson, META_HTTP_POST, MetaAMQPjsonCDR, MetaAMQPjsonMap}
+	CDRExportFormats = []string{DRYRUN, MetaFileCSV, MetaFileFWV, MetaFileJSONL, MetaSQLTable, MetaHTTPjsonCDR, MetaHTTPjsonMap, MetaHTTPjson, META_HTTP_POST, MetaAMQPjsonCDR, MetaAMQPjsonMap}
 	PrimaryCdrFields = []string{CGRID, CDRSOURCE, CDRHOST, ACCID, TOR, REQTYPE, DIRECTION, TENANT, CATEGORY, ACCOUNT, SUBJECT, DESTINATION, SETUP_TIME, PDD, ANSWER_TIME, USAGE,
 		SUPPLIER, DISCONNECT_CAUSE, COST, RATED, PartialField, MEDI_RUNID}
 	GitLastLog                  string // If set, it will be processed as part of versioning
@@ -39,6 +39,8 @@ var (
 		META_HTTP_POST:  FormSuffix,
 		MetaFileCSV:     CSVSuffix,
 		MetaFileFWV:     FWVSuffix,
+		MetaFileJSONL:   JSONLSuffix,
+		MetaSQLTable:    JSNSuffix,
 	}
 	CacheInstanceToPrefix = map[string]string{
 		CacheDestinations:        DESTINATION_PREFIX,
@@ -64,6 +66,10 @@ var (
 		CacheThresholdProfiles:   ThresholdProfilePrefix,
 		CacheThresholds:          ThresholdPrefix,
 		CacheFilters:             FilterPrefix,
+		CacheSupplierProfiles:    SupplierProfilePrefix,
+		CacheExchangeRates:       ExchangeRatePrefix,
+		CacheAPIKeys:             APIKeyPrefix,
+		CacheAPIRoles:            APIRolePrefix,
 	}
 	CachePrefixToInstance map[string]string // will be built on init
 )
@@ -78,6 +84,7 @@ const (
 	MYSQL                         = "mysql"
 	MONGO                         = "mongo"
 	REDIS                         = "redis"
+	REDIS_SHARDED                 = "redis_sharded"
 	MAPSTOR                       = "mapstor"
 	LOCALHOST                     = "127.0.0.1"
 	FSCDR_FILE_CSV                = "freeswitch_file_csv"
@@ -112,6 +119,8 @@ const (
 	TBLTPStats                    = "tp_stats"
 	TBLTPThresholds               = "tp_thresholds"
 	TBLTPFilters                  = "tp_filters"
+	TBLTPSuppliers                = "tp_suppliers"
+	TBLTPExchangeRates            = "tp_exchange_rates"
 	TBLSMCosts                    = "sm_costs"
 	TBLCDRs                       = "cdrs"
 	TBLVersions                   = "versions"
@@ -135,6 +144,8 @@ const (
 	StatsCsv                      = "Stats.csv"
 	ThresholdsCsv                 = "Thresholds.csv"
 	FiltersCsv                    = "Filters.csv"
+	SuppliersCsv                  = "Suppliers.csv"
+	ExchangeRatesCsv              = "ExchangeRates.csv"
 	ROUNDING_UP                   = "*up"
 	ROUNDING_MIDDLE               = "*middle"
 	ROUNDING_DOWN                 = "*down"
@@ -267,6 +278,18 @@ const (
 	StatQueueProfilePrefix        = "sqp_"
 	ThresholdProfilePrefix        = "thp_"
 	StatQueuePrefix               = "stq_"
+	SupplierProfilePrefix         = "spp_"
+	SupplierProfilesStringIndex   = "spi_"
+	ExchangeRatePrefix            = "xrt_"
+	CDRExportQueuePrefix          = "ceq_"
+	IdempotencyRecordPrefix       = "idk_"
+	InvoicePrefix                 = "inv_"
+	InvoiceSequencePrefix         = "ivs_"
+	TaxProfilePrefix              = "txp_"
+	TaxProfilesStringIndex        = "txi_"
+	APIKeyPrefix                  = "apk_"
+	APIRolePrefix                 = "apr_"
+	SMGSessionPrefix              = "smg_"
 	LOADINST_KEY                  = "load_history"
 	SESSION_MANAGER_SOURCE        = "SMR"
 	MEDIATOR_SOURCE               = "MED"
@@ -306,6 +329,25 @@ const (
 	CGR_COMPUTELCR                = "cgr_computelcr"
 	CGR_SUPPLIERS                 = "cgr_suppliers"
 	CGRFlags                      = "cgr_flags"
+	CGRConnectorID                = "cgr_connectorid" // identifies the agent owning the session, scopes SMGenericV1.SyncSessions
+	IdempotencyKey                = "IdempotencyKey"
+	MetaRefund                    = "*refund"
+	MetaUsage                     = "*usage"
+	MetaCharge                    = "*charge"
+	MetaJSON                      = "*json"
+	CGRRefundedUsage              = "CGRRefundedUsage"
+	CGRRefundedCost               = "CGRRefundedCost"
+	CGRRefundedIncrements         = "CGRRefundedIncrements"
+	CGRRefundPending              = "CGRRefundPending"
+	RefundedCGRID                 = "RefundedCGRID"
+	RefundedRunID                 = "RefundedRunID"
+	MetaTax                       = "*tax"
+	CGRTaxes                      = "CGRTaxes"
+	CGRTaxAmount                  = "CGRTaxAmount"
+	MetaRequestClientCert         = "*request"
+	MetaRequireAnyClientCert      = "*require_any"
+	MetaVerifyClientCertIfGiven   = "*verify_if_given"
+	MetaRequireAndVerifyCert      = "*require_and_verify"
 	KAM_FLATSTORE                 = "kamailio_flatstore"
 	OSIPS_FLATSTORE               = "opensips_flatstore"
 	MAX_DEBIT_CACHE_PREFIX        = "MAX_DEBIT_"
@@ -362,6 +404,7 @@ const (
 	XML                          = "xml"
 	MetaGOBrpc                   = "*gob"
 	MetaJSONrpc                  = "*json"
+	MetaBiJSONrpc                = "*bijson"
 	MetaDateTime                 = "*datetime"
 	MetaMaskedDestination        = "*masked_destination"
 	MetaUnixTimestamp            = "*unix_timestamp"
@@ -371,6 +414,7 @@ const (
 	EVT_ACCOUNT_BALANCE_MODIFIED = "ACCOUNT_BALANCE_MODIFIED"
 	EVT_ACTION_TRIGGER_FIRED     = "ACTION_TRIGGER_FIRED"
 	EVT_ACTION_TIMING_FIRED      = "ACTION_TRIGGER_FIRED"
+	EVT_ACTION_EVENT             = "ACTION_EVENT"
 	SMAsterisk                   = "sm_asterisk"
 	DataDB                       = "data_db"
 	StorDB                       = "stor_db"
@@ -412,6 +456,7 @@ const (
 	FormSuffix                   = ".form"
 	CSVSuffix                    = ".csv"
 	FWVSuffix                    = ".fwv"
+	JSONLSuffix                  = ".jsonl"
 	CONTENT_JSON                 = "json"
 	CONTENT_FORM                 = "form"
 	CONTENT_TEXT                 = "text"
@@ -420,6 +465,10 @@ const (
 	CDRPoster                    = "cdr"
 	MetaFileCSV                  = "*file_csv"
 	MetaFileFWV                  = "*file_fwv"
+	MetaFileJSONL                = "*file_jsonl"
+	MetaSQLTable                 = "*sql_table"
+	MetaAccount                  = "*account"
+	MetaCGRID                    = "*cgrid"
 	Accounts                     = "Accounts"
 	AccountService               = "AccountS"
 	Actions                      = "Actions"
@@ -428,6 +477,8 @@ const (
 	SharedGroups                 = "SharedGroups"
 	MetaEveryMinute              = "*every_minute"
 	MetaHourly                   = "*hourly"
+	MetaDaily                    = "*daily"
+	MetaMonthly                  = "*monthly"
 	ID                           = "ID"
 	MetaASR                      = "*asr"
 	MetaACD                      = "*acd"
@@ -436,6 +487,13 @@ const (
 	MetaTCC                      = "*tcc"
 	MetaPDD                      = "*pdd"
 	MetaDDC                      = "*ddc"
+	MetaUsagePercentile          = "*usage_percentile"
+	MetaCostPercentile           = "*cost_percentile"
+	MetaStdDevACD                = "*stddev_acd"
+	MetaStdDevACC                = "*stddev_acc"
+	MetaSum                      = "*sum"
+	MetaDistinct                 = "*distinct"
+	MetaAverage                  = "*average"
 	CacheDestinations            = "destinations"
 	CacheReverseDestinations     = "reverse_destinations"
 	CacheRatingPlans             = "rating_plans"
@@ -490,7 +548,27 @@ const (
 	Disabled                     = "Disabled"
 	Action                       = "Action"
 	ThresholdSv1ProcessEvent     = "ThresholdSv1.ProcessEvent"
+	StatSv1GetQueueFloatMetrics  = "StatSV1.GetQueueFloatMetrics"
+	StatSv1ResetStatQueue        = "StatSV1.ResetStatQueue"
+	ThresholdSv1ResetThreshold   = "ThresholdSV1.ResetThreshold"
+	ResourceSv1GetAvailability   = "ResourceSV1.GetResourceAvailability"
+	AuthV1Authenticate           = "AuthV1.Authenticate"
+	HeaderAPIKey                 = "X-API-Key"
+	ResourceSv1GetUsage          = "ResourceSV1.GetResourceUsage"
+	CacheSv1InvalidateItems      = "CacheSv1.InvalidateItems"
+	ConfigSv1ReloadConfig        = "ConfigSv1.ReloadConfig"
+	CacheSupplierProfiles        = "supplier_profiles"
+	SupplierS                    = "SupplierS"
+	MetaWeight                   = "*weight"
+	MetaLeastCost                = "*least_cost"
+	MetaHighestCost              = "*highest_cost"
+	MetaQOS                      = "*qos"
 	MetaNow                      = "*now"
+	ThresholdS                   = "ThresholdS"
+	SchedulerS                   = "SchedulerS"
+	CacheExchangeRates           = "exchange_rates"
+	CacheAPIKeys                 = "api_keys"
+	CacheAPIRoles                = "api_roles"
 )
 
 func buildCacheInstRevPrefixes() {
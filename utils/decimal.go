@@ -0,0 +1,202 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// Decimal is an arbitrary-precision number used for monetary arithmetic.
+// Floats are imported via their shortest decimal representation so 0.1 is exactly 0.1
+// and summing millions of small amounts does not drift.
+// Decimal values are immutable, operations return new instances.
+//
+// Decimal is used for computing, not for storing: the persisted and wire monetary fields
+// (Balance.Value, CallCost.Cost, EventCost.Cost, CDR.Cost) stay float64 so the JSON/gob
+// formats and the existing DataDB/StorDB contents remain compatible. They are assigned out of
+// a Decimal rounded at rounding_decimals, hence the float holds the nearest value to an exact
+// decimal and no error accumulates from one debit to the next.
+type Decimal struct {
+	r big.Rat
+}
+
+// NewDecimalFromFloat64 imports a float, NaN and Inf are imported as 0
+func NewDecimalFromFloat64(x float64) *Decimal {
+	d := new(Decimal)
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return d
+	}
+	d.r.SetString(strconv.FormatFloat(x, 'f', -1, 64))
+	return d
+}
+
+// NewDecimalFromInt64 imports an integer
+func NewDecimalFromInt64(x int64) *Decimal {
+	d := new(Decimal)
+	d.r.SetInt64(x)
+	return d
+}
+
+// NewDecimalFromDuration imports the number of seconds in a duration
+func NewDecimalFromDuration(dur time.Duration) *Decimal {
+	d := new(Decimal)
+	d.r.SetFrac64(dur.Nanoseconds(), int64(time.Second))
+	return d
+}
+
+// NewDecimalFromString parses decimal representations like "1.2345" or "-1e-3"
+func NewDecimalFromString(s string) (*Decimal, error) {
+	d := new(Decimal)
+	if _, can := d.r.SetString(s); !can {
+		return nil, fmt.Errorf("cannot convert <%s> to decimal", s)
+	}
+	return d, nil
+}
+
+// SumFloat64 adds the floats as decimals
+func SumFloat64(xs ...float64) *Decimal {
+	d := new(Decimal)
+	for _, x := range xs {
+		d.r.Add(&d.r, &NewDecimalFromFloat64(x).r)
+	}
+	return d
+}
+
+// Add returns d+o
+func (d *Decimal) Add(o *Decimal) *Decimal {
+	s := new(Decimal)
+	s.r.Add(&d.r, &o.r)
+	return s
+}
+
+// Sub returns d-o
+func (d *Decimal) Sub(o *Decimal) *Decimal {
+	s := new(Decimal)
+	s.r.Sub(&d.r, &o.r)
+	return s
+}
+
+// Mul returns d*o
+func (d *Decimal) Mul(o *Decimal) *Decimal {
+	p := new(Decimal)
+	p.r.Mul(&d.r, &o.r)
+	return p
+}
+
+// Div returns d/o, the result is exact and only loses precision when rounded or exported
+func (d *Decimal) Div(o *Decimal) *Decimal {
+	q := new(Decimal)
+	if o.r.Sign() == 0 {
+		return q // avoid panics on bad rating data, same as returning no cost
+	}
+	q.r.Quo(&d.r, &o.r)
+	return q
+}
+
+// Neg returns -d
+func (d *Decimal) Neg() *Decimal {
+	n := new(Decimal)
+	n.r.Neg(&d.r)
+	return n
+}
+
+// Cmp compares d and o returning -1, 0 or +1
+func (d *Decimal) Cmp(o *Decimal) int {
+	return d.r.Cmp(&o.r)
+}
+
+// Sign returns -1, 0 or +1 based on the sign of d
+func (d *Decimal) Sign() int {
+	return d.r.Sign()
+}
+
+// Round rounds at prec decimals using one of the ROUNDING_* methods.
+// Methods apply to the absolute value so negative amounts round symmetrically.
+func (d *Decimal) Round(prec int, method string) *Decimal {
+	if prec >= 0 && d.r.IsInt() {
+		return d
+	}
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(prec))), nil)
+	scaled := new(big.Rat).Abs(&d.r)
+	if prec >= 0 {
+		scaled.Mul(scaled, new(big.Rat).SetInt(pow))
+	} else {
+		scaled.Quo(scaled, new(big.Rat).SetInt(pow))
+	}
+	intPart, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		switch method {
+		case ROUNDING_UP:
+			intPart.Add(intPart, big.NewInt(1))
+		case ROUNDING_MIDDLE:
+			if new(big.Int).Lsh(rem, 1).Cmp(scaled.Denom()) >= 0 {
+				intPart.Add(intPart, big.NewInt(1))
+			}
+		case ROUNDING_DOWN:
+		default:
+			return d
+		}
+	}
+	rnd := new(Decimal)
+	rnd.r.SetInt(intPart)
+	if prec >= 0 {
+		rnd.r.Quo(&rnd.r, new(big.Rat).SetInt(pow))
+	} else {
+		rnd.r.Mul(&rnd.r, new(big.Rat).SetInt(pow))
+	}
+	if d.r.Sign() < 0 {
+		rnd.r.Neg(&rnd.r)
+	}
+	return rnd
+}
+
+// Float64 exports the nearest float
+func (d *Decimal) Float64() float64 {
+	f, _ := d.r.Float64()
+	return f
+}
+
+// String returns the shortest decimal representation of the float export
+func (d *Decimal) String() string {
+	return strconv.FormatFloat(d.Float64(), 'f', -1, 64)
+}
+
+// MarshalJSON exports the Decimal as JSON number so it can replace float fields on the wire
+func (d *Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON imports JSON numbers
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if _, can := d.r.SetString(string(data)); !can {
+		return fmt.Errorf("cannot convert <%s> to decimal", string(data))
+	}
+	return nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
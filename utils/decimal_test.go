@@ -0,0 +1,186 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+func TestDecimalNoDrift(t *testing.T) {
+	sum := NewDecimalFromInt64(0)
+	for i := 0; i < 100000; i++ { // summing floats gives 999.9999999992356
+		sum = sum.Add(NewDecimalFromFloat64(0.01))
+	}
+	if sum.Float64() != 1000 {
+		t.Errorf("Expecting: 1000, received: %v", sum.Float64())
+	}
+}
+
+func TestDecimalOperations(t *testing.T) {
+	if rcv := SumFloat64(0.1, 0.2).Float64(); rcv != 0.3 {
+		t.Errorf("Expecting: 0.3, received: %v", rcv)
+	}
+	if rcv := NewDecimalFromFloat64(1.1).Sub(NewDecimalFromFloat64(0.9)).Float64(); rcv != 0.2 {
+		t.Errorf("Expecting: 0.2, received: %v", rcv)
+	}
+	if rcv := NewDecimalFromFloat64(0.1).Mul(NewDecimalFromInt64(3)).Float64(); rcv != 0.3 {
+		t.Errorf("Expecting: 0.3, received: %v", rcv)
+	}
+	// 0.01 per minute for 59 seconds
+	if rcv := NewDecimalFromFloat64(0.01).Mul(NewDecimalFromDuration(59*time.Second)).
+		Div(NewDecimalFromDuration(time.Minute)).Round(4, ROUNDING_UP).Float64(); rcv != 0.0099 {
+		t.Errorf("Expecting: 0.0099, received: %v", rcv)
+	}
+	if rcv := NewDecimalFromInt64(1).Div(NewDecimalFromInt64(0)); rcv.Sign() != 0 {
+		t.Errorf("Expecting: 0, received: %v", rcv)
+	}
+	if rcv := NewDecimalFromFloat64(math.NaN()); rcv.Sign() != 0 {
+		t.Errorf("Expecting: 0, received: %v", rcv)
+	}
+	if _, err := NewDecimalFromString("1,2"); err == nil {
+		t.Error("Expecting error")
+	}
+	if d, err := NewDecimalFromString("-1e-3"); err != nil {
+		t.Error(err)
+	} else if d.Float64() != -0.001 {
+		t.Errorf("Expecting: -0.001, received: %v", d)
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	for _, tc := range []struct {
+		x      float64
+		prec   int
+		method string
+		exp    float64
+	}{
+		{12.49, 1, ROUNDING_UP, 12.5},
+		{12.21, 1, ROUNDING_UP, 12.3},
+		{0.0701, 2, ROUNDING_UP, 0.08},
+		{12.49, 1, ROUNDING_DOWN, 12.4},
+		{12.21, 1, ROUNDING_DOWN, 12.2},
+		{12.45, 1, ROUNDING_MIDDLE, 12.5},
+		{12.44, 1, ROUNDING_MIDDLE, 12.4},
+		{-12.45, 1, ROUNDING_MIDDLE, -12.5},
+		{-12.44, 1, ROUNDING_MIDDLE, -12.4},
+		{-12.41, 1, ROUNDING_UP, -12.5},
+		{1255, -1, ROUNDING_MIDDLE, 1260},
+		{12.3456, 2, "", 12.3456},
+	} {
+		if rcv := NewDecimalFromFloat64(tc.x).Round(tc.prec, tc.method).Float64(); rcv != tc.exp {
+			t.Errorf("Round(%v, %d, %s), expecting: %v, received: %v", tc.x, tc.prec, tc.method, tc.exp, rcv)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	type withDecimal struct {
+		Cost *Decimal
+	}
+	type withFloat struct {
+		Cost float64
+	}
+	b, err := json.Marshal(withDecimal{Cost: SumFloat64(0.1, 0.2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"Cost":0.3}` {
+		t.Errorf("Received: %s", b)
+	}
+	var wf withFloat
+	if err := json.Unmarshal(b, &wf); err != nil {
+		t.Error(err)
+	} else if wf.Cost != 0.3 {
+		t.Errorf("Received: %+v", wf)
+	}
+	var wd withDecimal
+	if err := json.Unmarshal([]byte(`{"Cost":1.2345e-2}`), &wd); err != nil {
+		t.Error(err)
+	} else if wd.Cost.Float64() != 0.012345 {
+		t.Errorf("Received: %v", wd.Cost)
+	}
+}
+
+// testAmount generates small monetary amounts out of random integers
+func testAmount(x int32) float64 {
+	return float64(x) / 10000
+}
+
+func TestDecimalPropertyFloatRoundtrip(t *testing.T) {
+	f := func(x float64) bool {
+		return NewDecimalFromFloat64(x).Float64() == x
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecimalPropertySumAssociative(t *testing.T) {
+	f := func(xs []int32) bool {
+		forward := NewDecimalFromInt64(0)
+		for _, x := range xs {
+			forward = forward.Add(NewDecimalFromFloat64(testAmount(x)))
+		}
+		backward := NewDecimalFromInt64(0)
+		for i := len(xs) - 1; i >= 0; i-- {
+			backward = backward.Add(NewDecimalFromFloat64(testAmount(xs[i])))
+		}
+		return forward.Cmp(backward) == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecimalPropertySubtractAll(t *testing.T) {
+	f := func(start int32, xs []int32) bool {
+		value := NewDecimalFromFloat64(testAmount(start))
+		debited := NewDecimalFromInt64(0)
+		for _, x := range xs {
+			// export after each operation, same as the balances do
+			value = NewDecimalFromFloat64(value.Sub(NewDecimalFromFloat64(testAmount(x))).Float64())
+			debited = debited.Add(NewDecimalFromFloat64(testAmount(x)))
+		}
+		return NewDecimalFromFloat64(testAmount(start)).Sub(value).Cmp(debited) == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecimalPropertyRoundBounds(t *testing.T) {
+	f := func(x int64, prec uint8) bool {
+		d := NewDecimalFromFloat64(float64(x) / 1e6)
+		p := int(prec % 8)
+		up, down := d.Round(p, ROUNDING_UP), d.Round(p, ROUNDING_DOWN)
+		middle := d.Round(p, ROUNDING_MIDDLE)
+		if d.Sign() >= 0 {
+			return down.Cmp(d) <= 0 && d.Cmp(up) <= 0 &&
+				down.Cmp(middle) <= 0 && middle.Cmp(up) <= 0
+		}
+		return up.Cmp(d) <= 0 && d.Cmp(down) <= 0 &&
+			up.Cmp(middle) <= 0 && middle.Cmp(down) <= 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -46,6 +46,12 @@ var (
 	ErrResourceUnavailable     = errors.New("RESOURCE_UNAVAILABLE")
 	ErrNoActiveSession         = errors.New("NO_ACTIVE_SESSION")
 	ErrPartiallyExecuted       = errors.New("PARTIALLY_EXECUTED")
+	ErrNotRefundable           = errors.New("NOT_REFUNDABLE")
+	ErrRefundPending           = errors.New("REFUND_PENDING")
+	ErrUnauthenticated         = errors.New("UNAUTHENTICATED")
+	ErrUnauthorized            = errors.New("UNAUTHORIZED")
+	ErrIdempotencyKeyReused    = errors.New("IDEMPOTENCY_KEY_REUSED")
+	ErrIdempotencyKeyPending   = errors.New("IDEMPOTENCY_KEY_PENDING")
 )
 
 // NewCGRError initialises a new CGRError
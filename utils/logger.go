@@ -24,6 +24,7 @@ import (
 	"log/syslog"
 	"reflect"
 	"runtime"
+	"sync/atomic"
 )
 
 var Logger LoggerInterface
@@ -84,7 +85,7 @@ const (
 
 // Logs to standard output
 type StdLogger struct {
-	logLevel int
+	logLevel int32 // accessed atomically, changes on config reloads
 	syslog   *syslog.Writer
 }
 
@@ -112,12 +113,16 @@ func (sl *StdLogger) GetSyslog() *syslog.Writer {
 
 // SetLogLevel changes the log level
 func (sl *StdLogger) SetLogLevel(level int) {
-	sl.logLevel = level
+	atomic.StoreInt32(&sl.logLevel, int32(level))
+}
+
+func (sl *StdLogger) getLogLevel() int {
+	return int(atomic.LoadInt32(&sl.logLevel))
 }
 
 // Alert logs to syslog with alert level
 func (sl *StdLogger) Alert(m string) (err error) {
-	if sl.logLevel < LOGLEVEL_ALERT {
+	if sl.getLogLevel() < LOGLEVEL_ALERT {
 		return
 	}
 	if sl.syslog != nil {
@@ -130,7 +135,7 @@ func (sl *StdLogger) Alert(m string) (err error) {
 
 // Crit logs to syslog with critical level
 func (sl *StdLogger) Crit(m string) (err error) {
-	if sl.logLevel < LOGLEVEL_CRITICAL {
+	if sl.getLogLevel() < LOGLEVEL_CRITICAL {
 		return
 	}
 	if sl.syslog != nil {
@@ -143,7 +148,7 @@ func (sl *StdLogger) Crit(m string) (err error) {
 
 // Debug logs to syslog with debug level
 func (sl *StdLogger) Debug(m string) (err error) {
-	if sl.logLevel < LOGLEVEL_DEBUG {
+	if sl.getLogLevel() < LOGLEVEL_DEBUG {
 		return
 	}
 	if sl.syslog != nil {
@@ -156,7 +161,7 @@ func (sl *StdLogger) Debug(m string) (err error) {
 
 // Emerg logs to syslog with emergency level
 func (sl *StdLogger) Emerg(m string) (err error) {
-	if sl.logLevel < LOGLEVEL_EMERGENCY {
+	if sl.getLogLevel() < LOGLEVEL_EMERGENCY {
 		return
 	}
 	if sl.syslog != nil {
@@ -169,7 +174,7 @@ func (sl *StdLogger) Emerg(m string) (err error) {
 
 // Err logs to syslog with error level
 func (sl *StdLogger) Err(m string) (err error) {
-	if sl.logLevel < LOGLEVEL_ERROR {
+	if sl.getLogLevel() < LOGLEVEL_ERROR {
 		return
 	}
 	if sl.syslog != nil {
@@ -182,7 +187,7 @@ func (sl *StdLogger) Err(m string) (err error) {
 
 // Info logs to syslog with info level
 func (sl *StdLogger) Info(m string) (err error) {
-	if sl.logLevel < LOGLEVEL_INFO {
+	if sl.getLogLevel() < LOGLEVEL_INFO {
 		return
 	}
 	if sl.syslog != nil {
@@ -195,7 +200,7 @@ func (sl *StdLogger) Info(m string) (err error) {
 
 // Notice logs to syslog with notice level
 func (sl *StdLogger) Notice(m string) (err error) {
-	if sl.logLevel < LOGLEVEL_NOTICE {
+	if sl.getLogLevel() < LOGLEVEL_NOTICE {
 		return
 	}
 	if sl.syslog != nil {
@@ -208,7 +213,7 @@ func (sl *StdLogger) Notice(m string) (err error) {
 
 // Warning logs to syslog with warning level
 func (sl *StdLogger) Warning(m string) (err error) {
-	if sl.logLevel < LOGLEVEL_WARNING {
+	if sl.getLogLevel() < LOGLEVEL_WARNING {
 		return
 	}
 
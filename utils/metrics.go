@@ -0,0 +1,338 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+	"net/rpc"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric types as understood by the Prometheus text format
+const (
+	MetricCounter      = "counter"
+	MetricGauge        = "gauge"
+	MetricHistogram    = "histogram"
+	MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// Metrics is the registry exposed by the engine on the HTTP metrics URL
+var Metrics = NewMetricsRegistry()
+
+// rpcLatencyBuckets are the upper bounds, in seconds, of the RPC latency histogram
+var rpcLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// MetricSample is one value of a metric family, identified by its labels
+type MetricSample struct {
+	Suffix string // appended to the family name, eg: _bucket, _sum, _count
+	Labels map[string]string
+	Value  float64
+}
+
+// labelsString returns the labels in exposition format, sorted by name
+func (ms *MetricSample) labelsString() string {
+	if len(ms.Labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(ms.Labels))
+	for name := range ms.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lbls := make([]string, len(names))
+	for i, name := range names {
+		lbls[i] = fmt.Sprintf("%s=\"%s\"", name, escapeMetricLabel(ms.Labels[name]))
+	}
+	return "{" + strings.Join(lbls, ",") + "}"
+}
+
+// MetricFamily groups the samples sharing the same name, help and type
+type MetricFamily struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []*MetricSample
+}
+
+// MetricsCollector is implemented by the subsystems exposing metrics
+type MetricsCollector interface {
+	Metrics() []*MetricFamily
+}
+
+// MetricsCollectorFunc allows using ordinary functions as MetricsCollector
+type MetricsCollectorFunc func() []*MetricFamily
+
+func (f MetricsCollectorFunc) Metrics() []*MetricFamily {
+	return f()
+}
+
+// rpcMethodStats accounts the requests served for one RPC method
+type rpcMethodStats struct {
+	requests uint64
+	errors   uint64
+	sum      float64  // total seconds spent serving
+	buckets  []uint64 // cumulative counts, indexed as rpcLatencyBuckets
+}
+
+// NewMetricsRegistry constructs a MetricsRegistry
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{
+		collectors: make(map[string]MetricsCollector),
+		rpcStats:   make(map[string]*rpcMethodStats),
+	}
+}
+
+// MetricsRegistry keeps the collectors together with the RPC statistics
+type MetricsRegistry struct {
+	clMux      sync.RWMutex // protects collectors
+	collectors map[string]MetricsCollector
+	rsMux      sync.RWMutex // protects rpcStats
+	rpcStats   map[string]*rpcMethodStats
+}
+
+// RegisterCollector adds a collector, replacing the one already registered under the same name
+func (mr *MetricsRegistry) RegisterCollector(name string, c MetricsCollector) {
+	mr.clMux.Lock()
+	mr.collectors[name] = c
+	mr.clMux.Unlock()
+}
+
+// ObserveRPC records one request served for method
+func (mr *MetricsRegistry) ObserveRPC(method string, duration time.Duration, failed bool) {
+	secs := duration.Seconds()
+	mr.rsMux.Lock()
+	defer mr.rsMux.Unlock()
+	stats, has := mr.rpcStats[method]
+	if !has {
+		stats = &rpcMethodStats{buckets: make([]uint64, len(rpcLatencyBuckets))}
+		mr.rpcStats[method] = stats
+	}
+	stats.requests++
+	if failed {
+		stats.errors++
+	}
+	stats.sum += secs
+	for i, le := range rpcLatencyBuckets {
+		if secs <= le {
+			stats.buckets[i]++
+		}
+	}
+}
+
+// rpcMetrics returns the RPC statistics as metric families
+func (mr *MetricsRegistry) rpcMetrics() []*MetricFamily {
+	reqs := &MetricFamily{Name: "cgr_rpc_requests_total", Type: MetricCounter,
+		Help: "Number of RPC requests served, per method"}
+	errs := &MetricFamily{Name: "cgr_rpc_request_errors_total", Type: MetricCounter,
+		Help: "Number of RPC requests answered with error, per method"}
+	lat := &MetricFamily{Name: "cgr_rpc_request_duration_seconds", Type: MetricHistogram,
+		Help: "Time spent serving RPC requests, per method"}
+	mr.rsMux.RLock()
+	defer mr.rsMux.RUnlock()
+	methods := make([]string, 0, len(mr.rpcStats))
+	for method := range mr.rpcStats {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		stats := mr.rpcStats[method]
+		reqs.Samples = append(reqs.Samples,
+			&MetricSample{Labels: map[string]string{"method": method}, Value: float64(stats.requests)})
+		errs.Samples = append(errs.Samples,
+			&MetricSample{Labels: map[string]string{"method": method}, Value: float64(stats.errors)})
+		for i, le := range rpcLatencyBuckets {
+			lat.Samples = append(lat.Samples, &MetricSample{Suffix: "_bucket",
+				Labels: map[string]string{"method": method, "le": strconv.FormatFloat(le, 'g', -1, 64)},
+				Value:  float64(stats.buckets[i])})
+		}
+		lat.Samples = append(lat.Samples,
+			&MetricSample{Suffix: "_bucket", Labels: map[string]string{"method": method, "le": "+Inf"},
+				Value: float64(stats.requests)},
+			&MetricSample{Suffix: "_sum", Labels: map[string]string{"method": method}, Value: stats.sum},
+			&MetricSample{Suffix: "_count", Labels: map[string]string{"method": method},
+				Value: float64(stats.requests)})
+	}
+	return []*MetricFamily{reqs, errs, lat}
+}
+
+// Gather queries the collectors and merges their families by name, sorted by name
+func (mr *MetricsRegistry) Gather() (mfs []*MetricFamily) {
+	fams := make(map[string]*MetricFamily)
+	var names []string
+	merge := func(mf *MetricFamily) {
+		if len(mf.Samples) == 0 {
+			return
+		}
+		if fam, has := fams[mf.Name]; has {
+			fam.Samples = append(fam.Samples, mf.Samples...)
+			return
+		}
+		fams[mf.Name] = &MetricFamily{Name: mf.Name, Help: mf.Help, Type: mf.Type,
+			Samples: append([]*MetricSample{}, mf.Samples...)}
+		names = append(names, mf.Name)
+	}
+	for _, mf := range mr.rpcMetrics() {
+		merge(mf)
+	}
+	mr.clMux.RLock()
+	collectors := make([]MetricsCollector, 0, len(mr.collectors))
+	for _, c := range mr.collectors {
+		collectors = append(collectors, c)
+	}
+	mr.clMux.RUnlock()
+	for _, c := range collectors { // collectors can be slow, query them unlocked
+		for _, mf := range c.Metrics() {
+			merge(mf)
+		}
+	}
+	sort.Strings(names)
+	mfs = make([]*MetricFamily, len(names))
+	for i, name := range names {
+		mfs[i] = fams[name]
+	}
+	return
+}
+
+// WriteTo writes all the metrics in Prometheus text format
+func (mr *MetricsRegistry) WriteTo(w io.Writer) (n int64, err error) {
+	var buf bytes.Buffer
+	for _, mf := range mr.Gather() {
+		if mf.Help != "" {
+			fmt.Fprintf(&buf, "# HELP %s %s\n", mf.Name, escapeMetricHelp(mf.Help))
+		}
+		if mf.Type != "" {
+			fmt.Fprintf(&buf, "# TYPE %s %s\n", mf.Name, mf.Type)
+		}
+		for _, ms := range mf.Samples {
+			fmt.Fprintf(&buf, "%s%s%s %s\n", mf.Name, ms.Suffix, ms.labelsString(),
+				strconv.FormatFloat(ms.Value, 'g', -1, 64))
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP implements http.Handler so the registry can be scraped
+func (mr *MetricsRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", MetricsContentType)
+	mr.WriteTo(w)
+}
+
+func escapeMetricLabel(val string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(val)
+}
+
+func escapeMetricHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// pendingRPC is a request read but not yet answered
+type pendingRPC struct {
+	method string
+	start  time.Time
+}
+
+// NewMetricsServerCodec wraps c so the requests served through it are recorded in mr
+func NewMetricsServerCodec(c rpc.ServerCodec, mr *MetricsRegistry) rpc.ServerCodec {
+	return &metricsServerCodec{ServerCodec: c, mr: mr, pending: make(map[uint64]*pendingRPC)}
+}
+
+// metricsServerCodec measures the time between reading a request and writing its response
+type metricsServerCodec struct {
+	rpc.ServerCodec
+	mr      *MetricsRegistry
+	pending map[uint64]*pendingRPC
+	pMux    sync.Mutex // protects pending
+}
+
+func (c *metricsServerCodec) ReadRequestHeader(r *rpc.Request) (err error) {
+	if err = c.ServerCodec.ReadRequestHeader(r); err != nil {
+		return
+	}
+	c.pMux.Lock()
+	c.pending[r.Seq] = &pendingRPC{method: r.ServiceMethod, start: time.Now()}
+	c.pMux.Unlock()
+	return
+}
+
+func (c *metricsServerCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	c.pMux.Lock()
+	p, has := c.pending[r.Seq]
+	delete(c.pending, r.Seq)
+	c.pMux.Unlock()
+	// unknown methods are not accounted so clients cannot inflate the number of series
+	if has && !strings.HasPrefix(r.Error, "rpc: can't find") {
+		c.mr.ObserveRPC(p.method, time.Since(p.start), r.Error != "")
+	}
+	return c.ServerCodec.WriteResponse(r, body)
+}
+
+// gobServerCodec mirrors the one used by net/rpc.ServeConn, unexported there
+type gobServerCodec struct {
+	rwc    io.ReadWriteCloser
+	dec    *gob.Decoder
+	enc    *gob.Encoder
+	encBuf *bufio.Writer
+	closed bool
+}
+
+func newGobServerCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
+	buf := bufio.NewWriter(conn)
+	return &gobServerCodec{rwc: conn, dec: gob.NewDecoder(conn), enc: gob.NewEncoder(buf), encBuf: buf}
+}
+
+func (c *gobServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	return c.dec.Decode(r)
+}
+
+func (c *gobServerCodec) ReadRequestBody(body interface{}) error {
+	return c.dec.Decode(body)
+}
+
+func (c *gobServerCodec) WriteResponse(r *rpc.Response, body interface{}) (err error) {
+	if err = c.enc.Encode(r); err != nil {
+		if c.encBuf.Flush() == nil { // gob couldn't encode the header, shut down
+			c.Close()
+		}
+		return
+	}
+	if err = c.enc.Encode(body); err != nil {
+		if c.encBuf.Flush() == nil { // was a gob problem encoding the body but the header has been written
+			c.Close()
+		}
+		return
+	}
+	return c.encBuf.Flush()
+}
+
+func (c *gobServerCodec) Close() error {
+	if c.closed { // only close the connection once
+		return nil
+	}
+	c.closed = true
+	return c.rwc.Close()
+}
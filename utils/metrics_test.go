@@ -0,0 +1,126 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsWriteTo(t *testing.T) {
+	mr := NewMetricsRegistry()
+	mr.RegisterCollector("test", MetricsCollectorFunc(func() []*MetricFamily {
+		return []*MetricFamily{
+			&MetricFamily{Name: "cgr_test_value", Type: MetricGauge, Help: "Test\nvalue",
+				Samples: []*MetricSample{
+					&MetricSample{Labels: map[string]string{"tenant": "cgrates.org", "id": `TH"1`}, Value: 2.5},
+				}},
+			&MetricFamily{Name: "cgr_test_empty", Type: MetricGauge}, // families without samples are skipped
+		}
+	}))
+	mr.ObserveRPC("ApierV1.Ping", 2*time.Millisecond, false)
+	mr.ObserveRPC("ApierV1.Ping", 2*time.Second, true)
+	var buf bytes.Buffer
+	if _, err := mr.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	eOut := `# HELP cgr_rpc_request_duration_seconds Time spent serving RPC requests, per method
+# TYPE cgr_rpc_request_duration_seconds histogram
+cgr_rpc_request_duration_seconds_bucket{le="0.001",method="ApierV1.Ping"} 0
+cgr_rpc_request_duration_seconds_bucket{le="0.005",method="ApierV1.Ping"} 1
+cgr_rpc_request_duration_seconds_bucket{le="0.01",method="ApierV1.Ping"} 1
+cgr_rpc_request_duration_seconds_bucket{le="0.05",method="ApierV1.Ping"} 1
+cgr_rpc_request_duration_seconds_bucket{le="0.1",method="ApierV1.Ping"} 1
+cgr_rpc_request_duration_seconds_bucket{le="0.5",method="ApierV1.Ping"} 1
+cgr_rpc_request_duration_seconds_bucket{le="1",method="ApierV1.Ping"} 1
+cgr_rpc_request_duration_seconds_bucket{le="5",method="ApierV1.Ping"} 2
+cgr_rpc_request_duration_seconds_bucket{le="+Inf",method="ApierV1.Ping"} 2
+cgr_rpc_request_duration_seconds_sum{method="ApierV1.Ping"} 2.002
+cgr_rpc_request_duration_seconds_count{method="ApierV1.Ping"} 2
+# HELP cgr_rpc_request_errors_total Number of RPC requests answered with error, per method
+# TYPE cgr_rpc_request_errors_total counter
+cgr_rpc_request_errors_total{method="ApierV1.Ping"} 1
+# HELP cgr_rpc_requests_total Number of RPC requests served, per method
+# TYPE cgr_rpc_requests_total counter
+cgr_rpc_requests_total{method="ApierV1.Ping"} 2
+# HELP cgr_test_value Test\nvalue
+# TYPE cgr_test_value gauge
+cgr_test_value{id="TH\"1",tenant="cgrates.org"} 2.5
+`
+	if buf.String() != eOut {
+		t.Errorf("Expecting:\n%s\nreceived:\n%s", eOut, buf.String())
+	}
+}
+
+type TestMetricsRPC struct{}
+
+func (TestMetricsRPC) Echo(arg string, reply *string) error {
+	*reply = arg
+	return nil
+}
+
+func (TestMetricsRPC) Fail(arg string, reply *string) error {
+	return errors.New("FAILED")
+}
+
+func TestMetricsServerCodec(t *testing.T) {
+	srv := rpc.NewServer()
+	if err := srv.Register(TestMetricsRPC{}); err != nil {
+		t.Fatal(err)
+	}
+	mr := NewMetricsRegistry()
+	srvConn, clntConn := net.Pipe()
+	go srv.ServeCodec(NewMetricsServerCodec(jsonrpc.NewServerCodec(srvConn), mr))
+	clnt := jsonrpc.NewClient(clntConn)
+	defer clnt.Close()
+	var reply string
+	for i := 0; i < 3; i++ {
+		if err := clnt.Call("TestMetricsRPC.Echo", "test", &reply); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := clnt.Call("TestMetricsRPC.Fail", "test", &reply); err == nil {
+		t.Error("Expecting error")
+	}
+	if err := clnt.Call("TestMetricsRPC.Unknown", "test", &reply); err == nil {
+		t.Error("Expecting error")
+	}
+	var buf bytes.Buffer
+	mr.WriteTo(&buf)
+	out := buf.String()
+	for _, line := range []string{
+		`cgr_rpc_requests_total{method="TestMetricsRPC.Echo"} 3`,
+		`cgr_rpc_request_errors_total{method="TestMetricsRPC.Echo"} 0`,
+		`cgr_rpc_requests_total{method="TestMetricsRPC.Fail"} 1`,
+		`cgr_rpc_request_errors_total{method="TestMetricsRPC.Fail"} 1`,
+		`cgr_rpc_request_duration_seconds_count{method="TestMetricsRPC.Echo"} 3`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Missing <%s> in:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "TestMetricsRPC.Unknown") {
+		t.Errorf("Unknown method accounted:\n%s", out)
+	}
+}
@@ -79,7 +79,7 @@ func NewFallbackFileNameFronString(fileName string) (ffn *FallbackFileName, err
 		return nil, fmt.Errorf("unsupported module: %s", ffn.Module)
 	}
 	fileNameWithoutModule := fileName[moduleIdx+1:]
-	for _, trspt := range []string{MetaHTTPjsonCDR, MetaHTTPjsonMap, MetaHTTPjson, META_HTTP_POST, MetaAMQPjsonCDR, MetaAMQPjsonMap} {
+	for _, trspt := range []string{MetaHTTPjsonCDR, MetaHTTPjsonMap, MetaHTTPjson, META_HTTP_POST, MetaAMQPjsonCDR, MetaAMQPjsonMap, MetaFileJSONL, MetaSQLTable} {
 		if strings.HasPrefix(fileNameWithoutModule, trspt) {
 			ffn.Transport = trspt
 			break
@@ -97,7 +97,7 @@ func NewFallbackFileNameFronString(fileName string) (ffn *FallbackFileName, err
 		return nil, err
 	}
 	fileNameWithoutAddress := fileNameWithoutTransport[reqIDidx+1:]
-	for _, suffix := range []string{TxtSuffix, JSNSuffix, FormSuffix} {
+	for _, suffix := range []string{TxtSuffix, JSNSuffix, JSONLSuffix, FormSuffix} {
 		if strings.HasSuffix(fileNameWithoutAddress, suffix) {
 			ffn.FileSuffix = suffix
 			break
@@ -137,6 +137,13 @@ type HTTPPoster struct {
 	httpClient *http.Client
 }
 
+// SetClientTLSConfig presents the client certificate to the servers and verifies them against the CA within tlsCfg
+func (poster *HTTPPoster) SetClientTLSConfig(tlsCfg *tls.Config) {
+	tr := poster.httpClient.Transport.(*http.Transport)
+	tlsCfg.InsecureSkipVerify = tr.TLSClientConfig.InsecureSkipVerify
+	tr.TLSClientConfig = tlsCfg
+}
+
 // Post with built-in failover
 // Returns also reference towards client so we can close it's connections when done
 func (poster *HTTPPoster) Post(addr string, contentType string, content interface{}, attempts int, fallbackFilePath string) (respBody []byte, err error) {
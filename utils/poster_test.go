@@ -56,6 +56,17 @@ func TestFFNNewFallbackFileNameFronString(t *testing.T) {
 	} else if !reflect.DeepEqual(eFFN, ffn) {
 		t.Errorf("Expecting: %+v, received: %+v", eFFN, ffn)
 	}
+	fileName = "cdr|*file_jsonl|%2Fvar%2Fspool%2Fcgrates%2Fcdre%2Fcdrs.jsonl|0b3e8b6e-9f3c-4f5d-bb31-6a4b2ccff1a7.jsonl"
+	eFFN = &FallbackFileName{Module: "cdr",
+		Transport:  MetaFileJSONL,
+		Address:    "/var/spool/cgrates/cdre/cdrs.jsonl",
+		RequestID:  "0b3e8b6e-9f3c-4f5d-bb31-6a4b2ccff1a7",
+		FileSuffix: JSONLSuffix}
+	if ffn, err := NewFallbackFileNameFronString(fileName); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(eFFN, ffn) {
+		t.Errorf("Expecting: %+v, received: %+v", eFFN, ffn)
+	}
 }
 
 func TestFFNFallbackFileNameAsString(t *testing.T) {
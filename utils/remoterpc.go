@@ -0,0 +1,167 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package utils
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+	"time"
+
+	"github.com/cgrates/rpcclient"
+)
+
+// NewRemoteRPCClient returns a RPC connection towards another engine, codec being one of <json|gob>.
+// The connection is encrypted with tlsCfg if not nil and authenticated with apiKey if not empty.
+// Without lazyConnect the connection is established before returning, otherwise on first call.
+func NewRemoteRPCClient(addr, codec string, tlsCfg *tls.Config, apiKey string, connectAttempts, reconnects int,
+	connectTimeout, replyTimeout time.Duration, lazyConnect bool) (rmtClnt *RemoteRPCClient, err error) {
+	if codec != JSON && codec != GOB {
+		return nil, rpcclient.ErrUnsupportedCodec
+	}
+	rmtClnt = &RemoteRPCClient{addr: addr, codec: codec, tlsCfg: tlsCfg, apiKey: apiKey,
+		reconnects: reconnects, connectTimeout: connectTimeout, replyTimeout: replyTimeout}
+	if lazyConnect {
+		return
+	}
+	err = rmtClnt.connect(nil, connectAttempts)
+	return
+}
+
+// RemoteRPCClient is a RPC connection towards another engine, reconnecting once the connection is lost.
+// Unlike the rpcclient ones, it can go over TLS, be authenticated by API key and be closed when the pools are rebuilt.
+// Errors are the ones of rpcclient so the pools fail over the same way
+type RemoteRPCClient struct {
+	addr           string
+	codec          string
+	tlsCfg         *tls.Config
+	apiKey         string // presented on every connect since the server authenticates per connection
+	reconnects     int    // -1 for infinite
+	connectTimeout time.Duration
+	replyTimeout   time.Duration // 0 to wait for replies indefinitely
+	connMux        sync.RWMutex
+	connection     *rpc.Client
+	closed         bool // no reconnects after Close
+}
+
+// connect replaces the stale connection, trying maximum attempts times (-1 for infinite).
+// Concurrent callers reconnect only once since the others will not find their stale connection anymore
+func (rmtClnt *RemoteRPCClient) connect(stale *rpc.Client, attempts int) (err error) {
+	rmtClnt.connMux.Lock()
+	defer rmtClnt.connMux.Unlock()
+	if rmtClnt.connection != stale {
+		return
+	}
+	if rmtClnt.closed {
+		return rpcclient.ErrDisconnected
+	}
+	if stale != nil {
+		stale.Close()
+		rmtClnt.connection = nil
+	}
+	if attempts == 0 {
+		attempts = 1
+	}
+	dialer := &net.Dialer{Timeout: rmtClnt.connectTimeout}
+	for i := 0; attempts == -1 || i < attempts; i++ {
+		if i != 0 {
+			time.Sleep(time.Duration(i) * time.Second)
+		}
+		var conn net.Conn
+		if rmtClnt.tlsCfg != nil {
+			conn, err = tls.DialWithDialer(dialer, "tcp", rmtClnt.addr, rmtClnt.tlsCfg)
+		} else {
+			conn, err = dialer.Dial("tcp", rmtClnt.addr)
+		}
+		if err != nil {
+			continue
+		}
+		var clnt *rpc.Client
+		if rmtClnt.codec == JSON {
+			clnt = jsonrpc.NewClient(conn)
+		} else {
+			clnt = rpc.NewClient(conn)
+		}
+		if rmtClnt.apiKey != "" {
+			var reply string
+			if err = rmtClnt.call(clnt, AuthV1Authenticate, rmtClnt.apiKey, &reply); err != nil {
+				clnt.Close()
+				return // denied keys will not pass on retries either
+			}
+		}
+		rmtClnt.connection = clnt
+		return nil
+	}
+	return
+}
+
+// call waits for the reply on clnt maximum replyTimeout
+func (rmtClnt *RemoteRPCClient) call(clnt *rpc.Client, serviceMethod string, args interface{}, reply interface{}) error {
+	call := clnt.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	var timeout <-chan time.Time
+	if rmtClnt.replyTimeout != 0 {
+		timeout = time.After(rmtClnt.replyTimeout)
+	}
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-timeout:
+		return rpcclient.ErrReplyTimeout
+	}
+}
+
+// Call implements rpcclient.RpcClientConnection interface
+func (rmtClnt *RemoteRPCClient) Call(serviceMethod string, args interface{}, reply interface{}) (err error) {
+	for i := 0; i < 2; i++ { // second pass only after reconnect
+		rmtClnt.connMux.RLock()
+		conn := rmtClnt.connection
+		rmtClnt.connMux.RUnlock()
+		if conn == nil {
+			if errConn := rmtClnt.connect(nil, rmtClnt.reconnects); errConn != nil {
+				return rpcclient.ErrDisconnected
+			}
+			continue
+		}
+		if err = rmtClnt.call(conn, serviceMethod, args, reply); err == rpcclient.ErrReplyTimeout {
+			return
+		}
+		if err != rpc.ErrShutdown && err != io.ErrUnexpectedEOF {
+			return
+		}
+		if errConn := rmtClnt.connect(conn, rmtClnt.reconnects); errConn != nil {
+			return rpcclient.ErrDisconnected
+		}
+	}
+	return
+}
+
+// Close drops the connection, calls in progress or following fail with rpcclient.ErrDisconnected
+func (rmtClnt *RemoteRPCClient) Close() (err error) {
+	rmtClnt.connMux.Lock()
+	defer rmtClnt.connMux.Unlock()
+	rmtClnt.closed = true
+	if rmtClnt.connection != nil {
+		err = rmtClnt.connection.Close()
+		rmtClnt.connection = nil
+	}
+	return
+}
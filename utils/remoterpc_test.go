@@ -0,0 +1,109 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package utils
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cgrates/rpcclient"
+)
+
+func TestRemoteRPCClientErrors(t *testing.T) {
+	if _, err := NewRemoteRPCClient("127.0.0.1:0", "xml", nil, "", 1, 0, time.Second, time.Second, true); err != rpcclient.ErrUnsupportedCodec {
+		t.Errorf("expecting: %v, received: %v", rpcclient.ErrUnsupportedCodec, err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	clnt, err := NewRemoteRPCClient(addr, JSON, nil, "", 1, 0, time.Second, time.Second, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply string
+	if err := clnt.Call("AuthV1.Ping", "", &reply); err != rpcclient.ErrDisconnected { // the pools fail over on it
+		t.Errorf("expecting: %v, received: %v", rpcclient.ErrDisconnected, err)
+	}
+}
+
+func TestRemoteRPCClientAPIKey(t *testing.T) {
+	SetRPCAuthorizer(testAuthorizer{})
+	defer SetRPCAuthorizer(nil)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	rpcSrv := rpc.NewServer()
+	rpcSrv.RegisterName("AuthV1", new(AuthTestV1))
+	var srvConns []net.Conn
+	var srvConnsMux sync.Mutex
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			srvConnsMux.Lock()
+			srvConns = append(srvConns, conn)
+			srvConnsMux.Unlock()
+			go rpcSrv.ServeCodec(newAuthServerCodec(jsonrpc.NewServerCodec(conn), conn))
+		}
+	}()
+	if _, err := NewRemoteRPCClient(l.Addr().String(), JSON, nil, "wrong", 1, 0, time.Second, time.Second, false); err == nil ||
+		err.Error() != ErrUnauthenticated.Error() {
+		t.Errorf("expecting: %v, received: %v", ErrUnauthenticated, err)
+	}
+	clnt, err := NewRemoteRPCClient(l.Addr().String(), JSON, nil, "secret", 1, 1, time.Second, time.Second, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply string
+	if err := clnt.Call("AuthV1.Ping", "", &reply); err != nil {
+		t.Error(err)
+	} else if reply != OK {
+		t.Errorf("received: %s", reply)
+	}
+	// the server drops the connection, the reconnect authenticates again
+	srvConnsMux.Lock()
+	for _, conn := range srvConns {
+		conn.Close()
+	}
+	srvConnsMux.Unlock()
+	reply = ""
+	if err := clnt.Call("AuthV1.Ping", "", &reply); err != nil {
+		t.Error(err)
+	} else if reply != OK {
+		t.Errorf("received: %s", reply)
+	}
+	// closed by the pools being rebuilt, no reconnects anymore
+	if err := clnt.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := clnt.Call("AuthV1.Ping", "", &reply); err != rpcclient.ErrDisconnected {
+		t.Errorf("expecting: %v, received: %v", rpcclient.ErrDisconnected, err)
+	}
+}
@@ -20,6 +20,7 @@ package utils
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log"
@@ -28,6 +29,7 @@ import (
 	"net/rpc"
 	"net/rpc/jsonrpc"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/cenk/rpc2"
@@ -37,9 +39,11 @@ import (
 )
 
 type Server struct {
-	rpcEnabled  bool
-	httpEnabled bool
-	birpcSrv    *rpc2.Server
+	rpcEnabled             bool
+	httpEnabled            bool
+	birpcSrv               *rpc2.Server
+	httpMux                sync.Mutex // protects the handlers registration
+	httpHandlersRegistered bool
 }
 
 func (s *Server) RpcRegister(rcvr interface{}) {
@@ -87,27 +91,20 @@ func (s *Server) ServeJSON(addr string) {
 		log.Fatal("ServeJSON listen error:", e)
 	}
 	Logger.Info(fmt.Sprintf("Starting CGRateS JSON server at <%s>.", addr))
-	errCnt := 0
-	var lastErrorTime time.Time
-	for {
-		conn, err := lJSON.Accept()
-		if err != nil {
-			Logger.Err(fmt.Sprintf("<CGRServer> JSON accept error: <%s>", err.Error()))
-			now := time.Now()
-			if now.Sub(lastErrorTime) > time.Duration(5*time.Second) {
-				errCnt = 0 // reset error count if last error was more than 5 seconds ago
-			}
-			lastErrorTime = time.Now()
-			errCnt += 1
-			if errCnt > 50 { // Too many errors in short interval, network buffer failure most probably
-				break
-			}
-			continue
-		}
-		//utils.Logger.Info(fmt.Sprintf("<CGRServer> New incoming connection: %v", conn.RemoteAddr()))
-		go jsonrpc.ServeConn(conn)
-	}
+	acceptRPC(lJSON, "JSON", serveJSONConn)
+}
 
+// ServeJSONTLS serves JSON-RPC over TLS connections
+func (s *Server) ServeJSONTLS(addr string, tlsCfg *tls.Config) {
+	if !s.rpcEnabled {
+		return
+	}
+	lJSON, e := tls.Listen("tcp", addr, tlsCfg)
+	if e != nil {
+		log.Fatal("ServeJSONTLS listen error:", e)
+	}
+	Logger.Info(fmt.Sprintf("Starting CGRateS JSON TLS server at <%s>.", addr))
+	acceptRPC(lJSON, "JSON TLS", serveJSONConn)
 }
 
 func (s *Server) ServeGOB(addr string) {
@@ -119,12 +116,31 @@ func (s *Server) ServeGOB(addr string) {
 		log.Fatal("ServeGOB listen error:", e)
 	}
 	Logger.Info(fmt.Sprintf("Starting CGRateS GOB server at <%s>.", addr))
+	acceptRPC(lGOB, "GOB", serveGOBConn)
+}
+
+// ServeGOBTLS serves GOB-RPC over TLS connections
+func (s *Server) ServeGOBTLS(addr string, tlsCfg *tls.Config) {
+	if !s.rpcEnabled {
+		return
+	}
+	lGOB, e := tls.Listen("tcp", addr, tlsCfg)
+	if e != nil {
+		log.Fatal("ServeGOBTLS listen error:", e)
+	}
+	Logger.Info(fmt.Sprintf("Starting CGRateS GOB TLS server at <%s>.", addr))
+	acceptRPC(lGOB, "GOB TLS", serveGOBConn)
+}
+
+// acceptRPC serves the connections accepted by the listener,
+// giving up on too many accept errors within a short interval
+func acceptRPC(l net.Listener, codecName string, serveConn func(io.ReadWriteCloser)) {
 	errCnt := 0
 	var lastErrorTime time.Time
 	for {
-		conn, err := lGOB.Accept()
+		conn, err := l.Accept()
 		if err != nil {
-			Logger.Err(fmt.Sprintf("<CGRServer> GOB accept error: <%s>", err.Error()))
+			Logger.Err(fmt.Sprintf("<CGRServer> %s accept error: <%s>", codecName, err.Error()))
 			now := time.Now()
 			if now.Sub(lastErrorTime) > time.Duration(5*time.Second) {
 				errCnt = 0 // reset error count if last error was more than 5 seconds ago
@@ -136,20 +152,63 @@ func (s *Server) ServeGOB(addr string) {
 			}
 			continue
 		}
-
 		//utils.Logger.Info(fmt.Sprintf("<CGRServer> New incoming connection: %v", conn.RemoteAddr()))
-		go rpc.ServeConn(conn)
+		go serveConn(conn)
 	}
 }
 
+// serveJSONConn serves JSON-RPC over conn, accounting the requests in Metrics
+func serveJSONConn(conn io.ReadWriteCloser) {
+	rpc.ServeCodec(NewMetricsServerCodec(newAuthServerCodec(jsonrpc.NewServerCodec(conn), conn), Metrics))
+}
+
+// serveGOBConn serves GOB-RPC over conn, accounting the requests in Metrics
+func serveGOBConn(conn io.ReadWriteCloser) {
+	rpc.ServeCodec(NewMetricsServerCodec(newAuthServerCodec(newGobServerCodec(conn), conn), Metrics))
+}
+
 func handleRequest(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	w.Header().Set("Content-Type", "application/json")
-	res := NewRPCRequest(r.Body).Call()
+	rpcReq := NewRPCRequest(r.Body)
+	rpcReq.key, rpcReq.addr = r.Header.Get(HeaderAPIKey), r.RemoteAddr
+	res := rpcReq.Call()
 	io.Copy(w, res)
 }
 
-func (s *Server) ServeHTTP(addr string, jsonRPCURL string, wsRPCURL string, useBasicAuth bool, userList map[string]string) {
+func (s *Server) ServeHTTP(addr string, jsonRPCURL string, wsRPCURL string, metricsURL string,
+	useBasicAuth bool, userList map[string]string) {
+	s.registerHTTPHandlers(jsonRPCURL, wsRPCURL, metricsURL, useBasicAuth, userList)
+	if !s.httpEnabled {
+		return
+	}
+	Logger.Info(fmt.Sprintf("<HTTP> start listening at <%s>", addr))
+	http.ListenAndServe(addr, nil)
+}
+
+// ServeHTTPTLS serves the same handlers as ServeHTTP over HTTPS
+func (s *Server) ServeHTTPTLS(addr string, tlsCfg *tls.Config, jsonRPCURL string, wsRPCURL string, metricsURL string,
+	useBasicAuth bool, userList map[string]string) {
+	s.registerHTTPHandlers(jsonRPCURL, wsRPCURL, metricsURL, useBasicAuth, userList)
+	if !s.httpEnabled {
+		return
+	}
+	Logger.Info(fmt.Sprintf("<HTTP> start listening TLS at <%s>", addr))
+	srv := &http.Server{Addr: addr, TLSConfig: tlsCfg}
+	if err := srv.ListenAndServeTLS("", ""); err != nil { // certificates are part of the TLSConfig
+		log.Fatal("ServeHTTPTLS listen error:", err)
+	}
+}
+
+// registerHTTPHandlers registers the handlers once, shared by the HTTP and HTTPS listeners
+func (s *Server) registerHTTPHandlers(jsonRPCURL string, wsRPCURL string, metricsURL string,
+	useBasicAuth bool, userList map[string]string) {
+	s.httpMux.Lock()
+	defer s.httpMux.Unlock()
+	if s.httpHandlersRegistered {
+		return
+	}
+	s.httpHandlersRegistered = true
 	if s.rpcEnabled && jsonRPCURL != "" {
 		s.httpEnabled = true
 		Logger.Info("<HTTP> enabling handler for JSON-RPC")
@@ -164,7 +223,8 @@ func (s *Server) ServeHTTP(addr string, jsonRPCURL string, wsRPCURL string, useB
 		s.httpEnabled = true
 		Logger.Info("<HTTP> enabling handler for WebSocket connections")
 		wsHandler := websocket.Handler(func(ws *websocket.Conn) {
-			jsonrpc.ServeConn(ws)
+			serveJSONConn(&identifiedConn{ReadWriteCloser: ws,
+				key: ws.Request().Header.Get(HeaderAPIKey), addr: ws.Request().RemoteAddr})
 		})
 		if useBasicAuth {
 			http.HandleFunc(wsRPCURL, use(func(w http.ResponseWriter, r *http.Request) {
@@ -175,14 +235,18 @@ func (s *Server) ServeHTTP(addr string, jsonRPCURL string, wsRPCURL string, useB
 		}
 	}
 
-	if !s.httpEnabled {
-		return
+	if metricsURL != "" {
+		s.httpEnabled = true
+		Logger.Info("<HTTP> enabling handler for metrics")
+		if useBasicAuth {
+			http.HandleFunc(metricsURL, use(Metrics.ServeHTTP, basicAuth(userList)))
+		} else {
+			http.Handle(metricsURL, Metrics)
+		}
 	}
-	if useBasicAuth {
+	if s.httpEnabled && useBasicAuth {
 		Logger.Info("<HTTP> enabling basic auth")
 	}
-	Logger.Info(fmt.Sprintf("<HTTP> start listening at <%s>", addr))
-	http.ListenAndServe(addr, nil)
 }
 
 func (s *Server) ServeBiJSON(addr string) {
@@ -194,12 +258,29 @@ func (s *Server) ServeBiJSON(addr string) {
 		log.Fatal("ServeBiJSON listen error:", e)
 	}
 	Logger.Info(fmt.Sprintf("Starting CGRateS BiJSON server at <%s>", addr))
+	s.acceptBiJSON(lBiJSON)
+}
+
+// ServeBiJSONTLS serves the bidirectional JSON-RPC over TLS connections
+func (s *Server) ServeBiJSONTLS(addr string, tlsCfg *tls.Config) {
+	if s.birpcSrv == nil {
+		return
+	}
+	lBiJSON, e := tls.Listen("tcp", addr, tlsCfg)
+	if e != nil {
+		log.Fatal("ServeBiJSONTLS listen error:", e)
+	}
+	Logger.Info(fmt.Sprintf("Starting CGRateS BiJSON TLS server at <%s>", addr))
+	s.acceptBiJSON(lBiJSON)
+}
+
+func (s *Server) acceptBiJSON(l net.Listener) {
 	for {
-		conn, err := lBiJSON.Accept()
+		conn, err := l.Accept()
 		if err != nil {
 			log.Fatal(err)
 		}
-		go s.birpcSrv.ServeCodec(rpc2_jsonrpc.NewJSONCodec(conn))
+		go s.birpcSrv.ServeCodec(newAuthBiRPCCodec(rpc2_jsonrpc.NewJSONCodec(conn), conn))
 	}
 }
 
@@ -209,13 +290,15 @@ type rpcRequest struct {
 	r    io.Reader     // holds the JSON formated RPC request
 	rw   io.ReadWriter // holds the JSON formated RPC response
 	done chan bool     // signals then end of the RPC request
+	key  string        // API key out of the HTTP headers
+	addr string        // remote address of the HTTP client
 }
 
 // NewRPCRequest returns a new rpcRequest.
 func NewRPCRequest(r io.Reader) *rpcRequest {
 	var buf bytes.Buffer
 	done := make(chan bool)
-	return &rpcRequest{r, &buf, done}
+	return &rpcRequest{r: r, rw: &buf, done: done}
 }
 
 func (r *rpcRequest) Read(p []byte) (n int, err error) {
@@ -228,6 +311,14 @@ func (r *rpcRequest) Write(p []byte) (n int, err error) {
 	return
 }
 
+func (r *rpcRequest) apiKey() string {
+	return r.key
+}
+
+func (r *rpcRequest) remoteAddr() string {
+	return r.addr
+}
+
 func (r *rpcRequest) Close() error {
 	//r.done <- true // seem to be called sometimes before the write command finishes!
 	return nil
@@ -235,7 +326,7 @@ func (r *rpcRequest) Close() error {
 
 // Call invokes the RPC request, waits for it to complete, and returns the results.
 func (r *rpcRequest) Call() io.Reader {
-	go jsonrpc.ServeConn(r)
+	go serveJSONConn(r)
 	<-r.done
 	return r.rw
 }
@@ -0,0 +1,97 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSClientAuthTypes maps the client certificate verification modes of the listeners
+var TLSClientAuthTypes = map[string]tls.ClientAuthType{
+	META_NONE:                   tls.NoClientCert,
+	MetaRequestClientCert:       tls.RequestClientCert,
+	MetaRequireAnyClientCert:    tls.RequireAnyClientCert,
+	MetaVerifyClientCertIfGiven: tls.VerifyClientCertIfGiven,
+	MetaRequireAndVerifyCert:    tls.RequireAndVerifyClientCert,
+}
+
+// loadCertPool reads the PEM encoded CA certificates out of caCertificate
+func loadCertPool(caCertificate string) (*x509.CertPool, error) {
+	caPEM, err := ioutil.ReadFile(caCertificate)
+	if err != nil {
+		return nil, err
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in <%s>", caCertificate)
+	}
+	return certPool, nil
+}
+
+// NewServerTLSConfig builds the TLS configuration of the listeners.
+// caCertificate is used to verify the client certificates as requested by clientAuth.
+func NewServerTLSConfig(certificate, key, caCertificate, clientAuth string) (*tls.Config, error) {
+	if certificate == "" || key == "" {
+		return nil, errors.New("missing TLS certificate or key")
+	}
+	cert, err := tls.LoadX509KeyPair(certificate, key)
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if clientAuth != "" {
+		authType, has := TLSClientAuthTypes[clientAuth]
+		if !has {
+			return nil, fmt.Errorf("unsupported client auth: <%s>", clientAuth)
+		}
+		tlsCfg.ClientAuth = authType
+	}
+	if caCertificate != "" {
+		if tlsCfg.ClientCAs, err = loadCertPool(caCertificate); err != nil {
+			return nil, err
+		}
+	} else if tlsCfg.ClientAuth == tls.VerifyClientCertIfGiven ||
+		tlsCfg.ClientAuth == tls.RequireAndVerifyClientCert {
+		return nil, errors.New("missing CA certificate to verify the clients")
+	}
+	return tlsCfg, nil
+}
+
+// NewClientTLSConfig builds the TLS configuration of the outgoing connections.
+// The certificate is presented to the servers asking for it, caCertificate verifies the servers instead of the system pool.
+func NewClientTLSConfig(certificate, key, caCertificate string) (tlsCfg *tls.Config, err error) {
+	tlsCfg = new(tls.Config)
+	if certificate != "" || key != "" {
+		cert, err := tls.LoadX509KeyPair(certificate, key)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	if caCertificate != "" {
+		if tlsCfg.RootCAs, err = loadCertPool(caCertificate); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
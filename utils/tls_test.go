@@ -0,0 +1,188 @@
+/*
+Real-time Online/Offline Charging System (OCS) for Telecom & ISP environments
+Copyright (C) ITsysCOM GmbH
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>
+*/
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// writeTestCert signs a certificate for the usage with parent (self-signed if nil), writing it together with its key into dir
+func writeTestCert(t *testing.T, dir, name string, tmpl *x509.Certificate,
+	parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(dir, name+".crt"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(dir, name+".key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// writeTestPKI creates a CA together with the server and client certificates signed by it
+func writeTestPKI(t *testing.T, dir string) {
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(24 * time.Hour)
+	ca, caKey := writeTestCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "CGRateS Test CA"},
+		NotBefore: notBefore, NotAfter: notAfter, IsCA: true, BasicConstraintsValid: true,
+		KeyUsage: x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature}, nil, nil)
+	writeTestCert(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore: notBefore, NotAfter: notAfter, IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage: x509.KeyUsageDigitalSignature, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, ca, caKey)
+	writeTestCert(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3), Subject: pkix.Name{CommonName: "cgr-engine"},
+		NotBefore: notBefore, NotAfter: notAfter,
+		KeyUsage: x509.KeyUsageDigitalSignature, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}, ca, caKey)
+}
+
+type TLSTestV1 struct{}
+
+func (TLSTestV1) Ping(ign string, reply *string) error {
+	*reply = OK
+	return nil
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgr_tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestPKI(t, dir)
+	crt, key, ca := path.Join(dir, "server.crt"), path.Join(dir, "server.key"), path.Join(dir, "ca.crt")
+	if _, err := NewServerTLSConfig(crt, "", ca, META_NONE); err == nil {
+		t.Error("expecting error for missing key")
+	}
+	if _, err := NewServerTLSConfig(crt, key, ca, "*always"); err == nil {
+		t.Error("expecting error for unsupported client auth")
+	}
+	if _, err := NewServerTLSConfig(crt, key, "", MetaRequireAndVerifyCert); err == nil {
+		t.Error("expecting error for missing CA")
+	}
+	if _, err := NewServerTLSConfig(crt, key, key, MetaRequireAndVerifyCert); err == nil {
+		t.Error("expecting error for CA without certificates")
+	}
+	if tlsCfg, err := NewServerTLSConfig(crt, key, ca, MetaRequireAndVerifyCert); err != nil {
+		t.Error(err)
+	} else if tlsCfg.ClientAuth != tls.RequireAndVerifyClientCert || tlsCfg.ClientCAs == nil ||
+		len(tlsCfg.Certificates) != 1 {
+		t.Errorf("unexpected config: %+v", tlsCfg)
+	}
+}
+
+func TestRemoteRPCClientMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgr_tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestPKI(t, dir)
+	srvTLSCfg, err := NewServerTLSConfig(path.Join(dir, "server.crt"), path.Join(dir, "server.key"),
+		path.Join(dir, "ca.crt"), MetaRequireAndVerifyCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := tls.Listen("tcp", "127.0.0.1:0", srvTLSCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	rpcSrv := rpc.NewServer()
+	rpcSrv.Register(new(TLSTestV1))
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go rpcSrv.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	clntTLSCfg, err := NewClientTLSConfig(path.Join(dir, "client.crt"), path.Join(dir, "client.key"),
+		path.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clnt, err := NewRemoteRPCClient(l.Addr().String(), JSON, clntTLSCfg, "", 1, 1, time.Second, time.Second, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply string
+	if err := clnt.Call("TLSTestV1.Ping", "", &reply); err != nil {
+		t.Error(err)
+	} else if reply != OK {
+		t.Errorf("received: %s", reply)
+	}
+	// missing client certificate
+	clntTLSCfg, err = NewClientTLSConfig("", "", path.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clnt, err = NewRemoteRPCClient(l.Addr().String(), JSON, clntTLSCfg, "", 1, 0, time.Second, time.Second, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := clnt.Call("TLSTestV1.Ping", "", &reply); err == nil {
+		t.Error("expecting error for missing client certificate")
+	}
+	// server not trusted
+	clntTLSCfg, err = NewClientTLSConfig(path.Join(dir, "client.crt"), path.Join(dir, "client.key"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewRemoteRPCClient(l.Addr().String(), JSON, clntTLSCfg, "", 1, 0, time.Second, time.Second, false); err == nil {
+		t.Error("expecting error for server signed by unknown authority")
+	}
+}